@@ -16,12 +16,116 @@
 package backend
 
 import (
+	"errors"
+	"fmt"
 	"io"
+	"math/big"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/consensys/gnark/backend/hint"
 )
 
+// ErrMetadataMismatch is returned by Prove when the metadata of the proving key differs from the one
+// of the constraint system (see frontend.WithMetadata), that is when the key was generated for another circuit
+var ErrMetadataMismatch = errors.New("proving key and constraint system metadata don't match")
+
+// ErrSelfCheckFailed is returned by Prove when the proof doesn't verify against the public witness,
+// see WithSelfCheck. The verification error is included in the message
+var ErrSelfCheckFailed = errors.New("proof self-check failed")
+
+// ErrBlindingCheckFailed is returned by the PLONK prover when a polynomial it commits to isn't blinded,
+// see WithBlindingCheck
+var ErrBlindingCheckFailed = errors.New("blinding check failed")
+
+// ErrHintTimeout is returned by the solver when a hint function doesn't return within the
+// duration set by WithHintTimeout
+var ErrHintTimeout = errors.New("hint timeout")
+
+// ErrSolutionCacheMismatch is returned by the solver when the snapshot of the label given with
+// WithSolutionCache was stored by the solver of another constraint system
+var ErrSolutionCacheMismatch = errors.New("solution cache snapshot is from another circuit")
+
+// ErrSharedSolutionMismatch is returned by the SparseR1CS solver when the values of the shared solution
+// given with WithSharedSolution were solved for another circuit or another witness
+var ErrSharedSolutionMismatch = errors.New("shared solution is from another circuit or witness")
+
+// ErrTooManyPublicInputs is returned by the setups and the verifier exports when the circuit has more
+// public inputs than allowed, see WithMaxPublicInputs
+var ErrTooManyPublicInputs = errors.New("too many public inputs")
+
+// ErrUnsatisfiedAssignment is returned by CheckAssignment, wrapped in an *UnsatisfiedAssignmentError,
+// when constraints are not satisfied by the assignment
+var ErrUnsatisfiedAssignment = errors.New("assignment doesn't satisfy the constraints")
+
+// UnsatisfiedAssignmentError is the summary of the evaluation of the constraints by CheckAssignment
+// (or CheckAssignmentUpTo) of an assignment which doesn't satisfy all of them
+type UnsatisfiedAssignmentError struct {
+	NbUnsatisfied int // number of constraints found unsatisfied
+	NbChecked     int // number of constraints evaluated, less than NbConstraints if the check stopped early
+	NbConstraints int
+}
+
+func (e *UnsatisfiedAssignmentError) Error() string {
+	if e.NbChecked < e.NbConstraints {
+		return fmt.Sprintf("%s: %d unsatisfied constraints (stopped after %d of %d constraints)", ErrUnsatisfiedAssignment, e.NbUnsatisfied, e.NbChecked, e.NbConstraints)
+	}
+	return fmt.Sprintf("%s: %d of %d constraints unsatisfied", ErrUnsatisfiedAssignment, e.NbUnsatisfied, e.NbConstraints)
+}
+
+func (e *UnsatisfiedAssignmentError) Unwrap() error {
+	return ErrUnsatisfiedAssignment
+}
+
+// ErrUnexpectedHint is returned by the solvers, wrapped in an *UnexpectedHintsError, when hint functions
+// given to the prover are not referenced by the constraint system, see WithUnsafeHints
+var ErrUnexpectedHint = errors.New("hint function not referenced by the constraint system")
+
+// UnexpectedHintsError lists the hint functions given to the prover (see WithHints) which the constraint
+// system doesn't reference
+type UnexpectedHintsError struct {
+	Hints []string // name and ID of each offending hint function
+}
+
+func (e *UnexpectedHintsError) Error() string {
+	return fmt.Sprintf("%s: %s; remove them, or see backend.WithUnsafeHints", ErrUnexpectedHint, strings.Join(e.Hints, ", "))
+}
+
+func (e *UnexpectedHintsError) Unwrap() error {
+	return ErrUnexpectedHint
+}
+
+// ErrMissingHint is returned by CheckHintAvailability, wrapped in a *MissingHintsError, when hint
+// functions the constraint system calls are not available to the solvers
+var ErrMissingHint = errors.New("hint function required by the constraint system not available")
+
+// MissingHintsError lists the hint functions the constraint system calls which are neither builtins,
+// registered (see hint.Register) nor given to the prover (see WithHints)
+type MissingHintsError struct {
+	Hints []string // name and ID of each missing hint function
+}
+
+func (e *MissingHintsError) Error() string {
+	return fmt.Sprintf("%s: %s; register them (see hint.Register), or give them to the prover (see backend.WithHints)", ErrMissingHint, strings.Join(e.Hints, ", "))
+}
+
+func (e *MissingHintsError) Unwrap() error {
+	return ErrMissingHint
+}
+
+// ErrCBORHeaderMismatch is returned when decoding a CBOR witness or proof whose header (type, version,
+// curve or circuit digest) doesn't match the object decoded into
+var ErrCBORHeaderMismatch = errors.New("CBOR header mismatch")
+
+// ErrInvalidProofStream is returned when reading a streamed proof (see plonk.ProveStream) which is
+// truncated or corrupted
+var ErrInvalidProofStream = errors.New("invalid proof stream")
+
+// DefaultSolidityMaxPublicInputs is the maximum number of public inputs of an exported Solidity verifier,
+// unless set with WithMaxPublicInputs: the calldata and the gas of the verification grow with them.
+const DefaultSolidityMaxPublicInputs = 256
+
 // ID represent a unique ID for a proving scheme
 type ID uint16
 
@@ -29,9 +133,12 @@ const (
 	UNKNOWN ID = iota
 	GROTH16
 	PLONK
+	SIMULATED // transparent mock of Groth16 for the tests, see package backend/simulated
 )
 
 // Implemented return the list of proof systems implemented in gnark
+//
+// SIMULATED isn't a proof system and isn't listed
 func Implemented() []ID {
 	return []ID{GROTH16, PLONK}
 }
@@ -43,6 +150,8 @@ func (id ID) String() string {
 		return "groth16"
 	case PLONK:
 		return "plonk"
+	case SIMULATED:
+		return "simulated"
 	default:
 		return "unknown"
 	}
@@ -61,9 +170,30 @@ func NewProverOption(opts ...func(opt *ProverOption) error) (ProverOption, error
 
 // ProverOption is shared accross backends to parametrize calls to xxx.Prove(...)
 type ProverOption struct {
-	Force         bool            // default to false
-	HintFunctions []hint.Function // default to nil (use only solver std hints)
-	LoggerOut     io.Writer       // default to os.Stdout
+	Force           bool                     // default to false
+	HintFunctions   []hint.AnnotatedFunction // default to nil (use only solver std hints)
+	LoggerOut       io.Writer                // default to os.Stdout, circuit logs only (api.Println), see package logger for framework messages
+	SelfCheck       bool                     // default to false, see WithSelfCheck
+	VerifyingKey    interface{}              // default to nil, set by groth16.WithVerifyingKey or plonk.WithVerifyingKey
+	HintTimeout     time.Duration            // default to 0 (no timeout), see WithHintTimeout
+	PackBooleans    bool                     // default to false, see WithPackedBooleans
+	ConstantTime    bool                     // default to false, see WithConstantTimeHints
+	UnsafeHints     bool                     // default to false, see WithUnsafeHints
+	SolutionCache   *SolutionCache           // default to nil (no cache), see WithSolutionCache
+	SolutionLabel   string                   // snapshot of SolutionCache to use, see WithSolutionCache
+	SharedSolution  *SharedSolution          // default to nil, see WithSharedSolution
+	WitnessAnalysis io.Writer                // default to nil (no analysis), see WithWitnessAnalysis
+	Randomness      io.Reader                // default to nil (crypto/rand), see WithRandomness
+	CheckBlinding   bool                     // default to false, see WithBlindingCheck
+
+	CheckpointDir      string        // default to "" (no checkpoint), see WithCheckpoint
+	CheckpointInterval time.Duration // see WithCheckpoint
+
+	SolutionCacheDir    string // default to "" (no cache), see WithSolutionCacheDir
+	SolutionCacheChecks int    // default to 0 (DefaultSolutionCacheChecks), see WithSolutionCacheChecks
+
+	ProofComponents func(name string, data []byte) error // default to nil, see WithProofComponents
+	PublicOutputs   func(outputs map[string]*big.Int)    // default to nil, see WithPublicOutputs
 }
 
 // IgnoreSolverError is a ProverOption that indicates that the Prove algorithm
@@ -77,13 +207,280 @@ func IgnoreSolverError(opt *ProverOption) error {
 
 // WithHints is a Prover option that specifies additional hint functions to be used
 // by the constraint solver
+//
+// The hint functions are identified by their Go name (see hint.UUID); hints created with
+// hint.NewFixedHintNamed have stable IDs and are given with WithAnnotatedHints.
 func WithHints(hintFunctions ...hint.Function) func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		for _, f := range hintFunctions {
+			//lint:ignore SA1019 the IDs of the hint functions given to frontend.API.NewHint are their legacy IDs
+			opt.HintFunctions = append(opt.HintFunctions, hint.Annotate(f))
+		}
+		return nil
+	}
+}
+
+// WithAnnotatedHints is a Prover option that specifies additional annotated hint functions
+// (for example, remote hints) to be used by the constraint solver
+func WithAnnotatedHints(hintFunctions ...hint.AnnotatedFunction) func(opt *ProverOption) error {
 	return func(opt *ProverOption) error {
 		opt.HintFunctions = append(opt.HintFunctions, hintFunctions...)
 		return nil
 	}
 }
 
+// WithUnsafeHints is a Prover option that lets the solvers accept hint functions (see WithHints) which
+// the constraint system doesn't reference.
+//
+// By default, the solvers only accept the hint functions whose IDs are referenced by the constraint
+// system, recorded at compile time, and fail with an *UnexpectedHintsError listing the others: a hint
+// function the circuit doesn't expect is a bug at best, and its ID could collide with the one of an
+// expected hint. With or without the option, the builtin and registered hints are only looked up for the
+// IDs referenced by the constraint system.
+func WithUnsafeHints() func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		opt.UnsafeHints = true
+		return nil
+	}
+}
+
+// CheckHintAvailability checks, without solving, that the solvers of ccs (a
+// frontend.CompiledConstraintSystem) will find its hint functions with the prover options opts: it
+// returns a *MissingHintsError listing the hints ccs requires which are neither builtins,
+// registered nor given in opts. It also returns the errors of the solvers on the hints given in opts: an
+// *UnexpectedHintsError (see WithUnsafeHints), or a *hint.Collision.
+//
+// A service loading constraint systems calls it to refuse the ones it can't prove, before any witness.
+func CheckHintAvailability(ccs interface{ RequiredHints() []hint.Reference }, opts ...func(opt *ProverOption) error) error {
+	opt, err := NewProverOption(opts...)
+	if err != nil {
+		return err
+	}
+	required := ccs.RequiredHints()
+	referenced := make(map[hint.ID]string, len(required))
+	for _, r := range required {
+		referenced[r.UUID] = r.Name
+	}
+
+	if !opt.UnsafeHints {
+		var unexpected []string
+		for _, f := range opt.HintFunctions {
+			if _, ok := referenced[f.UUID()]; !ok {
+				unexpected = append(unexpected, fmt.Sprintf("%q (id %#x)", f.String(), uint64(f.UUID())))
+			}
+		}
+		if len(unexpected) != 0 {
+			return &UnexpectedHintsError{Hints: unexpected}
+		}
+	}
+
+	available, err := hint.FunctionsFor(referenced, opt.HintFunctions)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for _, r := range required {
+		if _, ok := available[r.UUID]; !ok {
+			missing = append(missing, fmt.Sprintf("%q (id %#x)", r.Name, uint64(r.UUID)))
+		}
+	}
+	if len(missing) != 0 {
+		return &MissingHintsError{Hints: missing}
+	}
+	return nil
+}
+
+// WithHintTimeout is a Prover option that bounds the duration of each hint function call.
+// If a hint doesn't return in time, the solver fails with ErrHintTimeout.
+//
+// A hint can't be interrupted: its goroutine is abandoned and keeps running until the hint returns,
+// its results are then discarded.
+func WithHintTimeout(d time.Duration) func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		if d < 0 {
+			return errors.New("hint timeout must be positive")
+		}
+		opt.HintTimeout = d
+		return nil
+	}
+}
+
+// WithConstantTimeHints is a Prover option that makes the solvers compute the builtin hints hint.IsZero,
+// hint.InvMod and hint.IthBit, under their stable names (hint.IsZeroNamed,
+// ...) or their legacy IDs, with the field arithmetic of the curve on fixed-width elements, instead of
+// big.Int operations whose running time depends on the values: the inverse is computed as a^(r-2) and
+// the zero test as 1 - a^(r-1), with a public exponent. The results are the same.
+//
+// It only applies to these hints, that is to the hints of the frontend.API methods Inverse, InverseOrZero,
+// IsZero, ToBinary and the comparisons built on it (Cmp, AssertIsLessOrEqual, ...); the other hints
+// (BatchInvMod, PermutationNetwork, the hints of gnark/std and the user hints) and the solving of the
+// constraints themselves are unchanged.
+func WithConstantTimeHints() func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		opt.ConstantTime = true
+		return nil
+	}
+}
+
+// WithPackedBooleans is a Prover option that packs the values of the wires constrained to be boolean
+// at compile time (AssertIsBoolean, ToBinary, IsZero, ...) in a bitset, instead of storing them as
+// field elements. It divides by up to 256 the memory used by these wires, which dominate the witness of
+// circuits like hash functions; the other wires cost a little more to access.
+//
+// The option applies to the R1CS solver and to the Groth16 prover, which accumulates the boolean wires
+// with point additions instead of multi exponentiations. PlonK ignores it.
+func WithPackedBooleans() func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		opt.PackBooleans = true
+		return nil
+	}
+}
+
+// WithSolutionCache is a Prover option that makes the R1CS solver reuse the snapshot stored in cache
+// under label by the previous solve, and store the new solution in its place.
+//
+// Before solving, the solver compares the witness with the snapshot, and solves again only the
+// constraints depending (through the wires they solve, or the hints they call) on the inputs which
+// changed; the wires solved by the other constraints are copied from the snapshot. This speeds up
+// the solving of successive witnesses which differ in a few inputs, for example proofs over a sliding
+// window of data.
+//
+// The solver fails with ErrSolutionCacheMismatch if the snapshot was stored by the solver of another
+// constraint system. PlonK ignores the option.
+func WithSolutionCache(cache *SolutionCache, label string) func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		if cache == nil {
+			return errors.New("solution cache is nil")
+		}
+		opt.SolutionCache = cache
+		opt.SolutionLabel = label
+		return nil
+	}
+}
+
+// WithSharedSolution is a Prover option that solves the witness once for the two constraint systems of
+// a circuit compiled with frontend.CompileBoth: the R1CS solver (Groth16) stores the wire values in
+// shared, and the SparseR1CS solver (PlonK) copies them through the wire mapping, such that only the
+// wires created by the PlonK conversion are solved. The constraints are still all checked.
+//
+// The R1CS must be solved first; otherwise, the SparseR1CS solver solves all the wires. It fails
+// with ErrSharedSolutionMismatch if the stored values were solved for another circuit or witness.
+func WithSharedSolution(shared *SharedSolution) func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		if shared == nil {
+			return errors.New("shared solution is nil")
+		}
+		opt.SharedSolution = shared
+		return nil
+	}
+}
+
+// WithCheckpoint is a Prover option that makes the solver save its state (the solved wires, the
+// constraint to resume at and, for the R1CS solver, the a, b, c vectors computed so far) to a
+// checkpoint file in dir, at least interval apart. If the process is interrupted (out of memory,
+// preemption...), the next solve of the same constraint system and witness with the option resumes
+// from the checkpoint instead of starting over.
+//
+// There is a checkpoint per constraint system and witness, named after their digest: the checkpoints
+// of the other constraint systems and witnesses are ignored, as are truncated or corrupted files.
+// The values are stored in the raw encoding of the field elements; the files are as big as the
+// solution (and the a, b, c vectors), and are removed once the witness is solved.
+func WithCheckpoint(dir string, interval time.Duration) func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		if dir == "" {
+			return errors.New("checkpoint directory is empty")
+		}
+		if interval <= 0 {
+			return errors.New("checkpoint interval must be positive")
+		}
+		opt.CheckpointDir = dir
+		opt.CheckpointInterval = interval
+		return nil
+	}
+}
+
+// DefaultSolutionCacheChecks is the number of constraints a solution loaded from a solution cache directory
+// is checked against, unless set with WithSolutionCacheChecks
+const DefaultSolutionCacheChecks = 64
+
+// WithSolutionCacheDir is a Prover option that makes the solver store the solved wires (and, for the R1CS
+// solver, the a, b, c vectors) of the witness in dir, and load them instead of solving the witness again
+// in the next proofs, such that they skip straight to the cryptographic phases. It is meant for the tests
+// which prove a circuit many times with the same witness.
+//
+// The entries are named after the digest of the constraint system, the witness, and the hint functions
+// given to the prover (their ID and name, not their code). A loaded solution is checked against a sample
+// of the constraints (see WithSolutionCacheChecks), to catch the entries gone stale; a stale entry is
+// removed and the witness solved again. The files are as big as the solution, they are not removed.
+//
+// It is unsafe in production: the solver trusts the wires of the files which are not checked, and the
+// files hold the secret inputs and the wires computed from them, in clear.
+func WithSolutionCacheDir(dir string) func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		if dir == "" {
+			return errors.New("solution cache directory is empty")
+		}
+		opt.SolutionCacheDir = dir
+		return nil
+	}
+}
+
+// WithSolutionCacheChecks is a Prover option that sets the number of constraints, chosen at random, a solution
+// loaded from the solution cache directory is checked against, see WithSolutionCacheDir. n < 0 checks all
+// the constraints.
+func WithSolutionCacheChecks(n int) func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		if n == 0 {
+			return errors.New("the number of checks of the solution cache must be non-zero")
+		}
+		opt.SolutionCacheChecks = n
+		return nil
+	}
+}
+
+// SetupOption configures the setups of the proving schemes and the export of their verifiers
+type SetupOption struct {
+	MaxPublicInputs int // default to 0 (no limit, DefaultSolidityMaxPublicInputs for the Solidity verifiers), see WithMaxPublicInputs
+}
+
+// NewSetupOption returns a default SetupOption with given options applied
+func NewSetupOption(opts ...func(opt *SetupOption) error) (SetupOption, error) {
+	opt := SetupOption{}
+	for _, option := range opts {
+		if err := option(&opt); err != nil {
+			return SetupOption{}, err
+		}
+	}
+	return opt, nil
+}
+
+// WithMaxPublicInputs is a Setup and verifier export option which makes them fail with
+// ErrTooManyPublicInputs if the circuit has more than n public inputs.
+//
+// The verifiers degrade with the number of public inputs, on chain in particular, where each costs
+// calldata and a scalar multiplication; the option catches a circuit growing past what its verifier
+// can afford when the keys are generated, instead of when the verifier is deployed.
+func WithMaxPublicInputs(n int) func(opt *SetupOption) error {
+	return func(opt *SetupOption) error {
+		if n <= 0 {
+			return errors.New("maximum number of public inputs must be positive")
+		}
+		opt.MaxPublicInputs = n
+		return nil
+	}
+}
+
+// CheckPublicInputs returns ErrTooManyPublicInputs (wrapped) if nbPublicInputs is more than max,
+// unless max is 0
+func CheckPublicInputs(nbPublicInputs, max int) error {
+	if max > 0 && nbPublicInputs > max {
+		return fmt.Errorf("%w: the circuit has %d public inputs, the maximum is %d; "+
+			"consider compressing the public inputs: hash them in the circuit, and make the hash the only public input",
+			ErrTooManyPublicInputs, nbPublicInputs, max)
+	}
+	return nil
+}
+
 // WithOutput is a Prover option that specifies an io.Writer as destination for logs printed by
 // api.Println(). If set to nil, no logs are printed.
 func WithOutput(w io.Writer) func(opt *ProverOption) error {
@@ -92,3 +489,73 @@ func WithOutput(w io.Writer) func(opt *ProverOption) error {
 		return nil
 	}
 }
+
+// WithSelfCheck is a Prover option that verifies the proof against the public witness before returning it.
+// If the verification fails (for example, because the proving key is corrupted), Prove returns
+// ErrSelfCheckFailed instead of the proof.
+//
+// The verifying key is taken from the option WithVerifyingKey of the proving scheme package or, for PLONK,
+// from the proving key; Prove returns an error if none is available.
+func WithSelfCheck() func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		opt.SelfCheck = true
+		return nil
+	}
+}
+
+// WithRandomness is a Prover option that draws the randomness of the proof (the r, s of Groth16, the
+// blinding polynomials of PLONK) from r instead of crypto/rand. Two proofs of the same witness with
+// readers returning the same bytes are then identical, which is useful to reproduce a proof in tests.
+//
+// The randomness is what makes the proofs zero-knowledge: r must not be predictable in production.
+func WithRandomness(r io.Reader) func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		if r == nil {
+			return errors.New("randomness reader is nil")
+		}
+		opt.Randomness = r
+		return nil
+	}
+}
+
+// WithBlindingCheck is a Prover option that makes the PLONK prover check that each polynomial it commits
+// to (l, r, o, z, and those of the lookups) is blinded, that is that the coefficients added by the blinding
+// are not zero; Prove returns ErrBlindingCheckFailed otherwise. Groth16 ignores the option.
+func WithBlindingCheck() func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		opt.CheckBlinding = true
+		return nil
+	}
+}
+
+// WithProofComponents is a Prover option that calls f with each component of the proof (its commitments,
+// its opening proofs...) as soon as it is final, encoded as in Proof.WriteTo. The prover stops with the
+// error of f, if any.
+//
+// The PLONK prover calls f as the rounds of the protocol end; the components of a Groth16 proof are
+// computed concurrently, f is called with them once the proof is computed. See plonk.ProveStream and
+// groth16.ProveStream, which write the components to a stream.
+func WithProofComponents(f func(name string, data []byte) error) func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		if f == nil {
+			return errors.New("proof components function is nil")
+		}
+		opt.ProofComponents = f
+		return nil
+	}
+}
+
+// WithPublicOutputs is a Prover option that calls f with the values of the public outputs of the circuit
+// (the public inputs tagged gnark:",public,output", see frontend.API.SetOutput) computed by the solver,
+// indexed by their name in the schema. The witness given to Prove doesn't need to assign them; they are
+// part of the public witness of the verifier. The self-check (see WithSelfCheck) verifies the proof
+// against the witness given to Prove: it must then assign the outputs.
+func WithPublicOutputs(f func(outputs map[string]*big.Int)) func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		if f == nil {
+			return errors.New("public outputs function is nil")
+		}
+		opt.PublicOutputs = f
+		return nil
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/examples/cubic"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// zeroReader is a source of randomness which only returns zeros
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// lookupCircuit checks that X is a byte, with a lookup, and that Y == X + 1
+type lookupCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *lookupCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	lk := api.(frontend.Lookuper)
+	entries := make([]big.Int, 256)
+	for i := 0; i < len(entries); i++ {
+		entries[i].SetUint64(uint64(i))
+	}
+	lk.Lookup(lk.AddTable("bytes", entries), circuit.X)
+	api.AssertIsEqual(circuit.Y, api.Add(circuit.X, 1))
+	return nil
+}
+
+func TestBlindingCheck(t *testing.T) {
+	assert := require.New(t)
+
+	var w cubic.Circuit
+	w.X.Assign(3)
+	w.Y.Assign(35)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &cubic.Circuit{})
+	assert.NoError(err)
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(err)
+	pk, vk, err := plonk.Setup(ccs, srs)
+	assert.NoError(err)
+
+	proof, err := plonk.Prove(ccs, pk, &w, backend.WithBlindingCheck())
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(proof, vk, &w))
+
+	// without randomness, the blinding adds nothing: the proof verifies, but isn't zero-knowledge
+	proof, err = plonk.Prove(ccs, pk, &w, backend.WithRandomness(zeroReader{}))
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(proof, vk, &w))
+	_, err = plonk.Prove(ccs, pk, &w, backend.WithRandomness(zeroReader{}), backend.WithBlindingCheck())
+	assert.ErrorIs(err, backend.ErrBlindingCheckFailed)
+
+	// the polynomials of the lookups are checked too
+	var lw lookupCircuit
+	lw.X.Assign(42)
+	lw.Y.Assign(43)
+	ccs, err = frontend.Compile(ecc.BN254, backend.PLONK, &lookupCircuit{})
+	assert.NoError(err)
+	srs, err = test.NewKZGSRS(ccs)
+	assert.NoError(err)
+	pk, vk, err = plonk.Setup(ccs, srs)
+	assert.NoError(err)
+	proof, err = plonk.Prove(ccs, pk, &lw, backend.WithBlindingCheck())
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(proof, vk, &lw))
+
+	_, err = backend.NewProverOption(backend.WithRandomness(nil))
+	assert.Error(err)
+}
+
+func TestRandomness(t *testing.T) {
+	assert := require.New(t)
+
+	var w cubic.Circuit
+	w.X.Assign(3)
+	w.Y.Assign(35)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &cubic.Circuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	// the bytes of r and s are read in order, and reduced modulo the scalar field
+	rs := make([]byte, 128)
+	rs[63], rs[127] = 1, 2
+	proof, err := groth16.Prove(ccs, pk, &w, backend.WithRandomness(bytes.NewReader(rs)))
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, &w))
+	again, err := groth16.Prove(ccs, pk, &w, backend.WithRandomness(bytes.NewReader(rs)))
+	assert.NoError(err)
+	assert.Equal(proof, again)
+
+	// a short read fails the proof
+	_, err = groth16.Prove(ccs, pk, &w, backend.WithRandomness(bytes.NewReader(rs[:100])))
+	assert.Error(err)
+
+	modulus := ecc.BN254.Info().Fr.Modulus()
+	wide := make([]byte, 128)
+	new(big.Int).Add(modulus, big.NewInt(1)).FillBytes(wide[:64])
+	new(big.Int).Add(modulus, big.NewInt(2)).FillBytes(wide[64:])
+	reduced, err := groth16.Prove(ccs, pk, &w, backend.WithRandomness(bytes.NewReader(wide)))
+	assert.NoError(err)
+	assert.Equal(proof, reduced)
+}
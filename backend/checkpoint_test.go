@@ -0,0 +1,172 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	errInterrupted = errors.New("interrupted")
+
+	// nbFirstCalls counts the calls to checkpointFirst, interrupted makes checkpointMiddle fail
+	nbFirstCalls int32
+	interrupted  int32
+
+	checkpointFirst = hint.NewFixedHintNamed("checkpointFirst", func(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+		atomic.AddInt32(&nbFirstCalls, 1)
+		outputs[0].Set(inputs[0])
+		return nil
+	}, 1, 1)
+	checkpointMiddle = hint.NewFixedHintNamed("checkpointMiddle", func(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+		if atomic.LoadInt32(&interrupted) == 1 {
+			return errInterrupted
+		}
+		outputs[0].Set(inputs[0])
+		return nil
+	}, 1, 1)
+)
+
+// checkpointCircuit iterates x <- x*x + X; the first iteration calls checkpointFirst, the middle one
+// checkpointMiddle, which interrupts the solve
+type checkpointCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+const nbCheckpointIterations = 8192
+
+func (circuit *checkpointCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	x := circuit.X
+	for i := 0; i < nbCheckpointIterations; i++ {
+		if i == 0 || i == nbCheckpointIterations/2 {
+			f := checkpointFirst
+			if i != 0 {
+				f = checkpointMiddle
+			}
+			h := api.NewAnnotatedHint(f, x)[0]
+			api.AssertIsEqual(h, x)
+			x = h
+		}
+		x = api.Add(api.Mul(x, x), circuit.X)
+	}
+	api.AssertIsEqual(x, circuit.Y)
+	return nil
+}
+
+func newCheckpointWitness(x int64) *checkpointCircuit {
+	modulus := ecc.BN254.Info().Fr.Modulus()
+	X := big.NewInt(x)
+	y := new(big.Int).Set(X)
+	for i := 0; i < nbCheckpointIterations; i++ {
+		y.Mul(y, y).Add(y, X).Mod(y, modulus)
+	}
+	var witness checkpointCircuit
+	witness.X.Assign(X)
+	witness.Y.Assign(y)
+	return &witness
+}
+
+// TestCheckpoint interrupts the solve of a witness halfway, and checks that the next Prove resumes
+// from the checkpoint and computes a valid proof, for both backends. The checkpoints of another
+// witness, and the corrupted checkpoints, are ignored.
+func TestCheckpoint(t *testing.T) {
+	assert := require.New(t)
+
+	for _, b := range backend.Implemented() {
+		dir := t.TempDir()
+		ccs, err := frontend.Compile(ecc.BN254, b, &checkpointCircuit{})
+		assert.NoError(err)
+
+		var prove func(witness frontend.Circuit) error
+		switch b {
+		case backend.GROTH16:
+			pk, vk, err := groth16.Setup(ccs)
+			assert.NoError(err)
+			prove = func(witness frontend.Circuit) error {
+				proof, err := groth16.Prove(ccs, pk, witness, backend.WithCheckpoint(dir, time.Nanosecond), backend.WithAnnotatedHints(checkpointFirst, checkpointMiddle))
+				if err != nil {
+					return err
+				}
+				return groth16.Verify(proof, vk, witness)
+			}
+		case backend.PLONK:
+			srs, err := test.NewKZGSRS(ccs)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(ccs, srs)
+			assert.NoError(err)
+			prove = func(witness frontend.Circuit) error {
+				proof, err := plonk.Prove(ccs, pk, witness, backend.WithCheckpoint(dir, time.Nanosecond), backend.WithAnnotatedHints(checkpointFirst, checkpointMiddle))
+				if err != nil {
+					return err
+				}
+				return plonk.Verify(proof, vk, witness)
+			}
+		}
+		checkpoints := func() []string {
+			files, err := filepath.Glob(filepath.Join(dir, "*.checkpoint"))
+			assert.NoError(err)
+			return files
+		}
+
+		w1, w2 := newCheckpointWitness(3), newCheckpointWitness(5)
+
+		// interrupt the solve of w1 halfway
+		atomic.StoreInt32(&nbFirstCalls, 0)
+		atomic.StoreInt32(&interrupted, 1)
+		assert.True(errors.Is(prove(w1), errInterrupted), b)
+		assert.Len(checkpoints(), 1, b)
+		assert.Equal(int32(1), atomic.LoadInt32(&nbFirstCalls), b)
+
+		// the checkpoint of w1 is ignored by the solve of w2
+		atomic.StoreInt32(&interrupted, 0)
+		assert.NoError(prove(w2), b)
+		assert.Equal(int32(2), atomic.LoadInt32(&nbFirstCalls), b)
+		assert.Len(checkpoints(), 1, b)
+
+		// a corrupted checkpoint is ignored
+		path := checkpoints()[0]
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(err)
+		data[len(data)/2] ^= 1
+		assert.NoError(ioutil.WriteFile(path, data, 0600))
+		atomic.StoreInt32(&interrupted, 1)
+		assert.True(errors.Is(prove(w1), errInterrupted), b)
+		assert.Equal(int32(3), atomic.LoadInt32(&nbFirstCalls), b)
+
+		// the solve of w1 resumes after the first hint, and the checkpoint is removed once w1 is solved
+		atomic.StoreInt32(&interrupted, 0)
+		assert.NoError(prove(w1), b)
+		assert.Equal(int32(3), atomic.LoadInt32(&nbFirstCalls), b)
+		assert.Empty(checkpoints(), b)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fiatshamir provides the native counterpart of the in-circuit transcript of
+// gnark/std/fiat-shamir.
+//
+// Challenge names and bound values are written in the hash as field elements of the scalar field
+// of the curve, like the gadget does, such that with MiMC (see NewMiMCTranscript) both derive the
+// same challenges from the same bound values. This is needed to recompute in a circuit the
+// challenges of a proof verifier.
+package fiatshamir
+
+import (
+	"errors"
+	"hash"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	cryptohash "github.com/consensys/gnark-crypto/hash"
+)
+
+// Errors returned by the transcripts when a challenge is unknown or used out of order. The in-circuit
+// transcript of gnark/std/fiat-shamir returns the same errors.
+var (
+	ErrChallengeNotFound            = errors.New("challenge not recorded in the Transcript")
+	ErrChallengeAlreadyComputed     = errors.New("challenge already computed, cannot be binded to other values")
+	ErrPreviousChallengeNotComputed = errors.New("the previous challenge is needed and has not been computed")
+)
+
+// Transcript handles the creation of challenges for Fiat Shamir.
+type Transcript struct {
+	// hash function that is used.
+	h hash.Hash
+
+	modulus    *big.Int
+	challenges map[string]challenge
+	previous   *challenge
+}
+
+type challenge struct {
+	position   int    // position of the challenge in the transcript. order matters.
+	bindings   []byte // bindings stores the field elements a challenge is binded to.
+	value      []byte // value stores the computed challenge
+	isComputed bool
+}
+
+// NewTranscript returns a new transcript over the scalar field of curveID.
+// h is the hash function that is used to compute the challenges.
+// challenges are the name of the challenges. The order is important.
+func NewTranscript(curveID ecc.ID, h hash.Hash, challengesID ...string) Transcript {
+	n := len(challengesID)
+	t := Transcript{
+		h:          h,
+		modulus:    curveID.Info().Fr.Modulus(),
+		challenges: make(map[string]challenge, n),
+	}
+
+	for i := 0; i < n; i++ {
+		t.challenges[challengesID[i]] = challenge{position: i}
+	}
+
+	return t
+}
+
+// NewMiMCTranscript returns a new transcript using the MiMC hash function of curveID with the
+// given seed, which derives the same challenges as the in-circuit transcript with
+// gnark/std/hash/mimc.NewMiMC(seed, curveID, api).
+func NewMiMCTranscript(curveID ecc.ID, seed string, challengesID ...string) Transcript {
+	var h hash.Hash
+	switch curveID {
+	case ecc.BN254:
+		h = cryptohash.MIMC_BN254.New(seed)
+	case ecc.BLS12_377:
+		h = cryptohash.MIMC_BLS12_377.New(seed)
+	case ecc.BLS12_381:
+		h = cryptohash.MIMC_BLS12_381.New(seed)
+	case ecc.BLS24_315:
+		h = cryptohash.MIMC_BLS24_315.New(seed)
+	case ecc.BW6_761:
+		h = cryptohash.MIMC_BW6_761.New(seed)
+	case ecc.BW6_633:
+		h = cryptohash.MIMC_BW6_633.New(seed)
+	default:
+		panic("not implemented")
+	}
+	return NewTranscript(curveID, h, challengesID...)
+}
+
+// Bind binds the challenge to values, reduced modulo the scalar field. A challenge can be binded
+// to an arbitrary number of values, but the order in which the binded values are added is
+// important. Once a challenge is computed, it cannot be binded to other values.
+func (t *Transcript) Bind(challengeID string, values []*big.Int) error {
+
+	challenge, ok := t.challenges[challengeID]
+
+	if !ok {
+		return ErrChallengeNotFound
+	}
+	if challenge.isComputed {
+		return ErrChallengeAlreadyComputed
+	}
+
+	for _, v := range values {
+		challenge.bindings = append(challenge.bindings, t.element(v)...)
+	}
+	t.challenges[challengeID] = challenge
+
+	return nil
+
+}
+
+// ComputeChallenge computes the challenge corresponding to the given name.
+// The resulting value is:
+// * H(name || previous_challenge || binded_values...) if the challenge is not the first one
+// * H(name || binded_values... ) if it's is the first challenge
+// where name is the big-endian integer of the bytes of the name, reduced modulo the scalar field.
+func (t *Transcript) ComputeChallenge(challengeID string) (*big.Int, error) {
+
+	challenge, ok := t.challenges[challengeID]
+
+	if !ok {
+		return nil, ErrChallengeNotFound
+	}
+
+	// if the challenge was already computed we return it
+	if challenge.isComputed {
+		return new(big.Int).SetBytes(challenge.value), nil
+	}
+
+	t.h.Reset()
+	defer t.h.Reset()
+
+	// write the challenge name, the purpose is to have a domain separator
+	if _, err := t.h.Write(t.element(new(big.Int).SetBytes([]byte(challengeID)))); err != nil {
+		return nil, err
+	}
+
+	// write the previous challenge if it's not the first challenge
+	if challenge.position != 0 {
+		if t.previous == nil || (t.previous.position != challenge.position-1) {
+			return nil, ErrPreviousChallengeNotComputed
+		}
+		if _, err := t.h.Write(t.previous.value); err != nil {
+			return nil, err
+		}
+	}
+
+	// write the binded values in the order they were added
+	if _, err := t.h.Write(challenge.bindings); err != nil {
+		return nil, err
+	}
+
+	// compute the hash of the accumulated values
+	challenge.value = t.h.Sum(nil)
+	challenge.isComputed = true
+	t.previous = &challenge
+
+	t.challenges[challengeID] = challenge
+
+	return new(big.Int).SetBytes(challenge.value), nil
+
+}
+
+// element returns the big-endian encoding of v modulo the scalar field, on the byte size of the field
+func (t *Transcript) element(v *big.Int) []byte {
+	var r big.Int
+	r.Mod(v, t.modulus)
+	return r.FillBytes(make([]byte, (t.modulus.BitLen()+7)/8))
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fiatshamir
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscript(t *testing.T) {
+	assert := require.New(t)
+
+	// with names and values on the byte size of the field, the challenges are the ones
+	// of the gnark-crypto transcript
+	alpha, beta := string(make([]byte, 32)), string(append(make([]byte, 31), 1))
+	expected := fiatshamir.NewTranscript(hash.MIMC_BN254.New("seed"), alpha, beta)
+	ts := NewMiMCTranscript(ecc.BN254, "seed", alpha, beta)
+
+	values := []*big.Int{big.NewInt(1), big.NewInt(42)}
+	for _, v := range values {
+		assert.NoError(expected.Bind(alpha, v.FillBytes(make([]byte, 32))))
+	}
+	assert.NoError(ts.Bind(alpha, values))
+
+	for _, id := range []string{alpha, beta} {
+		e, err := expected.ComputeChallenge(id)
+		assert.NoError(err)
+		c, err := ts.ComputeChallenge(id)
+		assert.NoError(err)
+		assert.Equal(new(big.Int).SetBytes(e), c)
+	}
+
+	// a computed challenge is returned as is
+	c, err := ts.ComputeChallenge(alpha)
+	assert.NoError(err)
+	again, err := ts.ComputeChallenge(alpha)
+	assert.NoError(err)
+	assert.Equal(c, again)
+
+	// values are reduced modulo the scalar field
+	r := ecc.BN254.Info().Fr.Modulus()
+	ts1 := NewMiMCTranscript(ecc.BN254, "seed", "gamma")
+	ts2 := NewMiMCTranscript(ecc.BN254, "seed", "gamma")
+	assert.NoError(ts1.Bind("gamma", []*big.Int{big.NewInt(3)}))
+	assert.NoError(ts2.Bind("gamma", []*big.Int{new(big.Int).Add(r, big.NewInt(3))}))
+	c1, err := ts1.ComputeChallenge("gamma")
+	assert.NoError(err)
+	c2, err := ts2.ComputeChallenge("gamma")
+	assert.NoError(err)
+	assert.Equal(c1, c2)
+}
+
+func TestTranscriptOrdering(t *testing.T) {
+	assert := require.New(t)
+
+	ts := NewMiMCTranscript(ecc.BN254, "seed", "gamma", "alpha", "zeta")
+
+	assert.ErrorIs(ts.Bind("beta", nil), ErrChallengeNotFound)
+	_, err := ts.ComputeChallenge("beta")
+	assert.ErrorIs(err, ErrChallengeNotFound)
+
+	_, err = ts.ComputeChallenge("alpha")
+	assert.ErrorIs(err, ErrPreviousChallengeNotComputed)
+
+	_, err = ts.ComputeChallenge("gamma")
+	assert.NoError(err)
+	assert.ErrorIs(ts.Bind("gamma", []*big.Int{big.NewInt(1)}), ErrChallengeAlreadyComputed)
+
+	_, err = ts.ComputeChallenge("zeta")
+	assert.ErrorIs(err, ErrPreviousChallengeNotComputed)
+	_, err = ts.ComputeChallenge("alpha")
+	assert.NoError(err)
+	_, err = ts.ComputeChallenge("zeta")
+	assert.NoError(err)
+}
@@ -0,0 +1,215 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package bls12381verifier
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	gnarkio "github.com/consensys/gnark/io"
+)
+
+var (
+	// ErrPairingCheckFailed is returned by Verify when the proof is invalid
+	ErrPairingCheckFailed = errors.New("pairing doesn't match")
+
+	// ErrSubgroupCheckFailed is returned by Verify when the points of the proof are not in the correct subgroups
+	ErrSubgroupCheckFailed = errors.New("points in the proof are not in the correct subgroup")
+)
+
+// Proof is a Groth16 proof over BLS12-381, see ReadProof
+type Proof struct {
+	Ar, Krs curve.G1Affine
+	Bs      curve.G2Affine
+}
+
+// ReadProof reads a proof written with groth16.Proof.WriteTo (compressed) or WriteRawTo (uncompressed)
+func ReadProof(r io.Reader) (*Proof, error) {
+	var proof Proof
+	dec := curve.NewDecoder(r)
+	toDecode := []interface{}{
+		&proof.Ar,
+		&proof.Bs,
+		&proof.Krs,
+	}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return nil, err
+		}
+	}
+	return &proof, nil
+}
+
+// VerifyingKey holds the part of a Groth16 verifying key over BLS12-381 that verification needs, see ReadVerifyingKey
+type VerifyingKey struct {
+	Prepared
+
+	// [Kvk]1, the indexes correspond to the public wires: the constant wire ONE, then the public inputs
+	K []curve.G1Affine
+
+	// metadata of the constraint system (see frontend.WithMetadata)
+	Metadata map[string]string
+}
+
+// ReadVerifyingKey reads a verifying key written with groth16.VerifyingKey.WriteTo (compressed) or
+// WriteRawTo (uncompressed), and prepares it: the pairing e(α, β) is computed once, here.
+//
+// format: [α]1,[β]1,[β]2,[γ]2,[δ]1,[δ]2,uint32(len(Kvk)),[Kvk]1, optionally followed by the metadata
+func ReadVerifyingKey(r io.Reader) (*VerifyingKey, error) {
+	var vk VerifyingKey
+	var alpha, beta1, delta1 curve.G1Affine
+	var beta, gamma, delta curve.G2Affine
+
+	lr := gnarkio.NewLimitReader(r)
+	dec := curve.NewDecoder(lr)
+	toDecode := []interface{}{
+		&alpha,
+		&beta1,
+		&beta,
+		&gamma,
+		&delta1,
+		&delta,
+		&vk.K,
+	}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return nil, err
+		}
+	}
+	if len(vk.K) == 0 {
+		return nil, errors.New("invalid verifying key: empty Kvk")
+	}
+
+	metadata, _, err := compiled.ReadMetadataSection(lr)
+	if err != nil {
+		return nil, err
+	}
+	vk.Metadata = metadata
+
+	if vk.Prepared, err = Prepare(alpha, beta, gamma, delta); err != nil {
+		return nil, err
+	}
+	return &vk, nil
+}
+
+// NbPublicWitness returns the number of elements of the public witness
+func (vk *VerifyingKey) NbPublicWitness() int {
+	return len(vk.K) - 1
+}
+
+// ReadPublicWitness reads a public witness written with witness.WritePublicTo, for the verifying key vk
+//
+// format: uint32(len(publicWitness)) | publicWitness
+func ReadPublicWitness(r io.Reader, vk *VerifyingKey) ([]fr.Element, error) {
+	var buf [4]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return nil, err
+	}
+	if n := binary.BigEndian.Uint32(buf[:]); int(n) != vk.NbPublicWitness() {
+		return nil, fmt.Errorf("invalid witness size, got %d, expected %d", n, vk.NbPublicWitness())
+	}
+
+	publicWitness := make([]fr.Element, vk.NbPublicWitness())
+	dec := curve.NewDecoder(io.LimitReader(r, int64(len(publicWitness)*fr.Limbs*8)))
+	for i := 0; i < len(publicWitness); i++ {
+		if err := dec.Decode(&publicWitness[i]); err != nil {
+			return nil, err
+		}
+	}
+	return publicWitness, nil
+}
+
+// Verify verifies a proof with given VerifyingKey and publicWitness
+func Verify(proof *Proof, vk *VerifyingKey, publicWitness []fr.Element) error {
+	return vk.Prepared.Verify(proof, vk.K, publicWitness)
+}
+
+// Prepared holds the values derived from a verifying key that verification needs, besides [Kvk]1
+type Prepared struct {
+	E                  curve.GT       // e(α, β)
+	GammaNeg, DeltaNeg curve.G2Affine // -[γ]2, -[δ]2
+}
+
+// Prepare computes e(α, β), -[γ]2 and -[δ]2
+func Prepare(alpha curve.G1Affine, beta, gamma, delta curve.G2Affine) (Prepared, error) {
+	var p Prepared
+	var err error
+	p.E, err = curve.Pair([]curve.G1Affine{alpha}, []curve.G2Affine{beta})
+	if err != nil {
+		return Prepared{}, err
+	}
+	p.GammaNeg.Neg(&gamma)
+	p.DeltaNeg.Neg(&delta)
+	return p, nil
+}
+
+// Verify verifies a proof for the public witness (without the ONE_WIRE), k being the [Kvk]1 of the
+// verifying key
+func (p *Prepared) Verify(proof *Proof, k []curve.G1Affine, publicWitness []fr.Element) error {
+	if len(publicWitness) != (len(k) - 1) {
+		return fmt.Errorf("invalid witness size, got %d, expected %d (public - ONE_WIRE)", len(publicWitness), len(k)-1)
+	}
+
+	// check that the points in the proof are in the correct subgroup
+	if !(proof.Ar.IsInSubGroup() && proof.Krs.IsInSubGroup() && proof.Bs.IsInSubGroup()) {
+		return ErrSubgroupCheckFailed
+	}
+
+	var doubleML curve.GT
+	chDone := make(chan error, 1)
+
+	// compute (eKrsδ, eArBs)
+	go func() {
+		var errML error
+		doubleML, errML = curve.MillerLoop([]curve.G1Affine{proof.Krs, proof.Ar}, []curve.G2Affine{p.DeltaNeg, proof.Bs})
+		chDone <- errML
+		close(chDone)
+	}()
+
+	// compute e(Σx.[Kvk(t)]1, -[γ]2)
+	var kSum curve.G1Jac
+	if _, err := kSum.MultiExp(k[1:], publicWitness, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return err
+	}
+	kSum.AddMixed(&k[0])
+	var kSumAff curve.G1Affine
+	kSumAff.FromJacobian(&kSum)
+
+	right, err := curve.MillerLoop([]curve.G1Affine{kSumAff}, []curve.G2Affine{p.GammaNeg})
+	if err != nil {
+		return err
+	}
+
+	// wait for (eKrsδ, eArBs)
+	if err := <-chDone; err != nil {
+		return err
+	}
+
+	right = curve.FinalExponentiation(&right, &doubleML)
+	if !p.E.Equal(&right) {
+		return ErrPairingCheckFailed
+	}
+	return nil
+}
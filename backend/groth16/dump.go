@@ -0,0 +1,89 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groth16
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	groth16_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/groth16"
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+	groth16_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	groth16_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/groth16"
+	groth16_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/groth16"
+	"github.com/consensys/gnark/internal/backend/ioutils"
+)
+
+// MapProvingKey loads a ProvingKey written with ProvingKey.WriteDump from the file at path.
+//
+// On Linux the file is memory mapped and the large point tables of the key are used in place:
+// loading is close to instantaneous and the tables are paged in by the OS as the prover needs them,
+// instead of being copied on the Go heap. On other platforms, the file is read in memory and the tables
+// still alias the read buffer (no per-point decoding or allocation).
+//
+// The returned release function unmaps the file; the ProvingKey must not be used after it is called.
+func MapProvingKey(path string) (pk ProvingKey, release func() error, err error) {
+	data, unmap, err := ioutils.MapFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = unmap()
+		}
+	}()
+
+	// header: magic | curveID | ...
+	if len(data) < 16 {
+		return nil, nil, errors.New("invalid proving key dump: file too short")
+	}
+	curveID := ecc.ID(binary.BigEndian.Uint64(data[8:16]))
+
+	switch curveID {
+	case ecc.BN254:
+		_pk := &groth16_bn254.ProvingKey{}
+		err = _pk.ReadDumpBytes(data)
+		pk = _pk
+	case ecc.BLS12_377:
+		_pk := &groth16_bls12377.ProvingKey{}
+		err = _pk.ReadDumpBytes(data)
+		pk = _pk
+	case ecc.BLS12_381:
+		_pk := &groth16_bls12381.ProvingKey{}
+		err = _pk.ReadDumpBytes(data)
+		pk = _pk
+	case ecc.BW6_761:
+		_pk := &groth16_bw6761.ProvingKey{}
+		err = _pk.ReadDumpBytes(data)
+		pk = _pk
+	case ecc.BW6_633:
+		_pk := &groth16_bw6633.ProvingKey{}
+		err = _pk.ReadDumpBytes(data)
+		pk = _pk
+	case ecc.BLS24_315:
+		_pk := &groth16_bls24315.ProvingKey{}
+		err = _pk.ReadDumpBytes(data)
+		pk = _pk
+	default:
+		return nil, nil, errors.New("invalid proving key dump: unknown curve")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pk, unmap, nil
+}
@@ -0,0 +1,152 @@
+package groth16
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/curves"
+	"github.com/stretchr/testify/require"
+)
+
+type dumpCircuit struct {
+	nbConstraints int
+	X             frontend.Variable
+	Y             frontend.Variable `gnark:",public"`
+}
+
+func (circuit *dumpCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	x := circuit.X
+	for i := 0; i < circuit.nbConstraints; i++ {
+		x = api.Mul(x, circuit.X)
+	}
+	api.AssertIsEqual(x, circuit.Y)
+	return nil
+}
+
+func TestMapProvingKey(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range curves.Implemented() {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &dumpCircuit{nbConstraints: 3})
+		assert.NoError(err)
+
+		pk, vk, err := Setup(ccs)
+		assert.NoError(err)
+
+		// X**4 == Y
+		var witness dumpCircuit
+		witness.X.Assign(2)
+		witness.Y.Assign(16)
+
+		path := filepath.Join(t.TempDir(), "pk.dump")
+		f, err := os.Create(path)
+		assert.NoError(err)
+		assert.NoError(pk.WriteDump(f))
+		assert.NoError(f.Close())
+
+		mappedPK, release, err := MapProvingKey(path)
+		assert.NoError(err)
+		assert.False(pk.IsDifferent(mappedPK), "mapped proving key differs from original")
+
+		proof, err := Prove(ccs, pk, &witness)
+		assert.NoError(err)
+		assert.NoError(Verify(proof, vk, &witness))
+
+		mappedProof, err := Prove(ccs, mappedPK, &witness)
+		assert.NoError(err)
+		assert.NoError(Verify(mappedProof, vk, &witness))
+
+		assert.NoError(release())
+	}
+}
+
+// BenchmarkProvingKeyLoad compares loading a ≥1M constraints proving key with ReadFrom, ReadDump and
+// MapProvingKey. The heapB/op metric is the Go heap growth caused by the load, which is what ends up
+// in the process RSS for the heap based paths.
+func BenchmarkProvingKeyLoad(b *testing.B) {
+	const nbConstraints = 1 << 20
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &dumpCircuit{nbConstraints: nbConstraints})
+	if err != nil {
+		b.Fatal(err)
+	}
+	pk, err := DummySetup(ccs)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var raw bytes.Buffer
+	if _, err := pk.WriteRawTo(&raw); err != nil {
+		b.Fatal(err)
+	}
+	path := filepath.Join(b.TempDir(), "pk.dump")
+	f, err := os.Create(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := pk.WriteDump(f); err != nil {
+		b.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		b.Fatal(err)
+	}
+	pk = nil
+
+	measure := func(b *testing.B, load func() func()) {
+		var before, after runtime.MemStats
+		var heap uint64
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			release := load()
+			runtime.ReadMemStats(&after)
+			heap += after.HeapAlloc - before.HeapAlloc
+			release()
+		}
+		b.ReportMetric(float64(heap)/float64(b.N), "heapB/op")
+	}
+
+	b.Run("ReadFrom_raw", func(b *testing.B) {
+		measure(b, func() func() {
+			pk := NewProvingKey(ecc.BN254)
+			if _, err := pk.UnsafeReadFrom(bytes.NewReader(raw.Bytes())); err != nil {
+				b.Fatal(err)
+			}
+			return func() { runtime.KeepAlive(pk) }
+		})
+	})
+
+	b.Run("ReadDump", func(b *testing.B) {
+		measure(b, func() func() {
+			f, err := os.Open(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer f.Close()
+			pk := NewProvingKey(ecc.BN254)
+			if err := pk.ReadDump(f); err != nil {
+				b.Fatal(err)
+			}
+			return func() { runtime.KeepAlive(pk) }
+		})
+	})
+
+	b.Run("MapProvingKey", func(b *testing.B) {
+		measure(b, func() func() {
+			pk, release, err := MapProvingKey(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			return func() {
+				runtime.KeepAlive(pk)
+				_ = release()
+			}
+		})
+	})
+}
@@ -0,0 +1,174 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groth16
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math/big"
+	"sort"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// MaxLabelsSize is the maximum size of the encoded labels of a wrapped proof, see WrapProof
+const MaxLabelsSize = 4096
+
+// envelopeMagic prefixes the wrapped proofs
+var envelopeMagic = []byte{'g', 'n', 'a', 'r', 'k', 'p', 'r', 'f'}
+
+var (
+	// ErrLabelsTooLarge is returned by WrapProof when the encoded labels exceed MaxLabelsSize
+	ErrLabelsTooLarge = fmt.Errorf("proof labels exceed %d bytes", MaxLabelsSize)
+
+	// ErrInvalidEnvelope is returned by UnwrapProof when the wrapped proof is malformed or corrupted
+	ErrInvalidEnvelope = errors.New("invalid proof envelope")
+)
+
+// WrapProof encodes proof with application-defined labels (circuit version, block height, ...).
+//
+// The labels are metadata only: they are NOT bound to the proof, nor covered by any cryptographic
+// check, and anyone may change them; a checksum only detects accidental corruption.
+//
+// The encoding is: magic | curveID (uint16) | labels size (uint32) | labels | crc32(labels) | proof
+// where labels are sorted by key, each key and value prefixed by its length (uint16), and proof is
+// written with Proof.WriteTo.
+func WrapProof(proof Proof, labels map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var section bytes.Buffer
+	for _, k := range keys {
+		if len(section.Bytes())+4+len(k)+len(labels[k]) > MaxLabelsSize {
+			return nil, ErrLabelsTooLarge
+		}
+		writeLabelString(&section, k)
+		writeLabelString(&section, labels[k])
+	}
+
+	var buf bytes.Buffer
+	buf.Write(envelopeMagic)
+	_ = binary.Write(&buf, binary.BigEndian, uint16(proof.CurveID()))
+	_ = binary.Write(&buf, binary.BigEndian, uint32(section.Len()))
+	buf.Write(section.Bytes())
+	_ = binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(section.Bytes()))
+	if _, err := proof.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnwrapProof decodes a proof written with WrapProof and returns it with its labels
+func UnwrapProof(b []byte) (Proof, map[string]string, error) {
+	curveID, labels, proofBytes, err := unwrap(b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proof := NewProof(curveID)
+	if _, err := proof.ReadFrom(bytes.NewReader(proofBytes)); err != nil {
+		return nil, nil, err
+	}
+	return proof, labels, nil
+}
+
+// IsWrappedProof returns true if b starts like a proof written with WrapProof
+func IsWrappedProof(b []byte) bool {
+	return bytes.HasPrefix(b, envelopeMagic)
+}
+
+// unwrap decodes the envelope and returns the curve, labels and proof bytes
+func unwrap(b []byte) (ecc.ID, map[string]string, []byte, error) {
+	if !IsWrappedProof(b) {
+		return ecc.UNKNOWN, nil, nil, fmt.Errorf("%w: missing magic", ErrInvalidEnvelope)
+	}
+	b = b[len(envelopeMagic):]
+	if len(b) < 6 {
+		return ecc.UNKNOWN, nil, nil, fmt.Errorf("%w: too short", ErrInvalidEnvelope)
+	}
+	curveID := ecc.ID(binary.BigEndian.Uint16(b[:2]))
+	size := binary.BigEndian.Uint32(b[2:6])
+	b = b[6:]
+	if size > MaxLabelsSize || int(size)+4 > len(b) {
+		return ecc.UNKNOWN, nil, nil, fmt.Errorf("%w: invalid labels size %d", ErrInvalidEnvelope, size)
+	}
+	section := b[:size]
+	if crc32.ChecksumIEEE(section) != binary.BigEndian.Uint32(b[size:size+4]) {
+		return ecc.UNKNOWN, nil, nil, fmt.Errorf("%w: labels checksum mismatch", ErrInvalidEnvelope)
+	}
+	switch curveID {
+	case ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BW6_761, ecc.BW6_633, ecc.BLS24_315:
+	default:
+		return ecc.UNKNOWN, nil, nil, fmt.Errorf("%w: unknown curve", ErrInvalidEnvelope)
+	}
+
+	labels := make(map[string]string)
+	for len(section) > 0 {
+		k, rest, ok := readLabelString(section)
+		if !ok {
+			return ecc.UNKNOWN, nil, nil, fmt.Errorf("%w: truncated label", ErrInvalidEnvelope)
+		}
+		v, rest, ok := readLabelString(rest)
+		if !ok {
+			return ecc.UNKNOWN, nil, nil, fmt.Errorf("%w: truncated label", ErrInvalidEnvelope)
+		}
+		labels[k] = v
+		section = rest
+	}
+
+	return curveID, labels, b[size+4:], nil
+}
+
+func writeLabelString(buf *bytes.Buffer, s string) {
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(s)))
+	buf.WriteString(s)
+}
+
+func readLabelString(b []byte) (string, []byte, bool) {
+	if len(b) < 2 {
+		return "", nil, false
+	}
+	n := int(binary.BigEndian.Uint16(b[:2]))
+	if len(b) < 2+n {
+		return "", nil, false
+	}
+	return string(b[2 : 2+n]), b[2+n:], true
+}
+
+// unwrappingVerifier accepts raw and wrapped proofs
+type unwrappingVerifier struct {
+	Verifier
+	curveID ecc.ID
+}
+
+func (v unwrappingVerifier) Verify(proofBytes []byte, publicInputs []*big.Int) error {
+	if IsWrappedProof(proofBytes) {
+		curveID, _, b, err := unwrap(proofBytes)
+		if err != nil {
+			return err
+		}
+		if curveID != v.curveID {
+			return fmt.Errorf("proof is on curve %s, verifying key on %s", curveID, v.curveID)
+		}
+		proofBytes = b
+	}
+	return v.Verifier.Verify(proofBytes, publicInputs)
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groth16
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/curves"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapProof(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range curves.Implemented() {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &dumpCircuit{nbConstraints: 3})
+		assert.NoError(err)
+
+		pk, vk, err := Setup(ccs)
+		assert.NoError(err)
+
+		var witness dumpCircuit
+		witness.X.Assign(2)
+		witness.Y.Assign(16)
+
+		proof, err := Prove(ccs, pk, &witness)
+		assert.NoError(err)
+
+		// round trip
+		labels := map[string]string{"circuit": "transfer-v3", "block": "1234567", "empty": ""}
+		wrapped, err := WrapProof(proof, labels)
+		assert.NoError(err)
+		assert.True(IsWrappedProof(wrapped))
+
+		_proof, _labels, err := UnwrapProof(wrapped)
+		assert.NoError(err)
+		assert.Equal(labels, _labels)
+		assert.Equal(proof, _proof, "%s", curve)
+		assert.NoError(Verify(_proof, vk, &witness))
+
+		// the encoding is deterministic
+		again, err := WrapProof(proof, labels)
+		assert.NoError(err)
+		assert.Equal(wrapped, again)
+
+		// no labels
+		wrapped, err = WrapProof(proof, nil)
+		assert.NoError(err)
+		_, _labels, err = UnwrapProof(wrapped)
+		assert.NoError(err)
+		assert.Empty(_labels)
+
+		// the verifier sniffs the magic and accepts raw and wrapped proofs
+		var vkBuf, proofBuf bytes.Buffer
+		_, err = vk.WriteCompactTo(&vkBuf)
+		assert.NoError(err)
+		_, err = proof.WriteTo(&proofBuf)
+		assert.NoError(err)
+		assert.False(IsWrappedProof(proofBuf.Bytes()))
+
+		verifier, err := NewVerifier(vkBuf.Bytes())
+		assert.NoError(err)
+		publicInputs := []*big.Int{big.NewInt(16)}
+		assert.NoError(verifier.Verify(proofBuf.Bytes(), publicInputs), "%s", curve)
+		assert.NoError(verifier.Verify(wrapped, publicInputs), "%s", curve)
+		assert.Error(verifier.Verify(wrapped, []*big.Int{big.NewInt(17)}))
+	}
+}
+
+func TestWrapProofLabels(t *testing.T) {
+	assert := require.New(t)
+
+	proof := NewProof(ecc.BN254)
+
+	// 4 bytes of lengths + 4092 bytes of data == MaxLabelsSize
+	_, err := WrapProof(proof, map[string]string{"k": strings.Repeat("v", MaxLabelsSize-5)})
+	assert.NoError(err)
+	_, err = WrapProof(proof, map[string]string{"k": strings.Repeat("v", MaxLabelsSize-4)})
+	assert.True(errors.Is(err, ErrLabelsTooLarge), err)
+	_, err = WrapProof(proof, map[string]string{"a": strings.Repeat("v", 3000), "b": strings.Repeat("v", 3000)})
+	assert.True(errors.Is(err, ErrLabelsTooLarge), err)
+
+	wrapped, err := WrapProof(proof, map[string]string{"height": "42"})
+	assert.NoError(err)
+	offset := len(envelopeMagic) + 6 // labels section
+
+	// corrupted labels are detected
+	for i := offset; i < offset+2+len("height")+2+len("42"); i++ {
+		corrupted := append([]byte{}, wrapped...)
+		corrupted[i] ^= 1
+		_, _, err = UnwrapProof(corrupted)
+		assert.True(errors.Is(err, ErrInvalidEnvelope), err)
+	}
+
+	// as well as invalid sizes, truncated envelopes and unknown curves
+	corrupted := append([]byte{}, wrapped...)
+	corrupted[offset-1] ^= 1
+	_, _, err = UnwrapProof(corrupted)
+	assert.True(errors.Is(err, ErrInvalidEnvelope), err)
+
+	_, _, err = UnwrapProof(wrapped[:offset+3])
+	assert.True(errors.Is(err, ErrInvalidEnvelope), err)
+
+	corrupted = append([]byte{}, wrapped...)
+	corrupted[len(envelopeMagic)] = 0xff
+	_, _, err = UnwrapProof(corrupted)
+	assert.True(errors.Is(err, ErrInvalidEnvelope), err)
+
+	_, _, err = UnwrapProof(wrapped[len(envelopeMagic):])
+	assert.True(errors.Is(err, ErrInvalidEnvelope), err)
+
+	// a truncated proof is rejected by the proof decoder
+	_, _, err = UnwrapProof(wrapped[:len(wrapped)-1])
+	assert.Error(err)
+}
@@ -14,29 +14,42 @@
 
 // Package groth16 implements Groth16 Zero Knowledge Proof system  (aka zkSNARK).
 //
+// Programs which only verify proofs on one curve can import the verifier-only package of that curve
+// instead (bn254verifier, bls12381verifier, ...): it reads the keys, proofs and public witnesses written
+// by this package, and doesn't link the frontend, the prover, nor the other curves.
+//
 // See also
 //
 // https://eprint.iacr.org/2016/260.pdf
 package groth16
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
 
 	"github.com/consensys/gnark-crypto/ecc"
 
 	"github.com/consensys/gnark/backend"
+	gnarkwitness "github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/compiled"
+
 	backend_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
 	backend_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
 	backend_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
 	backend_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
 	backend_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+	backend_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
 
 	witness_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/witness"
 	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
 	witness_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/witness"
 	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
 	witness_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/witness"
+	witness_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/witness"
 
 	gnarkio "github.com/consensys/gnark/io"
 
@@ -45,6 +58,7 @@ import (
 	groth16_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/groth16"
 	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
 	groth16_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/groth16"
+	groth16_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/groth16"
 )
 
 // TODO @gbotrel document hint functions here and in assert
@@ -61,6 +75,14 @@ type groth16Object interface {
 // it's underlying implementation is curve specific (see gnark/internal/backend)
 type Proof interface {
 	groth16Object
+
+	// MarshalCBOR returns the canonical CBOR encoding of the proof, a map of its named components
+	// (compressed points, big endian field elements) after a header with the type and the curve
+	MarshalCBOR() ([]byte, error)
+
+	// UnmarshalCBOR decodes a proof encoded with MarshalCBOR, it fails with backend.ErrCBORHeaderMismatch
+	// if data is not a proof of this type and curve
+	UnmarshalCBOR(data []byte) error
 }
 
 // ProvingKey represents a Groth16 ProvingKey
@@ -76,7 +98,45 @@ type ProvingKey interface {
 	// NbG2 returns the number of G2 elements in the ProvingKey
 	NbG2() int
 
+	// WriteDump writes the ProvingKey in a raw, non-portable layout that can be memory mapped
+	// (see MapProvingKey)
+	WriteDump(w io.Writer) error
+
+	// ReadDump reads a ProvingKey written with WriteDump
+	ReadDump(r io.Reader) error
+
+	// WriteSplitTo writes the ProvingKey as WriteTo, in the directory dir, in chunk files of at most chunkSize
+	// bytes followed by their manifest (size and SHA-256 of each chunk and of the whole), for the file stores
+	// which limit the size of the objects. See gnarkio.WriteSplitTo, which splits any serialization (the
+	// PLONK SRS for instance)
+	WriteSplitTo(dir string, chunkSize int64) (int64, error)
+
+	// ReadSplitFrom reads a ProvingKey written with WriteSplitTo, streaming the chunks through ReadFrom. It
+	// returns a *gnarkio.ChunkError with the index of the first missing or corrupted chunk
+	ReadSplitFrom(dir string) (int64, error)
+
+	// UnsafeReadSplitFrom is ReadSplitFrom through UnsafeReadFrom
+	UnsafeReadSplitFrom(dir string) (int64, error)
+
+	// Precompute builds and caches on the key the tables of the fixed-base multi exponentiations of Prove in G1,
+	// with windows of windowSize bits. Prove uses them and returns the same proofs, faster, at the cost of
+	// PrecomputeSize(windowSize) bytes of memory: about (fr bits / windowSize + 1) times the G1 points of the key.
+	// Windows of 12 to 18 bits pay off on large circuits, 16 bits being the fastest.
+	Precompute(windowSize int) error
+
+	// PrecomputeSize returns the size in bytes of the tables of Precompute with the given window size
+	PrecomputeSize(windowSize int) int64
+
+	// WritePrecomputedTo writes the tables of Precompute, to be read with ReadPrecomputedFrom on the same key
+	WritePrecomputedTo(w io.Writer) (int64, error)
+
+	// ReadPrecomputedFrom reads tables written with WritePrecomputedTo, in place of Precompute
+	ReadPrecomputedFrom(r io.Reader) (int64, error)
+
 	IsDifferent(interface{}) bool
+
+	// GetMetadata returns the metadata of the constraint system the key was generated for
+	GetMetadata() map[string]string
 }
 
 // VerifyingKey represents a Groth16 VerifyingKey
@@ -98,10 +158,27 @@ type VerifyingKey interface {
 	NbG2() int
 
 	// ExportSolidity writes a solidity Verifier contract from the VerifyingKey
-	// this will return an error if not supported on the CurveID()
-	ExportSolidity(w io.Writer) error
+	// this will return an error if not supported on the CurveID(), or if the circuit has too many
+	// public inputs (see backend.WithMaxPublicInputs)
+	ExportSolidity(w io.Writer, opts ...func(opt *backend.SetupOption) error) error
+
+	// WriteCompactTo writes the VerifyingKey in the compact format consumed by NewVerifier
+	WriteCompactTo(w io.Writer) (int64, error)
+
+	// Precompute computes and caches the values Verify derives from the VerifyingKey, such that
+	// the verifications don't compute them. It is idempotent and safe for concurrent use.
+	Precompute() error
+
+	// WritePreparedTo writes the VerifyingKey with its precomputation
+	WritePreparedTo(w io.Writer) (int64, error)
+
+	// ReadPreparedFrom reads a VerifyingKey written with WritePreparedTo, ready to verify
+	ReadPreparedFrom(r io.Reader) (int64, error)
 
 	IsDifferent(interface{}) bool
+
+	// GetMetadata returns the metadata of the constraint system the key was generated for
+	GetMetadata() map[string]string
 }
 
 // Verify runs the groth16.Verify algorithm on provided proof with given witness
@@ -132,6 +209,12 @@ func Verify(proof Proof, vk VerifyingKey, publicWitness frontend.Circuit) error
 			return err
 		}
 		return groth16_bw6761.Verify(_proof, vk.(*groth16_bw6761.VerifyingKey), w)
+	case *groth16_bw6633.Proof:
+		w := witness_bw6633.Witness{}
+		if err := w.FromPublicAssignment(publicWitness); err != nil {
+			return err
+		}
+		return groth16_bw6633.Verify(_proof, vk.(*groth16_bw6633.VerifyingKey), w)
 	case *groth16_bls24315.Proof:
 		w := witness_bls24315.Witness{}
 		if err := w.FromPublicAssignment(publicWitness); err != nil {
@@ -173,6 +256,12 @@ func ReadAndVerify(proof Proof, vk VerifyingKey, publicWitness io.Reader) error
 			return err
 		}
 		return groth16_bw6761.Verify(proof.(*groth16_bw6761.Proof), _vk, w)
+	case *groth16_bw6633.VerifyingKey:
+		w := witness_bw6633.Witness{}
+		if _, err := w.LimitReadFrom(publicWitness, vk.NbPublicWitness()); err != nil {
+			return err
+		}
+		return groth16_bw6633.Verify(proof.(*groth16_bw6633.Proof), _vk, w)
 	case *groth16_bls24315.VerifyingKey:
 		w := witness_bls24315.Witness{}
 		if _, err := w.LimitReadFrom(publicWitness, vk.NbPublicWitness()); err != nil {
@@ -198,40 +287,68 @@ func Prove(r1cs frontend.CompiledConstraintSystem, pk ProvingKey, witness fronte
 		return nil, err
 	}
 
+	if !compiled.EqualMetadata(r1cs.GetMetadata(), pk.GetMetadata()) {
+		return nil, backend.ErrMetadataMismatch
+	}
+	if err := gnarkwitness.CheckFrVector(witness, r1cs); err != nil {
+		return nil, err
+	}
+
+	var proof Proof
 	switch _r1cs := r1cs.(type) {
 	case *backend_bls12377.R1CS:
 		w := witness_bls12377.Witness{}
 		if err := w.FromFullAssignment(witness); err != nil {
 			return nil, err
 		}
-		return groth16_bls12377.Prove(_r1cs, pk.(*groth16_bls12377.ProvingKey), w, opt)
+		proof, err = groth16_bls12377.Prove(_r1cs, pk.(*groth16_bls12377.ProvingKey), w, opt)
 	case *backend_bls12381.R1CS:
 		w := witness_bls12381.Witness{}
 		if err := w.FromFullAssignment(witness); err != nil {
 			return nil, err
 		}
-		return groth16_bls12381.Prove(_r1cs, pk.(*groth16_bls12381.ProvingKey), w, opt)
+		proof, err = groth16_bls12381.Prove(_r1cs, pk.(*groth16_bls12381.ProvingKey), w, opt)
 	case *backend_bn254.R1CS:
 		w := witness_bn254.Witness{}
 		if err := w.FromFullAssignment(witness); err != nil {
 			return nil, err
 		}
-		return groth16_bn254.Prove(_r1cs, pk.(*groth16_bn254.ProvingKey), w, opt)
+		proof, err = groth16_bn254.Prove(_r1cs, pk.(*groth16_bn254.ProvingKey), w, opt)
 	case *backend_bw6761.R1CS:
 		w := witness_bw6761.Witness{}
 		if err := w.FromFullAssignment(witness); err != nil {
 			return nil, err
 		}
-		return groth16_bw6761.Prove(_r1cs, pk.(*groth16_bw6761.ProvingKey), w, opt)
+		proof, err = groth16_bw6761.Prove(_r1cs, pk.(*groth16_bw6761.ProvingKey), w, opt)
+	case *backend_bw6633.R1CS:
+		w := witness_bw6633.Witness{}
+		if err := w.FromFullAssignment(witness); err != nil {
+			return nil, err
+		}
+		proof, err = groth16_bw6633.Prove(_r1cs, pk.(*groth16_bw6633.ProvingKey), w, opt)
 	case *backend_bls24315.R1CS:
 		w := witness_bls24315.Witness{}
 		if err := w.FromFullAssignment(witness); err != nil {
 			return nil, err
 		}
-		return groth16_bls24315.Prove(_r1cs, pk.(*groth16_bls24315.ProvingKey), w, opt)
+		proof, err = groth16_bls24315.Prove(_r1cs, pk.(*groth16_bls24315.ProvingKey), w, opt)
 	default:
 		panic("unrecognized R1CS curve type")
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opt.SelfCheck {
+		verify := func(vk VerifyingKey) error {
+			return Verify(proof, vk, witness)
+		}
+		if err := selfCheck(pk, opt, verify); err != nil {
+			return nil, err
+		}
+	}
+
+	return proof, nil
 }
 
 // ReadAndProve behaves like Prove, , except witness is read from a io.Reader
@@ -245,43 +362,116 @@ func ReadAndProve(r1cs frontend.CompiledConstraintSystem, pk ProvingKey, witness
 		return nil, err
 	}
 
+	if !compiled.EqualMetadata(r1cs.GetMetadata(), pk.GetMetadata()) {
+		return nil, backend.ErrMetadataMismatch
+	}
+
 	_, nbSecret, nbPublic := r1cs.GetNbVariables()
 	expectedSize := (nbSecret + nbPublic - 1)
+	nbOptional := compiled.NbTrailingOptional(r1cs.GetOptionalSecrets(), nbSecret)
+
+	// the self-check needs the public part of the witness
+	var fullWitness bytes.Buffer
+	if opt.SelfCheck {
+		witness = io.TeeReader(witness, &fullWitness)
+	}
 
+	var proof Proof
 	switch _r1cs := r1cs.(type) {
 	case *backend_bls12377.R1CS:
 		w := witness_bls12377.Witness{}
-		if _, err := w.LimitReadFrom(witness, expectedSize); err != nil {
+		if _, err := w.LimitReadFromOptional(witness, expectedSize, nbOptional); err != nil {
 			return nil, err
 		}
-		return groth16_bls12377.Prove(_r1cs, pk.(*groth16_bls12377.ProvingKey), w, opt)
+		proof, err = groth16_bls12377.Prove(_r1cs, pk.(*groth16_bls12377.ProvingKey), w, opt)
 	case *backend_bls12381.R1CS:
 		w := witness_bls12381.Witness{}
-		if _, err := w.LimitReadFrom(witness, expectedSize); err != nil {
+		if _, err := w.LimitReadFromOptional(witness, expectedSize, nbOptional); err != nil {
 			return nil, err
 		}
-		return groth16_bls12381.Prove(_r1cs, pk.(*groth16_bls12381.ProvingKey), w, opt)
+		proof, err = groth16_bls12381.Prove(_r1cs, pk.(*groth16_bls12381.ProvingKey), w, opt)
 	case *backend_bn254.R1CS:
 		w := witness_bn254.Witness{}
-		if _, err := w.LimitReadFrom(witness, expectedSize); err != nil {
+		if _, err := w.LimitReadFromOptional(witness, expectedSize, nbOptional); err != nil {
 			return nil, err
 		}
-		return groth16_bn254.Prove(_r1cs, pk.(*groth16_bn254.ProvingKey), w, opt)
+		proof, err = groth16_bn254.Prove(_r1cs, pk.(*groth16_bn254.ProvingKey), w, opt)
 	case *backend_bw6761.R1CS:
 		w := witness_bw6761.Witness{}
-		if _, err := w.LimitReadFrom(witness, expectedSize); err != nil {
+		if _, err := w.LimitReadFromOptional(witness, expectedSize, nbOptional); err != nil {
+			return nil, err
+		}
+		proof, err = groth16_bw6761.Prove(_r1cs, pk.(*groth16_bw6761.ProvingKey), w, opt)
+	case *backend_bw6633.R1CS:
+		w := witness_bw6633.Witness{}
+		if _, err := w.LimitReadFromOptional(witness, expectedSize, nbOptional); err != nil {
 			return nil, err
 		}
-		return groth16_bw6761.Prove(_r1cs, pk.(*groth16_bw6761.ProvingKey), w, opt)
+		proof, err = groth16_bw6633.Prove(_r1cs, pk.(*groth16_bw6633.ProvingKey), w, opt)
 	case *backend_bls24315.R1CS:
 		w := witness_bls24315.Witness{}
-		if _, err := w.LimitReadFrom(witness, expectedSize); err != nil {
+		if _, err := w.LimitReadFromOptional(witness, expectedSize, nbOptional); err != nil {
 			return nil, err
 		}
-		return groth16_bls24315.Prove(_r1cs, pk.(*groth16_bls24315.ProvingKey), w, opt)
+		proof, err = groth16_bls24315.Prove(_r1cs, pk.(*groth16_bls24315.ProvingKey), w, opt)
 	default:
 		panic("unrecognized R1CS curve type")
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opt.SelfCheck {
+		verify := func(vk VerifyingKey) error {
+			return ReadAndVerify(proof, vk, bytes.NewReader(publicWitness(fullWitness.Bytes(), nbPublic-1)))
+		}
+		if err := selfCheck(pk, opt, verify); err != nil {
+			return nil, err
+		}
+	}
+
+	return proof, nil
+}
+
+// WithVerifyingKey is a Prover option that sets the verifying key used to check the proof, see backend.WithSelfCheck
+func WithVerifyingKey(vk VerifyingKey) func(opt *backend.ProverOption) error {
+	return func(opt *backend.ProverOption) error {
+		opt.VerifyingKey = vk
+		return nil
+	}
+}
+
+// selfCheck runs verify against the verifying key set by WithVerifyingKey; the Groth16 verifying key
+// can't be derived from the proving key
+func selfCheck(pk ProvingKey, opt backend.ProverOption, verify func(vk VerifyingKey) error) error {
+	if opt.VerifyingKey == nil {
+		return errors.New("self-check: no verifying key, see groth16.WithVerifyingKey")
+	}
+	vk, ok := opt.VerifyingKey.(VerifyingKey)
+	if !ok {
+		return fmt.Errorf("self-check: %T is not a groth16 verifying key", opt.VerifyingKey)
+	}
+	if vk.CurveID() != pk.CurveID() {
+		return fmt.Errorf("self-check: verifying key on %s, proving key on %s", vk.CurveID(), pk.CurveID())
+	}
+	if err := verify(vk); err != nil {
+		return fmt.Errorf("%w: %v", backend.ErrSelfCheckFailed, err)
+	}
+	return nil
+}
+
+// publicWitness returns the public part, of size nbPublic, of a full witness encoded
+// with the binary serialization protocol of the gnark/backend/witness package
+func publicWitness(fullWitness []byte, nbPublic int) []byte {
+	nbElements := int(binary.BigEndian.Uint32(fullWitness[:4]))
+	if nbElements == 0 {
+		return fullWitness
+	}
+	elementSize := (len(fullWitness) - 4) / nbElements
+
+	r := make([]byte, 4, 4+nbPublic*elementSize)
+	binary.BigEndian.PutUint32(r, uint32(nbPublic))
+	return append(r, fullWitness[4:4+nbPublic*elementSize]...)
 }
 
 // Setup runs groth16.Setup with provided R1CS and outputs a key pair associated with the circuit.
@@ -292,7 +482,22 @@ func ReadAndProve(r1cs frontend.CompiledConstraintSystem, pk ProvingKey, witness
 //
 // Two main solutions to this deployment issues are: running the Setup through a MPC (multi party computation)
 // or using a ZKP backend like PLONK where the per-circuit Setup is deterministic.
-func Setup(r1cs frontend.CompiledConstraintSystem) (ProvingKey, VerifyingKey, error) {
+//
+// With backend.WithMaxPublicInputs, it fails with backend.ErrTooManyPublicInputs if the circuit has more
+// public inputs than allowed.
+func Setup(r1cs frontend.CompiledConstraintSystem, opts ...func(opt *backend.SetupOption) error) (ProvingKey, VerifyingKey, error) {
+
+	// apply options
+	opt, err := backend.NewSetupOption(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the public variables of a R1CS include the constant wire ONE
+	_, _, nbPublic := r1cs.GetNbVariables()
+	if err := backend.CheckPublicInputs(nbPublic-1, opt.MaxPublicInputs); err != nil {
+		return nil, nil, err
+	}
 
 	switch _r1cs := r1cs.(type) {
 	case *backend_bls12377.R1CS:
@@ -323,6 +528,13 @@ func Setup(r1cs frontend.CompiledConstraintSystem) (ProvingKey, VerifyingKey, er
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
+	case *backend_bw6633.R1CS:
+		var pk groth16_bw6633.ProvingKey
+		var vk groth16_bw6633.VerifyingKey
+		if err := groth16_bw6633.Setup(_r1cs, &pk, &vk); err != nil {
+			return nil, nil, err
+		}
+		return &pk, &vk, nil
 	case *backend_bls24315.R1CS:
 		var pk groth16_bls24315.ProvingKey
 		var vk groth16_bls24315.VerifyingKey
@@ -363,6 +575,12 @@ func DummySetup(r1cs frontend.CompiledConstraintSystem) (ProvingKey, error) {
 			return nil, err
 		}
 		return &pk, nil
+	case *backend_bw6633.R1CS:
+		var pk groth16_bw6633.ProvingKey
+		if err := groth16_bw6633.DummySetup(_r1cs, &pk); err != nil {
+			return nil, err
+		}
+		return &pk, nil
 	case *backend_bls24315.R1CS:
 		var pk groth16_bls24315.ProvingKey
 		if err := groth16_bls24315.DummySetup(_r1cs, &pk); err != nil {
@@ -387,6 +605,8 @@ func NewProvingKey(curveID ecc.ID) ProvingKey {
 		pk = &groth16_bls12381.ProvingKey{}
 	case ecc.BW6_761:
 		pk = &groth16_bw6761.ProvingKey{}
+	case ecc.BW6_633:
+		pk = &groth16_bw6633.ProvingKey{}
 	case ecc.BLS24_315:
 		pk = &groth16_bls24315.ProvingKey{}
 	default:
@@ -408,6 +628,8 @@ func NewVerifyingKey(curveID ecc.ID) VerifyingKey {
 		vk = &groth16_bls12381.VerifyingKey{}
 	case ecc.BW6_761:
 		vk = &groth16_bw6761.VerifyingKey{}
+	case ecc.BW6_633:
+		vk = &groth16_bw6633.VerifyingKey{}
 	case ecc.BLS24_315:
 		vk = &groth16_bls24315.VerifyingKey{}
 	default:
@@ -430,6 +652,8 @@ func NewProof(curveID ecc.ID) Proof {
 		proof = &groth16_bls12381.Proof{}
 	case ecc.BW6_761:
 		proof = &groth16_bw6761.Proof{}
+	case ecc.BW6_633:
+		proof = &groth16_bw6633.Proof{}
 	case ecc.BLS24_315:
 		proof = &groth16_bls24315.Proof{}
 	default:
@@ -452,6 +676,8 @@ func NewCS(curveID ecc.ID) frontend.CompiledConstraintSystem {
 		r1cs = &backend_bls12381.R1CS{}
 	case ecc.BW6_761:
 		r1cs = &backend_bw6761.R1CS{}
+	case ecc.BW6_633:
+		r1cs = &backend_bw6633.R1CS{}
 	case ecc.BLS24_315:
 		r1cs = &backend_bls24315.R1CS{}
 	default:
@@ -469,6 +695,9 @@ func IsSolved(r1cs frontend.CompiledConstraintSystem, witness frontend.Circuit,
 	if err != nil {
 		return err
 	}
+	if err := gnarkwitness.CheckFrVector(witness, r1cs); err != nil {
+		return err
+	}
 
 	switch _r1cs := r1cs.(type) {
 	case *backend_bls12377.R1CS:
@@ -495,6 +724,12 @@ func IsSolved(r1cs frontend.CompiledConstraintSystem, witness frontend.Circuit,
 			return err
 		}
 		return _r1cs.IsSolved(w, opt)
+	case *backend_bw6633.R1CS:
+		w := witness_bw6633.Witness{}
+		if err := w.FromFullAssignment(witness); err != nil {
+			return err
+		}
+		return _r1cs.IsSolved(w, opt)
 	case *backend_bls24315.R1CS:
 		w := witness_bls24315.Witness{}
 		if err := w.FromFullAssignment(witness); err != nil {
@@ -0,0 +1,109 @@
+package groth16
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16/bn254verifier"
+	"github.com/consensys/gnark/frontend"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/stretchr/testify/require"
+)
+
+// legacyCircuit is the circuit of the keys in testdata/groth16_*_bn254.bin, written
+// on BN254 by gnark before the keys carried the metadata of the constraint system
+type legacyCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *legacyCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(c.Y, api.Add(api.Mul(c.X, c.X, c.X), c.X, 5))
+	return nil
+}
+
+type key interface {
+	io.ReaderFrom
+	io.WriterTo
+}
+
+func TestReadLegacyKeys(t *testing.T) {
+	assert := require.New(t)
+	trailer := []byte("data following the key")
+
+	for _, tc := range []struct {
+		file string
+		key  func() key
+	}{
+		{"groth16_vk_bn254.bin", func() key { return NewVerifyingKey(ecc.BN254) }},
+		{"groth16_pk_bn254.bin", func() key { return NewProvingKey(ecc.BN254) }},
+	} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", tc.file))
+		assert.NoError(err)
+
+		k := tc.key()
+		n, err := k.ReadFrom(bytes.NewReader(data))
+		assert.NoError(err, tc.file)
+		assert.EqualValues(len(data), n, tc.file)
+
+		// without metadata, the key is written back in the legacy format
+		var buf bytes.Buffer
+		_, err = k.WriteTo(&buf)
+		assert.NoError(err, tc.file)
+		assert.Equal(data, buf.Bytes(), tc.file)
+
+		// the data following the key is left to the next reader of the LimitReader
+		lr := gnarkio.NewLimitReader(bytes.NewReader(append(data, trailer...)))
+		n, err = tc.key().ReadFrom(lr)
+		assert.NoError(err, tc.file)
+		assert.EqualValues(len(data), n, tc.file)
+		rest, err := ioutil.ReadAll(lr)
+		assert.NoError(err, tc.file)
+		assert.Equal(trailer, rest, tc.file)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "groth16_vk_bn254.bin"))
+	assert.NoError(err)
+	vk, err := bn254verifier.ReadVerifyingKey(bytes.NewReader(data))
+	assert.NoError(err)
+	assert.Nil(vk.Metadata)
+}
+
+func TestKeysMetadataSection(t *testing.T) {
+	assert := require.New(t)
+	trailer := []byte("data following the key")
+	metadata := map[string]string{"name": "legacy"}
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &legacyCircuit{}, frontend.WithMetadata(metadata))
+	assert.NoError(err)
+	pk, vk, err := Setup(ccs)
+	assert.NoError(err)
+
+	for _, tc := range []struct {
+		k    key
+		read func() key
+	}{
+		{vk, func() key { return NewVerifyingKey(ecc.BN254) }},
+		{pk, func() key { return NewProvingKey(ecc.BN254) }},
+	} {
+		var buf bytes.Buffer
+		written, err := tc.k.WriteTo(&buf)
+		assert.NoError(err)
+		buf.Write(trailer)
+
+		lr := gnarkio.NewLimitReader(&buf)
+		k := tc.read()
+		n, err := k.ReadFrom(lr)
+		assert.NoError(err)
+		assert.Equal(written, n)
+		assert.Equal(metadata, k.(interface{ GetMetadata() map[string]string }).GetMetadata())
+		rest, err := ioutil.ReadAll(lr)
+		assert.NoError(err)
+		assert.Equal(trailer, rest)
+	}
+}
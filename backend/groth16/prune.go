@@ -0,0 +1,212 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groth16
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	groth16_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/groth16"
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+	groth16_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	groth16_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/groth16"
+	groth16_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/groth16"
+)
+
+// PublicInputsFixing records the public inputs fixed by PrunePublicInputs, for audit purposes,
+// and is needed by ProvePruned and VerifyPruned to map the circuit inputs to the pruned keys.
+type PublicInputsFixing struct {
+	Curve    ecc.ID       `json:"curve"`
+	NbPublic int          `json:"nbPublic"` // number of public inputs (without the ONE_WIRE) of the original VerifyingKey
+	Fixed    []FixedInput `json:"fixed"`    // sorted by index
+}
+
+// FixedInput is a public input pinned to a value, see PublicInputsFixing
+type FixedInput struct {
+	Index int      `json:"index"` // index in the public witness, without the ONE_WIRE
+	Value *big.Int `json:"value"` // reduced modulo the scalar field
+}
+
+// PrunePublicInputs fixes the public inputs at the given indexes (in the public witness, without the ONE_WIRE)
+// to the given values: their [Kvk]1 points are folded into the constant term of the VerifyingKey.
+//
+// The returned VerifyingKey is smaller, and expects the remaining public inputs only (see VerifyPruned);
+// it accepts a proof if and only if the original VerifyingKey accepts it with the fixed inputs set to
+// their values. The Groth16 ProvingKey doesn't depend on which wires are public, hence pk is returned as is,
+// and proofs are computed by ProvePruned against the full witness.
+func PrunePublicInputs(pk ProvingKey, vk VerifyingKey, fixed map[int]*big.Int) (ProvingKey, VerifyingKey, *PublicInputsFixing, error) {
+	if pk.CurveID() != vk.CurveID() {
+		return nil, nil, nil, errors.New("proving key and verifying key are on different curves")
+	}
+	curveID := vk.CurveID()
+	modulus := curveID.Info().Fr.Modulus()
+
+	fixing := &PublicInputsFixing{
+		Curve:    curveID,
+		NbPublic: vk.NbPublicWitness(),
+		Fixed:    make([]FixedInput, 0, len(fixed)),
+	}
+	reduced := make(map[int]*big.Int, len(fixed))
+	for i, v := range fixed {
+		if v == nil {
+			return nil, nil, nil, fmt.Errorf("public input %d: nil value", i)
+		}
+		reduced[i] = new(big.Int).Mod(v, modulus)
+		fixing.Fixed = append(fixing.Fixed, FixedInput{Index: i, Value: reduced[i]})
+	}
+	sort.Slice(fixing.Fixed, func(i, j int) bool { return fixing.Fixed[i].Index < fixing.Fixed[j].Index })
+
+	var (
+		pruned VerifyingKey
+		err    error
+	)
+	switch _vk := vk.(type) {
+	case *groth16_bls12377.VerifyingKey:
+		pruned, err = _vk.FixPublicInputs(reduced)
+	case *groth16_bls12381.VerifyingKey:
+		pruned, err = _vk.FixPublicInputs(reduced)
+	case *groth16_bn254.VerifyingKey:
+		pruned, err = _vk.FixPublicInputs(reduced)
+	case *groth16_bw6761.VerifyingKey:
+		pruned, err = _vk.FixPublicInputs(reduced)
+	case *groth16_bw6633.VerifyingKey:
+		pruned, err = _vk.FixPublicInputs(reduced)
+	case *groth16_bls24315.VerifyingKey:
+		pruned, err = _vk.FixPublicInputs(reduced)
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return pk, pruned, fixing, nil
+}
+
+// ProvePruned behaves like Prove, for the keys returned by PrunePublicInputs.
+//
+// The fixed public inputs of the witness may be left unassigned, in which case their pinned value is used;
+// if they are assigned to a different value, no proof is produced and an error is returned.
+func ProvePruned(r1cs frontend.CompiledConstraintSystem, pk ProvingKey, fixing *PublicInputsFixing, fullWitness frontend.Circuit, opts ...func(opt *backend.ProverOption) error) (Proof, error) {
+	restore, err := fixing.assign(fullWitness)
+	if err != nil {
+		return nil, err
+	}
+	defer restore()
+
+	return Prove(r1cs, pk, fullWitness, opts...)
+}
+
+// VerifyPruned behaves like Verify, for the keys returned by PrunePublicInputs: only the remaining
+// public inputs are given to the verifier.
+//
+// The fixed public inputs of the public witness may be left unassigned; if they are assigned,
+// they must hold their pinned value.
+func VerifyPruned(proof Proof, vk VerifyingKey, fixing *PublicInputsFixing, publicWitness frontend.Circuit) error {
+	restore, err := fixing.assign(publicWitness)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	var buf bytes.Buffer
+	if _, err := witness.WritePublicTo(&buf, fixing.Curve, publicWitness); err != nil {
+		return err
+	}
+	reduced, err := fixing.prune(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return ReadAndVerify(proof, vk, bytes.NewReader(reduced))
+}
+
+// assign sets the unassigned fixed public inputs of circuit to their pinned value, and checks the
+// assigned ones hold it. The returned function unsets the inputs assigned by this call.
+func (fixing *PublicInputsFixing) assign(circuit frontend.Circuit) (restore func(), err error) {
+	schema, err := frontend.ParseSchema(circuit)
+	if err != nil {
+		return nil, err
+	}
+	if schema.NbPublic != fixing.NbPublic {
+		return nil, fmt.Errorf("circuit has %d public inputs, the fixing expects %d", schema.NbPublic, fixing.NbPublic)
+	}
+	modulus := fixing.Curve.Info().Fr.Modulus()
+
+	var assigned []*frontend.Variable
+	restore = func() {
+		for _, v := range assigned {
+			v.WitnessValue = nil
+		}
+	}
+
+	publicIdx, j := 0, 0
+	err = schema.Visit(circuit, func(f *frontend.Field, v *frontend.Variable) error {
+		if f.Visibility != frontend.Public {
+			return nil
+		}
+		defer func() { publicIdx++ }()
+		if j == len(fixing.Fixed) || fixing.Fixed[j].Index != publicIdx {
+			return nil
+		}
+		pinned := fixing.Fixed[j].Value
+		j++
+		if v.WitnessValue == nil {
+			v.WitnessValue = new(big.Int).Set(pinned)
+			assigned = append(assigned, v)
+			return nil
+		}
+		value := frontend.FromInterface(v.WitnessValue)
+		if value.Mod(&value, modulus).Cmp(pinned) != 0 {
+			return fmt.Errorf("public input %s is fixed to %s, got %s", f.Name, pinned.String(), value.String())
+		}
+		return nil
+	})
+	if err != nil {
+		restore()
+		return nil, err
+	}
+	return restore, nil
+}
+
+// prune removes the fixed inputs from a public witness encoded with the binary protocol of
+// the witness package
+func (fixing *PublicInputsFixing) prune(publicWitness []byte) ([]byte, error) {
+	elementSize := (fixing.Curve.Info().Fr.Modulus().BitLen() + 7) / 8
+	if len(publicWitness) != 4+fixing.NbPublic*elementSize {
+		return nil, errors.New("invalid public witness size")
+	}
+
+	r := make([]byte, 4, len(publicWitness)-len(fixing.Fixed)*elementSize)
+	binary.BigEndian.PutUint32(r, uint32(fixing.NbPublic-len(fixing.Fixed)))
+	elements, j := publicWitness[4:], 0
+	for i := 0; i < fixing.NbPublic; i++ {
+		if j < len(fixing.Fixed) && fixing.Fixed[j].Index == i {
+			j++
+			continue
+		}
+		r = append(r, elements[i*elementSize:(i+1)*elementSize]...)
+	}
+	return r, nil
+}
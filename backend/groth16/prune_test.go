@@ -0,0 +1,100 @@
+package groth16
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/curves"
+	"github.com/stretchr/testify/require"
+)
+
+// reservedCircuit has a public input Reserved, which is always zero in a deployment
+type reservedCircuit struct {
+	X        frontend.Variable
+	Y        frontend.Variable `gnark:",public"`
+	Reserved frontend.Variable `gnark:",public"`
+}
+
+func (circuit *reservedCircuit) Define(curveID ecc.ID, cs frontend.API) error {
+	cs.AssertIsEqual(cs.Mul(circuit.X, circuit.X), cs.Add(circuit.Y, circuit.Reserved))
+	return nil
+}
+
+func TestPrunePublicInputs(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range curves.Implemented() {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &reservedCircuit{})
+		assert.NoError(err)
+
+		pk, vk, err := Setup(ccs)
+		assert.NoError(err)
+
+		// fix Reserved (the second public input) to 0
+		pk, prunedVk, fixing, err := PrunePublicInputs(pk, vk, map[int]*big.Int{1: big.NewInt(0)})
+		assert.NoError(err)
+		assert.Equal(1, prunedVk.NbPublicWitness())
+		assert.Equal(vk.NbG1()-1, prunedVk.NbG1())
+		assert.Equal([]FixedInput{{Index: 1, Value: big.NewInt(0)}}, fixing.Fixed)
+
+		// the fixing is recorded for audit
+		record, err := json.Marshal(fixing)
+		assert.NoError(err)
+		var decoded PublicInputsFixing
+		assert.NoError(json.Unmarshal(record, &decoded))
+		assert.Equal(fixing, &decoded)
+
+		// the pruned keys survive serialization
+		var vkBuf bytes.Buffer
+		_, err = prunedVk.WriteTo(&vkBuf)
+		assert.NoError(err)
+		reloadedVk := NewVerifyingKey(curve)
+		_, err = reloadedVk.ReadFrom(&vkBuf)
+		assert.NoError(err)
+
+		// the reserved input is left unassigned: the pinned value is used
+		var witness reservedCircuit
+		witness.X.Assign(3)
+		witness.Y.Assign(9)
+		proof, err := ProvePruned(ccs, pk, fixing, &witness)
+		assert.NoError(err, "%s", curve)
+		assert.Nil(witness.Reserved.WitnessValue, "the witness must be left untouched")
+
+		var publicWitness reservedCircuit
+		publicWitness.Y.Assign(9)
+		assert.NoError(VerifyPruned(proof, prunedVk, fixing, &publicWitness), "%s", curve)
+		assert.NoError(VerifyPruned(proof, reloadedVk, fixing, &publicWitness), "%s", curve)
+		assert.Error(VerifyPruned(proof, prunedVk, fixing, &reservedCircuit{Y: frontend.Value(8)}))
+
+		// the remaining public inputs are the ones of the compact verifier too
+		var compactBuf bytes.Buffer
+		_, err = prunedVk.WriteCompactTo(&compactBuf)
+		assert.NoError(err)
+		verifier, err := NewVerifier(compactBuf.Bytes())
+		assert.NoError(err)
+		var proofBuf bytes.Buffer
+		_, err = proof.WriteTo(&proofBuf)
+		assert.NoError(err)
+		assert.NoError(verifier.Verify(proofBuf.Bytes(), []*big.Int{big.NewInt(9)}))
+
+		// a proof claiming another value for the reserved input can't be produced with the fixing...
+		var otherWitness reservedCircuit
+		otherWitness.X.Assign(3)
+		otherWitness.Y.Assign(8)
+		otherWitness.Reserved.Assign(1)
+		_, err = ProvePruned(ccs, pk, fixing, &otherWitness)
+		assert.Error(err)
+
+		// ... and if produced with the unpruned keys, it doesn't verify against the pruned key
+		otherProof, err := Prove(ccs, pk, &otherWitness)
+		assert.NoError(err)
+		assert.NoError(Verify(otherProof, vk, &reservedCircuit{Y: frontend.Value(8), Reserved: frontend.Value(1)}))
+		assert.Error(VerifyPruned(otherProof, prunedVk, fixing, &reservedCircuit{Y: frontend.Value(8)}), "%s", curve)
+		assert.Error(VerifyPruned(otherProof, prunedVk, fixing, &reservedCircuit{Y: frontend.Value(8), Reserved: frontend.Value(1)}))
+	}
+}
@@ -0,0 +1,100 @@
+package groth16
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/stretchr/testify/require"
+)
+
+// opaqueReader hides the length of the underlying reader
+type opaqueReader struct{ r io.Reader }
+
+func (r opaqueReader) Read(p []byte) (int, error) { return r.r.Read(p) }
+
+// readLimitsEncodings returns the encodings of a BN254 proof, verifying key and proving key
+func readLimitsEncodings(t testing.TB) (proof, vk, pk []byte) {
+	assert := require.New(t)
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &dumpCircuit{nbConstraints: 3})
+	assert.NoError(err)
+	_pk, _vk, err := Setup(ccs)
+	assert.NoError(err)
+
+	var witness dumpCircuit
+	witness.X.Assign(2)
+	witness.Y.Assign(16)
+	_proof, err := Prove(ccs, _pk, &witness)
+	assert.NoError(err)
+
+	var bProof, bVK, bPK bytes.Buffer
+	_, err = _proof.WriteTo(&bProof)
+	assert.NoError(err)
+	_, err = _vk.WriteTo(&bVK)
+	assert.NoError(err)
+	_, err = _pk.WriteTo(&bPK)
+	assert.NoError(err)
+	return bProof.Bytes(), bVK.Bytes(), bPK.Bytes()
+}
+
+// TestReadLimits reads encodings whose length fields claim huge slices: they used to be allocated
+// before the values were read
+func TestReadLimits(t *testing.T) {
+	assert := require.New(t)
+	_, vk, pk := readLimitsEncodings(t)
+
+	// the valid encodings are read
+	_, err := NewVerifyingKey(ecc.BN254).ReadFrom(bytes.NewReader(vk))
+	assert.NoError(err)
+	_, err = NewProvingKey(ecc.BN254).ReadFrom(bytes.NewReader(pk))
+	assert.NoError(err)
+
+	// [α]1,[β]1,[β]2,[γ]2,[δ]1,[δ]2 (compressed) then uint32(len(K)): 2^32-1 points of K
+	const offsetK = 3*32 + 3*64
+	hugeK := append([]byte{}, vk[:offsetK]...)
+	hugeK = append(hugeK, 0xff, 0xff, 0xff, 0xff)
+
+	// the domain starts with its cardinality: 2^40
+	hugeDomain := append([]byte{}, pk...)
+	binary.BigEndian.PutUint64(hugeDomain[:8], 1<<40)
+
+	// the remaining length of the reader is known
+	_, err = NewVerifyingKey(ecc.BN254).ReadFrom(bytes.NewReader(hugeK))
+	assert.ErrorIs(err, gnarkio.ErrLimitExceeded)
+	_, err = NewVerifyingKey(ecc.BN254).UnsafeReadFrom(bytes.NewReader(hugeK))
+	assert.ErrorIs(err, gnarkio.ErrLimitExceeded)
+
+	// or the cap applies
+	defer gnarkio.SetMaxReadBytes(gnarkio.SetMaxReadBytes(1 << 20))
+	_, err = NewVerifyingKey(ecc.BN254).ReadFrom(opaqueReader{bytes.NewReader(hugeK)})
+	assert.ErrorIs(err, gnarkio.ErrLimitExceeded)
+	_, err = NewProvingKey(ecc.BN254).ReadFrom(opaqueReader{bytes.NewReader(hugeDomain)})
+	assert.ErrorIs(err, gnarkio.ErrLimitExceeded)
+	_, err = NewProvingKey(ecc.BN254).ReadFrom(opaqueReader{bytes.NewReader(pk)})
+	assert.NoError(err)
+
+	// a dump claiming 2^40 bytes of metadata
+	var dump bytes.Buffer
+	_pk := NewProvingKey(ecc.BN254)
+	_, err = _pk.ReadFrom(bytes.NewReader(pk))
+	assert.NoError(err)
+	assert.NoError(_pk.WriteDump(&dump))
+	hugeMeta := append([]byte{}, dump.Bytes()...)
+	binary.BigEndian.PutUint64(hugeMeta[16:24], 1<<40)
+	assert.ErrorIs(NewProvingKey(ecc.BN254).ReadDump(bytes.NewReader(hugeMeta)), gnarkio.ErrLimitExceeded)
+
+	// the constraint system is buffered by the decoder, up to the cap
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &dumpCircuit{nbConstraints: 3})
+	assert.NoError(err)
+	var bCCS bytes.Buffer
+	_, err = ccs.WriteTo(&bCCS)
+	assert.NoError(err)
+	gnarkio.SetMaxReadBytes(int64(bCCS.Len() / 2))
+	_, err = NewCS(ecc.BN254).ReadFrom(bytes.NewReader(bCCS.Bytes()))
+	assert.ErrorIs(err, gnarkio.ErrLimitExceeded)
+}
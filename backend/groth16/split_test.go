@@ -0,0 +1,137 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package groth16_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/examples/cubic"
+	"github.com/consensys/gnark/frontend"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitProvingKey(t *testing.T) {
+	assert := require.New(t)
+	const chunkSize = 100
+
+	var w cubic.Circuit
+	w.X.Assign(3)
+	w.Y.Assign(35)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &cubic.Circuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	var expected bytes.Buffer
+	_, err = pk.WriteTo(&expected)
+	assert.NoError(err)
+
+	dir := t.TempDir()
+	n, err := pk.WriteSplitTo(dir, chunkSize)
+	assert.NoError(err)
+	assert.Equal(int64(expected.Len()), n)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, gnarkio.SplitManifestName))
+	assert.NoError(err)
+	var manifest gnarkio.SplitManifest
+	assert.NoError(json.Unmarshal(data, &manifest))
+	nbChunks := (expected.Len() + chunkSize - 1) / chunkSize
+	assert.True(nbChunks > 2)
+	assert.Len(manifest.Chunks, nbChunks)
+	for i, c := range manifest.Chunks {
+		if i < nbChunks-1 {
+			assert.Equal(int64(chunkSize), c.Size)
+		}
+	}
+
+	// round trip, with and without the subgroup checks
+	for _, read := range []func(pk groth16.ProvingKey) (int64, error){
+		func(pk groth16.ProvingKey) (int64, error) { return pk.ReadSplitFrom(dir) },
+		func(pk groth16.ProvingKey) (int64, error) { return pk.UnsafeReadSplitFrom(dir) },
+	} {
+		key := groth16.NewProvingKey(ecc.BN254)
+		n, err = read(key)
+		assert.NoError(err)
+		assert.Equal(int64(expected.Len()), n)
+		var got bytes.Buffer
+		_, err = key.WriteTo(&got)
+		assert.NoError(err)
+		assert.Equal(expected.Bytes(), got.Bytes())
+
+		proof, err := groth16.Prove(ccs, key, &w)
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, &w))
+	}
+
+	chunkError := func(err error) int {
+		var e *gnarkio.ChunkError
+		assert.True(errors.As(err, &e), "%v", err)
+		return e.Index
+	}
+	path := func(i int) string { return filepath.Join(dir, manifest.Chunks[i].File) }
+
+	// a corrupted chunk is reported, even the last one which ReadFrom may not read to its end
+	for _, i := range []int{1, nbChunks - 1} {
+		chunk, err := ioutil.ReadFile(path(i))
+		assert.NoError(err)
+		chunk[len(chunk)-1] ^= 1
+		assert.NoError(ioutil.WriteFile(path(i), chunk, 0600))
+
+		_, err = groth16.NewProvingKey(ecc.BN254).ReadSplitFrom(dir)
+		assert.Equal(i, chunkError(err))
+		assert.Contains(err.Error(), "digest mismatch")
+
+		chunk[len(chunk)-1] ^= 1
+		assert.NoError(ioutil.WriteFile(path(i), chunk, 0600))
+	}
+
+	// a truncated chunk
+	chunk, err := ioutil.ReadFile(path(2))
+	assert.NoError(err)
+	assert.NoError(ioutil.WriteFile(path(2), chunk[1:], 0600))
+	_, err = groth16.NewProvingKey(ecc.BN254).ReadSplitFrom(dir)
+	assert.Equal(2, chunkError(err))
+
+	// a missing chunk
+	assert.NoError(os.Remove(path(2)))
+	_, err = groth16.NewProvingKey(ecc.BN254).UnsafeReadSplitFrom(dir)
+	assert.Equal(2, chunkError(err))
+	assert.True(os.IsNotExist(errors.Unwrap(err)))
+
+	// the PLONK SRS too
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(err)
+	dir = t.TempDir()
+	_, err = gnarkio.WriteSplitTo(dir, chunkSize, srs)
+	assert.NoError(err)
+	readSRS := kzg.NewSRS(ecc.BN254)
+	_, err = gnarkio.ReadSplitFrom(dir, readSRS)
+	assert.NoError(err)
+	assert.Equal(srs, readSRS)
+}
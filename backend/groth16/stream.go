@@ -0,0 +1,73 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groth16
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/common"
+)
+
+// proofComponents are the components of a streamed proof, in the order of Proof.WriteTo
+var proofComponents = []string{"ar", "bs", "krs"}
+
+// ProveStream is Prove, writing the components of the proof (Ar, Bs and Krs) to w, framed, with a
+// checksum after the last one; ReadStreamedProof reads the proof back. It is the counterpart of
+// plonk.ProveStream for the consumers of both backends: the components of a Groth16 proof are computed
+// concurrently, and are written once the proof is computed.
+//
+// The function set with backend.WithProofComponents, if any, is called after each component is written.
+// If the prover fails, ProveStream returns the error without writing the checksum, and ReadStreamedProof
+// fails with backend.ErrInvalidProofStream.
+func ProveStream(r1cs frontend.CompiledConstraintSystem, pk ProvingKey, witness frontend.Circuit, w io.Writer, opts ...func(opt *backend.ProverOption) error) (Proof, error) {
+	stream := common.NewProofStreamWriter(w, backend.GROTH16, r1cs.CurveID())
+	opts = append(opts[:len(opts):len(opts)], stream.ProverOption())
+	proof, err := Prove(r1cs, pk, witness, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Close(); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// ReadStreamedProof reads a proof written by ProveStream. The proof is the one ProveStream returned,
+// with the same encoding with Proof.WriteTo. It fails with backend.ErrInvalidProofStream (wrapped) if
+// the stream is truncated, corrupted, or misses a component.
+func ReadStreamedProof(r io.Reader) (Proof, error) {
+	curveID, components, err := common.ReadProofStream(r, backend.GROTH16)
+	if err != nil {
+		return nil, err
+	}
+	b, err := common.AssembleProofStream(components, proofComponents, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := NewProof(curveID)
+	n, err := proof.ReadFrom(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", backend.ErrInvalidProofStream, err)
+	}
+	if n != int64(len(b)) {
+		return nil, fmt.Errorf("%w: %d trailing bytes in the components", backend.ErrInvalidProofStream, int64(len(b))-n)
+	}
+	return proof, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groth16
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/curves"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveStream(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range curves.Implemented() {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &dumpCircuit{nbConstraints: 3})
+		assert.NoError(err)
+
+		pk, vk, err := Setup(ccs)
+		assert.NoError(err)
+
+		var witness dumpCircuit
+		witness.X.Assign(2)
+		witness.Y.Assign(16)
+
+		proof, err := Prove(ccs, pk, &witness, backend.WithRandomness(rand.New(rand.NewSource(1))))
+		assert.NoError(err)
+
+		var stream bytes.Buffer
+		_, err = ProveStream(ccs, pk, &witness, &stream, backend.WithRandomness(rand.New(rand.NewSource(1))))
+		assert.NoError(err)
+
+		// the proof read from the stream is the one of the regular prover
+		read, err := ReadStreamedProof(bytes.NewReader(stream.Bytes()))
+		assert.NoError(err)
+		assert.Equal(proof, read, "%s", curve)
+		assert.NoError(Verify(read, vk, &witness))
+
+		// a truncated stream is rejected
+		for i := 0; i < stream.Len(); i++ {
+			_, err := ReadStreamedProof(bytes.NewReader(stream.Bytes()[:i]))
+			assert.ErrorIs(err, backend.ErrInvalidProofStream, "%s: truncated at %d", curve, i)
+		}
+
+		// as is a stream of another backend
+		corrupted := append([]byte(nil), stream.Bytes()...)
+		corrupted[9] = byte(backend.PLONK)
+		_, err = ReadStreamedProof(bytes.NewReader(corrupted))
+		assert.ErrorIs(err, backend.ErrInvalidProofStream)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groth16
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	groth16_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/groth16"
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+	groth16_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	groth16_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/groth16"
+	groth16_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/groth16"
+)
+
+// Verifier is a standalone Groth16 verifier, which only needs the serialized VerifyingKey, proof
+// and public inputs. It doesn't depend on a compiled circuit nor on a witness structure, which makes it
+// suitable for constrained environments (WASM in the browser, ...). See examples/wasm.
+type Verifier interface {
+	// Verify verifies a proof (as written by Proof.WriteTo, Proof.WriteRawTo or WrapProof) against the public inputs,
+	// in the order of the public witness (without the constant ONE_WIRE)
+	Verify(proofBytes []byte, publicInputs []*big.Int) error
+}
+
+// NewVerifier parses a VerifyingKey written with VerifyingKey.WriteCompactTo and returns a Verifier.
+//
+// In the compact encoding, e(α, β), -[γ]2 and -[δ]2 are precomputed and points are not compressed,
+// such that no pairing nor square root is computed when the key is loaded.
+func NewVerifier(vkBytes []byte) (Verifier, error) {
+	if len(vkBytes) < 2 {
+		return nil, errors.New("invalid verifying key: too short")
+	}
+	curveID := ecc.ID(binary.BigEndian.Uint16(vkBytes[:2]))
+
+	var (
+		v   Verifier
+		err error
+	)
+	switch curveID {
+	case ecc.BN254:
+		v, err = groth16_bn254.NewVerifier(vkBytes)
+	case ecc.BLS12_377:
+		v, err = groth16_bls12377.NewVerifier(vkBytes)
+	case ecc.BLS12_381:
+		v, err = groth16_bls12381.NewVerifier(vkBytes)
+	case ecc.BW6_761:
+		v, err = groth16_bw6761.NewVerifier(vkBytes)
+	case ecc.BW6_633:
+		v, err = groth16_bw6633.NewVerifier(vkBytes)
+	case ecc.BLS24_315:
+		v, err = groth16_bls24315.NewVerifier(vkBytes)
+	default:
+		return nil, errors.New("invalid verifying key: unknown curve")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unwrappingVerifier{Verifier: v, curveID: curveID}, nil
+}
@@ -0,0 +1,211 @@
+package groth16
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16/bls12377verifier"
+	"github.com/consensys/gnark/backend/groth16/bls12381verifier"
+	"github.com/consensys/gnark/backend/groth16/bls24315verifier"
+	"github.com/consensys/gnark/backend/groth16/bn254verifier"
+	"github.com/consensys/gnark/backend/groth16/bw6633verifier"
+	"github.com/consensys/gnark/backend/groth16/bw6761verifier"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/curves"
+	"github.com/stretchr/testify/require"
+)
+
+// verifierPackages reads a verifying key, a proof and a public witness, and verifies the proof,
+// with the verifier-only package of each curve
+var verifierPackages = map[ecc.ID]struct {
+	path   string
+	verify func(vk, proof, publicWitness io.Reader) error
+}{
+	ecc.BN254: {"bn254verifier", func(vk, proof, publicWitness io.Reader) error {
+		v, err := bn254verifier.ReadVerifyingKey(vk)
+		if err != nil {
+			return err
+		}
+		p, err := bn254verifier.ReadProof(proof)
+		if err != nil {
+			return err
+		}
+		w, err := bn254verifier.ReadPublicWitness(publicWitness, v)
+		if err != nil {
+			return err
+		}
+		return bn254verifier.Verify(p, v, w)
+	}},
+	ecc.BLS12_381: {"bls12381verifier", func(vk, proof, publicWitness io.Reader) error {
+		v, err := bls12381verifier.ReadVerifyingKey(vk)
+		if err != nil {
+			return err
+		}
+		p, err := bls12381verifier.ReadProof(proof)
+		if err != nil {
+			return err
+		}
+		w, err := bls12381verifier.ReadPublicWitness(publicWitness, v)
+		if err != nil {
+			return err
+		}
+		return bls12381verifier.Verify(p, v, w)
+	}},
+	ecc.BLS12_377: {"bls12377verifier", func(vk, proof, publicWitness io.Reader) error {
+		v, err := bls12377verifier.ReadVerifyingKey(vk)
+		if err != nil {
+			return err
+		}
+		p, err := bls12377verifier.ReadProof(proof)
+		if err != nil {
+			return err
+		}
+		w, err := bls12377verifier.ReadPublicWitness(publicWitness, v)
+		if err != nil {
+			return err
+		}
+		return bls12377verifier.Verify(p, v, w)
+	}},
+	ecc.BW6_761: {"bw6761verifier", func(vk, proof, publicWitness io.Reader) error {
+		v, err := bw6761verifier.ReadVerifyingKey(vk)
+		if err != nil {
+			return err
+		}
+		p, err := bw6761verifier.ReadProof(proof)
+		if err != nil {
+			return err
+		}
+		w, err := bw6761verifier.ReadPublicWitness(publicWitness, v)
+		if err != nil {
+			return err
+		}
+		return bw6761verifier.Verify(p, v, w)
+	}},
+	ecc.BLS24_315: {"bls24315verifier", func(vk, proof, publicWitness io.Reader) error {
+		v, err := bls24315verifier.ReadVerifyingKey(vk)
+		if err != nil {
+			return err
+		}
+		p, err := bls24315verifier.ReadProof(proof)
+		if err != nil {
+			return err
+		}
+		w, err := bls24315verifier.ReadPublicWitness(publicWitness, v)
+		if err != nil {
+			return err
+		}
+		return bls24315verifier.Verify(p, v, w)
+	}},
+	ecc.BW6_633: {"bw6633verifier", func(vk, proof, publicWitness io.Reader) error {
+		v, err := bw6633verifier.ReadVerifyingKey(vk)
+		if err != nil {
+			return err
+		}
+		p, err := bw6633verifier.ReadProof(proof)
+		if err != nil {
+			return err
+		}
+		w, err := bw6633verifier.ReadPublicWitness(publicWitness, v)
+		if err != nil {
+			return err
+		}
+		return bw6633verifier.Verify(p, v, w)
+	}},
+}
+
+func TestVerifierPackages(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range curves.Implemented() {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &dumpCircuit{nbConstraints: 3}, frontend.WithMetadata(map[string]string{"name": "dump"}))
+		assert.NoError(err)
+
+		pk, vk, err := Setup(ccs)
+		assert.NoError(err)
+
+		// X**4 == Y
+		var assignment, badAssignment dumpCircuit
+		assignment.X.Assign(2)
+		assignment.Y.Assign(16)
+		badAssignment.Y.Assign(17)
+
+		proof, err := Prove(ccs, pk, &assignment)
+		assert.NoError(err)
+
+		// both encodings of the keys and proofs
+		var vkBuf, vkRawBuf, proofBuf, proofRawBuf, publicBuf, badPublicBuf bytes.Buffer
+		_, err = vk.WriteTo(&vkBuf)
+		assert.NoError(err)
+		_, err = vk.WriteRawTo(&vkRawBuf)
+		assert.NoError(err)
+		_, err = proof.WriteTo(&proofBuf)
+		assert.NoError(err)
+		_, err = proof.WriteRawTo(&proofRawBuf)
+		assert.NoError(err)
+		_, err = witness.WritePublicTo(&publicBuf, curve, &assignment)
+		assert.NoError(err)
+		_, err = witness.WritePublicTo(&badPublicBuf, curve, &badAssignment)
+		assert.NoError(err)
+
+		verify := verifierPackages[curve].verify
+		for _, vkBytes := range [][]byte{vkBuf.Bytes(), vkRawBuf.Bytes()} {
+			for _, proofBytes := range [][]byte{proofBuf.Bytes(), proofRawBuf.Bytes()} {
+				// the verifier-only package accepts and rejects what the full package does
+				assert.NoError(Verify(proof, vk, &assignment))
+				assert.NoError(verify(bytes.NewReader(vkBytes), bytes.NewReader(proofBytes), bytes.NewReader(publicBuf.Bytes())), "%s", curve)
+
+				assert.Error(Verify(proof, vk, &badAssignment))
+				assert.Error(verify(bytes.NewReader(vkBytes), bytes.NewReader(proofBytes), bytes.NewReader(badPublicBuf.Bytes())), "%s", curve)
+
+				assert.Error(verify(bytes.NewReader(vkBytes), bytes.NewReader(proofBytes[:10]), bytes.NewReader(publicBuf.Bytes())), "truncated proof")
+				assert.Error(verify(bytes.NewReader(vkBytes), bytes.NewReader(proofBytes), bytes.NewReader(publicBuf.Bytes()[:4])), "truncated witness")
+			}
+		}
+	}
+}
+
+// TestVerifierPackagesImports checks that the verifier-only packages don't link the compiler,
+// the prover, PLONK nor the other curves
+func TestVerifierPackagesImports(t *testing.T) {
+	assert := require.New(t)
+
+	forbidden := []string{
+		"github.com/consensys/gnark/frontend",
+		"github.com/consensys/gnark/std",
+		"github.com/consensys/gnark/backend/plonk",
+		"github.com/consensys/gnark/backend/witness",
+		"github.com/consensys/gnark/internal/utils",
+		"github.com/consensys/gnark/internal/backend/bn254",
+		"github.com/consensys/gnark/internal/backend/bls12-377",
+		"github.com/consensys/gnark/internal/backend/bls12-381",
+		"github.com/consensys/gnark/internal/backend/bls24-315",
+		"github.com/consensys/gnark/internal/backend/bw6-761",
+		"github.com/consensys/gnark/internal/backend/bw6-633",
+		"github.com/consensys/gnark-crypto/kzg",
+	}
+
+	for curve, pkg := range verifierPackages {
+		out, err := exec.Command("go", "list", "-deps", "github.com/consensys/gnark/backend/groth16/"+pkg.path).Output()
+		assert.NoError(err)
+		deps := strings.Fields(string(out))
+		curveDir := "github.com/consensys/gnark-crypto/ecc/" + strings.TrimSuffix(pkg.path, "verifier")
+
+		for _, dep := range deps {
+			for _, f := range forbidden {
+				assert.False(dep == f || strings.HasPrefix(dep, f+"/"), "%s imports %s", pkg.path, dep)
+			}
+			assert.False(strings.HasSuffix(dep, "/fr/fft"), "%s imports %s", pkg.path, dep)
+			// no other curve
+			if d := strings.ReplaceAll(dep, "-", ""); strings.HasPrefix(d, "github.com/consensys/gnarkcrypto/ecc/") {
+				d = strings.Replace(d, "gnarkcrypto", "gnark-crypto", 1)
+				assert.True(d == curveDir || strings.HasPrefix(d, curveDir+"/"), "%s (%s) imports %s", pkg.path, curve, dep)
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package groth16
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/curves"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactVerifier(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range curves.Implemented() {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &dumpCircuit{nbConstraints: 3})
+		assert.NoError(err)
+
+		pk, vk, err := Setup(ccs)
+		assert.NoError(err)
+
+		// X**4 == Y
+		var witness dumpCircuit
+		witness.X.Assign(2)
+		witness.Y.Assign(16)
+
+		proof, err := Prove(ccs, pk, &witness)
+		assert.NoError(err)
+		assert.NoError(Verify(proof, vk, &witness))
+
+		var vkBuf bytes.Buffer
+		written, err := vk.WriteCompactTo(&vkBuf)
+		assert.NoError(err)
+		assert.Equal(int64(vkBuf.Len()), written)
+
+		verifier, err := NewVerifier(vkBuf.Bytes())
+		assert.NoError(err)
+
+		// the compact verifier must accept both proof encodings
+		var proofBuf, proofRawBuf bytes.Buffer
+		_, err = proof.WriteTo(&proofBuf)
+		assert.NoError(err)
+		_, err = proof.WriteRawTo(&proofRawBuf)
+		assert.NoError(err)
+
+		publicInputs := []*big.Int{big.NewInt(16)}
+		assert.NoError(verifier.Verify(proofBuf.Bytes(), publicInputs), "%s", curve)
+		assert.NoError(verifier.Verify(proofRawBuf.Bytes(), publicInputs), "%s", curve)
+
+		// and reject what the standard verifier rejects
+		var badWitness dumpCircuit
+		badWitness.Y.Assign(17)
+		assert.Error(Verify(proof, vk, &badWitness))
+		assert.Error(verifier.Verify(proofBuf.Bytes(), []*big.Int{big.NewInt(17)}))
+		assert.Error(verifier.Verify(proofBuf.Bytes(), nil), "wrong number of public inputs")
+		assert.Error(verifier.Verify(proofBuf.Bytes()[:10], publicInputs), "truncated proof")
+
+		// truncated or corrupted keys are rejected
+		_, err = NewVerifier(vkBuf.Bytes()[:vkBuf.Len()-1])
+		assert.Error(err)
+		corrupted := append([]byte{}, vkBuf.Bytes()...)
+		corrupted[0] = 0xff
+		_, err = NewVerifier(corrupted)
+		assert.Error(err)
+	}
+}
+
+func TestPreparedVerifyingKey(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range curves.Implemented() {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &dumpCircuit{nbConstraints: 3})
+		assert.NoError(err)
+
+		pk, vk, err := Setup(ccs)
+		assert.NoError(err)
+
+		var witness dumpCircuit
+		witness.X.Assign(2)
+		witness.Y.Assign(16)
+
+		proof, err := Prove(ccs, pk, &witness)
+		assert.NoError(err)
+
+		var vkBuf bytes.Buffer
+		written, err := vk.WritePreparedTo(&vkBuf)
+		assert.NoError(err)
+		assert.Equal(int64(vkBuf.Len()), written)
+
+		prepared := NewVerifyingKey(curve)
+		read, err := prepared.ReadPreparedFrom(bytes.NewReader(vkBuf.Bytes()))
+		assert.NoError(err)
+		assert.Equal(written, read)
+		assert.NoError(prepared.Precompute(), "idempotent")
+
+		assert.NoError(Verify(proof, prepared, &witness), "%s", curve)
+
+		// invalid proofs are still rejected
+		var badWitness dumpCircuit
+		badWitness.Y.Assign(17)
+		assert.Error(Verify(proof, prepared, &badWitness), "%s", curve)
+
+		assert.Error(Verify(proof, prepared, &dumpCircuit{X: witness.X, Y: frontend.Value(15)}))
+
+		// a proof from another setup
+		otherPk, _, err := Setup(ccs)
+		assert.NoError(err)
+		otherProof, err := Prove(ccs, otherPk, &witness)
+		assert.NoError(err)
+		assert.Error(Verify(otherProof, prepared, &witness), "%s", curve)
+
+		// truncated keys are rejected
+		_, err = NewVerifyingKey(curve).ReadPreparedFrom(bytes.NewReader(vkBuf.Bytes()[:vkBuf.Len()/2]))
+		assert.Error(err)
+	}
+}
@@ -2,6 +2,7 @@ package hint
 
 import (
 	"errors"
+	"fmt"
 	"hash/fnv"
 	"math/big"
 	"reflect"
@@ -10,13 +11,183 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 )
 
-type ID uint32
+// ID identifies a hint function in the compiled constraint systems. It is a 64-bit FNV-1a hash
+// (see UUID and NewFixedHintNamed), such that collisions are unlikely even with many hints
+type ID uint64
 
 type Function func(curveID ecc.ID, inputs []*big.Int, result *big.Int) error
 
+// AnnotatedFunction is a hint function which carries its own identity and arity.
+//
+// Unlike Function, whose ID is derived from its name, it may be constructed at run time
+// (see NewRemoteHint) and may have several outputs.
+type AnnotatedFunction interface {
+	// UUID returns the ID under which the hint is recorded in the compiled constraint system
+	UUID() ID
+
+	// NbInputs returns the number of inputs of the hint, or -1 if it accepts any number of inputs
+	NbInputs() int
+
+	// NbOutputs returns the number of outputs of the hint, or -1 if it has as many outputs as inputs
+	NbOutputs() int
+
+	// Call computes the outputs of the hint; len(outputs) == NbOutputs()
+	Call(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error
+
+	// String returns a human readable name of the hint, used in error messages
+	String() string
+}
+
 // UUID returns a unique ID for a hint function name
+//
+// The name is the one of the Go function (see Name), it changes when the code is refactored
+// (renamed function, closure instead of method, package moved...), and with it the ID recorded
+// in the compiled constraint systems. See NewFixedHintNamed for stable IDs.
 func UUID(f Function) ID {
-	name := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	return uuid(name(f))
+}
+
+// Name returns the name of the Go function f, from which UUID derives its ID
+func Name(f Function) string {
+	return name(f)
+}
+
+// Annotate returns an AnnotatedFunction with a single output, wrapping f.
+// Its ID is UUID(f).
+//
+// Deprecated: the ID is derived from the name of the Go function, which isn't stable across
+// refactorings; use NewFixedHintNamed.
+func Annotate(f Function) AnnotatedFunction {
+	return annotated{f: f}
+}
+
+// NewFixedHintNamed returns an AnnotatedFunction with nIn inputs and nOut outputs, computed by f.
+// nIn may be -1 if the hint accepts any number of inputs, nOut may be -1 if the hint has as many
+// outputs as inputs.
+//
+// Its ID is derived from name, nIn and nOut only: it doesn't change when f is renamed or moved, such
+// that constraint systems compiled with the hint can be solved by later versions of the code, as long as
+// the name is kept. By convention, the name is prefixed by the module, for example "gnark/ithbit".
+func NewFixedHintNamed(name string, f func(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error, nIn, nOut int) AnnotatedFunction {
+	if nIn < -1 || nOut < -1 || nOut == 0 {
+		panic(fmt.Sprintf("hint %s: invalid arity (%d, %d)", name, nIn, nOut))
+	}
+	return &namedHint{name: name, f: f, nIn: nIn, nOut: nOut}
+}
+
+type namedHint struct {
+	name      string
+	f         func(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error
+	nIn, nOut int
+}
+
+func (h *namedHint) UUID() ID       { return namedUUID(h.name, h.nIn, h.nOut) }
+func (h *namedHint) NbInputs() int  { return h.nIn }
+func (h *namedHint) NbOutputs() int { return h.nOut }
+func (h *namedHint) String() string { return h.name }
+func (h *namedHint) Call(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	return h.f(curveID, inputs, outputs)
+}
+
+// Stable names of the hints of the standard library (see NewFixedHintNamed), used by the frontend
+var (
+	IthBitNamed = NewFixedHintNamed("gnark/ithbit", single(IthBit), 2, 1)
+	IsZeroNamed = NewFixedHintNamed("gnark/iszero", single(IsZero), 1, 1)
+	InvModNamed = NewFixedHintNamed("gnark/invmod", single(InvMod), 1, 1)
+
+	IthDigitNamed = NewFixedHintNamed("gnark/ithdigit", single(IthDigit), 3, 1)
+	IdentityNamed = NewFixedHintNamed("gnark/identity", single(Identity), 1, 1)
+)
+
+// Builtins returns the hints of the standard library, which the solvers register by default: the hints
+// with a stable name, and the hint functions under their legacy IDs (see UUID), such that constraint systems
+// compiled before the stable names can be solved. Each of them is also registered under its 32-bit ID,
+// for the constraint systems compiled before the IDs were widened to 64 bits.
+//
+// With backend.WithConstantTimeHints, the solvers compute IthBit, IsZero and InvMod with the
+// field arithmetic of the curve instead of calling these functions, such that their running time
+// doesn't depend on the values of the inputs.
+func Builtins() []AnnotatedFunction {
+	res := []AnnotatedFunction{
+		IthBitNamed,
+		IsZeroNamed,
+		InvModNamed,
+		IthDigitNamed,
+		IdentityNamed,
+		BatchInvMod,
+		PermutationNetwork,
+		annotated{f: IthBit},
+		annotated{f: IsZero},
+		annotated{f: InvMod},
+	}
+	for i, n := 0, len(res); i < n; i++ {
+		res = append(res, legacyHint{AnnotatedFunction: res[i], id: legacyUUID(res[i])})
+	}
+	return res
+}
+
+// legacyHint is a builtin hint under its 32-bit ID
+type legacyHint struct {
+	AnnotatedFunction
+	id ID
+}
+
+func (h legacyHint) UUID() ID { return h.id }
+
+// legacyUUID returns the 32-bit ID of a builtin hint
+func legacyUUID(h AnnotatedFunction) ID {
+	switch t := h.(type) {
+	case *namedHint:
+		return uuid32(namedKey(t.name, t.nIn, t.nOut))
+	case annotated:
+		return uuid32(name(t.f))
+	case batchInvMod:
+		return uuid32(batchInvModKey)
+	default:
+		panic("no legacy ID for hint " + h.String())
+	}
+}
+
+// single adapts a hint function to the signature of NewFixedHintNamed
+func single(f Function) func(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	return func(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+		return f(curveID, inputs, outputs[0])
+	}
+}
+
+type annotated struct {
+	f Function
+}
+
+func (a annotated) UUID() ID       { return UUID(a.f) }
+func (a annotated) NbInputs() int  { return -1 }
+func (a annotated) NbOutputs() int { return 1 }
+func (a annotated) String() string { return name(a.f) }
+func (a annotated) Call(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	return a.f(curveID, inputs, outputs[0])
+}
+
+func name(f Function) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+// namedUUID is the ID of a hint with a stable name and arities
+func namedUUID(name string, nIn, nOut int) ID {
+	return uuid(namedKey(name, nIn, nOut))
+}
+
+func namedKey(name string, nIn, nOut int) string {
+	return fmt.Sprintf("%s(%d,%d)", name, nIn, nOut)
+}
+
+func uuid(name string) ID {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return ID(h.Sum64())
+}
+
+// uuid32 is the 32-bit hash of the IDs before they were widened to 64 bits
+func uuid32(name string) ID {
 	h := fnv.New32a()
 	_, _ = h.Write([]byte(name))
 	return ID(h.Sum32())
@@ -39,6 +210,39 @@ func IthBit(_ ecc.ID, inputs []*big.Int, result *big.Int) error {
 	return nil
 }
 
+// Identity expects len(inputs) == 1
+// inputs[0] == a
+// returns a; the solvers compute the public outputs of a circuit with it (see frontend.API.SetOutput)
+func Identity(_ ecc.ID, inputs []*big.Int, result *big.Int) error {
+	if len(inputs) != 1 {
+		return errors.New("Identity expects one input")
+	}
+	result.Set(inputs[0])
+	return nil
+}
+
+// IthDigit expects len(inputs) == 3
+// inputs[0] == a
+// inputs[1] == n
+// inputs[2] == k
+// returns digit number n of a in base 2**k
+func IthDigit(_ ecc.ID, inputs []*big.Int, result *big.Int) error {
+	if len(inputs) != 3 {
+		return errors.New("ithDigit expects 3 inputs; inputs[0] == value, inputs[1] == digit position, inputs[2] == digit size in bits")
+	}
+	if !inputs[1].IsUint64() || !inputs[2].IsUint64() || inputs[2].Uint64() > 64 {
+		result.SetUint64(0)
+		return nil
+	}
+	n, k := inputs[1].Uint64(), uint(inputs[2].Uint64())
+
+	result.Rsh(inputs[0], uint(n)*k)
+	var mask big.Int
+	mask.Lsh(big.NewInt(1), k).Sub(&mask, big.NewInt(1))
+	result.And(result, &mask)
+	return nil
+}
+
 // IsZero expects len(inputs) == 1
 // inputs[0] == a
 // returns m = 1 - a^(modulus-1)
@@ -64,3 +268,77 @@ func IsZero(curveID ecc.ID, inputs []*big.Int, result *big.Int) error {
 
 	return nil
 }
+
+// InvMod expects len(inputs) == 1
+// inputs[0] == a
+// returns 1/a (mod modulus), computed with the extended euclidean algorithm, or 0 if a == 0
+//
+// The caller is responsible for constraining the result; for a == 0, the result is
+// not an inverse (see frontend.API Inverse and InverseOrZero).
+func InvMod(curveID ecc.ID, inputs []*big.Int, result *big.Int) error {
+	if len(inputs) != 1 {
+		return errors.New("InvMod expects one input")
+	}
+
+	// get fr modulus
+	q := curveID.Info().Fr.Modulus()
+
+	result.Mod(inputs[0], q)
+	if result.Sign() == 0 {
+		return nil
+	}
+	result.ModInverse(result, q)
+
+	return nil
+}
+
+// BatchInvMod has as many outputs as inputs, and sets outputs[i] = 1/inputs[i] (mod modulus),
+// or 0 if inputs[i] == 0. The inverses are computed with Montgomery's trick, that is, with a
+// single modular inversion and 3(n-1) multiplications.
+//
+// The caller is responsible for constraining the results (see frontend.API BatchInvert
+// and BatchInvertOrZero).
+var BatchInvMod AnnotatedFunction = batchInvMod{}
+
+type batchInvMod struct{}
+
+const batchInvModKey = "github.com/consensys/gnark/backend/hint.BatchInvMod"
+
+func (batchInvMod) UUID() ID       { return uuid(batchInvModKey) }
+func (batchInvMod) NbInputs() int  { return -1 }
+func (batchInvMod) NbOutputs() int { return -1 }
+func (batchInvMod) String() string { return "BatchInvMod" }
+
+func (batchInvMod) Call(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	if len(inputs) != len(outputs) {
+		return errors.New("BatchInvMod expects as many outputs as inputs")
+	}
+
+	// get fr modulus
+	q := curveID.Info().Fr.Modulus()
+
+	// outputs[i] = inputs[0] * ... * inputs[i-1], skipping the zeros
+	acc := big.NewInt(1)
+	for i := 0; i < len(inputs); i++ {
+		outputs[i].Set(acc)
+		if inputs[i].Sign() != 0 {
+			acc.Mul(acc, inputs[i]).Mod(acc, q)
+		}
+	}
+	if acc.ModInverse(acc, q) == nil {
+		// an input is a multiple of the modulus, without being reduced
+		return errors.New("BatchInvMod expects inputs reduced modulo r")
+	}
+
+	// walk back: acc = 1 / (inputs[0] * ... * inputs[i])
+	for i := len(inputs) - 1; i >= 0; i-- {
+		if inputs[i].Sign() == 0 {
+			outputs[i].SetUint64(0)
+			continue
+		}
+		outputs[i].Mul(outputs[i], acc).Mod(outputs[i], q)
+		acc.Mul(acc, inputs[i]).Mod(acc, q)
+	}
+
+	return nil
+}
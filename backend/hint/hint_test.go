@@ -0,0 +1,376 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hint_test
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// hintCircuit asserts f(X) == 2 * X
+type hintCircuit struct {
+	X frontend.Variable
+	f hint.Function
+}
+
+func (circuit *hintCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.NewHint(circuit.f, circuit.X), api.Mul(circuit.X, 2))
+	return nil
+}
+
+func double(_ ecc.ID, inputs []*big.Int, result *big.Int) error {
+	result.Lsh(inputs[0], 1)
+	return nil
+}
+
+func panicking(_ ecc.ID, inputs []*big.Int, result *big.Int) error {
+	var outputs []*big.Int
+	outputs[1].Set(inputs[0]) // index out of range
+	return nil
+}
+
+func sleeping(_ ecc.ID, inputs []*big.Int, result *big.Int) error {
+	time.Sleep(time.Second)
+	result.Lsh(inputs[0], 1)
+	return nil
+}
+
+func TestHintFailures(t *testing.T) {
+	assert := require.New(t)
+
+	isSolved := map[backend.ID]func(frontend.CompiledConstraintSystem, frontend.Circuit, ...func(*backend.ProverOption) error) error{
+		backend.GROTH16: groth16.IsSolved,
+		backend.PLONK:   plonk.IsSolved,
+	}
+
+	var witness hintCircuit
+	witness.X.Assign(21)
+
+	for _, b := range backend.Implemented() {
+		compile := func(f hint.Function) frontend.CompiledConstraintSystem {
+			ccs, err := frontend.Compile(ecc.BN254, b, &hintCircuit{f: f})
+			assert.NoError(err)
+			return ccs
+		}
+
+		// a hint returning in time is not affected by the timeout
+		assert.NoError(isSolved[b](compile(double), &witness, backend.WithHints(double), backend.WithHintTimeout(time.Second)))
+
+		// a panic is reported as an error, with the hint name
+		err := isSolved[b](compile(panicking), &witness, backend.WithHints(panicking))
+		assert.Error(err)
+		assert.Contains(err.Error(), "hint_test.panicking")
+		assert.Contains(err.Error(), "index out of range")
+
+		// a hint which doesn't return in time makes the solver fail
+		start := time.Now()
+		err = isSolved[b](compile(sleeping), &witness, backend.WithHints(sleeping), backend.WithHintTimeout(10*time.Millisecond))
+		assert.ErrorIs(err, backend.ErrHintTimeout)
+		assert.Contains(err.Error(), "hint_test.sleeping")
+		assert.Less(int64(time.Since(start)), int64(time.Second))
+
+		// without timeout, the hint is waited for
+		assert.NoError(isSolved[b](compile(sleeping), &witness, backend.WithHints(sleeping)))
+	}
+
+	assert.Error(backend.WithHintTimeout(-time.Second)(&backend.ProverOption{}))
+}
+
+func TestUnexpectedHints(t *testing.T) {
+	assert := require.New(t)
+
+	var witness hintCircuit
+	witness.X.Assign(21)
+
+	for _, b := range backend.Implemented() {
+		ccs, err := frontend.Compile(ecc.BN254, b, &hintCircuit{f: double})
+		assert.NoError(err)
+		isSolved := groth16.IsSolved
+		if b == backend.PLONK {
+			isSolved = plonk.IsSolved
+		}
+
+		// the hints of the circuit work unchanged
+		assert.NoError(isSolved(ccs, &witness, backend.WithHints(double)))
+
+		// an extra hint the circuit doesn't reference is rejected, and named in the error
+		err = isSolved(ccs, &witness, backend.WithHints(double, sleeping))
+		var unexpected *backend.UnexpectedHintsError
+		assert.True(errors.As(err, &unexpected), "%v", err)
+		assert.Len(unexpected.Hints, 1)
+		assert.Contains(unexpected.Hints[0], "hint_test.sleeping")
+
+		// unless the hints are unsafe
+		assert.NoError(isSolved(ccs, &witness, backend.WithHints(double, sleeping), backend.WithUnsafeHints()))
+	}
+
+	// the builtin hints the circuit references are still available
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &builtinHintsCircuit{})
+	assert.NoError(err)
+	assert.Contains(ccs.ReferencedHints(), hint.IthBitNamed.UUID())
+	var builtinWitness builtinHintsCircuit
+	builtinWitness.X.Assign(10)
+	builtinWitness.Y.Assign(5)
+	assert.NoError(groth16.IsSolved(ccs, &builtinWitness))
+}
+
+// builtinHintsCircuit uses the builtin hints of ToBinary and Inverse
+type builtinHintsCircuit struct {
+	X, Y frontend.Variable
+}
+
+func (circuit *builtinHintsCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.ToBinary(circuit.X, 4)
+	api.AssertIsEqual(api.Mul(api.Inverse(circuit.Y), circuit.X), 2)
+	return nil
+}
+
+// namedHintCircuit asserts f(X) == 2 * X, for a hint with a stable name
+type namedHintCircuit struct {
+	X frontend.Variable
+	f hint.AnnotatedFunction
+}
+
+func (circuit *namedHintCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.NewAnnotatedHint(circuit.f, circuit.X)[0], api.Mul(circuit.X, 2))
+	return nil
+}
+
+// doubleRenamed is double after a refactoring
+func doubleRenamed(_ ecc.ID, inputs []*big.Int, result *big.Int) error {
+	result.Lsh(inputs[0], 1)
+	return nil
+}
+
+func TestNamedHint(t *testing.T) {
+	assert := require.New(t)
+
+	named := func(f hint.Function) hint.AnnotatedFunction {
+		return hint.NewFixedHintNamed("test/double", func(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+			return f(curveID, inputs, outputs[0])
+		}, 1, 1)
+	}
+
+	var witness hintCircuit
+	witness.X.Assign(21)
+
+	// the constraint systems are compiled with double
+	legacy, err := frontend.Compile(ecc.BN254, backend.GROTH16, &hintCircuit{f: double})
+	assert.NoError(err)
+	stable, err := frontend.Compile(ecc.BN254, backend.GROTH16, &namedHintCircuit{f: named(double)})
+	assert.NoError(err)
+
+	// and solved with doubleRenamed: the legacy ID changed with the function name, the constraint
+	// system doesn't reference it
+	err = groth16.IsSolved(legacy, &witness, backend.WithHints(doubleRenamed))
+	assert.ErrorIs(err, backend.ErrUnexpectedHint)
+	err = groth16.IsSolved(legacy, &witness, backend.WithHints(doubleRenamed), backend.WithUnsafeHints())
+	assert.Error(err)
+	assert.Contains(err.Error(), "hint 'github.com/consensys/gnark/backend/hint_test.double' not found")
+
+	// the stable ID didn't
+	assert.NoError(groth16.IsSolved(stable, &namedHintCircuit{X: witness.X}, backend.WithAnnotatedHints(named(doubleRenamed))))
+	assert.Equal(named(double).UUID(), named(doubleRenamed).UUID())
+	assert.NotEqual(hint.UUID(double), hint.UUID(doubleRenamed))
+
+	// the arities are part of the ID
+	assert.NotEqual(named(double).UUID(), hint.NewFixedHintNamed("test/double", nil, 2, 1).UUID())
+
+	// a missing hint is reported with its name
+	err = groth16.IsSolved(stable, &namedHintCircuit{X: witness.X})
+	assert.Error(err)
+	assert.Contains(err.Error(), "hint 'test/double' not found")
+}
+
+// tableCircuit asserts table[X] + offset == Y, the table (of 1024 entries) and the offset being static
+// parameters of the lookup hint
+type tableCircuit struct {
+	X      frontend.Variable
+	Y      frontend.Variable `gnark:",public"`
+	table  []byte
+	offset int64
+}
+
+func (circuit *tableCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.ToBinary(circuit.X, 10)
+	y := api.NewAnnotatedHint(tableLookup, hint.Static(circuit.table), hint.StaticInt(big.NewInt(circuit.offset)), circuit.X)[0]
+	api.AssertIsEqual(y, circuit.Y)
+	return nil
+}
+
+var tableLookup = hint.NewFixedHintNamedWithStatic("test/lookup", func(_ ecc.ID, staticParams [][]byte, inputs []*big.Int, outputs []*big.Int) error {
+	table := staticParams[0]
+	if !inputs[0].IsUint64() || inputs[0].Uint64() >= uint64(len(table)) {
+		return errors.New("index out of the table")
+	}
+	outputs[0].SetUint64(uint64(table[inputs[0].Uint64()]))
+	outputs[0].Add(outputs[0], new(big.Int).SetBytes(staticParams[1]))
+	return nil
+}, 2, 1, 1)
+
+func TestStaticHint(t *testing.T) {
+	assert := require.New(t)
+
+	table := make([]byte, 1024)
+	for i := range table {
+		table[i] = byte(i*7 + 3)
+	}
+	circuit := &tableCircuit{table: table, offset: 1000}
+
+	var witness, wrong tableCircuit
+	witness.X.Assign(700)
+	witness.Y.Assign(int(table[700]) + 1000)
+	wrong.X.Assign(700)
+	wrong.Y.Assign(int(table[701]) + 1000)
+
+	// the simulator gives the static parameters to the hint too
+	assert.NoError(test.IsSolved(circuit, &witness, ecc.BN254))
+	assert.Error(test.IsSolved(circuit, &wrong, ecc.BN254))
+
+	for _, b := range backend.Implemented() {
+		ccs, err := frontend.Compile(ecc.BN254, b, circuit)
+		assert.NoError(err)
+
+		// the static parameters are serialized with the constraint system
+		var buf bytes.Buffer
+		_, err = ccs.WriteTo(&buf)
+		assert.NoError(err)
+		assert.Greater(buf.Len(), len(table))
+
+		opt := backend.WithAnnotatedHints(tableLookup)
+		switch b {
+		case backend.GROTH16:
+			read := groth16.NewCS(ecc.BN254)
+			_, err = read.ReadFrom(&buf)
+			assert.NoError(err)
+
+			pk, vk, err := groth16.Setup(read)
+			assert.NoError(err)
+			proof, err := groth16.Prove(read, pk, &witness, opt)
+			assert.NoError(err)
+			assert.NoError(groth16.Verify(proof, vk, &witness))
+			_, err = groth16.Prove(read, pk, &wrong, opt)
+			assert.Error(err)
+		case backend.PLONK:
+			read := plonk.NewCS(ecc.BN254)
+			_, err = read.ReadFrom(&buf)
+			assert.NoError(err)
+
+			srs, err := test.NewKZGSRS(read)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(read, srs)
+			assert.NoError(err)
+			proof, err := plonk.Prove(read, pk, &witness, opt)
+			assert.NoError(err)
+			assert.NoError(plonk.Verify(proof, vk, &witness))
+			_, err = plonk.Prove(read, pk, &wrong, opt)
+			assert.Error(err)
+		}
+	}
+
+	// the number of static parameters is part of the ID, not their values
+	assert.NotEqual(tableLookup.UUID(), hint.NewFixedHintNamedWithStatic("test/lookup", nil, 1, 1, 1).UUID())
+	assert.NotEqual(tableLookup.UUID(), hint.NewFixedHintNamed("test/lookup", nil, 1, 1).UUID())
+
+	// the static parameters must match the hint
+	_, err := frontend.Compile(ecc.BN254, backend.GROTH16, &missingStaticCircuit{})
+	assert.Error(err)
+	assert.Contains(err.Error(), "hint test/lookup expects 2 static parameters, got 0")
+}
+
+// missingStaticCircuit calls the lookup hint without its static parameters
+type missingStaticCircuit struct {
+	X frontend.Variable
+}
+
+func (circuit *missingStaticCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.NewAnnotatedHint(tableLookup, circuit.X)[0], 0)
+	return nil
+}
+
+// chainedHintsCircuit asserts g(f(X)) == 4 * X before f(X) == 2 * X: the hint g reads the output of
+// the hint f, and is called first
+type chainedHintsCircuit struct {
+	X frontend.Variable
+}
+
+func (circuit *chainedHintsCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	a := api.NewHint(double, circuit.X)
+	b := api.NewHint(doubleRenamed, a)
+	api.AssertIsEqual(b, api.Mul(circuit.X, 4))
+	api.AssertIsEqual(a, api.Mul(circuit.X, 2))
+	return nil
+}
+
+func TestChainedHints(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	var witness, other chainedHintsCircuit
+	witness.X.Assign(21)
+	other.X.Assign(5)
+
+	hints := test.WithProverOpts(backend.WithHints(double, doubleRenamed))
+	assert.ProverSucceeded(&chainedHintsCircuit{}, &witness, test.WithCurves(ecc.BN254), hints)
+
+	// the R1CS solver follows a schedule with a solution cache, in which the upstream hint is called first
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &chainedHintsCircuit{})
+	assert.NoError(err)
+	cache := backend.NewSolutionCache()
+	for _, w := range []*chainedHintsCircuit{&witness, &other, &witness} {
+		assert.NoError(groth16.IsSolved(ccs, w, backend.WithHints(double, doubleRenamed), backend.WithSolutionCache(cache, "chain")))
+	}
+}
+
+func TestCyclicHints(t *testing.T) {
+	assert := require.New(t)
+
+	// the frontend can't create a cycle, the hints read wires which exist before them
+	input := func(wire int) compiled.LinearExpression {
+		return compiled.LinearExpression{compiled.Pack(wire, compiled.CoeffIdOne, compiled.Internal)}
+	}
+	f := compiled.Hint{Name: "f", Inputs: []compiled.LinearExpression{input(2)}, Wires: []int{1}}
+	g := compiled.Hint{Name: "g", Inputs: []compiled.LinearExpression{input(1)}, Wires: []int{2, 3}}
+	mHints := map[int]compiled.Hint{1: f, 2: g, 3: g}
+
+	err := compiled.LinkHints(mHints)
+	assert.Error(err)
+	assert.Contains(err.Error(), "cyclic hint dependencies")
+	assert.Contains(err.Error(), "hint f (wire 1)")
+	assert.Contains(err.Error(), "hint g (wire 2)")
+
+	// without the cycle, both wires of g record f
+	g.Inputs = []compiled.LinearExpression{input(1), input(0)}
+	f.Inputs = []compiled.LinearExpression{input(0)}
+	mHints = map[int]compiled.Hint{1: f, 2: g, 3: g}
+	assert.NoError(compiled.LinkHints(mHints))
+	assert.Empty(mHints[1].Deps)
+	assert.Equal([]int{1}, mHints[2].Deps)
+	assert.Equal([]int{1}, mHints[3].Deps)
+}
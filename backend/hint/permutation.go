@@ -0,0 +1,125 @@
+package hint
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// PermutationNetwork routes a permutation through a Beneš network, see frontend.API AssertIsPermutation.
+//
+// The inputs are a and b, two slices of n values, n a power of two greater or equal to 8, followed by zeros
+// such that there are as many inputs as switches in the network of size n: n*log2(n) - n/2. The outputs
+// are the switches settings (0: straight, 1: crossed) routing a to b, in the order of NbPermutationSwitches.
+// It fails if b is not a permutation of a.
+var PermutationNetwork = NewFixedHintNamed("gnark/permutation-network", routePermutation, -1, -1)
+
+// NbPermutationSwitches returns the number of switches of a Beneš network of size n (a power of two)
+//
+// The network of size 2 is a single switch. The network of size n > 2 is a column of n/2 switches, whose
+// outputs 2i and 2i+1 are the inputs i of the upper and lower networks of size n/2, whose outputs i are
+// the inputs of the switch i of a last column of n/2 switches. The switches are ordered as: first column,
+// upper network, lower network, last column.
+func NbPermutationSwitches(n int) int {
+	if n <= 2 {
+		return n / 2
+	}
+	return n + 2*NbPermutationSwitches(n/2)
+}
+
+func routePermutation(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	n := 8
+	for NbPermutationSwitches(n) < len(inputs) {
+		n *= 2
+	}
+	if NbPermutationSwitches(n) != len(inputs) || len(outputs) != len(inputs) {
+		return errors.New("PermutationNetwork expects a and b followed by zeros, as many inputs as switches")
+	}
+
+	// p[j] is the index in a of b[j]; equal values are matched in order
+	q := curveID.Info().Fr.Modulus()
+	indexes := make(map[string][]int, n)
+	var v big.Int
+	for i := 0; i < n; i++ {
+		k := v.Mod(inputs[i], q).String()
+		indexes[k] = append(indexes[k], i)
+	}
+	p := make([]int, n)
+	for j := 0; j < n; j++ {
+		k := v.Mod(inputs[n+j], q).String()
+		if len(indexes[k]) == 0 {
+			return errors.New("PermutationNetwork: b is not a permutation of a")
+		}
+		p[j], indexes[k] = indexes[k][0], indexes[k][1:]
+	}
+
+	switches := routeBenes(p, make([]bool, 0, len(outputs)))
+	for i := range outputs {
+		outputs[i].SetUint64(0)
+		if switches[i] {
+			outputs[i].SetUint64(1)
+		}
+	}
+	return nil
+}
+
+// routeBenes appends to switches the settings of the Beneš network such that its output j is its
+// input p[j], with the looping algorithm
+func routeBenes(p []int, switches []bool) []bool {
+	n := len(p)
+	if n == 2 {
+		return append(switches, p[0] == 1)
+	}
+
+	inv := make([]int, n) // input i goes to output inv[i]
+	for j, i := range p {
+		inv[i] = j
+	}
+
+	// subnetwork (0: upper, 1: lower) of each input and output. The inputs 2i and 2i+1 go to
+	// different subnetworks, as do the outputs 2j and 2j+1.
+	const unset = -1
+	inSub, outSub := make([]int, n), make([]int, n)
+	for i := range inSub {
+		inSub[i], outSub[i] = unset, unset
+	}
+	for start := 0; start < n; start += 2 {
+		if outSub[start] != unset {
+			continue
+		}
+		for j := start; outSub[j] == unset; {
+			// output j comes from the upper network, and so does its input
+			outSub[j] = 0
+			i := p[j]
+			inSub[i] = 0
+			// the other input of the switch goes to the lower network, and so does its output
+			inSub[i^1] = 1
+			j = inv[i^1]
+			outSub[j] = 1
+			// the other output of the switch comes from the upper network
+			j ^= 1
+		}
+	}
+
+	// first column, and the permutations of the subnetworks
+	pUpper, pLower := make([]int, n/2), make([]int, n/2)
+	for i := 0; i < n; i += 2 {
+		switches = append(switches, inSub[i] == 1)
+	}
+	for j := 0; j < n; j++ {
+		if outSub[j] == 0 {
+			pUpper[j/2] = p[j] / 2
+		} else {
+			pLower[j/2] = p[j] / 2
+		}
+	}
+	switches = routeBenes(pUpper, switches)
+	switches = routeBenes(pLower, switches)
+
+	// last column
+	for j := 0; j < n; j += 2 {
+		switches = append(switches, outSub[j] == 1)
+	}
+	return switches
+}
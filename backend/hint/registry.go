@@ -0,0 +1,255 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hint
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Registration is a hint function known to the solvers, and where it was registered
+type Registration struct {
+	Function AnnotatedFunction
+
+	// CallSite is the file:line of the call to Register, "builtin" for the hints of Builtins, and
+	// empty for the hints given to the prover (see backend.WithAnnotatedHints)
+	CallSite string
+}
+
+func (r Registration) String() string {
+	switch r.CallSite {
+	case "":
+		return fmt.Sprintf("%q (given to the prover)", r.Function.String())
+	case builtinCallSite:
+		return fmt.Sprintf("%q (builtin)", r.Function.String())
+	}
+	return fmt.Sprintf("%q (registered at %s)", r.Function.String(), r.CallSite)
+}
+
+// Collision is a pair of distinct hint functions with the same ID: a solver can't tell which one
+// a constraint system refers to. Two functions are distinct if their names (String()) differ.
+type Collision struct {
+	ID            ID
+	First, Second Registration
+}
+
+func (c *Collision) Error() string {
+	return fmt.Sprintf("duplicate hint function with id %#x: %s and %s; rename one of them (see NewFixedHintNamed)", uint64(c.ID), c.First, c.Second)
+}
+
+var registry struct {
+	sync.RWMutex
+	registrations []Registration
+}
+
+// Register makes f available to the solvers of all the constraint systems, in addition to the
+// builtins and the hints given to the prover (see backend.WithAnnotatedHints). It is typically
+// called in the init function of the package defining f.
+//
+// Register panics if a distinct function with the same ID is a builtin or is registered (see Collision);
+// registering the same function again is a no-op. The registration is recorded before panicking,
+// such that CheckRegistry reports it if the panic is recovered.
+func Register(f AnnotatedFunction) {
+	callSite := "unknown"
+	if _, file, line, ok := runtime.Caller(1); ok {
+		callSite = fmt.Sprintf("%s:%d", file, line)
+	}
+	r := Registration{Function: f, CallSite: callSite}
+
+	registry.Lock()
+	defer registry.Unlock()
+	if c := findCollision(builtinRegistrations(), registry.registrations, r); c != nil {
+		registry.registrations = append(registry.registrations, r)
+		panic(c.Error())
+	}
+	for _, other := range registry.registrations {
+		if sameFunction(other.Function, f) {
+			return
+		}
+	}
+	registry.registrations = append(registry.registrations, r)
+}
+
+// Registered returns the hint functions registered with Register
+func Registered() []AnnotatedFunction {
+	registry.RLock()
+	defer registry.RUnlock()
+	res := make([]AnnotatedFunction, len(registry.registrations))
+	for i, r := range registry.registrations {
+		res[i] = r.Function
+	}
+	return res
+}
+
+// Description describes a hint function known to the solvers, see Describe
+type Description struct {
+	UUID         ID
+	Name         string
+	TotalInputs  int    // -1 if the number of inputs varies
+	TotalOutputs int    // -1 if the number of outputs varies
+	RegisteredAt string // see Registration.CallSite
+}
+
+func (d Description) String() string {
+	return fmt.Sprintf("%q id=%#x inputs=%s outputs=%s registered=%s", d.Name, uint64(d.UUID), arity(d.TotalInputs), arity(d.TotalOutputs), d.RegisteredAt)
+}
+
+func arity(n int) string {
+	if n < 0 {
+		return "any"
+	}
+	return fmt.Sprint(n)
+}
+
+// Describe returns the descriptions of the builtins and the registered hint functions, sorted by name then
+// ID: the hint functions a binary provides to the solvers, without the ones given to the prover. Compare
+// them with the hint functions a constraint system requires, see frontend.CompiledConstraintSystem.RequiredHints
+// and backend.CheckHintAvailability.
+func Describe() []Description {
+	registry.RLock()
+	all := append(builtinRegistrations(), registry.registrations...)
+	registry.RUnlock()
+
+	res := make([]Description, len(all))
+	for i, r := range all {
+		res[i] = Description{
+			UUID:         r.Function.UUID(),
+			Name:         r.Function.String(),
+			TotalInputs:  r.Function.NbInputs(),
+			TotalOutputs: r.Function.NbOutputs(),
+			RegisteredAt: r.CallSite,
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+		return res[i].UUID < res[j].UUID
+	})
+	return res
+}
+
+// Reference is a hint function a constraint system calls, by ID and name (empty for the constraint
+// systems compiled before the names were recorded)
+type Reference struct {
+	UUID ID
+	Name string
+}
+
+// CheckRegistry returns the collisions between the builtins, the registered hint functions, and
+// hintFunctions (the hints given to the prover). It is meant for tests: a collision makes Register
+// panic, and the prover fail.
+func CheckRegistry(hintFunctions ...AnnotatedFunction) []Collision {
+	registry.RLock()
+	all := append(builtinRegistrations(), registry.registrations...)
+	registry.RUnlock()
+	for _, f := range hintFunctions {
+		all = append(all, Registration{Function: f})
+	}
+
+	var res []Collision
+	for i := 1; i < len(all); i++ {
+		if c := findCollision(all[:i], nil, all[i]); c != nil {
+			res = append(res, *c)
+		}
+	}
+	return res
+}
+
+// Functions returns the hint functions available to a solver, by ID: the builtins, the registered hint
+// functions, and hintFunctions (the hints given to the prover). It returns a *Collision error if two
+// distinct functions have the same ID.
+func Functions(hintFunctions []AnnotatedFunction) (map[ID]AnnotatedFunction, error) {
+	return functions(nil, hintFunctions)
+}
+
+// FunctionsFor is Functions for a constraint system referencing the hint functions of IDs referenced: the
+// builtins and the registered hint functions of other IDs are left out, such that they can't collide
+// with hintFunctions.
+func FunctionsFor(referenced map[ID]string, hintFunctions []AnnotatedFunction) (map[ID]AnnotatedFunction, error) {
+	return functions(referenced, hintFunctions)
+}
+
+// functions implements Functions and FunctionsFor; the builtins and the registered hints are filtered
+// by referenced if it isn't nil
+func functions(referenced map[ID]string, hintFunctions []AnnotatedFunction) (map[ID]AnnotatedFunction, error) {
+	registry.RLock()
+	all := append(builtinRegistrations(), registry.registrations...)
+	registry.RUnlock()
+	if referenced != nil {
+		n := 0
+		for _, r := range all {
+			if _, ok := referenced[r.Function.UUID()]; ok {
+				all[n] = r
+				n++
+			}
+		}
+		all = all[:n]
+	}
+	for _, f := range hintFunctions {
+		all = append(all, Registration{Function: f})
+	}
+
+	res := make(map[ID]AnnotatedFunction, len(all))
+	byID := make(map[ID]Registration, len(all))
+	for _, r := range all {
+		id := r.Function.UUID()
+		if other, ok := byID[id]; ok {
+			if sameFunction(other.Function, r.Function) {
+				continue
+			}
+			return nil, &Collision{ID: id, First: other, Second: r}
+		}
+		byID[id] = r
+		res[id] = r.Function
+	}
+	return res, nil
+}
+
+var builtins = Builtins()
+
+const builtinCallSite = "builtin"
+
+// builtinRegistrations returns the builtins as registrations
+func builtinRegistrations() []Registration {
+	res := make([]Registration, len(builtins))
+	for i, f := range builtins {
+		res[i] = Registration{Function: f, CallSite: builtinCallSite}
+	}
+	return res
+}
+
+// findCollision returns the collision of r with a registration of a or b, or nil
+func findCollision(a, b []Registration, r Registration) *Collision {
+	id := r.Function.UUID()
+	for _, set := range [][]Registration{a, b} {
+		for _, other := range set {
+			if other.Function.UUID() == id && !sameFunction(other.Function, r.Function) {
+				return &Collision{ID: id, First: other, Second: r}
+			}
+		}
+	}
+	return nil
+}
+
+// sameFunction returns true if a and b are the same hint function: same ID and same name.
+// The functions themselves can't be compared, and nothing distinguishes them in a constraint system.
+func sameFunction(a, b AnnotatedFunction) bool {
+	return a.UUID() == b.UUID() && a.String() == b.String()
+}
@@ -0,0 +1,190 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hint_test
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+func doubleOutputs(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	outputs[0].Lsh(inputs[0], 1)
+	return nil
+}
+
+// forcedCollision has the ID of another hint
+type forcedCollision struct {
+	hint.AnnotatedFunction
+	id hint.ID
+}
+
+func (h forcedCollision) UUID() hint.ID { return h.id }
+
+// register calls hint.Register, and returns its panic
+func register(f hint.AnnotatedFunction) (err interface{}) {
+	defer func() {
+		err = recover()
+	}()
+	hint.Register(f)
+	return nil
+}
+
+func TestCollisions(t *testing.T) {
+	assert := require.New(t)
+	defer hint.ResetRegistry()
+
+	// these names collide under the 32-bit IDs, but not under the 64-bit ones
+	const name1, name2 = "collision/522789", "collision/739192"
+	fnv32 := func(name string) uint32 {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(fmt.Sprintf("%s(1,1)", name)))
+		return h.Sum32()
+	}
+	assert.Equal(fnv32(name1), fnv32(name2))
+	h1 := hint.NewFixedHintNamed(name1, doubleOutputs, 1, 1)
+	h2 := hint.NewFixedHintNamed(name2, doubleOutputs, 1, 1)
+	assert.NotEqual(h1.UUID(), h2.UUID())
+	assert.Empty(hint.CheckRegistry(h1, h2))
+
+	// the builtins don't collide, including their 32-bit IDs
+	assert.Empty(hint.CheckRegistry())
+
+	var witness namedHintCircuit
+	witness.X.Assign(21)
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &namedHintCircuit{f: h1})
+	assert.NoError(err)
+
+	// a registered hint is solved without being given to the prover; registering it again,
+	// or giving it to the prover too, is fine
+	hint.Register(h1)
+	assert.Nil(register(h1))
+	assert.NoError(groth16.IsSolved(ccs, &witness))
+	assert.NoError(groth16.IsSolved(ccs, &witness, backend.WithAnnotatedHints(h1)))
+	assert.Empty(hint.CheckRegistry(h1, h2))
+
+	// a hint forced to collide with h1 fails the prover, and the error names both
+	colliding := forcedCollision{AnnotatedFunction: h2, id: h1.UUID()}
+	err = groth16.IsSolved(ccs, &witness, backend.WithAnnotatedHints(colliding))
+	var collision *hint.Collision
+	assert.True(errors.As(err, &collision), "%v", err)
+	assert.Equal(h1.UUID(), collision.ID)
+	assert.Contains(err.Error(), fmt.Sprintf("%q (registered at ", name1))
+	assert.Contains(err.Error(), "registry_test.go:")
+	assert.Contains(err.Error(), fmt.Sprintf("%q (given to the prover)", name2))
+
+	collisions := hint.CheckRegistry(colliding)
+	assert.Len(collisions, 1)
+	assert.Equal(name1, collisions[0].First.Function.String())
+	assert.Equal(name2, collisions[0].Second.Function.String())
+
+	// registering it panics, with the call sites of both registrations
+	p := register(colliding)
+	assert.NotNil(p)
+	msg := fmt.Sprint(p)
+	assert.Contains(msg, name1)
+	assert.Contains(msg, name2)
+	assert.Contains(msg, "registry_test.go:")
+	assert.Len(hint.CheckRegistry(), 1)
+
+	// as it does with a builtin
+	hint.ResetRegistry()
+	p = register(forcedCollision{AnnotatedFunction: h2, id: hint.IthBitNamed.UUID()})
+	assert.NotNil(p)
+	assert.Contains(fmt.Sprint(p), fmt.Sprintf("%q (builtin)", hint.IthBitNamed.String()))
+}
+
+func TestDescribe(t *testing.T) {
+	assert := require.New(t)
+	defer hint.ResetRegistry()
+
+	// the builtins, sorted by name
+	builtins := hint.Describe()
+	assert.Len(builtins, len(hint.Builtins()))
+	for i, d := range builtins {
+		assert.Equal("builtin", d.RegisteredAt)
+		if i > 0 {
+			assert.True(builtins[i-1].Name < d.Name || (builtins[i-1].Name == d.Name && builtins[i-1].UUID < d.UUID))
+		}
+	}
+
+	// a registered hint is described with its call site, at its place in the order
+	h := hint.NewFixedHintNamed("describe/double", doubleOutputs, 1, 1)
+	hint.Register(h)
+	described := hint.Describe()
+	assert.Len(described, len(builtins)+1)
+	assert.Equal(described, hint.Describe())
+	i := 0
+	for described[i].Name != "describe/double" {
+		i++
+	}
+	d := described[i]
+	assert.Equal(h.UUID(), d.UUID)
+	assert.Equal(1, d.TotalInputs)
+	assert.Equal(1, d.TotalOutputs)
+	assert.Contains(d.RegisteredAt, "registry_test.go:")
+	assert.Equal(fmt.Sprintf(`"describe/double" id=%#x inputs=1 outputs=1 registered=%s`, uint64(h.UUID()), d.RegisteredAt), d.String())
+	assert.Equal(builtins, append(described[:i:i], described[i+1:]...))
+
+	// the variadic hints
+	for _, d := range described {
+		if d.Name == hint.BatchInvMod.String() {
+			assert.Contains(d.String(), "inputs=any outputs=any")
+		}
+	}
+}
+
+func TestHintAvailability(t *testing.T) {
+	assert := require.New(t)
+	defer hint.ResetRegistry()
+
+	h := hint.NewFixedHintNamed("availability/double", doubleOutputs, 1, 1)
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &namedHintCircuit{f: h})
+	assert.NoError(err)
+	assert.Equal([]hint.Reference{{UUID: h.UUID(), Name: "availability/double"}}, ccs.RequiredHints())
+
+	// the hint is neither registered nor given to the prover
+	err = backend.CheckHintAvailability(ccs)
+	var missing *backend.MissingHintsError
+	assert.True(errors.As(err, &missing), "%v", err)
+	assert.ErrorIs(err, backend.ErrMissingHint)
+	assert.Equal([]string{fmt.Sprintf("%q (id %#x)", "availability/double", uint64(h.UUID()))}, missing.Hints)
+
+	// the prover would fail the same way
+	var witness namedHintCircuit
+	witness.X.Assign(21)
+	assert.Error(groth16.IsSolved(ccs, &witness))
+
+	// given to the prover, or registered
+	assert.NoError(backend.CheckHintAvailability(ccs, backend.WithAnnotatedHints(h)))
+	hint.Register(h)
+	assert.NoError(backend.CheckHintAvailability(ccs))
+
+	// the hints the constraint system doesn't reference are reported as by the solvers
+	err = backend.CheckHintAvailability(ccs, backend.WithHints(double))
+	assert.ErrorIs(err, backend.ErrUnexpectedHint)
+	assert.NoError(backend.CheckHintAvailability(ccs, backend.WithHints(double), backend.WithUnsafeHints()))
+}
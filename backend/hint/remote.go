@@ -0,0 +1,360 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hint
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/internal/curves"
+)
+
+// Remote hints are computed by an external process (the responder), reached through a unix socket
+// or a TCP connection. Each call opens a connection, sends a request and reads a response.
+//
+// Both messages are framed: a 4 bytes big endian length followed by the payload. In the payload,
+// byte strings and big integers (big endian, absolute value) are prefixed by their 4 bytes length.
+//
+//	request:  name | curveID (uint16) | nbOutputs (uint32) | nbInputs (uint32) | inputs...
+//	response: 0 (byte) | nbOutputs (uint32) | outputs...
+//	          1 (byte) | error message
+const (
+	statusOK byte = iota
+	statusError
+)
+
+// maxRemoteFrameSize bounds the size of the messages read from the wire
+const maxRemoteFrameSize = 1 << 24
+
+// DefaultRemoteTimeout is the default timeout of a remote hint call, see WithRemoteTimeout
+const DefaultRemoteTimeout = 30 * time.Second
+
+var (
+	// ErrRemoteConnection is returned when the responder can't be reached or the connection fails
+	ErrRemoteConnection = errors.New("remote hint: connection failed")
+
+	// ErrRemoteProtocol is returned when a malformed message is received
+	ErrRemoteProtocol = errors.New("remote hint: protocol error")
+
+	// ErrRemoteHint is returned when the responder fails to compute the hint
+	ErrRemoteHint = errors.New("remote hint: responder error")
+)
+
+// RemoteOption configures a remote hint, see NewRemoteHint
+type RemoteOption func(*remoteHint)
+
+// WithRemoteTimeout sets the timeout of a remote hint call (connection and exchange)
+func WithRemoteTimeout(timeout time.Duration) RemoteOption {
+	return func(h *remoteHint) {
+		h.timeout = timeout
+	}
+}
+
+// NewRemoteHint returns a hint with nIn inputs and nOut outputs, computed by the responder
+// listening on endpoint (unix://path or tcp://host:port), see ServeRemoteHints.
+//
+// The ID of the hint is derived from its name and arity; the solver returns ErrRemoteConnection,
+// ErrRemoteProtocol or ErrRemoteHint (wrapped) if a call fails.
+func NewRemoteHint(name string, nIn, nOut int, endpoint string, opts ...RemoteOption) AnnotatedFunction {
+	h := &remoteHint{
+		name:    name,
+		nIn:     nIn,
+		nOut:    nOut,
+		timeout: DefaultRemoteTimeout,
+	}
+	h.network, h.address, h.err = parseEndpoint(endpoint)
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+type remoteHint struct {
+	name             string
+	nIn, nOut        int
+	network, address string
+	err              error // invalid endpoint
+	timeout          time.Duration
+}
+
+func (h *remoteHint) UUID() ID {
+	return namedUUID(h.name, h.nIn, h.nOut)
+}
+
+func (h *remoteHint) NbInputs() int  { return h.nIn }
+func (h *remoteHint) NbOutputs() int { return h.nOut }
+func (h *remoteHint) String() string { return h.name }
+
+func (h *remoteHint) Call(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	if h.err != nil {
+		return fmt.Errorf("%w: %v", ErrRemoteConnection, h.err)
+	}
+	if len(inputs) != h.nIn || len(outputs) != h.nOut {
+		return fmt.Errorf("%s expects %d inputs and %d outputs, got %d and %d", h.name, h.nIn, h.nOut, len(inputs), len(outputs))
+	}
+
+	conn, err := net.DialTimeout(h.network, h.address, h.timeout)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRemoteConnection, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(h.timeout)); err != nil {
+		return fmt.Errorf("%w: %v", ErrRemoteConnection, err)
+	}
+
+	var req bytes.Buffer
+	writeBytes(&req, []byte(h.name))
+	writeUint16(&req, uint16(curveID))
+	writeUint32(&req, uint32(len(outputs)))
+	writeUint32(&req, uint32(len(inputs)))
+	for _, in := range inputs {
+		writeBytes(&req, in.Bytes())
+	}
+	if err := writeFrame(conn, req.Bytes()); err != nil {
+		return err
+	}
+
+	resp, err := readFrame(conn)
+	if err != nil {
+		return err
+	}
+	r := bytes.NewReader(resp)
+	status, err := r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("%w: empty response", ErrRemoteProtocol)
+	}
+	switch status {
+	case statusOK:
+		if err := readBigInts(r, outputs); err != nil {
+			return err
+		}
+	case statusError:
+		msg, _ := io.ReadAll(r)
+		return fmt.Errorf("%w: %s", ErrRemoteHint, msg)
+	default:
+		return fmt.Errorf("%w: unknown response status %d", ErrRemoteProtocol, status)
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("%w: %d trailing bytes in response", ErrRemoteProtocol, r.Len())
+	}
+	return nil
+}
+
+// RemoteHandler computes the outputs of a remote hint; len(outputs) is the number
+// of outputs expected by the caller.
+type RemoteHandler func(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error
+
+// ServeRemoteHints is a reference responder for hints created with NewRemoteHint;
+// handlers are indexed by hint name. Each connection is served in its own goroutine.
+//
+// It blocks until l is closed (then it returns nil) or fails to accept a connection.
+func ServeRemoteHints(l net.Listener, handlers map[string]RemoteHandler) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go serveRemoteHint(conn, handlers)
+	}
+}
+
+func serveRemoteHint(conn net.Conn, handlers map[string]RemoteHandler) {
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(DefaultRemoteTimeout)); err != nil {
+		return
+	}
+
+	req, err := readFrame(conn)
+	if err != nil {
+		return
+	}
+
+	var resp bytes.Buffer
+	outputs, err := computeRemoteHint(req, handlers)
+	if err != nil {
+		resp.WriteByte(statusError)
+		resp.WriteString(err.Error())
+	} else {
+		resp.WriteByte(statusOK)
+		writeUint32(&resp, uint32(len(outputs)))
+		for _, out := range outputs {
+			writeBytes(&resp, out.Bytes())
+		}
+	}
+	_ = writeFrame(conn, resp.Bytes())
+}
+
+// computeRemoteHint decodes a request and calls the matching handler
+func computeRemoteHint(req []byte, handlers map[string]RemoteHandler) ([]*big.Int, error) {
+	r := bytes.NewReader(req)
+	name, err := readBytes(r)
+	if err != nil {
+		return nil, err
+	}
+	var curveID uint16
+	var nbOutputs uint32
+	if err := binary.Read(r, binary.BigEndian, &curveID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRemoteProtocol, err)
+	}
+	if err := binary.Read(r, binary.BigEndian, &nbOutputs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRemoteProtocol, err)
+	}
+	inputs, err := readBigIntSlice(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes in request", ErrRemoteProtocol, r.Len())
+	}
+
+	handler, ok := handlers[string(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown hint %q", name)
+	}
+	curve := ecc.ID(curveID)
+	if !curves.IsImplemented(curve) {
+		return nil, fmt.Errorf("unknown curve %d", curveID)
+	}
+	if nbOutputs > maxRemoteFrameSize {
+		return nil, fmt.Errorf("%w: too many outputs (%d)", ErrRemoteProtocol, nbOutputs)
+	}
+
+	outputs := make([]*big.Int, nbOutputs)
+	for i := range outputs {
+		outputs[i] = new(big.Int)
+	}
+	if err := handler(curve, inputs, outputs); err != nil {
+		return nil, err
+	}
+
+	// the outputs are sent as absolute values
+	q := curve.Info().Fr.Modulus()
+	for _, out := range outputs {
+		out.Mod(out, q)
+	}
+	return outputs, nil
+}
+
+func parseEndpoint(endpoint string) (network, address string, err error) {
+	for _, network := range []string{"unix", "tcp"} {
+		if address := strings.TrimPrefix(endpoint, network+"://"); address != endpoint {
+			return network, address, nil
+		}
+	}
+	return "", "", fmt.Errorf("invalid endpoint %q, expected unix://path or tcp://host:port", endpoint)
+}
+
+func writeFrame(w io.Writer, payload []byte) error {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], uint32(len(payload)))
+	if _, err := w.Write(append(buf[:], payload...)); err != nil {
+		return fmt.Errorf("%w: %v", ErrRemoteConnection, err)
+	}
+	return nil
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	var buf [4]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRemoteConnection, err)
+	}
+	size := binary.BigEndian.Uint32(buf[:])
+	if size > maxRemoteFrameSize {
+		return nil, fmt.Errorf("%w: message too large (%d bytes)", ErrRemoteProtocol, size)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRemoteConnection, err)
+	}
+	return payload, nil
+}
+
+func writeUint16(b *bytes.Buffer, v uint16) {
+	var buf [2]byte
+	binary.BigEndian.PutUint16(buf[:], v)
+	b.Write(buf[:])
+}
+
+func writeUint32(b *bytes.Buffer, v uint32) {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	b.Write(buf[:])
+}
+
+func writeBytes(b *bytes.Buffer, v []byte) {
+	writeUint32(b, uint32(len(v)))
+	b.Write(v)
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRemoteProtocol, err)
+	}
+	if int64(size) > int64(r.Len()) {
+		return nil, fmt.Errorf("%w: truncated message", ErrRemoteProtocol)
+	}
+	v := make([]byte, size)
+	_, _ = r.Read(v)
+	return v, nil
+}
+
+// readBigInts reads a length-prefixed list of len(res) big integers in res
+func readBigInts(r *bytes.Reader, res []*big.Int) error {
+	v, err := readBigIntSlice(r)
+	if err != nil {
+		return err
+	}
+	if len(v) != len(res) {
+		return fmt.Errorf("%w: expected %d values, got %d", ErrRemoteProtocol, len(res), len(v))
+	}
+	for i := range v {
+		res[i].Set(v[i])
+	}
+	return nil
+}
+
+func readBigIntSlice(r *bytes.Reader) ([]*big.Int, error) {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRemoteProtocol, err)
+	}
+	// each value takes at least 4 bytes
+	if int64(n)*4 > int64(r.Len()) {
+		return nil, fmt.Errorf("%w: truncated message", ErrRemoteProtocol)
+	}
+	res := make([]*big.Int, n)
+	for i := range res {
+		b, err := readBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = new(big.Int).SetBytes(b)
+	}
+	return res, nil
+}
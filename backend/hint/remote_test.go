@@ -0,0 +1,157 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hint_test
+
+import (
+	"errors"
+	"math/big"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// divModCircuit computes the euclidean division of A by B with a remote hint
+type divModCircuit struct {
+	A, B frontend.Variable
+	Q, R frontend.Variable `gnark:",public"`
+	hint hint.AnnotatedFunction
+}
+
+func (circuit *divModCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	res := api.NewAnnotatedHint(circuit.hint, circuit.A, circuit.B)
+	api.AssertIsEqual(res[0], circuit.Q)
+	api.AssertIsEqual(res[1], circuit.R)
+	api.AssertIsEqual(api.Add(api.Mul(res[0], circuit.B), res[1]), circuit.A)
+	return nil
+}
+
+func divMod(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	if inputs[1].Sign() == 0 {
+		return errors.New("division by zero")
+	}
+	outputs[0].DivMod(inputs[0], inputs[1], outputs[1])
+	return nil
+}
+
+// serve starts a responder on a unix socket and returns its endpoint
+func serve(t *testing.T, handlers map[string]hint.RemoteHandler) string {
+	path := filepath.Join(t.TempDir(), "hints.sock")
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() { done <- hint.ServeRemoteHints(l, handlers) }()
+	t.Cleanup(func() {
+		require.NoError(t, l.Close())
+		require.NoError(t, <-done)
+	})
+
+	return "unix://" + path
+}
+
+func divModWitness(a, b, q, r int) *divModCircuit {
+	var witness divModCircuit
+	witness.A.Assign(a)
+	witness.B.Assign(b)
+	witness.Q.Assign(q)
+	witness.R.Assign(r)
+	return &witness
+}
+
+func TestRemoteHint(t *testing.T) {
+	endpoint := serve(t, map[string]hint.RemoteHandler{"divmod": divMod})
+	remote := hint.NewRemoteHint("divmod", 2, 2, endpoint)
+
+	assert := test.NewAssert(t)
+	assert.ProverSucceeded(&divModCircuit{hint: remote}, divModWitness(23, 5, 4, 3),
+		test.WithBackends(backend.GROTH16), test.WithCurves(ecc.BN254),
+		test.WithProverOpts(backend.WithAnnotatedHints(remote)))
+}
+
+func TestRemoteHintErrors(t *testing.T) {
+	assert := require.New(t)
+
+	endpoint := serve(t, map[string]hint.RemoteHandler{
+		"divmod": divMod,
+		"slow": func(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+			time.Sleep(time.Second)
+			return divMod(curveID, inputs, outputs)
+		},
+	})
+
+	prove := func(h hint.AnnotatedFunction, witness *divModCircuit) error {
+		ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &divModCircuit{hint: h})
+		assert.NoError(err)
+		pk, err := groth16.DummySetup(ccs)
+		assert.NoError(err)
+		_, err = groth16.Prove(ccs, pk, witness, backend.WithAnnotatedHints(h))
+		return err
+	}
+
+	// the responder fails to compute the hint
+	err := prove(hint.NewRemoteHint("divmod", 2, 2, endpoint), divModWitness(23, 0, 0, 0))
+	assert.True(errors.Is(err, hint.ErrRemoteHint), err)
+	assert.Contains(err.Error(), "division by zero")
+
+	// unknown hint
+	err = prove(hint.NewRemoteHint("mod", 2, 2, endpoint), divModWitness(23, 5, 4, 3))
+	assert.True(errors.Is(err, hint.ErrRemoteHint), err)
+
+	// timeout
+	err = prove(hint.NewRemoteHint("slow", 2, 2, endpoint, hint.WithRemoteTimeout(10*time.Millisecond)), divModWitness(23, 5, 4, 3))
+	assert.True(errors.Is(err, hint.ErrRemoteConnection), err)
+
+	// no responder
+	err = prove(hint.NewRemoteHint("divmod", 2, 2, endpoint+".missing"), divModWitness(23, 5, 4, 3))
+	assert.True(errors.Is(err, hint.ErrRemoteConnection), err)
+
+	// invalid endpoint
+	err = prove(hint.NewRemoteHint("divmod", 2, 2, "localhost:1234"), divModWitness(23, 5, 4, 3))
+	assert.True(errors.Is(err, hint.ErrRemoteConnection), err)
+}
+
+func TestRemoteHintProtocol(t *testing.T) {
+	assert := require.New(t)
+
+	// a responder which doesn't speak the protocol
+	path := filepath.Join(t.TempDir(), "hints.sock")
+	l, err := net.Listen("unix", path)
+	assert.NoError(err)
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	}()
+
+	remote := hint.NewRemoteHint("divmod", 2, 2, "unix://"+path)
+	outputs := []*big.Int{new(big.Int), new(big.Int)}
+	err = remote.Call(ecc.BN254, []*big.Int{big.NewInt(23), big.NewInt(5)}, outputs)
+	assert.True(errors.Is(err, hint.ErrRemoteProtocol), err)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hint
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// Static is a static parameter of a hint: a constant known when the circuit is defined (a table of
+// constants, a domain separation tag...) which is not a circuit variable.
+//
+// It is given to API.NewAnnotatedHint among the inputs, recorded as is in the compiled constraint
+// system, and delivered in order to FunctionWithStatic.CallWithStatic when the hint is solved.
+type Static []byte
+
+// StaticInt returns the static parameter encoding v, in big endian (see big.Int Bytes and SetBytes).
+// The sign of v is dropped.
+func StaticInt(v *big.Int) Static {
+	return Static(v.Bytes())
+}
+
+// FunctionWithStatic is a hint function which receives static parameters besides its inputs.
+//
+// Its ID covers the number of static parameters, not their values: the same hint may be recorded with
+// different tables in a constraint system.
+type FunctionWithStatic interface {
+	AnnotatedFunction
+
+	// NbStatic returns the number of static parameters of the hint, or -1 if it accepts any number
+	NbStatic() int
+
+	// CallWithStatic computes the outputs of the hint; len(outputs) == NbOutputs().
+	// Call is CallWithStatic without static parameters.
+	CallWithStatic(curveID ecc.ID, staticParams [][]byte, inputs []*big.Int, outputs []*big.Int) error
+}
+
+// NewFixedHintNamedWithStatic is like NewFixedHintNamed, for a hint with nStatic static parameters
+// (-1 for any number). Its ID is derived from name, nStatic, nIn and nOut only.
+func NewFixedHintNamedWithStatic(name string, f func(curveID ecc.ID, staticParams [][]byte, inputs []*big.Int, outputs []*big.Int) error, nStatic, nIn, nOut int) FunctionWithStatic {
+	if nStatic < -1 || nIn < -1 || nOut < -1 || nOut == 0 {
+		panic(fmt.Sprintf("hint %s: invalid arity (%d; %d, %d)", name, nStatic, nIn, nOut))
+	}
+	return &staticHint{name: name, f: f, nStatic: nStatic, nIn: nIn, nOut: nOut}
+}
+
+type staticHint struct {
+	name               string
+	f                  func(curveID ecc.ID, staticParams [][]byte, inputs []*big.Int, outputs []*big.Int) error
+	nStatic, nIn, nOut int
+}
+
+func (h *staticHint) UUID() ID {
+	return uuid(fmt.Sprintf("%s(%d;%d,%d)", h.name, h.nStatic, h.nIn, h.nOut))
+}
+func (h *staticHint) NbStatic() int  { return h.nStatic }
+func (h *staticHint) NbInputs() int  { return h.nIn }
+func (h *staticHint) NbOutputs() int { return h.nOut }
+func (h *staticHint) String() string { return h.name }
+func (h *staticHint) Call(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	return h.f(curveID, nil, inputs, outputs)
+}
+func (h *staticHint) CallWithStatic(curveID ecc.ID, staticParams [][]byte, inputs []*big.Int, outputs []*big.Int) error {
+	return h.f(curveID, staticParams, inputs, outputs)
+}
+
+// CheckStatic returns an error if f can't be called with nbStatic static parameters
+func CheckStatic(f AnnotatedFunction, nbStatic int) error {
+	fs, ok := f.(FunctionWithStatic)
+	if !ok {
+		if nbStatic != 0 {
+			return fmt.Errorf("hint %s doesn't accept static parameters, got %d", f, nbStatic)
+		}
+		return nil
+	}
+	if n := fs.NbStatic(); n >= 0 && n != nbStatic {
+		return fmt.Errorf("hint %s expects %d static parameters, got %d", f, n, nbStatic)
+	}
+	return nil
+}
+
+// Invoke computes the outputs of f, with CallWithStatic if f is a FunctionWithStatic, with Call otherwise
+// (staticParams must then be empty, see CheckStatic)
+func Invoke(f AnnotatedFunction, curveID ecc.ID, staticParams [][]byte, inputs []*big.Int, outputs []*big.Int) error {
+	if fs, ok := f.(FunctionWithStatic); ok {
+		return fs.CallWithStatic(curveID, staticParams, inputs, outputs)
+	}
+	if len(staticParams) != 0 {
+		return CheckStatic(f, len(staticParams))
+	}
+	return f.Call(curveID, inputs, outputs)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// manyPublicInputsCircuit has one more public input than a Solidity verifier accepts by default
+type manyPublicInputsCircuit struct {
+	X   [backend.DefaultSolidityMaxPublicInputs]frontend.Variable `gnark:",public"`
+	Sum frontend.Variable                                         `gnark:",public"`
+}
+
+func (circuit *manyPublicInputsCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	sum := api.Constant(0)
+	for i := 0; i < len(circuit.X); i++ {
+		sum = api.Add(sum, circuit.X[i])
+	}
+	api.AssertIsEqual(sum, circuit.Sum)
+	return nil
+}
+
+func TestMaxPublicInputs(t *testing.T) {
+	assert := require.New(t)
+	const nbPublicInputs = backend.DefaultSolidityMaxPublicInputs + 1
+
+	_, err := backend.NewSetupOption(backend.WithMaxPublicInputs(0))
+	assert.Error(err)
+	assert.False(errors.Is(err, backend.ErrTooManyPublicInputs))
+
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &manyPublicInputsCircuit{})
+	assert.NoError(err)
+
+	stats, err := frontend.EstimateSparseR1CS(r1cs)
+	assert.NoError(err)
+	assert.Equal(nbPublicInputs, stats.NbPublicInputs)
+
+	// groth16
+	_, _, err = groth16.Setup(r1cs, backend.WithMaxPublicInputs(nbPublicInputs-1))
+	assert.True(errors.Is(err, backend.ErrTooManyPublicInputs), "%v", err)
+	_, _, err = groth16.Setup(r1cs, backend.WithMaxPublicInputs(0))
+	assert.Error(err)
+	_, vk, err := groth16.Setup(r1cs, backend.WithMaxPublicInputs(nbPublicInputs))
+	assert.NoError(err)
+
+	// the Solidity verifier is limited by default
+	var buf bytes.Buffer
+	err = vk.ExportSolidity(&buf)
+	assert.True(errors.Is(err, backend.ErrTooManyPublicInputs), "%v", err)
+	assert.Contains(err.Error(), "compressing the public inputs")
+	assert.NoError(vk.ExportSolidity(&buf, backend.WithMaxPublicInputs(nbPublicInputs)))
+	assert.NotZero(buf.Len())
+
+	// plonk
+	scs, err := frontend.Compile(ecc.BN254, backend.PLONK, &manyPublicInputsCircuit{})
+	assert.NoError(err)
+	srs, err := test.NewKZGSRS(scs)
+	assert.NoError(err)
+	_, _, err = plonk.Setup(scs, srs, backend.WithMaxPublicInputs(nbPublicInputs-1))
+	assert.True(errors.Is(err, backend.ErrTooManyPublicInputs), "%v", err)
+	_, _, err = plonk.Setup(scs, srs, backend.WithMaxPublicInputs(nbPublicInputs))
+	assert.NoError(err)
+}
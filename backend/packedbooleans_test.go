@@ -0,0 +1,213 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend_test
+
+import (
+	"math/rand"
+	"runtime"
+	"sort"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	"github.com/consensys/gnark/internal/backend/circuits"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// bitsCircuit decomposes each X in 64 bits
+type bitsCircuit struct {
+	X []frontend.Variable
+}
+
+func (circuit *bitsCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	for i := 0; i < len(circuit.X); i++ {
+		api.ToBinary(circuit.X[i], 64)
+	}
+	return nil
+}
+
+func newBitsCircuit(n int) *bitsCircuit {
+	return &bitsCircuit{X: make([]frontend.Variable, n)}
+}
+
+func newBitsWitness(n int) *bitsCircuit {
+	w := newBitsCircuit(n)
+	for i := 0; i < n; i++ {
+		w.X[i].Assign(rand.Uint64()) //#nosec G404 weak rng is fine here
+	}
+	return w
+}
+
+func TestPackedBooleans(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	keys := make([]string, 0, len(circuits.Circuits))
+	for k := range circuits.Circuits {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		tData := circuits.Circuits[k]
+		if tData.ExpectedCompileError != "" {
+			continue
+		}
+		t.Log(k)
+		opts := []func(opt *test.TestingOption) error{
+			test.WithBackends(backend.GROTH16),
+			test.WithProverOpts(backend.WithHints(tData.HintFunctions...), backend.WithPackedBooleans()),
+		}
+		if testing.Short() {
+			opts = append(opts, test.WithCurves(ecc.BN254))
+		}
+
+		for _, w := range tData.ValidWitnesses {
+			assert.ProverSucceeded(tData.Circuit, w, opts...)
+		}
+		for _, w := range tData.InvalidWitnesses {
+			assert.ProverFailed(tData.Circuit, w, opts...)
+		}
+	}
+}
+
+func TestPackedBooleansSolution(t *testing.T) {
+	assert := require.New(t)
+
+	const n = 10
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, newBitsCircuit(n))
+	assert.NoError(err)
+	r1cs := ccs.(*cs_bn254.R1CS)
+
+	var w witness_bn254.Witness
+	assert.NoError(w.FromFullAssignment(newBitsWitness(n)))
+
+	solve := func(opts ...func(opt *backend.ProverOption) error) ([]fr.Element, error) {
+		opt, err := backend.NewProverOption(opts...)
+		assert.NoError(err)
+		a := make([]fr.Element, r1cs.GetNbConstraints())
+		b := make([]fr.Element, r1cs.GetNbConstraints())
+		c := make([]fr.Element, r1cs.GetNbConstraints())
+		return r1cs.Solve(w, a, b, c, opt)
+	}
+
+	expected, err := solve()
+	assert.NoError(err)
+
+	// unpacked by Solve
+	values, err := solve(backend.WithPackedBooleans())
+	assert.NoError(err)
+	assert.Equal(expected, values)
+
+	// packed by SolvePacked
+	opt, err := backend.NewProverOption()
+	assert.NoError(err)
+	a := make([]fr.Element, r1cs.GetNbConstraints())
+	b := make([]fr.Element, r1cs.GetNbConstraints())
+	c := make([]fr.Element, r1cs.GetNbConstraints())
+	packed, err := r1cs.SolvePacked(w, a, b, c, opt)
+	assert.NoError(err)
+	assert.Equal(n*64, packed.Layout.NbBooleans(), "the bits of the decompositions should be packed")
+	assert.Equal(len(expected)-n*64, len(packed.Values))
+	for i := range expected {
+		assert.Equal(expected[i], packed.Get(i), "wire %d", i)
+	}
+
+	// a boolean input can't hold another value
+	ccs, err = frontend.Compile(ecc.BN254, backend.GROTH16, &booleanCircuit{})
+	assert.NoError(err)
+	r1cs = ccs.(*cs_bn254.R1CS)
+	w = witness_bn254.Witness{fr.NewElement(2)}
+	a = make([]fr.Element, r1cs.GetNbConstraints())
+	b = make([]fr.Element, r1cs.GetNbConstraints())
+	c = make([]fr.Element, r1cs.GetNbConstraints())
+	_, err = r1cs.SolvePacked(w, a, b, c, opt)
+	assert.ErrorIs(err, cs_bn254.ErrUnsatisfiedConstraint)
+}
+
+type booleanCircuit struct {
+	B frontend.Variable
+}
+
+func (circuit *booleanCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsBoolean(circuit.B)
+	return nil
+}
+
+// BenchmarkPackedBooleans measures the memory used by the solver on a circuit of 5M bit wires.
+// The metric solution-B is the heap retained by the solution, the allocations being dominated by the
+// hints calls.
+func BenchmarkPackedBooleans(b *testing.B) {
+	const nbBits = 5_000_000
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, newBitsCircuit(nbBits/64))
+	if err != nil {
+		b.Fatal(err)
+	}
+	r1cs := ccs.(*cs_bn254.R1CS)
+
+	var w witness_bn254.Witness
+	if err := w.FromFullAssignment(newBitsWitness(nbBits / 64)); err != nil {
+		b.Fatal(err)
+	}
+	opt, err := backend.NewProverOption()
+	if err != nil {
+		b.Fatal(err)
+	}
+	a := make([]fr.Element, r1cs.GetNbConstraints())
+	c := make([]fr.Element, r1cs.GetNbConstraints())
+	d := make([]fr.Element, r1cs.GetNbConstraints())
+
+	run := func(b *testing.B, solve func() (interface{}, error)) {
+		var before, after runtime.MemStats
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			b.StartTimer()
+
+			solution, err := solve()
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.StopTimer()
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+			runtime.KeepAlive(solution)
+			b.StartTimer()
+		}
+		b.ReportMetric(float64(after.HeapAlloc)-float64(before.HeapAlloc), "solution-B")
+	}
+
+	b.Run("full", func(b *testing.B) {
+		run(b, func() (interface{}, error) {
+			return r1cs.Solve(w, a, c, d, opt)
+		})
+	})
+
+	b.Run("packed", func(b *testing.B) {
+		run(b, func() (interface{}, error) {
+			return r1cs.SolvePacked(w, a, c, d, opt)
+		})
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plonk_test
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/curves"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the CBOR encodings")
+
+type cborCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *cborCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	x := api.Mul(circuit.X, circuit.X)
+	api.AssertIsEqual(api.Mul(x, x), circuit.Y)
+	return nil
+}
+
+func TestProofCBORGolden(t *testing.T) {
+	for _, curve := range curves.Implemented() {
+		t.Run(curve.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curve, backend.PLONK, &cborCircuit{})
+			assert.NoError(err)
+			srs, err := test.NewKZGSRS(ccs)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(ccs, srs)
+			assert.NoError(err)
+
+			var witness cborCircuit
+			witness.X.Assign(2)
+			witness.Y.Assign(16)
+			proof, err := plonk.Prove(ccs, pk, &witness)
+			assert.NoError(err)
+
+			// a fresh proof survives the round trip
+			data, err := proof.MarshalCBOR()
+			assert.NoError(err)
+			decoded := plonk.NewProof(curve)
+			assert.NoError(decoded.UnmarshalCBOR(data))
+			assert.NoError(plonk.Verify(decoded, vk, &witness))
+
+			// the encoding is pinned, proofs being randomized the golden file is a past proof
+			golden := filepath.Join("testdata", "proof_"+curve.String()+".cbor")
+			if *update {
+				assert.NoError(ioutil.WriteFile(golden, data, 0644))
+			}
+			expected, err := ioutil.ReadFile(golden)
+			assert.NoError(err)
+			decoded = plonk.NewProof(curve)
+			assert.NoError(decoded.UnmarshalCBOR(expected))
+			reencoded, err := decoded.MarshalCBOR()
+			assert.NoError(err)
+			assert.Equal(expected, reencoded, "the encoding changed, run the tests with -update if it is intended")
+
+			// the header must match
+			other := ecc.BN254
+			if curve == ecc.BN254 {
+				other = ecc.BLS12_381
+			}
+			err = plonk.NewProof(other).UnmarshalCBOR(expected)
+			assert.True(errors.Is(err, backend.ErrCBORHeaderMismatch), "%v", err)
+		})
+	}
+}
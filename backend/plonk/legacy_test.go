@@ -0,0 +1,100 @@
+package plonk_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// legacyCircuit is the circuit of the keys in testdata/plonk_*_bn254.bin, written
+// on BN254 by gnark before the keys carried lookups and metadata
+type legacyCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *legacyCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(c.Y, api.Add(api.Mul(c.X, c.X, c.X), c.X, 5))
+	return nil
+}
+
+type key interface {
+	io.ReaderFrom
+	io.WriterTo
+	GetMetadata() map[string]string
+}
+
+func TestReadLegacyKeys(t *testing.T) {
+	assert := require.New(t)
+	trailer := []byte("data following the key")
+
+	for _, tc := range []struct {
+		file string
+		key  func() key
+	}{
+		{"plonk_vk_bn254.bin", func() key { return plonk.NewVerifyingKey(ecc.BN254) }},
+		{"plonk_pk_bn254.bin", func() key { return plonk.NewProvingKey(ecc.BN254) }},
+	} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", tc.file))
+		assert.NoError(err)
+
+		k := tc.key()
+		n, err := k.ReadFrom(bytes.NewReader(data))
+		assert.NoError(err, tc.file)
+		assert.EqualValues(len(data), n, tc.file)
+		assert.Nil(k.GetMetadata(), tc.file)
+
+		// without lookups and metadata, the key is written back in the legacy format
+		var buf bytes.Buffer
+		_, err = k.WriteTo(&buf)
+		assert.NoError(err, tc.file)
+		assert.Equal(data, buf.Bytes(), tc.file)
+
+		// the data following the key is left to the next reader of the LimitReader
+		lr := gnarkio.NewLimitReader(bytes.NewReader(append(data, trailer...)))
+		n, err = tc.key().ReadFrom(lr)
+		assert.NoError(err, tc.file)
+		assert.EqualValues(len(data), n, tc.file)
+		rest, err := ioutil.ReadAll(lr)
+		assert.NoError(err, tc.file)
+		assert.Equal(trailer, rest, tc.file)
+	}
+}
+
+func TestVerifyingKeyMetadataSection(t *testing.T) {
+	assert := require.New(t)
+	trailer := []byte("data following the key")
+	metadata := map[string]string{"name": "legacy"}
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &legacyCircuit{}, frontend.WithMetadata(metadata))
+	assert.NoError(err)
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(err)
+	_, vk, err := plonk.Setup(ccs, srs)
+	assert.NoError(err)
+
+	var buf bytes.Buffer
+	written, err := vk.WriteTo(&buf)
+	assert.NoError(err)
+	buf.Write(trailer)
+
+	lr := gnarkio.NewLimitReader(&buf)
+	read := plonk.NewVerifyingKey(ecc.BN254)
+	n, err := read.ReadFrom(lr)
+	assert.NoError(err)
+	assert.Equal(written, n)
+	assert.Equal(metadata, read.GetMetadata())
+	rest, err := ioutil.ReadAll(lr)
+	assert.NoError(err)
+	assert.Equal(trailer, rest)
+}
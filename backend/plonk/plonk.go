@@ -20,36 +20,45 @@
 package plonk
 
 import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
 	"io"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/kzg"
 	"github.com/consensys/gnark/backend"
+	gnarkwitness "github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/compiled"
 
 	cs_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
 	cs_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
 	cs_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
 	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
 	cs_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+	cs_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
 
 	plonk_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/plonk"
 	plonk_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/plonk"
 	plonk_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/plonk"
 	plonk_bn254 "github.com/consensys/gnark/internal/backend/bn254/plonk"
 	plonk_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/plonk"
+	plonk_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/plonk"
 
 	witness_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/witness"
 	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
 	witness_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/witness"
 	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
 	witness_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/witness"
+	witness_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/witness"
 
 	kzg_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/kzg"
 	kzg_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
 	kzg_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr/kzg"
 	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
 	kzg_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr/kzg"
+	kzg_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr/kzg"
 )
 
 // Proof represents a Plonk proof generated by plonk.Prove
@@ -58,6 +67,14 @@ import (
 type Proof interface {
 	io.WriterTo
 	io.ReaderFrom
+
+	// MarshalCBOR returns the canonical CBOR encoding of the proof, a map of its named components
+	// (compressed points, big endian field elements) after a header with the type and the curve
+	MarshalCBOR() ([]byte, error)
+
+	// UnmarshalCBOR decodes a proof encoded with MarshalCBOR, it fails with backend.ErrCBORHeaderMismatch
+	// if data is not a proof of this type and curve
+	UnmarshalCBOR(data []byte) error
 }
 
 // ProvingKey represents a plonk ProvingKey
@@ -68,6 +85,9 @@ type ProvingKey interface {
 	io.ReaderFrom
 	InitKZG(srs kzg.SRS) error
 	VerifyingKey() interface{}
+
+	// GetMetadata returns the metadata of the constraint system the key was generated for
+	GetMetadata() map[string]string
 }
 
 // VerifyingKey represents a plonk VerifyingKey
@@ -78,10 +98,27 @@ type VerifyingKey interface {
 	io.ReaderFrom
 	InitKZG(srs kzg.SRS) error
 	NbPublicWitness() int // number of elements expected in the public witness
+
+	// GetMetadata returns the metadata of the constraint system the key was generated for
+	GetMetadata() map[string]string
 }
 
 // Setup prepares the public data associated to a circuit + public inputs.
-func Setup(ccs frontend.CompiledConstraintSystem, kzgSRS kzg.SRS) (ProvingKey, VerifyingKey, error) {
+//
+// With backend.WithMaxPublicInputs, it fails with backend.ErrTooManyPublicInputs if the circuit has more
+// public inputs than allowed.
+func Setup(ccs frontend.CompiledConstraintSystem, kzgSRS kzg.SRS, opts ...func(opt *backend.SetupOption) error) (ProvingKey, VerifyingKey, error) {
+
+	// apply options
+	opt, err := backend.NewSetupOption(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, _, nbPublic := ccs.GetNbVariables()
+	if err := backend.CheckPublicInputs(nbPublic, opt.MaxPublicInputs); err != nil {
+		return nil, nil, err
+	}
 
 	switch tccs := ccs.(type) {
 	case *cs_bn254.SparseR1CS:
@@ -92,6 +129,8 @@ func Setup(ccs frontend.CompiledConstraintSystem, kzgSRS kzg.SRS) (ProvingKey, V
 		return plonk_bls12377.Setup(tccs, kzgSRS.(*kzg_bls12377.SRS))
 	case *cs_bw6761.SparseR1CS:
 		return plonk_bw6761.Setup(tccs, kzgSRS.(*kzg_bw6761.SRS))
+	case *cs_bw6633.SparseR1CS:
+		return plonk_bw6633.Setup(tccs, kzgSRS.(*kzg_bw6633.SRS))
 	case *cs_bls24315.SparseR1CS:
 		return plonk_bls24315.Setup(tccs, kzgSRS.(*kzg_bls24315.SRS))
 	default:
@@ -113,45 +152,73 @@ func Prove(ccs frontend.CompiledConstraintSystem, pk ProvingKey, fullWitness fro
 		return nil, err
 	}
 
+	if !compiled.EqualMetadata(ccs.GetMetadata(), pk.GetMetadata()) {
+		return nil, backend.ErrMetadataMismatch
+	}
+	if err := gnarkwitness.CheckFrVector(fullWitness, ccs); err != nil {
+		return nil, err
+	}
+
+	var proof Proof
 	switch tccs := ccs.(type) {
 	case *cs_bn254.SparseR1CS:
 		w := witness_bn254.Witness{}
 		if err := w.FromFullAssignment(fullWitness); err != nil {
 			return nil, err
 		}
-		return plonk_bn254.Prove(tccs, pk.(*plonk_bn254.ProvingKey), w, opt)
+		proof, err = plonk_bn254.Prove(tccs, pk.(*plonk_bn254.ProvingKey), w, opt)
 
 	case *cs_bls12381.SparseR1CS:
 		w := witness_bls12381.Witness{}
 		if err := w.FromFullAssignment(fullWitness); err != nil {
 			return nil, err
 		}
-		return plonk_bls12381.Prove(tccs, pk.(*plonk_bls12381.ProvingKey), w, opt)
+		proof, err = plonk_bls12381.Prove(tccs, pk.(*plonk_bls12381.ProvingKey), w, opt)
 
 	case *cs_bls12377.SparseR1CS:
 		w := witness_bls12377.Witness{}
 		if err := w.FromFullAssignment(fullWitness); err != nil {
 			return nil, err
 		}
-		return plonk_bls12377.Prove(tccs, pk.(*plonk_bls12377.ProvingKey), w, opt)
+		proof, err = plonk_bls12377.Prove(tccs, pk.(*plonk_bls12377.ProvingKey), w, opt)
 
 	case *cs_bw6761.SparseR1CS:
 		w := witness_bw6761.Witness{}
 		if err := w.FromFullAssignment(fullWitness); err != nil {
 			return nil, err
 		}
-		return plonk_bw6761.Prove(tccs, pk.(*plonk_bw6761.ProvingKey), w, opt)
+		proof, err = plonk_bw6761.Prove(tccs, pk.(*plonk_bw6761.ProvingKey), w, opt)
+	case *cs_bw6633.SparseR1CS:
+		w := witness_bw6633.Witness{}
+		if err := w.FromFullAssignment(fullWitness); err != nil {
+			return nil, err
+		}
+		proof, err = plonk_bw6633.Prove(tccs, pk.(*plonk_bw6633.ProvingKey), w, opt)
 
 	case *cs_bls24315.SparseR1CS:
 		w := witness_bls24315.Witness{}
 		if err := w.FromFullAssignment(fullWitness); err != nil {
 			return nil, err
 		}
-		return plonk_bls24315.Prove(tccs, pk.(*plonk_bls24315.ProvingKey), w, opt)
+		proof, err = plonk_bls24315.Prove(tccs, pk.(*plonk_bls24315.ProvingKey), w, opt)
 
 	default:
 		panic("unrecognized SparseR1CS curve type")
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opt.SelfCheck {
+		verify := func(vk VerifyingKey) error {
+			return Verify(proof, vk, fullWitness)
+		}
+		if err := selfCheck(pk, opt, verify); err != nil {
+			return nil, err
+		}
+	}
+
+	return proof, nil
 }
 
 // Verify verifies a PLONK proof, from the proof, preprocessed public data, and public witness.
@@ -186,6 +253,12 @@ func Verify(proof Proof, vk VerifyingKey, publicWitness frontend.Circuit) error
 			return err
 		}
 		return plonk_bw6761.Verify(_proof, vk.(*plonk_bw6761.VerifyingKey), w)
+	case *plonk_bw6633.Proof:
+		w := witness_bw6633.Witness{}
+		if err := w.FromPublicAssignment(publicWitness); err != nil {
+			return err
+		}
+		return plonk_bw6633.Verify(_proof, vk.(*plonk_bw6633.VerifyingKey), w)
 
 	case *plonk_bls24315.Proof:
 		w := witness_bls24315.Witness{}
@@ -212,6 +285,8 @@ func NewCS(curveID ecc.ID) frontend.CompiledConstraintSystem {
 		r1cs = &cs_bls12381.SparseR1CS{}
 	case ecc.BW6_761:
 		r1cs = &cs_bw6761.SparseR1CS{}
+	case ecc.BW6_633:
+		r1cs = &cs_bw6633.SparseR1CS{}
 	case ecc.BLS24_315:
 		r1cs = &cs_bls24315.SparseR1CS{}
 	default:
@@ -233,6 +308,8 @@ func NewProvingKey(curveID ecc.ID) ProvingKey {
 		pk = &plonk_bls12381.ProvingKey{}
 	case ecc.BW6_761:
 		pk = &plonk_bw6761.ProvingKey{}
+	case ecc.BW6_633:
+		pk = &plonk_bw6633.ProvingKey{}
 	case ecc.BLS24_315:
 		pk = &plonk_bls24315.ProvingKey{}
 	default:
@@ -255,6 +332,8 @@ func NewProof(curveID ecc.ID) Proof {
 		proof = &plonk_bls12381.Proof{}
 	case ecc.BW6_761:
 		proof = &plonk_bw6761.Proof{}
+	case ecc.BW6_633:
+		proof = &plonk_bw6633.Proof{}
 	case ecc.BLS24_315:
 		proof = &plonk_bls24315.Proof{}
 	default:
@@ -277,6 +356,8 @@ func NewVerifyingKey(curveID ecc.ID) VerifyingKey {
 		vk = &plonk_bls12381.VerifyingKey{}
 	case ecc.BW6_761:
 		vk = &plonk_bw6761.VerifyingKey{}
+	case ecc.BW6_633:
+		vk = &plonk_bw6633.VerifyingKey{}
 	case ecc.BLS24_315:
 		vk = &plonk_bls24315.VerifyingKey{}
 	default:
@@ -295,73 +376,125 @@ func ReadAndProve(ccs frontend.CompiledConstraintSystem, pk ProvingKey, witness
 		return nil, err
 	}
 
+	if !compiled.EqualMetadata(ccs.GetMetadata(), pk.GetMetadata()) {
+		return nil, backend.ErrMetadataMismatch
+	}
+
 	_, nbSecret, nbPublic := ccs.GetNbVariables()
 	expectedSize := (nbSecret + nbPublic)
+	nbOptional := compiled.NbTrailingOptional(ccs.GetOptionalSecrets(), nbSecret)
 
+	// the self-check needs the public part of the witness
+	var fullWitness bytes.Buffer
+	if opt.SelfCheck {
+		witness = io.TeeReader(witness, &fullWitness)
+	}
+
+	var proof Proof
 	switch tccs := ccs.(type) {
 	case *cs_bn254.SparseR1CS:
 		_pk := pk.(*plonk_bn254.ProvingKey)
 		w := witness_bn254.Witness{}
-		if _, err := w.LimitReadFrom(witness, expectedSize); err != nil {
+		if _, err := w.LimitReadFromOptional(witness, expectedSize, nbOptional); err != nil {
 			return nil, err
 		}
-		proof, err := plonk_bn254.Prove(tccs, _pk, w, opt)
-		if err != nil {
-			return proof, err
-		}
-		return proof, nil
+		proof, err = plonk_bn254.Prove(tccs, _pk, w, opt)
 
 	case *cs_bls12381.SparseR1CS:
 		_pk := pk.(*plonk_bls12381.ProvingKey)
 		w := witness_bls12381.Witness{}
-		if _, err := w.LimitReadFrom(witness, expectedSize); err != nil {
+		if _, err := w.LimitReadFromOptional(witness, expectedSize, nbOptional); err != nil {
 			return nil, err
 		}
-		proof, err := plonk_bls12381.Prove(tccs, _pk, w, opt)
-		if err != nil {
-			return proof, err
-		}
-		return proof, nil
+		proof, err = plonk_bls12381.Prove(tccs, _pk, w, opt)
 
 	case *cs_bls12377.SparseR1CS:
 		_pk := pk.(*plonk_bls12377.ProvingKey)
 		w := witness_bls12377.Witness{}
-		if _, err := w.LimitReadFrom(witness, expectedSize); err != nil {
+		if _, err := w.LimitReadFromOptional(witness, expectedSize, nbOptional); err != nil {
 			return nil, err
 		}
-		proof, err := plonk_bls12377.Prove(tccs, _pk, w, opt)
-		if err != nil {
-			return proof, err
-		}
-		return proof, nil
+		proof, err = plonk_bls12377.Prove(tccs, _pk, w, opt)
 
 	case *cs_bw6761.SparseR1CS:
 		_pk := pk.(*plonk_bw6761.ProvingKey)
 		w := witness_bw6761.Witness{}
-		if _, err := w.LimitReadFrom(witness, expectedSize); err != nil {
+		if _, err := w.LimitReadFromOptional(witness, expectedSize, nbOptional); err != nil {
 			return nil, err
 		}
-		proof, err := plonk_bw6761.Prove(tccs, _pk, w, opt)
-		if err != nil {
-			return proof, err
+		proof, err = plonk_bw6761.Prove(tccs, _pk, w, opt)
+	case *cs_bw6633.SparseR1CS:
+		_pk := pk.(*plonk_bw6633.ProvingKey)
+		w := witness_bw6633.Witness{}
+		if _, err := w.LimitReadFromOptional(witness, expectedSize, nbOptional); err != nil {
+			return nil, err
 		}
-		return proof, nil
+		proof, err = plonk_bw6633.Prove(tccs, _pk, w, opt)
 
 	case *cs_bls24315.SparseR1CS:
 		_pk := pk.(*plonk_bls24315.ProvingKey)
 		w := witness_bls24315.Witness{}
-		if _, err := w.LimitReadFrom(witness, expectedSize); err != nil {
+		if _, err := w.LimitReadFromOptional(witness, expectedSize, nbOptional); err != nil {
 			return nil, err
 		}
-		proof, err := plonk_bls24315.Prove(tccs, _pk, w, opt)
-		if err != nil {
-			return proof, err
-		}
-		return proof, nil
+		proof, err = plonk_bls24315.Prove(tccs, _pk, w, opt)
 
 	default:
 		panic("unrecognized R1CS curve type")
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opt.SelfCheck {
+		verify := func(vk VerifyingKey) error {
+			return ReadAndVerify(proof, vk, bytes.NewReader(publicWitness(fullWitness.Bytes(), nbPublic)))
+		}
+		if err := selfCheck(pk, opt, verify); err != nil {
+			return nil, err
+		}
+	}
+
+	return proof, nil
+}
+
+// WithVerifyingKey is a Prover option that sets the verifying key used to check the proof, see backend.WithSelfCheck.
+// It defaults to the verifying key embedded in the proving key
+func WithVerifyingKey(vk VerifyingKey) func(opt *backend.ProverOption) error {
+	return func(opt *backend.ProverOption) error {
+		opt.VerifyingKey = vk
+		return nil
+	}
+}
+
+// selfCheck runs verify against the verifying key set by WithVerifyingKey, or the one of pk
+func selfCheck(pk ProvingKey, opt backend.ProverOption, verify func(vk VerifyingKey) error) error {
+	v := opt.VerifyingKey
+	if v == nil {
+		v = pk.VerifyingKey()
+	}
+	vk, ok := v.(VerifyingKey)
+	if !ok {
+		return fmt.Errorf("self-check: %T is not a plonk verifying key", v)
+	}
+	if err := verify(vk); err != nil {
+		return fmt.Errorf("%w: %v", backend.ErrSelfCheckFailed, err)
+	}
+	return nil
+}
+
+// publicWitness returns the public part, of size nbPublic, of a full witness encoded
+// with the binary serialization protocol of the gnark/backend/witness package
+func publicWitness(fullWitness []byte, nbPublic int) []byte {
+	nbElements := int(binary.BigEndian.Uint32(fullWitness[:4]))
+	if nbElements == 0 {
+		return fullWitness
+	}
+	elementSize := (len(fullWitness) - 4) / nbElements
+
+	r := make([]byte, 4, 4+nbPublic*elementSize)
+	binary.BigEndian.PutUint32(r, uint32(nbPublic))
+	return append(r, fullWitness[4:4+nbPublic*elementSize]...)
 }
 
 // ReadAndVerify verifies a PLONK proof from a circuit, associated proving key, and the full witness
@@ -401,6 +534,13 @@ func ReadAndVerify(proof Proof, vk VerifyingKey, witness io.Reader) error {
 			return err
 		}
 		return plonk_bw6761.Verify(_proof, _vk, w)
+	case *plonk_bw6633.Proof:
+		_vk := vk.(*plonk_bw6633.VerifyingKey)
+		w := witness_bw6633.Witness{}
+		if _, err := w.LimitReadFrom(witness, expectedSize); err != nil {
+			return err
+		}
+		return plonk_bw6633.Verify(_proof, _vk, w)
 
 	case *plonk_bls24315.Proof:
 		_vk := vk.(*plonk_bls24315.VerifyingKey)
@@ -423,6 +563,9 @@ func IsSolved(ccs frontend.CompiledConstraintSystem, witness frontend.Circuit, o
 	if err != nil {
 		return err
 	}
+	if err := gnarkwitness.CheckFrVector(witness, ccs); err != nil {
+		return err
+	}
 
 	switch tccs := ccs.(type) {
 	case *cs_bn254.SparseR1CS:
@@ -449,6 +592,12 @@ func IsSolved(ccs frontend.CompiledConstraintSystem, witness frontend.Circuit, o
 			return err
 		}
 		return tccs.IsSolved(w, opt)
+	case *cs_bw6633.SparseR1CS:
+		w := witness_bw6633.Witness{}
+		if err := w.FromFullAssignment(witness); err != nil {
+			return err
+		}
+		return tccs.IsSolved(w, opt)
 	case *cs_bls24315.SparseR1CS:
 		w := witness_bls24315.Witness{}
 		if err := w.FromFullAssignment(witness); err != nil {
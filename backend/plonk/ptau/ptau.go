@@ -0,0 +1,302 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ptau reads the KZG SRS of PLONK (see plonk.Setup) from a powers of tau transcript, in the .ptau
+// format written by snarkjs, such as the files of the perpetual powers of tau ceremony, on BN254 and BLS12-381.
+//
+// A .ptau file is
+//
+//	"ptau" | version (uint32) | nbSections (uint32) | sections
+//
+// each section being type (uint32) | size (uint64) | data, the integers in little endian. The header
+// (section 1) holds the byte size n8 of a base field element, the base field modulus (n8 bytes), and the
+// power p of the transcript; the section 2 holds the 2**(p+1) - 1 points [τ**i]G1, the section 3 the 2**p
+// points [τ**i]G2. The coordinates are in Montgomery form, in little endian; the other sections are skipped.
+//
+// The file is read as a stream, up to the powers the SRS needs: the memory is bounded by the size of the SRS,
+// not of the file. The points are checked to be on the curve, and the consistency of the powers is checked with
+// pairings on a random subset of them, see WithChecks.
+package ptau
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/frontend"
+)
+
+// ErrInvalidFormat is returned, wrapped, when the transcript isn't a well-formed .ptau file of a supported curve
+var ErrInvalidFormat = errors.New("invalid ptau file")
+
+// ErrInconsistentPowers is returned, wrapped, when the points of the transcript are not the successive powers of
+// a same τ, on the standard generators
+var ErrInconsistentPowers = errors.New("inconsistent powers of tau")
+
+// DefaultNbChecks is the number of powers whose consistency is checked, unless set with WithChecks
+const DefaultNbChecks = 64
+
+const (
+	sectionHeader = 1
+	sectionTauG1  = 2
+	sectionTauG2  = 3
+)
+
+// Option holds the options of ReadSRS
+type Option struct {
+	nbChecks int
+}
+
+// WithChecks sets the number of powers [τ**i]G1 whose consistency with [τ**(i+1)]G1 is checked (the power i == 0,
+// which binds [τ]G2 to the powers of G1, is always checked). The checks of n powers cost two multi-exponentiations of
+// size n and a pairing check. n < 0, or n >= the size of the SRS, checks all the powers.
+func WithChecks(n int) func(opt *Option) error {
+	return func(opt *Option) error {
+		opt.nbChecks = n
+		return nil
+	}
+}
+
+// SizeFor returns the size of the SRS of the PLONK setup of ccs (see plonk.Setup)
+func SizeFor(ccs frontend.CompiledConstraintSystem) uint64 {
+	_, _, nbPublic := ccs.GetNbVariables()
+	return ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints()+nbPublic)) + 3
+}
+
+// ReadSRSFor reads the SRS of the PLONK setup of ccs from the transcript r, see ReadSRS. The transcript
+// must be on the curve of ccs
+func ReadSRSFor(ccs frontend.CompiledConstraintSystem, r io.Reader, opts ...func(opt *Option) error) (kzg.SRS, error) {
+	srs, curve, err := ReadSRS(r, SizeFor(ccs), opts...)
+	if err != nil {
+		return nil, err
+	}
+	if curve != ccs.CurveID() {
+		return nil, fmt.Errorf("%w: transcript on %s, constraint system on %s", ErrInvalidFormat, curve, ccs.CurveID())
+	}
+	return srs, nil
+}
+
+// ReadSRS reads the first size powers [τ**i]G1 and [τ]G2 from the transcript r, and returns them as a KZG SRS
+// with the curve of the transcript. It stops reading r once it has read them (r is read through a buffer of 1MiB,
+// it may be read ahead up to the buffer size).
+func ReadSRS(r io.Reader, size uint64, opts ...func(opt *Option) error) (kzg.SRS, ecc.ID, error) {
+	opt := Option{nbChecks: DefaultNbChecks}
+	for _, option := range opts {
+		if err := option(&opt); err != nil {
+			return nil, ecc.UNKNOWN, err
+		}
+	}
+	if size < 2 {
+		return nil, ecc.UNKNOWN, errors.New("the size of the SRS must be at least 2")
+	}
+
+	br := bufio.NewReaderSize(r, 1<<20)
+	var magic [4]byte
+	if _, err := io.ReadFull(br, magic[:]); err != nil {
+		return nil, ecc.UNKNOWN, err
+	}
+	if string(magic[:]) != "ptau" {
+		return nil, ecc.UNKNOWN, fmt.Errorf("%w: magic %q", ErrInvalidFormat, magic[:])
+	}
+	var version, nbSections uint32
+	if err := readLE(br, &version, &nbSections); err != nil {
+		return nil, ecc.UNKNOWN, err
+	}
+	if version != 1 {
+		return nil, ecc.UNKNOWN, fmt.Errorf("%w: version %d", ErrInvalidFormat, version)
+	}
+
+	var (
+		s          curveSRS
+		curve      ecc.ID
+		readG1     bool
+		readG2     bool
+		nbG1, nbG2 uint64
+	)
+	for i := uint32(0); i < nbSections && !(readG1 && readG2); i++ {
+		var sectionType uint32
+		var sectionSize uint64
+		if err := readLE(br, &sectionType, &sectionSize); err != nil {
+			return nil, ecc.UNKNOWN, err
+		}
+		section := io.LimitReader(br, int64(sectionSize))
+
+		switch {
+		case sectionType == sectionHeader && s == nil:
+			var err error
+			if s, curve, nbG1, nbG2, err = readHeader(section); err != nil {
+				return nil, ecc.UNKNOWN, err
+			}
+			if nbG1 < size {
+				return nil, ecc.UNKNOWN, fmt.Errorf("%w: %d powers of tau in G1, %d needed", ErrInvalidFormat, nbG1, size)
+			}
+		case (sectionType == sectionTauG1 || sectionType == sectionTauG2) && s == nil:
+			return nil, ecc.UNKNOWN, fmt.Errorf("%w: section %d before the header", ErrInvalidFormat, sectionType)
+		case sectionType == sectionTauG1:
+			if sectionSize != nbG1*uint64(s.g1Size()) {
+				return nil, ecc.UNKNOWN, fmt.Errorf("%w: %d bytes of powers of tau in G1", ErrInvalidFormat, sectionSize)
+			}
+			if err := s.readG1(section, size); err != nil {
+				return nil, ecc.UNKNOWN, err
+			}
+			readG1 = true
+		case sectionType == sectionTauG2:
+			if sectionSize != nbG2*uint64(s.g2Size()) {
+				return nil, ecc.UNKNOWN, fmt.Errorf("%w: %d bytes of powers of tau in G2", ErrInvalidFormat, sectionSize)
+			}
+			if err := s.readG2(section); err != nil {
+				return nil, ecc.UNKNOWN, err
+			}
+			readG2 = true
+		}
+
+		// skip the rest of the section
+		if _, err := io.Copy(ioutil.Discard, section); err != nil {
+			return nil, ecc.UNKNOWN, err
+		}
+	}
+	if !readG1 || !readG2 {
+		return nil, ecc.UNKNOWN, fmt.Errorf("%w: missing powers of tau", ErrInvalidFormat)
+	}
+
+	indexes, err := sample(size-1, opt.nbChecks)
+	if err != nil {
+		return nil, ecc.UNKNOWN, err
+	}
+	if err := s.check(indexes); err != nil {
+		return nil, ecc.UNKNOWN, err
+	}
+	return s.srs(), curve, nil
+}
+
+// curveSRS reads and checks the points of a transcript on a curve
+type curveSRS interface {
+	// g1Size and g2Size return the size of a point, in bytes
+	g1Size() int
+	g2Size() int
+
+	// readG1 reads the first n powers in G1, and checks that they are on the curve
+	readG1(r io.Reader, n uint64) error
+
+	// readG2 reads G2 and [τ]G2, and checks that they are in the subgroup
+	readG2(r io.Reader) error
+
+	// check returns ErrInconsistentPowers if, for an index i, [τ**(i+1)]G1 isn't [τ]([τ**i]G1), or if
+	// the generators are not the standard ones. The points [τ**i]G1 checked are in the subgroup
+	check(indexes []uint64) error
+
+	srs() kzg.SRS
+}
+
+// readHeader reads the header section and returns the reader of the points of its curve, and the number of
+// points in G1 and G2
+func readHeader(r io.Reader) (s curveSRS, curve ecc.ID, nbG1, nbG2 uint64, err error) {
+	var n8 uint32
+	if err = readLE(r, &n8); err != nil {
+		return
+	}
+	if n8 == 0 || n8 > 64 {
+		err = fmt.Errorf("%w: %d bytes field elements", ErrInvalidFormat, n8)
+		return
+	}
+	buf := make([]byte, n8)
+	if _, err = io.ReadFull(r, buf); err != nil {
+		return
+	}
+	q := new(big.Int).SetBytes(reverse(buf))
+	var power uint32
+	if err = readLE(r, &power); err != nil {
+		return
+	}
+	if power > 30 {
+		err = fmt.Errorf("%w: power %d", ErrInvalidFormat, power)
+		return
+	}
+
+	switch {
+	case q.Cmp(ecc.BN254.Info().Fp.Modulus()) == 0 && n8 == 32:
+		s, curve = new(srsBN254), ecc.BN254
+	case q.Cmp(ecc.BLS12_381.Info().Fp.Modulus()) == 0 && n8 == 48:
+		s, curve = new(srsBLS12381), ecc.BLS12_381
+	default:
+		err = fmt.Errorf("%w: unsupported base field %s", ErrInvalidFormat, q)
+		return
+	}
+	nbG2 = uint64(1) << power
+	nbG1 = 2*nbG2 - 1
+	return
+}
+
+// sample returns the indexes of the n powers to check: all of them if nbChecks < 0 or nbChecks >= n, else
+// 0 and nbChecks - 1 distinct random indexes
+func sample(n uint64, nbChecks int) ([]uint64, error) {
+	if nbChecks < 0 || uint64(nbChecks) >= n {
+		res := make([]uint64, n)
+		for i := range res {
+			res[i] = uint64(i)
+		}
+		return res, nil
+	}
+	res := []uint64{0}
+	seen := map[uint64]bool{0: true}
+	max := new(big.Int).SetUint64(n)
+	for len(res) < nbChecks {
+		i, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[i.Uint64()] {
+			seen[i.Uint64()] = true
+			res = append(res, i.Uint64())
+		}
+	}
+	return res, nil
+}
+
+// setMont sets the limbs of a base field element from its little endian Montgomery form, and returns
+// ErrInvalidFormat if it isn't reduced modulo q
+func setMont(limbs []uint64, b []byte, q *big.Int) error {
+	if new(big.Int).SetBytes(reverse(b)).Cmp(q) >= 0 {
+		return fmt.Errorf("%w: coordinate not reduced", ErrInvalidFormat)
+	}
+	for i := range limbs {
+		limbs[i] = binary.LittleEndian.Uint64(b[8*i:])
+	}
+	return nil
+}
+
+// reverse returns a reversed copy of b
+func reverse(b []byte) []byte {
+	res := make([]byte, len(b))
+	for i := range b {
+		res[len(b)-1-i] = b[i]
+	}
+	return res
+}
+
+func readLE(r io.Reader, data ...interface{}) error {
+	for _, d := range data {
+		if err := binary.Read(r, binary.LittleEndian, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
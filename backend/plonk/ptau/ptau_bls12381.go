@@ -0,0 +1,133 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptau
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	kzg_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+)
+
+type srsBLS12381 struct {
+	kzg_bls12381.SRS
+}
+
+func (s *srsBLS12381) g1Size() int { return 2 * fp.Bytes }
+func (s *srsBLS12381) g2Size() int { return 4 * fp.Bytes }
+
+func (s *srsBLS12381) readG1(r io.Reader, n uint64) error {
+	s.G1 = make([]bls12381.G1Affine, n)
+	buf := make([]byte, s.g1Size())
+	for i := range s.G1 {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return err
+		}
+		if err := s.setG1(&s.G1[i], buf); err != nil {
+			return fmt.Errorf("[τ**%d]G1: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (s *srsBLS12381) readG2(r io.Reader) error {
+	buf := make([]byte, s.g2Size())
+	for i := range s.G2 {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return err
+		}
+		if err := s.setG2(&s.G2[i], buf); err != nil {
+			return fmt.Errorf("[τ**%d]G2: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (s *srsBLS12381) setG1(p *bls12381.G1Affine, buf []byte) error {
+	q := fp.Modulus()
+	if err := setMont(p.X[:], buf[:fp.Bytes], q); err != nil {
+		return err
+	}
+	if err := setMont(p.Y[:], buf[fp.Bytes:], q); err != nil {
+		return err
+	}
+	if p.IsInfinity() || !p.IsOnCurve() {
+		return fmt.Errorf("%w: point not on the curve", ErrInvalidFormat)
+	}
+	return nil
+}
+
+func (s *srsBLS12381) setG2(p *bls12381.G2Affine, buf []byte) error {
+	q := fp.Modulus()
+	for i, c := range []*fp.Element{&p.X.A0, &p.X.A1, &p.Y.A0, &p.Y.A1} {
+		if err := setMont(c[:], buf[i*fp.Bytes:(i+1)*fp.Bytes], q); err != nil {
+			return err
+		}
+	}
+	if p.IsInfinity() || !p.IsOnCurve() || !p.IsInSubGroup() {
+		return fmt.Errorf("%w: point not in the subgroup", ErrInvalidFormat)
+	}
+	return nil
+}
+
+func (s *srsBLS12381) check(indexes []uint64) error {
+	_, _, g1, g2 := bls12381.Generators()
+	if !s.G1[0].Equal(&g1) || !s.G2[0].Equal(&g2) {
+		return fmt.Errorf("%w: not the standard generators", ErrInconsistentPowers)
+	}
+
+	// Σ r_i [τ**(i+1)]G1 == [τ](Σ r_i [τ**i]G1) for random r_i
+	lhs, rhs := make([]bls12381.G1Affine, len(indexes)), make([]bls12381.G1Affine, len(indexes))
+	scalars := make([]fr.Element, len(indexes))
+	for j, i := range indexes {
+		if !s.G1[i].IsInSubGroup() || !s.G1[i+1].IsInSubGroup() {
+			return fmt.Errorf("%w: [τ**%d]G1 not in the subgroup", ErrInvalidFormat, i)
+		}
+		lhs[j], rhs[j] = s.G1[i+1], s.G1[i]
+		if _, err := scalars[j].SetRandom(); err != nil {
+			return err
+		}
+	}
+	config := ecc.MultiExpConfig{ScalarsMont: true}
+	var lhsJac, rhsJac bls12381.G1Jac
+	if _, err := lhsJac.MultiExp(lhs, scalars, config); err != nil {
+		return err
+	}
+	if _, err := rhsJac.MultiExp(rhs, scalars, config); err != nil {
+		return err
+	}
+	var a, b bls12381.G1Affine
+	a.FromJacobian(&lhsJac)
+	b.FromJacobian(&rhsJac)
+	b.Neg(&b)
+
+	ok, err := bls12381.PairingCheck([]bls12381.G1Affine{a, b}, []bls12381.G2Affine{s.G2[0], s.G2[1]})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInconsistentPowers
+	}
+	return nil
+}
+
+func (s *srsBLS12381) srs() kzg.SRS {
+	return &s.SRS
+}
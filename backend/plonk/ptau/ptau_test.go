@@ -0,0 +1,271 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptau
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls12381fp "github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	kzg_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bn254fp "github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+const testPower = 4 // 31 powers in G1
+
+var testTau = big.NewInt(0xcafe)
+
+// writePtau writes a transcript of power testPower on curve, with the section 4 (the powers of α) filled
+// with zeros, for the reader to skip it. corrupt, if not nil, is called on the serialized powers in G1
+func writePtau(t *testing.T, curve ecc.ID, corrupt func(g1 [][]byte)) []byte {
+	nbG2 := 1 << testPower
+	nbG1 := 2*nbG2 - 1
+	var q *big.Int
+	var n8 int
+	switch curve {
+	case ecc.BN254:
+		q, n8 = bn254fp.Modulus(), bn254fp.Bytes
+	case ecc.BLS12_381:
+		q, n8 = bls12381fp.Modulus(), bls12381fp.Bytes
+	}
+
+	r := curve.Info().Fr.Modulus()
+	g1 := make([][]byte, nbG1)
+	g2 := make([][]byte, nbG2)
+	power := big.NewInt(1)
+	for i := 0; i < nbG1; i++ {
+		g1[i] = g1Bytes(curve, power)
+		if i < nbG2 {
+			g2[i] = g2Bytes(curve, power)
+		}
+		power.Mul(power, testTau).Mod(power, r)
+	}
+	if corrupt != nil {
+		corrupt(g1)
+	}
+
+	var buf bytes.Buffer
+	le := func(data interface{}) {
+		if err := binary.Write(&buf, binary.LittleEndian, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf.WriteString("ptau")
+	le(uint32(1)) // version
+	le(uint32(4)) // sections
+
+	le(uint32(sectionHeader))
+	le(uint64(4 + n8 + 4 + 4))
+	le(uint32(n8))
+	qBytes := make([]byte, n8)
+	buf.Write(reverse(q.FillBytes(qBytes)))
+	le(uint32(testPower))
+	le(uint32(testPower)) // ceremony power
+
+	le(uint32(sectionTauG1))
+	le(uint64(nbG1 * 2 * n8))
+	for _, p := range g1 {
+		buf.Write(p)
+	}
+
+	le(uint32(sectionTauG2))
+	le(uint64(nbG2 * 4 * n8))
+	for _, p := range g2 {
+		buf.Write(p)
+	}
+
+	le(uint32(4))
+	le(uint64(nbG2 * 2 * n8))
+	buf.Write(make([]byte, nbG2*2*n8))
+
+	return buf.Bytes()
+}
+
+// g1Bytes returns [s]G1 in the .ptau encoding: the coordinates in Montgomery form, in little endian
+func g1Bytes(curve ecc.ID, s *big.Int) []byte {
+	var limbs []uint64
+	switch curve {
+	case ecc.BN254:
+		_, _, g, _ := bn254.Generators()
+		var p bn254.G1Affine
+		p.ScalarMultiplication(&g, s)
+		limbs = append(append(limbs, p.X[:]...), p.Y[:]...)
+	case ecc.BLS12_381:
+		_, _, g, _ := bls12381.Generators()
+		var p bls12381.G1Affine
+		p.ScalarMultiplication(&g, s)
+		limbs = append(append(limbs, p.X[:]...), p.Y[:]...)
+	}
+	return limbsBytes(limbs)
+}
+
+// g2Bytes returns [s]G2 in the .ptau encoding, see g1Bytes
+func g2Bytes(curve ecc.ID, s *big.Int) []byte {
+	var limbs []uint64
+	switch curve {
+	case ecc.BN254:
+		_, _, _, g := bn254.Generators()
+		var p bn254.G2Affine
+		p.ScalarMultiplication(&g, s)
+		for _, c := range []bn254fp.Element{p.X.A0, p.X.A1, p.Y.A0, p.Y.A1} {
+			limbs = append(limbs, c[:]...)
+		}
+	case ecc.BLS12_381:
+		_, _, _, g := bls12381.Generators()
+		var p bls12381.G2Affine
+		p.ScalarMultiplication(&g, s)
+		for _, c := range []bls12381fp.Element{p.X.A0, p.X.A1, p.Y.A0, p.Y.A1} {
+			limbs = append(limbs, c[:]...)
+		}
+	}
+	return limbsBytes(limbs)
+}
+
+func limbsBytes(limbs []uint64) []byte {
+	res := make([]byte, 8*len(limbs))
+	for i, l := range limbs {
+		binary.LittleEndian.PutUint64(res[8*i:], l)
+	}
+	return res
+}
+
+func TestReadSRS(t *testing.T) {
+	assert := require.New(t)
+	const size = 11
+
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		srs, c, err := ReadSRS(bytes.NewReader(writePtau(t, curve, nil)), size)
+		assert.NoError(err, curve.String())
+		assert.Equal(curve, c)
+
+		switch curve {
+		case ecc.BN254:
+			expected, err := kzg_bn254.NewSRS(size, testTau)
+			assert.NoError(err)
+			assert.Equal(expected, srs)
+		case ecc.BLS12_381:
+			expected, err := kzg_bls12381.NewSRS(size, testTau)
+			assert.NoError(err)
+			assert.Equal(expected, srs)
+		}
+	}
+}
+
+// failingReader fails the test if it is read
+type failingReader struct{ t *testing.T }
+
+func (r failingReader) Read(p []byte) (int, error) {
+	r.t.Fatal("read past the powers of tau")
+	return 0, io.EOF
+}
+
+func TestReadSRSStopsReading(t *testing.T) {
+	ptau := writePtau(t, ecc.BN254, nil)
+	// the section 4 is the last 2**testPower * 2 * 32 bytes
+	ptau = ptau[:len(ptau)-(1<<testPower)*2*32]
+
+	_, _, err := ReadSRS(io.MultiReader(bytes.NewReader(ptau), failingReader{t}), 8)
+	require.NoError(t, err)
+}
+
+func TestCorruptedPower(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		// [τ**5]G1 replaced by [2 τ**5]G1, on the curve
+		ptau := writePtau(t, curve, func(g1 [][]byte) {
+			s := new(big.Int).Exp(testTau, big.NewInt(5), curve.Info().Fr.Modulus())
+			g1[5] = g1Bytes(curve, s.Lsh(s, 1))
+		})
+		_, _, err := ReadSRS(bytes.NewReader(ptau), 11)
+		assert.True(errors.Is(err, ErrInconsistentPowers), "%s: %v", curve, err)
+
+		// out of the SRS, the corrupted power isn't read
+		_, _, err = ReadSRS(bytes.NewReader(ptau), 5)
+		assert.NoError(err, curve.String())
+
+		// a point which isn't on the curve
+		ptau = writePtau(t, curve, func(g1 [][]byte) {
+			g1[3][0] ^= 1
+		})
+		_, _, err = ReadSRS(bytes.NewReader(ptau), 11, WithChecks(0))
+		assert.True(errors.Is(err, ErrInvalidFormat), "%s: %v", curve, err)
+	}
+}
+
+func TestInvalidFormat(t *testing.T) {
+	assert := require.New(t)
+	ptau := writePtau(t, ecc.BN254, nil)
+
+	// not a ptau file
+	bad := append([]byte("ptaw"), ptau[4:]...)
+	_, _, err := ReadSRS(bytes.NewReader(bad), 11)
+	assert.True(errors.Is(err, ErrInvalidFormat), err)
+
+	// more powers than the transcript has
+	_, _, err = ReadSRS(bytes.NewReader(ptau), 1<<(testPower+1))
+	assert.True(errors.Is(err, ErrInvalidFormat), err)
+
+	// truncated
+	_, _, err = ReadSRS(bytes.NewReader(ptau[:100]), 11)
+	assert.Error(err)
+}
+
+// cubicCircuit checks that Y == X**3 + X + 5
+type cubicCircuit struct {
+	X frontend.Variable `gnark:",secret"`
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *cubicCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(circuit.Y, api.Add(api.Mul(circuit.X, circuit.X, circuit.X), circuit.X, 5))
+	return nil
+}
+
+func TestPlonkSetup(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		ccs, err := frontend.Compile(curve, backend.PLONK, &cubicCircuit{})
+		assert.NoError(err)
+		srs, err := ReadSRSFor(ccs, bytes.NewReader(writePtau(t, curve, nil)))
+		assert.NoError(err)
+
+		pk, vk, err := plonk.Setup(ccs, srs)
+		assert.NoError(err)
+		witness := cubicCircuit{X: frontend.Value(3), Y: frontend.Value(35)}
+		proof, err := plonk.Prove(ccs, pk, &witness)
+		assert.NoError(err)
+		assert.NoError(plonk.Verify(proof, vk, &witness))
+	}
+
+	// a transcript on another curve
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &cubicCircuit{})
+	assert.NoError(err)
+	_, err = ReadSRSFor(ccs, bytes.NewReader(writePtau(t, ecc.BLS12_381, nil)))
+	assert.True(errors.Is(err, ErrInvalidFormat), err)
+}
@@ -0,0 +1,37 @@
+//go:build go1.18
+// +build go1.18
+
+package plonk_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonk"
+	gnarkio "github.com/consensys/gnark/io"
+)
+
+// FuzzReadProof reads arbitrary BN254 proofs; it must neither panic nor allocate more than the input
+// (or the read limit) justifies
+func FuzzReadProof(f *testing.F) {
+	proof, _, _ := readLimitsEncodings(f)
+	f.Add(proof)
+	f.Add(append(append([]byte{}, proof[:1+7*32+32+32]...), 0xff, 0xff, 0xff, 0xff)) // see TestReadLimits
+	defer gnarkio.SetMaxReadBytes(gnarkio.SetMaxReadBytes(1 << 20))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = plonk.NewProof(ecc.BN254).ReadFrom(bytes.NewReader(data))
+		_, _ = plonk.NewProof(ecc.BN254).ReadFrom(opaqueReader{bytes.NewReader(data)})
+	})
+}
+
+// FuzzReadVerifyingKey reads arbitrary BN254 verifying keys, see FuzzReadProof
+func FuzzReadVerifyingKey(f *testing.F) {
+	_, vk, _ := readLimitsEncodings(f)
+	f.Add(vk)
+	defer gnarkio.SetMaxReadBytes(gnarkio.SetMaxReadBytes(1 << 20))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = plonk.NewVerifyingKey(ecc.BN254).ReadFrom(bytes.NewReader(data))
+		_, _ = plonk.NewVerifyingKey(ecc.BN254).ReadFrom(opaqueReader{bytes.NewReader(data)})
+	})
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plonk_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// opaqueReader hides the length of the underlying reader
+type opaqueReader struct{ r io.Reader }
+
+func (r opaqueReader) Read(p []byte) (int, error) { return r.r.Read(p) }
+
+// readLimitsEncodings returns the encodings of a BN254 proof, verifying key and proving key
+func readLimitsEncodings(t testing.TB) (proof, vk, pk []byte) {
+	assert := require.New(t)
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &hashChainCircuit{})
+	assert.NoError(err)
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(err)
+	_pk, _vk, err := plonk.Setup(ccs, srs)
+	assert.NoError(err)
+	_proof, err := plonk.Prove(ccs, _pk, hashChainAssignment())
+	assert.NoError(err)
+
+	var bProof, bVK, bPK bytes.Buffer
+	_, err = _proof.WriteTo(&bProof)
+	assert.NoError(err)
+	_, err = _vk.WriteTo(&bVK)
+	assert.NoError(err)
+	_, err = _pk.WriteTo(&bPK)
+	assert.NoError(err)
+	return bProof.Bytes(), bVK.Bytes(), bPK.Bytes()
+}
+
+// TestReadLimits reads encodings whose length fields claim huge slices: they used to be allocated
+// before the values were read
+func TestReadLimits(t *testing.T) {
+	assert := require.New(t)
+	proof, vk, pk := readLimitsEncodings(t)
+
+	// the valid encodings are read
+	_, err := plonk.NewProof(ecc.BN254).ReadFrom(bytes.NewReader(proof))
+	assert.NoError(err)
+	_, err = plonk.NewProvingKey(ecc.BN254).ReadFrom(bytes.NewReader(pk))
+	assert.NoError(err)
+
+	// version, [L]1,[R]1,[O]1,[Z]1,[H0]1,[H1]1,[H2]1 (compressed), then the batched opening proof
+	// [H]1, point, and uint32(len(ClaimedValues)): 2^32-1 claimed values
+	const offsetClaimedValues = 1 + 7*32 + 32 + 32
+	hugeClaimedValues := append([]byte{}, proof[:offsetClaimedValues]...)
+	hugeClaimedValues = append(hugeClaimedValues, 0xff, 0xff, 0xff, 0xff)
+
+	// the proving key starts with the verifying key, then the cardinality of DomainNum: 2^40
+	hugeDomain := append([]byte{}, pk...)
+	binary.BigEndian.PutUint64(hugeDomain[len(vk):len(vk)+8], 1<<40)
+
+	// the remaining length of the reader is known
+	_, err = plonk.NewProof(ecc.BN254).ReadFrom(bytes.NewReader(hugeClaimedValues))
+	assert.ErrorIs(err, gnarkio.ErrLimitExceeded)
+	_, err = plonk.NewProvingKey(ecc.BN254).ReadFrom(bytes.NewReader(hugeDomain))
+	assert.ErrorIs(err, gnarkio.ErrLimitExceeded)
+
+	// or the cap applies
+	defer gnarkio.SetMaxReadBytes(gnarkio.SetMaxReadBytes(1 << 24))
+	_, err = plonk.NewProof(ecc.BN254).ReadFrom(opaqueReader{bytes.NewReader(hugeClaimedValues)})
+	assert.ErrorIs(err, gnarkio.ErrLimitExceeded)
+	_, err = plonk.NewProvingKey(ecc.BN254).ReadFrom(opaqueReader{bytes.NewReader(hugeDomain)})
+	assert.ErrorIs(err, gnarkio.ErrLimitExceeded)
+	_, err = plonk.NewProvingKey(ecc.BN254).ReadFrom(opaqueReader{bytes.NewReader(pk)})
+	assert.NoError(err)
+}
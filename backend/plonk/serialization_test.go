@@ -0,0 +1,128 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plonk_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// hashChainCircuit hashes X 16 times with MiMC
+type hashChainCircuit struct {
+	X frontend.Variable `gnark:",secret"`
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *hashChainCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	h, err := mimc.NewMiMC("seed", curveID, api)
+	if err != nil {
+		return err
+	}
+	x := circuit.X
+	for i := 0; i < 16; i++ {
+		h.Reset()
+		h.Write(x)
+		x = h.Sum()
+	}
+	api.AssertIsEqual(x, circuit.Y)
+	return nil
+}
+
+func hashChainAssignment() *hashChainCircuit {
+	x := make([]byte, 32)
+	x[31] = 42
+	var w hashChainCircuit
+	w.X.Assign(x)
+	for i := 0; i < 16; i++ {
+		h := hash.MIMC_BN254.New("seed")
+		h.Write(x)
+		x = h.Sum(nil)
+	}
+	w.Y.Assign(x)
+	return &w
+}
+
+func TestSparseR1CSSerialization(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &hashChainCircuit{})
+	assert.NoError(err)
+	spr := ccs.(*cs_bn254.SparseR1CS)
+
+	// the previous format encoded the SparseR1CS as is
+	mode, err := cbor.CoreDetEncOptions().EncMode()
+	assert.NoError(err)
+	old, err := mode.Marshal(spr)
+	assert.NoError(err)
+
+	var buf bytes.Buffer
+	written, err := ccs.WriteTo(&buf)
+	assert.NoError(err)
+	assert.Equal(int64(buf.Len()), written)
+	t.Logf("%d gates: %d bytes, %d bytes in the previous format (%.1fx smaller)",
+		ccs.GetNbConstraints(), buf.Len(), len(old), float64(len(old))/float64(buf.Len()))
+	assert.Less(3*buf.Len(), len(old))
+
+	// both formats read to the same constraint system
+	var read, readOld, readUnsafe cs_bn254.SparseR1CS
+	n, err := read.ReadFrom(bytes.NewReader(buf.Bytes()))
+	assert.NoError(err)
+	assert.Equal(written, n)
+	_, err = readOld.ReadFrom(bytes.NewReader(old))
+	assert.NoError(err)
+	_, err = readUnsafe.UnsafeReadFrom(bytes.NewReader(buf.Bytes()))
+	assert.NoError(err)
+	assert.Equal(spr.Constraints, read.Constraints)
+	assert.Equal(spr.Constraints, readOld.Constraints)
+	assert.Equal(spr.Constraints, readUnsafe.Constraints)
+	assert.Nil(read.Gates)
+
+	// the proofs of the read constraint system verify
+	srs, err := test.NewKZGSRS(&read)
+	assert.NoError(err)
+	pk, vk, err := plonk.Setup(&read, srs)
+	assert.NoError(err)
+	proof, err := plonk.Prove(&read, pk, hashChainAssignment())
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(proof, vk, hashChainAssignment()))
+
+	// the gates must reference existing wires, unless read with UnsafeReadFrom
+	invalid := spr.SparseR1CS.Compact()
+	invalid.NbInternalVariables = 0
+	data, err := mode.Marshal(&cs_bn254.SparseR1CS{SparseR1CS: invalid, Coefficients: spr.Coefficients})
+	assert.NoError(err)
+	_, err = new(cs_bn254.SparseR1CS).ReadFrom(bytes.NewReader(data))
+	assert.Error(err)
+	_, err = new(cs_bn254.SparseR1CS).UnsafeReadFrom(bytes.NewReader(data))
+	assert.NoError(err)
+
+	// truncated gates are rejected in both cases
+	invalid.Gates.Gates = invalid.Gates.Gates[:len(invalid.Gates.Gates)-1]
+	data, err = mode.Marshal(&cs_bn254.SparseR1CS{SparseR1CS: invalid, Coefficients: spr.Coefficients})
+	assert.NoError(err)
+	_, err = new(cs_bn254.SparseR1CS).UnsafeReadFrom(bytes.NewReader(data))
+	assert.Error(err)
+}
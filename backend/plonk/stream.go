@@ -0,0 +1,75 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plonk
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/common"
+)
+
+// proofComponents are the components of a streamed proof, in the order of Proof.WriteTo
+var proofComponents = []string{"lro", "z", "h", "batchedProof", "zShiftedOpening", "lookup"}
+
+// ProveStream is Prove, writing the components of the proof to w as soon as they are final, in the
+// order the prover computes them: the commitments to the wires (l, r, o), to the permutation polynomial
+// z, to the quotient h, then the opening proofs with the claimed evaluations, and the lookup proof if
+// the circuit has lookups. A consumer may transfer the first components while the prover computes the
+// opening proofs. ReadStreamedProof reads the proof back.
+//
+// The function set with backend.WithProofComponents, if any, is called after each component is written.
+// The components are framed, and a checksum follows the last one: if the prover fails after writing
+// some of them (or if the self-check fails, see backend.WithSelfCheck), ProveStream returns the error
+// without writing the checksum, and ReadStreamedProof fails with backend.ErrInvalidProofStream.
+func ProveStream(ccs frontend.CompiledConstraintSystem, pk ProvingKey, fullWitness frontend.Circuit, w io.Writer, opts ...func(opt *backend.ProverOption) error) (Proof, error) {
+	stream := common.NewProofStreamWriter(w, backend.PLONK, ccs.CurveID())
+	opts = append(opts[:len(opts):len(opts)], stream.ProverOption())
+	proof, err := Prove(ccs, pk, fullWitness, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Close(); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// ReadStreamedProof reads a proof written by ProveStream. The proof is the one ProveStream returned,
+// with the same encoding with Proof.WriteTo. It fails with backend.ErrInvalidProofStream (wrapped) if
+// the stream is truncated, corrupted, or misses a component.
+func ReadStreamedProof(r io.Reader) (Proof, error) {
+	curveID, components, err := common.ReadProofStream(r, backend.PLONK)
+	if err != nil {
+		return nil, err
+	}
+	b, err := common.AssembleProofStream(components, proofComponents, map[string]bool{"lookup": true})
+	if err != nil {
+		return nil, err
+	}
+
+	proof := NewProof(curveID)
+	n, err := proof.ReadFrom(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", backend.ErrInvalidProofStream, err)
+	}
+	if n != int64(len(b)) {
+		return nil, fmt.Errorf("%w: %d trailing bytes in the components", backend.ErrInvalidProofStream, int64(len(b))-n)
+	}
+	return proof, nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plonk_test
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// memberCircuit asserts that X is in a set large enough to be a lookup table
+type memberCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *memberCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	set := make([]big.Int, 32)
+	for i := range set {
+		set[i].SetInt64(int64(i * i))
+	}
+	api.AssertIsMember(circuit.X, set)
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+func TestProveStream(t *testing.T) {
+	var member memberCircuit
+	member.X.Assign(49)
+	member.Y.Assign(2401)
+
+	for name, c := range map[string]struct {
+		circuit, assignment frontend.Circuit
+		components          []string
+	}{
+		"hashChain": {&hashChainCircuit{}, hashChainAssignment(), []string{"lro", "z", "h", "zShiftedOpening", "batchedProof"}},
+		"lookup":    {&memberCircuit{}, &member, []string{"lro", "z", "h", "zShiftedOpening", "batchedProof", "lookup"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, c.circuit)
+			assert.NoError(err)
+			srs, err := test.NewKZGSRS(ccs)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(ccs, srs)
+			assert.NoError(err)
+
+			// the components are written in the order they are computed
+			var names []string
+			record := backend.WithProofComponents(func(name string, data []byte) error {
+				names = append(names, name)
+				return nil
+			})
+			proof, err := plonk.Prove(ccs, pk, c.assignment, backend.WithRandomness(rand.New(rand.NewSource(1))), record)
+			assert.NoError(err)
+			assert.Equal(c.components, names)
+
+			var stream bytes.Buffer
+			streamed, err := plonk.ProveStream(ccs, pk, c.assignment, &stream, backend.WithRandomness(rand.New(rand.NewSource(1))))
+			assert.NoError(err)
+
+			// the proof read from the stream is the one of the regular prover
+			read, err := plonk.ReadStreamedProof(bytes.NewReader(stream.Bytes()))
+			assert.NoError(err)
+			var expected, got bytes.Buffer
+			_, err = proof.WriteTo(&expected)
+			assert.NoError(err)
+			_, err = read.WriteTo(&got)
+			assert.NoError(err)
+			assert.Equal(expected.Bytes(), got.Bytes())
+			assert.Equal(streamed, read)
+			assert.NoError(plonk.Verify(read, vk, c.assignment))
+
+			// a truncated stream is rejected
+			for i := 0; i < stream.Len(); i++ {
+				_, err := plonk.ReadStreamedProof(bytes.NewReader(stream.Bytes()[:i]))
+				assert.ErrorIs(err, backend.ErrInvalidProofStream, "truncated at %d", i)
+			}
+
+			// as is a corrupted one
+			corrupted := append([]byte(nil), stream.Bytes()...)
+			corrupted[len(corrupted)/2] ^= 1
+			_, err = plonk.ReadStreamedProof(bytes.NewReader(corrupted))
+			assert.ErrorIs(err, backend.ErrInvalidProofStream)
+		})
+	}
+}
+
+var errAbort = errors.New("abort")
+
+func TestProveStreamFailure(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &hashChainCircuit{})
+	assert.NoError(err)
+	srs, err := test.NewKZGSRS(ccs)
+	assert.NoError(err)
+	pk, _, err := plonk.Setup(ccs, srs)
+	assert.NoError(err)
+
+	// the prover fails after writing a component: the stream has no checksum
+	fail := backend.WithProofComponents(func(name string, data []byte) error {
+		if name == "h" {
+			return errAbort
+		}
+		return nil
+	})
+	var stream bytes.Buffer
+	_, err = plonk.ProveStream(ccs, pk, hashChainAssignment(), &stream, fail)
+	assert.ErrorIs(err, errAbort)
+	_, err = plonk.ReadStreamedProof(bytes.NewReader(stream.Bytes()))
+	assert.ErrorIs(err, backend.ErrInvalidProofStream)
+
+	// as when the witness doesn't satisfy the circuit
+	var invalid hashChainCircuit
+	invalid.X.Assign(1)
+	invalid.Y.Assign(42)
+	stream.Reset()
+	_, err = plonk.ProveStream(ccs, pk, &invalid, &stream)
+	assert.Error(err)
+	_, err = plonk.ReadStreamedProof(bytes.NewReader(stream.Bytes()))
+	assert.ErrorIs(err, backend.ErrInvalidProofStream)
+}
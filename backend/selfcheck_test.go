@@ -0,0 +1,118 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	plonk_bn254 "github.com/consensys/gnark/internal/backend/bn254/plonk"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+type selfCheckCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *selfCheckCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+func TestSelfCheck(t *testing.T) {
+	assert := require.New(t)
+
+	var w selfCheckCircuit
+	w.X.Assign(3)
+	w.Y.Assign(27)
+	var fullWitness bytes.Buffer
+	_, err := witness.WriteFullTo(&fullWitness, ecc.BN254, &w)
+	assert.NoError(err)
+
+	// groth16
+	{
+		ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &selfCheckCircuit{})
+		assert.NoError(err)
+		pk, vk, err := groth16.Setup(ccs)
+		assert.NoError(err)
+
+		proof, err := groth16.Prove(ccs, pk, &w, backend.WithSelfCheck(), groth16.WithVerifyingKey(vk))
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, &w))
+		_, err = groth16.ReadAndProve(ccs, pk, bytes.NewReader(fullWitness.Bytes()), backend.WithSelfCheck(), groth16.WithVerifyingKey(vk))
+		assert.NoError(err)
+
+		// the verifying key can't be derived from the proving key
+		_, err = groth16.Prove(ccs, pk, &w, backend.WithSelfCheck())
+		assert.Error(err)
+		assert.NotErrorIs(err, backend.ErrSelfCheckFailed)
+
+		// corrupt the proving key
+		_pk := pk.(*groth16_bn254.ProvingKey)
+		_pk.G1.Alpha = _pk.G1.Beta
+
+		_, err = groth16.Prove(ccs, pk, &w, backend.WithSelfCheck(), groth16.WithVerifyingKey(vk))
+		assert.ErrorIs(err, backend.ErrSelfCheckFailed)
+		_, err = groth16.ReadAndProve(ccs, pk, bytes.NewReader(fullWitness.Bytes()), backend.WithSelfCheck(), groth16.WithVerifyingKey(vk))
+		assert.ErrorIs(err, backend.ErrSelfCheckFailed)
+
+		proof, err = groth16.Prove(ccs, pk, &w)
+		assert.NoError(err)
+		assert.Error(groth16.Verify(proof, vk, &w))
+	}
+
+	// plonk
+	{
+		ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &selfCheckCircuit{})
+		assert.NoError(err)
+		srs, err := test.NewKZGSRS(ccs)
+		assert.NoError(err)
+		pk, vk, err := plonk.Setup(ccs, srs)
+		assert.NoError(err)
+
+		// the verifying key is the one of the proving key, unless specified
+		proof, err := plonk.Prove(ccs, pk, &w, backend.WithSelfCheck())
+		assert.NoError(err)
+		assert.NoError(plonk.Verify(proof, vk, &w))
+		_, err = plonk.Prove(ccs, pk, &w, backend.WithSelfCheck(), plonk.WithVerifyingKey(vk))
+		assert.NoError(err)
+		_, err = plonk.ReadAndProve(ccs, pk, bytes.NewReader(fullWitness.Bytes()), backend.WithSelfCheck())
+		assert.NoError(err)
+
+		// corrupt the proving key
+		_pk := pk.(*plonk_bn254.ProvingKey)
+		_pk.Ql[0].SetUint64(42)
+
+		_, err = plonk.Prove(ccs, pk, &w, backend.WithSelfCheck())
+		assert.ErrorIs(err, backend.ErrSelfCheckFailed)
+		_, err = plonk.ReadAndProve(ccs, pk, bytes.NewReader(fullWitness.Bytes()), backend.WithSelfCheck())
+		assert.ErrorIs(err, backend.ErrSelfCheckFailed)
+
+		proof, err = plonk.Prove(ccs, pk, &w)
+		assert.NoError(err)
+		assert.Error(plonk.Verify(proof, vk, &w))
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import "sync"
+
+// SharedSolution carries the wire values solved by the R1CS solver of a circuit compiled with
+// frontend.CompileBoth to the SparseR1CS solver of the same circuit, see WithSharedSolution.
+// It is safe for concurrent use.
+type SharedSolution struct {
+	lock    sync.Mutex
+	mapping []int
+	values  interface{}
+}
+
+// NewSharedSolution returns a SharedSolution for the wire mapping returned by frontend.CompileBoth:
+// mapping[i] is the R1CS wire of the SparseR1CS wire i, or -1 for the wires of the SparseR1CS only.
+func NewSharedSolution(mapping []int) *SharedSolution {
+	return &SharedSolution{mapping: mapping}
+}
+
+// Mapping returns the wire mapping of the SharedSolution, it must not be modified
+func (s *SharedSolution) Mapping() []int {
+	return s.mapping
+}
+
+// Load returns the values stored by the last R1CS solve, if any
+func (s *SharedSolution) Load() (interface{}, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.values, s.values != nil
+}
+
+// Store sets the values of the R1CS wires (solver specific). The solver doesn't modify them once stored.
+func (s *SharedSolution) Store(values interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.values = values
+}
@@ -0,0 +1,407 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package simulated implements a transparent mock of a proof system (backend.SIMULATED), for fast tests.
+//
+// It runs everything a Groth16 prover runs but the cryptography: the circuit is compiled to a R1CS
+// (see frontend.Compile), the witness is read into the witness vector of the curve (from an assignment
+// or from its binary serialization, see package witness), and the solver computes the wires, calling the
+// hints, and checks all the constraints. The "proof" is then a digest of the circuit and of the public
+// witness, which Verify recomputes from the public witness it is given.
+//
+// A proof is not zero-knowledge, and anyone can forge one: the package is meant for the tests
+// (see test.WithBackends), never for production.
+package simulated
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	gnarkwitness "github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/compiled"
+
+	backend_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
+	backend_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
+	backend_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	backend_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	backend_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
+	backend_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+
+	witness_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/witness"
+	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
+	witness_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/witness"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	witness_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/witness"
+	witness_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/witness"
+)
+
+// ErrInvalidProof is returned by Verify when the proof wasn't computed for this circuit and public witness,
+// or was computed ignoring the solver errors (see backend.IgnoreSolverError)
+var ErrInvalidProof = errors.New("simulated proof doesn't match the circuit and the public witness")
+
+// ErrCircuitMismatch is returned by Prove when the proving key was generated for a constraint system
+// of another curve or with another number of public inputs
+var ErrCircuitMismatch = errors.New("proving key and constraint system don't match")
+
+// ProvingKey of the simulated backend: the digest of the constraint system it was generated for
+type ProvingKey struct {
+	Curve         ecc.ID
+	CircuitDigest [sha256.Size]byte
+	NbPublic      int // public inputs, without the constant wire
+	Metadata      map[string]string
+}
+
+// VerifyingKey of the simulated backend, the same as the proving key
+type VerifyingKey struct {
+	Curve         ecc.ID
+	CircuitDigest [sha256.Size]byte
+	NbPublic      int // public inputs, without the constant wire
+}
+
+// Proof of the simulated backend: the digest of the circuit and of the public witness, or zero if it was
+// computed ignoring the solver errors
+type Proof struct {
+	Digest [sha256.Size]byte
+}
+
+// CurveID returns the curve of the constraint system the key was generated for
+func (pk *ProvingKey) CurveID() ecc.ID {
+	return pk.Curve
+}
+
+// GetMetadata returns the metadata of the constraint system the key was generated for
+func (pk *ProvingKey) GetMetadata() map[string]string {
+	return pk.Metadata
+}
+
+// CurveID returns the curve of the constraint system the key was generated for
+func (vk *VerifyingKey) CurveID() ecc.ID {
+	return vk.Curve
+}
+
+// NbPublicWitness returns the number of elements in the expected public witness
+func (vk *VerifyingKey) NbPublicWitness() int {
+	return vk.NbPublic
+}
+
+// Setup returns the keys of the constraint system, which must be compiled for backend.SIMULATED (or
+// backend.GROTH16): they hold the digest of its serialization. It doesn't run any cryptographic setup
+func Setup(ccs frontend.CompiledConstraintSystem) (*ProvingKey, *VerifyingKey, error) {
+	if _, err := r1csOf(ccs); err != nil {
+		return nil, nil, err
+	}
+	h := sha256.New()
+	h.Write([]byte(ccs.CurveID().String()))
+	if _, err := ccs.WriteTo(h); err != nil {
+		return nil, nil, err
+	}
+
+	_, _, nbPublic := ccs.GetNbVariables()
+	pk := &ProvingKey{
+		Curve:    ccs.CurveID(),
+		NbPublic: nbPublic - 1,
+		Metadata: compiled.CopyMetadata(ccs.GetMetadata()),
+	}
+	copy(pk.CircuitDigest[:], h.Sum(nil))
+	vk := &VerifyingKey{
+		Curve:         pk.Curve,
+		CircuitDigest: pk.CircuitDigest,
+		NbPublic:      pk.NbPublic,
+	}
+	return pk, vk, nil
+}
+
+// Prove solves the constraint system with the assignment, and returns the digest of the circuit and of its
+// public witness. As with groth16.Prove, the public outputs (see frontend.API.SetOutput) are the values
+// computed by the solver, and backend.IgnoreSolverError returns a proof which doesn't verify instead
+// of the solver error.
+func Prove(ccs frontend.CompiledConstraintSystem, pk *ProvingKey, assignment frontend.Circuit, opts ...func(opt *backend.ProverOption) error) (*Proof, error) {
+	opt, err := backend.NewProverOption(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkProvingKey(ccs, pk); err != nil {
+		return nil, err
+	}
+	if err := gnarkwitness.CheckFrVector(assignment, ccs); err != nil {
+		return nil, err
+	}
+
+	var w interface{}
+	switch ccs.(type) {
+	case *backend_bls12377.R1CS:
+		_w := witness_bls12377.Witness{}
+		err = _w.FromFullAssignment(assignment)
+		w = _w
+	case *backend_bls12381.R1CS:
+		_w := witness_bls12381.Witness{}
+		err = _w.FromFullAssignment(assignment)
+		w = _w
+	case *backend_bn254.R1CS:
+		_w := witness_bn254.Witness{}
+		err = _w.FromFullAssignment(assignment)
+		w = _w
+	case *backend_bw6761.R1CS:
+		_w := witness_bw6761.Witness{}
+		err = _w.FromFullAssignment(assignment)
+		w = _w
+	case *backend_bw6633.R1CS:
+		_w := witness_bw6633.Witness{}
+		err = _w.FromFullAssignment(assignment)
+		w = _w
+	case *backend_bls24315.R1CS:
+		_w := witness_bls24315.Witness{}
+		err = _w.FromFullAssignment(assignment)
+		w = _w
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return prove(ccs, pk, w, opt)
+}
+
+// ReadAndProve behaves like Prove, except the full witness is read from r, encoded following the binary
+// serialization protocol described in gnark/backend/witness package
+func ReadAndProve(ccs frontend.CompiledConstraintSystem, pk *ProvingKey, r io.Reader, opts ...func(opt *backend.ProverOption) error) (*Proof, error) {
+	opt, err := backend.NewProverOption(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkProvingKey(ccs, pk); err != nil {
+		return nil, err
+	}
+
+	_, nbSecret, nbPublic := ccs.GetNbVariables()
+	expectedSize := nbSecret + nbPublic - 1
+	nbOptional := compiled.NbTrailingOptional(ccs.GetOptionalSecrets(), nbSecret)
+
+	var w interface{}
+	switch ccs.(type) {
+	case *backend_bls12377.R1CS:
+		_w := witness_bls12377.Witness{}
+		_, err = _w.LimitReadFromOptional(r, expectedSize, nbOptional)
+		w = _w
+	case *backend_bls12381.R1CS:
+		_w := witness_bls12381.Witness{}
+		_, err = _w.LimitReadFromOptional(r, expectedSize, nbOptional)
+		w = _w
+	case *backend_bn254.R1CS:
+		_w := witness_bn254.Witness{}
+		_, err = _w.LimitReadFromOptional(r, expectedSize, nbOptional)
+		w = _w
+	case *backend_bw6761.R1CS:
+		_w := witness_bw6761.Witness{}
+		_, err = _w.LimitReadFromOptional(r, expectedSize, nbOptional)
+		w = _w
+	case *backend_bw6633.R1CS:
+		_w := witness_bw6633.Witness{}
+		_, err = _w.LimitReadFromOptional(r, expectedSize, nbOptional)
+		w = _w
+	case *backend_bls24315.R1CS:
+		_w := witness_bls24315.Witness{}
+		_, err = _w.LimitReadFromOptional(r, expectedSize, nbOptional)
+		w = _w
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return prove(ccs, pk, w, opt)
+}
+
+// Verify returns nil if the proof was computed for the circuit of the verifying key and the public witness
+func Verify(proof *Proof, vk *VerifyingKey, publicWitness frontend.Circuit) error {
+	var buf bytes.Buffer
+	if _, err := gnarkwitness.WritePublicTo(&buf, vk.Curve, publicWitness); err != nil {
+		return err
+	}
+	return ReadAndVerify(proof, vk, &buf)
+}
+
+// ReadAndVerify behaves like Verify, except the public witness is read from r, encoded following the
+// binary serialization protocol described in gnark/backend/witness package
+func ReadAndVerify(proof *Proof, vk *VerifyingKey, r io.Reader) error {
+	public, err := readPublic(r, vk.Curve, vk.NbPublic)
+	if err != nil {
+		return err
+	}
+	if digest(vk.CircuitDigest, vk.Curve, public) != proof.Digest {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// IsSolved attempts to solve the constraint system with provided witness
+// returns nil if it succeeds, error otherwise.
+func IsSolved(ccs frontend.CompiledConstraintSystem, witness frontend.Circuit, opts ...func(opt *backend.ProverOption) error) error {
+	return groth16.IsSolved(ccs, witness, opts...)
+}
+
+// prove solves the constraint system with w, the witness vector of its curve, and returns the digest of
+// its public part, in which the public outputs are replaced by the values the solver computed
+func prove(ccs frontend.CompiledConstraintSystem, pk *ProvingKey, w interface{}, opt backend.ProverOption) (*Proof, error) {
+	var outputs map[string]*big.Int
+	publicOutputs := opt.PublicOutputs
+	opt.PublicOutputs = func(values map[string]*big.Int) {
+		outputs = values
+		if publicOutputs != nil {
+			publicOutputs(values)
+		}
+	}
+
+	public := make([]big.Int, pk.NbPublic)
+	var err error
+	switch _r1cs := ccs.(type) {
+	case *backend_bls12377.R1CS:
+		_w := w.(witness_bls12377.Witness)
+		for i := range public {
+			_w[i].ToBigIntRegular(&public[i])
+		}
+		err = _r1cs.IsSolved(_w, opt)
+	case *backend_bls12381.R1CS:
+		_w := w.(witness_bls12381.Witness)
+		for i := range public {
+			_w[i].ToBigIntRegular(&public[i])
+		}
+		err = _r1cs.IsSolved(_w, opt)
+	case *backend_bn254.R1CS:
+		_w := w.(witness_bn254.Witness)
+		for i := range public {
+			_w[i].ToBigIntRegular(&public[i])
+		}
+		err = _r1cs.IsSolved(_w, opt)
+	case *backend_bw6761.R1CS:
+		_w := w.(witness_bw6761.Witness)
+		for i := range public {
+			_w[i].ToBigIntRegular(&public[i])
+		}
+		err = _r1cs.IsSolved(_w, opt)
+	case *backend_bw6633.R1CS:
+		_w := w.(witness_bw6633.Witness)
+		for i := range public {
+			_w[i].ToBigIntRegular(&public[i])
+		}
+		err = _r1cs.IsSolved(_w, opt)
+	case *backend_bls24315.R1CS:
+		_w := w.(witness_bls24315.Witness)
+		for i := range public {
+			_w[i].ToBigIntRegular(&public[i])
+		}
+		err = _r1cs.IsSolved(_w, opt)
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+	if err != nil {
+		if opt.Force {
+			// the zero digest is never the one of a public witness
+			return &Proof{}, nil
+		}
+		return nil, err
+	}
+
+	r1cs, _ := r1csOf(ccs)
+	for _, o := range r1cs.PublicOutputs {
+		public[o.Index].Set(outputs[o.Name])
+	}
+	return &Proof{Digest: digest(pk.CircuitDigest, pk.Curve, public)}, nil
+}
+
+// checkProvingKey returns an error if pk wasn't generated for a constraint system like ccs
+func checkProvingKey(ccs frontend.CompiledConstraintSystem, pk *ProvingKey) error {
+	if _, err := r1csOf(ccs); err != nil {
+		return err
+	}
+	if !compiled.EqualMetadata(ccs.GetMetadata(), pk.Metadata) {
+		return backend.ErrMetadataMismatch
+	}
+	_, _, nbPublic := ccs.GetNbVariables()
+	if ccs.CurveID() != pk.Curve || nbPublic-1 != pk.NbPublic {
+		return ErrCircuitMismatch
+	}
+	return nil
+}
+
+// r1csOf returns the R1CS underlying ccs, or an error if ccs wasn't compiled to a R1CS
+func r1csOf(ccs frontend.CompiledConstraintSystem) (*compiled.R1CS, error) {
+	switch _r1cs := ccs.(type) {
+	case *backend_bls12377.R1CS:
+		return &_r1cs.R1CS, nil
+	case *backend_bls12381.R1CS:
+		return &_r1cs.R1CS, nil
+	case *backend_bn254.R1CS:
+		return &_r1cs.R1CS, nil
+	case *backend_bw6761.R1CS:
+		return &_r1cs.R1CS, nil
+	case *backend_bw6633.R1CS:
+		return &_r1cs.R1CS, nil
+	case *backend_bls24315.R1CS:
+		return &_r1cs.R1CS, nil
+	default:
+		return nil, fmt.Errorf("%T is not a R1CS, compile the circuit for backend.SIMULATED", ccs)
+	}
+}
+
+// readPublic reads a public witness of nbPublic elements, [uint32(nbElements) | publicVariables], see
+// package witness. The values are reduced modulo the scalar field of the curve
+func readPublic(r io.Reader, curve ecc.ID, nbPublic int) ([]big.Int, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if n := int(binary.BigEndian.Uint32(header[:])); n != nbPublic {
+		return nil, fmt.Errorf("invalid witness size, got %d, expected %d", n, nbPublic)
+	}
+
+	modulus := curve.Info().Fr.Modulus()
+	buf := make([]byte, elementSize(curve))
+	public := make([]big.Int, nbPublic)
+	for i := range public {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		public[i].SetBytes(buf).Mod(&public[i], modulus)
+	}
+	return public, nil
+}
+
+// digest returns the hash of the circuit digest and of the public witness
+func digest(circuitDigest [sha256.Size]byte, curve ecc.ID, public []big.Int) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write(circuitDigest[:])
+	buf := make([]byte, elementSize(curve))
+	for i := range public {
+		public[i].FillBytes(buf)
+		h.Write(buf)
+	}
+	var res [sha256.Size]byte
+	copy(res[:], h.Sum(nil))
+	return res
+}
+
+func elementSize(curve ecc.ID) int {
+	return (curve.Info().Fr.Modulus().BitLen() + 7) / 8
+}
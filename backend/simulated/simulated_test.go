@@ -0,0 +1,206 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulated_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/simulated"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// sumCircuit checks that the non-zero Xs sum to Sum; IsZero is solved by a hint
+type sumCircuit struct {
+	Xs  [3]frontend.Variable `gnark:",secret"`
+	Sum frontend.Variable    `gnark:",public"`
+}
+
+func (circuit *sumCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	sum := api.Constant(0)
+	for _, x := range circuit.Xs {
+		sum = api.Add(sum, api.Select(api.IsZero(x), 0, x))
+	}
+	api.AssertIsEqual(sum, circuit.Sum)
+	return nil
+}
+
+// sumCircuitV1 is an older version of sumCircuit, its assignments are missing an input
+type sumCircuitV1 struct {
+	Xs  [2]frontend.Variable `gnark:",secret"`
+	Sum frontend.Variable    `gnark:",public"`
+}
+
+func (circuit *sumCircuitV1) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Add(circuit.Xs[0], circuit.Xs[1]), circuit.Sum)
+	return nil
+}
+
+func TestProverSucceeded(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assert.ProverSucceeded(&sumCircuit{}, &sumCircuit{Xs: [3]frontend.Variable{frontend.Value(1), frontend.Value(0), frontend.Value(41)}, Sum: frontend.Value(42)},
+		test.WithBackends(backend.SIMULATED))
+}
+
+func TestUnsatisfiedConstraint(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assert.ProverFailed(&sumCircuit{}, &sumCircuit{Xs: [3]frontend.Variable{frontend.Value(1), frontend.Value(0), frontend.Value(41)}, Sum: frontend.Value(43)},
+		test.WithBackends(backend.SIMULATED))
+}
+
+func TestWitnessShape(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assert.ProverFailed(&sumCircuit{}, &sumCircuitV1{Xs: [2]frontend.Variable{frontend.Value(1), frontend.Value(41)}, Sum: frontend.Value(42)},
+		test.WithBackends(backend.SIMULATED))
+
+	// the serialized witness too
+	ccs, err := frontend.Compile(ecc.BN254, backend.SIMULATED, &sumCircuit{})
+	assert.NoError(err)
+	pk, _, err := simulated.Setup(ccs)
+	assert.NoError(err)
+	var buf bytes.Buffer
+	_, err = witness.WriteFullTo(&buf, ecc.BN254, &sumCircuitV1{Xs: [2]frontend.Variable{frontend.Value(1), frontend.Value(41)}, Sum: frontend.Value(42)})
+	assert.NoError(err)
+	_, err = simulated.ReadAndProve(ccs, pk, &buf)
+	assert.Error(err)
+}
+
+func TestVerify(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.SIMULATED, &sumCircuit{})
+	assert.NoError(err)
+	pk, vk, err := simulated.Setup(ccs)
+	assert.NoError(err)
+
+	proof, err := simulated.Prove(ccs, pk, &sumCircuit{Xs: [3]frontend.Variable{frontend.Value(1), frontend.Value(0), frontend.Value(41)}, Sum: frontend.Value(42)})
+	assert.NoError(err)
+	assert.NoError(simulated.Verify(proof, vk, &sumCircuit{Sum: frontend.Value(42)}))
+	assert.ErrorIs(simulated.Verify(proof, vk, &sumCircuit{Sum: frontend.Value(43)}), simulated.ErrInvalidProof)
+
+	// a proof ignoring the solver errors never verifies
+	proof, err = simulated.Prove(ccs, pk, &sumCircuit{Xs: [3]frontend.Variable{frontend.Value(1), frontend.Value(0), frontend.Value(41)}, Sum: frontend.Value(43)}, backend.IgnoreSolverError)
+	assert.NoError(err)
+	assert.ErrorIs(simulated.Verify(proof, vk, &sumCircuit{Sum: frontend.Value(43)}), simulated.ErrInvalidProof)
+
+	// nor with the key of another circuit with the same public inputs
+	other, err := frontend.Compile(ecc.BN254, backend.SIMULATED, &sumCircuitV1{})
+	assert.NoError(err)
+	_, otherVK, err := simulated.Setup(other)
+	assert.NoError(err)
+	proof, err = simulated.Prove(ccs, pk, &sumCircuit{Xs: [3]frontend.Variable{frontend.Value(1), frontend.Value(0), frontend.Value(41)}, Sum: frontend.Value(42)})
+	assert.NoError(err)
+	assert.ErrorIs(simulated.Verify(proof, otherVK, &sumCircuit{Sum: frontend.Value(42)}), simulated.ErrInvalidProof)
+
+	// PLONK constraint systems are not simulated
+	scs, err := frontend.Compile(ecc.BN254, backend.PLONK, &sumCircuit{})
+	assert.NoError(err)
+	_, _, err = simulated.Setup(scs)
+	assert.Error(err)
+}
+
+// cubeCircuit computes Y = X**3 + X + 5
+type cubeCircuit struct {
+	X frontend.Variable `gnark:",secret"`
+	Y frontend.Variable `gnark:",public,output"`
+}
+
+func (circuit *cubeCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.SetOutput(circuit.Y, api.Add(api.Mul(circuit.X, circuit.X, circuit.X), circuit.X, 5))
+	return nil
+}
+
+func TestPublicOutputs(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.SIMULATED, &cubeCircuit{})
+	assert.NoError(err)
+	pk, vk, err := simulated.Setup(ccs)
+	assert.NoError(err)
+
+	var outputs map[string]*big.Int
+	proof, err := simulated.Prove(ccs, pk, &cubeCircuit{X: frontend.Value(3)}, backend.WithPublicOutputs(func(o map[string]*big.Int) {
+		outputs = o
+	}))
+	assert.NoError(err)
+	assert.Equal(0, outputs["Y"].Cmp(big.NewInt(35)))
+
+	assert.NoError(simulated.Verify(proof, vk, &cubeCircuit{Y: frontend.Value(35)}))
+	assert.ErrorIs(simulated.Verify(proof, vk, &cubeCircuit{Y: frontend.Value(36)}), simulated.ErrInvalidProof)
+}
+
+// chainCircuit is a chain of n multiplications
+type chainCircuit struct {
+	n int
+	X frontend.Variable `gnark:",secret"`
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *chainCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	y := circuit.X
+	for i := 0; i < circuit.n; i++ {
+		y = api.Mul(y, y, circuit.X)
+	}
+	api.AssertIsEqual(y, circuit.Y)
+	return nil
+}
+
+// TestFasterThanGroth16 checks that the simulated setup, prove and verify take less than a tenth
+// of the Groth16 ones, on a circuit of 100k constraints
+func TestFasterThanGroth16(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the Groth16 setup of 100k constraints in short mode")
+	}
+	assert := require.New(t)
+
+	const n = 50000 // 2 constraints per multiplication
+	circuit := chainCircuit{n: n}
+	ccs, err := frontend.Compile(ecc.BN254, backend.SIMULATED, &circuit)
+	assert.NoError(err)
+	assert.GreaterOrEqual(ccs.GetNbConstraints(), 100000)
+
+	// y = x**(2n+1) = 1 for x = 1, and for x = -1
+	assignment := chainCircuit{X: frontend.Value(-1), Y: frontend.Value(-1)}
+
+	start := time.Now()
+	pk, vk, err := simulated.Setup(ccs)
+	assert.NoError(err)
+	proof, err := simulated.Prove(ccs, pk, &assignment)
+	assert.NoError(err)
+	assert.NoError(simulated.Verify(proof, vk, &assignment))
+	simulatedTime := time.Since(start)
+
+	start = time.Now()
+	gpk, gvk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	gproof, err := groth16.Prove(ccs, gpk, &assignment)
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(gproof, gvk, &assignment))
+	groth16Time := time.Since(start)
+
+	t.Logf("simulated: %s, groth16: %s", simulatedTime, groth16Time)
+	assert.Less(10*simulatedTime, groth16Time, "the simulated backend should be an order of magnitude faster")
+}
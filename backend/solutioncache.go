@@ -0,0 +1,57 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import "sync"
+
+// SolutionCache holds snapshots of solved witnesses, keyed by labels chosen by the caller, see
+// WithSolutionCache. It is safe for concurrent use.
+type SolutionCache struct {
+	lock      sync.Mutex
+	snapshots map[string]SolutionSnapshot
+}
+
+// SolutionSnapshot is a solved witness stored in a SolutionCache
+type SolutionSnapshot struct {
+	Digest []byte      // digest of the solved constraint system
+	Values interface{} // solver specific: the wire values and the a, b, c vectors of the R1CS solver
+}
+
+// NewSolutionCache returns an empty SolutionCache
+func NewSolutionCache() *SolutionCache {
+	return &SolutionCache{snapshots: make(map[string]SolutionSnapshot)}
+}
+
+// Load returns the snapshot stored under label, if any
+func (c *SolutionCache) Load(label string) (SolutionSnapshot, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	s, ok := c.snapshots[label]
+	return s, ok
+}
+
+// Store sets the snapshot stored under label. The solver doesn't modify a snapshot once stored.
+func (c *SolutionCache) Store(label string, s SolutionSnapshot) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.snapshots[label] = s
+}
+
+// Delete removes the snapshot stored under label
+func (c *SolutionCache) Delete(label string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.snapshots, label)
+}
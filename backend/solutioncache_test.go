@@ -0,0 +1,226 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	"github.com/consensys/gnark/internal/backend/circuits"
+	"github.com/stretchr/testify/require"
+)
+
+// newSolveVectors returns n a, b, c vectors for r1cs
+func newSolveVectors(r1cs *cs_bn254.R1CS, n int) (a, b, c [][]fr.Element) {
+	a, b, c = make([][]fr.Element, n), make([][]fr.Element, n), make([][]fr.Element, n)
+	for i := 0; i < n; i++ {
+		a[i] = make([]fr.Element, r1cs.GetNbConstraints())
+		b[i] = make([]fr.Element, r1cs.GetNbConstraints())
+		c[i] = make([]fr.Element, r1cs.GetNbConstraints())
+	}
+	return
+}
+
+// TestSolutionCache checks that solving with a solution cache computes the same wire values and
+// a, b, c vectors as Solve, for the circuits of the registry, starting from the snapshot of each
+// valid witness
+func TestSolutionCache(t *testing.T) {
+	assert := require.New(t)
+
+	keys := make([]string, 0, len(circuits.Circuits))
+	for k := range circuits.Circuits {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		tData := circuits.Circuits[k]
+		if tData.ExpectedCompileError != "" {
+			continue
+		}
+		ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, tData.Circuit)
+		assert.NoError(err, k)
+		r1cs := ccs.(*cs_bn254.R1CS)
+
+		witnesses := make([][]fr.Element, 0, len(tData.ValidWitnesses))
+		for _, w := range tData.ValidWitnesses {
+			var witness witness_bn254.Witness
+			assert.NoError(witness.FromFullAssignment(w), k)
+			witnesses = append(witnesses, witness)
+		}
+
+		for _, packBooleans := range []bool{false, true} {
+			opt, err := backend.NewProverOption(backend.WithHints(tData.HintFunctions...))
+			assert.NoError(err)
+			opt.PackBooleans = packBooleans
+
+			for _, first := range witnesses {
+				cache := backend.NewSolutionCache()
+				cachedOpt := opt
+				assert.NoError(backend.WithSolutionCache(cache, k)(&cachedOpt))
+
+				a, b, c := newSolveVectors(r1cs, 1)
+				_, err := r1cs.Solve(first, a[0], b[0], c[0], cachedOpt)
+				assert.NoError(err, k)
+
+				for i, w := range witnesses {
+					a, b, c := newSolveVectors(r1cs, 1)
+					values, err := r1cs.Solve(w, a[0], b[0], c[0], cachedOpt)
+					assert.NoError(err, k)
+
+					ea, eb, ec := newSolveVectors(r1cs, 1)
+					expected, err := r1cs.Solve(w, ea[0], eb[0], ec[0], opt)
+					assert.NoError(err, k)
+					assert.Equal(expected, values, "%s: wire values of witness %d", k, i)
+					assert.Equal(ea[0], a[0], "%s: a of witness %d", k, i)
+					assert.Equal(eb[0], b[0], "%s: b of witness %d", k, i)
+					assert.Equal(ec[0], c[0], "%s: c of witness %d", k, i)
+				}
+
+				for _, w := range tData.InvalidWitnesses {
+					var witness witness_bn254.Witness
+					assert.NoError(witness.FromFullAssignment(w), k)
+					a, b, c := newSolveVectors(r1cs, 1)
+					_, err := r1cs.Solve(witness, a[0], b[0], c[0], cachedOpt)
+					assert.Error(err, k)
+				}
+			}
+		}
+	}
+}
+
+// TestSolutionCacheProve proves a sliding window of inputs with a solution cache. The window is a
+// ring buffer, such that a single input changes between two proofs.
+func TestSolutionCacheProve(t *testing.T) {
+	assert := require.New(t)
+
+	const nbX = 20
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, newBitsCircuit(nbX))
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	cache := backend.NewSolutionCache()
+	window := make([]uint64, nbX)
+	for i := 0; i < nbX; i++ {
+		window[i] = rand.Uint64() //#nosec G404 weak rng is fine here
+	}
+	for i := 0; i < 4; i++ {
+		// slide the window by one input
+		window[i%nbX] = rand.Uint64() //#nosec G404 weak rng is fine here
+		witness := newBitsCircuit(nbX)
+		for j := 0; j < nbX; j++ {
+			witness.X[j].Assign(window[j])
+		}
+
+		proof, err := groth16.Prove(ccs, pk, witness, backend.WithSolutionCache(cache, "window"))
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, witness))
+	}
+}
+
+// TestSolutionCacheMismatch checks that a snapshot stored by the solver of another circuit is rejected
+func TestSolutionCacheMismatch(t *testing.T) {
+	assert := require.New(t)
+
+	ccs1, err := frontend.Compile(ecc.BN254, backend.GROTH16, newBitsCircuit(2))
+	assert.NoError(err)
+	ccs2, err := frontend.Compile(ecc.BN254, backend.GROTH16, newBitsCircuit(3))
+	assert.NoError(err)
+
+	cache := backend.NewSolutionCache()
+	opt, err := backend.NewProverOption(backend.WithSolutionCache(cache, "label"))
+	assert.NoError(err)
+
+	solve := func(ccs frontend.CompiledConstraintSystem, nbX int) error {
+		r1cs := ccs.(*cs_bn254.R1CS)
+		var witness witness_bn254.Witness
+		assert.NoError(witness.FromFullAssignment(newBitsWitness(nbX)))
+		a, b, c := newSolveVectors(r1cs, 1)
+		_, err := r1cs.Solve(witness, a[0], b[0], c[0], opt)
+		return err
+	}
+
+	assert.NoError(solve(ccs1, 2))
+	assert.ErrorIs(solve(ccs2, 3), backend.ErrSolutionCacheMismatch)
+
+	// the snapshot is kept
+	assert.NoError(solve(ccs1, 2))
+
+	assert.Error(backend.WithSolutionCache(nil, "label")(&opt))
+}
+
+// BenchmarkSolutionCache compares the solving time of a circuit of 200k constraints without and
+// with a solution cache, when 10% of the inputs change between two witnesses.
+func BenchmarkSolutionCache(b *testing.B) {
+	const nbX = 200_000 / 65 // ToBinary(x, 64) is 65 constraints
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, newBitsCircuit(nbX))
+	if err != nil {
+		b.Fatal(err)
+	}
+	r1cs := ccs.(*cs_bn254.R1CS)
+
+	// the solves alternate between two witnesses which differ in 10% of the inputs
+	var w witness_bn254.Witness
+	if err := w.FromFullAssignment(newBitsWitness(nbX)); err != nil {
+		b.Fatal(err)
+	}
+	witnesses := [2][]fr.Element{w, append([]fr.Element(nil), w...)}
+	for _, j := range rand.Perm(nbX)[:nbX/10] {
+		witnesses[1][j].SetUint64(rand.Uint64()) //#nosec G404 weak rng is fine here
+	}
+
+	va, vb, vc := newSolveVectors(r1cs, 1)
+
+	b.Run("solve", func(b *testing.B) {
+		opt, err := backend.NewProverOption()
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := r1cs.Solve(witnesses[i%2], va[0], vb[0], vc[0], opt); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("solution-cache", func(b *testing.B) {
+		opt, err := backend.NewProverOption(backend.WithSolutionCache(backend.NewSolutionCache(), "bench"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		// cold solve: computes the dependency graph and stores the first snapshot
+		if _, err := r1cs.Solve(witnesses[0], va[0], vb[0], vc[0], opt); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := r1cs.Solve(witnesses[(i+1)%2], va[0], vb[0], vc[0], opt); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
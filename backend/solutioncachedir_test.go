@@ -0,0 +1,156 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend_test
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// slowHintDelay is the time slowHint takes, the solve of slowCircuit takes at least as long
+const slowHintDelay = 200 * time.Millisecond
+
+var (
+	nbSlowCalls int32 // counts the calls to slowHint
+
+	slowHint = hint.NewFixedHintNamed("slowHint", func(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+		atomic.AddInt32(&nbSlowCalls, 1)
+		time.Sleep(slowHintDelay)
+		outputs[0].Set(inputs[0])
+		return nil
+	}, 1, 1)
+)
+
+// slowCircuit checks that Y == X**3 + X + 5, X being copied by slowHint
+type slowCircuit struct {
+	X frontend.Variable `gnark:",secret"`
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *slowCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	x := api.NewAnnotatedHint(slowHint, circuit.X)[0]
+	api.AssertIsEqual(x, circuit.X)
+	api.AssertIsEqual(circuit.Y, api.Add(api.Mul(x, x, x), x, 5))
+	return nil
+}
+
+// TestSolutionCacheDir proves a witness twice with a solution cache directory, and checks that the second
+// proof loads the solution instead of solving the witness, for both backends. Another witness misses the
+// cache, and a stale entry is removed and solved again.
+func TestSolutionCacheDir(t *testing.T) {
+	assert := require.New(t)
+
+	for _, b := range backend.Implemented() {
+		dir := t.TempDir()
+		ccs, err := frontend.Compile(ecc.BN254, b, &slowCircuit{})
+		assert.NoError(err)
+
+		var prove func(witness frontend.Circuit) error
+		opts := []func(*backend.ProverOption) error{
+			backend.WithSolutionCacheDir(dir),
+			backend.WithSolutionCacheChecks(-1),
+			backend.WithAnnotatedHints(slowHint),
+		}
+		switch b {
+		case backend.GROTH16:
+			pk, vk, err := groth16.Setup(ccs)
+			assert.NoError(err)
+			prove = func(witness frontend.Circuit) error {
+				proof, err := groth16.Prove(ccs, pk, witness, opts...)
+				if err != nil {
+					return err
+				}
+				return groth16.Verify(proof, vk, witness)
+			}
+		case backend.PLONK:
+			srs, err := test.NewKZGSRS(ccs)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(ccs, srs)
+			assert.NoError(err)
+			prove = func(witness frontend.Circuit) error {
+				proof, err := plonk.Prove(ccs, pk, witness, opts...)
+				if err != nil {
+					return err
+				}
+				return plonk.Verify(proof, vk, witness)
+			}
+		}
+		entries := func() []string {
+			files, err := filepath.Glob(filepath.Join(dir, "*.solution"))
+			assert.NoError(err)
+			return files
+		}
+		timedProve := func(witness frontend.Circuit) time.Duration {
+			start := time.Now()
+			assert.NoError(prove(witness), b)
+			return time.Since(start)
+		}
+
+		w1 := &slowCircuit{X: frontend.Value(3), Y: frontend.Value(35)}
+		w2 := &slowCircuit{X: frontend.Value(2), Y: frontend.Value(15)}
+
+		// the first proof solves w1 and stores its solution
+		atomic.StoreInt32(&nbSlowCalls, 0)
+		first := timedProve(w1)
+		assert.Len(entries(), 1, b)
+		assert.Equal(int32(1), atomic.LoadInt32(&nbSlowCalls), b)
+
+		// the second loads it
+		second := timedProve(w1)
+		assert.Equal(int32(1), atomic.LoadInt32(&nbSlowCalls), b)
+		assert.Less(int64(second), int64(first), b)
+		assert.Less(int64(second), int64(slowHintDelay), b)
+
+		// another witness misses the cache
+		assert.NoError(prove(w2), b)
+		assert.Equal(int32(2), atomic.LoadInt32(&nbSlowCalls), b)
+		assert.Len(entries(), 2, b)
+
+		// a stale entry of w1, well-formed but with another value of the last wire, fails the checks: it is
+		// removed, and w1 solved again
+		for _, path := range entries() {
+			data, err := ioutil.ReadFile(path)
+			assert.NoError(err)
+			data = data[:len(data)-sha256.Size]
+			const header = len("gnarkckp") + 2 + sha256.Size
+			nbWires := int(binary.BigEndian.Uint64(data[header:]))
+			last := header + 3*8 + (nbWires+7)/8 + (nbWires-1)*32
+			data[last] ^= 1
+			digest := sha256.Sum256(data)
+			assert.NoError(ioutil.WriteFile(path, append(data, digest[:]...), 0600))
+		}
+		assert.NoError(prove(w1), b)
+		assert.Equal(int32(3), atomic.LoadInt32(&nbSlowCalls), b)
+		assert.NoError(prove(w1), b)
+		assert.Equal(int32(3), atomic.LoadInt32(&nbSlowCalls), b)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package witness
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/common"
+	"github.com/consensys/gnark/internal/backend/compiled"
+)
+
+// witnessCBOR is the CBOR encoding of a public witness, see WriteCBORTo
+type witnessCBOR struct {
+	common.CBORHeader
+	Digest []byte            `cbor:"digest"`
+	Public map[string][]byte `cbor:"public"`
+}
+
+// WriteCBORTo encodes the public inputs of publicWitness, reduced modulo the scalar field of curveID,
+// and writes them on w in canonical CBOR: a map with the header
+//
+//	"type": "gnark/witness/public", "version": 1, "curve": curveID.String(), "digest": circuitDigest
+//
+// and under "public", a map from the name of each public input (as in WriteSequence) to its value,
+// a big-endian byte string of the size of the modulus. circuitDigest identifies the circuit (for
+// example a hash of its compiled constraint system), it may be empty.
+func WriteCBORTo(w io.Writer, curveID ecc.ID, publicWitness frontend.Circuit, circuitDigest []byte) (int64, error) {
+	schema, err := frontend.ParseSchema(publicWitness)
+	if err != nil {
+		return 0, err
+	}
+
+	// the values are the ones of the binary protocol, in the order of the schema
+	var buf bytes.Buffer
+	if _, err := WritePublicTo(&buf, curveID, publicWitness); err != nil {
+		return 0, err
+	}
+	elementSize := getElementSize(curveID)
+	values := buf.Bytes()[4:]
+
+	res := witnessCBOR{
+		CBORHeader: common.NewCBORHeader(common.CBORPublicWitness, curveID),
+		Digest:     circuitDigest,
+		Public:     make(map[string][]byte, schema.NbPublic),
+	}
+	if res.Digest == nil {
+		res.Digest = []byte{}
+	}
+	for _, f := range schema.Fields {
+		if f.Visibility == compiled.Public {
+			res.Public[f.Name] = values[:elementSize]
+			values = values[elementSize:]
+		}
+	}
+
+	data, err := common.CBOREncMode.Marshal(res)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// ReadCBORFrom reads a public witness written with WriteCBORTo, and assigns the public inputs of
+// publicWitness. It fails with backend.ErrCBORHeaderMismatch if the witness is not a public witness
+// over curveID, or if its circuit digest is not circuitDigest. The values must be reduced, and
+// publicWitness must have the same public inputs.
+func ReadCBORFrom(r io.Reader, curveID ecc.ID, publicWitness frontend.Circuit, circuitDigest []byte) (int64, error) {
+	schema, err := frontend.ParseSchema(publicWitness)
+	if err != nil {
+		return 0, err
+	}
+
+	dec := common.CBORDecMode.NewDecoder(r)
+	var wc witnessCBOR
+	if err := dec.Decode(&wc); err != nil {
+		return int64(dec.NumBytesRead()), err
+	}
+	read := int64(dec.NumBytesRead())
+	if err := wc.Check(common.CBORPublicWitness, curveID); err != nil {
+		return read, err
+	}
+	if !bytes.Equal(wc.Digest, circuitDigest) {
+		return read, fmt.Errorf("%w: circuit digest %x, expected %x", backend.ErrCBORHeaderMismatch, wc.Digest, circuitDigest)
+	}
+
+	elementSize := getElementSize(curveID)
+	modulus := curveID.Info().Fr.Modulus()
+	known := make(map[string]struct{}, schema.NbPublic)
+	reader := func(f *frontend.Field, v *frontend.Variable) error {
+		if f.Visibility != compiled.Public {
+			return nil
+		}
+		known[f.Name] = struct{}{}
+		b, ok := wc.Public[f.Name]
+		if !ok {
+			return fmt.Errorf("when parsing variable %s: missing assignment", f.Name)
+		}
+		if len(b) != elementSize {
+			return fmt.Errorf("when parsing variable %s: invalid value size %d, expected %d", f.Name, len(b), elementSize)
+		}
+		value := new(big.Int).SetBytes(b)
+		if value.Cmp(modulus) >= 0 {
+			return fmt.Errorf("when parsing variable %s: value is not reduced", f.Name)
+		}
+		v.Assign(value)
+		return nil
+	}
+	if err := schema.Visit(publicWitness, reader); err != nil {
+		return read, err
+	}
+
+	if len(known) != len(wc.Public) {
+		var unknown []string
+		for name := range wc.Public {
+			if _, ok := known[name]; !ok {
+				unknown = append(unknown, name)
+			}
+		}
+		sort.Strings(unknown)
+		return read, errors.New("unknown public variables: " + strings.Join(unknown, ", "))
+	}
+	return read, nil
+}
@@ -0,0 +1,116 @@
+package witness
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/common"
+	"github.com/consensys/gnark/internal/curves"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the CBOR encodings")
+
+type cborCircuit struct {
+	X      frontend.Variable `gnark:",public"`
+	Nested struct {
+		Y frontend.Variable
+		Z frontend.Variable
+	} `gnark:",public"`
+	W frontend.Variable `gnark:"w,public"`
+}
+
+func (circuit *cborCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	return nil
+}
+
+func cborAssignment() *cborCircuit {
+	var w cborCircuit
+	w.X.Assign(42)
+	w.Nested.Y.Assign(-1)
+	w.Nested.Z.Assign(3)
+	w.W.Assign(new(big.Int).Lsh(big.NewInt(1), 100))
+	return &w
+}
+
+func TestCBORGolden(t *testing.T) {
+	digest := []byte("circuit digest")
+	for _, curveID := range curves.Implemented() {
+		t.Run(curveID.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			var buf bytes.Buffer
+			written, err := WriteCBORTo(&buf, curveID, cborAssignment(), digest)
+			assert.NoError(err)
+			assert.Equal(int64(buf.Len()), written)
+
+			golden := filepath.Join("testdata", "public_"+curveID.String()+".cbor")
+			if *update {
+				assert.NoError(ioutil.WriteFile(golden, buf.Bytes(), 0644))
+			}
+			expected, err := ioutil.ReadFile(golden)
+			assert.NoError(err)
+			assert.Equal(expected, buf.Bytes(), "the encoding changed, run the tests with -update if it is intended")
+
+			// round trip, the values are reduced
+			var decoded cborCircuit
+			read, err := ReadCBORFrom(bytes.NewReader(expected), curveID, &decoded, digest)
+			assert.NoError(err)
+			assert.Equal(written, read)
+			var reencoded bytes.Buffer
+			_, err = WriteCBORTo(&reencoded, curveID, &decoded, digest)
+			assert.NoError(err)
+			assert.Equal(expected, reencoded.Bytes())
+
+			var minusOne big.Int
+			minusOne.Sub(curveID.Info().Fr.Modulus(), big.NewInt(1))
+			var binary bytes.Buffer
+			_, err = WritePublicTo(&binary, curveID, &decoded)
+			assert.NoError(err)
+			elementSize := getElementSize(curveID)
+			assert.Equal(minusOne.Bytes(), binary.Bytes()[4+elementSize:4+2*elementSize])
+
+			// the header must match
+			_, err = ReadCBORFrom(bytes.NewReader(expected), curveID, &decoded, []byte("other digest"))
+			assert.True(errors.Is(err, backend.ErrCBORHeaderMismatch), "%v", err)
+			other := ecc.BN254
+			if curveID == ecc.BN254 {
+				other = ecc.BLS12_381
+			}
+			_, err = ReadCBORFrom(bytes.NewReader(expected), other, &decoded, digest)
+			assert.True(errors.Is(err, backend.ErrCBORHeaderMismatch), "%v", err)
+		})
+	}
+}
+
+func TestCBORInvalid(t *testing.T) {
+	assert := require.New(t)
+
+	var buf bytes.Buffer
+	_, err := WriteCBORTo(&buf, ecc.BN254, cborAssignment(), nil)
+	assert.NoError(err)
+
+	// the public inputs must be the same
+	var w circuit
+	_, err = ReadCBORFrom(bytes.NewReader(buf.Bytes()), ecc.BN254, &w, nil)
+	assert.Error(err)
+
+	// the values must be reduced
+	wc := witnessCBOR{}
+	assert.NoError(common.CBORDecMode.Unmarshal(buf.Bytes(), &wc))
+	wc.Public["X"] = bytes.Repeat([]byte{0xff}, 32)
+	data, err := common.CBOREncMode.Marshal(wc)
+	assert.NoError(err)
+	var decoded cborCircuit
+	_, err = ReadCBORFrom(bytes.NewReader(data), ecc.BN254, &decoded, nil)
+	assert.Error(err)
+	assert.Contains(err.Error(), "not reduced")
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package witness
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+// Commit returns the commitment to the secret witness of assignment: the hash by h of the secret
+// values, in the order of the secret witness vector (see frontend.SecretInputs), each reduced modulo
+// the scalar field of curveID and encoded as a big-endian byte array of the size of the field modulus.
+//
+// With h a MiMC of gnark-crypto (for example hash.MIMC_BN254.New(seed)), the commitment is the one
+// std/witnesscommit.Verify recomputes in a circuit with the MiMC of std/hash/mimc and the same seed:
+// the field elements are hashed as such, without padding. Missing optional secret inputs are 0.
+func Commit(curveID ecc.ID, assignment frontend.Circuit, h hash.Hash) ([]byte, error) {
+	schema, err := frontend.ParseSchema(assignment)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := schema.SecretInputs(assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	// the optional inputs are the only ones a witness may omit
+	optional := make(map[int]bool)
+	i := 0
+	for _, f := range schema.Fields {
+		if f.Visibility == frontend.Secret {
+			optional[i] = f.Optional
+			i++
+		}
+	}
+
+	modulus := curveID.Info().Fr.Modulus()
+	buf := make([]byte, getElementSize(curveID))
+	h.Reset()
+	for i, v := range secret {
+		for j := range buf {
+			buf[j] = 0
+		}
+		if v.WitnessValue != nil {
+			value := frontend.FromInterface(v.WitnessValue)
+			value.Mod(&value, modulus)
+			value.FillBytes(buf)
+		} else if !optional[i] {
+			return nil, fmt.Errorf("secret input %d: missing assignment", i)
+		}
+		if _, err := h.Write(buf); err != nil {
+			return nil, err
+		}
+	}
+	return h.Sum(nil), nil
+}
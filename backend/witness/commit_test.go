@@ -0,0 +1,59 @@
+package witness
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+type commitCircuit struct {
+	A frontend.Variable
+	P frontend.Variable `gnark:",public"`
+	B struct {
+		C frontend.Variable
+		D frontend.Variable `gnark:",optional"`
+	}
+}
+
+func (circuit *commitCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Add(circuit.A, circuit.B.C, circuit.B.D), circuit.P)
+	return nil
+}
+
+func TestCommit(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &commitCircuit{})
+	assert.NoError(err)
+
+	var w commitCircuit
+	w.A.Assign(-1)
+	w.B.C.Assign(3)
+	w.P.Assign(2)
+
+	// the secret values of the witness vector, reduced, in order, each one a block of MiMC
+	_, secret, err := ToVector(&w, ccs)
+	assert.NoError(err)
+	assert.Len(secret, 3)
+	h := hash.MIMC_BN254.New("seed")
+	for _, v := range secret {
+		buf := make([]byte, getElementSize(ecc.BN254))
+		v.FillBytes(buf)
+		_, err := h.Write(buf)
+		assert.NoError(err)
+	}
+	expected := h.Sum(nil)
+
+	commitment, err := Commit(ecc.BN254, &w, hash.MIMC_BN254.New("seed"))
+	assert.NoError(err)
+	assert.Equal(expected, commitment)
+
+	// the missing inputs must be optional
+	w.B.C.WitnessValue = nil
+	_, err = Commit(ecc.BN254, &w, hash.MIMC_BN254.New("seed"))
+	assert.Error(err)
+}
@@ -0,0 +1,270 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package encrypted encrypts full witnesses to store them at rest.
+//
+// The witness is serialized with witness.WriteFullTo and encrypted with AES-256-GCM,
+// with a key supplied by the caller.
+//
+// Binary protocol
+//
+//	[magic | version | uint16(curveID) | uint16(len(digest)) | digest | nonce | keyCheck | uint32(len(ciphertext)) | ciphertext]
+//
+// where
+//   - magic is "gnkw", version is a byte, 1 for this protocol
+//   - digest is the circuit digest given with WithCircuitDigest, possibly empty
+//   - nonce is a random 12 bytes GCM nonce
+//   - keyCheck is the first 16 bytes of HMAC-SHA256(key, nonce), to tell a wrong key from a corrupted ciphertext
+//   - ciphertext is the sealed witness (binary protocol of package witness) followed by the GCM tag
+//
+// Everything before the ciphertext is the header; it is authenticated as the GCM additional data.
+package encrypted
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	gnarkio "github.com/consensys/gnark/io"
+)
+
+// KeySize is the size of the keys, in bytes (AES-256)
+const KeySize = 32
+
+const (
+	magic         = "gnkw"
+	version       = 1
+	nonceSize     = 12
+	keyCheckSize  = 16
+	maxDigestSize = math.MaxUint16
+)
+
+var (
+	// ErrInvalidKey is returned if the key is not KeySize bytes long
+	ErrInvalidKey = errors.New("invalid key size")
+
+	// ErrInvalidFormat is returned if the stream is not an encrypted witness of a supported version
+	ErrInvalidFormat = errors.New("invalid encrypted witness format")
+
+	// ErrTruncated is returned if the stream ends before the end of the encrypted witness
+	ErrTruncated = errors.New("truncated encrypted witness")
+
+	// ErrWrongKey is returned if the witness was encrypted with another key
+	ErrWrongKey = errors.New("wrong key")
+
+	// ErrCircuitDigestMismatch is returned if the circuit digest of the header is not the one
+	// given with WithCircuitDigest
+	ErrCircuitDigestMismatch = errors.New("circuit digest mismatch")
+
+	// ErrAuthentication is returned if the header or the ciphertext was modified
+	ErrAuthentication = errors.New("encrypted witness authentication failed")
+)
+
+// Option holds the options of WriteEncryptedTo and ReadEncryptedFrom
+type Option struct {
+	CircuitDigest []byte
+}
+
+// WithCircuitDigest sets the digest of the circuit the witness is an assignment of (for example
+// a hash of its compiled constraint system). WriteEncryptedTo records it in the authenticated
+// header, ReadEncryptedFrom fails with ErrCircuitDigestMismatch if the header holds another digest.
+func WithCircuitDigest(digest []byte) func(opt *Option) error {
+	return func(opt *Option) error {
+		if len(digest) > maxDigestSize {
+			return fmt.Errorf("circuit digest is too long: %d bytes", len(digest))
+		}
+		opt.CircuitDigest = digest
+		return nil
+	}
+}
+
+func newOption(opts ...func(opt *Option) error) (Option, error) {
+	var opt Option
+	for _, option := range opts {
+		if err := option(&opt); err != nil {
+			return Option{}, err
+		}
+	}
+	return opt, nil
+}
+
+// WriteEncryptedTo encrypts the full witness with key (KeySize bytes) and writes it on w
+func WriteEncryptedTo(w io.Writer, curveID ecc.ID, assignment frontend.Circuit, key []byte, opts ...func(opt *Option) error) (int64, error) {
+	opt, err := newOption(opts...)
+	if err != nil {
+		return 0, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return 0, err
+	}
+
+	var plaintext bytes.Buffer
+	if _, err := witness.WriteFullTo(&plaintext, curveID, assignment); err != nil {
+		return 0, err
+	}
+
+	nonce := make([]byte, nonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return 0, err
+	}
+
+	var header bytes.Buffer
+	header.WriteString(magic)
+	header.WriteByte(version)
+	_ = binary.Write(&header, binary.BigEndian, uint16(curveID))
+	_ = binary.Write(&header, binary.BigEndian, uint16(len(opt.CircuitDigest)))
+	header.Write(opt.CircuitDigest)
+	header.Write(nonce)
+	header.Write(keyCheck(key, nonce))
+	_ = binary.Write(&header, binary.BigEndian, uint32(plaintext.Len()+aead.Overhead()))
+
+	ciphertext := aead.Seal(nil, nonce, plaintext.Bytes(), header.Bytes())
+
+	n, err := w.Write(header.Bytes())
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := w.Write(ciphertext)
+	return int64(n + m), err
+}
+
+// ReadEncryptedFrom reads an encrypted witness from r, decrypts it with key and sets the values of
+// assignment. The curve is the one given to WriteEncryptedTo, recorded in the header.
+//
+// It fails with ErrWrongKey, ErrCircuitDigestMismatch or ErrTruncated if the key, the circuit digest
+// or the length of the stream doesn't match, and with ErrAuthentication if the data was modified.
+func ReadEncryptedFrom(r io.Reader, assignment frontend.Circuit, key []byte, opts ...func(opt *Option) error) (int64, error) {
+	opt, err := newOption(opts...)
+	if err != nil {
+		return 0, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return 0, err
+	}
+	schema, err := frontend.ParseSchema(assignment)
+	if err != nil {
+		return 0, err
+	}
+
+	// the header is recorded as it is read, it is the additional data of the ciphertext
+	var header bytes.Buffer
+	tr := io.TeeReader(r, &header)
+	read := func(buf []byte) error {
+		if _, err := io.ReadFull(tr, buf); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return ErrTruncated
+			}
+			return err
+		}
+		return nil
+	}
+
+	var buf [8]byte
+	if err := read(buf[:len(magic)+1]); err != nil {
+		return int64(header.Len()), err
+	}
+	if string(buf[:len(magic)]) != magic || buf[len(magic)] != version {
+		return int64(header.Len()), ErrInvalidFormat
+	}
+
+	if err := read(buf[:4]); err != nil {
+		return int64(header.Len()), err
+	}
+	curveID := ecc.ID(binary.BigEndian.Uint16(buf[:2]))
+	switch curveID {
+	case ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BLS24_315, ecc.BW6_761, ecc.BW6_633:
+	default:
+		return int64(header.Len()), fmt.Errorf("%w: unknown curve %d", ErrInvalidFormat, curveID)
+	}
+
+	digest := make([]byte, binary.BigEndian.Uint16(buf[2:4]))
+	if err := read(digest); err != nil {
+		return int64(header.Len()), err
+	}
+
+	nonce := make([]byte, nonceSize+keyCheckSize)
+	if err := read(nonce); err != nil {
+		return int64(header.Len()), err
+	}
+	nonce, check := nonce[:nonceSize], nonce[nonceSize:]
+	if !hmac.Equal(check, keyCheck(key, nonce)) {
+		return int64(header.Len()), ErrWrongKey
+	}
+
+	if opt.CircuitDigest != nil && !bytes.Equal(digest, opt.CircuitDigest) {
+		return int64(header.Len()), fmt.Errorf("%w: got %x, expected %x", ErrCircuitDigestMismatch, digest, opt.CircuitDigest)
+	}
+
+	if err := read(buf[:4]); err != nil {
+		return int64(header.Len()), err
+	}
+	// the length is checked before the allocation, see gnarkio.MaxReadBytes
+	size := int64(binary.BigEndian.Uint32(buf[:4]))
+	if size != int64(4+(schema.NbPublic+schema.NbSecret)*curveID.Info().Fr.Bytes+aead.Overhead()) {
+		return int64(header.Len()), fmt.Errorf("%w: the size of the witness doesn't match the circuit", ErrInvalidFormat)
+	}
+	if limit := gnarkio.MaxReadBytes(); size > limit {
+		return int64(header.Len()), &gnarkio.LimitExceededError{What: "encrypted witness", Size: size, Limit: limit}
+	}
+	ciphertext := make([]byte, size)
+	n := header.Len()
+	if _, err := io.ReadFull(r, ciphertext); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			err = ErrTruncated
+		}
+		return int64(n), err
+	}
+	n += len(ciphertext)
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, header.Bytes())
+	if err != nil {
+		return int64(n), ErrAuthentication
+	}
+
+	if _, err := witness.ReadFullFrom(bytes.NewReader(plaintext), curveID, assignment); err != nil {
+		return int64(n), err
+	}
+	return int64(n), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: got %d bytes, expected %d", ErrInvalidKey, len(key), KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// keyCheck returns the first bytes of HMAC-SHA256(key, nonce)
+func keyCheck(key, nonce []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(nonce)
+	return mac.Sum(nil)[:keyCheckSize]
+}
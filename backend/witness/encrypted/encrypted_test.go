@@ -0,0 +1,149 @@
+package encrypted
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/stretchr/testify/require"
+)
+
+type circuit struct {
+	X frontend.Variable `gnark:",public"`
+	Y frontend.Variable
+	Z frontend.Variable
+}
+
+func (circuit *circuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.Y, circuit.Z), circuit.X)
+	return nil
+}
+
+func assignment() *circuit {
+	var w circuit
+	w.X.Assign(big.NewInt(35))
+	w.Y.Assign(big.NewInt(5))
+	w.Z.Assign(big.NewInt(7))
+	return &w
+}
+
+func newKey(t *testing.T) []byte {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func encrypt(t *testing.T, key []byte, opts ...func(opt *Option) error) []byte {
+	var buf bytes.Buffer
+	written, err := WriteEncryptedTo(&buf, ecc.BN254, assignment(), key, opts...)
+	require.NoError(t, err)
+	require.Equal(t, int64(buf.Len()), written)
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	assert := require.New(t)
+	key := newKey(t)
+	digest := []byte("circuit digest")
+
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BLS24_315, ecc.BW6_761, ecc.BW6_633} {
+		var buf bytes.Buffer
+		written, err := WriteEncryptedTo(&buf, curveID, assignment(), key, WithCircuitDigest(digest))
+		assert.NoError(err)
+
+		// the witness is not in clear
+		var plaintext bytes.Buffer
+		_, err = witness.WriteFullTo(&plaintext, curveID, assignment())
+		assert.NoError(err)
+		assert.False(bytes.Contains(buf.Bytes(), plaintext.Bytes()[4:]), curveID.String())
+
+		var w circuit
+		read, err := ReadEncryptedFrom(&buf, &w, key, WithCircuitDigest(digest))
+		assert.NoError(err, curveID.String())
+		assert.Equal(written, read)
+		assert.True(reflect.DeepEqual(assignment(), &w), curveID.String())
+	}
+
+	// the digest is optional when reading
+	var w circuit
+	_, err := ReadEncryptedFrom(bytes.NewReader(encrypt(t, key, WithCircuitDigest(digest))), &w, key)
+	assert.NoError(err)
+
+	// two encryptions differ
+	assert.NotEqual(encrypt(t, key), encrypt(t, key))
+}
+
+func TestDecryptionErrors(t *testing.T) {
+	assert := require.New(t)
+	key := newKey(t)
+	encrypted := encrypt(t, key, WithCircuitDigest([]byte("circuit digest")))
+
+	read := func(data, key []byte, opts ...func(opt *Option) error) error {
+		var w circuit
+		_, err := ReadEncryptedFrom(bytes.NewReader(data), &w, key, opts...)
+		return err
+	}
+
+	// wrong key
+	assert.ErrorIs(read(encrypted, newKey(t)), ErrWrongKey)
+	assert.ErrorIs(read(encrypted, key[:16]), ErrInvalidKey)
+
+	// wrong circuit digest
+	assert.ErrorIs(read(encrypted, key, WithCircuitDigest([]byte("another digest"))), ErrCircuitDigestMismatch)
+	assert.ErrorIs(read(encrypt(t, key), key, WithCircuitDigest([]byte("circuit digest"))), ErrCircuitDigestMismatch)
+
+	// truncation, anywhere
+	for i := 0; i < len(encrypted); i++ {
+		assert.ErrorIs(read(encrypted[:i], key), ErrTruncated, "truncated at %d", i)
+	}
+
+	// tampered ciphertext, and tampered circuit digest of the header
+	for _, i := range []int{len(encrypted) - 1, len(encrypted) - 20, len(magic) + 5} {
+		tampered := append([]byte{}, encrypted...)
+		tampered[i] ^= 1
+		assert.ErrorIs(read(tampered, key), ErrAuthentication, "tampered byte %d", i)
+	}
+
+	// not an encrypted witness
+	assert.ErrorIs(read([]byte("not a witness"), key), ErrInvalidFormat)
+
+	// a ciphertext length which doesn't match the circuit fails before the allocation
+	offset := len(magic) + 1 + 4 + len("circuit digest") + nonceSize + keyCheckSize
+	tampered := append([]byte{}, encrypted...)
+	binary.BigEndian.PutUint32(tampered[offset:], math.MaxUint32)
+	assert.ErrorIs(read(tampered, key), ErrInvalidFormat)
+
+	// and the one of the circuit is bounded by MaxReadBytes
+	defer gnarkio.SetMaxReadBytes(gnarkio.SetMaxReadBytes(int64(len(encrypted) - offset - 5)))
+	assert.ErrorIs(read(encrypted, key), gnarkio.ErrLimitExceeded)
+}
+
+func TestProveFromDecryptedWitness(t *testing.T) {
+	assert := require.New(t)
+	key := newKey(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	var w circuit
+	_, err = ReadEncryptedFrom(bytes.NewReader(encrypt(t, key)), &w, key)
+	assert.NoError(err)
+
+	proof, err := groth16.Prove(ccs, pk, &w)
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, &circuit{X: w.X}))
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package witness
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+// FrVector is a witness of field elements of the scalar field of a curve: the values of the public and
+// secret inputs, in the order of the compiled constraint system (see WriteSequence).
+//
+// It is implemented by the Vector of the per-curve witness packages (see FromFrSlice), which Prove,
+// IsSolved and Verify use without converting the values.
+type FrVector interface {
+	frontend.Circuit
+
+	// CurveID returns the curve of the scalar field of the values
+	CurveID() ecc.ID
+
+	// NbInputs returns the number of public and secret values
+	NbInputs() (nbPublic, nbSecret int)
+}
+
+// CheckFrVector returns an error if assignment is a FrVector of another curve than ccs, or with other
+// numbers of public and secret inputs. Other assignments are not checked.
+func CheckFrVector(assignment frontend.Circuit, ccs frontend.CompiledConstraintSystem) error {
+	v, ok := assignment.(FrVector)
+	if !ok {
+		return nil
+	}
+	if v.CurveID() != ccs.CurveID() {
+		return fmt.Errorf("witness vector of %s, constraint system of %s", v.CurveID(), ccs.CurveID())
+	}
+	nbPublic, nbSecret := nbInputs(ccs)
+	if public, secret := v.NbInputs(); public != nbPublic || secret != nbSecret {
+		return fmt.Errorf("witness vector has %d public and %d secret values, constraint system expects %d and %d",
+			public, secret, nbPublic, nbSecret)
+	}
+	return nil
+}
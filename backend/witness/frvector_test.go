@@ -0,0 +1,97 @@
+package witness_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	fr_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// vectorFrSlices returns the values of vectorAssignment as field elements
+func vectorFrSlices() (public, secret []fr.Element) {
+	public = []fr.Element{fr.NewElement(40)}
+	secret = []fr.Element{fr.NewElement(2), fr.NewElement(3), fr.NewElement(4), fr.NewElement(5), fr.NewElement(6)}
+	return
+}
+
+func TestFrVectorProof(t *testing.T) {
+	assert := require.New(t)
+
+	public, secret := vectorFrSlices()
+	full := witness_bn254.FromFrSlice(public, secret)
+	publicOnly := witness_bn254.FromFrSlice(public, nil)
+
+	// the proofs of the vector and of the circuit structure are the same
+	for _, zkpID := range []backend.ID{backend.GROTH16, backend.PLONK} {
+		ccs, err := frontend.Compile(ecc.BN254, zkpID, &vectorCircuit{})
+		assert.NoError(err)
+
+		switch zkpID {
+		case backend.GROTH16:
+			pk, vk, err := groth16.Setup(ccs)
+			assert.NoError(err)
+			assert.NoError(groth16.IsSolved(ccs, full))
+			proof, err := groth16.Prove(ccs, pk, full, backend.WithRandomness(rand.New(rand.NewSource(1))))
+			assert.NoError(err)
+			expected, err := groth16.Prove(ccs, pk, vectorAssignment(), backend.WithRandomness(rand.New(rand.NewSource(1))))
+			assert.NoError(err)
+			assert.Equal(expected, proof)
+			assert.NoError(groth16.Verify(proof, vk, publicOnly))
+		case backend.PLONK:
+			srs, err := test.NewKZGSRS(ccs)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(ccs, srs)
+			assert.NoError(err)
+			assert.NoError(plonk.IsSolved(ccs, full))
+			proof, err := plonk.Prove(ccs, pk, full, backend.WithRandomness(rand.New(rand.NewSource(1))))
+			assert.NoError(err)
+			expected, err := plonk.Prove(ccs, pk, vectorAssignment(), backend.WithRandomness(rand.New(rand.NewSource(1))))
+			assert.NoError(err)
+			var got, want bytes.Buffer
+			_, err = proof.WriteTo(&got)
+			assert.NoError(err)
+			_, err = expected.WriteTo(&want)
+			assert.NoError(err)
+			assert.Equal(want.Bytes(), got.Bytes())
+			assert.NoError(plonk.Verify(proof, vk, publicOnly))
+		}
+	}
+}
+
+func TestFrVectorMismatch(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &vectorCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	// the values are in the scalar field of another curve
+	other := witness_bls12381.FromFrSlice([]fr_bls12381.Element{fr_bls12381.NewElement(40)},
+		make([]fr_bls12381.Element, 5))
+	_, err = groth16.Prove(ccs, pk, other)
+	assert.EqualError(err, "witness vector of bls12_381, constraint system of bn254")
+	assert.Error(groth16.IsSolved(ccs, other))
+
+	proof, err := groth16.Prove(ccs, pk, vectorAssignment())
+	assert.NoError(err)
+	err = groth16.Verify(proof, vk, other)
+	assert.EqualError(err, "witness vector of bls12_381, expected bn254")
+
+	// the split of the values doesn't match the constraint system
+	public, secret := vectorFrSlices()
+	_, err = groth16.Prove(ccs, pk, witness_bn254.FromFrSlice(append(public, secret[0]), secret[1:]))
+	assert.EqualError(err, "witness vector has 2 public and 4 secret values, constraint system expects 1 and 5")
+	assert.Error(groth16.IsSolved(ccs, witness_bn254.FromFrSlice(public, nil)))
+}
@@ -0,0 +1,185 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package witness_test
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// oldOptionalCircuit is the first version of optionalCircuit, before Salt was added
+type oldOptionalCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *oldOptionalCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+type optionalCircuit struct {
+	X    frontend.Variable
+	Y    frontend.Variable `gnark:",public"`
+	Salt frontend.Variable `gnark:",optional"`
+}
+
+func (circuit *optionalCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Add(api.Mul(circuit.X, circuit.X), circuit.Salt), circuit.Y)
+	return nil
+}
+
+func TestOptionalSecretInputs(t *testing.T) {
+	assert := require.New(t)
+
+	// witnesses produced for the old circuit
+	oldJSON, err := witness.ToJSON(&oldOptionalCircuit{X: frontend.Value(3), Y: frontend.Value(9)}, ecc.BN254)
+	assert.NoError(err)
+	var oldBinary bytes.Buffer
+	_, err = witness.WriteFullTo(&oldBinary, ecc.BN254, &oldOptionalCircuit{X: frontend.Value(3), Y: frontend.Value(9)})
+	assert.NoError(err)
+
+	var w, publicW optionalCircuit
+	assert.NoError(witness.FromJSON(&w, []byte(oldJSON)))
+	assert.NoError(witness.FromJSON(&publicW, []byte(oldJSON)))
+	assert.Equal(big.NewInt(0), w.Salt.WitnessValue)
+
+	for _, b := range backend.Implemented() {
+		ccs, err := frontend.Compile(ecc.BN254, b, &optionalCircuit{})
+		assert.NoError(err)
+		assert.Equal([]int{1}, ccs.GetOptionalSecrets())
+
+		// the optional inputs are serialized with the constraint system
+		var buf bytes.Buffer
+		_, err = ccs.WriteTo(&buf)
+		assert.NoError(err)
+		var reloaded frontend.CompiledConstraintSystem
+		if b == backend.GROTH16 {
+			reloaded = groth16.NewCS(ecc.BN254)
+		} else {
+			reloaded = plonk.NewCS(ecc.BN254)
+		}
+		_, err = reloaded.ReadFrom(&buf)
+		assert.NoError(err)
+		assert.Equal([]int{1}, reloaded.GetOptionalSecrets())
+
+		var (
+			prove        func(w frontend.Circuit) error
+			readAndProve func(r io.Reader) error
+		)
+		switch b {
+		case backend.GROTH16:
+			pk, vk, err := groth16.Setup(ccs)
+			assert.NoError(err)
+			prove = func(w frontend.Circuit) error {
+				proof, err := groth16.Prove(ccs, pk, w)
+				if err != nil {
+					return err
+				}
+				return groth16.Verify(proof, vk, &publicW)
+			}
+			readAndProve = func(r io.Reader) error {
+				proof, err := groth16.ReadAndProve(ccs, pk, r)
+				if err != nil {
+					return err
+				}
+				return groth16.Verify(proof, vk, &publicW)
+			}
+		case backend.PLONK:
+			srs, err := test.NewKZGSRS(ccs)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(ccs, srs)
+			assert.NoError(err)
+			prove = func(w frontend.Circuit) error {
+				proof, err := plonk.Prove(ccs, pk, w)
+				if err != nil {
+					return err
+				}
+				return plonk.Verify(proof, vk, &publicW)
+			}
+			readAndProve = func(r io.Reader) error {
+				proof, err := plonk.ReadAndProve(ccs, pk, r)
+				if err != nil {
+					return err
+				}
+				return plonk.Verify(proof, vk, &publicW)
+			}
+		}
+
+		// old JSON, old binary, struct and vector witnesses without the optional input
+		assert.NoError(prove(&w), b.String())
+		assert.NoError(readAndProve(bytes.NewReader(oldBinary.Bytes())), b.String())
+		assert.NoError(prove(&optionalCircuit{X: frontend.Value(3), Y: frontend.Value(9)}), b.String())
+		vector, err := witness.FromVector(ccs, []*big.Int{big.NewInt(9)}, []*big.Int{big.NewInt(3)})
+		assert.NoError(err)
+		assert.NoError(prove(vector), b.String())
+
+		// the optional input is used when present
+		assert.NoError(prove(&optionalCircuit{X: frontend.Value(2), Y: frontend.Value(9), Salt: frontend.Value(5)}), b.String())
+		assert.Error(prove(&optionalCircuit{X: frontend.Value(3), Y: frontend.Value(9), Salt: frontend.Value(5)}), b.String())
+
+		// non-optional inputs are still required
+		err = prove(&optionalCircuit{Y: frontend.Value(9)})
+		assert.EqualError(err, "when parsing variable X: missing assignment", b.String())
+		_, err = witness.FromVector(ccs, []*big.Int{big.NewInt(9)}, []*big.Int{})
+		assert.NoError(err, "an empty secret vector is a public witness")
+		_, err = witness.FromVector(ccs, []*big.Int{big.NewInt(9)}, []*big.Int{nil, big.NewInt(1)})
+		assert.EqualError(err, "secret witness: missing assignment at index 0")
+	}
+
+	// JSON witnesses missing a non-optional input, or with unknown inputs
+	var missing optionalCircuit
+	err = witness.FromJSON(&missing, []byte(`{"Public": {"Y": "9"}, "Secret": {"Salt": "1"}}`))
+	assert.EqualError(err, "when parsing variable X: missing assignment")
+	err = witness.FromJSON(&missing, []byte(`{"Public": {"Y": "9"}, "Secret": {"X": "3", "Nonce": "1"}}`))
+	assert.EqualError(err, "unknown public or secret variables: Nonce")
+
+	// the binary witness can omit trailing optional inputs, not the other ones
+	var read optionalCircuit
+	_, err = witness.ReadFullFrom(bytes.NewReader(oldBinary.Bytes()), ecc.BN254, &read)
+	assert.NoError(err)
+	assert.Equal(big.NewInt(0), read.Salt.WitnessValue)
+	var short bytes.Buffer
+	_, err = witness.WritePublicTo(&short, ecc.BN254, &oldOptionalCircuit{Y: frontend.Value(9)})
+	assert.NoError(err)
+	_, err = witness.ReadFullFrom(&short, ecc.BN254, &optionalCircuit{})
+	assert.Error(err)
+
+	// public inputs can't be optional
+	_, err = frontend.Compile(ecc.BN254, backend.GROTH16, &publicOptionalCircuit{})
+	assert.Error(err)
+}
+
+type publicOptionalCircuit struct {
+	X frontend.Variable `gnark:",public,optional"`
+}
+
+func (circuit *publicOptionalCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(circuit.X, 0)
+	return nil
+}
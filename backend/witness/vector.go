@@ -0,0 +1,150 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package witness
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	cs_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
+	cs_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
+	cs_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	cs_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
+	cs_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+	"github.com/consensys/gnark/internal/backend/compiled"
+)
+
+// vector is a witness assignment which holds the public and secret values
+// in the order of the compiled constraint system
+type vector struct {
+	Public []frontend.Variable `gnark:",public"`
+	Secret []frontend.Variable `gnark:",secret"`
+}
+
+// Define is not meant to be called, a vector is only an assignment
+func (v *vector) Define(curveID ecc.ID, api frontend.API) error {
+	return errors.New("a witness vector is not a circuit definition")
+}
+
+// FromVector returns a witness built from the public and secret values given in the order
+// of the compiled constraint system (see WriteSequence), bypassing the circuit structure.
+//
+// The result can be used wherever an assignment is expected, for example in Prove and Verify.
+// public may be the only non-empty input when building a public witness. secret may omit the
+// trailing optional secret inputs (see ccs.GetOptionalSecrets), and have nil optional values: they are
+// then assigned 0.
+// Values are reduced modulo the scalar field of ccs.CurveID()
+func FromVector(ccs frontend.CompiledConstraintSystem, public, secret []*big.Int) (frontend.Circuit, error) {
+	nbPublic, nbSecret := nbInputs(ccs)
+	if len(public) != nbPublic {
+		return nil, fmt.Errorf("invalid public witness size: got %d, expected %d", len(public), nbPublic)
+	}
+	nbOptional := compiled.NbTrailingOptional(ccs.GetOptionalSecrets(), nbSecret)
+	if len(secret) != 0 && (len(secret) > nbSecret || len(secret) < nbSecret-nbOptional) {
+		if nbOptional == 0 {
+			return nil, fmt.Errorf("invalid secret witness size: got %d, expected %d", len(secret), nbSecret)
+		}
+		return nil, fmt.Errorf("invalid secret witness size: got %d, expected %d to %d", len(secret), nbSecret-nbOptional, nbSecret)
+	}
+
+	v := &vector{
+		Public: make([]frontend.Variable, len(public)),
+	}
+	if len(secret) != 0 {
+		v.Secret = make([]frontend.Variable, nbSecret)
+		for i := len(secret); i < nbSecret; i++ {
+			v.Secret[i].Assign(new(big.Int)) // omitted optional input
+		}
+	}
+	for i := 0; i < len(public); i++ {
+		if public[i] == nil {
+			return nil, fmt.Errorf("public witness: missing assignment at index %d", i)
+		}
+		v.Public[i].Assign(new(big.Int).Set(public[i]))
+	}
+	for i := 0; i < len(secret); i++ {
+		if secret[i] == nil {
+			if !isOptional(ccs, i) {
+				return nil, fmt.Errorf("secret witness: missing assignment at index %d", i)
+			}
+			v.Secret[i].Assign(new(big.Int))
+			continue
+		}
+		v.Secret[i].Assign(new(big.Int).Set(secret[i]))
+	}
+	return v, nil
+}
+
+// ToVector extracts the public and secret values of the assignment, in the order of the compiled
+// constraint system (see WriteSequence). It is the inverse of FromVector.
+//
+// Missing optional secret inputs are 0. Values are reduced modulo the scalar field of ccs.CurveID()
+func ToVector(assignment frontend.Circuit, ccs frontend.CompiledConstraintSystem) (public, secret []*big.Int, err error) {
+	modulus := ccs.CurveID().Info().Fr.Modulus()
+
+	schema, err := frontend.ParseSchema(assignment)
+	if err != nil {
+		return nil, nil, err
+	}
+	collectHandler := func(f *frontend.Field, v *frontend.Variable) error {
+		var value big.Int
+		if v.WitnessValue != nil {
+			value = frontend.FromInterface(v.WitnessValue)
+			value.Mod(&value, modulus)
+		} else if !f.Optional {
+			return fmt.Errorf("when parsing variable %s: missing assignment", f.Name)
+		}
+
+		if f.Visibility == compiled.Public {
+			public = append(public, &value)
+		} else if f.Visibility == compiled.Secret {
+			secret = append(secret, &value)
+		}
+		return nil
+	}
+	if err := schema.Visit(assignment, collectHandler); err != nil {
+		return nil, nil, err
+	}
+
+	nbPublic, nbSecret := nbInputs(ccs)
+	if len(public) != nbPublic || len(secret) != nbSecret {
+		return nil, nil, fmt.Errorf("assignment has %d public and %d secret inputs, constraint system expects %d and %d",
+			len(public), len(secret), nbPublic, nbSecret)
+	}
+	return public, secret, nil
+}
+
+// isOptional returns true if the secret input i of ccs is optional
+func isOptional(ccs frontend.CompiledConstraintSystem, i int) bool {
+	optional := ccs.GetOptionalSecrets()
+	j := sort.SearchInts(optional, i)
+	return j < len(optional) && optional[j] == i
+}
+
+// nbInputs returns the number of public and secret inputs of the constraint system,
+// not counting the constant ONE_WIRE allocated in R1CS
+func nbInputs(ccs frontend.CompiledConstraintSystem) (nbPublic, nbSecret int) {
+	_, nbSecret, nbPublic = ccs.GetNbVariables()
+	switch ccs.(type) {
+	case *cs_bn254.R1CS, *cs_bls12377.R1CS, *cs_bls12381.R1CS, *cs_bw6761.R1CS, *cs_bw6633.R1CS, *cs_bls24315.R1CS:
+		nbPublic--
+	}
+	return
+}
@@ -0,0 +1,120 @@
+package witness_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+type vectorPoint struct {
+	X frontend.Variable
+	Y frontend.Variable
+}
+
+type vectorCircuit struct {
+	A      frontend.Variable
+	Points [2]vectorPoint
+	Sum    frontend.Variable `gnark:",public"`
+}
+
+func (circuit *vectorCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	sum := api.Mul(circuit.A, circuit.Points[0].X, circuit.Points[1].X)
+	sum = api.Add(sum, circuit.Points[0].Y, circuit.Points[1].Y)
+	api.AssertIsEqual(sum, circuit.Sum)
+	return nil
+}
+
+func vectorAssignment() *vectorCircuit {
+	var assignment vectorCircuit
+	assignment.A.Assign(2)
+	assignment.Points[0].X.Assign(3)
+	assignment.Points[0].Y.Assign(4)
+	assignment.Points[1].X.Assign(5)
+	assignment.Points[1].Y.Assign(6)
+	assignment.Sum.Assign(40)
+	return &assignment
+}
+
+func TestVectorRoundTrip(t *testing.T) {
+	assert := require.New(t)
+
+	for _, zkpID := range []backend.ID{backend.GROTH16, backend.PLONK} {
+		ccs, err := frontend.Compile(ecc.BN254, zkpID, &vectorCircuit{})
+		assert.NoError(err)
+
+		public, secret, err := witness.ToVector(vectorAssignment(), ccs)
+		assert.NoError(err)
+		assert.Equal([]*big.Int{big.NewInt(40)}, public)
+		assert.Equal([]*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5), big.NewInt(6)}, secret)
+
+		full, err := witness.FromVector(ccs, public, secret)
+		assert.NoError(err)
+		publicOnly, err := witness.FromVector(ccs, public, nil)
+		assert.NoError(err)
+
+		switch zkpID {
+		case backend.GROTH16:
+			pk, vk, err := groth16.Setup(ccs)
+			assert.NoError(err)
+			proof, err := groth16.Prove(ccs, pk, full)
+			assert.NoError(err)
+			assert.NoError(groth16.Verify(proof, vk, publicOnly))
+			assert.NoError(groth16.Verify(proof, vk, vectorAssignment()))
+
+			proof, err = groth16.Prove(ccs, pk, vectorAssignment())
+			assert.NoError(err)
+			assert.NoError(groth16.Verify(proof, vk, publicOnly))
+		case backend.PLONK:
+			srs, err := test.NewKZGSRS(ccs)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(ccs, srs)
+			assert.NoError(err)
+			proof, err := plonk.Prove(ccs, pk, full)
+			assert.NoError(err)
+			assert.NoError(plonk.Verify(proof, vk, publicOnly))
+			assert.NoError(plonk.Verify(proof, vk, vectorAssignment()))
+
+			proof, err = plonk.Prove(ccs, pk, vectorAssignment())
+			assert.NoError(err)
+			assert.NoError(plonk.Verify(proof, vk, publicOnly))
+		}
+
+		// an invalid witness must not be accepted
+		secret[0] = big.NewInt(1)
+		full, err = witness.FromVector(ccs, public, secret)
+		assert.NoError(err)
+		switch zkpID {
+		case backend.GROTH16:
+			assert.Error(groth16.IsSolved(ccs, full))
+		case backend.PLONK:
+			assert.Error(plonk.IsSolved(ccs, full))
+		}
+	}
+}
+
+func TestVectorInvalidSize(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &vectorCircuit{})
+	assert.NoError(err)
+
+	one := big.NewInt(1)
+	_, err = witness.FromVector(ccs, []*big.Int{one, one}, []*big.Int{one, one, one, one, one})
+	assert.Error(err)
+	_, err = witness.FromVector(ccs, []*big.Int{one}, []*big.Int{one, one})
+	assert.Error(err)
+	_, err = witness.FromVector(ccs, []*big.Int{nil}, nil)
+	assert.Error(err)
+
+	var assignment vectorCircuit
+	_, _, err = witness.ToVector(&assignment, ccs)
+	assert.Error(err, "missing assignment")
+}
@@ -23,6 +23,9 @@
 // 	* `nbElements == len(publicVariables) [+ len(secretVariables)]`.
 // 	* each variable (a *field element*) is encoded as a big-endian byte array, where `len(bytes(variable)) == len(bytes(modulus))`
 //
+// A full witness may omit the last secret variables if they are optional (tagged `gnark:",optional"`),
+// `nbElements` is then the number of encoded variables; the omitted variables are read as 0.
+//
 // Ordering
 //
 // First, `publicVariables`, then `secretVariables`. Each subset is ordered from the order of definition in the circuit structure.
@@ -42,10 +45,13 @@ package witness
 
 import (
 	"encoding/binary"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"math/big"
-	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	fr_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
@@ -53,14 +59,15 @@ import (
 	fr_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
 	fr_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	fr_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr"
+	fr_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
 	"github.com/consensys/gnark/frontend"
 	witness_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/witness"
 	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
 	witness_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/witness"
 	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
 	witness_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/witness"
+	witness_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/witness"
 	"github.com/consensys/gnark/internal/backend/compiled"
-	"github.com/consensys/gnark/internal/parser"
 )
 
 // WriteFullTo encodes the witness to a slice of []fr.Element and write the []byte on provided writer
@@ -90,6 +97,12 @@ func WriteFullTo(w io.Writer, curveID ecc.ID, witness frontend.Circuit) (int64,
 			return 0, err
 		}
 		return _witness.WriteTo(w)
+	case ecc.BW6_633:
+		_witness := &witness_bw6633.Witness{}
+		if err := _witness.FromFullAssignment(witness); err != nil {
+			return 0, err
+		}
+		return _witness.WriteTo(w)
 	case ecc.BLS24_315:
 		_witness := &witness_bls24315.Witness{}
 		if err := _witness.FromFullAssignment(witness); err != nil {
@@ -128,6 +141,12 @@ func WritePublicTo(w io.Writer, curveID ecc.ID, publicWitness frontend.Circuit)
 			return 0, err
 		}
 		return _witness.WriteTo(w)
+	case ecc.BW6_633:
+		_witness := &witness_bw6633.Witness{}
+		if err := _witness.FromPublicAssignment(publicWitness); err != nil {
+			return 0, err
+		}
+		return _witness.WriteTo(w)
 	case ecc.BLS24_315:
 		_witness := &witness_bls24315.Witness{}
 		if err := _witness.FromPublicAssignment(publicWitness); err != nil {
@@ -142,17 +161,17 @@ func WritePublicTo(w io.Writer, curveID ecc.ID, publicWitness frontend.Circuit)
 // WriteSequence writes the expected sequence order of the witness on provided writer
 // witness elements are identified by their tag name, or if unset, struct & field name
 func WriteSequence(w io.Writer, circuit frontend.Circuit) error {
+	schema, err := frontend.ParseSchema(circuit)
+	if err != nil {
+		return err
+	}
 	var public, secret []string
-	collectHandler := func(visibility compiled.Visibility, name string, tInput reflect.Value) error {
-		if visibility == compiled.Public {
-			public = append(public, name)
-		} else if visibility == compiled.Secret {
-			secret = append(secret, name)
+	for _, f := range schema.Fields {
+		if f.Visibility == compiled.Public {
+			public = append(public, f.Name)
+		} else if f.Visibility == compiled.Secret {
+			secret = append(secret, f.Name)
 		}
-		return nil
-	}
-	if err := parser.Visit(circuit, "", compiled.Unset, collectHandler, reflect.TypeOf(frontend.Variable{})); err != nil {
-		return err
 	}
 
 	if _, err := io.WriteString(w, "public:\n"); err != nil {
@@ -189,14 +208,11 @@ func WriteSequence(w io.Writer, circuit frontend.Circuit) error {
 // If it can't fully re-construct the witness from the reader, returns an error
 // if the provided witness has 0 public Variables this function returns 0, nil
 func ReadPublicFrom(r io.Reader, curveID ecc.ID, witness frontend.Circuit) (int64, error) {
-	nbPublic := 0
-	collectHandler := func(visibility compiled.Visibility, name string, tInput reflect.Value) error {
-		if visibility == compiled.Public {
-			nbPublic++
-		}
-		return nil
+	schema, err := frontend.ParseSchema(witness)
+	if err != nil {
+		return 0, err
 	}
-	_ = parser.Visit(witness, "", compiled.Unset, collectHandler, reflect.TypeOf(frontend.Variable{}))
+	nbPublic := schema.NbPublic
 
 	if nbPublic == 0 {
 		return 0, nil
@@ -220,21 +236,19 @@ func ReadPublicFrom(r io.Reader, curveID ecc.ID, witness frontend.Circuit) (int6
 	read := 4
 
 	bufElement := make([]byte, elementSize)
-	reader := func(visibility compiled.Visibility, name string, tInput reflect.Value) error {
-		if visibility == compiled.Public {
+	reader := func(f *frontend.Field, v *frontend.Variable) error {
+		if f.Visibility == compiled.Public {
 			r, err := io.ReadFull(lr, bufElement)
 			read += r
 			if err != nil {
 				return err
 			}
-			v := tInput.Interface().(frontend.Variable)
 			v.Assign(new(big.Int).SetBytes(bufElement))
-			tInput.Set(reflect.ValueOf(v))
 		}
 		return nil
 	}
 
-	if err := parser.Visit(witness, "", compiled.Unset, reader, reflect.TypeOf(frontend.Variable{})); err != nil {
+	if err := schema.Visit(witness, reader); err != nil {
 		return int64(read), err
 	}
 
@@ -248,17 +262,11 @@ func ReadPublicFrom(r io.Reader, curveID ecc.ID, witness frontend.Circuit) (int6
 // If it can't fully re-construct the witness from the reader, returns an error
 // if the provided witness has 0 public Variables and 0 secret Variables this function returns 0, nil
 func ReadFullFrom(r io.Reader, curveID ecc.ID, witness frontend.Circuit) (int64, error) {
-	nbPublic := 0
-	nbSecrets := 0
-	collectHandler := func(visibility compiled.Visibility, name string, tInput reflect.Value) error {
-		if visibility == compiled.Public {
-			nbPublic++
-		} else if visibility == compiled.Secret {
-			nbSecrets++
-		}
-		return nil
+	schema, err := frontend.ParseSchema(witness)
+	if err != nil {
+		return 0, err
 	}
-	_ = parser.Visit(witness, "", compiled.Unset, collectHandler, reflect.TypeOf(frontend.Variable{}))
+	nbPublic, nbSecrets := schema.NbPublic, schema.NbSecret
 
 	if nbPublic == 0 && nbSecrets == 0 {
 		return 0, nil
@@ -270,9 +278,11 @@ func ReadFullFrom(r io.Reader, curveID ecc.ID, witness frontend.Circuit) (int64,
 		return int64(read), err
 	}
 	sliceLen := binary.BigEndian.Uint32(buf[:4])
-	if int(sliceLen) != (nbPublic + nbSecrets) {
+	nbOptional := nbTrailingOptional(schema)
+	if int(sliceLen) > (nbPublic+nbSecrets) || int(sliceLen) < (nbPublic+nbSecrets-nbOptional) {
 		return 4, errors.New("invalid witness size")
 	}
+	nbSecrets = int(sliceLen) - nbPublic
 
 	elementSize := getElementSize(curveID)
 	expectedSize := elementSize * (nbPublic + nbSecrets)
@@ -282,41 +292,58 @@ func ReadFullFrom(r io.Reader, curveID ecc.ID, witness frontend.Circuit) (int64,
 
 	bufElement := make([]byte, elementSize)
 
-	reader := func(targetVisibility, visibility compiled.Visibility, name string, tInput reflect.Value) error {
-		if visibility == targetVisibility {
-			r, err := io.ReadFull(lr, bufElement)
-			read += r
-			if err != nil {
-				return err
+	var nbSecretRead int
+	reader := func(targetVisibility compiled.Visibility) func(f *frontend.Field, v *frontend.Variable) error {
+		return func(f *frontend.Field, v *frontend.Variable) error {
+			if f.Visibility == targetVisibility {
+				if f.Visibility == compiled.Secret {
+					if nbSecretRead == nbSecrets {
+						v.Assign(new(big.Int)) // omitted optional variable
+						return nil
+					}
+					nbSecretRead++
+				}
+				r, err := io.ReadFull(lr, bufElement)
+				read += r
+				if err != nil {
+					return err
+				}
+				v.Assign(new(big.Int).SetBytes(bufElement))
 			}
-			v := tInput.Interface().(frontend.Variable)
-			v.Assign(new(big.Int).SetBytes(bufElement))
-			tInput.Set(reflect.ValueOf(v))
+			return nil
 		}
-		return nil
-	}
-
-	publicReader := func(visibility compiled.Visibility, name string, tInput reflect.Value) error {
-		return reader(compiled.Public, visibility, name, tInput)
-	}
-
-	secretReader := func(visibility compiled.Visibility, name string, tInput reflect.Value) error {
-		return reader(compiled.Secret, visibility, name, tInput)
 	}
 
 	// public
-	if err := parser.Visit(witness, "", compiled.Unset, publicReader, reflect.TypeOf(frontend.Variable{})); err != nil {
+	if err := schema.Visit(witness, reader(compiled.Public)); err != nil {
 		return int64(read), err
 	}
 
 	// secret
-	if err := parser.Visit(witness, "", compiled.Unset, secretReader, reflect.TypeOf(frontend.Variable{})); err != nil {
+	if err := schema.Visit(witness, reader(compiled.Secret)); err != nil {
 		return int64(read), err
 	}
 
 	return int64(read), nil
 }
 
+// nbTrailingOptional returns the number of optional secret inputs at the end of the secret inputs
+// of the schema, that is the number of values a full witness may omit
+func nbTrailingOptional(schema *frontend.Schema) int {
+	var optional []int
+	var i int // secret index
+	for _, f := range schema.Fields {
+		if f.Visibility != compiled.Secret {
+			continue
+		}
+		if f.Optional {
+			optional = append(optional, i)
+		}
+		i++
+	}
+	return compiled.NbTrailingOptional(optional, i)
+}
+
 func getElementSize(curve ecc.ID) int {
 	// now compute expected size from field element size.
 	var elementSize int
@@ -331,6 +358,8 @@ func getElementSize(curve ecc.ID) int {
 		elementSize = fr_bn254.Bytes
 	case ecc.BW6_761:
 		elementSize = fr_bw6761.Bytes
+	case ecc.BW6_633:
+		elementSize = fr_bw6633.Bytes
 	default:
 		panic("not implemented")
 	}
@@ -349,9 +378,81 @@ func ToJSON(witness frontend.Circuit, curveID ecc.ID) (string, error) {
 		return witness_bls12381.ToJSON(witness)
 	case ecc.BW6_761:
 		return witness_bw6761.ToJSON(witness)
+	case ecc.BW6_633:
+		return witness_bw6633.ToJSON(witness)
 	case ecc.BLS24_315:
 		return witness_bls24315.ToJSON(witness)
 	default:
 		panic("not implemented")
 	}
 }
+
+// FromJSON assigns the inputs of witness from a JSON witness, as produced by ToJSON:
+//
+//	{
+//		"Public": {"Y": "35"},
+//		"Secret": {"X": "3", "Z": "2"}
+//	}
+//
+// Values are decimal integers (see frontend.WriteWitnessSchema). A missing secret input tagged
+// `gnark:",optional"` is assigned 0; any other missing input is an error, as is an input name
+// unknown to witness.
+func FromJSON(witness frontend.Circuit, data []byte) error {
+	schema, err := frontend.ParseSchema(witness)
+	if err != nil {
+		return err
+	}
+
+	var values struct {
+		Public map[string]string
+		Secret map[string]string
+	}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	known := make(map[string]compiled.Visibility, len(schema.Fields))
+	assignHandler := func(f *frontend.Field, v *frontend.Variable) error {
+		var s string
+		var ok bool
+		switch f.Visibility {
+		case compiled.Public:
+			s, ok = values.Public[f.Name]
+		case compiled.Secret:
+			s, ok = values.Secret[f.Name]
+		default:
+			return nil
+		}
+		known[f.Name] = f.Visibility
+		if !ok || s == "<nil>" {
+			if !f.Optional {
+				return fmt.Errorf("when parsing variable %s: missing assignment", f.Name)
+			}
+			*v = frontend.Value(new(big.Int))
+			return nil
+		}
+		value, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return fmt.Errorf("when parsing variable %s: invalid value %q", f.Name, s)
+		}
+		*v = frontend.Value(value)
+		return nil
+	}
+	if err := schema.Visit(witness, assignHandler); err != nil {
+		return err
+	}
+
+	var unknown []string
+	for visibility, m := range map[compiled.Visibility]map[string]string{compiled.Public: values.Public, compiled.Secret: values.Secret} {
+		for name := range m {
+			if v, ok := known[name]; !ok || v != visibility {
+				unknown = append(unknown, name)
+			}
+		}
+	}
+	if len(unknown) != 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown public or secret variables: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/consensys/gnark/internal/backend/ioutils"
+)
+
+// SmallScalarBits is the bit size bound of the scalars counted as small by ScalarCounts
+const SmallScalarBits = 16
+
+// ScalarCounts counts the scalars of a multi exponentiation by size: the zeros are free, the ones cost a
+// point addition, the small scalars a few windows of the Pippenger algorithm, the full-size scalars all of them
+type ScalarCounts struct {
+	Zero  int
+	One   int
+	Small int // in [2, 2**SmallScalarBits)
+	Full  int
+}
+
+// Len returns the number of scalars counted
+func (c ScalarCounts) Len() int {
+	return c.Zero + c.One + c.Small + c.Full
+}
+
+// WitnessRegion counts the values of the wires which are the scalars of a multi exponentiation of the prover
+type WitnessRegion struct {
+	Name string // for Groth16, "A", "B" or "C", the element of the proof the multi exponentiation computes
+	ScalarCounts
+	Cost int // estimated cost of the multi exponentiation, in point additions, see MultiExpCost
+}
+
+// WitnessAnalysis is the distribution of the values of the wires of a solved witness, per multi exponentiation
+// of the prover, see frontend.CompiledConstraintSystem.AnalyzeWitness and WithWitnessAnalysis.
+//
+// For Groth16, the regions are:
+//   - "A": the wires of the L linear expressions of the constraints, the scalars of the multi exponentiation of A;
+//   - "B": the wires of the R linear expressions, the scalars of the multi exponentiations of B, in G1 and in G2
+//     (the cost is the one in G1, the additions in G2 are about 3 times more expensive);
+//   - "C": the secret and internal wires, the scalars of the multi exponentiation of C (the one by the
+//     quotient polynomial H, of full-size scalars, doesn't depend on the witness and isn't counted).
+type WitnessAnalysis struct {
+	NbWires int
+	NbBits  int          // bit size of the scalars
+	Wires   ScalarCounts // values of all the wires
+	Regions []WitnessRegion
+}
+
+// WriteTo writes a human readable report of the analysis to w
+func (a *WitnessAnalysis) WriteTo(w io.Writer) (int64, error) {
+	cw := &ioutils.WriterCounter{W: w}
+	tw := tabwriter.NewWriter(cw, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "region\twires\tzero\tone\tsmall (<2^%d)\tfull (%d bits)\tmsm cost (additions)\t\n", SmallScalarBits, a.NbBits)
+	fmt.Fprintf(tw, "all\t%d\t%d\t%d\t%d\t%d\t-\t\n", a.Wires.Len(), a.Wires.Zero, a.Wires.One, a.Wires.Small, a.Wires.Full)
+	for _, r := range a.Regions {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t\n", r.Name, r.Len(), r.Zero, r.One, r.Small, r.Full, r.Cost)
+	}
+	err := tw.Flush()
+	return cw.N, err
+}
+
+// MultiExpCost returns the estimated number of point additions (and doublings) of a multi exponentiation
+// of scalars of nbBits bits counted by counts, with the Pippenger algorithm: the scalars are split in windows
+// of c bits, each non-zero window of a scalar costs an addition to one of the 2**c buckets of the window, and
+// each window 2**(c+1) additions to sum its buckets, and c doublings; c is the one minimizing the cost.
+// The zeros are skipped, and the ones and small scalars only have non-zero windows among the lowest ones.
+func MultiExpCost(counts ScalarCounts, nbBits int) int {
+	if counts.Len() == counts.Zero {
+		return 0
+	}
+	best := -1
+	for c := 1; c <= 24; c++ {
+		windows := func(bits int) int { return (bits + c - 1) / c }
+		nbWindows := 1 // windows of the largest scalars
+		if counts.Full != 0 {
+			nbWindows = windows(nbBits)
+		} else if counts.Small != 0 {
+			nbWindows = windows(SmallScalarBits)
+		}
+		cost := counts.One + counts.Small*windows(SmallScalarBits) + counts.Full*windows(nbBits)
+		cost += nbWindows*(1<<uint(c+1)) + (nbWindows-1)*c
+		if best == -1 || cost < best {
+			best = cost
+		}
+	}
+	return best
+}
+
+// ErrWitnessAnalysisUnsupported is returned by AnalyzeWitness for the constraint systems of PlonK,
+// whose multi exponentiations are over polynomials, not over the values of the wires
+var ErrWitnessAnalysisUnsupported = errors.New("witness analysis is only supported for R1CS (Groth16)")
+
+// WithWitnessAnalysis is a Prover option that writes the analysis of the solved witness to w (see
+// WitnessAnalysis): the distribution of the values of the wires per multi exponentiation, and their
+// estimated cost, to predict the proving time. The proof is unchanged. PlonK ignores the option.
+func WithWitnessAnalysis(w io.Writer) func(opt *ProverOption) error {
+	return func(opt *ProverOption) error {
+		if w == nil {
+			return errors.New("witness analysis writer is nil")
+		}
+		opt.WitnessAnalysis = w
+		return nil
+	}
+}
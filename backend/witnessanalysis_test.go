@@ -0,0 +1,135 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend_test
+
+import (
+	"bytes"
+	"math/big"
+	"math/bits"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/examples/cubic"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWitnessAnalysis(t *testing.T) {
+	assert := require.New(t)
+
+	// x**3 + x + 5 == y: the wires are 1, y, x, x**2, x**3, and the constraints
+	// x * x == x**2, x**2 * x == x**3 and y * 1 == x**3 + x + 5
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &cubic.Circuit{})
+	assert.NoError(err)
+	nbBits := ecc.BN254.Info().Fr.Bits
+
+	region := func(name string, counts backend.ScalarCounts) backend.WitnessRegion {
+		return backend.WitnessRegion{Name: name, ScalarCounts: counts, Cost: backend.MultiExpCost(counts, nbBits)}
+	}
+	minusOne := new(big.Int).Sub(ecc.BN254.Info().Fr.Modulus(), big.NewInt(1))
+	for _, c := range []struct {
+		values   []*big.Int
+		expected backend.WitnessAnalysis
+	}{
+		{
+			// x = 3
+			values: []*big.Int{big.NewInt(1), big.NewInt(35), big.NewInt(3), big.NewInt(9), big.NewInt(27)},
+			expected: backend.WitnessAnalysis{NbWires: 5, NbBits: nbBits, Wires: backend.ScalarCounts{One: 1, Small: 4}, Regions: []backend.WitnessRegion{
+				region("A", backend.ScalarCounts{Small: 3}),         // x, x**2, y
+				region("B", backend.ScalarCounts{One: 1, Small: 1}), // x, 1
+				region("C", backend.ScalarCounts{Small: 3}),         // x, x**2, x**3
+			}},
+		},
+		{
+			// x = -1
+			values: []*big.Int{big.NewInt(1), big.NewInt(3), minusOne, big.NewInt(1), minusOne},
+			expected: backend.WitnessAnalysis{NbWires: 5, NbBits: nbBits, Wires: backend.ScalarCounts{One: 2, Small: 1, Full: 2}, Regions: []backend.WitnessRegion{
+				region("A", backend.ScalarCounts{One: 1, Small: 1, Full: 1}),
+				region("B", backend.ScalarCounts{One: 1, Full: 1}),
+				region("C", backend.ScalarCounts{One: 1, Full: 2}),
+			}},
+		},
+		{
+			// x = 0, values given modulo the field
+			values: []*big.Int{big.NewInt(1), big.NewInt(5), big.NewInt(0), new(big.Int).Add(minusOne, big.NewInt(1)), big.NewInt(0)},
+			expected: backend.WitnessAnalysis{NbWires: 5, NbBits: nbBits, Wires: backend.ScalarCounts{Zero: 3, One: 1, Small: 1}, Regions: []backend.WitnessRegion{
+				region("A", backend.ScalarCounts{Zero: 2, Small: 1}),
+				region("B", backend.ScalarCounts{Zero: 1, One: 1}),
+				region("C", backend.ScalarCounts{Zero: 3}),
+			}},
+		},
+	} {
+		analysis, err := ccs.AnalyzeWitness(c.values)
+		assert.NoError(err)
+		assert.Equal(&c.expected, analysis)
+	}
+
+	_, err = ccs.AnalyzeWitness([]*big.Int{big.NewInt(1)})
+	assert.Error(err, "invalid assignment size")
+
+	sparse, err := frontend.Compile(ecc.BN254, backend.PLONK, &cubic.Circuit{})
+	assert.NoError(err)
+	_, err = sparse.AnalyzeWitness(nil)
+	assert.ErrorIs(err, backend.ErrWitnessAnalysisUnsupported)
+}
+
+func TestWitnessAnalysisProver(t *testing.T) {
+	assert := require.New(t)
+
+	// the wires are 1, x and its 64 bits; the bits are in the L and R linear expressions of their
+	// boolean constraints, with 1, and x is only in the O one of the decomposition
+	const x = 0x00ff00ff00ff00ff
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, newBitsCircuit(1))
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	ones := bits.OnesCount64(x)
+	nbBits := ecc.BN254.Info().Fr.Bits
+	bitsAndOne := backend.ScalarCounts{Zero: 64 - ones, One: ones + 1}
+	bitsAndX := backend.ScalarCounts{Zero: 64 - ones, One: ones, Full: 1}
+	expected := backend.WitnessAnalysis{NbWires: 66, NbBits: nbBits, Wires: backend.ScalarCounts{Zero: 64 - ones, One: ones + 1, Full: 1}, Regions: []backend.WitnessRegion{
+		{Name: "A", ScalarCounts: bitsAndOne, Cost: backend.MultiExpCost(bitsAndOne, nbBits)},
+		{Name: "B", ScalarCounts: bitsAndOne, Cost: backend.MultiExpCost(bitsAndOne, nbBits)},
+		{Name: "C", ScalarCounts: bitsAndX, Cost: backend.MultiExpCost(bitsAndX, nbBits)},
+	}}
+	var report bytes.Buffer
+	_, err = expected.WriteTo(&report)
+	assert.NoError(err)
+
+	assignment := newBitsCircuit(1)
+	assignment.X[0].Assign(uint64(x))
+	var publicAssignment bitsCircuit
+	for _, packed := range []bool{false, true} {
+		opts := []func(opt *backend.ProverOption) error{}
+		if packed {
+			opts = append(opts, backend.WithPackedBooleans())
+		}
+		var buf bytes.Buffer
+		proof, err := groth16.Prove(ccs, pk, assignment, append(opts, backend.WithWitnessAnalysis(&buf))...)
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, &publicAssignment))
+		assert.Equal(report.String(), buf.String(), "packed booleans: %v", packed)
+	}
+
+	// the cost model skips the zeros, and favors the small scalars
+	assert.Equal(0, backend.MultiExpCost(backend.ScalarCounts{Zero: 10}, nbBits))
+	assert.Equal(10+4, backend.MultiExpCost(backend.ScalarCounts{One: 10}, nbBits), "an addition per one, and the buckets of a window of 1 bit")
+	assert.Less(backend.MultiExpCost(backend.ScalarCounts{Small: 1000}, nbBits), backend.MultiExpCost(backend.ScalarCounts{Full: 1000}, nbBits))
+}
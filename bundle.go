@@ -0,0 +1,271 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gnark
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/common"
+	"github.com/consensys/gnark/internal/curves"
+	gnarkio "github.com/consensys/gnark/io"
+)
+
+// ErrVerifyingKeyMismatch is returned by VerifyBundle when the verifying key isn't the one of the bundle
+var ErrVerifyingKeyMismatch = errors.New("the verifying key doesn't match the digest of the bundle")
+
+// PublicWitness is the public witness of a proof, in its canonical binary encoding (see
+// witness.WritePublicTo): the values of the public inputs, and only them. NewPublicWitness doesn't
+// read the secret inputs of the assignment: a PublicWitness holds no secret data by construction.
+type PublicWitness struct {
+	curveID ecc.ID
+	data    []byte
+}
+
+// NewPublicWitness returns the public witness of the assignment on the curve curveID. The assignment
+// may be a full witness, its secret inputs are ignored.
+func NewPublicWitness(curveID ecc.ID, assignment frontend.Circuit) (PublicWitness, error) {
+	if _, err := curveOf(curveID.String()); err != nil {
+		return PublicWitness{}, err
+	}
+	var buf bytes.Buffer
+	if _, err := witness.WritePublicTo(&buf, curveID, assignment); err != nil {
+		return PublicWitness{}, err
+	}
+	return PublicWitness{curveID: curveID, data: buf.Bytes()}, nil
+}
+
+// CurveID returns the curve of the public witness
+func (w PublicWitness) CurveID() ecc.ID {
+	return w.curveID
+}
+
+// Bytes returns the binary encoding of the public witness, see witness.ReadPublicFrom
+func (w PublicWitness) Bytes() []byte {
+	return append([]byte(nil), w.data...)
+}
+
+// VerificationBundle is what a verifier needs, with the verifying key, to reproduce the verification
+// of a proof: the proof, its public witness, the digest of the verifying key it verifies with, and
+// metadata (a ticket number, the version of the prover, ...). It is written in a single small file
+// with WriteTo, and verified with VerifyBundle.
+//
+// A bundle holds no secret data: its witness is a PublicWitness.
+type VerificationBundle struct {
+	Curve              ecc.ID
+	Backend            backend.ID
+	Proof              []byte // canonical CBOR encoding of the proof, see groth16.Proof.MarshalCBOR
+	PublicWitness      PublicWitness
+	VerifyingKeyDigest [sha256.Size]byte // see VerifyingKeyDigest
+	Metadata           map[string]string
+}
+
+// bundleCBOR is the CBOR encoding of a VerificationBundle, see VerificationBundle.WriteTo
+type bundleCBOR struct {
+	common.CBORHeader
+	Backend      string            `cbor:"backend"`
+	Proof        []byte            `cbor:"proof"`
+	Public       []byte            `cbor:"public"`
+	VerifyingKey []byte            `cbor:"vk"`
+	Metadata     map[string]string `cbor:"metadata,omitempty"`
+}
+
+// NewVerificationBundle returns the bundle of the proof (a groth16.Proof or a plonk.Proof) and its public
+// witness, for the verifying key of digest vkDigest (see VerifyingKeyDigest). meta is copied.
+func NewVerificationBundle(proof interface{}, publicWitness PublicWitness, vkDigest [sha256.Size]byte, meta map[string]string) (*VerificationBundle, error) {
+	if publicWitness.data == nil {
+		return nil, errors.New("empty public witness, see NewPublicWitness")
+	}
+	var data []byte
+	var err error
+	var typ string
+	res := VerificationBundle{Curve: publicWitness.curveID, PublicWitness: publicWitness, VerifyingKeyDigest: vkDigest}
+	switch p := proof.(type) {
+	case groth16.Proof:
+		data, err = p.MarshalCBOR()
+		typ, res.Backend = common.CBORGroth16Proof, backend.GROTH16
+	case plonk.Proof:
+		data, err = p.MarshalCBOR()
+		typ, res.Backend = common.CBORPlonkProof, backend.PLONK
+	default:
+		return nil, fmt.Errorf("%T is not a groth16 or plonk proof", proof)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the proof must be on the curve of the public witness
+	var header common.CBORHeader
+	if err := common.CBORDecMode.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	if err := header.Check(typ, res.Curve); err != nil {
+		return nil, err
+	}
+	res.Proof = data
+
+	if len(meta) != 0 {
+		res.Metadata = make(map[string]string, len(meta))
+		for k, v := range meta {
+			res.Metadata[k] = v
+		}
+	}
+	return &res, nil
+}
+
+// WriteTo writes the bundle on w in canonical CBOR: a map with the header
+//
+//	"type": "gnark/bundle", "version": 1, "curve": Curve.String()
+//
+// and the keys "backend" (Backend.String()), "proof", "public" (the binary encoding of the public witness),
+// "vk" (the digest of the verifying key) and, if any, "metadata".
+func (b *VerificationBundle) WriteTo(w io.Writer) (int64, error) {
+	data, err := common.CBOREncMode.Marshal(bundleCBOR{
+		CBORHeader:   common.NewCBORHeader(common.CBORVerificationBundle, b.Curve),
+		Backend:      b.Backend.String(),
+		Proof:        b.Proof,
+		Public:       b.PublicWitness.data,
+		VerifyingKey: b.VerifyingKeyDigest[:],
+		Metadata:     b.Metadata,
+	})
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// ReadFrom reads a bundle written with WriteTo
+func (b *VerificationBundle) ReadFrom(r io.Reader) (int64, error) {
+	dec := common.CBORDecMode.NewDecoder(gnarkio.NewLimitReader(r))
+	var bc bundleCBOR
+	if err := dec.Decode(&bc); err != nil {
+		return int64(dec.NumBytesRead()), err
+	}
+	read := int64(dec.NumBytesRead())
+
+	curveID, err := curveOf(bc.Curve)
+	if err != nil {
+		return read, err
+	}
+	if err := bc.Check(common.CBORVerificationBundle, curveID); err != nil {
+		return read, err
+	}
+	backendID, err := backendOf(bc.Backend)
+	if err != nil {
+		return read, err
+	}
+	if len(bc.VerifyingKey) != sha256.Size {
+		return read, fmt.Errorf("invalid verifying key digest size %d", len(bc.VerifyingKey))
+	}
+
+	*b = VerificationBundle{
+		Curve:         curveID,
+		Backend:       backendID,
+		Proof:         bc.Proof,
+		PublicWitness: PublicWitness{curveID: curveID, data: bc.Public},
+		Metadata:      bc.Metadata,
+	}
+	copy(b.VerifyingKeyDigest[:], bc.VerifyingKey)
+	return read, nil
+}
+
+// VerifyingKeyDigest returns the SHA-256 of the serialization of vk (a groth16.VerifyingKey or a
+// plonk.VerifyingKey), see WriteTo
+func VerifyingKeyDigest(vk io.WriterTo) ([sha256.Size]byte, error) {
+	var res [sha256.Size]byte
+	h := sha256.New()
+	if _, err := vk.WriteTo(h); err != nil {
+		return res, err
+	}
+	copy(res[:], h.Sum(nil))
+	return res, nil
+}
+
+// VerifyBundle checks that vk is the verifying key of the bundle, with ErrVerifyingKeyMismatch if its
+// digest isn't the one of the bundle, then verifies the proof of the bundle with its public witness
+func VerifyBundle(bundle *VerificationBundle, vk io.WriterTo) error {
+	digest, err := VerifyingKeyDigest(vk)
+	if err != nil {
+		return err
+	}
+	if digest != bundle.VerifyingKeyDigest {
+		return fmt.Errorf("%w: %x, expected %x", ErrVerifyingKeyMismatch, digest, bundle.VerifyingKeyDigest)
+	}
+	if bundle.PublicWitness.curveID != bundle.Curve {
+		return fmt.Errorf("public witness on %s, bundle on %s", bundle.PublicWitness.curveID, bundle.Curve)
+	}
+	if _, err := curveOf(bundle.Curve.String()); err != nil {
+		return err
+	}
+
+	public := bytes.NewReader(bundle.PublicWitness.data)
+	switch bundle.Backend {
+	case backend.GROTH16:
+		_vk, ok := vk.(groth16.VerifyingKey)
+		if !ok || reflect.TypeOf(vk) != reflect.TypeOf(groth16.NewVerifyingKey(bundle.Curve)) {
+			return fmt.Errorf("%T is not a groth16 verifying key on %s", vk, bundle.Curve)
+		}
+		proof := groth16.NewProof(bundle.Curve)
+		if err := proof.UnmarshalCBOR(bundle.Proof); err != nil {
+			return err
+		}
+		return groth16.ReadAndVerify(proof, _vk, public)
+	case backend.PLONK:
+		_vk, ok := vk.(plonk.VerifyingKey)
+		if !ok || reflect.TypeOf(vk) != reflect.TypeOf(plonk.NewVerifyingKey(bundle.Curve)) {
+			return fmt.Errorf("%T is not a plonk verifying key on %s", vk, bundle.Curve)
+		}
+		proof := plonk.NewProof(bundle.Curve)
+		if err := proof.UnmarshalCBOR(bundle.Proof); err != nil {
+			return err
+		}
+		return plonk.ReadAndVerify(proof, _vk, public)
+	default:
+		return fmt.Errorf("unsupported backend %s", bundle.Backend)
+	}
+}
+
+// curveOf returns the supported curve of the given name, see curves.Implemented
+func curveOf(name string) (ecc.ID, error) {
+	for _, id := range curves.Implemented() {
+		if id.String() == name {
+			return id, nil
+		}
+	}
+	return ecc.UNKNOWN, fmt.Errorf("unsupported curve %q", name)
+}
+
+// backendOf returns the implemented backend of the given name
+func backendOf(name string) (backend.ID, error) {
+	for _, id := range backend.Implemented() {
+		if id.String() == name {
+			return id, nil
+		}
+	}
+	return backend.UNKNOWN, fmt.Errorf("unsupported backend %q", name)
+}
@@ -0,0 +1,189 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gnark
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/curves"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// bundleCircuit checks that Y == X**3 + X + 5
+type bundleCircuit struct {
+	X frontend.Variable `gnark:",secret"`
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *bundleCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(circuit.Y, api.Add(api.Mul(circuit.X, circuit.X, circuit.X), circuit.X, 5))
+	return nil
+}
+
+// otherCircuit checks that Y == X**2 + 5, its PlonK verifying key differs from the one of bundleCircuit
+type otherCircuit struct {
+	X frontend.Variable `gnark:",secret"`
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *otherCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(circuit.Y, api.Add(api.Mul(circuit.X, circuit.X), 5))
+	return nil
+}
+
+// TestVerificationBundle proves a witness with both backends, and checks that the bundle of the proof
+// verifies after a round trip, holds no secret, and is rejected with another verifying key
+func TestVerificationBundle(t *testing.T) {
+	assert := require.New(t)
+
+	// a secret which would be noticed in the bundle
+	x, _ := new(big.Int).SetString("1234567890abcdef1234567890abcdef1234567890abcdef", 16)
+	r := ecc.BN254.Info().Fr.Modulus()
+	y := new(big.Int).Exp(x, big.NewInt(3), r)
+	y.Add(y, x).Add(y, big.NewInt(5)).Mod(y, r)
+	xBytes := x.FillBytes(make([]byte, 32))
+
+	for _, b := range backend.Implemented() {
+		ccs, err := frontend.Compile(ecc.BN254, b, &bundleCircuit{})
+		assert.NoError(err)
+		assignment := &bundleCircuit{X: frontend.Value(x), Y: frontend.Value(y)}
+
+		var proof, otherVK interface{}
+		var vk io.WriterTo
+		switch b {
+		case backend.GROTH16:
+			pk, _vk, err := groth16.Setup(ccs)
+			assert.NoError(err)
+			proof, err = groth16.Prove(ccs, pk, assignment)
+			assert.NoError(err)
+			_, otherVK, err = groth16.Setup(ccs)
+			assert.NoError(err)
+			vk = _vk
+		case backend.PLONK:
+			srs, err := test.NewKZGSRS(ccs)
+			assert.NoError(err)
+			pk, _vk, err := plonk.Setup(ccs, srs)
+			assert.NoError(err)
+			proof, err = plonk.Prove(ccs, pk, assignment)
+			assert.NoError(err)
+			other, err := frontend.Compile(ecc.BN254, b, &otherCircuit{})
+			assert.NoError(err)
+			_, otherVK, err = plonk.Setup(other, srs)
+			assert.NoError(err)
+			vk = _vk
+		}
+
+		// the public witness of the full assignment
+		public, err := NewPublicWitness(ecc.BN254, assignment)
+		assert.NoError(err)
+		digest, err := VerifyingKeyDigest(vk)
+		assert.NoError(err)
+		bundle, err := NewVerificationBundle(proof, public, digest, map[string]string{"ticket": "42"})
+		assert.NoError(err, b)
+		assert.NoError(VerifyBundle(bundle, vk), b)
+
+		var buf bytes.Buffer
+		_, err = bundle.WriteTo(&buf)
+		assert.NoError(err)
+		assert.False(bytes.Contains(buf.Bytes(), xBytes), "%s: the bundle holds the secret", b)
+		var read VerificationBundle
+		_, err = read.ReadFrom(bytes.NewReader(buf.Bytes()))
+		assert.NoError(err)
+		assert.Equal(bundle, &read)
+		assert.NoError(VerifyBundle(&read, vk), b)
+
+		// another verifying key is rejected before the verification
+		err = VerifyBundle(&read, otherVK.(io.WriterTo))
+		assert.True(errors.Is(err, ErrVerifyingKeyMismatch), "%s: %v", b, err)
+		read.VerifyingKeyDigest[0] ^= 1
+		err = VerifyBundle(&read, vk)
+		assert.True(errors.Is(err, ErrVerifyingKeyMismatch), "%s: %v", b, err)
+
+		// another public witness doesn't verify
+		wrong, err := NewPublicWitness(ecc.BN254, &bundleCircuit{Y: frontend.Value(new(big.Int).Add(y, big.NewInt(1)))})
+		assert.NoError(err)
+		bundle, err = NewVerificationBundle(proof, wrong, digest, nil)
+		assert.NoError(err)
+		err = VerifyBundle(bundle, vk)
+		assert.Error(err)
+		assert.False(errors.Is(err, ErrVerifyingKeyMismatch))
+
+		// a proof on another curve than the public witness
+		other, err := NewPublicWitness(ecc.BLS12_381, assignment)
+		assert.NoError(err)
+		_, err = NewVerificationBundle(proof, other, digest, nil)
+		assert.Error(err)
+	}
+}
+
+// TestVerificationBundleCurves checks that a groth16 bundle verifies after a round trip on all the curves
+// supported by gnark, BW6-633 included
+func TestVerificationBundleCurves(t *testing.T) {
+	for _, curve := range curves.Implemented() {
+		t.Run(curve.String(), func(t *testing.T) {
+			assert := require.New(t)
+			ccs, err := frontend.Compile(curve, backend.GROTH16, &bundleCircuit{})
+			assert.NoError(err)
+			assignment := &bundleCircuit{X: frontend.Value(3), Y: frontend.Value(35)}
+			pk, vk, err := groth16.Setup(ccs)
+			assert.NoError(err)
+			proof, err := groth16.Prove(ccs, pk, assignment)
+			assert.NoError(err)
+
+			public, err := NewPublicWitness(curve, assignment)
+			assert.NoError(err)
+			digest, err := VerifyingKeyDigest(vk)
+			assert.NoError(err)
+			bundle, err := NewVerificationBundle(proof, public, digest, nil)
+			assert.NoError(err)
+			var buf bytes.Buffer
+			_, err = bundle.WriteTo(&buf)
+			assert.NoError(err)
+			var read VerificationBundle
+			_, err = read.ReadFrom(&buf)
+			assert.NoError(err)
+			assert.Equal(curve, read.Curve)
+			assert.NoError(VerifyBundle(&read, vk))
+		})
+	}
+}
+
+// TestVerificationBundleWitnessType checks that a bundle can't be built from an assignment, which may hold
+// secrets, but only from a PublicWitness
+func TestVerificationBundleWitnessType(t *testing.T) {
+	witnessType := reflect.TypeOf(NewVerificationBundle).In(1)
+	circuitType := reflect.TypeOf((*frontend.Circuit)(nil)).Elem()
+	if witnessType != reflect.TypeOf(PublicWitness{}) || circuitType.AssignableTo(witnessType) {
+		t.Fatal("a bundle must take a PublicWitness, not a circuit assignment")
+	}
+
+	// the zero PublicWitness isn't a public witness
+	if _, err := NewVerificationBundle(groth16.NewProof(ecc.BN254), PublicWitness{}, [32]byte{}, nil); err == nil {
+		t.Fatal("expected an error for an empty public witness")
+	}
+}
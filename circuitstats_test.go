@@ -23,6 +23,9 @@ func TestCircuitStatistics(t *testing.T) {
 
 	curves := ecc.Implemented()
 	for name, tData := range circuits.Circuits {
+		if tData.ExpectedCompileError != "" {
+			continue // the circuit doesn't compile
+		}
 
 		for _, curve := range curves {
 			check := func(backendID backend.ID) {
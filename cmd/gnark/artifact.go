@@ -0,0 +1,256 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/internal/curves"
+	gnarkio "github.com/consensys/gnark/io"
+)
+
+// artifactVersion is the version of the header of the artifacts
+const artifactVersion = 1
+
+// artifactMagic starts the artifacts
+var artifactMagic = [4]byte{'g', 'n', 'r', 'k'}
+
+// kind is the kind of object stored in an artifact
+type kind uint8
+
+const (
+	kindCCS kind = iota + 1
+	kindProvingKey
+	kindVerifyingKey
+	kindProof
+)
+
+var kindNames = [...]string{"", "ccs", "pk", "vk", "proof"}
+
+func (k kind) String() string {
+	if int(k) >= len(kindNames) || k == 0 {
+		return fmt.Sprintf("kind(%d)", k)
+	}
+	return kindNames[k]
+}
+
+// header is the header of an artifact, followed by the object serialized with its WriteTo method:
+//
+//	magic [4]byte | version uint8 | kind uint8 | curve uint16 | backend uint8 | len(circuit) uint16 | circuit
+//
+// in big endian.
+type header struct {
+	Kind    kind
+	Curve   ecc.ID
+	Backend backend.ID
+	Circuit string // name of the example, or path of the plugin
+}
+
+// info describes an artifact, see inspect
+type info struct {
+	header
+	Version int
+	Size    int64  // size of the serialized object, in bytes
+	Digest  []byte // SHA-256 of the serialized object
+}
+
+// inspect reads the artifact from r, and returns its description
+func inspect(r io.Reader) (info, error) {
+	h, version, err := readHeader(r)
+	if err != nil {
+		return info{}, err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(hash, r)
+	if err != nil {
+		return info{}, err
+	}
+	return info{header: h, Version: version, Size: size, Digest: hash.Sum(nil)}, nil
+}
+
+func writeHeader(w io.Writer, h header) error {
+	if len(h.Circuit) > 0xffff {
+		return errors.New("circuit name too long")
+	}
+	buf := make([]byte, 0, 11+len(h.Circuit))
+	buf = append(buf, artifactMagic[:]...)
+	buf = append(buf, artifactVersion, byte(h.Kind))
+	buf = append(buf, byte(h.Curve>>8), byte(h.Curve), byte(h.Backend))
+	buf = append(buf, byte(len(h.Circuit)>>8), byte(len(h.Circuit)))
+	buf = append(buf, h.Circuit...)
+	_, err := w.Write(buf)
+	return err
+}
+
+// readHeader reads the header of an artifact, and returns it with its version
+func readHeader(r io.Reader) (header, int, error) {
+	var buf [11]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return header{}, 0, fmt.Errorf("not a gnark artifact: %v", err)
+	}
+	if [4]byte{buf[0], buf[1], buf[2], buf[3]} != artifactMagic {
+		return header{}, 0, errors.New("not a gnark artifact")
+	}
+	version := int(buf[4])
+	if version != artifactVersion {
+		return header{}, version, fmt.Errorf("unsupported artifact version %d", version)
+	}
+	h := header{
+		Kind:    kind(buf[5]),
+		Curve:   ecc.ID(binary.BigEndian.Uint16(buf[6:])),
+		Backend: backend.ID(buf[8]),
+	}
+	if h.Kind == 0 || int(h.Kind) >= len(kindNames) {
+		return header{}, version, fmt.Errorf("unknown artifact %s", h.Kind)
+	}
+	if !curves.IsImplemented(h.Curve) {
+		return header{}, version, fmt.Errorf("unknown curve %d", h.Curve)
+	}
+	if !isImplementedBackend(h.Backend) {
+		return header{}, version, fmt.Errorf("unknown backend %d", h.Backend)
+	}
+	circuit := make([]byte, binary.BigEndian.Uint16(buf[9:]))
+	if _, err := io.ReadFull(r, circuit); err != nil {
+		return header{}, version, fmt.Errorf("truncated header: %v", err)
+	}
+	h.Circuit = string(circuit)
+	return h, version, nil
+}
+
+// newObject returns the object stored in an artifact of header h, to be read with ReadFrom
+func newObject(h header) io.ReaderFrom {
+	switch h.Backend {
+	case backend.GROTH16:
+		switch h.Kind {
+		case kindCCS:
+			return groth16.NewCS(h.Curve)
+		case kindProvingKey:
+			return groth16.NewProvingKey(h.Curve)
+		case kindVerifyingKey:
+			return groth16.NewVerifyingKey(h.Curve)
+		case kindProof:
+			return groth16.NewProof(h.Curve)
+		}
+	case backend.PLONK:
+		switch h.Kind {
+		case kindCCS:
+			return plonk.NewCS(h.Curve)
+		case kindProvingKey:
+			return plonk.NewProvingKey(h.Curve)
+		case kindVerifyingKey:
+			return plonk.NewVerifyingKey(h.Curve)
+		case kindProof:
+			return plonk.NewProof(h.Curve)
+		}
+	}
+	panic("unknown artifact")
+}
+
+// openFile opens the file at path or, if path is the manifest of a split file (see gnarkio.WriteSplitTo),
+// the concatenation of its chunks, which are checked as they are read (see readToEnd)
+func openFile(path string) (io.ReadCloser, error) {
+	if filepath.Base(path) == gnarkio.SplitManifestName {
+		return gnarkio.OpenSplit(filepath.Dir(path))
+	}
+	return os.Open(path)
+}
+
+// readToEnd reads r to its end, to check the last chunks of a split file (see openFile)
+func readToEnd(r io.Reader) error {
+	_, err := io.Copy(ioutil.Discard, r)
+	return err
+}
+
+// readArtifact reads the artifact of kind k at path, and returns its header and its object
+func readArtifact(path string, k kind) (header, io.ReaderFrom, error) {
+	f, err := openFile(path)
+	if err != nil {
+		return header{}, nil, fail(exitIO, "%v", err)
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+
+	h, _, err := readHeader(r)
+	if err != nil {
+		return header{}, nil, fail(exitArtifact, "%s: %v", path, err)
+	}
+	if h.Kind != k {
+		return header{}, nil, fail(exitArtifact, "%s: is a %s, expected a %s", path, h.Kind, k)
+	}
+	obj := newObject(h)
+	if _, err := obj.ReadFrom(r); err != nil {
+		if cerr := readToEnd(r); cerr != nil {
+			err = cerr
+		}
+		return header{}, nil, fail(exitArtifact, "%s: invalid %s: %v", path, k, err)
+	}
+	if err := readToEnd(r); err != nil {
+		return header{}, nil, fail(exitArtifact, "%s: %v", path, err)
+	}
+	return h, obj, nil
+}
+
+// writeArtifact writes the artifact of header h and object obj at path
+func writeArtifact(path string, h header, obj io.WriterTo) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fail(exitIO, "%v", err)
+	}
+	w := bufio.NewWriter(f)
+	if err = writeHeader(w, h); err == nil {
+		if _, err = obj.WriteTo(w); err == nil {
+			err = w.Flush()
+		}
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fail(exitIO, "%s: %v", path, err)
+	}
+	return nil
+}
+
+// checkMatch checks that the artifacts a and b were generated for the same circuit, curve and backend
+func checkMatch(a, b header, pathA, pathB string) error {
+	if a.Curve != b.Curve || a.Backend != b.Backend || a.Circuit != b.Circuit {
+		return fail(exitArtifact, "%s (%s, %s, %s) and %s (%s, %s, %s) don't match",
+			pathA, a.Circuit, a.Curve, a.Backend, pathB, b.Circuit, b.Curve, b.Backend)
+	}
+	return nil
+}
+
+func isImplementedBackend(b backend.ID) bool {
+	for _, i := range backend.Implemented() {
+		if i == b {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"plugin"
+	"sort"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/examples/cubic"
+	"github.com/consensys/gnark/examples/exponentiate"
+	"github.com/consensys/gnark/examples/mimc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/curves"
+)
+
+// examples are the circuits given by name to --circuit
+var examples = map[string]func() frontend.Circuit{
+	"cubic":        func() frontend.Circuit { return new(cubic.Circuit) },
+	"exponentiate": func() frontend.Circuit { return new(exponentiate.Circuit) },
+	"mimc":         func() frontend.Circuit { return new(mimc.Circuit) },
+}
+
+// loadCircuit returns the example circuit name, or the circuit exported by the plugin at the path
+// name
+func loadCircuit(name string) (frontend.Circuit, error) {
+	if newCircuit, ok := examples[name]; ok {
+		return newCircuit(), nil
+	}
+	if !strings.HasSuffix(name, ".so") {
+		names := make([]string, 0, len(examples))
+		for n := range examples {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fail(exitUsage, "unknown circuit %q: not an example (%s), nor a plugin (.so)", name, strings.Join(names, ", "))
+	}
+
+	p, err := plugin.Open(name)
+	if err != nil {
+		return nil, fail(exitIO, "%v", err)
+	}
+	symbol, err := p.Lookup("Circuit")
+	if err != nil {
+		return nil, fail(exitUsage, "%s: %v", name, err)
+	}
+	circuit, ok := symbol.(*frontend.Circuit)
+	if !ok || *circuit == nil {
+		return nil, fail(exitUsage, "%s: Circuit is a %T, expected a non-nil frontend.Circuit", name, symbol)
+	}
+	return *circuit, nil
+}
+
+func parseCurve(name string) (ecc.ID, error) {
+	var names []string
+	for _, curve := range curves.Implemented() {
+		if strings.EqualFold(name, curve.String()) {
+			return curve, nil
+		}
+		names = append(names, curve.String())
+	}
+	return ecc.UNKNOWN, fail(exitUsage, "unknown curve %q (%s)", name, strings.Join(names, ", "))
+}
+
+func parseBackend(name string) (backend.ID, error) {
+	var names []string
+	for _, b := range backend.Implemented() {
+		if strings.EqualFold(name, b.String()) {
+			return b, nil
+		}
+		names = append(names, b.String())
+	}
+	return backend.UNKNOWN, fail(exitUsage, "unknown backend %q (%s)", name, strings.Join(names, ", "))
+}
+
+// jsonWitness is the JSON format of a witness, see witness.ToJSON
+type jsonWitness struct {
+	Public map[string]string
+	Secret map[string]string
+}
+
+// readWitness assigns the inputs of circuit of the given visibilities from the JSON witness at path.
+// All the inputs must be assigned, and the witness must not have other values.
+func readWitness(path string, circuit frontend.Circuit, visibilities ...frontend.Visibility) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fail(exitIO, "%v", err)
+	}
+	var w jsonWitness
+	if err := json.Unmarshal(data, &w); err != nil {
+		return fail(exitWitness, "%s: %v", path, err)
+	}
+	values := map[frontend.Visibility]map[string]string{
+		frontend.Public: w.Public,
+		frontend.Secret: w.Secret,
+	}
+	wanted := make(map[frontend.Visibility]bool)
+	for _, v := range visibilities {
+		wanted[v] = true
+	}
+
+	schema, err := frontend.ParseSchema(circuit)
+	if err != nil {
+		return fail(exitUsage, "%v", err)
+	}
+	used := 0
+	err = schema.Visit(circuit, func(f *frontend.Field, v *frontend.Variable) error {
+		if !wanted[f.Visibility] {
+			return nil
+		}
+		s, ok := values[f.Visibility][f.Name]
+		if !ok {
+			return fmt.Errorf("missing %s input %s", visibilityName(f.Visibility), f.Name)
+		}
+		value, ok := new(big.Int).SetString(s, 0)
+		if !ok {
+			return fmt.Errorf("%s: invalid value %q", f.Name, s)
+		}
+		v.Assign(value)
+		used++
+		return nil
+	})
+	if err != nil {
+		return fail(exitWitness, "%s: %v", path, err)
+	}
+
+	nbValues := 0
+	for _, v := range visibilities {
+		nbValues += len(values[v])
+	}
+	if used != nbValues {
+		return fail(exitWitness, "%s: %d values for %d inputs", path, nbValues, used)
+	}
+	return nil
+}
+
+func visibilityName(v frontend.Visibility) string {
+	name, _ := v.MarshalText()
+	return string(name)
+}
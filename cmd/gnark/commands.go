@@ -0,0 +1,331 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+func cmdCompile(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("compile", stderr)
+	circuitName := fs.String("circuit", "", "name of an example circuit, or path of a Go plugin (.so) exporting Circuit")
+	curveName := fs.String("curve", "bn254", "elliptic curve")
+	backendName := fs.String("backend", "groth16", "proving scheme: groth16 or plonk")
+	out := fs.String("out", "", "path of the compiled constraint system")
+	if err := parseFlags(fs, args, "circuit", "out"); err != nil {
+		return err
+	}
+
+	curve, err := parseCurve(*curveName)
+	if err != nil {
+		return err
+	}
+	b, err := parseBackend(*backendName)
+	if err != nil {
+		return err
+	}
+	circuit, err := loadCircuit(*circuitName)
+	if err != nil {
+		return err
+	}
+
+	ccs, err := frontend.Compile(curve, b, circuit)
+	if err != nil {
+		return fail(exitCompile, "%v", err)
+	}
+	if err := writeArtifact(*out, header{Kind: kindCCS, Curve: curve, Backend: b, Circuit: *circuitName}, ccs); err != nil {
+		return err
+	}
+	_, _, nbPublic := ccs.GetNbVariables()
+	fmt.Fprintf(stdout, "%s: %d constraints, %d public inputs\n", *out, ccs.GetNbConstraints(), nbPublic)
+	return nil
+}
+
+func cmdSetup(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("setup", stderr)
+	ccsPath := fs.String("ccs", "", "path of the compiled constraint system")
+	pkPath := fs.String("pk", "", "path of the proving key")
+	vkPath := fs.String("vk", "", "path of the verifying key")
+	srsPath := fs.String("srs", "", "path of the KZG SRS (plonk)")
+	unsafeSRS := fs.Bool("unsafe-srs", false, "generate the KZG SRS from a known secret, and write it at --srs (plonk, for tests only)")
+	maxPublicInputs := fs.Int("max-public-inputs", 0, "fail if the circuit has more public inputs (0 for no limit)")
+	if err := parseFlags(fs, args, "ccs", "pk", "vk"); err != nil {
+		return err
+	}
+	var opts []func(opt *backend.SetupOption) error
+	if *maxPublicInputs != 0 {
+		opts = append(opts, backend.WithMaxPublicInputs(*maxPublicInputs))
+		if _, err := backend.NewSetupOption(opts...); err != nil {
+			return fail(exitUsage, "--max-public-inputs: %v", err)
+		}
+	}
+
+	h, obj, err := readArtifact(*ccsPath, kindCCS)
+	if err != nil {
+		return err
+	}
+	ccs := obj.(frontend.CompiledConstraintSystem)
+
+	var pk, vk io.WriterTo
+	switch h.Backend {
+	case backend.GROTH16:
+		pk, vk, err = groth16.Setup(ccs, opts...)
+	case backend.PLONK:
+		var srs kzg.SRS
+		if *unsafeSRS {
+			srs, err = newUnsafeSRS(ccs, *srsPath)
+		} else {
+			srs, err = readSRS(h.Curve, *srsPath)
+		}
+		if err != nil {
+			return err
+		}
+		pk, vk, err = plonk.Setup(ccs, srs, opts...)
+	}
+	if err != nil {
+		return fail(exitSetup, "%v", err)
+	}
+
+	h.Kind = kindProvingKey
+	if err := writeArtifact(*pkPath, h, pk); err != nil {
+		return err
+	}
+	h.Kind = kindVerifyingKey
+	return writeArtifact(*vkPath, h, vk)
+}
+
+func cmdProve(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("prove", stderr)
+	ccsPath := fs.String("ccs", "", "path of the compiled constraint system")
+	pkPath := fs.String("pk", "", "path of the proving key")
+	witnessPath := fs.String("witness", "", "path of the JSON full witness")
+	out := fs.String("out", "", "path of the proof")
+	srsPath := fs.String("srs", "", "path of the KZG SRS (plonk)")
+	if err := parseFlags(fs, args, "ccs", "pk", "witness", "out"); err != nil {
+		return err
+	}
+
+	h, ccs, err := readArtifact(*ccsPath, kindCCS)
+	if err != nil {
+		return err
+	}
+	// the plugin of the circuit registers its hints
+	circuit, err := loadCircuit(h.Circuit)
+	if err != nil {
+		return err
+	}
+	if err := backend.CheckHintAvailability(ccs.(frontend.CompiledConstraintSystem)); err != nil {
+		return fail(exitArtifact, "%s: %v", *ccsPath, err)
+	}
+	hPK, pk, err := readArtifact(*pkPath, kindProvingKey)
+	if err != nil {
+		return err
+	}
+	if err := checkMatch(h, hPK, *ccsPath, *pkPath); err != nil {
+		return err
+	}
+	if err := readWitness(*witnessPath, circuit, frontend.Public, frontend.Secret); err != nil {
+		return err
+	}
+
+	var proof io.WriterTo
+	switch h.Backend {
+	case backend.GROTH16:
+		proof, err = groth16.Prove(ccs.(frontend.CompiledConstraintSystem), pk.(groth16.ProvingKey), circuit)
+	case backend.PLONK:
+		var srs kzg.SRS
+		if srs, err = readSRS(h.Curve, *srsPath); err != nil {
+			return err
+		}
+		if err = pk.(plonk.ProvingKey).InitKZG(srs); err != nil {
+			return fail(exitArtifact, "%s: %v", *srsPath, err)
+		}
+		proof, err = plonk.Prove(ccs.(frontend.CompiledConstraintSystem), pk.(plonk.ProvingKey), circuit)
+	}
+	if err != nil {
+		return fail(exitProve, "%v", err)
+	}
+
+	h.Kind = kindProof
+	return writeArtifact(*out, h, proof)
+}
+
+func cmdVerify(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("verify", stderr)
+	vkPath := fs.String("vk", "", "path of the verifying key")
+	proofPath := fs.String("proof", "", "path of the proof")
+	witnessPath := fs.String("witness", "", "path of the JSON public witness")
+	srsPath := fs.String("srs", "", "path of the KZG SRS (plonk)")
+	if err := parseFlags(fs, args, "vk", "proof", "witness"); err != nil {
+		return err
+	}
+
+	h, vk, err := readArtifact(*vkPath, kindVerifyingKey)
+	if err != nil {
+		return err
+	}
+	hProof, proof, err := readArtifact(*proofPath, kindProof)
+	if err != nil {
+		return err
+	}
+	if err := checkMatch(h, hProof, *vkPath, *proofPath); err != nil {
+		return err
+	}
+	circuit, err := loadCircuit(h.Circuit)
+	if err != nil {
+		return err
+	}
+	if err := readWitness(*witnessPath, circuit, frontend.Public); err != nil {
+		return err
+	}
+
+	switch h.Backend {
+	case backend.GROTH16:
+		err = groth16.Verify(proof.(groth16.Proof), vk.(groth16.VerifyingKey), circuit)
+	case backend.PLONK:
+		var srs kzg.SRS
+		if srs, err = readSRS(h.Curve, *srsPath); err != nil {
+			return err
+		}
+		if err = vk.(plonk.VerifyingKey).InitKZG(srs); err != nil {
+			return fail(exitArtifact, "%s: %v", *srsPath, err)
+		}
+		err = plonk.Verify(proof.(plonk.Proof), vk.(plonk.VerifyingKey), circuit)
+	}
+	if err != nil {
+		return fail(exitVerify, "%v", err)
+	}
+	fmt.Fprintln(stdout, "valid proof")
+	return nil
+}
+
+func cmdInspect(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("inspect", stderr)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fail(exitUsage, "no artifact to inspect")
+	}
+
+	for _, path := range fs.Args() {
+		f, err := openFile(path)
+		if err != nil {
+			return fail(exitIO, "%v", err)
+		}
+		i, err := inspect(bufio.NewReader(f))
+		f.Close()
+		if err != nil {
+			return fail(exitArtifact, "%s: %v", path, err)
+		}
+		fmt.Fprintf(stdout, "%s: kind=%s curve=%s backend=%s circuit=%s version=%d size=%d digest=%x\n",
+			path, i.Kind, i.Curve, i.Backend, i.Circuit, i.Version, i.Size, i.Digest)
+	}
+	return nil
+}
+
+func cmdHints(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("hints", stderr)
+	ccsPath := fs.String("ccs", "", "path of a compiled constraint system, to list its required hints instead")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *ccsPath == "" {
+		for _, d := range hint.Describe() {
+			fmt.Fprintln(stdout, d)
+		}
+		return nil
+	}
+
+	h, ccs, err := readArtifact(*ccsPath, kindCCS)
+	if err != nil {
+		return err
+	}
+	if _, err := loadCircuit(h.Circuit); err != nil {
+		return err
+	}
+	for _, r := range ccs.(frontend.CompiledConstraintSystem).RequiredHints() {
+		fmt.Fprintf(stdout, "%q id=%#x\n", r.Name, uint64(r.UUID))
+	}
+	if err := backend.CheckHintAvailability(ccs.(frontend.CompiledConstraintSystem)); err != nil {
+		return fail(exitArtifact, "%s: %v", *ccsPath, err)
+	}
+	return nil
+}
+
+// readSRS reads the KZG SRS of curve at path
+func readSRS(curve ecc.ID, path string) (kzg.SRS, error) {
+	if path == "" {
+		return nil, fail(exitUsage, "plonk needs a KZG SRS, see --srs")
+	}
+	f, err := openFile(path)
+	if err != nil {
+		return nil, fail(exitIO, "%v", err)
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	srs := kzg.NewSRS(curve)
+	if _, err := srs.ReadFrom(r); err != nil {
+		if cerr := readToEnd(r); cerr != nil {
+			err = cerr
+		}
+		return nil, fail(exitArtifact, "%s: invalid KZG SRS: %v", path, err)
+	}
+	if err := readToEnd(r); err != nil {
+		return nil, fail(exitArtifact, "%s: %v", path, err)
+	}
+	return srs, nil
+}
+
+// newUnsafeSRS returns a KZG SRS for ccs generated from a known secret, and writes it at path
+func newUnsafeSRS(ccs frontend.CompiledConstraintSystem, path string) (kzg.SRS, error) {
+	if path == "" {
+		return nil, fail(exitUsage, "--unsafe-srs writes the SRS at --srs")
+	}
+	srs, err := test.NewKZGSRS(ccs)
+	if err != nil {
+		return nil, fail(exitSetup, "%v", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fail(exitIO, "%v", err)
+	}
+	w := bufio.NewWriter(f)
+	if _, err = srs.WriteTo(w); err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, fail(exitIO, "%s: %v", path, err)
+	}
+	return srs, nil
+}
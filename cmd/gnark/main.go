@@ -0,0 +1,175 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command gnark compiles circuits, and runs the setup, the prover and the verifier of a proving
+// scheme on files, without writing Go:
+//
+//	gnark compile --circuit cubic --curve bn254 --backend groth16 --out cubic.ccs
+//	gnark setup --ccs cubic.ccs --pk cubic.pk --vk cubic.vk
+//	gnark prove --ccs cubic.ccs --pk cubic.pk --witness witness.json --out cubic.proof
+//	gnark verify --vk cubic.vk --proof cubic.proof --witness public.json
+//	gnark inspect cubic.ccs cubic.pk cubic.vk cubic.proof
+//	gnark hints --ccs cubic.ccs
+//
+// The circuit is one of the examples (cubic, exponentiate, mimc), or a Go plugin (a .so file built
+// with go build -buildmode=plugin) exporting a variable Circuit of type frontend.Circuit. The files
+// written by gnark start with a header recording the circuit, the curve and the backend, such that
+// prove and verify don't need them again (see inspect).
+//
+// The witnesses are JSON files, in the format of witness.ToJSON: the decimal values of the inputs,
+// by visibility and name, for example {"Public": {"Y": "35"}, "Secret": {"x": "3"}}. The public
+// witness given to verify has no "Secret" object.
+//
+// PLONK needs a KZG SRS (--srs), in the binary format of gnark-crypto. For tests, setup
+// --unsafe-srs generates one, from a known secret, and writes it at the path of --srs.
+//
+// setup --max-public-inputs n fails if the circuit has more than n public inputs.
+//
+// hints lists the hint functions gnark provides to the solvers (see hint.Describe): the builtins, and
+// the hints registered by the plugins. With --ccs, it lists the hints the constraint system requires,
+// and fails if some are missing, as prove does before reading the proving key.
+//
+// The files read by gnark (artifacts and SRS) may be split in chunks, for the file stores which limit
+// the size of the objects: the path of the manifest of the chunks (see gnarkio.WriteSplitTo), named
+// manifest.json, stands for the file.
+//
+// The exit code tells the kind of error, see the exit* constants.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// exit codes of the command
+const (
+	exitOK       = 0
+	exitError    = 1 // unexpected error
+	exitUsage    = 2 // invalid command line, unknown circuit, curve or backend
+	exitIO       = 3 // a file can't be read or written
+	exitArtifact = 4 // a file isn't an artifact of the expected kind, curve or backend, or misses hints
+	exitCompile  = 5 // the circuit doesn't compile
+	exitSetup    = 6 // the setup failed
+	exitWitness  = 7 // the witness isn't valid JSON, or doesn't match the inputs of the circuit
+	exitProve    = 8 // the prover failed, for example on a witness which doesn't satisfy the circuit
+	exitVerify   = 9 // the proof is invalid
+)
+
+const usage = `usage: gnark <command> [flags]
+
+commands:
+	compile   compile a circuit to a constraint system (.ccs)
+	setup     run the setup of a constraint system, write the proving and verifying keys
+	prove     prove a witness
+	verify    verify a proof with a public witness
+	inspect   print the kind, curve, backend, circuit, version and digest of artifacts
+	hints     list the hint functions available to the solvers, or required by a constraint system
+
+run gnark <command> -h for the flags of a command
+`
+
+// command runs a command with its arguments
+type command func(args []string, stdout, stderr io.Writer) error
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"compile": cmdCompile,
+		"setup":   cmdSetup,
+		"prove":   cmdProve,
+		"verify":  cmdVerify,
+		"inspect": cmdInspect,
+		"hints":   cmdHints,
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command line args, and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "gnark: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+	if err := cmd(args[1:], stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "gnark %s: %v\n", args[0], err)
+		var e *cliError
+		if errors.As(err, &e) {
+			return e.code
+		}
+		return exitError
+	}
+	return exitOK
+}
+
+// cliError is an error with the exit code of its kind
+type cliError struct {
+	code int
+	err  error
+}
+
+func (e *cliError) Error() string {
+	return e.err.Error()
+}
+
+func (e *cliError) Unwrap() error {
+	return e.err
+}
+
+// fail returns a formatted error, exiting with code
+func fail(code int, format string, a ...interface{}) error {
+	return &cliError{code: code, err: fmt.Errorf(format, a...)}
+}
+
+// newFlagSet returns the flags of the command name, which prints its errors to stderr
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("gnark "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+// parseFlags parses args, and checks that the required flags are set
+func parseFlags(fs *flag.FlagSet, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		return fail(exitUsage, "%v", err)
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	var missing []string
+	for _, name := range required {
+		if !set[name] {
+			missing = append(missing, "--"+name)
+		}
+	}
+	if len(missing) != 0 {
+		sort.Strings(missing)
+		return fail(exitUsage, "missing flags %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
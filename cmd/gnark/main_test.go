@@ -0,0 +1,163 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark/internal/curves"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPipeline compiles the cubic circuit, runs the setup, proves and verifies a witness with the
+// command line, for both backends, and checks the exit codes of the errors
+func TestPipeline(t *testing.T) {
+	for _, b := range []string{"groth16", "plonk"} {
+		t.Run(b, func(t *testing.T) {
+			assert := require.New(t)
+			dir := t.TempDir()
+			path := func(name string) string { return filepath.Join(dir, name) }
+			gnark := func(expected int, args ...string) string {
+				var stdout, stderr bytes.Buffer
+				code := run(args, &stdout, &stderr)
+				assert.Equal(expected, code, "gnark %s: %s", strings.Join(args, " "), stderr.String())
+				return stdout.String()
+			}
+			write := func(name, content string) string {
+				assert.NoError(ioutil.WriteFile(path(name), []byte(content), 0600))
+				return path(name)
+			}
+			var srs []string
+			if b == "plonk" {
+				srs = []string{"--srs", path("cubic.srs")}
+			}
+
+			// x**3 + x + 5 == y
+			witness := write("witness.json", `{"Public": {"Y": "35"}, "Secret": {"x": "3"}}`)
+			public := write("public.json", `{"Public": {"Y": "35"}}`)
+
+			gnark(exitOK, "compile", "--circuit", "cubic", "--curve", "bn254", "--backend", b, "--out", path("cubic.ccs"))
+			gnark(exitOK, append([]string{"setup", "--ccs", path("cubic.ccs"), "--pk", path("cubic.pk"), "--vk", path("cubic.vk"), "--unsafe-srs"}, srs...)...)
+			gnark(exitOK, append([]string{"prove", "--ccs", path("cubic.ccs"), "--pk", path("cubic.pk"), "--witness", witness, "--out", path("cubic.proof")}, srs...)...)
+			out := gnark(exitOK, append([]string{"verify", "--vk", path("cubic.vk"), "--proof", path("cubic.proof"), "--witness", public}, srs...)...)
+			assert.Equal("valid proof\n", out)
+
+			out = gnark(exitOK, "inspect", path("cubic.ccs"), path("cubic.pk"), path("cubic.vk"), path("cubic.proof"))
+			lines := strings.Split(strings.TrimSpace(out), "\n")
+			assert.Len(lines, 4)
+			for i, kind := range []string{"ccs", "pk", "vk", "proof"} {
+				assert.Contains(lines[i], "kind="+kind+" curve=bn254 backend="+b+" circuit=cubic version=1 ")
+				assert.Regexp(`digest=[0-9a-f]{64}$`, lines[i])
+			}
+
+			// the builtin hints are available, cubic doesn't require any
+			assert.Contains(gnark(exitOK, "hints"), "registered=builtin")
+			assert.Equal("", gnark(exitOK, "hints", "--ccs", path("cubic.ccs")))
+
+			// the key and the SRS may be split in chunks, a corrupted chunk is reported
+			split := func(name string) string {
+				data, err := ioutil.ReadFile(path(name))
+				assert.NoError(err)
+				_, err = gnarkio.WriteSplitTo(path(name+".split"), 64, bytes.NewReader(data))
+				assert.NoError(err)
+				return filepath.Join(path(name+".split"), gnarkio.SplitManifestName)
+			}
+			splitPK := split("cubic.pk")
+			var splitSRS []string
+			if b == "plonk" {
+				splitSRS = []string{"--srs", split("cubic.srs")}
+			}
+			gnark(exitOK, append([]string{"prove", "--ccs", path("cubic.ccs"), "--pk", splitPK, "--witness", witness, "--out", path("split.proof")}, splitSRS...)...)
+			gnark(exitOK, append([]string{"verify", "--vk", path("cubic.vk"), "--proof", path("split.proof"), "--witness", public}, splitSRS...)...)
+			assert.NoError(ioutil.WriteFile(filepath.Join(filepath.Dir(splitPK), "chunk-000001.bin"), make([]byte, 64), 0600))
+			var stdout, stderr bytes.Buffer
+			assert.Equal(exitArtifact, run([]string{"inspect", splitPK}, &stdout, &stderr))
+			assert.Contains(stderr.String(), "chunk 1 ")
+
+			// a wrong public input is rejected by the verifier, a wrong secret by the prover
+			gnark(exitVerify, append([]string{"verify", "--vk", path("cubic.vk"), "--proof", path("cubic.proof"), "--witness", write("wrong.json", `{"Public": {"Y": "36"}}`)}, srs...)...)
+			gnark(exitProve, append([]string{"prove", "--ccs", path("cubic.ccs"), "--pk", path("cubic.pk"), "--witness", write("bad.json", `{"Public": {"Y": "35"}, "Secret": {"x": "4"}}`), "--out", path("bad.proof")}, srs...)...)
+
+			// witnesses which don't match the circuit
+			for _, w := range []string{`{"Public": {"Y": "35"}}`, `{"Public": {"Y": "35"}, "Secret": {"x": "3", "z": "1"}}`, `{"Public": {"Y": "a"}, "Secret": {"x": "3"}}`, `{`} {
+				gnark(exitWitness, append([]string{"prove", "--ccs", path("cubic.ccs"), "--pk", path("cubic.pk"), "--witness", write("invalid.json", w), "--out", path("invalid.proof")}, srs...)...)
+			}
+
+			// artifacts of the wrong kind, or which are not artifacts
+			gnark(exitArtifact, append([]string{"verify", "--vk", path("cubic.pk"), "--proof", path("cubic.proof"), "--witness", public}, srs...)...)
+			gnark(exitArtifact, "inspect", witness)
+
+			// usage and I/O errors
+			gnark(exitUsage)
+			gnark(exitUsage, "unknown")
+			gnark(exitUsage, "compile", "--circuit", "cubic")
+			gnark(exitUsage, "compile", "--circuit", "unknown", "--out", path("unknown.ccs"))
+			gnark(exitUsage, "compile", "--circuit", "cubic", "--curve", "unknown", "--out", path("unknown.ccs"))
+			gnark(exitIO, "inspect", path("missing.ccs"))
+		})
+	}
+}
+
+// TestSetupMaxPublicInputs checks that the setup fails when the circuit has more public inputs than
+// --max-public-inputs
+func TestSetupMaxPublicInputs(t *testing.T) {
+	for _, b := range []string{"groth16", "plonk"} {
+		t.Run(b, func(t *testing.T) {
+			assert := require.New(t)
+			dir := t.TempDir()
+			path := func(name string) string { return filepath.Join(dir, name) }
+			gnark := func(expected int, args ...string) {
+				var stdout, stderr bytes.Buffer
+				code := run(args, &stdout, &stderr)
+				assert.Equal(expected, code, "gnark %s: %s", strings.Join(args, " "), stderr.String())
+			}
+			var srs []string
+			if b == "plonk" {
+				srs = []string{"--srs", path("exponentiate.srs"), "--unsafe-srs"}
+			}
+			setup := func(expected int, max string) {
+				gnark(expected, append([]string{"setup", "--ccs", path("exponentiate.ccs"), "--pk", path("exponentiate.pk"), "--vk", path("exponentiate.vk"), "--max-public-inputs", max}, srs...)...)
+			}
+
+			// X and Y are public
+			gnark(exitOK, "compile", "--circuit", "exponentiate", "--backend", b, "--out", path("exponentiate.ccs"))
+			setup(exitSetup, "1")
+			setup(exitOK, "2")
+			setup(exitUsage, "-1")
+		})
+	}
+}
+
+// TestCurves checks that the circuits compile on all the curves supported by gnark, BW6-633 included
+func TestCurves(t *testing.T) {
+	for _, curve := range curves.Implemented() {
+		t.Run(curve.String(), func(t *testing.T) {
+			assert := require.New(t)
+			ccs := filepath.Join(t.TempDir(), "cubic.ccs")
+			var stdout, stderr bytes.Buffer
+			assert.Equal(exitOK, run([]string{"compile", "--circuit", "cubic", "--curve", curve.String(), "--out", ccs}, &stdout, &stderr), stderr.String())
+			stdout.Reset()
+			assert.Equal(exitOK, run([]string{"inspect", ccs}, &stdout, &stderr), stderr.String())
+			assert.Contains(stdout.String(), "kind=ccs curve="+strings.ToLower(curve.String())+" ")
+		})
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gnark
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/consensys/gnark/internal/normalize"
+)
+
+// nbDiffConstraints is the maximum number of constraints listed by DiffConstraintSystems,
+// on each side of the diff
+const nbDiffConstraints = 10
+
+// DiffConstraintSystems writes on w a human readable report of the differences between the
+// constraint systems a and b: the deltas of the number of constraints, coefficients, wires and hints,
+// and the first constraints present in one system but not in the other.
+//
+// The constraints are compared in their canonical form: the internal wires are renumbered in order
+// of first appearance and the terms are sorted (see test.CircuitsEquivalent). If a and b are not of the
+// same type (R1CS or SparseR1CS) or curve, the report only states the mismatch.
+// It returns an error only if writing to w fails.
+func DiffConstraintSystems(a, b frontend.CompiledConstraintSystem, w io.Writer) error {
+	var sbb strings.Builder
+
+	viewA, viewB := normalize.View(a), normalize.View(b)
+	typeA, typeB := systemType(viewA), systemType(viewB)
+
+	fmt.Fprintf(&sbb, "a: %s(%s), b: %s(%s)\n", typeA, a.CurveID(), typeB, b.CurveID())
+	if typeA != typeB || a.CurveID() != b.CurveID() {
+		sbb.WriteString("mismatch: the constraint systems can't be compared\n")
+		_, err := io.WriteString(w, sbb.String())
+		return err
+	}
+
+	writeDelta(&sbb, "constraints", a.GetNbConstraints(), b.GetNbConstraints())
+	writeDelta(&sbb, "coefficients", a.GetNbCoefficients(), b.GetNbCoefficients())
+	removed, added := diffMultisets(viewA.Coefficients, viewB.Coefficients)
+	writeList(&sbb, "+ ", added, len(added))
+	writeList(&sbb, "- ", removed, len(removed))
+
+	internalA, secretA, publicA := a.GetNbVariables()
+	internalB, secretB, publicB := b.GetNbVariables()
+	writeDelta(&sbb, "public wires", publicA, publicB)
+	writeDelta(&sbb, "secret wires", secretA, secretB)
+	writeDelta(&sbb, "internal wires", internalA, internalB)
+
+	writeHintsDelta(&sbb, countHints(viewA.CS().MHints), countHints(viewB.CS().MHints))
+
+	constraintsA, _ := viewA.Canonical()
+	constraintsB, _ := viewB.Canonical()
+	onlyA, onlyB := diffMultisets(constraintsA, constraintsB)
+	fmt.Fprintf(&sbb, "constraints only in a: %d\n", len(onlyA))
+	writeList(&sbb, "", onlyA, nbDiffConstraints)
+	fmt.Fprintf(&sbb, "constraints only in b: %d\n", len(onlyB))
+	writeList(&sbb, "", onlyB, nbDiffConstraints)
+
+	_, err := io.WriteString(w, sbb.String())
+	return err
+}
+
+func systemType(s normalize.System) string {
+	if s.R1CS != nil {
+		return "R1CS"
+	}
+	return "SparseR1CS"
+}
+
+func writeDelta(sbb *strings.Builder, name string, a, b int) {
+	fmt.Fprintf(sbb, "%s: %d -> %d (%+d)\n", name, a, b, b-a)
+}
+
+// writeList writes at most n lines of l, prefixed by a tab and prefix
+func writeList(sbb *strings.Builder, prefix string, l []string, n int) {
+	for i := 0; i < len(l) && i < n; i++ {
+		sbb.WriteByte('\t')
+		sbb.WriteString(prefix)
+		sbb.WriteString(l[i])
+		sbb.WriteByte('\n')
+	}
+	if len(l) > n {
+		fmt.Fprintf(sbb, "\t... (%d more)\n", len(l)-n)
+	}
+}
+
+// diffMultisets returns the elements of a which are not in b, and the elements of b which are not in a,
+// counting duplicates, in sorted order
+func diffMultisets(a, b []string) (onlyA, onlyB []string) {
+	count := make(map[string]int, len(a))
+	for _, s := range a {
+		count[s]++
+	}
+	for _, s := range b {
+		if count[s] > 0 {
+			count[s]--
+		} else {
+			onlyB = append(onlyB, s)
+		}
+	}
+	for _, s := range a {
+		if count[s] > 0 {
+			count[s]--
+			onlyA = append(onlyA, s)
+		}
+	}
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	return
+}
+
+// countHints returns the number of calls of each hint
+func countHints(mHints map[int]compiled.Hint) map[hint.ID]int {
+	res := make(map[hint.ID]int)
+	for wire, h := range mHints {
+		// a hint with several outputs is stored for each of its wires
+		if wire == h.Wires[0] {
+			res[h.ID]++
+		}
+	}
+	return res
+}
+
+func writeHintsDelta(sbb *strings.Builder, a, b map[hint.ID]int) {
+	ids := make([]hint.ID, 0, len(a)+len(b))
+	for id := range a {
+		ids = append(ids, id)
+	}
+	for id := range b {
+		if _, ok := a[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var nbA, nbB int
+	for _, id := range ids {
+		nbA += a[id]
+		nbB += b[id]
+	}
+	writeDelta(sbb, "hints", nbA, nbB)
+
+	// the builtin and registered hints are named in the diff, the others are only known by their ID
+	known, _ := hint.Functions(nil)
+
+	for _, id := range ids {
+		if a[id] == b[id] {
+			continue
+		}
+		name := "unknown"
+		if h, ok := known[id]; ok {
+			name = h.String()
+		}
+		status := "changed"
+		if a[id] == 0 {
+			status = "added"
+		} else if b[id] == 0 {
+			status = "removed"
+		}
+		fmt.Fprintf(sbb, "\t%s %d (%s): %d -> %d\n", status, id, name, a[id], b[id])
+	}
+}
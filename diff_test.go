@@ -0,0 +1,81 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gnark
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+type diffCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *diffCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+// diffModifiedCircuit is diffCircuit, with an additional assertion X != 7
+type diffModifiedCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *diffModifiedCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	api.AssertIsEqual(api.IsZero(api.Sub(circuit.X, 7)), 0)
+	return nil
+}
+
+func TestDiffConstraintSystems(t *testing.T) {
+	assert := require.New(t)
+
+	a, err := frontend.Compile(ecc.BN254, backend.GROTH16, &diffCircuit{})
+	assert.NoError(err)
+	b, err := frontend.Compile(ecc.BN254, backend.GROTH16, &diffModifiedCircuit{})
+	assert.NoError(err)
+
+	var buf bytes.Buffer
+	assert.NoError(DiffConstraintSystems(a, b, &buf))
+	report := buf.String()
+	assert.Contains(report, "constraints: 2 -> 5 (+3)")
+	assert.Contains(report, "coefficients: 4 -> 6 (+2)\n\t+ -7\n\t+ 7\n")
+	assert.Contains(report, "internal wires: 1 -> 3 (+2)")
+	assert.Contains(report, "hints: 0 -> 1 (+1)\n\tadded")
+	assert.Contains(report, "(gnark/invmod): 0 -> 1")
+	assert.Contains(report, "constraints only in a: 0\nconstraints only in b: 3\n")
+
+	// a system doesn't differ from itself
+	buf.Reset()
+	assert.NoError(DiffConstraintSystems(a, a, &buf))
+	assert.Contains(buf.String(), "constraints: 2 -> 2 (+0)\ncoefficients: 4 -> 4 (+0)\n")
+	assert.Contains(buf.String(), "constraints only in a: 0\nconstraints only in b: 0\n")
+
+	// different types and curves
+	c, err := frontend.Compile(ecc.BLS12_381, backend.PLONK, &diffCircuit{})
+	assert.NoError(err)
+	buf.Reset()
+	assert.NoError(DiffConstraintSystems(a, c, &buf))
+	assert.Equal("a: R1CS(bn254), b: SparseR1CS(bls12_381)\nmismatch: the constraint systems can't be compared\n", buf.String())
+}
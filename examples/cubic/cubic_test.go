@@ -17,6 +17,7 @@ package cubic
 import (
 	"testing"
 
+	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/test"
 )
@@ -37,3 +38,20 @@ func TestCubicEquation(t *testing.T) {
 	})
 
 }
+
+func TestCubicProofIndistinguishable(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	var cubicCircuit Circuit
+
+	assert.ProofIndistinguishable(&cubicCircuit, &Circuit{
+		X: frontend.Value(3),
+		Y: frontend.Value(35),
+	}, 3, test.WithCurves(ecc.BN254, ecc.BLS12_381))
+
+	// opt in from ProverSucceeded
+	assert.ProverSucceeded(&cubicCircuit, &Circuit{
+		X: frontend.Value(3),
+		Y: frontend.Value(35),
+	}, test.WithCurves(ecc.BN254), test.WithProofIndistinguishability(2))
+}
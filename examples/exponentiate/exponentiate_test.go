@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/types"
 	"github.com/consensys/gnark/test"
 )
 
@@ -40,3 +41,26 @@ func TestExponentiateGroth16(t *testing.T) {
 	})
 
 }
+
+func TestExponentiateTyped(t *testing.T) {
+
+	assert := test.NewAssert(t)
+
+	var expCircuit TypedCircuit
+
+	witness := TypedCircuit{
+		X: frontend.Value(2),
+		Y: frontend.Value(4095),
+	}
+	witness.E.Assign(12)
+	assert.ProverFailed(&expCircuit, &witness)
+
+	witness.Y = frontend.Value(4096)
+	assert.ProverSucceeded(&expCircuit, &witness)
+
+	// the exponent has 8 bits: 256 isn't truncated to 0
+	witness.E = types.UintN{}
+	witness.E.Assign(256)
+	witness.Y = frontend.Value(1)
+	assert.ProverFailed(&expCircuit, &witness)
+}
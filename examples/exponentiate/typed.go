@@ -0,0 +1,57 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exponentiate
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/types"
+)
+
+// TypedCircuit y == x**e, Circuit written with the typed variables of std/types:
+// the exponent is a bitSize-bit unsigned integer, assigned from a uint64 in the witness
+type TypedCircuit struct {
+	X frontend.Variable `gnark:",public"`
+	Y frontend.Variable `gnark:",public"`
+
+	E types.UintN
+}
+
+// Define declares the circuit's constraints
+// y == x**e
+func (circuit *TypedCircuit) Define(curveID ecc.ID, api frontend.API) error {
+
+	// number of bits of exponent
+	const bitSize = 8
+
+	// range checks the exponent; wrapping it again wouldn't add constraints
+	e := types.NewUintN(api, circuit.E, bitSize)
+	bits := e.ToBinary()
+
+	output := api.Constant(1)
+	for i := 0; i < len(bits); i++ {
+		if i != 0 {
+			output = api.Mul(output, output)
+		}
+		multiply := api.Mul(output, circuit.X)
+		output = bits[len(bits)-1-i].Select(api, multiply, output)
+	}
+
+	api.AssertIsEqual(circuit.Y, output)
+
+	return nil
+}
@@ -49,19 +49,7 @@ func (circuit *Circuit) Define(curveID ecc.ID, api frontend.API) error {
 	const bitSize = 2
 
 	// specify constraints
-	output := api.Constant(1)
-	bits := api.ToBinary(circuit.E, bitSize)
-
-	for i := 0; i < len(bits); i++ {
-		// api.Println(fmt.Sprintf("e[%d]", i), bits[i]) // we may print a variable for testing and / or debugging purposes
-
-		if i != 0 {
-			output = api.Mul(output, output)
-		}
-		multiply := api.Mul(output, circuit.X)
-		output = api.Select(bits[len(bits)-1-i], multiply, output)
-
-	}
+	output := api.Exp(circuit.X, circuit.E, bitSize)
 
 	api.AssertIsEqual(circuit.Y, output)
 
@@ -0,0 +1,79 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Command wasm exposes the gnark Groth16 verifier to JavaScript.
+//
+// Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o verifier.wasm ./examples/wasm
+//
+// and load it with the wasm_exec.js glue shipped with the Go distribution. Once the module is
+// running, it registers a global function:
+//
+//	newGroth16Verifier(vk: Uint8Array) -> { verify(proof: Uint8Array, publicInputs: string[]) }
+//
+// where vk is written with VerifyingKey.WriteCompactTo, proof with Proof.WriteTo or Proof.WriteRawTo,
+// and publicInputs are base 10 or 0x-prefixed hexadecimal strings. verify returns null when the proof is
+// valid, and an error message otherwise.
+//
+// See README.md for artifact size and verification latency.
+package main
+
+import (
+	"errors"
+	"math/big"
+	"syscall/js"
+
+	"github.com/consensys/gnark/backend/groth16"
+)
+
+func main() {
+	js.Global().Set("newGroth16Verifier", js.FuncOf(newVerifier))
+	select {}
+}
+
+func newVerifier(this js.Value, args []js.Value) interface{} {
+	if len(args) != 1 {
+		panic("newGroth16Verifier expects 1 argument: vk")
+	}
+	verifier, err := groth16.NewVerifier(bytesFromJS(args[0]))
+	if err != nil {
+		panic(err.Error())
+	}
+
+	verify := func(this js.Value, args []js.Value) interface{} {
+		if len(args) != 2 {
+			return "verify expects 2 arguments: proof, publicInputs"
+		}
+		publicInputs, err := publicInputsFromJS(args[1])
+		if err != nil {
+			return err.Error()
+		}
+		if err := verifier.Verify(bytesFromJS(args[0]), publicInputs); err != nil {
+			return err.Error()
+		}
+		return nil
+	}
+
+	return map[string]interface{}{
+		"verify": js.FuncOf(verify),
+	}
+}
+
+func bytesFromJS(v js.Value) []byte {
+	b := make([]byte, v.Get("length").Int())
+	js.CopyBytesToGo(b, v)
+	return b
+}
+
+func publicInputsFromJS(v js.Value) ([]*big.Int, error) {
+	res := make([]*big.Int, v.Length())
+	for i := 0; i < len(res); i++ {
+		n, ok := new(big.Int).SetString(v.Index(i).String(), 0)
+		if !ok {
+			return nil, errors.New("invalid public input " + v.Index(i).String())
+		}
+		res[i] = n
+	}
+	return res, nil
+}
@@ -0,0 +1,10 @@
+//go:build !(js && wasm)
+// +build !js !wasm
+
+package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("this example must be built with GOOS=js GOARCH=wasm, see README.md")
+}
@@ -16,7 +16,11 @@ limitations under the License.
 
 package frontend
 
-import "github.com/consensys/gnark/backend/hint"
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/backend/hint"
+)
 
 // API represents the available functions to circuit developers
 type API interface {
@@ -35,6 +39,30 @@ type API interface {
 	// Mul returns res = i1 * i2 * ... in
 	Mul(i1, i2 interface{}, in ...interface{}) Variable
 
+	// Sum returns res = vs[0] + vs[1] + ... + vs[n-1], or 0 if vs is empty.
+	// It doesn't add any constraint (the result is a linear expression); on PLONK, the linear
+	// expression is split in a chain of addition gates when used, see constraintSystem.Sum
+	Sum(vs ...Variable) Variable
+
+	// NewLinearCombination returns an empty sum, to which terms are added one at a time.
+	// Unlike acc = Add(acc, term) in a loop, which copies and sorts acc at each step, the terms
+	// are sorted and collapsed once, when the sum is committed
+	NewLinearCombination() LinearCombination
+
+	// Product returns res = vs[0] * vs[1] * ... * vs[n-1], or 1 if vs is empty.
+	// Constants are folded and the variables are multiplied in a balanced tree
+	Product(vs ...Variable) Variable
+
+	// Exp returns base**exponent, the exponent being constrained to fit on nbBits bits (nbBits must be in
+	// [1, fr.Bits - 1], so that the decomposition of the exponent is unique). It costs about 3 constraints
+	// per bit, 2 if base is a constant, plus the range check of the exponent; a constant exponent is
+	// ExpConstant
+	Exp(base, exponent Variable, nbBits int) Variable
+
+	// ExpConstant returns base**e for a constant e >= 0 (base**0 == 1, for base == 0 too). It costs
+	// len(e) - 1 squarings and popcount(e) - 1 multiplications
+	ExpConstant(base Variable, e *big.Int) Variable
+
 	// DivUnchecked returns i1 / i2 . if i1 == i2 == 0, returns 0
 	DivUnchecked(i1, i2 interface{}) Variable
 
@@ -42,8 +70,28 @@ type API interface {
 	Div(i1, i2 interface{}) Variable
 
 	// Inverse returns res = 1 / i1
+	//
+	// It constrains i1 * res == 1: if i1 == 0, the constraint system is unsatisfiable
+	// (and Compile fails if i1 is a constant). See InverseOrZero when i1 may be zero.
 	Inverse(i1 interface{}) Variable
 
+	// InverseOrZero returns 1 / i1, or 0 if i1 == 0
+	//
+	// Unlike Inverse, it is satisfiable for i1 == 0, hence safe to use in conditional logic.
+	InverseOrZero(i1 interface{}) Variable
+
+	// BatchInvert returns res[i] = 1 / vs[i]
+	//
+	// It is equivalent to calling Inverse on each element, but the solver computes
+	// all the inverses with a single modular inversion (see hint.BatchInvMod).
+	// If an element is zero, the constraint system is unsatisfiable.
+	BatchInvert(vs []Variable) []Variable
+
+	// BatchInvertOrZero returns res[i] = 1 / vs[i], or 0 if vs[i] == 0
+	//
+	// It is equivalent to calling InverseOrZero on each element, see BatchInvert.
+	BatchInvertOrZero(vs []Variable) []Variable
+
 	// ---------------------------------------------------------------------------------------------
 	// Bit operations
 
@@ -55,8 +103,20 @@ type API interface {
 	ToBinary(i1 interface{}, n ...int) []Variable
 
 	// FromBinary packs b, seen as a fr.Element in little endian
+	//
+	// len(b) can't exceed the bit length of the field: Compile fails otherwise.
+	// Note that the packed value is reduced modulo the field modulus, see FromBinaryChecked
 	FromBinary(b ...Variable) Variable
 
+	// FromBinaryMod packs b, seen as an integer in little endian, reduced modulo the field modulus.
+	// b may have any length
+	FromBinaryMod(b ...Variable) Variable
+
+	// FromBinaryChecked packs b, seen as an integer in little endian, and constrains it to
+	// be less than the field modulus, such that b is the canonical binary representation of the result.
+	// b may have any length, the bits above the field bit length must be 0
+	FromBinaryChecked(b ...Variable) Variable
+
 	// Xor returns a ^ b
 	// a and b must be 0 or 1
 	Xor(a, b Variable) Variable
@@ -91,12 +151,78 @@ type API interface {
 	AssertIsBoolean(i1 interface{})
 
 	// AssertIsLessOrEqual fails if  v > bound
+	//
+	// A constant bound can be of any type accepted by FromInterface; it must not be negative, and
+	// when it is >= q-1 no constraint is added
 	AssertIsLessOrEqual(v Variable, bound interface{})
 
+	// AssertIsLessOrEqualBounded fails if v > bound, or if v or bound don't fit on nbBits bits.
+	// It is cheaper than AssertIsLessOrEqual when nbBits is small; nbBits must be less than
+	// the field bit length minus one
+	AssertIsLessOrEqualBounded(v Variable, bound interface{}, nbBits int)
+
+	// RangeCheck fails if v >= 2**nbBits, and returns the nbBits bits of v in little endian.
+	// nbBits must be in [1, fr.Bits].
+	//
+	// Unlike ToBinary, the constraints are added once per variable: the decompositions of a variable
+	// are recorded, and the next range checks reuse them (a boolean variable isn't decomposed)
+	RangeCheck(v interface{}, nbBits int) []Variable
+
+	// AssertIsPermutation fails if b is not a permutation of a (if they are not equal as multisets).
+	// a and b must have the same length. By default it is a grand product argument with a challenge
+	// hashed in the circuit; see PermutationOption for its soundness, WithPermutationChallenge and
+	// WithPermutationNetwork for the variants
+	AssertIsPermutation(a, b []Variable, opts ...func(opt *PermutationOption) error)
+
+	// AssertIsMember fails if v is not an element of set. It costs len(set) - 1 constraints, or a
+	// lookup with PLONK for sets of more than 16 elements
+	AssertIsMember(v Variable, set []big.Int)
+
+	// AssertIsNotMember fails if v is an element of set. It costs len(set) constraints
+	AssertIsNotMember(v Variable, set []big.Int)
+
+	// LoopBegin marks the beginning of the iteration of an unrolled loop, until the matching LoopEnd.
+	// It doesn't add any constraint: the compiled constraint system records which constraints each
+	// iteration created, reported by ProfileLoops and ToHTML, and used to detect the repeated
+	// constraint shapes. The loops may be nested:
+	//
+	//	for i := 0; i < n; i++ {
+	//		api.LoopBegin("round", i)
+	//		...
+	//		api.LoopEnd()
+	//	}
+	LoopBegin(label string, iteration int)
+
+	// LoopEnd marks the end of the iteration begun by the last LoopBegin
+	LoopEnd()
+
+	// Tag begins the scope of a tag, until the returned function is called. It doesn't add any constraint:
+	// the compiled constraint system counts the constraints created in each scope, see
+	// CompiledConstraintSystem.ConstraintsPerTag. The label of a tag nested in another one is joined to
+	// its label with "/", so label can't be empty or contain "/":
+	//
+	//	defer api.Tag("sha256")()
+	//	...
+	//	end := api.Tag("compress") // "sha256/compress"
+	//	...
+	//	end()
+	Tag(label string) (end func())
+
 	// Println behaves like fmt.Println but accepts frontend.Variable as parameter
 	// whose value will be resolved at runtime when computed by the solver
 	Println(a ...interface{})
 
+	// MarkOutput names v as an output of the circuit. It doesn't add any constraint:
+	// Simulator.Run returns the value of v, and the compiled constraint system records its linear expression.
+	// The fields of the circuit tagged `gnark:",output"` are marked as outputs once Define returns
+	MarkOutput(v Variable, name string)
+
+	// SetOutput constrains output, a public input tagged `gnark:",public,output"`, to be equal to v. The
+	// solver computes output from v instead of reading it from the witness: the full witness may leave
+	// it unassigned, and its value is returned after solving (see backend.WithPublicOutputs), for the
+	// public witness of the verifier. Each output must be set exactly once.
+	SetOutput(output, v Variable)
+
 	// Constant returns a frontend.Variable representing a known value at compile time
 	Constant(input interface{}) Variable
 
@@ -108,5 +234,49 @@ type API interface {
 	// this doesn't add any constraint to the newly created wire
 	// from the backend point of view, it's equivalent to a user-supplied witness
 	// except, the solver is going to assign it a value, not the caller
+	//
+	// the hintID is derived from the Go name of f (see hint.UUID), which changes when f is refactored;
+	// NewAnnotatedHint with hint.NewFixedHintNamed records a stable ID
 	NewHint(f hint.Function, inputs ...interface{}) Variable
+
+	// NewAnnotatedHint is like NewHint, for a hint function which may have several outputs.
+	// It returns f.NbOutputs() variables (len(inputs) if f.NbOutputs() == -1);
+	// if f.NbInputs() >= 0, len(inputs) must match it.
+	// The inputs of type hint.Static are not variables but static parameters of f, which must then be
+	// a hint.FunctionWithStatic: they are recorded in the constraint system and given to f in order
+	NewAnnotatedHint(f hint.AnnotatedFunction, inputs ...interface{}) []Variable
+}
+
+// LinearCombination accumulates the terms of a sum, see API.NewLinearCombination
+type LinearCombination interface {
+	// Add adds term (a Variable or a constant) to the sum
+	Add(term interface{})
+
+	// Commit returns the sum of the terms added so far. It doesn't add any constraint
+	// (the result is a linear expression), and more terms may be added afterwards
+	Commit() Variable
+}
+
+// TableID identifies a lookup table, see Lookuper
+type TableID int
+
+// Lookuper is implemented by the API of the backends supporting lookup arguments (PLONK, through plookup).
+//
+// A lookup costs a single constraint, regardless of the table size; the tables
+// are committed once, in the setup. For example, an 8-bit range check is a lookup in
+// the table [0, 1, ..., 255].
+//
+// Compiling a circuit which uses lookups for Groth16 returns an error.
+// The API is obtained by type assertion in Define:
+//
+//	if lk, ok := api.(frontend.Lookuper); ok {
+//		bytes := lk.AddTable("bytes", entries)
+//		lk.Lookup(bytes, circuit.X)
+//	}
+type Lookuper interface {
+	// AddTable adds a lookup table to the circuit and returns its ID
+	AddTable(name string, entries []big.Int) TableID
+
+	// Lookup fails if v is not an entry of the table
+	Lookup(table TableID, v Variable)
 }
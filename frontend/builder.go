@@ -0,0 +1,120 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/internal/backend/compiled"
+)
+
+// Builder constructs a circuit programmatically, without a circuit structure: the inputs are
+// allocated with AddPublicVariable and AddSecretVariable, and the constraints are added with the
+// embedded API, as in Circuit.Define.
+//
+// The public (resp. secret) inputs are ordered as they are added, such that the result of Finalize is
+// the same as the one of Compile on a circuit structure declaring the inputs in that order.
+// The witness is given in that order too, see witness.FromVector.
+//
+// Unlike Compile, the Builder doesn't recover from the panics of the API.
+type Builder struct {
+	API
+
+	cs        *constraintSystem
+	zkpID     backend.ID
+	opt       CompileOption
+	names     map[string]struct{}
+	err       error
+	finalized bool
+}
+
+// NewBuilder returns a Builder of a circuit for the given curve and proving system.
+// WithSchema and WithVisibilityOverride don't apply to a Builder; the other Compile options do.
+func NewBuilder(curveID ecc.ID, zkpID backend.ID, opts ...func(opt *CompileOption) error) (*Builder, error) {
+	b := &Builder{zkpID: zkpID, names: make(map[string]struct{})}
+	for _, o := range opts {
+		if err := o(&b.opt); err != nil {
+			return nil, err
+		}
+	}
+	if b.opt.schema != nil || b.opt.visibilityOverrides != nil {
+		return nil, errors.New("WithSchema and WithVisibilityOverride can't be used with a Builder")
+	}
+	if compiled.MetadataSize(b.opt.metadata) > compiled.MaxMetadataSize {
+		return nil, ErrMetadataTooLarge
+	}
+
+	cs := newConstraintSystem(curveID, b.opt.capacity)
+	cs.zkpID = zkpID
+	cs.rangeCheckStrategy = b.opt.rangeCheckStrategy
+	cs.hintInputThreshold = b.opt.hintInputs()
+	b.cs = &cs
+	b.API = b.cs
+	return b, nil
+}
+
+// AddPublicVariable allocates a new public input
+func (b *Builder) AddPublicVariable(name string) Variable {
+	b.checkName(name)
+	return b.cs.newPublicVariable(name)
+}
+
+// AddSecretVariable allocates a new secret input
+func (b *Builder) AddSecretVariable(name string) Variable {
+	b.checkName(name)
+	return b.cs.newSecretVariable(name)
+}
+
+// checkName records an error, returned by Finalize, if name is empty or already used
+func (b *Builder) checkName(name string) {
+	if b.err != nil {
+		return
+	}
+	if name == "" {
+		b.err = errors.New("input name can't be empty")
+		return
+	}
+	if _, ok := b.names[name]; ok {
+		b.err = fmt.Errorf("duplicate input name %q", name)
+		return
+	}
+	b.names[name] = struct{}{}
+}
+
+// Inputs returns the names of the public and secret inputs, in the order of the witness
+func (b *Builder) Inputs() (public, secret []string) {
+	// the first public variable is the constant ONE_WIRE
+	public = append(public, b.cs.public.names[1:]...)
+	secret = append(secret, b.cs.secret.names...)
+	return
+}
+
+// Finalize converts the circuit to a CompiledConstraintSystem.
+// The Builder can't be used afterwards.
+func (b *Builder) Finalize() (CompiledConstraintSystem, error) {
+	if b.finalized {
+		return nil, errors.New("builder is already finalized")
+	}
+	b.finalized = true
+	if b.err != nil {
+		return nil, b.err
+	}
+	return compile(b.cs, b.zkpID, &b.opt)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/examples/cubic"
+	"github.com/consensys/gnark/frontend"
+	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	"github.com/stretchr/testify/require"
+)
+
+// buildCubic builds the circuit of examples/cubic with a frontend.Builder
+func buildCubic(curveID ecc.ID, zkpID backend.ID) (frontend.CompiledConstraintSystem, error) {
+	b, err := frontend.NewBuilder(curveID, zkpID)
+	if err != nil {
+		return nil, err
+	}
+	x := b.AddSecretVariable("x")
+	y := b.AddPublicVariable("Y")
+
+	x3 := b.Mul(x, x, x)
+	b.AssertIsEqual(y, b.Add(x3, x, 5))
+
+	return b.Finalize()
+}
+
+func TestBuilder(t *testing.T) {
+	assert := require.New(t)
+
+	for _, b := range backend.Implemented() {
+		ccs, err := frontend.Compile(ecc.BN254, b, &cubic.Circuit{})
+		assert.NoError(err)
+		dynamic, err := buildCubic(ecc.BN254, b)
+		assert.NoError(err)
+
+		// the compiled constraint systems are identical, but for the call sites in the debug info
+		for _, c := range []frontend.CompiledConstraintSystem{ccs, dynamic} {
+			switch c := c.(type) {
+			case *cs_bn254.R1CS:
+				c.DebugInfo = nil
+			case *cs_bn254.SparseR1CS:
+				c.DebugInfo = nil
+			}
+		}
+		var expected, got bytes.Buffer
+		_, err = ccs.WriteTo(&expected)
+		assert.NoError(err)
+		_, err = dynamic.WriteTo(&got)
+		assert.NoError(err)
+		assert.Equal(expected.Bytes(), got.Bytes(), b.String())
+	}
+
+	// a proof of the dynamic circuit verifies against the verifying key of the circuit structure
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &cubic.Circuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	dynamic, err := buildCubic(ecc.BN254, backend.GROTH16)
+	assert.NoError(err)
+	fullWitness, err := witness.FromVector(dynamic, []*big.Int{big.NewInt(35)}, []*big.Int{big.NewInt(3)})
+	assert.NoError(err)
+	publicWitness, err := witness.FromVector(dynamic, []*big.Int{big.NewInt(35)}, nil)
+	assert.NoError(err)
+
+	proof, err := groth16.Prove(dynamic, pk, fullWitness)
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, publicWitness))
+
+	wrongWitness, err := witness.FromVector(dynamic, []*big.Int{big.NewInt(36)}, nil)
+	assert.NoError(err)
+	assert.Error(groth16.Verify(proof, vk, wrongWitness))
+}
+
+func TestBuilderErrors(t *testing.T) {
+	assert := require.New(t)
+
+	b, err := frontend.NewBuilder(ecc.BN254, backend.GROTH16)
+	assert.NoError(err)
+	x := b.AddSecretVariable("x")
+	y := b.AddPublicVariable("x")
+	b.AssertIsEqual(x, y)
+	_, err = b.Finalize()
+	assert.EqualError(err, `duplicate input name "x"`)
+	_, err = b.Finalize()
+	assert.Error(err)
+
+	b, err = frontend.NewBuilder(ecc.BN254, backend.PLONK)
+	assert.NoError(err)
+	x = b.AddSecretVariable("x")
+	y = b.AddPublicVariable("y")
+	b.AddSecretVariable("z")
+	b.AssertIsEqual(x, y)
+	public, secret := b.Inputs()
+	assert.Equal([]string{"y"}, public)
+	assert.Equal([]string{"x", "z"}, secret)
+
+	// z is not constrained
+	_, err = b.Finalize()
+	assert.Error(err)
+
+	_, err = frontend.NewBuilder(ecc.BN254, backend.GROTH16, frontend.WithSchema(&frontend.Schema{}))
+	assert.Error(err)
+}
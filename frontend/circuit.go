@@ -16,25 +16,78 @@ limitations under the License.
 
 package frontend
 
-import "github.com/consensys/gnark-crypto/ecc"
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/internal/backend/compiled"
+)
 
 // Circuit must be implemented by user-defined circuits
 //
 // the tag format is as follow:
-// 		type MyCircuit struct {
-// 			Y frontend.Variable `gnark:"name,option"`
-// 		}
+//
+//	type MyCircuit struct {
+//		Y frontend.Variable `gnark:"name,option"`
+//	}
+//
 // if empty, default resolves to variable name (here "Y") and secret visibility
 // similarly to json or xml struct tags, these are valid:
-// 		`gnark:",public"` or `gnark:"-"`
+//
+//	`gnark:",public"` or `gnark:"-"`
+//
 // using "-" marks the variable as ignored by the Compile method. This can be useful when you need to
 // declare variables as aliases that are already allocated. For example
-// 		type MyCircuit struct {
-// 			Y frontend.Variable `gnark:",public"`
-//			Z frontend.Variable `gnark:"-"`
-// 		}
+//
+//	type MyCircuit struct {
+//		Y frontend.Variable `gnark:",public"`
+//		Z frontend.Variable `gnark:"-"`
+//	}
+//
 // it is then the developer responsability to do circuit.Z = circuit.Y in the Define() method
+//
+// exported fields which aren't Variables (or structs, slices, arrays of such) are ignored; with
+// WithStrictSchema, they must be tagged "-", and the inputs must be tagged "secret" or "public"
 type Circuit interface {
 	// Define declares the circuit's Constraints
 	Define(curveID ecc.ID, api API) error
 }
+
+// Visibility of a circuit input (Secret or Public), see OverrideVisibility
+type Visibility = compiled.Visibility
+
+const (
+	Secret = compiled.Secret
+	Public = compiled.Public
+)
+
+// OverrideVisibility returns circuit, with the visibility of some of its inputs overridden.
+//
+// overrides is keyed by the dotted path of the Go field names (or slice indexes) leading to the
+// input, for example "Inner.X" or "Points.0"; overriding a struct applies to all its inputs.
+// It takes precedence over the gnark struct tags. A path which doesn't exist in the circuit
+// results in an error when the circuit is parsed (by Compile, or when building the witness).
+//
+// The witness of a circuit compiled with overridden visibilities (see WithVisibilityOverride)
+// must be built with the same overrides, for example
+//
+//	proof, err := groth16.Prove(ccs, pk, frontend.OverrideVisibility(&assignment, overrides))
+func OverrideVisibility(circuit Circuit, overrides map[string]Visibility) Circuit {
+	o := make(map[string]Visibility, len(overrides))
+	for k, v := range overrides {
+		o[k] = v
+	}
+	return &overriddenCircuit{circuit: circuit, overrides: o}
+}
+
+type overriddenCircuit struct {
+	circuit   Circuit
+	overrides map[string]Visibility
+}
+
+func (c *overriddenCircuit) Define(curveID ecc.ID, api API) error {
+	return c.circuit.Define(curveID, api)
+}
+
+// OverriddenVisibility implements parser.VisibilityOverrider
+func (c *overriddenCircuit) OverriddenVisibility() (interface{}, map[string]compiled.Visibility) {
+	return c.circuit, c.overrides
+}
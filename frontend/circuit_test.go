@@ -0,0 +1,90 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+type visibilityInner struct {
+	X, Y frontend.Variable
+}
+
+type visibilityCircuit struct {
+	Inner visibilityInner
+	Z     frontend.Variable `gnark:",public"`
+}
+
+func (circuit *visibilityCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.Inner.X, circuit.Inner.Y), circuit.Z)
+	return nil
+}
+
+func TestVisibilityOverride(t *testing.T) {
+	assert := require.New(t)
+
+	for _, tc := range []struct {
+		overrides      map[string]frontend.Visibility
+		nbPublicInputs int
+	}{
+		{nil, 1},
+		{map[string]frontend.Visibility{"Inner": frontend.Public}, 3},
+		{map[string]frontend.Visibility{"Inner.Y": frontend.Public, "Z": frontend.Secret}, 1},
+	} {
+		ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &visibilityCircuit{}, frontend.WithVisibilityOverride(tc.overrides))
+		assert.NoError(err)
+
+		_, _, nbPublic := ccs.GetNbVariables()
+		assert.Equal(tc.nbPublicInputs+1, nbPublic, "%v", tc.overrides) // + the ONE_WIRE
+
+		pk, vk, err := groth16.Setup(ccs)
+		assert.NoError(err)
+		assert.Equal(tc.nbPublicInputs, vk.NbPublicWitness())
+
+		assignment := visibilityCircuit{
+			Inner: visibilityInner{X: frontend.Value(3), Y: frontend.Value(5)},
+			Z:     frontend.Value(15),
+		}
+
+		proof, err := groth16.Prove(ccs, pk, frontend.OverrideVisibility(&assignment, tc.overrides))
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, frontend.OverrideVisibility(&assignment, tc.overrides)))
+
+		if tc.overrides != nil {
+			// the struct alone doesn't match the compiled system
+			assert.Error(groth16.Verify(proof, vk, &assignment))
+		}
+	}
+}
+
+func TestVisibilityOverrideInvalid(t *testing.T) {
+	for _, overrides := range []map[string]frontend.Visibility{
+		{"W": frontend.Public},
+		{"Inner.W": frontend.Public},
+		{"Inner_X": frontend.Public},
+		{"Z": frontend.Visibility(42)},
+	} {
+		_, err := frontend.Compile(ecc.BN254, backend.GROTH16, &visibilityCircuit{}, frontend.WithVisibilityOverride(overrides))
+		require.Error(t, err, "%v", overrides)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// hashChain hashes x n times with MiMC, checking that each hash is not zero (the IsZero hint is the
+// most expensive step of the solver)
+func hashChain(curveID ecc.ID, api frontend.API, x frontend.Variable, n int) frontend.Variable {
+	for i := 0; i < n; i++ {
+		h, _ := mimc.NewMiMC("seed", curveID, api)
+		h.Write(x)
+		x = h.Sum()
+		api.AssertIsEqual(api.IsZero(x), 0)
+	}
+	return x
+}
+
+// hashChainCircuit asserts that hashChain(X, 64) == Y
+type hashChainCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *hashChainCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(hashChain(curveID, api, circuit.X, 64), circuit.Y)
+	return nil
+}
+
+// hashChainOutput computes hashChain(X, 64), see Simulator
+type hashChainOutput struct {
+	X frontend.Variable
+}
+
+func (circuit *hashChainOutput) Define(curveID ecc.ID, api frontend.API) error {
+	api.MarkOutput(hashChain(curveID, api, circuit.X, 64), "Y")
+	return nil
+}
+
+// TestCompileBoth produces a Groth16 and a PlonK proof of the same assignment, solving the witness once
+func TestCompileBoth(t *testing.T) {
+	assert := require.New(t)
+
+	witness := func(x int) *hashChainCircuit {
+		var input hashChainOutput
+		input.X.Assign(x)
+		outputs, err := frontend.NewSimulator(ecc.BN254).Run(&hashChainOutput{}, &input)
+		assert.NoError(err)
+		var w hashChainCircuit
+		w.X.Assign(x)
+		w.Y.Assign(outputs["Y"])
+		return &w
+	}
+	w, other := witness(42), witness(43)
+
+	r1cs, sparseR1CS, mapping, err := frontend.CompileBoth(ecc.BN254, &hashChainCircuit{})
+	assert.NoError(err)
+
+	// the systems are the ones compiled separately
+	for b, ccs := range map[backend.ID]frontend.CompiledConstraintSystem{backend.GROTH16: r1cs, backend.PLONK: sparseR1CS} {
+		expected, err := frontend.Compile(ecc.BN254, b, &hashChainCircuit{})
+		assert.NoError(err)
+		assert.Equal(expected.GetNbConstraints(), ccs.GetNbConstraints(), b)
+	}
+	internal, secret, public := sparseR1CS.GetNbVariables()
+	assert.Len(mapping, internal+secret+public)
+	assert.Equal(1, mapping[0], "the R1CS starts with the ONE_WIRE")
+	assert.Equal(-1, mapping[len(mapping)-1], "the last wires are created by the PlonK conversion")
+
+	pkGroth16, vkGroth16, err := groth16.Setup(r1cs)
+	assert.NoError(err)
+	srs, err := test.NewKZGSRS(sparseR1CS)
+	assert.NoError(err)
+	pkPlonk, vkPlonk, err := plonk.Setup(sparseR1CS, srs)
+	assert.NoError(err)
+
+	// both proofs from a single solve
+	shared := backend.NewSharedSolution(mapping)
+	proofGroth16, err := groth16.Prove(r1cs, pkGroth16, w, backend.WithSharedSolution(shared))
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proofGroth16, vkGroth16, w))
+	proofPlonk, err := plonk.Prove(sparseR1CS, pkPlonk, w, backend.WithSharedSolution(shared))
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(proofPlonk, vkPlonk, w))
+
+	// the saving: the PlonK solve only solves the wires of the PlonK gates
+	solve := func(opts ...func(*backend.ProverOption) error) time.Duration {
+		start := time.Now()
+		assert.NoError(plonk.IsSolved(sparseR1CS, w, opts...))
+		return time.Since(start)
+	}
+	full, seeded := solve(), solve(backend.WithSharedSolution(shared))
+	t.Logf("PlonK solve: %s, seeded with the R1CS solution: %s", full, seeded)
+
+	// the shared solution must be of the same witness and circuit
+	err = plonk.IsSolved(sparseR1CS, other, backend.WithSharedSolution(shared))
+	assert.True(errors.Is(err, backend.ErrSharedSolutionMismatch), err)
+	err = plonk.IsSolved(sparseR1CS, w, backend.WithSharedSolution(backend.NewSharedSolution(mapping[1:])))
+	assert.NoError(err, "the R1CS wasn't solved, the PlonK solver solves all the wires")
+	wrong := backend.NewSharedSolution(mapping[1:])
+	assert.NoError(groth16.IsSolved(r1cs, w, backend.WithSharedSolution(wrong)))
+	err = plonk.IsSolved(sparseR1CS, w, backend.WithSharedSolution(wrong))
+	assert.True(errors.Is(err, backend.ErrSharedSolutionMismatch), err)
+
+	// the R1CS solve of the other witness replaces the values
+	assert.NoError(groth16.IsSolved(r1cs, other, backend.WithSharedSolution(shared)))
+	assert.NoError(plonk.IsSolved(sparseR1CS, other, backend.WithSharedSolution(shared)))
+}
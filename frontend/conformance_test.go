@@ -0,0 +1,147 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// constantCircuit runs the API call of constantCases[id]; X is assigned to 3 in the witness
+type constantCircuit struct {
+	X  frontend.Variable
+	id int
+}
+
+func (circuit *constantCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	constantCases[circuit.id].define(api, circuit.X)
+	return nil
+}
+
+// verdicts of a conformance case
+const (
+	verdictOK           = "ok"
+	verdictCompileError = "compile error"
+	verdictSolveError   = "solve error"
+)
+
+type constantCase struct {
+	name   string
+	define func(api frontend.API, x frontend.Variable)
+
+	// expected verdict, and number of constraints the call adds when it compiles (-1 if it
+	// depends on the backend)
+	verdict       string
+	nbConstraints int
+}
+
+var rPlusOne = new(big.Int).Add(ecc.BN254.Info().Fr.Modulus(), big.NewInt(1))
+
+var constantCases = []constantCase{
+	{"AssertIsEqual(3, 3)", func(api frontend.API, x frontend.Variable) { api.AssertIsEqual(3, 3) }, verdictOK, 0},
+	{"AssertIsEqual(3, 4)", func(api frontend.API, x frontend.Variable) { api.AssertIsEqual(3, 4) }, verdictCompileError, 0},
+	{"AssertIsEqual(1, r+1)", func(api frontend.API, x frontend.Variable) { api.AssertIsEqual(1, rPlusOne) }, verdictOK, 0},
+	{"AssertIsEqual(Add(1, 2), 3)", func(api frontend.API, x frontend.Variable) { api.AssertIsEqual(api.Add(1, 2), 3) }, verdictOK, 0},
+	{"AssertIsEqual(Mul(2, 2), Constant(5))", func(api frontend.API, x frontend.Variable) { api.AssertIsEqual(api.Mul(2, 2), api.Constant(5)) }, verdictCompileError, 0},
+	{"AssertIsEqual(x, 3)", func(api frontend.API, x frontend.Variable) { api.AssertIsEqual(x, 3) }, verdictOK, 1},
+	{"AssertIsEqual(3, x)", func(api frontend.API, x frontend.Variable) { api.AssertIsEqual(3, x) }, verdictOK, 1},
+	{"AssertIsEqual(x, 4)", func(api frontend.API, x frontend.Variable) { api.AssertIsEqual(x, 4) }, verdictSolveError, 1},
+	{"AssertIsEqual(Sub(x, x), 0)", func(api frontend.API, x frontend.Variable) { api.AssertIsEqual(api.Sub(x, x), 0) }, verdictOK, 1},
+	{"AssertIsDifferent(3, 4)", func(api frontend.API, x frontend.Variable) { api.AssertIsDifferent(3, 4) }, verdictOK, 0},
+	{"AssertIsDifferent(3, 3)", func(api frontend.API, x frontend.Variable) { api.AssertIsDifferent(3, 3) }, verdictCompileError, 0},
+	{"AssertIsBoolean(1)", func(api frontend.API, x frontend.Variable) { api.AssertIsBoolean(1) }, verdictOK, 0},
+	{"AssertIsBoolean(2)", func(api frontend.API, x frontend.Variable) { api.AssertIsBoolean(2) }, verdictCompileError, 0},
+	{"AssertIsLessOrEqual(Constant(3), 4)", func(api frontend.API, x frontend.Variable) { api.AssertIsLessOrEqual(api.Constant(3), 4) }, verdictOK, 0},
+	{"AssertIsLessOrEqual(Constant(5), 4)", func(api frontend.API, x frontend.Variable) { api.AssertIsLessOrEqual(api.Constant(5), 4) }, verdictCompileError, 0},
+	{"AssertIsLessOrEqual(Constant(3), Constant(4))", func(api frontend.API, x frontend.Variable) { api.AssertIsLessOrEqual(api.Constant(3), api.Constant(4)) }, verdictOK, 0},
+	{"AssertIsLessOrEqual(Constant(5), Constant(4))", func(api frontend.API, x frontend.Variable) { api.AssertIsLessOrEqual(api.Constant(5), api.Constant(4)) }, verdictCompileError, 0},
+	{"AssertIsLessOrEqual(x, 4)", func(api frontend.API, x frontend.Variable) { api.AssertIsLessOrEqual(x, 4) }, verdictOK, -1},
+	{"AssertIsLessOrEqual(x, 2)", func(api frontend.API, x frontend.Variable) { api.AssertIsLessOrEqual(x, 2) }, verdictSolveError, -1},
+	{"AssertIsLessOrEqual(Constant(3), x)", func(api frontend.API, x frontend.Variable) { api.AssertIsLessOrEqual(api.Constant(3), x) }, verdictOK, -1},
+	{"AssertIsLessOrEqual(Constant(4), x)", func(api frontend.API, x frontend.Variable) { api.AssertIsLessOrEqual(api.Constant(4), x) }, verdictSolveError, -1},
+	{"Inverse(0)", func(api frontend.API, x frontend.Variable) { api.Inverse(0) }, verdictCompileError, 0},
+	{"Div(x, 0)", func(api frontend.API, x frontend.Variable) { api.Div(x, 0) }, verdictCompileError, 0},
+}
+
+// TestConstantConformance checks that the R1CS and SparseR1CS builders, and the test engine,
+// agree on API calls involving constants
+func TestConstantConformance(t *testing.T) {
+	for id, c := range constantCases {
+		id, c := id, c
+		t.Run(c.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			var witness constantCircuit
+			witness.X.Assign(3)
+
+			// test engine
+			engineVerdict := verdictOK
+			if err := test.IsSolved(&constantCircuit{id: id}, &witness, ecc.BN254); err != nil {
+				engineVerdict = verdictSolveError
+			}
+			if c.verdict == verdictOK {
+				assert.Equal(verdictOK, engineVerdict, "engine")
+			} else {
+				assert.Equal(verdictSolveError, engineVerdict, "engine")
+			}
+
+			for _, b := range backend.Implemented() {
+				verdict, nbConstraints, err := constantVerdict(b, id)
+				assert.Equal(c.verdict, verdict, "%s", b)
+				if verdict == verdictCompileError {
+					assert.Contains(err.Error(), "conformance_test.go", "%s: the error must locate the call", b)
+				}
+				if verdict != verdictCompileError && c.nbConstraints >= 0 {
+					assert.Equal(c.nbConstraints, nbConstraints, "%s", b)
+				}
+			}
+		})
+	}
+}
+
+// constantVerdict compiles and solves constantCases[id] on backend b, and returns the verdict and
+// the number of constraints added by the API call
+func constantVerdict(b backend.ID, id int) (string, int, error) {
+	ccs, err := frontend.Compile(ecc.BN254, b, &constantCircuit{id: id}, frontend.IgnoreUnconstrainedInputs)
+	if err != nil {
+		return verdictCompileError, 0, err
+	}
+
+	var witness constantCircuit
+	witness.X.Assign(3)
+	switch b {
+	case backend.GROTH16:
+		err = groth16.IsSolved(ccs, &witness)
+	case backend.PLONK:
+		err = plonk.IsSolved(ccs, &witness)
+	default:
+		panic(fmt.Sprintf("unknown backend %s", b))
+	}
+	if err != nil {
+		return verdictSolveError, ccs.GetNbConstraints(), err
+	}
+	return verdictOK, ccs.GetNbConstraints(), nil
+}
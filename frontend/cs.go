@@ -18,6 +18,7 @@ package frontend
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"math/big"
 	"sort"
@@ -25,6 +26,7 @@ import (
 	"strings"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/internal/backend/compiled"
 )
@@ -60,12 +62,50 @@ type constraintSystem struct {
 
 	mDebug map[int]int // maps constraint ID to debugInfo id
 
+	outputs []compiled.Output // see MarkOutput
+
+	optionalSecrets []int // indexes of the secret inputs tagged "optional"
+
+	publicOutputs []publicOutput // public inputs tagged "public,output", see SetOutput
+
+	// iterations of the unrolled loops (see LoopBegin), nil if Define doesn't record any
+	loops     *compiled.Loops
+	loopIDs   map[string]int // index of the labels in loops.Labels
+	loopStack []int          // indexes of the open scopes in loops.Scopes
+
+	// scopes of the tags (see Tag), nil if Define doesn't record any
+	tags     *compiled.Tags
+	tagIDs   map[string]int // index of the labels in tags.Labels
+	tagStack []int          // indexes of the open scopes in tags.Scopes
+
+	// lookup tables and lookups (see Lookuper), PLONK only
+	tables       []compiled.LookupTable
+	lookups      []lookup
+	memberTables map[string]TableID // tables of the sets of AssertIsMember, by elements
+
+	// range checks (see WithRangeCheckStrategy): the strategy, the backend it is resolved for (UNKNOWN
+	// for CompileBoth), the number of range checks per strategy which fired, and the table of RangeCheckLookup
+	rangeCheckStrategy RangeCheckStrategy
+	zkpID              backend.ID
+	rangeChecks        map[string]int
+	bytesTable         *TableID
+
 	curveID ecc.ID
+
+	metadata map[string]string // see WithMetadata
+
+	cse *subexpressions // results of the operations, nil unless WithCSE
+
+	// hint inputs reduced to a wire (see WithHintInputThreshold): the number of terms above which they
+	// are, 0 if disabled, and their wires by expression (see subexpressionKey)
+	hintInputThreshold int
+	hintInputWires     map[string]Variable
 }
 
 type variables struct {
 	variables []Variable
-	booleans  map[int]struct{} // keep track of boolean variables (we constrain them once)
+	booleans  map[int]struct{}   // keep track of boolean variables (we constrain them once)
+	bits      map[int][]Variable // keep track of the binary decompositions (we range check once)
 }
 
 type inputs struct {
@@ -80,6 +120,9 @@ func (v *inputs) new(cs *constraintSystem, visibility compiled.Visibility, name
 
 func (v *variables) new(cs *constraintSystem, visibility compiled.Visibility) Variable {
 	idx := len(v.variables)
+	if idx >= maxNbWires || (visibility != compiled.Virtual && cs.nbWires() >= maxNbWires) {
+		panic(ErrTooManyWires)
+	}
 	variable := Variable{visibility: visibility, id: idx, linExp: cs.LinearExpression(compiled.Pack(idx, compiled.CoeffIdOne, visibility))}
 
 	v.variables = append(v.variables, variable)
@@ -99,8 +142,78 @@ type CompiledConstraintSystem interface {
 	CurveID() ecc.ID
 	FrSize() int
 
-	// ToHTML generates a human readable representation of the constraint system
-	ToHTML(w io.Writer) error
+	// GetMetadata returns the metadata given at compile time (see WithMetadata), or nil
+	GetMetadata() map[string]string
+
+	// GetOptionalSecrets returns the indexes, among the secret inputs, of the inputs tagged
+	// "optional" which a witness may omit (they are then assigned 0)
+	GetOptionalSecrets() []int
+
+	// GetPublicOutputs returns the names of the public inputs tagged "public,output", computed by the
+	// solver (see API.SetOutput)
+	GetPublicOutputs() []string
+
+	// GetRangeChecks returns the number of range checks of the circuit per strategy which fired
+	// ("bits", "digits(k)" or "lookup", see WithRangeCheckStrategy), or nil. The AssertIsLessOrEqual
+	// against a constant bound >= q-1 need no constraint, they are counted as "none".
+	GetRangeChecks() map[string]int
+
+	// GetNbEliminatedConstraints returns the number of R1C the elimination of the common subexpressions
+	// didn't add to the circuit (see WithCSE); PlonK gates are counted as the R1C they are split from
+	GetNbEliminatedConstraints() int
+
+	// GetNbReducedHintInputs returns the number of hint input expressions reduced to a wire, see
+	// WithHintInputThreshold
+	GetNbReducedHintInputs() int
+
+	// ConstraintsPerTag returns the number of constraints created in the scopes of each tag (see
+	// API.Tag), by label; they sum to GetNbConstraints, the constraints out of any scope counting
+	// for the empty label. It returns nil if the circuit doesn't record any tag
+	ConstraintsPerTag() map[string]int
+
+	// ReferencedHints returns the IDs and names of the hint functions the constraint system calls;
+	// the solvers reject the other hint functions given to the prover (see backend.WithUnsafeHints)
+	ReferencedHints() map[hint.ID]string
+
+	// RequiredHints returns the hint functions the constraint system calls, as ReferencedHints, sorted by
+	// name then ID; see backend.CheckHintAvailability
+	RequiredHints() []hint.Reference
+
+	// CheckAssignment evaluates every constraint against values, a full assignment of the wires
+	// (public, secret then internal wires; for R1CS, the public wires start with the constant
+	// wire 1), without solving. It returns which constraints are satisfied, and an
+	// *backend.UnsatisfiedAssignmentError counting the unsatisfied ones, if any.
+	CheckAssignment(values []*big.Int) (satisfied []bool, err error)
+
+	// CheckAssignmentUpTo is CheckAssignment, stopping once maxFailures (or a few more, as the
+	// constraints are evaluated in parallel) unsatisfied constraints are found; the constraints
+	// which were not evaluated are reported unsatisfied. maxFailures <= 0 means no limit.
+	CheckAssignmentUpTo(values []*big.Int, maxFailures int) (satisfied []bool, err error)
+
+	// AnalyzeWitness returns the distribution of the values of values, a full assignment of the wires (see
+	// CheckAssignment), per multi exponentiation of the prover, and their estimated cost, see
+	// backend.WitnessAnalysis. It returns backend.ErrWitnessAnalysisUnsupported for PlonK.
+	AnalyzeWitness(values []*big.Int) (*backend.WitnessAnalysis, error)
+
+	// ToHTML generates a human readable representation of the constraint system: a self-contained
+	// page searching and rendering the constraints a page at a time, see WithConstraintRange,
+	// WithHTMLPageSize and WithHTMLTable
+	ToHTML(w io.Writer, opts ...func(opt *ExportOptions) error) error
+
+	// ToJSON writes the constraints of the constraint system in the compact JSON format embedded in
+	// the page of ToHTML, see WithConstraintRange
+	ToJSON(w io.Writer, opts ...func(opt *ExportOptions) error) error
+}
+
+// capacity limits of the constraint system, tests may lower them
+var (
+	maxNbWires  = MaxNbWires
+	maxNbCoeffs = MaxNbCoefficients
+)
+
+// nbWires returns the number of wires allocated so far: public, secret and internal variables
+func (cs *constraintSystem) nbWires() int {
+	return len(cs.public.variables.variables) + len(cs.secret.variables.variables) + len(cs.internal.variables)
 }
 
 // initialCapacity has quite some impact on frontend performance, especially on large circuits size
@@ -132,15 +245,19 @@ func newConstraintSystem(curveID ecc.ID, initialCapacity ...int) constraintSyste
 
 	cs.public.variables.variables = make([]Variable, 0)
 	cs.public.booleans = make(map[int]struct{})
+	cs.public.bits = make(map[int][]Variable)
 
 	cs.secret.variables.variables = make([]Variable, 0)
 	cs.secret.booleans = make(map[int]struct{})
+	cs.secret.bits = make(map[int][]Variable)
 
 	cs.internal.variables = make([]Variable, 0, capacity)
 	cs.internal.booleans = make(map[int]struct{})
+	cs.internal.bits = make(map[int][]Variable)
 
 	cs.virtual.variables = make([]Variable, 0)
 	cs.virtual.booleans = make(map[int]struct{})
+	cs.virtual.bits = make(map[int][]Variable)
 
 	// by default the circuit is given on public wire equal to 1
 	cs.public.variables.variables[0] = cs.newPublicVariable("one")
@@ -159,11 +276,57 @@ func newConstraintSystem(curveID ecc.ID, initialCapacity ...int) constraintSyste
 // from the backend point of view, it's equivalent to a user-supplied witness
 // except, the solver is going to assign it a value, not the caller
 func (cs *constraintSystem) NewHint(f hint.Function, inputs ...interface{}) Variable {
-	// create resulting wire
-	r := cs.newInternalVariable()
+	static, inputs := splitStatic(inputs)
+	if len(static) != 0 {
+		panic(fmt.Sprintf("hint %s doesn't accept static parameters, see hint.FunctionWithStatic", hint.Name(f)))
+	}
+	return cs.newHint(hint.UUID(f), hint.Name(f), 1, inputs, nil)[0]
+}
+
+// NewAnnotatedHint is like NewHint, for a hint function with f.NbOutputs() outputs.
+// The inputs of type hint.Static are static parameters of f, which must be a hint.FunctionWithStatic
+func (cs *constraintSystem) NewAnnotatedHint(f hint.AnnotatedFunction, inputs ...interface{}) []Variable {
+	static, inputs := splitStatic(inputs)
+	if err := hint.CheckStatic(f, len(static)); err != nil {
+		panic(err)
+	}
+	if nIn := f.NbInputs(); nIn >= 0 && nIn != len(inputs) {
+		panic(fmt.Sprintf("hint %s expects %d inputs, got %d", f, nIn, len(inputs)))
+	}
+	nOut := f.NbOutputs()
+	if nOut == -1 {
+		nOut = len(inputs)
+	}
+	if nOut < 1 {
+		panic(fmt.Sprintf("hint %s has no output", f))
+	}
+	return cs.newHint(f.UUID(), f.String(), nOut, inputs, static)
+}
+
+// splitStatic separates the static parameters of a hint (hint.Static) from its inputs, keeping their order
+func splitStatic(inputs []interface{}) (static [][]byte, others []interface{}) {
+	others = inputs[:0:0]
+	for _, in := range inputs {
+		if s, ok := in.(hint.Static); ok {
+			static = append(static, append([]byte(nil), s...))
+			continue
+		}
+		others = append(others, in)
+	}
+	return static, others
+}
+
+func (cs *constraintSystem) newHint(id hint.ID, name string, nbOutputs int, inputs []interface{}, static [][]byte) []Variable {
+	// create resulting wires
+	res := make([]Variable, nbOutputs)
+	wires := make([]int, nbOutputs)
+	for i := range res {
+		res[i] = cs.newInternalVariable()
+		wires[i] = res[i].id
 
-	// mark hint as unconstrained, for now
-	cs.mHintsConstrained[r.id] = false
+		// mark hint as unconstrained, for now
+		cs.mHintsConstrained[res[i].id] = false
+	}
 
 	// now we need to store the linear expressions of the expected input
 	// that will be resolved in the solver
@@ -172,13 +335,36 @@ func (cs *constraintSystem) NewHint(f hint.Function, inputs ...interface{}) Vari
 	// ensure inputs are set and pack them in a []uint64
 	for i, in := range inputs {
 		t := cs.Constant(in)
+		if cs.hintInputThreshold > 0 && len(t.linExp) > cs.hintInputThreshold {
+			t = cs.reduceHintInput(t)
+		}
 		hintInputs[i] = t.linExp.Clone() // TODO @gbotrel check that we need to clone here ?
 	}
 
 	// add the hint to the constraint system
-	cs.mHints[r.id] = compiled.Hint{ID: hint.UUID(f), Inputs: hintInputs}
+	// (the same hint is stored for each of its output wires)
+	h := compiled.Hint{ID: id, Name: name, Inputs: hintInputs, Wires: wires, Static: static}
+	for _, wire := range wires {
+		cs.mHints[wire] = h
+	}
 
-	return r
+	return res
+}
+
+// reduceHintInput returns a new wire constrained to be equal to v, a hint input of more than
+// hintInputThreshold terms, or the wire of a previous hint input with the same expression
+func (cs *constraintSystem) reduceHintInput(v Variable) Variable {
+	key := cs.subexpressionKey(v)
+	if w, ok := cs.hintInputWires[key]; ok {
+		return w
+	}
+	w := cs.newInternalVariable()
+	cs.addConstraint(newR1C(v, cs.one(), w))
+	if cs.hintInputWires == nil {
+		cs.hintInputWires = make(map[string]Variable)
+	}
+	cs.hintInputWires[key] = w
+	return w
 }
 
 // bitLen returns the number of bits needed to represent a fr.Element
@@ -236,19 +422,26 @@ func (cs *constraintSystem) reduce(l compiled.LinearExpression) compiled.LinearE
 		sort.Sort(l)
 	}
 
+	if len(l) == 0 {
+		return l
+	}
+
+	// the terms of a same variable are contiguous, collapse them in l[j]
 	var c big.Int
+	j := 0
 	for i := 1; i < len(l); i++ {
-		pcID, pvID, pVis := l[i-1].Unpack()
+		pcID, pvID, pVis := l[j].Unpack()
 		ccID, cvID, cVis := l[i].Unpack()
 		if pVis == cVis && pvID == cvID {
 			// we have redundancy
 			c.Add(&cs.coeffs[pcID], &cs.coeffs[ccID])
-			l[i-1].SetCoeffID(cs.coeffID(&c))
-			l = append(l[:i], l[i+1:]...)
-			i--
+			l[j].SetCoeffID(cs.coeffID(&c))
+			continue
 		}
+		j++
+		l[j] = l[i]
 	}
-	return l
+	return l[:j+1]
 }
 
 func (cs *constraintSystem) coeffID64(v int64) int {
@@ -257,7 +450,7 @@ func (cs *constraintSystem) coeffID64(v int64) int {
 	} else {
 		var bCopy big.Int
 		bCopy.SetInt64(v)
-		resID := len(cs.coeffs)
+		resID := cs.newCoeffID()
 		cs.coeffs = append(cs.coeffs, bCopy)
 		cs.coeffsIDsInt64[v] = resID
 		return resID
@@ -286,12 +479,20 @@ func (cs *constraintSystem) coeffID(b *big.Int) int {
 	// else add it in the cs.coeffs map and update the cs.coeffsIDs map
 	var bCopy big.Int
 	bCopy.Set(b)
-	resID := len(cs.coeffs)
+	resID := cs.newCoeffID()
 	cs.coeffs = append(cs.coeffs, bCopy)
 	cs.coeffsIDsLarge[key] = resID
 	return resID
 }
 
+// newCoeffID returns the ID of the next coefficient to be appended to cs.coeffs
+func (cs *constraintSystem) newCoeffID() int {
+	if len(cs.coeffs) >= maxNbCoeffs {
+		panic(ErrTooManyCoefficients)
+	}
+	return len(cs.coeffs)
+}
+
 func (cs *constraintSystem) addConstraint(r1c compiled.R1C, debugID ...int) {
 	cs.constraints = append(cs.constraints, r1c)
 	if len(debugID) > 0 {
@@ -322,6 +523,37 @@ func (cs *constraintSystem) newVirtualVariable() Variable {
 	return cs.virtual.new(cs, compiled.Virtual)
 }
 
+// variablesOf returns the set of variables v belongs to
+func (cs *constraintSystem) variablesOf(v Variable) *variables {
+	switch v.visibility {
+	case compiled.Internal:
+		return &cs.internal
+	case compiled.Secret:
+		return &cs.secret.variables
+	case compiled.Public:
+		return &cs.public.variables
+	case compiled.Virtual:
+		return &cs.virtual
+	default:
+		panic("not implemented")
+	}
+}
+
+// isBoolean returns true if v is constrained as a boolean
+func (cs *constraintSystem) isBoolean(v Variable) bool {
+	_, ok := cs.variablesOf(v).booleans[v.id]
+	return ok
+}
+
+// markBits records bits as the binary decomposition of v, if it is shorter than the one
+// recorded so far
+func (cs *constraintSystem) markBits(v Variable, bits []Variable) {
+	set := cs.variablesOf(v)
+	if b, ok := set.bits[v.id]; !ok || len(bits) < len(b) {
+		set.bits[v.id] = bits
+	}
+}
+
 // markBoolean marks the variable as boolean and return true
 // if a constraint was added, false if the variable was already
 // constrained as a boolean
@@ -369,6 +601,10 @@ func (cs *constraintSystem) checkVariables() error {
 	publicConstrained := make([]bool, cptPublic+1)
 	publicConstrained[0] = true
 
+	if cptHints|cptSecret|cptPublic == 0 {
+		return nil // nothing to check, the circuit may have no constraints at all
+	}
+
 	// for each constraint, we check the linear expressions and mark our inputs / hints as constrained
 	processLinearExpression := func(l compiled.LinearExpression) {
 		for _, t := range l {
@@ -409,6 +645,13 @@ func (cs *constraintSystem) checkVariables() error {
 		}
 
 	}
+	for _, l := range cs.lookups {
+		processLinearExpression(l.linExp)
+
+		if cptHints|cptSecret|cptPublic == 0 {
+			return nil // we can stop.
+		}
+	}
 
 	// something is a miss, we build the error string
 	var sbb strings.Builder
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"math/big"
 	"runtime/debug"
+	"strconv"
 
 	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/internal/backend/compiled"
@@ -87,9 +88,11 @@ func (cs *constraintSystem) Mul(i1, i2 interface{}, in ...interface{}) Variable
 
 		// v1 and v2 are both unknown, this is the only case we add a constraint
 		if !v1.isConstant() && !v2.isConstant() {
-			res := cs.newInternalVariable()
-			cs.constraints = append(cs.constraints, newR1C(v1, v2, res))
-			return res
+			return cs.memoize("mul", true, []Variable{v1, v2}, func() []Variable {
+				res := cs.newInternalVariable()
+				cs.constraints = append(cs.constraints, newR1C(v1, v2, res))
+				return []Variable{res}
+			})[0]
 		}
 
 		// v1 and v2 are constants, we multiply big.Int values and return resulting constant
@@ -118,6 +121,108 @@ func (cs *constraintSystem) Mul(i1, i2 interface{}, in ...interface{}) Variable
 	return res
 }
 
+// Sum returns res = vs[0] + vs[1] + ... + vs[n-1]
+//
+// The result is a single linear expression: no constraint is added until it is used multiplicatively.
+//
+// On PLONK, the linear expression is split in a chain of addition gates (see sparseR1CS.split), not in a
+// balanced tree: each addition gate reduces the number of terms by one in both, so they have the same
+// number of gates, and the depth of the gates costs nothing, as the solver and the prover handle them
+// one at a time. The chain also lets split reuse the gates of a prefix already reduced by another sum.
+func (cs *constraintSystem) Sum(vs ...Variable) Variable {
+	switch len(vs) {
+	case 0:
+		return cs.Constant(0)
+	case 1:
+		return vs[0]
+	}
+
+	in := make([]interface{}, len(vs)-2)
+	for i := 2; i < len(vs); i++ {
+		in[i-2] = vs[i]
+	}
+	return cs.Add(vs[0], vs[1], in...)
+}
+
+// NewLinearCombination returns an empty sum, see LinearCombination
+func (cs *constraintSystem) NewLinearCombination() LinearCombination {
+	return &linearCombination{cs: cs}
+}
+
+// linearCombination stores the terms as they are added; they are sorted and
+// collapsed by Commit only
+type linearCombination struct {
+	cs    *constraintSystem
+	terms compiled.LinearExpression
+}
+
+func (lc *linearCombination) Add(term interface{}) {
+	v := lc.cs.Constant(term)
+	lc.terms = append(lc.terms, v.linExp...)
+}
+
+func (lc *linearCombination) Commit() Variable {
+	if len(lc.terms) == 0 {
+		return lc.cs.Constant(0)
+	}
+
+	// lc.terms is kept as is, for the next terms
+	res := Variable{linExp: lc.terms.Clone()}
+	res.linExp = lc.cs.reduce(res.linExp)
+
+	return res
+}
+
+// Product returns res = vs[0] * vs[1] * ... * vs[n-1]
+//
+// The constants are folded first, then the variables are multiplied in a balanced tree.
+func (cs *constraintSystem) Product(vs ...Variable) Variable {
+	switch len(vs) {
+	case 0:
+		return cs.Constant(1)
+	case 1:
+		return vs[0]
+	}
+
+	in := make([]interface{}, len(vs))
+	for i := 0; i < len(vs); i++ {
+		in[i] = vs[i]
+	}
+	vars, _ := cs.toVariables(in...)
+
+	// fold the constants
+	q := cs.curveID.Info().Fr.Modulus()
+	c := big.NewInt(1)
+	level := make([]Variable, 0, len(vars))
+	for _, v := range vars {
+		if v.isConstant() {
+			c.Mul(c, v.constantValue(cs)).Mod(c, q)
+		} else {
+			level = append(level, v)
+		}
+	}
+	if len(level) == 0 {
+		return cs.Constant(c)
+	}
+
+	// balanced multiplication tree
+	for len(level) > 1 {
+		next := make([]Variable, 0, (len(level)+1)/2)
+		for i := 0; i+1 < len(level); i += 2 {
+			next = append(next, cs.Mul(level[i], level[i+1]))
+		}
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+		level = next
+	}
+
+	if c.IsUint64() && c.Uint64() == 1 {
+		return level[0]
+	}
+	return cs.mulConstant(level[0], cs.Constant(c))
+}
+
 func (cs *constraintSystem) mulConstant(v1, constant Variable) Variable {
 	// multiplying a variable by a constant -> we updated the coefficients in the linear expression
 	// leading to that variable
@@ -160,15 +265,100 @@ func (cs *constraintSystem) Inverse(i1 interface{}) Variable {
 		return cs.Constant(c)
 	}
 
-	// allocate resulting variable
-	res := cs.newInternalVariable()
+	return cs.memoize("inverse", false, vars, func() []Variable {
+		// res is computed by the solver such that res = 1/v, or 0 if v == 0; in the
+		// latter case, the constraint v * res == 1 is not satisfied
+		res := cs.NewAnnotatedHint(hint.InvModNamed, vars[0])[0]
+
+		debug := cs.addDebugInfo("inverse", vars[0], "*", res, " == 1")
+		cs.addConstraint(newR1C(vars[0], res, cs.one()), debug)
+
+		return []Variable{res}
+	})[0]
+}
+
+// InverseOrZero returns res = inverse(v), or 0 if v == 0
+func (cs *constraintSystem) InverseOrZero(i1 interface{}) Variable {
+	vars, _ := cs.toVariables(i1)
 
-	debug := cs.addDebugInfo("inverse", vars[0], "*", res, " == 1")
-	cs.addConstraint(newR1C(vars[0], res, cs.one()), debug)
+	if vars[0].isConstant() {
+		c := vars[0].constantValue(cs)
+		c.Mod(c, cs.curveID.Info().Fr.Modulus())
+		if c.Sign() == 0 {
+			return cs.Constant(0)
+		}
+		c.ModInverse(c, cs.curveID.Info().Fr.Modulus())
+		return cs.Constant(c)
+	}
+
+	// m = IsZero(v), and inv is the inverse of v, or 0
+	m, inv := cs.isZero(vars[0])
+
+	// m * inv == 0 // constrain inv to be 0 if v == 0
+	debug := cs.addDebugInfo("inverseOrZero", m, "*", inv, " == 0")
+	cs.addConstraint(newR1C(m, inv, cs.Constant(0)), debug)
+
+	return inv
+}
+
+// BatchInvert returns res[i] = 1 / vs[i]
+func (cs *constraintSystem) BatchInvert(vs []Variable) []Variable {
+	res := make([]Variable, len(vs))
+	vars, idx := cs.batchInverses(vs, res, cs.Inverse)
+	if len(vars) == 0 {
+		return res
+	}
+
+	// the solver computes all the inverses with a single modular inversion; as for Inverse,
+	// each vs[i] * res[i] == 1 is constrained
+	inverses := cs.NewAnnotatedHint(hint.BatchInvMod, vars...)
+	for j, i := range idx {
+		v := vars[j].(Variable)
+		debug := cs.addDebugInfo("batchInvert", v, "*", inverses[j], " == 1")
+		cs.addConstraint(newR1C(v, inverses[j], cs.one()), debug)
+		res[i] = inverses[j]
+	}
+
+	return res
+}
+
+// BatchInvertOrZero returns res[i] = 1 / vs[i], or 0 if vs[i] == 0
+func (cs *constraintSystem) BatchInvertOrZero(vs []Variable) []Variable {
+	res := make([]Variable, len(vs))
+	vars, idx := cs.batchInverses(vs, res, cs.InverseOrZero)
+	if len(vars) == 0 {
+		return res
+	}
+
+	inverses := cs.NewAnnotatedHint(hint.BatchInvMod, vars...)
+	for j, i := range idx {
+		v := vars[j].(Variable)
+
+		// as for InverseOrZero, m = IsZero(v) and m * inv == 0
+		m := cs.zeroMask(v, inverses[j])
+		debug := cs.addDebugInfo("batchInvertOrZero", m, "*", inverses[j], " == 0")
+		cs.addConstraint(newR1C(m, inverses[j], cs.Constant(0)), debug)
+		res[i] = inverses[j]
+	}
 
 	return res
 }
 
+// batchInverses sets res[i] = inverse(vs[i]) for the constants of vs, and returns the other
+// elements with their indexes in vs
+func (cs *constraintSystem) batchInverses(vs []Variable, res []Variable, inverse func(interface{}) Variable) (vars []interface{}, idx []int) {
+	for i := 0; i < len(vs); i++ {
+		v, _ := cs.toVariables(vs[i])
+		if v[0].isConstant() {
+			res[i] = inverse(v[0])
+			continue
+		}
+		vars = append(vars, v[0])
+		idx = append(idx, i)
+	}
+	return vars, idx
+}
+
 // Div returns res = i1 / i2
 func (cs *constraintSystem) Div(i1, i2 interface{}) Variable {
 	vars, _ := cs.toVariables(i1, i2)
@@ -177,13 +367,15 @@ func (cs *constraintSystem) Div(i1, i2 interface{}) Variable {
 	v2 := vars[1]
 
 	if !v2.isConstant() {
-		res := cs.newInternalVariable()
-		debug := cs.addDebugInfo("div", v1, "/", v2, " == ", res)
-		v2Inv := cs.newInternalVariable()
-		// note that here we ensure that v2 can't be 0, but it costs us one extra constraint
-		cs.addConstraint(newR1C(v2, v2Inv, cs.one()), debug)
-		cs.addConstraint(newR1C(v1, v2Inv, res), debug)
-		return res
+		return cs.memoize("div", false, vars, func() []Variable {
+			res := cs.newInternalVariable()
+			debug := cs.addDebugInfo("div", v1, "/", v2, " == ", res)
+			v2Inv := cs.newInternalVariable()
+			// note that here we ensure that v2 can't be 0, but it costs us one extra constraint
+			cs.addConstraint(newR1C(v2, v2Inv, cs.one()), debug)
+			cs.addConstraint(newR1C(v1, v2Inv, res), debug)
+			return []Variable{res}
+		})[0]
 	}
 
 	// v2 is constant
@@ -299,21 +491,34 @@ func (cs *constraintSystem) IsZero(i1 interface{}) Variable {
 		return cs.Constant(0)
 	}
 
-	debug := cs.addDebugInfo("isZero", a)
+	return cs.memoize("isZero", false, vars, func() []Variable {
+		m, _ := cs.isZero(a)
+		return []Variable{m}
+	})[0]
+
+}
 
-	//m * (1 - m) = 0       // constrain m to be 0 or 1
-	// a * m = 0            // constrain m to be 0 if a != 0
-	// _ = inverse(m + a) 	// constrain m to be 1 if a == 0
+// isZero returns m = 1 if a is zero, 0 otherwise, and the hinted inv such that a * inv = 1 - m
+func (cs *constraintSystem) isZero(a Variable) (m, inv Variable) {
+	// inv is computed by the solver such that inv = 1/a, or 0 if a == 0
+	inv = cs.NewAnnotatedHint(hint.InvModNamed, a)[0]
+	return cs.zeroMask(a, inv), inv
+}
 
-	// m is computed by the solver such that m = 1 - a^(modulus - 1)
-	m := cs.NewHint(hint.IsZero, a)
+// zeroMask returns m = 1 if a is zero, 0 otherwise; inv must be computed by the solver
+// such that inv = 1/a, or 0 if a == 0
+func (cs *constraintSystem) zeroMask(a, inv Variable) Variable {
+	debug := cs.addDebugInfo("isZero", a)
+
+	// a * inv = 1 - m 	// constrain m to be 1 if a == 0
+	// a * m = 0        // constrain m to be 0 if a != 0
+	// (m is then necessarily boolean)
+	m := cs.newInternalVariable()
+	cs.addConstraint(newR1C(a, inv, cs.Sub(1, m)), debug)
 	cs.addConstraint(newR1C(a, m, cs.Constant(0)), debug)
 
-	cs.AssertIsBoolean(m)
-	ma := cs.Add(m, a)
-	_ = cs.Inverse(ma)
+	cs.markBoolean(m)
 	return m
-
 }
 
 // ToBinary unpacks a variable in binary,
@@ -344,10 +549,17 @@ func (cs *constraintSystem) ToBinary(i1 interface{}, n ...int) []Variable {
 		return b
 	}
 
+	return cs.memoize("toBinary"+strconv.Itoa(nbBits), false, vars, func() []Variable {
+		return cs.toBinary(a, nbBits)
+	})
+}
+
+// toBinary is ToBinary for a variable a
+func (cs *constraintSystem) toBinary(a Variable, nbBits int) []Variable {
 	// allocate the resulting variables and bit-constraint them
 	b := make([]Variable, nbBits)
 	for i := 0; i < nbBits; i++ {
-		b[i] = cs.NewHint(hint.IthBit, a, i)
+		b[i] = cs.NewAnnotatedHint(hint.IthBitNamed, a, i)[0]
 		cs.AssertIsBoolean(b[i])
 	}
 
@@ -368,6 +580,11 @@ func (cs *constraintSystem) ToBinary(i1 interface{}, n ...int) []Variable {
 
 	// record the constraint Σ (2**i * b[i]) == a
 	cs.addConstraint(newR1C(Σbi, cs.one(), a), debug)
+
+	// the decomposition of a wire may be reused by RangeCheck
+	if a.visibility != compiled.Unset {
+		cs.markBits(a, b)
+	}
 	return b
 
 }
@@ -383,7 +600,7 @@ func (cs *constraintSystem) toBinaryUnsafe(a Variable, nbBits int) []Variable {
 	// allocate the resulting variables and bit-constraint them
 	b := make([]Variable, nbBits)
 	for i := 0; i < nbBits; i++ {
-		b[i] = cs.NewHint(hint.IthBit, a, i)
+		b[i] = cs.NewAnnotatedHint(hint.IthBitNamed, a, i)[0]
 	}
 
 	// here what we do is we add a single constraint where
@@ -408,7 +625,17 @@ func (cs *constraintSystem) toBinaryUnsafe(a Variable, nbBits int) []Variable {
 }
 
 // FromBinary packs b, seen as a fr.Element in little endian
+//
+// len(b) can't exceed the bit length of the field (see FromBinaryMod)
 func (cs *constraintSystem) FromBinary(b ...Variable) Variable {
+	if len(b) > cs.bitLen() {
+		panic(fmt.Sprintf("FromBinary: %d bits exceed the field bit length (%d), see FromBinaryMod and FromBinaryChecked\n%s", len(b), cs.bitLen(), string(debug.Stack())))
+	}
+	return cs.FromBinaryMod(b...)
+}
+
+// FromBinaryMod packs b, seen as an integer in little endian, reduced modulo the field modulus
+func (cs *constraintSystem) FromBinaryMod(b ...Variable) Variable {
 	// ensure inputs are set
 	for i := 0; i < len(b); i++ {
 		b[i].assertIsSet(cs)
@@ -433,6 +660,35 @@ func (cs *constraintSystem) FromBinary(b ...Variable) Variable {
 	return res
 }
 
+// FromBinaryChecked packs b, seen as an integer in little endian, and constrains it to
+// be less than the field modulus
+func (cs *constraintSystem) FromBinaryChecked(b ...Variable) Variable {
+	nbBits := cs.bitLen()
+
+	// the bits above the field bit length must be 0
+	for i := nbBits; i < len(b); i++ {
+		cs.AssertIsEqual(b[i], 0)
+	}
+	if len(b) > nbBits {
+		b = b[:nbBits]
+	}
+	res := cs.FromBinaryMod(b...)
+
+	// Σ (2**i * b[i]) <= modulus - 1, on nbBits bits
+	bits := make([]Variable, nbBits)
+	copy(bits, b)
+	for i := len(b); i < nbBits; i++ {
+		bits[i] = cs.Constant(0)
+	}
+	bound := cs.curveID.Info().Fr.Modulus()
+	bound.Sub(bound, big.NewInt(1))
+
+	debug := cs.addDebugInfo("fromBinaryChecked", res, " <= ", cs.Constant(bound))
+	cs.mustBeLessOrEqBits(bits, *bound, debug)
+
+	return res
+}
+
 // Select if i0 is true, yields i1 else yields i2
 func (cs *constraintSystem) Select(i0, i1, i2 interface{}) Variable {
 	vars, _ := cs.toVariables(i0, i1, i2)
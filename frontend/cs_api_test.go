@@ -17,7 +17,9 @@ limitations under the License.
 package frontend
 
 import (
+	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -38,6 +40,30 @@ func TestPrintln(t *testing.T) {
 	cs.Println(nil, 1, "a", new(big.Int), one)
 }
 
+func TestPrintlnDeterministic(t *testing.T) {
+	type node struct {
+		value int
+		next  *node
+		f     func()
+	}
+	format := func() string {
+		cs := newConstraintSystem(ecc.BN254)
+		list := &node{value: 1, next: &node{value: 2}, f: func() {}}
+		cs.Println(list, []*node{list.next}, map[string]*node{"b": list, "a": list.next})
+		return cs.logs[0].Format
+	}
+
+	// the addresses of the values aren't printed
+	f := format()
+	if f != format() {
+		t.Fatalf("log entry not deterministic: %q", f)
+	}
+	const expected = "&{1 &{2 <nil> <nil>} func()} [&{2 <nil> <nil>}] map[a:&{2 <nil> <nil>} b:&{1 &{2 <nil> <nil>} func()}]\n"
+	if !strings.HasSuffix(f, expected) {
+		t.Fatalf("got %q, expected the suffix %q", f, expected)
+	}
+}
+
 // empty circuits
 type IsBool1 struct{}
 type IsBool2 struct{}
@@ -100,3 +126,180 @@ func (c *IsBool3) Define(curve ecc.ID, cs API) error {
 
 	return nil
 }
+
+// sumProductCircuit asserts that the sum and the product of X are S and P
+type sumProductCircuit struct {
+	sum, product bool
+	X            [1000]Variable
+	S, P         Variable `gnark:",public"`
+}
+
+func (c *sumProductCircuit) Define(curve ecc.ID, cs API) error {
+	if c.sum {
+		cs.AssertIsEqual(cs.Sum(c.X[:]...), c.S)
+	} else {
+		cs.AssertIsEqual(c.S, 0)
+	}
+	if c.product {
+		cs.AssertIsEqual(cs.Product(c.X[:]...), c.P)
+	} else {
+		cs.AssertIsEqual(c.P, 0)
+	}
+	return nil
+}
+
+func TestSumProductNbConstraints(t *testing.T) {
+	// the circuits have an extra assertion (S == 0 or P == 0)
+	for _, tc := range []struct {
+		sum, product  bool
+		b             backend.ID
+		nbConstraints int
+	}{
+		{true, false, backend.GROTH16, 1 + 1},       // a single linear expression
+		{false, true, backend.GROTH16, 999 + 1 + 1}, // 999 multiplications
+		{true, false, backend.PLONK, 999 + 1},       // 1001 terms (with S), asserted in 999 addition gates
+		{false, true, backend.PLONK, 999 + 1 + 1},
+	} {
+		ccs, err := Compile(ecc.BN254, tc.b, &sumProductCircuit{sum: tc.sum, product: tc.product})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ccs.GetNbConstraints() != tc.nbConstraints {
+			t.Fatalf("sum: %t, product: %t, %s: expected %d constraints, got %d", tc.sum, tc.product, tc.b, tc.nbConstraints, ccs.GetNbConstraints())
+		}
+	}
+}
+
+type isZeroCircuit struct {
+	X Variable
+}
+
+func (c *isZeroCircuit) Define(curve ecc.ID, cs API) error {
+	cs.IsZero(c.X)
+	return nil
+}
+
+func TestIsZeroNbConstraints(t *testing.T) {
+	// a * inv == 1 - m and a * m == 0; a single gate each in PLONK
+	for _, b := range []backend.ID{backend.GROTH16, backend.PLONK} {
+		ccs, err := Compile(ecc.BN254, b, &isZeroCircuit{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ccs.GetNbConstraints() != 2 {
+			t.Fatalf("%s: expected 2 constraints, got %d", b, ccs.GetNbConstraints())
+		}
+	}
+
+	// the result is boolean, asserting it doesn't cost a constraint
+	cs := newConstraintSystem(ecc.BN254)
+	m := cs.IsZero(cs.newSecretVariable("x"))
+	nbConstraints := len(cs.constraints)
+	cs.AssertIsBoolean(m)
+	if len(cs.constraints) != nbConstraints {
+		t.Fatal("the result of IsZero should be marked as boolean")
+	}
+}
+
+func TestSumProductEdgeCases(t *testing.T) {
+	cs := newConstraintSystem(ecc.BN254)
+	x := cs.newSecretVariable("x")
+	y := cs.newSecretVariable("y")
+
+	if v := cs.Sum(); !v.isConstant() || v.constantValue(&cs).Sign() != 0 {
+		t.Fatal("empty sum should be 0")
+	}
+	if v := cs.Product(); !v.isConstant() || v.constantValue(&cs).Cmp(big.NewInt(1)) != 0 {
+		t.Fatal("empty product should be 1")
+	}
+	if v := cs.Sum(x); v.id != x.id || v.visibility != x.visibility {
+		t.Fatal("sum of a single variable should return it")
+	}
+	if v := cs.Product(x); v.id != x.id || v.visibility != x.visibility {
+		t.Fatal("product of a single variable should return it")
+	}
+
+	// constants are folded, no constraint is added for them
+	if v := cs.Product(cs.Constant(2), cs.Constant(3), cs.Constant(7)); !v.isConstant() || v.constantValue(&cs).Cmp(big.NewInt(42)) != 0 {
+		t.Fatal("product of constants should be a constant")
+	}
+	nbConstraints := cs.NbConstraints()
+	cs.Product(cs.Constant(2), x, cs.Constant(3), y, cs.Constant(7))
+	if cs.NbConstraints() != nbConstraints+1 {
+		t.Fatal("expected a single constraint")
+	}
+}
+
+func TestLinearCombination(t *testing.T) {
+	cs := newConstraintSystem(ecc.BN254)
+	x := cs.newSecretVariable("x")
+	y := cs.newPublicVariable("y")
+	z := cs.newInternalVariable()
+
+	// duplicated variables, constants and scaled terms, with cancellations
+	terms := []interface{}{
+		x, 3, cs.Mul(y, 5), z, cs.Neg(x), cs.Sub(z, 2), cs.Mul(y, -5), 7, cs.Add(x, y), -8, cs.Mul(z, 3),
+	}
+
+	acc := cs.Constant(terms[0])
+	lc := cs.NewLinearCombination()
+	lc.Add(terms[0])
+	for i := 1; i < len(terms); i++ {
+		acc = cs.Add(acc, terms[i])
+		lc.Add(terms[i])
+
+		// the committed sum is the one of the Add chain, at each step
+		sum := lc.Commit()
+		if len(sum.linExp) != len(acc.linExp) {
+			t.Fatalf("term %d: expected %d terms, got %d", i, len(acc.linExp), len(sum.linExp))
+		}
+		for j := range acc.linExp {
+			if sum.linExp[j] != acc.linExp[j] {
+				t.Fatalf("term %d: linear expressions differ at %d", i, j)
+			}
+		}
+	}
+
+	if v := cs.NewLinearCombination().Commit(); !v.isConstant() || v.constantValue(&cs).Sign() != 0 {
+		t.Fatal("empty linear combination should be 0")
+	}
+}
+
+func BenchmarkLinearCombination(b *testing.B) {
+	accumulate := func(b *testing.B, n int, chain bool) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			cs := newConstraintSystem(ecc.BN254, n)
+			vs := make([]Variable, n)
+			for j := 0; j < n; j++ {
+				vs[j] = cs.newInternalVariable()
+			}
+			b.StartTimer()
+
+			if chain {
+				acc := cs.Constant(0)
+				for j := 0; j < n; j++ {
+					acc = cs.Add(acc, vs[j])
+				}
+				continue
+			}
+			lc := cs.NewLinearCombination()
+			for j := 0; j < n; j++ {
+				lc.Add(vs[j])
+			}
+			_ = lc.Commit()
+		}
+	}
+
+	for _, n := range []int{1000, 10000, 100000} {
+		b.Run(fmt.Sprintf("NewLinearCombination/%d", n), func(b *testing.B) {
+			accumulate(b, n, false)
+		})
+	}
+	// acc = Add(acc, term) is quadratic
+	for _, n := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("Add/%d", n), func(b *testing.B) {
+			accumulate(b, n, true)
+		})
+	}
+}
@@ -31,6 +31,18 @@ func (cs *constraintSystem) AssertIsEqual(i1, i2 interface{}) {
 	l := cs.Constant(i1)
 	o := cs.Constant(i2)
 
+	// the assertion on two constants is checked at compile time, on all backends
+	if l.isConstant() && o.isConstant() {
+		a, b := l.constantValue(cs), o.constantValue(cs)
+		modulus := cs.curveID.Info().Fr.Modulus()
+		a.Mod(a, modulus)
+		b.Mod(b, modulus)
+		if a.Cmp(b) != 0 {
+			panic(fmt.Sprintf("assertIsEqual failed: constant(%s) == constant(%s)\n%s", a.String(), b.String(), string(debug.Stack())))
+		}
+		return
+	}
+
 	if len(l.linExp) > len(o.linExp) {
 		l, o = o, l // maximize number of zeroes in r1cs.A
 	}
@@ -54,6 +66,7 @@ func (cs *constraintSystem) AssertIsBoolean(i1 interface{}) {
 		if !(c.IsUint64() && (c.Uint64() == 0 || c.Uint64() == 1)) {
 			panic(fmt.Sprintf("assertIsBoolean failed: constant(%s)\n%s", c.String(), string(debug.Stack())))
 		}
+		return
 	}
 
 	if v.visibility == compiled.Unset {
@@ -76,7 +89,9 @@ func (cs *constraintSystem) AssertIsBoolean(i1 interface{}) {
 
 // AssertIsLessOrEqual adds assertion in constraint system  (v <= bound)
 //
-// bound can be a constant or a Variable
+// bound can be a constant or a Variable; against a constant bound, the range checks follow the
+// strategy of the constraint system (see WithRangeCheckStrategy). A constant bound must not be
+// negative; if it is 0, v must be 0, and if it is >= q-1, no constraint is added.
 //
 // derived from:
 // https://github.com/zcash/zips/blob/main/protocol/protocol.pdf
@@ -84,68 +99,194 @@ func (cs *constraintSystem) AssertIsLessOrEqual(v Variable, bound interface{}) {
 
 	v.assertIsSet(cs)
 
-	switch b := bound.(type) {
-	case Variable:
+	b, ok := bound.(Variable)
+	if ok {
 		b.assertIsSet(cs)
+	}
+	if ok && !b.isConstant() {
+		if v.isConstant() {
+			// the bits of a constant are canonical if it is reduced
+			c := v.constantValue(cs)
+			v = cs.Constant(c.Mod(c, cs.curveID.Info().Fr.Modulus()))
+		}
 		cs.mustBeLessOrEqVar(v, b)
-	default:
-		cs.mustBeLessOrEqCst(v, FromInterface(b))
+		return
 	}
 
+	// constant bound; the value of a constant Variable is reduced as the field element it is
+	var c big.Int
+	if ok {
+		c = *b.constantValue(cs)
+		c.Mod(&c, cs.curveID.Info().Fr.Modulus())
+	} else {
+		c = FromInterface(bound)
+	}
+	cs.mustBeLessOrEqCst(v, c)
 }
 
-func (cs *constraintSystem) mustBeLessOrEqVar(a, bound Variable) {
-	debug := cs.addDebugInfo("mustBeLessOrEq", a, " <= ", bound)
+// AssertIsLessOrEqualBounded adds assertion in constraint system (v <= bound), where
+// v and bound are constrained to be nbBits-bit integers
+//
+// bound can be a constant or a Variable
+func (cs *constraintSystem) AssertIsLessOrEqualBounded(v Variable, bound interface{}, nbBits int) {
+	v.assertIsSet(cs)
+	if b, ok := bound.(Variable); ok {
+		b.assertIsSet(cs)
+	}
 
-	nbBits := cs.bitLen()
+	// v and bound are less than 2**nbBits <= (q-1)/2, so that bound - v (mod q)
+	// is less than 2**nbBits if and only if v <= bound
+	if nbBits < 1 || nbBits > cs.bitLen()-2 {
+		panic(fmt.Sprintf("AssertIsLessOrEqualBounded: nbBits must be in [1, %d]", cs.bitLen()-2))
+	}
 
-	aBits := cs.toBinaryUnsafe(a, nbBits)
-	boundBits := cs.ToBinary(bound, nbBits)
+	cs.mustBeInRange(v, nbBits)
+	cs.mustBeInRange(bound, nbBits)
+	cs.mustBeInRange(cs.Sub(bound, v), nbBits)
+}
 
-	p := make([]Variable, nbBits+1)
-	p[nbBits] = cs.Constant(1)
+// mustBeInRange ensures a < 2**nbBits
+func (cs *constraintSystem) mustBeInRange(a interface{}, nbBits int) {
+	vars, _ := cs.toVariables(a)
+	if vars[0].isConstant() {
+		c := vars[0].constantValue(cs)
+		c.Mod(c, cs.curveID.Info().Fr.Modulus())
+		if c.BitLen() > nbBits {
+			panic(fmt.Sprintf("AssertIsLessOrEqualBounded: constant(%s) doesn't fit on %d bits, constraint will never be satisfied", c.String(), nbBits))
+		}
+		return
+	}
+	cs.mustBeInRangeStrategy(vars[0], nbBits)
+}
 
-	zero := cs.Constant(0)
+// RangeCheck adds assertion in constraint system (v < 2**nbBits), and returns the nbBits bits of v
+// in little endian
+//
+// The binary decomposition of a variable (by ToBinary or RangeCheck) is recorded: the next range
+// checks of the variable reuse it, such that the constraints are added once. A boolean variable
+// isn't decomposed.
+func (cs *constraintSystem) RangeCheck(i1 interface{}, nbBits int) []Variable {
+	if nbBits < 1 || nbBits > cs.bitLen() {
+		panic(fmt.Sprintf("RangeCheck: nbBits must be in [1, %d]", cs.bitLen()))
+	}
+	vars, _ := cs.toVariables(i1)
+	v := vars[0]
+	if v.isConstant() {
+		c := v.constantValue(cs)
+		c.Mod(c, cs.curveID.Info().Fr.Modulus())
+		if c.BitLen() > nbBits {
+			panic(fmt.Sprintf("RangeCheck: constant(%s) doesn't fit on %d bits, constraint will never be satisfied", c.String(), nbBits))
+		}
+		return cs.ToBinary(c, nbBits)
+	}
+	v.assertIsSet(cs)
 
-	for i := nbBits - 1; i >= 0; i-- {
+	if v.visibility == compiled.Unset {
+		// a linear expression isn't a variable we can keep track of
+		return cs.ToBinary(v, nbBits)
+	}
+
+	bits := make([]Variable, nbBits)
+	for i := 0; i < nbBits; i++ {
+		bits[i] = cs.Constant(0)
+	}
+	if nbBits == 1 || cs.isBoolean(v) {
+		cs.AssertIsBoolean(v)
+		bits[0] = v
+		return bits
+	}
 
-		// if bound[i] == 0
-		// 		p[i] = p[i+1]
-		//		t = p[i+1]
-		// else
-		// 		p[i] = p[i+1] * a[i]
-		//		t = 0
-		v := cs.Mul(p[i+1], aBits[i])
-		p[i] = cs.Select(boundBits[i], v, p[i+1])
+	recorded, ok := cs.variablesOf(v).bits[v.id]
+	if !ok {
+		return cs.ToBinary(v, nbBits)
+	}
+	if len(recorded) > nbBits {
+		// v was decomposed on more bits, the extra ones must be 0
+		for i := nbBits; i < len(recorded); i++ {
+			cs.AssertIsEqual(recorded[i], 0)
+		}
+		recorded = recorded[:nbBits]
+		cs.markBits(v, recorded)
+	}
+	copy(bits, recorded)
+	return bits
+}
 
-		t := cs.Select(boundBits[i], zero, p[i+1])
+func (cs *constraintSystem) mustBeLessOrEqVar(a, bound Variable) {
+	debug := cs.addDebugInfo("mustBeLessOrEq", a, " <= ", bound)
 
-		// (1 - t - ai) * ai == 0
-		l := cs.one()
-		l = cs.Sub(l, t)
-		l = cs.Sub(l, aBits[i])
+	nbBits := cs.bitLen()
 
-		// note if bound[i] == 1, this constraint is (1 - ai) * ai == 0
-		// --> this is a boolean constraint
-		// if bound[i] == 0, t must be 0 or 1, thus ai must be 0 or 1 too
-		cs.markBoolean(aBits[i]) // this does not create a constraint
+	aBits := cs.ToBinary(a, nbBits)
+	boundBits := cs.ToBinary(bound, nbBits)
 
-		cs.addConstraint(newR1C(l, aBits[i], zero), debug)
+	// the decompositions must be canonical: else, if a + q (resp. bound + q) fits on nbBits,
+	// it could be decomposed instead of a (resp. bound)
+	var qMinusOne big.Int
+	qMinusOne.Sub(cs.curveID.Info().Fr.Modulus(), big.NewInt(1))
+	for _, bits := range [][]Variable{aBits, boundBits} {
+		if !bits[0].isConstant() {
+			cs.mustBeLessOrEqBits(bits, qMinusOne, debug)
+		}
+	}
+
+	// borrow chain of bound - a, from the lsb
+	// 	if bound[i] == a[i]
+	// 		borrow = borrow
+	// 	else
+	// 		borrow = a[i]
+	// that is: borrow = (1 - a[i] - bound[i] + 2 * a[i] * bound[i]) * borrow + a[i] - a[i] * bound[i]
+	borrow := cs.Constant(0)
+	for i := 0; i < nbBits; i++ {
+		ab := cs.Mul(aBits[i], boundBits[i])
+		eq := cs.Sub(cs.Add(1, ab, ab), aBits[i], boundBits[i])
+		borrow = cs.Add(cs.Mul(eq, borrow), cs.Sub(aBits[i], ab))
 	}
 
+	// a <= bound if and only if bound - a doesn't borrow
+	cs.addConstraint(newR1C(borrow, cs.one(), cs.Constant(0)), debug)
 }
 
+// mustBeLessOrEqCst ensures a <= bound, see AssertIsLessOrEqual for the semantics of the bound
 func (cs *constraintSystem) mustBeLessOrEqCst(a Variable, bound big.Int) {
 	nbBits := cs.bitLen()
 
-	// ensure the bound is positive, it's bit-len doesn't matter
 	if bound.Sign() == -1 {
-		panic("AssertIsLessOrEqual: bound must be positive")
+		panic(fmt.Sprintf("AssertIsLessOrEqual: bound (%s) must be positive", bound.String()))
 	}
-	if bound.BitLen() > nbBits {
-		panic("AssertIsLessOrEqual: bound is too large, constraint will never be satisfied")
+
+	// any field element is <= q-1
+	var qMinusOne big.Int
+	qMinusOne.Sub(cs.curveID.Info().Fr.Modulus(), big.NewInt(1))
+	if bound.Cmp(&qMinusOne) >= 0 {
+		cs.countRangeCheck(noRangeCheck)
+		return
+	}
+
+	// the assertion on a constant is checked at compile time, on all backends
+	if a.isConstant() {
+		c := a.constantValue(cs)
+		c.Mod(c, cs.curveID.Info().Fr.Modulus())
+		if c.Cmp(&bound) > 0 {
+			panic(fmt.Sprintf("assertIsLessOrEqual failed: constant(%s) <= constant(%s)\n%s", c.String(), bound.String(), string(debug.Stack())))
+		}
+		return
 	}
 
+	if bound.Sign() == 0 {
+		cs.AssertIsEqual(a, 0)
+		return
+	}
+
+	// a <= bound if and only if a < 2**n and bound - a < 2**n, n being the bit length of the bound:
+	// if 2**(n+1) <= q, bound - a (mod q) >= q - 2**n >= 2**n when a > bound
+	if n := bound.BitLen(); n <= nbBits-2 {
+		cs.mustBeInRangeStrategy(a, n)
+		cs.mustBeInRangeStrategy(cs.Sub(bound, a), n)
+		return
+	}
+	cs.countRangeCheck(RangeCheckBits)
+
 	// debug info
 	debug := cs.addDebugInfo("mustBeLessOrEq", a, " <= ", cs.Constant(bound))
 
@@ -153,6 +294,14 @@ func (cs *constraintSystem) mustBeLessOrEqCst(a Variable, bound big.Int) {
 	// (as opposed to ToBinary)
 	aBits := cs.toBinaryUnsafe(a, nbBits)
 
+	cs.mustBeLessOrEqBits(aBits, bound, debug)
+}
+
+// mustBeLessOrEqBits ensures aBits, seen as an integer in little endian, is less or equal to bound
+// and boolean-constrains aBits
+func (cs *constraintSystem) mustBeLessOrEqBits(aBits []Variable, bound big.Int, debug int) {
+	nbBits := len(aBits)
+
 	// t trailing bits in the bound
 	t := 0
 	for i := 0; i < nbBits; i++ {
@@ -182,7 +331,9 @@ func (cs *constraintSystem) mustBeLessOrEqCst(a Variable, bound big.Int) {
 			l = cs.Sub(l, aBits[i])
 
 			cs.addConstraint(newR1C(l, aBits[i], cs.Constant(0)), debug)
-			cs.markBoolean(aBits[i])
+			if aBits[i].visibility != compiled.Unset { // not a constant or a linear expression
+				cs.markBoolean(aBits[i])
+			}
 		} else {
 			cs.AssertIsBoolean(aBits[i])
 		}
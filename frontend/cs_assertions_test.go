@@ -0,0 +1,233 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+
+	cs_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
+	cs_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
+	cs_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	cs_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
+	cs_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+)
+
+// assertionShape is an assertion on the inputs X[0], X[1], ...
+type assertionShape struct {
+	nbInputs int
+	assert   func(api frontend.API, x []frontend.Variable)
+	nbGates  int   // number of PLONK gates
+	valid    []int // satisfying inputs
+	invalid  []int // unsatisfying inputs
+}
+
+var assertionShapes = map[string]assertionShape{
+	"x == 5": {1, func(api frontend.API, x []frontend.Variable) {
+		api.AssertIsEqual(x[0], 5)
+	}, 1, []int{5}, []int{6}},
+	"5 == x": {1, func(api frontend.API, x []frontend.Variable) {
+		api.AssertIsEqual(5, x[0])
+	}, 1, []int{5}, []int{6}},
+	"x + y == 5": {2, func(api frontend.API, x []frontend.Variable) {
+		api.AssertIsEqual(api.Add(x[0], x[1]), 5)
+	}, 1, []int{2, 3}, []int{2, 4}},
+	"2x + 3y == z": {3, func(api frontend.API, x []frontend.Variable) {
+		api.AssertIsEqual(api.Add(api.Mul(x[0], 2), api.Mul(x[1], 3)), x[2])
+	}, 1, []int{1, 2, 8}, []int{1, 2, 9}},
+	"x + y == z + w": {4, func(api frontend.API, x []frontend.Variable) {
+		api.AssertIsEqual(api.Add(x[0], x[1]), api.Add(x[2], x[3]))
+	}, 2, []int{1, 6, 3, 4}, []int{1, 6, 3, 5}},
+	"x + y + z + w == 10": {4, func(api frontend.API, x []frontend.Variable) {
+		api.AssertIsEqual(api.Add(x[0], x[1], x[2], x[3]), 10)
+	}, 2, []int{1, 2, 3, 4}, []int{1, 2, 3, 5}},
+	"x + y == x + z": {3, func(api frontend.API, x []frontend.Variable) {
+		api.AssertIsEqual(api.Add(x[0], x[1]), api.Add(x[0], x[2]))
+	}, 1, []int{1, 2, 2}, []int{1, 2, 3}},
+	"x * y == z + w": {4, func(api frontend.API, x []frontend.Variable) {
+		api.AssertIsEqual(api.Mul(x[0], x[1]), api.Add(x[2], x[3]))
+	}, 2, []int{3, 4, 5, 7}, []int{3, 4, 5, 8}},
+	"x + y is boolean": {2, func(api frontend.API, x []frontend.Variable) {
+		api.AssertIsBoolean(api.Add(x[0], x[1]))
+	}, 2, []int{0, 1}, []int{1, 1}},
+	"x + y + z is boolean": {3, func(api frontend.API, x []frontend.Variable) {
+		api.AssertIsBoolean(api.Add(x[0], x[1], x[2]))
+	}, 3, []int{0, 1, 0}, []int{1, 1, 0}},
+}
+
+// assertionCircuit asserts a shape. The PLONK prover needs a few gates, added by powers of P.
+type assertionCircuit struct {
+	X     []frontend.Variable
+	P     frontend.Variable `gnark:",public"`
+	shape string
+}
+
+func (circuit *assertionCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	p := circuit.P
+	for i := 0; i < 8; i++ {
+		p = api.Mul(p, circuit.P)
+	}
+	if shape, ok := assertionShapes[circuit.shape]; ok {
+		shape.assert(api, circuit.X)
+	}
+	return nil
+}
+
+func assertionWitness(inputs []int) *assertionCircuit {
+	w := &assertionCircuit{X: make([]frontend.Variable, len(inputs))}
+	for i, v := range inputs {
+		w.X[i].Assign(v)
+	}
+	w.P.Assign(2)
+	return w
+}
+
+// TestAssertionGates pins the number of PLONK gates of AssertIsEqual and AssertIsBoolean on
+// linear expressions, and checks their semantics
+func TestAssertionGates(t *testing.T) {
+	padding, err := frontend.Compile(ecc.BN254, backend.PLONK, &assertionCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, shape := range assertionShapes {
+		circuit := &assertionCircuit{X: make([]frontend.Variable, shape.nbInputs), shape: name}
+
+		ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, circuit)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if nbGates := ccs.GetNbConstraints() - padding.GetNbConstraints(); nbGates != shape.nbGates {
+			t.Fatalf("%s: expected %d gates, got %d", name, shape.nbGates, nbGates)
+		}
+
+		// the assert helper caches the compiled circuits by type, hence a new helper per shape
+		assert := test.NewAssert(t)
+		opts := []func(opt *test.TestingOption) error{test.WithCurves(ecc.BN254), test.WithBackends(backend.PLONK)}
+		assert.ProverSucceeded(circuit, assertionWitness(shape.valid), opts...)
+		assert.ProverFailed(circuit, assertionWitness(shape.invalid), opts...)
+	}
+}
+
+// variableBoundCircuit asserts A <= Bound, Bound being a variable
+type variableBoundCircuit struct {
+	A     frontend.Variable
+	Bound frontend.Variable `gnark:",public"`
+}
+
+func (circuit *variableBoundCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsLessOrEqual(circuit.A, circuit.Bound)
+	return nil
+}
+
+// nonCanonicalBit behaves like hint.IthBit, except it decomposes x + q instead of x,
+// when x is less than 4
+func nonCanonicalBit(curveID ecc.ID, inputs []*big.Int, result *big.Int) error {
+	x := new(big.Int).Set(inputs[0])
+	if x.Cmp(big.NewInt(4)) == -1 {
+		x.Add(x, curveID.Info().Fr.Modulus())
+	}
+	result.SetUint64(uint64(x.Bit(int(inputs[1].Uint64()))))
+	return nil
+}
+
+func mHints(ccs frontend.CompiledConstraintSystem) map[int]compiled.Hint {
+	switch c := ccs.(type) {
+	case *cs_bn254.R1CS:
+		return c.MHints
+	case *cs_bls12377.R1CS:
+		return c.MHints
+	case *cs_bls12381.R1CS:
+		return c.MHints
+	case *cs_bw6761.R1CS:
+		return c.MHints
+	case *cs_bw6633.R1CS:
+		return c.MHints
+	case *cs_bls24315.R1CS:
+		return c.MHints
+	case *cs_bn254.SparseR1CS:
+		return c.MHints
+	case *cs_bls12377.SparseR1CS:
+		return c.MHints
+	case *cs_bls12381.SparseR1CS:
+		return c.MHints
+	case *cs_bw6761.SparseR1CS:
+		return c.MHints
+	case *cs_bw6633.SparseR1CS:
+		return c.MHints
+	case *cs_bls24315.SparseR1CS:
+		return c.MHints
+	default:
+		panic("unrecognized constraint system type")
+	}
+}
+
+// TestAssertIsLessOrEqualNonCanonical ensures a malicious prover can't prove 5 <= 3
+// by decomposing the bound as 3 + q, which fits on the field bit length on all curves
+func TestAssertIsLessOrEqualNonCanonical(t *testing.T) {
+	var witness variableBoundCircuit
+	witness.A.Assign(5)
+	witness.Bound.Assign(3)
+
+	for _, curve := range ecc.Implemented() {
+		for _, b := range []backend.ID{backend.GROTH16, backend.PLONK} {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curve, b, &variableBoundCircuit{})
+			assert.NoError(err)
+
+			// replace the bit decomposition hints by the malicious one
+			replaced := 0
+			hints := mHints(ccs)
+			for vID, h := range hints {
+				if h.ID == hint.IthBitNamed.UUID() {
+					h.ID = hint.UUID(nonCanonicalBit)
+					hints[vID] = h
+					replaced++
+				}
+			}
+			assert.True(replaced > 0, "%s(%s)", b, curve)
+
+			opt := backend.WithHints(nonCanonicalBit)
+			switch b {
+			case backend.GROTH16:
+				pk, err := groth16.DummySetup(ccs)
+				assert.NoError(err)
+				_, err = groth16.Prove(ccs, pk, &witness, opt)
+				assert.Error(err, "%s(%s)", b, curve)
+			case backend.PLONK:
+				srs, err := test.NewKZGSRS(ccs)
+				assert.NoError(err)
+				pk, _, err := plonk.Setup(ccs, srs)
+				assert.NoError(err)
+				_, err = plonk.Prove(ccs, pk, &witness, opt)
+				assert.Error(err, "%s(%s)", b, curve)
+			}
+		}
+	}
+}
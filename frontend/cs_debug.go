@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -71,6 +72,30 @@ func (cs *constraintSystem) Println(a ...interface{}) {
 	cs.logs = append(cs.logs, log)
 }
 
+// MarkOutput records the linear expression of v as an output of the circuit, resolved by the solver.
+// It doesn't add any constraint.
+func (cs *constraintSystem) MarkOutput(v Variable, name string) {
+	v.assertIsSet(cs)
+	for i := 0; i < len(cs.outputs); i++ {
+		if cs.outputs[i].Name == name {
+			panic(fmt.Sprintf("duplicate output %q", name))
+		}
+	}
+	cs.outputs = append(cs.outputs, compiled.Output{Name: name, Value: v.linExp.Clone()})
+}
+
+// markOutputFields marks the fields of the circuit tagged "output" as outputs, once Define has set them
+func (cs *constraintSystem) markOutputFields(circuit Circuit) error {
+	return parser.VisitOutputs(circuit, func(visibility compiled.Visibility, name string, tValue reflect.Value) error {
+		v := tValue.Interface().(Variable)
+		if len(v.linExp) == 0 {
+			return fmt.Errorf("output %s is not set by Define", name)
+		}
+		cs.MarkOutput(v, name)
+		return nil
+	}, reflect.TypeOf(Variable{}))
+}
+
 func printArg(log *compiled.LogEntry, sbb *strings.Builder, a interface{}) {
 
 	count := 0
@@ -81,9 +106,9 @@ func printArg(log *compiled.LogEntry, sbb *strings.Builder, a interface{}) {
 	// ignoring error, counter() always return nil
 	_ = parser.Visit(a, "", compiled.Unset, counter, reflect.TypeOf(Variable{}))
 
-	// no variables in nested struct, we use fmt std print function
+	// no variables in nested struct, we print it like fmt.Sprint
 	if count == 0 {
-		sbb.WriteString(fmt.Sprint(a))
+		writeValue(sbb, reflect.ValueOf(a), 0)
 		return
 	}
 
@@ -110,6 +135,88 @@ func printArg(log *compiled.LogEntry, sbb *strings.Builder, a interface{}) {
 	sbb.WriteByte('}')
 }
 
+// maxPrintDepth is the number of pointers writeValue follows, it prints the type of the value beyond
+const maxPrintDepth = 8
+
+// writeValue writes v like fmt.Sprint, except that it writes the values pointers point to, instead of their
+// addresses, and the types of functions and channels: the log entries, and so the compiled constraint
+// system, don't depend on the addresses of the values printed. The keys of maps are sorted by their
+// formatted value.
+func writeValue(sbb *strings.Builder, v reflect.Value, depth int) {
+	if !v.IsValid() {
+		sbb.WriteString("<nil>")
+		return
+	}
+	if v.CanInterface() {
+		switch x := v.Interface().(type) {
+		case fmt.Stringer, error:
+			sbb.WriteString(fmt.Sprint(x))
+			return
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			sbb.WriteString("<nil>")
+			return
+		}
+		if depth >= maxPrintDepth {
+			sbb.WriteString(v.Type().String())
+			return
+		}
+		sbb.WriteByte('&')
+		writeValue(sbb, v.Elem(), depth+1)
+	case reflect.Interface:
+		if v.IsNil() {
+			sbb.WriteString("<nil>")
+			return
+		}
+		writeValue(sbb, v.Elem(), depth)
+	case reflect.Struct:
+		sbb.WriteByte('{')
+		for i := 0; i < v.NumField(); i++ {
+			if i > 0 {
+				sbb.WriteByte(' ')
+			}
+			writeValue(sbb, v.Field(i), depth)
+		}
+		sbb.WriteByte('}')
+	case reflect.Slice, reflect.Array:
+		sbb.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				sbb.WriteByte(' ')
+			}
+			writeValue(sbb, v.Index(i), depth)
+		}
+		sbb.WriteByte(']')
+	case reflect.Map:
+		entries := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			var entry strings.Builder
+			writeValue(&entry, iter.Key(), depth)
+			entry.WriteByte(':')
+			writeValue(&entry, iter.Value(), depth)
+			entries = append(entries, entry.String())
+		}
+		sort.Strings(entries)
+		sbb.WriteString("map[")
+		sbb.WriteString(strings.Join(entries, " "))
+		sbb.WriteByte(']')
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if v.IsNil() {
+			sbb.WriteString("<nil>")
+			return
+		}
+		sbb.WriteString(v.Type().String())
+	default:
+		// fmt prints the value v holds, even if it is an unexported field
+		sbb.WriteString(fmt.Sprint(v))
+	}
+}
+
 func (cs *constraintSystem) addDebugInfo(errName string, i ...interface{}) int {
 	var debug compiled.LogEntry
 
@@ -0,0 +1,80 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Exp returns base**exponent, exponent being range checked on nbBits bits.
+//
+// nbBits is less than the bit length of the modulus: 2**nbBits is then less than the modulus, and the
+// exponent has a single decomposition; on fr.Bits bits, a prover could use the bits of exponent + modulus.
+//
+// The bits of the exponent are read from the most significant one: at each step the result is squared,
+// then multiplied by Select(bit, base, 1). The selection is a linear expression when base is a constant
+// (see Select), and the first steps multiply by 1: this costs 3 constraints per bit with a variable base, 2
+// with a constant one, plus the range check. A constant exponent is ExpConstant.
+func (cs *constraintSystem) Exp(base, exponent Variable, nbBits int) Variable {
+	base.assertIsSet(cs)
+	exponent.assertIsSet(cs)
+	if nbBits < 1 || nbBits >= cs.bitLen() {
+		panic(fmt.Sprintf("Exp: nbBits must be in [1, %d]", cs.bitLen()-1))
+	}
+
+	if exponent.isConstant() {
+		e := exponent.constantValue(cs)
+		e.Mod(e, cs.curveID.Info().Fr.Modulus())
+		if e.BitLen() > nbBits {
+			panic(fmt.Sprintf("Exp: constant(%s) doesn't fit on %d bits, constraint will never be satisfied", e.String(), nbBits))
+		}
+		return cs.ExpConstant(base, e)
+	}
+
+	bits := cs.RangeCheck(exponent, nbBits)
+	res := cs.one()
+	for i := nbBits - 1; i >= 0; i-- {
+		res = cs.Mul(res, res)
+		res = cs.Mul(res, cs.Select(bits[i], base, 1))
+	}
+	return res
+}
+
+// ExpConstant returns base**e, e being a constant which must not be negative (base**0 == 1, for base == 0
+// too).
+//
+// It squares and multiplies over the bits of e, without any Select: it costs len(e) - 1 squarings and
+// popcount(e) - 1 multiplications, and no constraint if base is a constant.
+func (cs *constraintSystem) ExpConstant(base Variable, e *big.Int) Variable {
+	base.assertIsSet(cs)
+	if e.Sign() == -1 {
+		panic(fmt.Sprintf("ExpConstant: exponent (%s) must not be negative", e.String()))
+	}
+	if e.Sign() == 0 {
+		return cs.one()
+	}
+
+	res := base
+	for i := e.BitLen() - 2; i >= 0; i-- {
+		res = cs.Mul(res, res)
+		if e.Bit(i) == 1 {
+			res = cs.Mul(res, base)
+		}
+	}
+	return res
+}
@@ -0,0 +1,175 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// expCircuit asserts Y == X**E, E on nbBits bits, or Y == X**exponent and X*E == 0 if exponent is set
+type expCircuit struct {
+	X, E     frontend.Variable
+	Y        frontend.Variable `gnark:",public"`
+	nbBits   int
+	exponent *big.Int
+}
+
+func (circuit *expCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	if circuit.exponent != nil {
+		api.AssertIsEqual(api.ExpConstant(circuit.X, circuit.exponent), circuit.Y)
+		api.AssertIsEqual(api.Mul(circuit.X, circuit.E), 0) // X isn't constrained by X**0
+
+		return nil
+	}
+	api.AssertIsEqual(api.Exp(circuit.X, circuit.E, circuit.nbBits), circuit.Y)
+	return nil
+}
+
+func expWitness(x, e, y int64) *expCircuit {
+	var w expCircuit
+	w.X.Assign(big.NewInt(x))
+	w.E.Assign(big.NewInt(e))
+	w.Y.Assign(big.NewInt(y))
+	return &w
+}
+
+func TestExp(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	circuit := &expCircuit{nbBits: 4}
+	for _, w := range []*expCircuit{
+		expWitness(3, 5, 243),
+		expWitness(3, 0, 1),
+		expWitness(0, 0, 1),
+		expWitness(0, 7, 0),
+		expWitness(2, 15, 1<<15),
+	} {
+		assert.ProverSucceeded(circuit, w, test.WithCurves(ecc.BN254))
+	}
+	for _, w := range []*expCircuit{
+		expWitness(3, 5, 244),
+		expWitness(0, 0, 0),
+		expWitness(2, 16, 1<<16), // the exponent doesn't fit on 4 bits
+	} {
+		assert.ProverFailed(circuit, w, test.WithCurves(ecc.BN254))
+	}
+
+	for e, x := range map[int64]int64{0: 0, 1: 5, 6: 3, 13: 2} {
+		// the assert helper caches the compiled circuits by type, hence a new helper per exponent
+		assert := test.NewAssert(t)
+		circuit := &expCircuit{exponent: big.NewInt(e)}
+		y := new(big.Int).Exp(big.NewInt(x), big.NewInt(e), nil)
+		assert.ProverSucceeded(circuit, expWitness(x, 0, y.Int64()), test.WithCurves(ecc.BN254))
+		assert.ProverFailed(circuit, expWitness(x, 0, y.Int64()+1), test.WithCurves(ecc.BN254))
+	}
+}
+
+// expConstantExponentCircuit asserts Y == X**13 with Exp
+type expConstantExponentCircuit struct {
+	X, Y frontend.Variable
+}
+
+func (circuit *expConstantExponentCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Exp(circuit.X, api.Constant(13), 4), circuit.Y)
+	return nil
+}
+
+func TestExpCosts(t *testing.T) {
+	assert := require.New(t)
+
+	// 64-bit exponents: a bit costs a squaring, a Select and a multiplication, but for the first one,
+	// plus its range check (64 boolean constraints and the recomposition); the constant exponent costs
+	// 63 squarings and popcount - 1 multiplications. The circuits assert 1 (resp. 3) constraints more.
+	e := new(big.Int).SetUint64(0xf0f0f0f0f0f0f0f1)
+	test.AssertConstraintCount(t, &expCircuit{nbBits: 64}, backend.GROTH16, ecc.BN254, 1+63*3+65+1, 0)
+	test.AssertConstraintCount(t, &expCircuit{exponent: e}, backend.GROTH16, ecc.BN254, 63+32+3, 0)
+	variable, err := frontend.Compile(ecc.BN254, backend.PLONK, &expCircuit{nbBits: 64})
+	assert.NoError(err)
+	constant, err := frontend.Compile(ecc.BN254, backend.PLONK, &expCircuit{exponent: e})
+	assert.NoError(err)
+	assert.Less(2*constant.GetNbConstraints(), variable.GetNbConstraints())
+
+	// a constant base folds the Select
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &expConstantBaseCircuit{})
+	assert.NoError(err)
+	assert.Equal(63*2+65+1, ccs.GetNbConstraints())
+
+	// Exp with a constant exponent is ExpConstant
+	ccs, err = frontend.Compile(ecc.BN254, backend.GROTH16, &expConstantExponentCircuit{})
+	assert.NoError(err)
+	assert.Equal(3+2+1, ccs.GetNbConstraints())
+	assert.NoError(test.IsSolved(&expConstantExponentCircuit{}, &expConstantExponentCircuit{X: frontend.Value(2), Y: frontend.Value(1 << 13)}, ecc.BN254))
+
+	// the exponent must fit, and ExpConstant's must not be negative
+	_, err = frontend.Compile(ecc.BN254, backend.GROTH16, &expCircuit{nbBits: 0})
+	assert.Error(err)
+	_, err = frontend.Compile(ecc.BN254, backend.PLONK, &expCircuit{exponent: big.NewInt(-1)})
+	assert.Error(err)
+	assert.Error(test.IsSolved(&expCircuit{exponent: big.NewInt(-1)}, expWitness(2, 0, 1), ecc.BN254))
+}
+
+// TestExpNonCanonical ensures a malicious prover can't prove 2**3 == 2**(3 + q) == 16 by decomposing the
+// exponent 3 as 3 + q, which fits on fr.Bits bits but not on the fr.Bits - 1 bits Exp accepts at most
+func TestExpNonCanonical(t *testing.T) {
+	assert := require.New(t)
+	nbBits := ecc.BN254.Info().Fr.Bits
+
+	_, err := frontend.Compile(ecc.BN254, backend.GROTH16, &expCircuit{nbBits: nbBits})
+	assert.Error(err)
+	assert.Error(test.IsSolved(&expCircuit{nbBits: nbBits}, expWitness(2, 3, 8), ecc.BN254))
+
+	circuit := &expCircuit{nbBits: nbBits - 1}
+	assert.NoError(test.IsSolved(circuit, expWitness(2, 3, 8), ecc.BN254))
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, circuit)
+	assert.NoError(err)
+
+	// replace the bit decomposition hints by the malicious one
+	replaced := 0
+	hints := mHints(ccs)
+	for vID, h := range hints {
+		if h.ID == hint.IthBitNamed.UUID() {
+			h.ID = hint.UUID(nonCanonicalBit)
+			hints[vID] = h
+			replaced++
+		}
+	}
+	assert.True(replaced > 0)
+
+	pk, err := groth16.DummySetup(ccs)
+	assert.NoError(err)
+	_, err = groth16.Prove(ccs, pk, expWitness(2, 3, 16), backend.WithHints(nonCanonicalBit))
+	assert.Error(err)
+}
+
+// expConstantBaseCircuit asserts Y == 3**E, E on 64 bits
+type expConstantBaseCircuit struct {
+	E, Y frontend.Variable
+}
+
+func (circuit *expConstantBaseCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Exp(api.Constant(3), circuit.E, 64), circuit.Y)
+	return nil
+}
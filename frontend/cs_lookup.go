@@ -0,0 +1,76 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/internal/backend/compiled"
+)
+
+// errLookupNotSupported is returned when compiling a circuit using lookups for a backend which doesn't support them
+var errLookupNotSupported = errors.New("lookup tables are not supported by groth16, use plonk")
+
+// lookup records a call to Lookup, it is converted into a SparseR1C when compiling for PLONK
+type lookup struct {
+	table   TableID
+	linExp  compiled.LinearExpression
+	debugID int
+}
+
+// AddTable adds a lookup table to the circuit and returns its ID
+func (cs *constraintSystem) AddTable(name string, entries []big.Int) TableID {
+	if len(entries) == 0 {
+		panic("lookup table " + name + " is empty")
+	}
+
+	table := compiled.LookupTable{Name: name, Entries: make([]int, len(entries))}
+	modulus := cs.curveID.Info().Fr.Modulus()
+	var e big.Int
+	for i := 0; i < len(entries); i++ {
+		e.Mod(&entries[i], modulus)
+		table.Entries[i] = cs.coeffID(&e)
+	}
+	cs.tables = append(cs.tables, table)
+
+	return TableID(len(cs.tables) - 1)
+}
+
+// Lookup fails if v is not an entry of the table
+func (cs *constraintSystem) Lookup(table TableID, v Variable) {
+	v.assertIsSet(cs)
+	if int(table) < 0 || int(table) >= len(cs.tables) {
+		panic(fmt.Sprintf("lookup table %d doesn't exist", table))
+	}
+	t := cs.tables[table]
+
+	if v.isConstant() {
+		c := v.constantValue(cs)
+		c.Mod(c, cs.curveID.Info().Fr.Modulus())
+		for _, cID := range t.Entries {
+			if cs.coeffs[cID].Cmp(c) == 0 {
+				return
+			}
+		}
+		panic(fmt.Sprintf("lookup failed: constant(%s) is not in table %s", c.String(), t.Name))
+	}
+
+	debug := cs.addDebugInfo("lookup", v, " in "+t.Name)
+	cs.lookups = append(cs.lookups, lookup{table: table, linExp: v.linExp.Clone(), debugID: debug})
+}
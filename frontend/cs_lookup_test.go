@@ -0,0 +1,74 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+)
+
+// lookupCircuit range checks the nbLookups first entries of X with a lookup in [0, 256)
+type lookupCircuit struct {
+	nbLookups int
+	X         [4]Variable
+}
+
+func (circuit *lookupCircuit) Define(curveID ecc.ID, cs API) error {
+	entries := make([]big.Int, 256)
+	for i := 0; i < len(entries); i++ {
+		entries[i].SetUint64(uint64(i))
+	}
+	lk := cs.(Lookuper)
+	bytes := lk.AddTable("bytes", entries)
+	for i := 0; i < circuit.nbLookups; i++ {
+		lk.Lookup(bytes, circuit.X[i])
+	}
+	for i := circuit.nbLookups; i < len(circuit.X); i++ {
+		cs.AssertIsEqual(circuit.X[i], 0)
+	}
+	return nil
+}
+
+func TestLookupGroth16(t *testing.T) {
+	_, err := Compile(ecc.BN254, backend.GROTH16, &lookupCircuit{nbLookups: 1})
+	if !errors.Is(err, errLookupNotSupported) {
+		t.Fatal("expected", errLookupNotSupported, "got", err)
+	}
+}
+
+func TestLookupNbConstraints(t *testing.T) {
+	ccs, err := Compile(ecc.BN254, backend.PLONK, &lookupCircuit{nbLookups: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := ccs.GetNbConstraints()
+
+	for i := 1; i <= 4; i++ {
+		ccs, err := Compile(ecc.BN254, backend.PLONK, &lookupCircuit{nbLookups: i})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a lookup replaces an assertion, and costs a single constraint
+		if ccs.GetNbConstraints() != ref {
+			t.Fatalf("%d lookups: expected %d constraints, got %d", i, ref, ccs.GetNbConstraints())
+		}
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"fmt"
+	"math/big"
+	"runtime/debug"
+	"strings"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+)
+
+// maxMemberProductSize is the size of the sets above which AssertIsMember is a lookup, with PLONK
+const maxMemberProductSize = 16
+
+// AssertIsMember fails if v is not an element of set.
+//
+// Π(v - set[i]) == 0 costs len(set) - 1 constraints; with PLONK, the sets of more than 16 elements
+// are lookup tables instead (see Lookuper), which costs a single constraint.
+func (cs *constraintSystem) AssertIsMember(v Variable, set []big.Int) {
+	v.assertIsSet(cs)
+	elements := cs.reduceSet(set)
+	if len(elements) == 0 {
+		panic(fmt.Sprintf("assertIsMember failed: empty set\n%s", string(debug.Stack())))
+	}
+
+	// the assertion on a constant is checked at compile time, on all backends
+	if v.isConstant() {
+		c := v.constantValue(cs)
+		c.Mod(c, cs.curveID.Info().Fr.Modulus())
+		if !containsBigInt(elements, c) {
+			panic(fmt.Sprintf("assertIsMember failed: constant(%s) is not in the set\n%s", c.String(), string(debug.Stack())))
+		}
+		return
+	}
+
+	if len(elements) > maxMemberProductSize && cs.zkpID == backend.PLONK {
+		cs.Lookup(cs.memberTable(elements), v)
+		return
+	}
+
+	// the last difference is multiplied in the assertion
+	n := len(elements) - 1
+	p := cs.memberProduct(v, elements[:n])
+	last := cs.Sub(v, elements[n])
+	debug := cs.addDebugInfo("assertIsMember", p, " * ", last, " == 0")
+	cs.addConstraint(newR1C(p, last, cs.Constant(0)), debug)
+}
+
+// AssertIsNotMember fails if v is an element of set.
+//
+// It asserts that Π(v - set[i]) has an inverse: len(set) - 1 constraints for the product, and a hint
+// and a constraint for its inverse.
+func (cs *constraintSystem) AssertIsNotMember(v Variable, set []big.Int) {
+	v.assertIsSet(cs)
+	elements := cs.reduceSet(set)
+
+	// the assertion on a constant is checked at compile time, on all backends
+	if v.isConstant() {
+		c := v.constantValue(cs)
+		c.Mod(c, cs.curveID.Info().Fr.Modulus())
+		if containsBigInt(elements, c) {
+			panic(fmt.Sprintf("assertIsNotMember failed: constant(%s) is in the set\n%s", c.String(), string(debug.Stack())))
+		}
+		return
+	}
+	if len(elements) == 0 {
+		return
+	}
+
+	p := cs.memberProduct(v, elements)
+	inv := cs.NewAnnotatedHint(hint.InvModNamed, p)[0]
+	debug := cs.addDebugInfo("assertIsNotMember", p, " * ", inv, " == 1")
+	cs.addConstraint(newR1C(p, inv, cs.one()), debug)
+}
+
+// memberProduct returns Π(v - elements[i])
+func (cs *constraintSystem) memberProduct(v Variable, elements []big.Int) Variable {
+	differences := make([]Variable, len(elements))
+	for i := range elements {
+		differences[i] = cs.Sub(v, elements[i])
+	}
+	return cs.Product(differences...)
+}
+
+// reduceSet returns the elements of set reduced modulo the scalar field, without duplicates, in order
+func (cs *constraintSystem) reduceSet(set []big.Int) []big.Int {
+	modulus := cs.curveID.Info().Fr.Modulus()
+	res := make([]big.Int, 0, len(set))
+	for i := range set {
+		var e big.Int
+		e.Mod(&set[i], modulus)
+		if !containsBigInt(res, &e) {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// memberTable returns the lookup table of the elements, added once per set
+func (cs *constraintSystem) memberTable(elements []big.Int) TableID {
+	keys := make([]string, len(elements))
+	for i := range elements {
+		keys[i] = elements[i].String()
+	}
+	key := strings.Join(keys, ",")
+	if id, ok := cs.memberTables[key]; ok {
+		return id
+	}
+	if cs.memberTables == nil {
+		cs.memberTables = make(map[string]TableID)
+	}
+	id := cs.AddTable(fmt.Sprintf("set%d", len(cs.memberTables)), elements)
+	cs.memberTables[key] = id
+	return id
+}
+
+func containsBigInt(s []big.Int, v *big.Int) bool {
+	for i := range s {
+		if s[i].Cmp(v) == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// memberSet returns the set {3, 10, 17, ...} of n elements
+func memberSet(n int) []big.Int {
+	set := make([]big.Int, n)
+	for i := range set {
+		set[i].SetInt64(int64(3 + 7*i))
+	}
+	return set
+}
+
+// membershipCircuit asserts that X is (or isn't) in a set of nbElements elements. The PLONK prover
+// needs a few gates, added by the products of X and powers of P.
+type membershipCircuit struct {
+	X          frontend.Variable
+	P          frontend.Variable `gnark:",public"`
+	nbElements int
+	notMember  bool
+}
+
+func (circuit *membershipCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	p := api.Mul(circuit.X, circuit.P)
+	for i := 0; i < 8; i++ {
+		p = api.Mul(p, circuit.P)
+	}
+	if circuit.nbElements == 0 {
+		return nil
+	}
+	if circuit.notMember {
+		api.AssertIsNotMember(circuit.X, memberSet(circuit.nbElements))
+	} else {
+		api.AssertIsMember(circuit.X, memberSet(circuit.nbElements))
+	}
+	return nil
+}
+
+func membershipWitness(x int) *membershipCircuit {
+	var w membershipCircuit
+	w.X.Assign(x)
+	w.P.Assign(2)
+	return &w
+}
+
+func TestAssertIsMember(t *testing.T) {
+	// a product with PLONK, and a lookup above 16 elements
+	for _, n := range []int{4, 17} {
+		set := memberSet(n)
+		circuit := &membershipCircuit{nbElements: n}
+		for _, b := range []backend.ID{backend.GROTH16, backend.PLONK} {
+			// the assert helper caches the compiled circuits by type, hence a new helper per set
+			assert := test.NewAssert(t)
+			opts := []func(opt *test.TestingOption) error{test.WithCurves(ecc.BN254), test.WithBackends(b)}
+			for i := range set {
+				assert.ProverSucceeded(circuit, membershipWitness(int(set[i].Int64())), opts...)
+			}
+			assert.ProverFailed(circuit, membershipWitness(4), opts...)
+			assert.ProverFailed(circuit, membershipWitness(0), opts...)
+		}
+	}
+}
+
+func TestAssertIsNotMember(t *testing.T) {
+	set := memberSet(4)
+	circuit := &membershipCircuit{nbElements: len(set), notMember: true}
+	assert := test.NewAssert(t)
+	opts := []func(opt *test.TestingOption) error{test.WithCurves(ecc.BN254)}
+	for i := range set {
+		assert.ProverFailed(circuit, membershipWitness(int(set[i].Int64())), opts...)
+	}
+	assert.ProverSucceeded(circuit, membershipWitness(4), opts...)
+	assert.ProverSucceeded(circuit, membershipWitness(0), opts...)
+}
+
+// TestMembershipConstraints pins the number of constraints of the assertions on a 16-element set
+func TestMembershipConstraints(t *testing.T) {
+	assert := require.New(t)
+
+	nbConstraints := func(b backend.ID, circuit *membershipCircuit) int {
+		padding, err := frontend.Compile(ecc.BN254, b, &membershipCircuit{})
+		assert.NoError(err)
+		ccs, err := frontend.Compile(ecc.BN254, b, circuit)
+		assert.NoError(err)
+		return ccs.GetNbConstraints() - padding.GetNbConstraints()
+	}
+
+	// n-1 multiplications, the last one in the assertion
+	assert.Equal(15, nbConstraints(backend.GROTH16, &membershipCircuit{nbElements: 16}))
+	assert.Equal(15, nbConstraints(backend.PLONK, &membershipCircuit{nbElements: 16}))
+
+	// n multiplications, and the assertion on the inverse
+	assert.Equal(16, nbConstraints(backend.GROTH16, &membershipCircuit{nbElements: 16, notMember: true}))
+
+	// above 16 elements, a lookup with PLONK
+	assert.Equal(16, nbConstraints(backend.GROTH16, &membershipCircuit{nbElements: 17}))
+	assert.Equal(1, nbConstraints(backend.PLONK, &membershipCircuit{nbElements: 17}))
+}
+
+// constantMembershipCircuit asserts the membership of a constant
+type constantMembershipCircuit struct {
+	X         frontend.Variable
+	constant  int
+	notMember bool
+}
+
+func (circuit *constantMembershipCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(circuit.X, 1)
+	if circuit.notMember {
+		api.AssertIsNotMember(api.Constant(circuit.constant), memberSet(16))
+	} else {
+		api.AssertIsMember(api.Constant(circuit.constant), memberSet(16))
+	}
+	return nil
+}
+
+func TestConstantMembership(t *testing.T) {
+	for _, b := range []backend.ID{backend.GROTH16, backend.PLONK} {
+		t.Run(b.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			reference, err := frontend.Compile(ecc.BN254, b, &constantMembershipCircuit{constant: 4, notMember: true})
+			assert.NoError(err)
+			ccs, err := frontend.Compile(ecc.BN254, b, &constantMembershipCircuit{constant: 10})
+			assert.NoError(err)
+			assert.Equal(reference.GetNbConstraints(), ccs.GetNbConstraints(), "constant assertions add no constraint")
+
+			_, err = frontend.Compile(ecc.BN254, b, &constantMembershipCircuit{constant: 4})
+			assert.Error(err)
+			assert.Contains(err.Error(), "assertIsMember failed: constant(4) is not in the set")
+			assert.Contains(err.Error(), "cs_membership_test.go")
+
+			_, err = frontend.Compile(ecc.BN254, b, &constantMembershipCircuit{constant: 10, notMember: true})
+			assert.Error(err)
+			assert.Contains(err.Error(), "assertIsNotMember failed: constant(10) is in the set")
+		})
+	}
+}
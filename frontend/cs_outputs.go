@@ -0,0 +1,90 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/internal/backend/compiled"
+)
+
+// publicOutput is a public input tagged "public,output"
+type publicOutput struct {
+	name  string
+	id    int                       // ID of the public variable
+	value compiled.LinearExpression // nil until SetOutput
+}
+
+// SetOutput constrains the public output to be equal to v, and records v as the input of the hint
+// computing the wire of output, see API.SetOutput
+func (cs *constraintSystem) SetOutput(output, v Variable) {
+	v.assertIsSet(cs)
+	var o *publicOutput
+	if output.visibility == compiled.Public {
+		for i := range cs.publicOutputs {
+			if cs.publicOutputs[i].id == output.id {
+				o = &cs.publicOutputs[i]
+				break
+			}
+		}
+	}
+	if o == nil {
+		panic(fmt.Sprintf("setOutput failed: the variable is not a public input tagged \"public,output\"\n%s", string(debug.Stack())))
+	}
+	if o.value != nil {
+		panic(fmt.Sprintf("setOutput failed: output %s is already set\n%s", o.name, string(debug.Stack())))
+	}
+
+	o.value = v.linExp.Clone()
+	cs.AssertIsEqual(output, v)
+}
+
+// checkPublicOutputs returns an error if a public output isn't set, once Define returns
+func (cs *constraintSystem) checkPublicOutputs() error {
+	for _, o := range cs.publicOutputs {
+		if o.value == nil {
+			return fmt.Errorf("public output %s is not set by SetOutput", o.name)
+		}
+	}
+	return nil
+}
+
+// compilePublicOutputs returns the public outputs of the compiled constraint system, and adds to
+// hints the hints computing their wires; wire returns the ID in the compiled constraint system of a
+// public variable, and offset sets the IDs of the terms of a linear expression
+func (cs *constraintSystem) compilePublicOutputs(hints map[int]compiled.Hint, wire func(id int) int, offset func(l compiled.LinearExpression)) []compiled.PublicOutput {
+	if len(cs.publicOutputs) == 0 {
+		return nil
+	}
+	res := make([]compiled.PublicOutput, len(cs.publicOutputs))
+	for i, o := range cs.publicOutputs {
+		// the first public variable is the constant ONE_WIRE, which isn't in the witness
+		res[i] = compiled.PublicOutput{Name: o.name, Index: o.id - 1}
+		input := o.value.Clone()
+		offset(input)
+		w := wire(o.id)
+		hints[w] = compiled.Hint{
+			ID:     hint.IdentityNamed.UUID(),
+			Name:   hint.IdentityNamed.String(),
+			Inputs: []compiled.LinearExpression{input},
+			Wires:  []int{w},
+		}
+	}
+	return res
+}
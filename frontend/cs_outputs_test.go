@@ -0,0 +1,144 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// publicOutputCircuit computes Y = X**3 + X + 5: the prover doesn't need to know Y
+type publicOutputCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public,output"`
+}
+
+func (circuit *publicOutputCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	x3 := api.Mul(circuit.X, circuit.X, circuit.X)
+	api.SetOutput(circuit.Y, api.Add(x3, circuit.X, 5))
+	return nil
+}
+
+func TestPublicOutputs(t *testing.T) {
+	assert := require.New(t)
+
+	for _, b := range backend.Implemented() {
+		ccs, err := frontend.Compile(ecc.BN254, b, &publicOutputCircuit{})
+		assert.NoError(err, b.String())
+		assert.Equal([]string{"Y"}, ccs.GetPublicOutputs(), b.String())
+
+		var outputs map[string]*big.Int
+		withOutputs := backend.WithPublicOutputs(func(o map[string]*big.Int) {
+			outputs = o
+		})
+
+		var (
+			prove  func(w frontend.Circuit) (interface{}, error)
+			verify func(proof interface{}, publicW frontend.Circuit) error
+		)
+		switch b {
+		case backend.GROTH16:
+			pk, vk, err := groth16.Setup(ccs)
+			assert.NoError(err)
+			prove = func(w frontend.Circuit) (interface{}, error) {
+				return groth16.Prove(ccs, pk, w, withOutputs)
+			}
+			verify = func(proof interface{}, publicW frontend.Circuit) error {
+				return groth16.Verify(proof.(groth16.Proof), vk, publicW)
+			}
+		case backend.PLONK:
+			srs, err := test.NewKZGSRS(ccs)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(ccs, srs)
+			assert.NoError(err)
+			prove = func(w frontend.Circuit) (interface{}, error) {
+				return plonk.Prove(ccs, pk, w, withOutputs)
+			}
+			verify = func(proof interface{}, publicW frontend.Circuit) error {
+				return plonk.Verify(proof.(plonk.Proof), vk, publicW)
+			}
+		}
+
+		// the prover doesn't assign Y, and reads it back
+		proof, err := prove(&publicOutputCircuit{X: frontend.Value(3)})
+		assert.NoError(err, b.String())
+		assert.Equal(big.NewInt(35), outputs["Y"], b.String())
+
+		// the verifier assigns Y as any public input
+		assert.NoError(verify(proof, &publicOutputCircuit{Y: frontend.Value(outputs["Y"])}), b.String())
+		assert.Error(verify(proof, &publicOutputCircuit{Y: frontend.Value(36)}), b.String())
+
+		// a value of Y in the full witness is ignored, the proof is for the computed value
+		proof, err = prove(&publicOutputCircuit{X: frontend.Value(2), Y: frontend.Value(36)})
+		assert.NoError(err, b.String())
+		assert.Equal(big.NewInt(15), outputs["Y"], b.String())
+		assert.NoError(verify(proof, &publicOutputCircuit{Y: frontend.Value(15)}), b.String())
+		assert.Error(verify(proof, &publicOutputCircuit{Y: frontend.Value(36)}), b.String())
+	}
+
+	// the test engine computes the outputs too, and checks them when they are assigned
+	assert.NoError(test.IsSolved(&publicOutputCircuit{}, &publicOutputCircuit{X: frontend.Value(3)}, ecc.BN254))
+	assert.NoError(test.IsSolved(&publicOutputCircuit{}, &publicOutputCircuit{X: frontend.Value(3), Y: frontend.Value(35)}, ecc.BN254))
+	assert.Error(test.IsSolved(&publicOutputCircuit{}, &publicOutputCircuit{X: frontend.Value(3), Y: frontend.Value(36)}, ecc.BN254))
+
+	// the outputs must be set exactly once, and be public
+	_, err := frontend.Compile(ecc.BN254, backend.GROTH16, &unsetPublicOutputCircuit{})
+	assert.EqualError(err, "public output Y is not set by SetOutput")
+	_, err = frontend.Compile(ecc.BN254, backend.PLONK, &twiceSetOutputCircuit{})
+	assert.Error(err)
+	_, err = frontend.Compile(ecc.BN254, backend.GROTH16, &secretOutputCircuit{})
+	assert.Error(err)
+}
+
+type unsetPublicOutputCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public,output"`
+}
+
+func (circuit *unsetPublicOutputCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(circuit.X, 3)
+	return nil
+}
+
+type twiceSetOutputCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public,output"`
+}
+
+func (circuit *twiceSetOutputCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.SetOutput(circuit.Y, circuit.X)
+	api.SetOutput(circuit.Y, api.Add(circuit.X, 1))
+	return nil
+}
+
+type secretOutputCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",secret,output"`
+}
+
+func (circuit *secretOutputCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(circuit.X, circuit.Y)
+	return nil
+}
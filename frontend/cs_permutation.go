@@ -0,0 +1,249 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	mimcbls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/mimc"
+	mimcbls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/mimc"
+	mimcbls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr/mimc"
+	mimcbn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	mimcbw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr/mimc"
+	mimcbw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr/mimc"
+	"github.com/consensys/gnark/backend/hint"
+)
+
+// PermutationSeed is the seed of the MiMC hash deriving the challenge of AssertIsPermutation:
+// the challenge is the MiMC hash (see std/hash/mimc) of the values of a, then b
+const PermutationSeed = "gnark/permutation"
+
+// PermutationOption holds the options of AssertIsPermutation
+//
+// By default, AssertIsPermutation checks that Π(a_i + γ) == Π(b_i + γ), the challenge γ being
+// the MiMC hash of all the values of a and b, computed in the circuit. If b is not a permutation
+// of a, the two products are different polynomials in γ of degree len(a): a prover can make them
+// agree only by finding values whose hash is one of their (at most len(a)) common roots, that is,
+// with probability about len(a)/r per evaluation of the hash, r being the size of the scalar field.
+// This relies on MiMC behaving as a random oracle, which is a heuristic assumption: the challenge is
+// derived from the witness (Fiat-Shamir in the circuit), not sampled by the verifier.
+type PermutationOption struct {
+	challenge *Variable
+	network   bool
+}
+
+// WithPermutationChallenge uses gamma as the challenge of the grand product instead of hashing a and b,
+// for example to share a challenge between several arguments.
+//
+// The argument is sound only if gamma is unpredictable once a and b are fixed: it must be derived from
+// (at least) all the values of a and b, or come from the verifier (a public input sampled after the
+// prover committed to a and b). A gamma known to the prover in advance breaks the soundness.
+func WithPermutationChallenge(gamma Variable) func(opt *PermutationOption) error {
+	return func(opt *PermutationOption) error {
+		opt.challenge = &gamma
+		return nil
+	}
+}
+
+// WithPermutationNetwork checks the permutation exactly, without challenge: a is routed to b through a
+// Beneš network whose switches are computed by the hint.PermutationNetwork hint and constrained to be
+// boolean. It costs O(n log n) constraints, n being len(a) rounded up to a power of two (at least 8): about
+// 2n log2(n) constraints, against ~2 constraints per value plus the hash for the grand product.
+func WithPermutationNetwork() func(opt *PermutationOption) error {
+	return func(opt *PermutationOption) error {
+		opt.network = true
+		return nil
+	}
+}
+
+func newPermutationOption(opts []func(opt *PermutationOption) error) PermutationOption {
+	var opt PermutationOption
+	for _, option := range opts {
+		if err := option(&opt); err != nil {
+			panic(err)
+		}
+	}
+	if opt.network && opt.challenge != nil {
+		panic("AssertIsPermutation: WithPermutationNetwork doesn't use a challenge")
+	}
+	return opt
+}
+
+// AssertIsPermutation fails if b is not a permutation of a, see PermutationOption
+func (cs *constraintSystem) AssertIsPermutation(a, b []Variable, opts ...func(opt *PermutationOption) error) {
+	if len(a) != len(b) {
+		panic(fmt.Sprintf("AssertIsPermutation: len(a) = %d and len(b) = %d differ", len(a), len(b)))
+	}
+	opt := newPermutationOption(opts)
+	for i := 0; i < len(a); i++ {
+		a[i].assertIsSet(cs)
+		b[i].assertIsSet(cs)
+	}
+	if len(a) == 0 {
+		return
+	}
+
+	if opt.network {
+		cs.assertIsPermutationNetwork(a, b)
+		return
+	}
+
+	var gamma Variable
+	if opt.challenge != nil {
+		gamma = *opt.challenge
+	} else {
+		gamma = cs.permutationChallenge(a, b)
+	}
+
+	pa, pb := make([]Variable, len(a)), make([]Variable, len(b))
+	for i := 0; i < len(a); i++ {
+		pa[i] = cs.Add(a[i], gamma)
+		pb[i] = cs.Add(b[i], gamma)
+	}
+	cs.AssertIsEqual(cs.Product(pa...), cs.Product(pb...))
+}
+
+// permutationChallenge returns the MiMC hash of the values of a and b, as std/hash/mimc computes it
+// with the seed PermutationSeed
+func (cs *constraintSystem) permutationChallenge(a, b []Variable) Variable {
+	params := mimcParams(cs.curveID)
+
+	h := cs.Constant(0)
+	for _, values := range [2][]Variable{a, b} {
+		for _, v := range values {
+			// h = encrypt(v, h) + v, the key h being added after the last round
+			res := v
+			for i := 0; i < len(params); i++ {
+				t := cs.Add(res, h, params[i])
+				if cs.curveID == ecc.BLS12_377 {
+					// res = (res+h+c)^-1
+					res = cs.Inverse(t)
+				} else {
+					// res = (res+h+c)^5
+					res = cs.Mul(t, t)
+					res = cs.Mul(res, res)
+					res = cs.Mul(res, t)
+				}
+			}
+			h = cs.Add(res, h, v)
+		}
+	}
+	return h
+}
+
+// mimcParams returns the round constants of MiMC with the seed PermutationSeed
+func mimcParams(curveID ecc.ID) []big.Int {
+	var res []big.Int
+	setParams := func(n int, toBigInt func(i int, res *big.Int)) {
+		res = make([]big.Int, n)
+		for i := 0; i < n; i++ {
+			toBigInt(i, &res[i])
+		}
+	}
+	switch curveID {
+	case ecc.BN254:
+		params := mimcbn254.NewParams(PermutationSeed)
+		setParams(len(params), func(i int, res *big.Int) { params[i].ToBigIntRegular(res) })
+	case ecc.BLS12_377:
+		params := mimcbls12377.NewParams(PermutationSeed)
+		setParams(len(params), func(i int, res *big.Int) { params[i].ToBigIntRegular(res) })
+	case ecc.BLS12_381:
+		params := mimcbls12381.NewParams(PermutationSeed)
+		setParams(len(params), func(i int, res *big.Int) { params[i].ToBigIntRegular(res) })
+	case ecc.BLS24_315:
+		params := mimcbls24315.NewParams(PermutationSeed)
+		setParams(len(params), func(i int, res *big.Int) { params[i].ToBigIntRegular(res) })
+	case ecc.BW6_761:
+		params := mimcbw6761.NewParams(PermutationSeed)
+		setParams(len(params), func(i int, res *big.Int) { params[i].ToBigIntRegular(res) })
+	case ecc.BW6_633:
+		params := mimcbw6633.NewParams(PermutationSeed)
+		setParams(len(params), func(i int, res *big.Int) { params[i].ToBigIntRegular(res) })
+	default:
+		panic(fmt.Sprintf("AssertIsPermutation: no MiMC hash on curve %d, use WithPermutationChallenge", curveID))
+	}
+	return res
+}
+
+// assertIsPermutationNetwork routes a to b through a Beneš network, see WithPermutationNetwork
+func (cs *constraintSystem) assertIsPermutationNetwork(a, b []Variable) {
+	// the network size is a power of two, such that there are at least as many switches as values
+	// in a and b: the hint has as many outputs as inputs
+	n := 8
+	for n < len(a) {
+		n *= 2
+	}
+	pad := func(v []Variable) []Variable {
+		res := make([]Variable, n)
+		copy(res, v)
+		for i := len(v); i < n; i++ {
+			res[i] = cs.Constant(0)
+		}
+		return res
+	}
+	a, b = pad(a), pad(b)
+
+	inputs := make([]interface{}, hint.NbPermutationSwitches(n))
+	for i := 0; i < n; i++ {
+		inputs[i] = a[i]
+		inputs[n+i] = b[i]
+	}
+	for i := 2 * n; i < len(inputs); i++ {
+		inputs[i] = 0
+	}
+	switches := cs.NewAnnotatedHint(hint.PermutationNetwork, inputs...)
+	for _, s := range switches {
+		cs.AssertIsBoolean(s)
+	}
+
+	out, _ := cs.benes(a, switches)
+	for i := 0; i < n; i++ {
+		cs.AssertIsEqual(out[i], b[i])
+	}
+}
+
+// benes returns the outputs of the Beneš network with the given switches settings, and the switches
+// which are not part of the network (see hint.NbPermutationSwitches)
+func (cs *constraintSystem) benes(in []Variable, switches []Variable) ([]Variable, []Variable) {
+	n := len(in)
+	if n == 2 {
+		x, y := cs.permutationSwitch(in[0], in[1], switches[0])
+		return []Variable{x, y}, switches[1:]
+	}
+
+	upper, lower := make([]Variable, n/2), make([]Variable, n/2)
+	for i := 0; i < n/2; i++ {
+		upper[i], lower[i] = cs.permutationSwitch(in[2*i], in[2*i+1], switches[i])
+	}
+	switches = switches[n/2:]
+	upper, switches = cs.benes(upper, switches)
+	lower, switches = cs.benes(lower, switches)
+
+	out := make([]Variable, n)
+	for j := 0; j < n/2; j++ {
+		out[2*j], out[2*j+1] = cs.permutationSwitch(upper[j], lower[j], switches[j])
+	}
+	return out, switches[n/2:]
+}
+
+// permutationSwitch returns (x, y) if s == 0, (y, x) if s == 1
+func (cs *constraintSystem) permutationSwitch(x, y, s Variable) (Variable, Variable) {
+	d := cs.Mul(s, cs.Sub(y, x))
+	return cs.Add(x, d), cs.Sub(y, d)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+)
+
+const (
+	permutationDefault = iota
+	permutationChallenge
+	permutationNetwork
+)
+
+// permutationCircuit checks that B is a permutation of A
+type permutationCircuit struct {
+	variant int
+	A       [5]frontend.Variable
+	B       [5]frontend.Variable `gnark:",public"`
+}
+
+func (circuit *permutationCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	var opts []func(opt *frontend.PermutationOption) error
+	switch circuit.variant {
+	case permutationChallenge:
+		h, err := mimc.NewMiMC("challenge", curveID, api)
+		if err != nil {
+			return err
+		}
+		h.Write(circuit.A[:]...)
+		h.Write(circuit.B[:]...)
+		opts = append(opts, frontend.WithPermutationChallenge(h.Sum()))
+	case permutationNetwork:
+		opts = append(opts, frontend.WithPermutationNetwork())
+	}
+	api.AssertIsPermutation(circuit.A[:], circuit.B[:], opts...)
+	return nil
+}
+
+func permutationWitness(a, b [5]int) *permutationCircuit {
+	var witness permutationCircuit
+	for i := 0; i < len(a); i++ {
+		witness.A[i].Assign(a[i])
+		witness.B[i].Assign(b[i])
+	}
+	return &witness
+}
+
+func TestAssertIsPermutation(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, variant := range []int{permutationDefault, permutationChallenge, permutationNetwork} {
+		circuit := permutationCircuit{variant: variant}
+
+		// equal multisets, in different orders
+		assert.ProverSucceeded(&circuit, permutationWitness([5]int{1, 2, 3, 4, 5}, [5]int{1, 2, 3, 4, 5}), test.WithCurves(ecc.BN254))
+		assert.ProverSucceeded(&circuit, permutationWitness([5]int{1, 2, 3, 4, 5}, [5]int{5, 3, 1, 4, 2}))
+		assert.ProverSucceeded(&circuit, permutationWitness([5]int{7, 0, 7, -1, 0}, [5]int{0, -1, 7, 0, 7}), test.WithCurves(ecc.BN254))
+
+		// a single changed element
+		assert.ProverFailed(&circuit, permutationWitness([5]int{1, 2, 3, 4, 5}, [5]int{5, 3, 1, 4, 6}))
+
+		// same set, different multiplicities
+		assert.ProverFailed(&circuit, permutationWitness([5]int{7, 0, 7, -1, 0}, [5]int{0, -1, 7, 7, 7}), test.WithCurves(ecc.BN254))
+	}
+}
+
+// permutationLengthCircuit calls AssertIsPermutation with slices of different lengths
+type permutationLengthCircuit struct {
+	A [3]frontend.Variable
+	B [2]frontend.Variable
+}
+
+func (circuit *permutationLengthCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsPermutation(circuit.A[:], circuit.B[:])
+	return nil
+}
+
+func TestAssertIsPermutationLengthMismatch(t *testing.T) {
+	assert := test.NewAssert(t)
+	assert.CompilationFailed(&permutationLengthCircuit{}, "len(a) = 3 and len(b) = 2 differ")
+}
@@ -0,0 +1,193 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/internal/backend/compiled"
+)
+
+// RangeCheckStrategy is how AssertIsLessOrEqual against a constant bound and AssertIsLessOrEqualBounded
+// check that a value fits on n bits, see WithRangeCheckStrategy. All the strategies accept the same
+// values, they differ by the number and the kind of constraints they add.
+type RangeCheckStrategy struct {
+	kind      rangeCheckKind
+	digitBits int
+}
+
+type rangeCheckKind uint8
+
+const (
+	rangeCheckAuto rangeCheckKind = iota
+	rangeCheckBits
+	rangeCheckDigits
+	rangeCheckLookup
+	rangeCheckNone // no constraint is needed, not a strategy users can pick
+)
+
+// MaxRangeCheckDigitBits is the largest digit size of RangeCheckDigits
+const MaxRangeCheckDigitBits = 8
+
+// lookupDigitBits is the size of the digits of RangeCheckLookup, looked up in a table of 2**lookupDigitBits entries
+const lookupDigitBits = 8
+
+var (
+	// RangeCheckAuto picks the strategy per backend and bound size: RangeCheckLookup for PLONK when
+	// the bound has at least 8 bits, RangeCheckBits otherwise. It is the default.
+	RangeCheckAuto = RangeCheckStrategy{kind: rangeCheckAuto}
+
+	// RangeCheckBits decomposes the value in bits, each of them boolean constrained
+	RangeCheckBits = RangeCheckStrategy{kind: rangeCheckBits}
+
+	// RangeCheckLookup decomposes the value in bytes, each of them looked up in a table of the
+	// 256 bytes (PLONK only, RangeCheckAuto is used with Groth16)
+	RangeCheckLookup = RangeCheckStrategy{kind: rangeCheckLookup, digitBits: lookupDigitBits}
+
+	// noRangeCheck counts the AssertIsLessOrEqual which need no constraint, their bound being >= q-1
+	noRangeCheck = RangeCheckStrategy{kind: rangeCheckNone}
+)
+
+// RangeCheckDigits decomposes the value in base 2**k digits, each of them constrained by the
+// polynomial vanishing on [0, 2**k), of degree 2**k. k must be in [1, MaxRangeCheckDigitBits].
+func RangeCheckDigits(k int) RangeCheckStrategy {
+	return RangeCheckStrategy{kind: rangeCheckDigits, digitBits: k}
+}
+
+// String returns the name of the strategy, as counted in CompiledConstraintSystem.GetRangeChecks
+func (s RangeCheckStrategy) String() string {
+	switch s.kind {
+	case rangeCheckAuto:
+		return "auto"
+	case rangeCheckBits:
+		return "bits"
+	case rangeCheckDigits:
+		return "digits(" + strconv.Itoa(s.digitBits) + ")"
+	case rangeCheckLookup:
+		return "lookup"
+	case rangeCheckNone:
+		return "none"
+	default:
+		return "unknown"
+	}
+}
+
+// resolve returns the strategy to range check a value on nbBits bits
+func (s RangeCheckStrategy) resolve(zkpID backend.ID, nbBits int) RangeCheckStrategy {
+	if s.kind == rangeCheckLookup && zkpID != backend.PLONK {
+		s = RangeCheckAuto
+	}
+	if s.kind == rangeCheckAuto {
+		if zkpID == backend.PLONK && nbBits >= lookupDigitBits {
+			return RangeCheckLookup
+		}
+		return RangeCheckBits
+	}
+	return s
+}
+
+// mustBeInRangeStrategy ensures a < 2**nbBits, with the range check strategy of the constraint system.
+// nbBits must be less than the bit length of the modulus.
+func (cs *constraintSystem) mustBeInRangeStrategy(a Variable, nbBits int) {
+	s := cs.rangeCheckStrategy.resolve(cs.zkpID, nbBits)
+	cs.countRangeCheck(s)
+
+	if s.kind == rangeCheckBits {
+		cs.ToBinary(a, nbBits)
+		return
+	}
+
+	debug := cs.addDebugInfo("rangeCheck["+s.String()+"]", a, " < 2^"+strconv.Itoa(nbBits))
+
+	// a == Σ 2**(k*i) * d[i], the top digit having the remaining bits
+	nbDigits := (nbBits + s.digitBits - 1) / s.digitBits
+	var Σdi Variable
+	Σdi.linExp = make(compiled.LinearExpression, nbDigits)
+	var c big.Int
+	c.SetUint64(1)
+	for i := 0; i < nbDigits; i++ {
+		size := s.digitBits
+		if i == nbDigits-1 {
+			size = nbBits - i*s.digitBits
+		}
+		d := cs.NewAnnotatedHint(hint.IthDigitNamed, a, i, s.digitBits)[0]
+		if s.kind == rangeCheckLookup {
+			cs.mustBeByte(d, size)
+		} else {
+			cs.mustBeDigit(d, size, debug)
+		}
+		Σdi.linExp[i] = cs.makeTerm(Variable{visibility: compiled.Internal, id: d.id}, &c)
+		c.Lsh(&c, uint(s.digitBits))
+	}
+
+	// the sum is less than 2**nbBits < q, hence the decomposition is the integer one
+	cs.addConstraint(newR1C(Σdi, cs.one(), a), debug)
+}
+
+// mustBeDigit ensures d < 2**nbBits with the polynomial Π (d - i) for i in [0, 2**nbBits)
+func (cs *constraintSystem) mustBeDigit(d Variable, nbBits int, debug int) {
+	if nbBits == 1 {
+		cs.AssertIsBoolean(d)
+		return
+	}
+	last := 1<<nbBits - 1
+	p := d
+	for i := 1; i < last; i++ {
+		p = cs.Mul(p, cs.Sub(d, i))
+	}
+	cs.addConstraint(newR1C(p, cs.Sub(d, last), cs.Constant(0)), debug)
+}
+
+// mustBeByte ensures d < 2**nbBits, nbBits <= 8, with lookups in the table of the bytes: a digit
+// of less than 8 bits is looked up shifted too, which is a byte if and only if the digit is small enough
+func (cs *constraintSystem) mustBeByte(d Variable, nbBits int) {
+	if cs.bytesTable == nil {
+		entries := make([]big.Int, 1<<lookupDigitBits)
+		for i := 0; i < len(entries); i++ {
+			entries[i].SetUint64(uint64(i))
+		}
+		table := cs.AddTable("rangeCheck", entries)
+		cs.bytesTable = &table
+	}
+	cs.Lookup(*cs.bytesTable, d)
+	if nbBits < lookupDigitBits {
+		cs.Lookup(*cs.bytesTable, cs.Mul(d, 1<<(lookupDigitBits-nbBits)))
+	}
+}
+
+// countRangeCheck records a range check done with the strategy s, see GetRangeChecks
+func (cs *constraintSystem) countRangeCheck(s RangeCheckStrategy) {
+	if cs.rangeChecks == nil {
+		cs.rangeChecks = make(map[string]int)
+	}
+	cs.rangeChecks[s.String()]++
+}
+
+// checkRangeCheckStrategy returns an error if s isn't a valid strategy
+func checkRangeCheckStrategy(s RangeCheckStrategy) error {
+	if s.kind == rangeCheckDigits && (s.digitBits < 1 || s.digitBits > MaxRangeCheckDigitBits) {
+		return fmt.Errorf("RangeCheckDigits: the digit size must be in [1, %d], got %d", MaxRangeCheckDigitBits, s.digitBits)
+	}
+	if s.kind > rangeCheckLookup {
+		return fmt.Errorf("unknown range check strategy %d", s.kind)
+	}
+	return nil
+}
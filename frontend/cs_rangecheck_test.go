@@ -0,0 +1,236 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+var rangeCheckStrategies = []frontend.RangeCheckStrategy{
+	frontend.RangeCheckAuto,
+	frontend.RangeCheckBits,
+	frontend.RangeCheckDigits(2),
+	frontend.RangeCheckDigits(3),
+	frontend.RangeCheckLookup,
+}
+
+// lessOrEqualCircuit asserts X <= bound, or with nbBits > 0, X <= Bound on nbBits bits
+type lessOrEqualCircuit struct {
+	X      frontend.Variable
+	Bound  frontend.Variable `gnark:",public"`
+	bound  *big.Int
+	nbBits int
+}
+
+func (circuit *lessOrEqualCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	if circuit.nbBits > 0 {
+		api.AssertIsLessOrEqualBounded(circuit.X, circuit.Bound, circuit.nbBits)
+	} else {
+		api.AssertIsLessOrEqual(circuit.X, circuit.bound)
+		api.AssertIsEqual(circuit.Bound, circuit.bound)
+	}
+	return nil
+}
+
+func lessOrEqualWitness(x, bound *big.Int) *lessOrEqualCircuit {
+	var w lessOrEqualCircuit
+	w.X.Assign(x)
+	w.Bound.Assign(bound)
+	return &w
+}
+
+// TestRangeCheckStrategies checks that all the strategies accept the same values, at the bound
+func TestRangeCheckStrategies(t *testing.T) {
+	modulus := ecc.BN254.Info().Fr.Modulus()
+	shapes := map[string]struct {
+		bound  *big.Int
+		nbBits int
+	}{
+		"1":           {bound: big.NewInt(1)},
+		"2^8":         {bound: big.NewInt(1 << 8)},
+		"2^16-1":      {bound: big.NewInt(1<<16 - 1)},
+		"1000003":     {bound: big.NewInt(1000003)},
+		"2^70+12345":  {bound: new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 70), big.NewInt(12345))},
+		"q-2":         {bound: new(big.Int).Sub(modulus, big.NewInt(2))},
+		"bounded(20)": {bound: big.NewInt(1000003), nbBits: 20},
+	}
+
+	for name, shape := range shapes {
+		one := big.NewInt(1)
+		var valid, invalid []*lessOrEqualCircuit
+		for _, x := range []*big.Int{big.NewInt(0), new(big.Int).Sub(shape.bound, one), shape.bound} {
+			valid = append(valid, lessOrEqualWitness(x, shape.bound))
+		}
+		for _, x := range []*big.Int{new(big.Int).Add(shape.bound, one), new(big.Int).Sub(modulus, one)} {
+			invalid = append(invalid, lessOrEqualWitness(x, shape.bound))
+		}
+		if shape.nbBits > 0 {
+			// x <= bound, but they don't fit on nbBits: the top digit is too large
+			tooLarge := new(big.Int).Lsh(one, uint(shape.nbBits))
+			invalid = append(invalid, lessOrEqualWitness(tooLarge, tooLarge))
+		}
+
+		for _, strategy := range rangeCheckStrategies {
+			circuit := &lessOrEqualCircuit{bound: shape.bound, nbBits: shape.nbBits}
+
+			// the assert helper caches the compiled circuits by type, hence a new helper per strategy
+			assert := test.NewAssert(t)
+			opts := []func(opt *test.TestingOption) error{
+				test.WithCurves(ecc.BN254),
+				test.WithCompileOpts(frontend.WithRangeCheckStrategy(strategy)),
+			}
+			for _, w := range valid {
+				assert.ProverSucceeded(circuit, w, opts...)
+			}
+			for _, w := range invalid {
+				assert.ProverFailed(circuit, w, opts...)
+			}
+			if t.Failed() {
+				t.Fatalf("%s with %s", name, strategy)
+			}
+		}
+	}
+}
+
+// TestRangeCheckStats checks which strategy fires, per backend and bound size
+func TestRangeCheckStats(t *testing.T) {
+	assert := require.New(t)
+
+	large := new(big.Int).Lsh(big.NewInt(1), 253)
+	for _, c := range []struct {
+		strategy frontend.RangeCheckStrategy
+		zkpID    backend.ID
+		bound    *big.Int
+		expected string
+	}{
+		{frontend.RangeCheckAuto, backend.GROTH16, big.NewInt(1000003), "bits"},
+		{frontend.RangeCheckAuto, backend.PLONK, big.NewInt(1000003), "lookup"},
+		{frontend.RangeCheckAuto, backend.PLONK, big.NewInt(100), "bits"},
+		{frontend.RangeCheckAuto, backend.PLONK, large, "bits"},
+		{frontend.RangeCheckBits, backend.PLONK, big.NewInt(1000003), "bits"},
+		{frontend.RangeCheckDigits(4), backend.GROTH16, big.NewInt(1000003), "digits(4)"},
+		{frontend.RangeCheckDigits(4), backend.PLONK, large, "bits"},
+		{frontend.RangeCheckLookup, backend.PLONK, big.NewInt(100), "lookup"},
+		{frontend.RangeCheckLookup, backend.GROTH16, big.NewInt(1000003), "bits"},
+	} {
+		circuit := &lessOrEqualCircuit{bound: c.bound}
+		ccs, err := frontend.Compile(ecc.BN254, c.zkpID, circuit, frontend.WithRangeCheckStrategy(c.strategy))
+		assert.NoError(err)
+
+		// the canonical comparison of large bounds is a single range check
+		expected := map[string]int{c.expected: 2}
+		if c.bound == large {
+			expected[c.expected] = 1
+		}
+		assert.Equal(expected, ccs.GetRangeChecks(), "%s with %s, %s", c.bound, c.strategy, c.zkpID)
+	}
+
+	for _, k := range []int{0, frontend.MaxRangeCheckDigitBits + 1} {
+		_, err := frontend.Compile(ecc.BN254, backend.PLONK, &lessOrEqualCircuit{bound: big.NewInt(3)}, frontend.WithRangeCheckStrategy(frontend.RangeCheckDigits(k)))
+		assert.Error(err)
+	}
+}
+
+// constantBoundCircuit asserts X <= bound, bound being a constant of any type; Y = X**2 gives the
+// provers a public input
+type constantBoundCircuit struct {
+	X     frontend.Variable
+	Y     frontend.Variable `gnark:",public"`
+	bound interface{}
+}
+
+func (circuit *constantBoundCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsLessOrEqual(circuit.X, circuit.bound)
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+// TestAssertIsLessOrEqualConstantBounds checks the edge cases of the constant bounds, on both backends
+// and the test engine
+func TestAssertIsLessOrEqualConstantBounds(t *testing.T) {
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377} {
+		modulus := curveID.Info().Fr.Modulus()
+		one := big.NewInt(1)
+		qMinus := func(i int64) *big.Int { return new(big.Int).Sub(modulus, big.NewInt(i)) }
+		witness := func(x *big.Int) *constantBoundCircuit {
+			var w constantBoundCircuit
+			w.X.Assign(x)
+			w.Y.Assign(new(big.Int).Mul(x, x))
+			return &w
+		}
+
+		maxInt64 := new(big.Int).SetInt64(math.MaxInt64)
+		maxUint64 := new(big.Int).SetUint64(math.MaxUint64)
+		for _, c := range []struct {
+			name    string
+			bound   interface{}
+			valid   []*big.Int
+			invalid []*big.Int
+			none    bool // no constraint is needed
+		}{
+			{"0", 0, []*big.Int{big.NewInt(0)}, []*big.Int{one, qMinus(1)}, false},
+			{"int32", int32(7), []*big.Int{big.NewInt(0), big.NewInt(7)}, []*big.Int{big.NewInt(8)}, false},
+			{"int64", int64(math.MaxInt64), []*big.Int{maxInt64}, []*big.Int{new(big.Int).Add(maxInt64, one)}, false},
+			{"uint64", uint64(math.MaxUint64), []*big.Int{maxUint64}, []*big.Int{new(big.Int).Add(maxUint64, one)}, false},
+			{"q-2", qMinus(2).String(), []*big.Int{qMinus(2)}, []*big.Int{qMinus(1)}, false},
+			{"q-1", qMinus(1).String(), []*big.Int{big.NewInt(0), qMinus(1)}, nil, true},
+			{"q", *modulus, []*big.Int{qMinus(1)}, nil, true},
+			{"2^300", new(big.Int).Lsh(one, 300), []*big.Int{qMinus(1)}, nil, true},
+		} {
+			circuit := &constantBoundCircuit{bound: c.bound}
+
+			assert := test.NewAssert(t)
+			for _, x := range c.valid {
+				assert.ProverSucceeded(circuit, witness(x), test.WithCurves(curveID))
+			}
+			for _, x := range c.invalid {
+				assert.ProverFailed(circuit, witness(x), test.WithCurves(curveID))
+			}
+
+			for _, b := range backend.Implemented() {
+				ccs, err := frontend.Compile(curveID, b, circuit)
+				assert.NoError(err)
+				if c.none {
+					assert.Equal(map[string]int{"none": 1}, ccs.GetRangeChecks(), "%s on %s, %s", c.name, curveID, b)
+				} else {
+					assert.Zero(ccs.GetRangeChecks()["none"], "%s on %s, %s", c.name, curveID, b)
+				}
+			}
+			if t.Failed() {
+				t.Fatalf("%s on %s", c.name, curveID)
+			}
+		}
+
+		// negative bounds are rejected
+		for _, bound := range []interface{}{-1, "-5", big.NewInt(-1)} {
+			circuit := &constantBoundCircuit{bound: bound}
+			for _, b := range backend.Implemented() {
+				_, err := frontend.Compile(curveID, b, circuit)
+				require.Error(t, err, "%v on %s, %s", bound, curveID, b)
+			}
+			require.Error(t, test.IsSolved(circuit, witness(big.NewInt(0)), curveID), "%v on %s", bound, curveID)
+		}
+	}
+}
@@ -21,7 +21,9 @@ import (
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/stretchr/testify/require"
 )
 
 func TestQuickSort(t *testing.T) {
@@ -143,3 +145,60 @@ func TestFindUnsolvedVariable(t *testing.T) {
 		solvedVariables[i] = true
 	}
 }
+
+type capacityCircuit struct {
+	A, B, C, D Variable
+	Y          Variable `gnark:",public"`
+}
+
+func (circuit *capacityCircuit) Define(curveID ecc.ID, api API) error {
+	api.AssertIsEqual(api.Add(circuit.A, circuit.B, circuit.C, circuit.D), circuit.Y)
+	return nil
+}
+
+func TestTooManyWires(t *testing.T) {
+	assert := require.New(t)
+	defer func(n int) { maxNbWires = n }(maxNbWires)
+
+	// the inputs don't fit (ONE_WIRE + 5 inputs)
+	maxNbWires = 5
+	for _, zkpID := range []backend.ID{backend.GROTH16, backend.PLONK} {
+		_, err := Compile(ecc.BN254, zkpID, &capacityCircuit{})
+		assert.ErrorIs(err, ErrTooManyWires, zkpID.String())
+	}
+
+	// the inputs fit, but splitting the linear expression in PLONK constraints creates new wires
+	maxNbWires = 6
+	_, err := Compile(ecc.BN254, backend.GROTH16, &capacityCircuit{})
+	assert.NoError(err)
+	_, err = Compile(ecc.BN254, backend.PLONK, &capacityCircuit{})
+	assert.ErrorIs(err, ErrTooManyWires)
+}
+
+type coefficientsCircuit struct {
+	A, B Variable
+	Y    Variable `gnark:",public"`
+}
+
+func (circuit *coefficientsCircuit) Define(curveID ecc.ID, api API) error {
+	api.AssertIsEqual(api.Add(api.Mul(circuit.A, 5), api.Mul(circuit.B, 7)), circuit.Y)
+	return nil
+}
+
+func TestTooManyCoefficients(t *testing.T) {
+	assert := require.New(t)
+	defer func(n int) { maxNbCoeffs = n }(maxNbCoeffs)
+
+	// 0, 1, 2, -1 are always allocated, there is room for 5 but not for 7
+	maxNbCoeffs = 5
+	for _, zkpID := range []backend.ID{backend.GROTH16, backend.PLONK} {
+		_, err := Compile(ecc.BN254, zkpID, &coefficientsCircuit{})
+		assert.ErrorIs(err, ErrTooManyCoefficients, zkpID.String())
+	}
+
+	maxNbCoeffs = 6
+	for _, zkpID := range []backend.ID{backend.GROTH16, backend.PLONK} {
+		_, err := Compile(ecc.BN254, zkpID, &coefficientsCircuit{})
+		assert.NoError(err, zkpID.String())
+	}
+}
@@ -24,41 +24,43 @@ import (
 	bls12381r1cs "github.com/consensys/gnark/internal/backend/bls12-381/cs"
 	bls24315r1cs "github.com/consensys/gnark/internal/backend/bls24-315/cs"
 	bn254r1cs "github.com/consensys/gnark/internal/backend/bn254/cs"
+	bw6633r1cs "github.com/consensys/gnark/internal/backend/bw6-633/cs"
 	bw6761r1cs "github.com/consensys/gnark/internal/backend/bw6-761/cs"
 )
 
 // toR1CS constructs a rank-1 constraint sytem
-func (cs *constraintSystem) toR1CS(curveID ecc.ID) (CompiledConstraintSystem, error) {
+func (cs *constraintSystem) toR1CS(curveID ecc.ID) (_ CompiledConstraintSystem, err error) {
+	defer recoverCapacityError(&err)
+
+	if len(cs.lookups) != 0 {
+		return nil, errLookupNotSupported
+	}
 
 	// wires = public wires  | secret wires | internal wires
 
 	// setting up the result
 	res := compiled.R1CS{
 		CS: compiled.CS{
-			NbInternalVariables: len(cs.internal.variables),
-			NbPublicVariables:   len(cs.public.variables.variables),
-			NbSecretVariables:   len(cs.secret.variables.variables),
-			DebugInfo:           make([]compiled.LogEntry, len(cs.debugInfo)),
-			Logs:                make([]compiled.LogEntry, len(cs.logs)),
-			MHints:              make(map[int]compiled.Hint, len(cs.mHints)),
-			MDebug:              make(map[int]int),
+			NbInternalVariables:     len(cs.internal.variables),
+			NbPublicVariables:       len(cs.public.variables.variables),
+			NbSecretVariables:       len(cs.secret.variables.variables),
+			DebugInfo:               make([]compiled.LogEntry, len(cs.debugInfo)),
+			Logs:                    make([]compiled.LogEntry, len(cs.logs)),
+			MHints:                  make(map[int]compiled.Hint, len(cs.mHints)),
+			MDebug:                  make(map[int]int),
+			Metadata:                cs.metadata,
+			OptionalSecrets:         cs.optionalSecrets,
+			RangeChecks:             cs.rangeChecks,
+			NbEliminatedConstraints: cs.nbEliminatedConstraints(),
+			NbReducedHintInputs:     len(cs.hintInputWires),
 		},
-		Constraints: make([]compiled.R1C, len(cs.constraints)),
+		Version: compiled.R1CSVersion,
 	}
 
 	// for logs, debugInfo and hints the only thing that will change
 	// is that ID of the wires will be offseted to take into account the final wire vector ordering
 	// that is: public wires  | secret wires | internal wires
 
-	// computational constraints (= gates)
-	for i, r1c := range cs.constraints {
-		res.Constraints[i] = compiled.R1C{
-			L: r1c.L.Clone(),
-			R: r1c.R.Clone(),
-			O: r1c.O.Clone(),
-		}
-	}
-
 	// for a R1CS, the correspondance between constraint and debug info won't change, we just copy
 	for k, v := range cs.mDebug {
 		res.MDebug[k] = v
@@ -85,21 +87,52 @@ func (cs *constraintSystem) toR1CS(curveID ecc.ID) (CompiledConstraintSystem, er
 		}
 	}
 
-	for i := 0; i < len(res.Constraints); i++ {
-		offsetIDs(res.Constraints[i].L)
-		offsetIDs(res.Constraints[i].R)
-		offsetIDs(res.Constraints[i].O)
+	// boolean wires, the virtual variables (linear expressions) marked as boolean are not wires
+	res.Booleans = compiled.NewBitSet(res.NbInternalVariables + res.NbPublicVariables + res.NbSecretVariables)
+	for vID := range cs.public.booleans {
+		res.Booleans.Set(shiftVID(vID, compiled.Public))
+	}
+	for vID := range cs.secret.booleans {
+		res.Booleans.Set(shiftVID(vID, compiled.Secret))
+	}
+	for vID := range cs.internal.booleans {
+		res.Booleans.Set(shiftVID(vID, compiled.Internal))
+	}
+
+	// computational constraints (= gates), stored in a compact list (see compiled.R1CList)
+	constraints := make([]compiled.R1C, len(cs.constraints))
+	for i, r1c := range cs.constraints {
+		constraints[i] = compiled.R1C{
+			L: r1c.L.Clone(),
+			R: r1c.R.Clone(),
+			O: r1c.O.Clone(),
+		}
+		offsetIDs(constraints[i].L)
+		offsetIDs(constraints[i].R)
+		offsetIDs(constraints[i].O)
 	}
+	res.Constraints = compiled.NewR1CListWithLoops(constraints, cs.loops)
+	res.Loops = cs.loops
+	res.Tags = cs.tags
 
 	// we need to offset the ids in the hints
 	for vID, hint := range cs.mHints {
 		k := shiftVID(vID, compiled.Internal)
+		// inputs are cloned, as they are shared by all the output wires of the hint
 		inputs := make([]compiled.LinearExpression, len(hint.Inputs))
-		copy(inputs, hint.Inputs)
 		for j := 0; j < len(inputs); j++ {
+			inputs[j] = hint.Inputs[j].Clone()
 			offsetIDs(inputs[j])
 		}
-		res.MHints[k] = compiled.Hint{ID: hint.ID, Inputs: inputs}
+		wires := make([]int, len(hint.Wires))
+		for j := 0; j < len(wires); j++ {
+			wires[j] = shiftVID(hint.Wires[j], compiled.Internal)
+		}
+		res.MHints[k] = compiled.Hint{ID: hint.ID, Name: hint.Name, Inputs: inputs, Wires: wires, Static: hint.Static}
+	}
+	res.PublicOutputs = cs.compilePublicOutputs(res.MHints, func(id int) int { return shiftVID(id, compiled.Public) }, offsetIDs)
+	if err := compiled.LinkHints(res.MHints); err != nil {
+		return nil, err
 	}
 
 	// we need to offset the ids in logs & debugInfo
@@ -128,6 +161,13 @@ func (cs *constraintSystem) toR1CS(curveID ecc.ID) (CompiledConstraintSystem, er
 		}
 	}
 
+	// and in the outputs
+	res.Outputs = make([]compiled.Output, len(cs.outputs))
+	for i := 0; i < len(cs.outputs); i++ {
+		res.Outputs[i] = compiled.Output{Name: cs.outputs[i].Name, Value: cs.outputs[i].Value.Clone()}
+		offsetIDs(res.Outputs[i].Value)
+	}
+
 	switch curveID {
 	case ecc.BLS12_377:
 		return bls12377r1cs.NewR1CS(res, cs.coeffs), nil
@@ -137,6 +177,8 @@ func (cs *constraintSystem) toR1CS(curveID ecc.ID) (CompiledConstraintSystem, er
 		return bn254r1cs.NewR1CS(res, cs.coeffs), nil
 	case ecc.BW6_761:
 		return bw6761r1cs.NewR1CS(res, cs.coeffs), nil
+	case ecc.BW6_633:
+		return bw6633r1cs.NewR1CS(res, cs.coeffs), nil
 	case ecc.BLS24_315:
 		return bls24315r1cs.NewR1CS(res, cs.coeffs), nil
 	case ecc.UNKNOWN:
@@ -17,6 +17,7 @@ limitations under the License.
 package frontend
 
 import (
+	"errors"
 	"math/big"
 	"sort"
 	"sync"
@@ -28,6 +29,7 @@ import (
 	bls12381r1cs "github.com/consensys/gnark/internal/backend/bls12-381/cs"
 	bls24315r1cs "github.com/consensys/gnark/internal/backend/bls24-315/cs"
 	bn254r1cs "github.com/consensys/gnark/internal/backend/bn254/cs"
+	bw6633r1cs "github.com/consensys/gnark/internal/backend/bw6-633/cs"
 	bw6761r1cs "github.com/consensys/gnark/internal/backend/bw6-761/cs"
 )
 
@@ -69,21 +71,41 @@ type innerRecord struct {
 	l compiled.LinearExpression
 }
 
-var bOne = new(big.Int).SetInt64(1)
+var (
+	bOne      = new(big.Int).SetInt64(1)
+	bMinusOne = new(big.Int).SetInt64(-1)
+)
 
-func (cs *constraintSystem) toSparseR1CS(curveID ecc.ID) (CompiledConstraintSystem, error) {
+// recoverCapacityError recovers the panics ErrTooManyWires, ErrTooManyCoefficients and ErrTooManyTerms,
+// and sets err to the recovered error. It must be deferred.
+func recoverCapacityError(err *error) {
+	if r := recover(); r != nil {
+		if e, ok := r.(error); ok && (errors.Is(e, ErrTooManyWires) || errors.Is(e, ErrTooManyCoefficients) || errors.Is(e, ErrTooManyTerms)) {
+			*err = e
+			return
+		}
+		panic(r)
+	}
+}
 
-	res := sparseR1CS{
+// newSparseR1CS returns the intermediate data structures to convert cs to a SparseR1CS
+func newSparseR1CS(cs *constraintSystem) sparseR1CS {
+	return sparseR1CS{
 		constraintSystem: cs,
 		ccs: compiled.SparseR1CS{
 			CS: compiled.CS{
-				NbInternalVariables: len(cs.internal.variables),
-				NbPublicVariables:   len(cs.public.variables.variables) - 1, // the ONE_WIRE is discarded in PlonK
-				NbSecretVariables:   len(cs.secret.variables.variables),
-				DebugInfo:           make([]compiled.LogEntry, len(cs.debugInfo)),
-				Logs:                make([]compiled.LogEntry, len(cs.logs)),
-				MDebug:              make(map[int]int),
-				MHints:              make(map[int]compiled.Hint),
+				NbInternalVariables:     len(cs.internal.variables),
+				NbPublicVariables:       len(cs.public.variables.variables) - 1, // the ONE_WIRE is discarded in PlonK
+				NbSecretVariables:       len(cs.secret.variables.variables),
+				DebugInfo:               make([]compiled.LogEntry, len(cs.debugInfo)),
+				Logs:                    make([]compiled.LogEntry, len(cs.logs)),
+				MDebug:                  make(map[int]int),
+				MHints:                  make(map[int]compiled.Hint),
+				Metadata:                cs.metadata,
+				OptionalSecrets:         cs.optionalSecrets,
+				RangeChecks:             cs.rangeChecks,
+				NbEliminatedConstraints: cs.nbEliminatedConstraints(),
+				NbReducedHintInputs:     len(cs.hintInputWires),
 			},
 			Constraints: make([]compiled.SparseR1C, 0, len(cs.constraints)),
 		},
@@ -93,10 +115,12 @@ func (cs *constraintSystem) toSparseR1CS(curveID ecc.ID) (CompiledConstraintSyst
 		reducedLE:            make(map[uint64][]innerRecord, len(cs.internal.variables)),
 		reducedLE_:           make(map[uint64]struct{}, len(cs.internal.variables)),
 	}
+}
 
-	// logs, debugInfo and hints are copied, the only thing that will change
-	// is that ID of the wires will be offseted to take into account the final wire vector ordering
-	// that is: public wires  | secret wires | internal wires
+// convertConstraints converts the R1C and the lookups of the constraint system into
+// SparseR1C, the wires IDs are not offseted yet
+func (res *sparseR1CS) convertConstraints() {
+	cs := res.constraintSystem
 
 	// we mark hint wires are solved
 	// each R1C from the frontend.ConstraintSystem is allowed to have at most one unsolved wire
@@ -109,7 +133,15 @@ func (cs *constraintSystem) toSparseR1CS(curveID ecc.ID) (CompiledConstraintSyst
 	// convert the R1C to SparseR1C
 	// in particular, all linear expressions that appear in the R1C
 	// will be split in multiple constraints in the SparseR1C
+	// the R1C i becomes the SparseR1C [starts[i], starts[i+1]), the loops and the tags are remapped accordingly
+	var starts []int
+	if cs.loops != nil || cs.tags != nil {
+		starts = make([]int, len(cs.constraints)+1)
+	}
 	for i := 0; i < len(cs.constraints); i++ {
+		if starts != nil {
+			starts[i] = len(res.ccs.Constraints)
+		}
 		// we set currentR1CDebugID to the debugInfo ID corresponding to the R1C we're processing
 		// if present. All constraints created throuh addConstraint will add a new mapping
 		if dID, ok := cs.mDebug[i]; ok {
@@ -119,6 +151,36 @@ func (cs *constraintSystem) toSparseR1CS(curveID ecc.ID) (CompiledConstraintSyst
 		}
 		res.r1cToSparseR1C(cs.constraints[i])
 	}
+	if starts != nil {
+		starts[len(cs.constraints)] = len(res.ccs.Constraints)
+		if cs.loops != nil {
+			res.ccs.Loops = cs.loops.Remap(starts)
+		}
+		if cs.tags != nil {
+			res.ccs.Tags = cs.tags.Remap(starts)
+		}
+	}
+
+	// convert the lookups; they come last, at this stage all the wires they reference are solved
+	res.ccs.Tables = make([]compiled.LookupTable, len(cs.tables))
+	copy(res.ccs.Tables, cs.tables)
+	for _, l := range cs.lookups {
+		res.currentR1CDebugID = l.debugID
+		res.lookupToSparseR1C(l)
+	}
+}
+
+func (cs *constraintSystem) toSparseR1CS(curveID ecc.ID) (ccs CompiledConstraintSystem, err error) {
+	// splitting the linear expressions creates new wires and coefficients,
+	// which may exceed the capacity of the constraint system
+	defer recoverCapacityError(&err)
+
+	res := newSparseR1CS(cs)
+	res.convertConstraints()
+
+	// logs, debugInfo and hints are copied, the only thing that will change
+	// is that ID of the wires will be offseted to take into account the final wire vector ordering
+	// that is: public wires  | secret wires | internal wires
 
 	// shift variable ID
 	// we want publicWires | privateWires | internalWires
@@ -142,8 +204,7 @@ func (cs *constraintSystem) toSparseR1CS(curveID ecc.ID) (CompiledConstraintSyst
 			// need to skip them.
 			return
 		}
-		_, vID, visibility := t.Unpack()
-		if vID == 0 && visibility == compiled.Public {
+		if t.IsConstant() {
 			// this would not happen in a plonk constraint as the constant term has been popped out
 			// however it may happen in the logs or the hints that contains
 			// terms associated with the ONE wire
@@ -151,6 +212,7 @@ func (cs *constraintSystem) toSparseR1CS(curveID ecc.ID) (CompiledConstraintSyst
 			t.SetVariableVisibility(compiled.Virtual)
 			return
 		}
+		_, vID, visibility := t.Unpack()
 		t.SetVariableID(shiftVID(vID, visibility))
 	}
 
@@ -190,17 +252,39 @@ func (cs *constraintSystem) toSparseR1CS(curveID ecc.ID) (CompiledConstraintSyst
 		}
 	}
 
+	// and in the outputs
+	res.ccs.Outputs = make([]compiled.Output, len(cs.outputs))
+	for i := 0; i < len(cs.outputs); i++ {
+		res.ccs.Outputs[i] = compiled.Output{Name: cs.outputs[i].Name, Value: cs.outputs[i].Value.Clone()}
+		for j := 0; j < len(res.ccs.Outputs[i].Value); j++ {
+			offsetTermID(&res.ccs.Outputs[i].Value[j])
+		}
+	}
+
 	// we need to offset the ids in the hints
 	for vID, hint := range cs.mHints {
 		k := shiftVID(vID, compiled.Internal)
+		// inputs are cloned, as they are shared by all the output wires of the hint
 		inputs := make([]compiled.LinearExpression, len(hint.Inputs))
-		copy(inputs, hint.Inputs)
 		for j := 0; j < len(inputs); j++ {
+			inputs[j] = hint.Inputs[j].Clone()
 			for k := 0; k < len(inputs[j]); k++ {
 				offsetTermID(&inputs[j][k])
 			}
 		}
-		res.ccs.MHints[k] = compiled.Hint{ID: hint.ID, Inputs: inputs}
+		wires := make([]int, len(hint.Wires))
+		for j := 0; j < len(wires); j++ {
+			wires[j] = shiftVID(hint.Wires[j], compiled.Internal)
+		}
+		res.ccs.MHints[k] = compiled.Hint{ID: hint.ID, Name: hint.Name, Inputs: inputs, Wires: wires, Static: hint.Static}
+	}
+	res.ccs.PublicOutputs = cs.compilePublicOutputs(res.ccs.MHints, func(id int) int { return shiftVID(id, compiled.Public) }, func(l compiled.LinearExpression) {
+		for j := 0; j < len(l); j++ {
+			offsetTermID(&l[j])
+		}
+	})
+	if err := compiled.LinkHints(res.ccs.MHints); err != nil {
+		return nil, err
 	}
 
 	// update number of internal variables with new wires created
@@ -216,6 +300,8 @@ func (cs *constraintSystem) toSparseR1CS(curveID ecc.ID) (CompiledConstraintSyst
 		return bn254r1cs.NewSparseR1CS(res.ccs, cs.coeffs), nil
 	case ecc.BW6_761:
 		return bw6761r1cs.NewSparseR1CS(res.ccs, cs.coeffs), nil
+	case ecc.BW6_633:
+		return bw6633r1cs.NewSparseR1CS(res.ccs, cs.coeffs), nil
 	case ecc.BLS24_315:
 		return bls24315r1cs.NewSparseR1CS(res.ccs, cs.coeffs), nil
 	default:
@@ -425,11 +511,8 @@ func (scs *sparseR1CS) divideLinearExpression(l, r compiled.LinearExpression, di
 //
 // ex: if l = <expr> + k1*ONE_WIRE the function returns <expr>, k1.
 func (scs *sparseR1CS) popConstantTerm(l compiled.LinearExpression) (compiled.LinearExpression, big.Int) {
-
-	const idOneWire = 0
-
 	for i := 0; i < len(l); i++ {
-		if l[i].VariableID() == idOneWire && l[i].VariableVisibility() == compiled.Public {
+		if l[i].IsConstant() {
 			lCopy := make(compiled.LinearExpression, len(l)-1)
 			copy(lCopy, l[:i])
 			copy(lCopy[i:], l[i+1:])
@@ -450,6 +533,9 @@ func (scs *sparseR1CS) newTerm(coeff *big.Int, idCS ...int) compiled.Term {
 		scs.solvedVariables[vID] = true
 	} else {
 		vID = scs.scsInternalVariables
+		if scs.ccs.NbPublicVariables+scs.ccs.NbSecretVariables+vID >= maxNbWires {
+			panic(ErrTooManyWires)
+		}
 		scs.scsInternalVariables++
 		scs.solvedVariables = append(scs.solvedVariables, true)
 	}
@@ -674,6 +760,29 @@ func (scs *sparseR1CS) split(l compiled.LinearExpression) compiled.Term {
 	return r
 }
 
+// lookupToSparseR1C adds a constraint L=v with null coefficients, and records it as a lookup.
+// If v is not a single wire, a constraint computing v is added first.
+func (scs *sparseR1CS) lookupToSparseR1C(l lookup) {
+	sort.Sort(l.linExp)
+	le, k := scs.popConstantTerm(l.linExp)
+	t := scs.split(le)
+
+	if k.Sign() != 0 || t.CoeffID() != compiled.CoeffIdOne {
+		// t + k - o == 0
+		o := scs.newTerm(bOne)
+		scs.addConstraint(compiled.SparseR1C{L: t, O: scs.negate(o), K: scs.coeffID(&k)})
+		t = o
+	}
+
+	// the gate is trivially satisfied, the lookup argument constrains L
+	t.SetCoeffID(compiled.CoeffIdZero)
+	scs.addConstraint(compiled.SparseR1C{L: t})
+	scs.ccs.Lookups = append(scs.ccs.Lookups, compiled.Lookup{
+		Constraint: len(scs.ccs.Constraints) - 1,
+		Table:      int(l.table),
+	})
+}
+
 // r1cToSparseR1C splits a r1c constraint
 func (scs *sparseR1CS) r1cToSparseR1C(r1c compiled.R1C) {
 
@@ -1112,6 +1221,31 @@ func (scs *sparseR1CS) splitR1C(r1c compiled.R1C) {
 
 	var cK big.Int
 
+	// (l + cL)*cR = o + cO, or cL*(r + cR) = o + cO, is linear: cR*l - o + cL*cR - cO == 0 is
+	// asserted in the addition chain of its terms, see assertLinearExpression. If a side was
+	// already reduced to a single wire by a previous constraint, comparing the reduced sides
+	// is cheaper, and the assertion is split as any R1C.
+	if len(l) == 0 || len(r) == 0 {
+		lin, cLin, c := l, cL, cR
+		if len(l) == 0 {
+			lin, cLin, c = r, cR, cL
+		}
+		if !scs.wasSplit(lin) && !scs.wasSplit(o) {
+			// the shorter side is negated, not to create the opposite coefficients of the longer one
+			var minusC big.Int
+			cK.Mul(&cLin, &c)
+			cK.Sub(&cK, &cO)
+			if len(o) < len(lin) {
+				scs.assertLinearExpression(scs.linearCombination(lin, &c, o, bMinusOne), &cK)
+			} else {
+				cK.Neg(&cK)
+				minusC.Neg(&c)
+				scs.assertLinearExpression(scs.linearCombination(o, bOne, lin, &minusC), &cK)
+			}
+			return
+		}
+	}
+
 	if len(o) == 0 {
 
 		if len(l) == 0 {
@@ -1242,6 +1376,60 @@ func (scs *sparseR1CS) splitR1C(r1c compiled.R1C) {
 	}
 }
 
+// wasSplit returns true if l has several terms, and was already reduced to a single term by split
+func (scs *sparseR1CS) wasSplit(l compiled.LinearExpression) bool {
+	if len(l) < 2 {
+		return false
+	}
+	gcd := bigIntPool.Get().(*big.Int)
+	scs.computeGCD(l, gcd)
+	_, ok := scs.wasReduced(scs.divideLinearExpression(l, nil, gcd))
+	bigIntPool.Put(gcd)
+	return ok
+}
+
+// linearCombination returns ca*a + cb*b, sorted, with the terms of a same variable collapsed and
+// without the null terms
+func (scs *sparseR1CS) linearCombination(a compiled.LinearExpression, ca *big.Int, b compiled.LinearExpression, cb *big.Int) compiled.LinearExpression {
+	res := make(compiled.LinearExpression, 0, len(a)+len(b))
+	for _, t := range a {
+		res = append(res, scs.multiply(t, ca))
+	}
+	for _, t := range b {
+		res = append(res, scs.multiply(t, cb))
+	}
+	res = scs.reduce(res)
+
+	j := 0
+	for _, t := range res {
+		if t.CoeffID() != compiled.CoeffIdZero && scs.coeffs[t.CoeffID()].Sign() != 0 {
+			res[j] = t
+			j++
+		}
+	}
+	return res[:j]
+}
+
+// assertLinearExpression adds the constraints l + k == 0, l being sorted.
+//
+// The comparison is folded in the last gate of the addition chain: the first terms are reduced to
+// a single term t, and the gate t + l[n-2] + l[n-1] + k == 0 checks the sum. A linear expression
+// of n terms costs max(n-2, 1) gates, instead of the n-1 gates reducing it to a single wire, and
+// the gate comparing the wire.
+func (scs *sparseR1CS) assertLinearExpression(l compiled.LinearExpression, k *big.Int) {
+	c := compiled.SparseR1C{K: scs.coeffID(k)}
+	switch n := len(l); n {
+	case 0:
+	case 1:
+		c.L = l[0]
+	case 2:
+		c.L, c.R = l[0], l[1]
+	default:
+		c.L, c.R, c.O = scs.split(l[:n-2]), l[n-2], l[n-1]
+	}
+	scs.addConstraint(c)
+}
+
 var bigIntPool = sync.Pool{
 	New: func() interface{} {
 		return new(big.Int)
@@ -0,0 +1,90 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"encoding/binary"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// subexpressions caches the results of the operations of the circuit, see WithCSE
+type subexpressions struct {
+	results      map[string]subexpression
+	nbEliminated int // constraints not added, as their operation was already computed
+}
+
+// subexpression is the result of an operation, and the number of constraints computing it
+type subexpression struct {
+	vars          []Variable
+	nbConstraints int
+}
+
+// memoize returns the result of the operation op on operands: with WithCSE, the result of a previous
+// call with the same operands, else compute(). The operands of a commutative operation are unordered.
+//
+// compute must only add constraints between its operands and its result, such that the result is the
+// same whenever the operands are: the outputs of NewHint aren't cached, each call creates new wires.
+func (cs *constraintSystem) memoize(op string, commutative bool, operands []Variable, compute func() []Variable) []Variable {
+	if cs.cse == nil {
+		return compute()
+	}
+
+	keys := make([]string, len(operands))
+	for i, v := range operands {
+		keys[i] = cs.subexpressionKey(v)
+	}
+	if commutative {
+		sort.Strings(keys)
+	}
+	key := op + "(" + strconv.Itoa(len(keys)) + ")" + strings.Join(keys, "")
+
+	if r, ok := cs.cse.results[key]; ok {
+		cs.cse.nbEliminated += r.nbConstraints
+		return append([]Variable(nil), r.vars...)
+	}
+
+	nbConstraints := len(cs.constraints)
+	res := compute()
+	cs.cse.results[key] = subexpression{
+		vars:          append([]Variable(nil), res...),
+		nbConstraints: len(cs.constraints) - nbConstraints,
+	}
+	return res
+}
+
+// nbEliminatedConstraints returns the number of constraints WithCSE didn't add
+func (cs *constraintSystem) nbEliminatedConstraints() int {
+	if cs.cse == nil {
+		return 0
+	}
+	return cs.cse.nbEliminated
+}
+
+// subexpressionKey returns the canonical encoding of the linear expression of v: its terms, sorted by
+// visibility and wire ID, prefixed with their number
+func (cs *constraintSystem) subexpressionKey(v Variable) string {
+	l := v.linExp.Clone()
+	sort.Sort(l)
+	buf := make([]byte, 8*(len(l)+1))
+	binary.BigEndian.PutUint64(buf, uint64(len(l)))
+	for i, t := range l {
+		binary.BigEndian.PutUint64(buf[8*(i+1):], uint64(t))
+	}
+	return string(buf)
+}
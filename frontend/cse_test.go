@@ -0,0 +1,121 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// squaresCircuit computes x*x three times: Y = x*x + x*x, Z = x*x + 1
+type squaresCircuit struct {
+	X frontend.Variable `gnark:",secret"`
+	Y frontend.Variable `gnark:",public"`
+	Z frontend.Variable `gnark:",public"`
+}
+
+func (circuit *squaresCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(circuit.Y, api.Add(api.Mul(circuit.X, circuit.X), api.Mul(circuit.X, circuit.X)))
+	api.AssertIsEqual(circuit.Z, api.Add(api.Mul(circuit.X, circuit.X), 1))
+	return nil
+}
+
+func squaresAssignment() *squaresCircuit {
+	var w squaresCircuit
+	w.X.Assign(3)
+	w.Y.Assign(18)
+	w.Z.Assign(10)
+	return &w
+}
+
+func TestCSE(t *testing.T) {
+	for _, b := range []backend.ID{backend.GROTH16, backend.PLONK} {
+		t.Run(b.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			without, err := frontend.Compile(ecc.BN254, b, &squaresCircuit{})
+			assert.NoError(err)
+			with, err := frontend.Compile(ecc.BN254, b, &squaresCircuit{}, frontend.WithCSE())
+			assert.NoError(err)
+
+			assert.Equal(0, without.GetNbEliminatedConstraints())
+			assert.Equal(2, with.GetNbEliminatedConstraints())
+			if b == backend.GROTH16 {
+				// 3 multiplications and 2 equalities, or a single multiplication
+				assert.Equal(5, without.GetNbConstraints())
+				assert.Equal(3, with.GetNbConstraints())
+			} else {
+				assert.Less(with.GetNbConstraints(), without.GetNbConstraints())
+			}
+		})
+	}
+
+	// the assert helper caches the compiled circuits by type, hence a new helper per option
+	test.NewAssert(t).ProverSucceeded(&squaresCircuit{}, squaresAssignment(), test.WithCurves(ecc.BN254))
+	test.NewAssert(t).ProverSucceeded(&squaresCircuit{}, squaresAssignment(), test.WithCurves(ecc.BN254), test.WithCompileOpts(frontend.WithCSE()))
+}
+
+// cseOperationsCircuit repeats the operations WithCSE caches, with commuted operands, and hints
+type cseOperationsCircuit struct {
+	X frontend.Variable `gnark:",secret"`
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *cseOperationsCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	sum := api.Add(circuit.X, circuit.Y)
+	api.AssertIsEqual(api.Mul(sum, circuit.Y), api.Mul(circuit.Y, api.Add(circuit.Y, circuit.X)))
+	api.AssertIsEqual(api.Div(circuit.X, circuit.Y), api.Div(circuit.X, circuit.Y))
+	api.AssertIsEqual(api.Inverse(circuit.X), api.Inverse(circuit.X))
+	api.AssertIsEqual(api.IsZero(circuit.X), api.IsZero(circuit.X))
+	api.AssertIsEqual(api.FromBinary(api.ToBinary(circuit.X, 8)...), api.FromBinary(api.ToBinary(circuit.X, 8)...))
+
+	// each hint call creates new wires
+	h1, h2 := api.NewHint(hint.IsZero, circuit.X), api.NewHint(hint.IsZero, circuit.X)
+	api.AssertIsBoolean(h1)
+	api.AssertIsBoolean(h2)
+	return nil
+}
+
+func TestCSEOperations(t *testing.T) {
+	assert := require.New(t)
+
+	without, err := frontend.Compile(ecc.BN254, backend.GROTH16, &cseOperationsCircuit{})
+	assert.NoError(err)
+	with, err := frontend.Compile(ecc.BN254, backend.GROTH16, &cseOperationsCircuit{}, frontend.WithCSE())
+	assert.NoError(err)
+
+	// mul: 1, div: 2, inverse: 1, isZero: 2, toBinary: 8 booleans and the recomposition
+	assert.Equal(15, with.GetNbEliminatedConstraints())
+	assert.Equal(without.GetNbConstraints()-15, with.GetNbConstraints())
+
+	// the hints aren't shared
+	internalWithout, _, _ := without.GetNbVariables()
+	internalWith, _, _ := with.GetNbVariables()
+	assert.Equal(len(without.ReferencedHints()), len(with.ReferencedHints()))
+	assert.Less(internalWith, internalWithout)
+
+	var w cseOperationsCircuit
+	w.X.Assign(5)
+	w.Y.Assign(7)
+	test.NewAssert(t).ProverSucceeded(&cseOperationsCircuit{}, &w, test.WithCurves(ecc.BN254), test.WithCompileOpts(frontend.WithCSE()))
+}
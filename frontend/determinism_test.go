@@ -0,0 +1,125 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/circuits"
+)
+
+// determinismDir is the environment variable of the directory the subprocesses of
+// TestDeterministicCompilation write the compiled constraint systems to
+const determinismDir = "GNARK_TEST_DETERMINISM_DIR"
+
+// TestDeterministicCompilation compiles the circuits of the registry on all the curves and backends in two
+// processes, and in this one, and checks that the serialized constraint systems are byte-identical.
+// The seeds of the hash of the maps differ from one process to another, and from one iteration to another:
+// the order of the iterations on maps doesn't change the constraint systems.
+func TestDeterministicCompilation(t *testing.T) {
+	if dir := os.Getenv(determinismDir); dir != "" {
+		// in a subprocess
+		writeCompiledCircuits(t, dir)
+		return
+	}
+
+	dirs := make([]string, 3)
+	for i := range dirs {
+		dir, err := ioutil.TempDir("", "gnark-determinism")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		dirs[i] = dir
+	}
+	for _, dir := range dirs[1:] {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestDeterministicCompilation$") //#nosec G204 the test binary
+		cmd.Env = append(os.Environ(), determinismDir+"="+dir)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v\n%s", err, out)
+		}
+	}
+	writeCompiledCircuits(t, dirs[0])
+
+	files, err := ioutil.ReadDir(dirs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no circuit compiled")
+	}
+	for _, f := range files {
+		expected, err := ioutil.ReadFile(filepath.Join(dirs[0], f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, dir := range dirs[1:] {
+			got, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(expected, got) {
+				t.Errorf("%s: the compilation is not deterministic", f.Name())
+			}
+		}
+	}
+}
+
+// writeCompiledCircuits compiles the circuits of the registry on all the curves and backends, twice, and
+// writes the serialized constraint systems to dir
+func writeCompiledCircuits(t *testing.T, dir string) {
+	names := make([]string, 0, len(circuits.Circuits))
+	for name, c := range circuits.Circuits {
+		if c.ExpectedCompileError == "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, curve := range ecc.Implemented() {
+			for _, b := range backend.Implemented() {
+				var serialized [2]bytes.Buffer
+				for i := range serialized {
+					ccs, err := frontend.Compile(curve, b, circuits.Circuits[name].Circuit)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if _, err := ccs.WriteTo(&serialized[i]); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if !bytes.Equal(serialized[0].Bytes(), serialized[1].Bytes()) {
+					t.Errorf("%s %s %s: the compilation is not deterministic", name, curve, b)
+				}
+				file := filepath.Join(dir, name+"."+curve.String()+"."+b.String())
+				if err := ioutil.WriteFile(file, serialized[0].Bytes(), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+}
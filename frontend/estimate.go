@@ -0,0 +1,210 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/internal/backend/compiled"
+
+	bls12377r1cs "github.com/consensys/gnark/internal/backend/bls12-377/cs"
+	bls12381r1cs "github.com/consensys/gnark/internal/backend/bls12-381/cs"
+	bls24315r1cs "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	bn254r1cs "github.com/consensys/gnark/internal/backend/bn254/cs"
+	bw6633r1cs "github.com/consensys/gnark/internal/backend/bw6-633/cs"
+	bw6761r1cs "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+)
+
+// Stats is the size of the SparseR1CS (PLONK constraint system) a circuit compiles to,
+// see EstimateSparseR1CS
+type Stats struct {
+	NbPublicInputs      int // number of public inputs, not counting the constant wire ONE of the R1CS
+	NbConstraints       int // number of PLONK gates
+	NbInternalVariables int // number of internal wires, including the wires created by the splitting of the linear expressions
+
+	// ByOrigin counts the PLONK gates per origin of the R1C they come from: the API which
+	// added the R1C and its call site in the circuit, read from the debug info of the R1CS.
+	// The gates of the R1C without debug info (Mul, Xor, ...) are counted with the empty origin.
+	ByOrigin map[string]int
+
+	// ByTag counts the PLONK gates per tag label, as CompiledConstraintSystem.ConstraintsPerTag
+	// (the tags of the R1CS, if they weren't dropped by its serialization), or is nil
+	ByTag map[string]int
+}
+
+// EstimateSparseR1CS returns the size of the SparseR1CS the circuit of ccs, a R1CS compiled
+// for Groth16, compiles to with backend.PLONK. The R1C are split in PLONK gates as the PLONK
+// compiler does (linear expressions reduced to a single term with addition gates, then one gate
+// per R1C), without building the SparseR1CS itself.
+//
+// The range checks are the ones of the R1CS: with RangeCheckAuto, the PLONK circuit may use lookups
+// instead (see WithRangeCheckStrategy).
+//
+// The coefficients of the R1CS are reduced modulo the scalar field: they are read as the
+// integers of smallest absolute value, which is how circuits usually write them.
+func EstimateSparseR1CS(ccs CompiledConstraintSystem) (stats Stats, err error) {
+	var r1cs *compiled.R1CS
+	var coeffs []big.Int
+	switch c := ccs.(type) {
+	case *bls12377r1cs.R1CS:
+		r1cs, coeffs = &c.R1CS, make([]big.Int, len(c.Coefficients))
+		for i := 0; i < len(coeffs); i++ {
+			c.Coefficients[i].ToBigIntRegular(&coeffs[i])
+		}
+	case *bls12381r1cs.R1CS:
+		r1cs, coeffs = &c.R1CS, make([]big.Int, len(c.Coefficients))
+		for i := 0; i < len(coeffs); i++ {
+			c.Coefficients[i].ToBigIntRegular(&coeffs[i])
+		}
+	case *bn254r1cs.R1CS:
+		r1cs, coeffs = &c.R1CS, make([]big.Int, len(c.Coefficients))
+		for i := 0; i < len(coeffs); i++ {
+			c.Coefficients[i].ToBigIntRegular(&coeffs[i])
+		}
+	case *bw6761r1cs.R1CS:
+		r1cs, coeffs = &c.R1CS, make([]big.Int, len(c.Coefficients))
+		for i := 0; i < len(coeffs); i++ {
+			c.Coefficients[i].ToBigIntRegular(&coeffs[i])
+		}
+	case *bw6633r1cs.R1CS:
+		r1cs, coeffs = &c.R1CS, make([]big.Int, len(c.Coefficients))
+		for i := 0; i < len(coeffs); i++ {
+			c.Coefficients[i].ToBigIntRegular(&coeffs[i])
+		}
+	case *bls24315r1cs.R1CS:
+		r1cs, coeffs = &c.R1CS, make([]big.Int, len(c.Coefficients))
+		for i := 0; i < len(coeffs); i++ {
+			c.Coefficients[i].ToBigIntRegular(&coeffs[i])
+		}
+	default:
+		return Stats{}, errors.New("EstimateSparseR1CS expects a R1CS compiled for a curve with backend.GROTH16")
+	}
+
+	defer recoverCapacityError(&err)
+
+	cs := r1csToConstraintSystem(ccs.CurveID(), r1cs, coeffs)
+	res := newSparseR1CS(&cs)
+	res.convertConstraints()
+
+	stats = Stats{
+		NbPublicInputs:      r1cs.NbPublicVariables - 1,
+		NbConstraints:       len(res.ccs.Constraints),
+		NbInternalVariables: res.scsInternalVariables,
+		ByOrigin:            make(map[string]int),
+	}
+	origins := make([]string, len(r1cs.DebugInfo))
+	for i := 0; i < len(origins); i++ {
+		origins[i] = debugInfoOrigin(r1cs.DebugInfo[i])
+	}
+	for i := 0; i < len(res.ccs.Constraints); i++ {
+		origin := ""
+		if dID, ok := res.ccs.MDebug[i]; ok {
+			origin = origins[dID]
+		}
+		stats.ByOrigin[origin]++
+	}
+	stats.ByTag = res.ccs.ConstraintsPerTag()
+
+	return stats, nil
+}
+
+// r1csToConstraintSystem returns the constraint system r1cs was compiled from, as far as the
+// conversion to SparseR1C is concerned: the constraints, hint wires, debug info, tags and coefficients.
+// The wires IDs are shifted back to their ID per visibility.
+func r1csToConstraintSystem(curveID ecc.ID, r1cs *compiled.R1CS, coeffs []big.Int) constraintSystem {
+	cs := newConstraintSystem(curveID)
+	cs.public.variables.variables = make([]Variable, r1cs.NbPublicVariables)
+	cs.secret.variables.variables = make([]Variable, r1cs.NbSecretVariables)
+	cs.internal.variables = make([]Variable, r1cs.NbInternalVariables)
+
+	// coefficients are read in (-q/2, q/2], the first ones are set by newConstraintSystem
+	var halfQ big.Int
+	q := curveID.Info().Fr.Modulus()
+	halfQ.Rsh(q, 1)
+	for i := len(cs.coeffs); i < len(coeffs); i++ {
+		if coeffs[i].Cmp(&halfQ) > 0 {
+			coeffs[i].Sub(&coeffs[i], q)
+		}
+		cs.coeffs = append(cs.coeffs, coeffs[i])
+		if coeffs[i].IsInt64() {
+			if _, ok := cs.coeffsIDsInt64[coeffs[i].Int64()]; !ok {
+				cs.coeffsIDsInt64[coeffs[i].Int64()] = i
+			}
+			continue
+		}
+		bKey, _ := coeffs[i].GobEncode()
+		if _, ok := cs.coeffsIDsLarge[string(bKey)]; !ok {
+			cs.coeffsIDsLarge[string(bKey)] = i
+		}
+	}
+
+	unshiftIDs := func(l compiled.LinearExpression) compiled.LinearExpression {
+		l = l.Clone()
+		for j := 0; j < len(l); j++ {
+			_, vID, visibility := l[j].Unpack()
+			switch visibility {
+			case compiled.Internal:
+				l[j].SetVariableID(vID - r1cs.NbPublicVariables - r1cs.NbSecretVariables)
+			case compiled.Secret:
+				l[j].SetVariableID(vID - r1cs.NbPublicVariables)
+			}
+		}
+		return l
+	}
+	cs.constraints = make([]compiled.R1C, r1cs.Constraints.Len())
+	var r1c compiled.R1C
+	for i := range cs.constraints {
+		r1cs.Constraints.Load(i, &r1c)
+		cs.constraints[i] = compiled.R1C{L: unshiftIDs(r1c.L), R: unshiftIDs(r1c.R), O: unshiftIDs(r1c.O)}
+	}
+
+	// only the hint wires matter to the conversion, they are considered solved
+	for vID, hint := range r1cs.MHints {
+		if vID < r1cs.NbPublicVariables+r1cs.NbSecretVariables {
+			continue // public output, see API.SetOutput
+		}
+		cs.mHints[vID-r1cs.NbPublicVariables-r1cs.NbSecretVariables] = compiled.Hint{ID: hint.ID, Name: hint.Name}
+	}
+	for k, v := range r1cs.MDebug {
+		cs.mDebug[k] = v
+	}
+	cs.debugInfo = r1cs.DebugInfo
+	cs.tags = r1cs.Tags
+
+	return cs
+}
+
+// debugInfoOrigin returns the API name and the circuit call site of a debug info, that is
+// "[api] function file:line", with the first stack frame out of the frontend
+func debugInfoOrigin(l compiled.LogEntry) string {
+	lines := strings.Split(l.Format, "\n")
+	origin := lines[0]
+	if i := strings.IndexByte(origin, ']'); i != -1 {
+		origin = origin[:i+1]
+	}
+	// the stack is a list of function \n\t file:line
+	for i := 1; i+1 < len(lines); i += 2 {
+		if strings.HasPrefix(lines[i], "frontend.") {
+			continue
+		}
+		return origin + " " + lines[i] + " " + strings.TrimPrefix(lines[i+1], "\t")
+	}
+	return origin
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/circuits"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateSparseR1CS(t *testing.T) {
+	assert := require.New(t)
+
+	keys := make([]string, 0, len(circuits.Circuits))
+	for k := range circuits.Circuits {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		tData := circuits.Circuits[k]
+		if tData.ExpectedCompileError != "" {
+			continue
+		}
+		for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377} {
+			// by default, the range checks of PLONK use lookups
+			bits := frontend.WithRangeCheckStrategy(frontend.RangeCheckBits)
+			r1cs, err := frontend.Compile(curveID, backend.GROTH16, tData.Circuit, bits)
+			assert.NoError(err, k)
+			scs, err := frontend.Compile(curveID, backend.PLONK, tData.Circuit, bits)
+			assert.NoError(err, k)
+
+			stats, err := frontend.EstimateSparseR1CS(r1cs)
+			assert.NoError(err, k)
+			internal, _, public := scs.GetNbVariables()
+			assert.Equal(public, stats.NbPublicInputs, "%s: number of public inputs", k)
+			assert.Equal(scs.GetNbConstraints(), stats.NbConstraints, "%s: number of constraints", k)
+			assert.Equal(internal, stats.NbInternalVariables, "%s: number of internal variables", k)
+
+			sum := 0
+			for _, n := range stats.ByOrigin {
+				sum += n
+			}
+			assert.Equal(stats.NbConstraints, sum, k)
+		}
+	}
+}
+
+type estimateCircuit struct {
+	X, Y frontend.Variable
+}
+
+func (circuit *estimateCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Add(circuit.X, circuit.Y, 3), api.Mul(circuit.X, circuit.Y, circuit.Y))
+	api.ToBinary(circuit.X, 8)
+	return nil
+}
+
+func TestEstimateSparseR1CSByOrigin(t *testing.T) {
+	assert := require.New(t)
+
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &estimateCircuit{})
+	assert.NoError(err)
+	stats, err := frontend.EstimateSparseR1CS(r1cs)
+	assert.NoError(err)
+
+	origins := make(map[string]int)
+	for origin, n := range stats.ByOrigin {
+		switch {
+		case origin == "":
+			origins["mul"] += n
+		case strings.HasPrefix(origin, "[assertIsEqual] frontend_test.(*estimateCircuit).Define"):
+			origins["assertIsEqual"] += n
+		case strings.HasPrefix(origin, "[assertIsBoolean] frontend_test.(*estimateCircuit).Define"):
+			origins["assertIsBoolean"] += n
+		case strings.HasPrefix(origin, "[toBinary] frontend_test.(*estimateCircuit).Define"):
+			origins["toBinary"] += n
+		default:
+			t.Fatalf("unexpected origin %q", origin)
+		}
+	}
+	assert.Equal(2, origins["mul"], "X*Y*Y")
+	assert.Equal(1, origins["assertIsEqual"], "X + Y + 3 == X*Y*Y, in a single gate")
+	assert.Equal(8, origins["assertIsBoolean"], "one per bit")
+	assert.Equal(7, origins["toBinary"], "the sum of the 8 bits == X, asserted in 7 addition gates")
+
+	// the estimate needs the coefficients of a R1CS
+	scs, err := frontend.Compile(ecc.BN254, backend.PLONK, &estimateCircuit{})
+	assert.NoError(err)
+	_, err = frontend.EstimateSparseR1CS(scs)
+	assert.Error(err)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/internal/backend/compiled"
+)
+
+// ExportOptions configures CompiledConstraintSystem.ToJSON and ToHTML
+type ExportOptions = compiled.ExportOptions
+
+// WithConstraintRange is an export option restricting the export to the constraints [from, to)
+func WithConstraintRange(from, to int) func(opt *ExportOptions) error {
+	return func(opt *ExportOptions) error {
+		if from < 0 || to <= from {
+			return fmt.Errorf("invalid constraint range [%d, %d)", from, to)
+		}
+		opt.From, opt.To = from, to
+		return nil
+	}
+}
+
+// WithHTMLPageSize is a ToHTML option setting the number of constraints rendered per page,
+// compiled.DefaultHTMLPageSize by default
+func WithHTMLPageSize(pageSize int) func(opt *ExportOptions) error {
+	return func(opt *ExportOptions) error {
+		if pageSize <= 0 {
+			return fmt.Errorf("invalid page size %d", pageSize)
+		}
+		opt.PageSize = pageSize
+		return nil
+	}
+}
+
+// WithHTMLTable is a ToHTML option writing a static table of all the constraints instead of the
+// paginated page, for tiny circuits
+func WithHTMLTable() func(opt *ExportOptions) error {
+	return func(opt *ExportOptions) error {
+		opt.Table = true
+		return nil
+	}
+}
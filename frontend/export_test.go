@@ -0,0 +1,124 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/examples/cubic"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the JSON exports")
+
+func TestExportGolden(t *testing.T) {
+	for _, b := range []backend.ID{backend.GROTH16, backend.PLONK} {
+		t.Run(b.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(ecc.BN254, b, &cubic.Circuit{})
+			assert.NoError(err)
+
+			var buf bytes.Buffer
+			assert.NoError(ccs.ToJSON(&buf))
+
+			golden := filepath.Join("testdata", "cubic_"+b.String()+".json")
+			if *update {
+				assert.NoError(ioutil.WriteFile(golden, buf.Bytes(), 0644))
+			}
+			expected, err := ioutil.ReadFile(golden)
+			assert.NoError(err)
+			assert.Equal(string(expected), buf.String(), "the export changed, run the tests with -update if it is intended")
+
+			// the page embeds the same export
+			var page bytes.Buffer
+			assert.NoError(ccs.ToHTML(&page, frontend.WithHTMLPageSize(1)))
+			assert.Contains(page.String(), string(bytes.TrimSpace(expected)))
+			assert.Contains(page.String(), "const pageSize = 1;")
+
+			// the static table is still available
+			page.Reset()
+			assert.NoError(ccs.ToHTML(&page, frontend.WithHTMLTable()))
+			assert.NotContains(page.String(), `id="data"`)
+		})
+	}
+}
+
+func TestExportRange(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &cubic.Circuit{})
+	assert.NoError(err)
+	nbConstraints := ccs.GetNbConstraints()
+	assert.True(nbConstraints > 1)
+
+	var all, slice struct {
+		From        int       `json:"from"`
+		Constraints [][][]int `json:"constraints"`
+	}
+	var buf bytes.Buffer
+	assert.NoError(ccs.ToJSON(&buf))
+	assert.NoError(json.Unmarshal(buf.Bytes(), &all))
+	assert.Len(all.Constraints, nbConstraints)
+
+	buf.Reset()
+	assert.NoError(ccs.ToJSON(&buf, frontend.WithConstraintRange(1, nbConstraints)))
+	assert.NoError(json.Unmarshal(buf.Bytes(), &slice))
+	assert.Equal(1, slice.From)
+	assert.Equal(all.Constraints[1:], slice.Constraints)
+
+	assert.Error(ccs.ToJSON(&buf, frontend.WithConstraintRange(2, 1)))
+	assert.Error(ccs.ToJSON(&buf, frontend.WithConstraintRange(nbConstraints+1, nbConstraints+2)))
+	assert.Error(ccs.ToHTML(&buf, frontend.WithHTMLPageSize(0)))
+}
+
+type exportSizeCircuit struct {
+	X frontend.Variable `gnark:",secret"`
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *exportSizeCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	x := circuit.X
+	for i := 0; i < 100000; i++ {
+		x = api.Mul(x, circuit.X)
+	}
+	api.AssertIsEqual(x, circuit.Y)
+	return nil
+}
+
+func TestExportSize(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the 100k constraints export in short mode")
+	}
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &exportSizeCircuit{})
+	assert.NoError(err)
+	assert.True(ccs.GetNbConstraints() >= 100000)
+
+	var buf bytes.Buffer
+	assert.NoError(ccs.ToHTML(&buf))
+	assert.True(buf.Len() < 8<<20, "the page of %d constraints takes %d bytes", ccs.GetNbConstraints(), buf.Len())
+}
@@ -0,0 +1,191 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/consensys/gnark/internal/parser"
+)
+
+// FillZero assigns 0 to the inputs of the sub-structures of assignment at fieldPaths, overwriting their
+// values, for example to fill the witness of a branch of the circuit which a selector doesn't take.
+// The nested structures and the arrays of the sub-structures are filled too.
+//
+// A path is the dotted list of the Go field names (or slice indexes) leading to the sub-structure, as
+// Field.Path of the schema (see ParseSchema), for example "BranchB" or "Points.0". Without paths, all
+// the inputs of assignment are assigned 0.
+//
+// assignment is a pointer to a witness structure, with its slices allocated: a nil slice has no inputs.
+// FillZero returns an error, listing the paths available at its level, if a path leads to no input.
+func FillZero(assignment interface{}, fieldPaths ...string) error {
+	inputs, err := walkAssignment(assignment)
+	if err != nil {
+		return err
+	}
+	if len(fieldPaths) == 0 {
+		fieldPaths = []string{""}
+	}
+	for _, path := range fieldPaths {
+		variables, _, err := inputs.under(path)
+		if err != nil {
+			return err
+		}
+		for _, v := range variables {
+			v.WitnessValue = 0
+		}
+	}
+	return nil
+}
+
+// FillFrom copies the values of the inputs of the sub-structure of src at fieldPath (see FillZero) to
+// the same sub-structure of dst, for example to reuse the witness of a branch of the circuit computed
+// for another proof. dst and src are pointers to witness structures of the same type, with slices of the
+// same lengths under fieldPath; an empty fieldPath copies all the inputs.
+func FillFrom(dst, src interface{}, fieldPath string) error {
+	if reflect.TypeOf(dst) != reflect.TypeOf(src) {
+		return fmt.Errorf("can't fill a %T from a %T", dst, src)
+	}
+	dstInputs, err := walkAssignment(dst)
+	if err != nil {
+		return err
+	}
+	srcInputs, err := walkAssignment(src)
+	if err != nil {
+		return err
+	}
+	dstVariables, dstPaths, err := dstInputs.under(fieldPath)
+	if err != nil {
+		return err
+	}
+	srcVariables, srcPaths, err := srcInputs.under(fieldPath)
+	if err != nil {
+		return err
+	}
+	if len(dstPaths) != len(srcPaths) {
+		return fmt.Errorf("%s: %d inputs in the destination, %d in the source", fieldPath, len(dstPaths), len(srcPaths))
+	}
+	for i := range dstPaths {
+		if dstPaths[i] != srcPaths[i] {
+			return fmt.Errorf("%s: input %s in the destination, %s in the source", fieldPath, dstPaths[i], srcPaths[i])
+		}
+	}
+	for i, v := range dstVariables {
+		v.WitnessValue = srcVariables[i].WitnessValue
+	}
+	return nil
+}
+
+// assignmentInputs are the inputs of a witness structure, in the order of the schema
+type assignmentInputs struct {
+	paths     []string
+	variables []*Variable
+}
+
+// walkAssignment returns the inputs of assignment, a pointer to a witness structure
+func walkAssignment(assignment interface{}) (*assignmentInputs, error) {
+	if o, ok := assignment.(parser.VisibilityOverrider); ok {
+		assignment, _ = o.OverriddenVisibility()
+	}
+	root := reflect.ValueOf(assignment)
+	if root.Kind() != reflect.Ptr || root.IsNil() || root.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("the assignment must be a pointer to a struct, got %T", assignment)
+	}
+	leafs, _, err := parser.Walk(assignment, tVariable, false)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &assignmentInputs{paths: make([]string, len(leafs)), variables: make([]*Variable, len(leafs))}
+	for i, l := range leafs {
+		v, err := fieldByIndex(root, l.Index)
+		if err != nil || v.Type() != tVariable || !v.CanAddr() {
+			return nil, fmt.Errorf("%T: invalid input %s", assignment, l.Path)
+		}
+		res.paths[i] = l.Path
+		res.variables[i] = v.Addr().Interface().(*Variable)
+	}
+	return res, nil
+}
+
+// under returns the inputs at path, or in the sub-structure at path, with their paths
+func (a *assignmentInputs) under(path string) ([]*Variable, []string, error) {
+	var variables []*Variable
+	var paths []string
+	for i, p := range a.paths {
+		if isUnder(p, path) {
+			variables = append(variables, a.variables[i])
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, nil, fmt.Errorf("%q: no input at this path; available paths: %s", path, strings.Join(a.available(path), ", "))
+	}
+	return variables, paths, nil
+}
+
+// available returns the paths of the inputs and sub-structures in the deepest sub-structure leading to
+// path, in the order of the schema
+func (a *assignmentInputs) available(path string) []string {
+	parent := ""
+	segments := strings.Split(path, ".")
+	for i := len(segments) - 1; i > 0; i-- {
+		prefix := strings.Join(segments[:i], ".")
+		if a.isStructure(prefix) {
+			parent = prefix
+			break
+		}
+	}
+
+	var res []string
+	seen := make(map[string]bool)
+	for _, p := range a.paths {
+		if !isUnder(p, parent) || p == parent {
+			continue
+		}
+		child := p
+		if parent != "" {
+			child = p[len(parent)+1:]
+		}
+		child = strings.SplitN(child, ".", 2)[0]
+		if parent != "" {
+			child = parent + "." + child
+		}
+		if !seen[child] {
+			seen[child] = true
+			res = append(res, child)
+		}
+	}
+	return res
+}
+
+// isStructure returns true if path leads to a sub-structure (or an array) with inputs
+func (a *assignmentInputs) isStructure(path string) bool {
+	for _, p := range a.paths {
+		if strings.HasPrefix(p, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnder returns true if the input at p is at path, or in the sub-structure at path ("" is the root)
+func isUnder(p, path string) bool {
+	return path == "" || p == path || strings.HasPrefix(p, path+".")
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// pathWitness is the witness of a path of branchCircuit: Y == X**2 and Z[0] + Z[1] == X
+type pathWitness struct {
+	X, Y frontend.Variable
+	Z    [2]frontend.Variable
+}
+
+func (w *pathWitness) check(api frontend.API) {
+	api.AssertIsEqual(w.Y, api.Mul(w.X, w.X))
+	api.AssertIsEqual(api.Add(w.Z[0], w.Z[1]), w.X)
+}
+
+// branchCircuit proves Out == A.Y or Out == B.Y, depending on Selector
+type branchCircuit struct {
+	Selector frontend.Variable `gnark:",public"`
+	Out      frontend.Variable `gnark:",public"`
+	A, B     pathWitness       `gnark:",secret"`
+}
+
+func (circuit *branchCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsBoolean(circuit.Selector)
+	circuit.A.check(api)
+	circuit.B.check(api)
+	api.AssertIsEqual(circuit.Out, api.Select(circuit.Selector, circuit.A.Y, circuit.B.Y))
+	return nil
+}
+
+func TestFillZero(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	newWitness := func() *branchCircuit {
+		var w branchCircuit
+		w.Selector.Assign(1)
+		w.Out.Assign(49)
+		w.A.X.Assign(7)
+		w.A.Y.Assign(49)
+		w.A.Z[0].Assign(3)
+		w.A.Z[1].Assign(4)
+		return &w
+	}
+
+	// the branch B, not taken, is filled with zeros
+	w := newWitness()
+	assert.NoError(frontend.FillZero(w, "B"))
+	for _, v := range []frontend.Variable{w.B.X, w.B.Y, w.B.Z[0], w.B.Z[1]} {
+		assert.Equal(0, v.WitnessValue)
+	}
+	assert.Equal(7, w.A.X.WitnessValue)
+	assert.ProverSucceeded(&branchCircuit{}, w, test.WithCurves(ecc.BN254))
+
+	// an input, or an element of an array
+	w = newWitness()
+	assert.NoError(frontend.FillZero(w, "B.X", "B.Y", "B.Z.0", "B.Z.1"))
+	assert.ProverSucceeded(&branchCircuit{}, w, test.WithCurves(ecc.BN254))
+
+	// a misspelled path is reported with the paths at its level
+	err := frontend.FillZero(newWitness(), "B.Zz")
+	assert.Error(err)
+	assert.Contains(err.Error(), `"B.Zz": no input at this path; available paths: B.X, B.Y, B.Z`)
+	err = frontend.FillZero(newWitness(), "C")
+	assert.Error(err)
+	assert.Contains(err.Error(), "available paths: Selector, Out, A, B")
+
+	// the whole witness, which must be a pointer
+	w = newWitness()
+	assert.NoError(frontend.FillZero(w))
+	assert.Equal(0, w.A.X.WitnessValue)
+	assert.Error(frontend.FillZero(branchCircuit{}))
+}
+
+func TestFillFrom(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// the branch A of another witness
+	var src branchCircuit
+	src.A.X.Assign(5)
+	src.A.Y.Assign(25)
+	src.A.Z[0].Assign(2)
+	src.A.Z[1].Assign(3)
+
+	var w branchCircuit
+	w.Selector.Assign(1)
+	w.Out.Assign(25)
+	assert.NoError(frontend.FillFrom(&w, &src, "A"))
+	assert.NoError(frontend.FillZero(&w, "B"))
+	assert.Equal(5, w.A.X.WitnessValue)
+	assert.Nil(src.B.X.WitnessValue)
+	assert.ProverSucceeded(&branchCircuit{}, &w, test.WithCurves(ecc.BN254))
+
+	assert.Error(frontend.FillFrom(&w, &src, "C"))
+	assert.Error(frontend.FillFrom(&w, &pathWitness{}, "X"))
+}
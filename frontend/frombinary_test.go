@@ -0,0 +1,95 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// fromBinaryCircuit packs Bits with FromBinary, FromBinaryMod or FromBinaryChecked and compares the result to Y
+type fromBinaryCircuit struct {
+	Bits []frontend.Variable
+	Y    frontend.Variable `gnark:",public"`
+	mode string
+}
+
+func (circuit *fromBinaryCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	var res frontend.Variable
+	switch circuit.mode {
+	case "mod":
+		res = api.FromBinaryMod(circuit.Bits...)
+	case "checked":
+		res = api.FromBinaryChecked(circuit.Bits...)
+	default:
+		res = api.FromBinary(circuit.Bits...)
+	}
+	api.AssertIsEqual(res, circuit.Y)
+	return nil
+}
+
+// fromBinaryWitness returns an assignment of nbBits bits to v, with Y = y
+func fromBinaryWitness(nbBits int, v, y *big.Int) *fromBinaryCircuit {
+	w := &fromBinaryCircuit{Bits: make([]frontend.Variable, nbBits)}
+	for i := range w.Bits {
+		w.Bits[i].Assign(int(v.Bit(i)))
+	}
+	w.Y.Assign(y)
+	return w
+}
+
+func TestFromBinaryBoundary(t *testing.T) {
+	// the assert helper caches the compiled circuits by type, hence a new helper per circuit
+	assert := func() *test.Assert { return test.NewAssert(t) }
+
+	// the moduli of BN254 and BLS12-377 have different bit lengths (254, 253) and shapes
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_377} {
+		nbBits := curve.Info().Fr.Bits
+		modulus := curve.Info().Fr.Modulus()
+		rMinusOne := new(big.Int).Sub(modulus, big.NewInt(1))
+		rPlusOne := new(big.Int).Add(modulus, big.NewInt(1))
+		zero, one := big.NewInt(0), big.NewInt(1)
+		opt := test.WithCurves(curve)
+
+		circuit := func(mode string, nbBits int) *fromBinaryCircuit {
+			return &fromBinaryCircuit{Bits: make([]frontend.Variable, nbBits), mode: mode}
+		}
+
+		// at the field bit length: r-1 is canonical, r and r+1 are not
+		for _, mode := range []string{"", "mod", "checked"} {
+			assert().SolvingSucceeded(circuit(mode, nbBits), fromBinaryWitness(nbBits, rMinusOne, rMinusOne), opt)
+		}
+		assert().SolvingSucceeded(circuit("", nbBits), fromBinaryWitness(nbBits, modulus, zero), opt)
+		assert().SolvingSucceeded(circuit("mod", nbBits), fromBinaryWitness(nbBits, rPlusOne, one), opt)
+		assert().SolvingFailed(circuit("checked", nbBits), fromBinaryWitness(nbBits, modulus, zero), opt)
+		assert().SolvingFailed(circuit("checked", nbBits), fromBinaryWitness(nbBits, rPlusOne, one), opt)
+
+		// above the field bit length, FromBinary doesn't compile
+		for _, b := range backend.Implemented() {
+			_, err := frontend.Compile(curve, b, circuit("", nbBits+1))
+			assert().Error(err)
+		}
+		assert().SolvingSucceeded(circuit("mod", nbBits+1), fromBinaryWitness(nbBits+1, new(big.Int).Lsh(one, uint(nbBits)), new(big.Int).Lsh(one, uint(nbBits))), opt)
+		assert().SolvingSucceeded(circuit("checked", nbBits+1), fromBinaryWitness(nbBits+1, rMinusOne, rMinusOne), opt)
+		assert().SolvingFailed(circuit("checked", nbBits+1), fromBinaryWitness(nbBits+1, new(big.Int).Lsh(one, uint(nbBits)), zero), opt)
+	}
+}
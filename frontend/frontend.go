@@ -20,51 +20,158 @@ package frontend
 import (
 	"errors"
 	"fmt"
-	"reflect"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/internal/backend/compiled"
-	"github.com/consensys/gnark/internal/parser"
+	"github.com/consensys/gnark/logger"
 )
 
 // errInputNotSet triggered when trying to access a variable that was not allocated
 var errInputNotSet = errors.New("variable is not allocated")
 
+var (
+	// ErrTooManyWires is returned by Compile when the circuit has more wires than
+	// a compiled constraint system can address (see MaxNbWires)
+	ErrTooManyWires = compiled.ErrTooManyWires
+
+	// ErrTooManyCoefficients is returned by Compile when the circuit has more distinct
+	// coefficients than a compiled constraint system can address (see MaxNbCoefficients)
+	ErrTooManyCoefficients = compiled.ErrTooManyCoefficients
+
+	// ErrTooManyTerms is returned by Compile when the constraints of a R1CS have more terms
+	// than a compiled constraint system can store (see compiled.MaxNbTerms)
+	ErrTooManyTerms = compiled.ErrTooManyTerms
+
+	// ErrMetadataTooLarge is returned by Compile when the metadata given with WithMetadata
+	// exceeds MaxMetadataSize bytes
+	ErrMetadataTooLarge = compiled.ErrMetadataTooLarge
+)
+
+// MaxNbWires and MaxNbCoefficients are the capacity limits of a compiled constraint system,
+// MaxMetadataSize the limit of the total size of the keys and values of its metadata
+const (
+	MaxNbWires        = compiled.MaxNbVariables
+	MaxNbCoefficients = compiled.MaxNbCoefficients
+	MaxMetadataSize   = compiled.MaxMetadataSize
+)
+
 // Compile will generate a CompiledConstraintSystem from the given circuit
 //
 // 1. it will first allocate the user inputs (see type Tag for more info)
 // example:
-// 		type MyCircuit struct {
-// 			Y frontend.Variable `gnark:"exponent,public"`
-// 		}
+//
+//	type MyCircuit struct {
+//		Y frontend.Variable `gnark:"exponent,public"`
+//	}
+//
 // in that case, Compile() will allocate one public variable with id "exponent"
 //
 // 2. it then calls circuit.Define(curveID, constraintSystem) to build the internal constraint system
 // from the declarative code
 //
-// 3. finally, it converts that to a CompiledConstraintSystem.
-// 		if zkpID == backend.GROTH16	--> R1CS
-//		if zkpID == backend.PLONK 	--> SparseR1CS
+//  3. finally, it converts that to a CompiledConstraintSystem.
+//     if zkpID == backend.GROTH16	--> R1CS
+//     if zkpID == backend.PLONK 	--> SparseR1CS
+//     if zkpID == backend.SIMULATED	--> R1CS
+//
+// The compilation is deterministic: the same circuit, compiled with the same options by the same version
+// of gnark, gives constraint systems whose serializations (WriteTo) are byte-identical, in one process or
+// in several. The digests of the serialization (see backend.WithCheckpoint and simulated.Setup) identify
+// the circuit on this guarantee. The debug information holds the files and lines of the stack of the
+// constraints: the same circuit built from sources at another path (without -trimpath) has another digest.
 //
 // initialCapacity is an optional parameter that reserves memory in slices
 // it should be set to the estimated number of constraints in the circuit, if known.
 func Compile(curveID ecc.ID, zkpID backend.ID, circuit Circuit, opts ...func(opt *CompileOption) error) (ccs CompiledConstraintSystem, err error) {
+	opt, circuit, err := newCompileOption(circuit, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// build the constraint system (see Circuit.Define)
+	cs, err := buildCS(curveID, zkpID, circuit, &opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return compile(&cs, zkpID, &opt)
+}
+
+// CompileBoth is like Compile, for both backends: the circuit is built once, and the R1CS (Groth16)
+// and the SparseR1CS (PlonK) are derived from the same constraint system.
+//
+// The wires of the SparseR1CS hold the values of wires of the R1CS, except the ones created to split
+// the constraints in PlonK gates: mapping[i] is the R1CS wire of the SparseR1CS wire i, or -1. See
+// backend.WithSharedSolution to solve a witness once for the proofs of both backends.
+func CompileBoth(curveID ecc.ID, circuit Circuit, opts ...func(opt *CompileOption) error) (r1cs, sparseR1CS CompiledConstraintSystem, mapping []int, err error) {
+	opt, circuit, err := newCompileOption(circuit, opts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// the backend is unknown, RangeCheckLookup falls back to RangeCheckAuto
+	cs, err := buildCS(curveID, backend.UNKNOWN, circuit, &opt)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cs.metadata = compiled.CopyMetadata(opt.metadata)
+	if !opt.ignoreUnconstrainedInputs {
+		if err := cs.checkVariables(); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// the SparseR1CS is derived last, its conversion adds coefficients to cs
+	if r1cs, err = cs.toR1CS(cs.curveID); err != nil {
+		return nil, nil, nil, err
+	}
+	if sparseR1CS, err = cs.toSparseR1CS(cs.curveID); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// wires = public wires | secret wires | internal wires, the R1CS starts with the ONE_WIRE, and the
+	// internal wires of the SparseR1CS start with the ones of the constraint system
+	internal, secret, public := sparseR1CS.GetNbVariables()
+	mapping = make([]int, public+secret+internal)
+	for i := range mapping {
+		if i < public+secret+len(cs.internal.variables) {
+			mapping[i] = i + 1
+		} else {
+			mapping[i] = -1
+		}
+	}
+
+	return r1cs, sparseR1CS, mapping, nil
+}
 
-	// setup option
+// newCompileOption applies the options, and returns them with the circuit to compile
+func newCompileOption(circuit Circuit, opts []func(opt *CompileOption) error) (CompileOption, Circuit, error) {
 	opt := CompileOption{}
 	for _, o := range opts {
 		if err := o(&opt); err != nil {
-			return nil, err
+			return opt, nil, err
 		}
 	}
 
-	// build the constraint system (see Circuit.Define)
-	cs, err := buildCS(curveID, circuit, opt.capacity)
-	if err != nil {
-		return nil, err
+	if opt.visibilityOverrides != nil {
+		if opt.schema != nil {
+			return opt, nil, errors.New("WithSchema and WithVisibilityOverride can't be used together, the schema holds the visibilities")
+		}
+		circuit = OverrideVisibility(circuit, opt.visibilityOverrides)
 	}
 
+	if compiled.MetadataSize(opt.metadata) > compiled.MaxMetadataSize {
+		return opt, nil, ErrMetadataTooLarge
+	}
+	return opt, circuit, nil
+}
+
+// compile converts the constraint system built from the circuit to a CompiledConstraintSystem
+func compile(cs *constraintSystem, zkpID backend.ID, opt *CompileOption) (CompiledConstraintSystem, error) {
+	cs.metadata = compiled.CopyMetadata(opt.metadata)
+
 	// ensure all inputs and hints are constrained
 	if !opt.ignoreUnconstrainedInputs {
 		if err := cs.checkVariables(); err != nil {
@@ -73,63 +180,80 @@ func Compile(curveID ecc.ID, zkpID backend.ID, circuit Circuit, opts ...func(opt
 	}
 
 	switch zkpID {
-	case backend.GROTH16:
-		ccs, err = cs.toR1CS(curveID)
+	case backend.GROTH16, backend.SIMULATED:
+		return cs.toR1CS(cs.curveID)
 	case backend.PLONK:
-		ccs, err = cs.toSparseR1CS(curveID)
+		return cs.toSparseR1CS(cs.curveID)
 	default:
 		panic("not implemented")
 	}
-	if err != nil {
-		return nil, err
-	}
-
-	return
 }
 
-// buildCS builds the constraint system. It bootstraps the inputs
-// allocations by parsing the circuit's underlying structure, then
-// it builds the constraint system using the Define method.
-func buildCS(curveID ecc.ID, circuit Circuit, initialCapacity ...int) (cs constraintSystem, err error) {
+// buildCS builds the constraint system for the backend zkpID. It bootstraps the inputs
+// allocations from the circuit's schema (parsed from its underlying structure
+// if nil), then it builds the constraint system using the Define method.
+func buildCS(curveID ecc.ID, zkpID backend.ID, circuit Circuit, opt *CompileOption) (cs constraintSystem, err error) {
 	// recover from panics to print user-friendlier messages
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v", r)
+			if e, ok := r.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
 			// TODO @gbotrel with debug buiild tag
 			// fmt.Println(string(debug.Stack()))
 		}
 	}()
 	// instantiate our constraint system
-	cs = newConstraintSystem(curveID, initialCapacity...)
-
-	// leaf handlers are called when encoutering leafs in the circuit data struct
-	// leafs are Constraints that need to be initialized in the context of compiling a circuit
-	var handler parser.LeafHandler = func(visibility compiled.Visibility, name string, tInput reflect.Value) error {
-		if tInput.CanSet() {
-			v := tInput.Interface().(Variable)
-			if v.id != 0 {
-				v.id = 0
-				// return errors.New("circuit was already compiled")
+	cs = newConstraintSystem(curveID, opt.capacity)
+	cs.zkpID = zkpID
+	cs.rangeCheckStrategy = opt.rangeCheckStrategy
+	cs.hintInputThreshold = opt.hintInputs()
+	if opt.cse {
+		cs.cse = &subexpressions{results: make(map[string]subexpression)}
+	}
+
+	schema := opt.schema
+	if schema == nil {
+		var warnings []string
+		if schema, warnings, err = parseSchema(circuit, opt.strictSchema); err != nil {
+			return cs, err
+		}
+		for _, w := range warnings {
+			logger.Warn("%s, see WithStrictSchema", w)
+		}
+	} else if opt.strictSchema && !schema.Strict {
+		return cs, errors.New("WithStrictSchema: the schema of WithSchema must be parsed by ParseSchemaStrict")
+	}
+
+	// the handler is called on the inputs of the circuit, which need to be initialized
+	// in the context of compiling a circuit
+	handler := func(f *Field, v *Variable) error {
+		if v.WitnessValue != nil {
+			return fmt.Errorf("circuit has %s illegaly assigned, can't compile", f.Name)
+		}
+		switch f.Visibility {
+		case compiled.Secret:
+			if f.Optional {
+				cs.optionalSecrets = append(cs.optionalSecrets, len(cs.secret.variables.variables))
 			}
-			if v.WitnessValue != nil {
-				return fmt.Errorf("circuit has %s illegaly assigned, can't compile", name)
+			*v = cs.newSecretVariable(f.Name)
+		case compiled.Public:
+			if f.Optional {
+				return fmt.Errorf("%s: public inputs can't be optional", f.Path)
 			}
-			switch visibility {
-			case compiled.Secret:
-				tInput.Set(reflect.ValueOf(cs.newSecretVariable(name)))
-			case compiled.Public:
-				tInput.Set(reflect.ValueOf(cs.newPublicVariable(name)))
-			case compiled.Unset:
-				return errors.New("can't set val " + name + " visibility is unset")
+			if f.Output {
+				cs.publicOutputs = append(cs.publicOutputs, publicOutput{name: f.Name, id: len(cs.public.variables.variables)})
 			}
-
-			return nil
+			*v = cs.newPublicVariable(f.Name)
+		default:
+			return errors.New("can't set val " + f.Name + " visibility is unset")
 		}
-		return errors.New("can't set val " + name)
+		return nil
 	}
-	// recursively parse through reflection the circuits members to find all Constraints that need to be allOoutputcated
-	// (secret or public inputs)
-	if err := parser.Visit(circuit, "", compiled.Unset, handler, reflect.TypeOf(Variable{})); err != nil {
+	// allocate the secret and public inputs, in the order of the schema
+	if err := schema.Visit(circuit, handler); err != nil {
 		return cs, err
 	}
 
@@ -137,6 +261,22 @@ func buildCS(curveID ecc.ID, circuit Circuit, initialCapacity ...int) (cs constr
 	if err := circuit.Define(curveID, &cs); err != nil {
 		return cs, err
 	}
+	if err := cs.checkLoops(); err != nil {
+		return cs, err
+	}
+	if err := cs.checkPublicOutputs(); err != nil {
+		return cs, err
+	}
+	if err := cs.checkTags(); err != nil {
+		return cs, err
+	}
+	if cs.tags != nil {
+		cs.tags.Serialize = opt.serializeTags
+	}
+
+	if err := cs.markOutputFields(circuit); err != nil {
+		return cs, err
+	}
 
 	return
 
@@ -145,8 +285,11 @@ func buildCS(curveID ecc.ID, circuit Circuit, initialCapacity ...int) (cs constr
 // Value returned a Variable with an assigned value
 // This is to be used in the context of witness creation only and
 // will triger an error if used inside a circuit Define(...) method
-// This is syntatic sugar for: frontend.Variable{WitnessValue: value}
+// This is syntatic sugar for: frontend.Variable{WitnessValue: value}; a bool value is 0 or 1
 func Value(value interface{}) Variable {
+	if b, ok := value.(bool); ok {
+		value = boolToUint64(b)
+	}
 	return Variable{WitnessValue: value}
 }
 
@@ -154,6 +297,14 @@ func Value(value interface{}) Variable {
 type CompileOption struct {
 	capacity                  int
 	ignoreUnconstrainedInputs bool
+	visibilityOverrides       map[string]Visibility
+	schema                    *Schema
+	metadata                  map[string]string
+	rangeCheckStrategy        RangeCheckStrategy
+	strictSchema              bool
+	serializeTags             bool
+	cse                       bool
+	hintInputThreshold        int // 0 for DefaultHintInputThreshold, -1 if disabled
 }
 
 // WithOutput is a Compile option that specifies the estimated capacity needed for internal variables and constraints
@@ -169,3 +320,119 @@ func IgnoreUnconstrainedInputs(opt *CompileOption) error {
 	opt.ignoreUnconstrainedInputs = true
 	return nil
 }
+
+// WithVisibilityOverride is a Compile option that overrides the visibility of some of the circuit inputs,
+// such that a single circuit type can be compiled with different sets of public inputs.
+// See OverrideVisibility for the format of overrides; the witness must be built with the same overrides.
+func WithVisibilityOverride(overrides map[string]Visibility) func(opt *CompileOption) error {
+	return func(opt *CompileOption) error {
+		opt.visibilityOverrides = overrides
+		return nil
+	}
+}
+
+// WithSchema is a Compile option that gives the schema of the circuit (see ParseSchema), such that
+// Compile doesn't parse the circuit structure. It is useful to compile the same circuit type several times.
+func WithSchema(schema *Schema) func(opt *CompileOption) error {
+	return func(opt *CompileOption) error {
+		opt.schema = schema
+		return nil
+	}
+}
+
+// WithMetadata is a Compile option that stores a user-defined description of the circuit (version,
+// parameters, ...) in the compiled constraint system. The metadata is serialized with it, and copied in the
+// keys at setup (see GetMetadata); keys and values can't exceed MaxMetadataSize bytes in total.
+func WithMetadata(metadata map[string]string) func(opt *CompileOption) error {
+	return func(opt *CompileOption) error {
+		opt.metadata = metadata
+		return nil
+	}
+}
+
+// WithRangeCheckStrategy is a Compile option that sets how AssertIsLessOrEqual against a constant bound
+// and AssertIsLessOrEqualBounded range check their values (RangeCheckAuto by default). The choices
+// are counted per strategy in the compiled constraint system, see CompiledConstraintSystem.GetRangeChecks.
+//
+// AssertIsLessOrEqual checks v <= bound with two range checks on the bit length n of the bound,
+// v < 2**n and bound - v < 2**n, when 2**(n+1) <= q; larger bounds are compared bit per bit with
+// the canonical binary decomposition of v, whatever the strategy.
+func WithRangeCheckStrategy(strategy RangeCheckStrategy) func(opt *CompileOption) error {
+	return func(opt *CompileOption) error {
+		if err := checkRangeCheckStrategy(strategy); err != nil {
+			return err
+		}
+		opt.rangeCheckStrategy = strategy
+		return nil
+	}
+}
+
+// WithStrictSchema is a Compile option that rejects the circuit structures which layout is ambiguous: exported
+// fields which aren't inputs must be tagged `gnark:"-"`, the inputs must have a visibility tag, and distinct
+// names. See ParseSchemaStrict for the rules; without this option, Compile logs the inputs without visibility
+// tag and the duplicate names as warnings. With WithSchema, the schema must be parsed by ParseSchemaStrict.
+func WithStrictSchema() func(opt *CompileOption) error {
+	return func(opt *CompileOption) error {
+		opt.strictSchema = true
+		return nil
+	}
+}
+
+// WithSerializedTags is a Compile option that serializes the tags of the circuit (see API.Tag) with the
+// compiled constraint system. By default they are only kept in memory: ConstraintsPerTag of a constraint
+// system read from its serialization returns nil.
+func WithSerializedTags() func(opt *CompileOption) error {
+	return func(opt *CompileOption) error {
+		opt.serializeTags = true
+		return nil
+	}
+}
+
+// WithCSE is a Compile option that eliminates the common subexpressions of the circuit: the exact repeats
+// of Mul, Div, Inverse, IsZero and ToBinary (with the same operands, and number of bits) return the result
+// of the first call instead of adding new wires and constraints. The outputs of NewHint are never shared,
+// each call creates new wires. The number of eliminated constraints is reported by
+// CompiledConstraintSystem.GetNbEliminatedConstraints.
+func WithCSE() func(opt *CompileOption) error {
+	return func(opt *CompileOption) error {
+		opt.cse = true
+		return nil
+	}
+}
+
+// DefaultHintInputThreshold is the number of terms above which a hint input is reduced to a wire, unless
+// set with WithHintInputThreshold
+const DefaultHintInputThreshold = 1024
+
+// WithHintInputThreshold is a Compile option that sets the number of terms above which the linear
+// expression of a hint input is reduced to a new wire, constrained to be equal to it, instead of being
+// stored in the hint: the solver then computes the sum of its terms once, in the constraint, and the hint
+// reads a single wire. The hints with the same input expression share its wire. It costs a constraint
+// per reduced expression (with PLONK, the gates of the linear expression), and bounds the cost of the
+// hint calls of the solver, which otherwise sums the terms of the inputs at each call.
+//
+// The threshold defaults to DefaultHintInputThreshold; 0 disables the reduction. The number of reduced
+// expressions is reported by CompiledConstraintSystem.GetNbReducedHintInputs.
+func WithHintInputThreshold(n int) func(opt *CompileOption) error {
+	return func(opt *CompileOption) error {
+		if n < 0 {
+			return errors.New("hint input threshold must be positive or 0")
+		}
+		opt.hintInputThreshold = n
+		if n == 0 {
+			opt.hintInputThreshold = -1
+		}
+		return nil
+	}
+}
+
+// hintInputs returns the hint input threshold set with WithHintInputThreshold, 0 if disabled
+func (opt *CompileOption) hintInputs() int {
+	switch opt.hintInputThreshold {
+	case 0:
+		return DefaultHintInputThreshold
+	case -1:
+		return 0
+	}
+	return opt.hintInputThreshold
+}
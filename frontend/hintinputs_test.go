@@ -0,0 +1,129 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	nbHintInputTerms = 10000
+	nbHintCalls      = 100
+)
+
+// echoHint returns its input
+func echoHint(curveID ecc.ID, inputs []*big.Int, result *big.Int) error {
+	result.Set(inputs[0])
+	return nil
+}
+
+// hintInputCircuit asserts that the weighted sum of nbHintInputTerms inputs is Y, and calls a hint
+// nbHintCalls times on the sum
+type hintInputCircuit struct {
+	X []frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func newHintInputCircuit() *hintInputCircuit {
+	return &hintInputCircuit{X: make([]frontend.Variable, nbHintInputTerms)}
+}
+
+func (circuit *hintInputCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	sum := circuit.X[0]
+	for i := 1; i < len(circuit.X); i++ {
+		sum = api.Add(sum, api.Mul(circuit.X[i], i+1))
+	}
+	api.AssertIsEqual(sum, circuit.Y)
+	for i := 0; i < nbHintCalls; i++ {
+		api.AssertIsEqual(api.NewHint(echoHint, sum), circuit.Y)
+	}
+	return nil
+}
+
+// hintInputAssignment sets the inputs to 1, and Y to their weighted sum plus delta
+func hintInputAssignment(delta int) *hintInputCircuit {
+	w := newHintInputCircuit()
+	for i := range w.X {
+		w.X[i].Assign(1)
+	}
+	w.Y.Assign(nbHintInputTerms*(nbHintInputTerms+1)/2 + delta)
+	return w
+}
+
+func TestHintInputThreshold(t *testing.T) {
+	assert := require.New(t)
+
+	raw, err := frontend.Compile(ecc.BN254, backend.GROTH16, newHintInputCircuit(), frontend.WithHintInputThreshold(0))
+	assert.NoError(err)
+	reduced, err := frontend.Compile(ecc.BN254, backend.GROTH16, newHintInputCircuit())
+	assert.NoError(err)
+
+	// the hints share the wire of their input
+	assert.Equal(0, raw.GetNbReducedHintInputs())
+	assert.Equal(1, reduced.GetNbReducedHintInputs())
+	assert.Equal(raw.GetNbConstraints()+1, reduced.GetNbConstraints())
+
+	// the threshold is a number of terms
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, newHintInputCircuit(), frontend.WithHintInputThreshold(nbHintInputTerms))
+	assert.NoError(err)
+	assert.Equal(0, ccs.GetNbReducedHintInputs())
+	ccs, err = frontend.Compile(ecc.BN254, backend.GROTH16, newHintInputCircuit(), frontend.WithHintInputThreshold(nbHintInputTerms-1))
+	assert.NoError(err)
+	assert.Equal(1, ccs.GetNbReducedHintInputs())
+
+	_, err = frontend.Compile(ecc.BN254, backend.GROTH16, newHintInputCircuit(), frontend.WithHintInputThreshold(-1))
+	assert.Error(err)
+
+	// the hints return the same outputs: they satisfy the same assertions
+	for _, ccs := range []frontend.CompiledConstraintSystem{raw, reduced} {
+		assert.NoError(groth16.IsSolved(ccs, hintInputAssignment(0), backend.WithHints(echoHint)))
+		assert.Error(groth16.IsSolved(ccs, hintInputAssignment(1), backend.WithHints(echoHint)))
+	}
+
+	spr, err := frontend.Compile(ecc.BN254, backend.PLONK, newHintInputCircuit())
+	assert.NoError(err)
+	assert.Equal(1, spr.GetNbReducedHintInputs())
+	assert.NoError(plonk.IsSolved(spr, hintInputAssignment(0), backend.WithHints(echoHint)))
+	assert.Error(plonk.IsSolved(spr, hintInputAssignment(1), backend.WithHints(echoHint)))
+}
+
+// BenchmarkHintInputs compares the solver with the hint input stored in the hints, and reduced to a wire
+func BenchmarkHintInputs(b *testing.B) {
+	for name, threshold := range map[string]int{"raw": 0, "reduced": frontend.DefaultHintInputThreshold} {
+		b.Run(name, func(b *testing.B) {
+			ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, newHintInputCircuit(), frontend.WithHintInputThreshold(threshold))
+			if err != nil {
+				b.Fatal(err)
+			}
+			w := hintInputAssignment(0)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := groth16.IsSolved(ccs, w, backend.WithHints(echoHint)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// rangeCheckCircuit checks that X, Y are bytes and that Z is in a small table, using lookups
+type rangeCheckCircuit struct {
+	X, Y frontend.Variable
+	Z    frontend.Variable
+	Sum  frontend.Variable `gnark:",public"`
+}
+
+func (circuit *rangeCheckCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	lk := api.(frontend.Lookuper)
+
+	entries := make([]big.Int, 256)
+	for i := 0; i < len(entries); i++ {
+		entries[i].SetUint64(uint64(i))
+	}
+	bytes := lk.AddTable("bytes", entries)
+	small := lk.AddTable("small", []big.Int{*big.NewInt(3), *big.NewInt(1000)})
+
+	lk.Lookup(bytes, circuit.X)
+	lk.Lookup(bytes, circuit.Y)
+	lk.Lookup(small, circuit.Z)
+
+	// the lookup of a linear expression costs an extra constraint
+	lk.Lookup(bytes, api.Sub(circuit.Sum, circuit.Z))
+
+	api.AssertIsEqual(api.Add(circuit.X, circuit.Y, circuit.Z), circuit.Sum)
+	return nil
+}
+
+func TestLookupRangeCheck(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	var circuit rangeCheckCircuit
+	opts := []func(opt *test.TestingOption) error{
+		test.WithBackends(backend.PLONK),
+		test.WithCurves(ecc.BN254),
+	}
+
+	assert.ProverSucceeded(&circuit, rangeCheckWitness(42, 100, 1000, 1142), opts...)
+
+	// X is not a byte
+	assert.ProverFailed(&circuit, rangeCheckWitness(256, 0, 3, 259), opts...)
+
+	// X is not a byte, X+r is
+	assert.ProverFailed(&circuit, rangeCheckWitness(-1, 1, 3, 3), opts...)
+
+	// Z is a byte, but not in the small table
+	assert.ProverFailed(&circuit, rangeCheckWitness(0, 0, 255, 255), opts...)
+
+	// Sum-Z is not a byte
+	assert.ProverFailed(&circuit, rangeCheckWitness(200, 200, 3, 403), opts...)
+}
+
+func rangeCheckWitness(x, y, z, sum interface{}) *rangeCheckCircuit {
+	var witness rangeCheckCircuit
+	witness.X.Assign(x)
+	witness.Y.Assign(y)
+	witness.Z.Assign(z)
+	witness.Sum.Assign(sum)
+	return &witness
+}
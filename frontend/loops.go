@@ -0,0 +1,118 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/internal/backend/compiled"
+)
+
+// LoopProfile is the number of constraints created by the iterations of a loop, see API.LoopBegin
+type LoopProfile struct {
+	Label string
+
+	// NbConstraints is the number of constraints created by the loop, including its nested loops.
+	// The iterations of a loop nested in a loop with the same label are counted once
+	NbConstraints int
+
+	// Iterations is the number of constraints created by each iteration, in the order they began
+	// (for a nested loop, the iterations within all the iterations of the enclosing loop)
+	Iterations []int
+}
+
+// ProfileLoops returns the number of constraints created by the loops of the circuit of ccs (see
+// API.LoopBegin), in the order of their first iteration, or nil if the circuit doesn't record any
+func ProfileLoops(ccs CompiledConstraintSystem) []LoopProfile {
+	c, ok := ccs.(interface{ GetLoops() *compiled.Loops })
+	if !ok || c.GetLoops() == nil {
+		return nil
+	}
+	loops := c.GetLoops()
+
+	var res []LoopProfile
+	index := make(map[int]int) // label -> index in res
+	for s, scope := range loops.Scopes {
+		i, ok := index[scope.Label]
+		if !ok {
+			i = len(res)
+			index[scope.Label] = i
+			res = append(res, LoopProfile{Label: loops.Labels[scope.Label]})
+		}
+		nbConstraints := scope.End - scope.Begin
+		res[i].Iterations = append(res[i].Iterations, nbConstraints)
+		if !hasAncestor(loops, s, scope.Label) {
+			res[i].NbConstraints += nbConstraints
+		}
+	}
+	return res
+}
+
+// hasAncestor returns true if an ancestor of the scope s has the label
+func hasAncestor(loops *compiled.Loops, s, label int) bool {
+	for p := loops.Scopes[s].Parent; p != -1; p = loops.Scopes[p].Parent {
+		if loops.Scopes[p].Label == label {
+			return true
+		}
+	}
+	return false
+}
+
+// LoopBegin records the beginning of a scope, see API.LoopBegin
+func (cs *constraintSystem) LoopBegin(label string, iteration int) {
+	if cs.loops == nil {
+		cs.loops = &compiled.Loops{}
+		cs.loopIDs = make(map[string]int)
+	}
+	id, ok := cs.loopIDs[label]
+	if !ok {
+		id = len(cs.loops.Labels)
+		cs.loopIDs[label] = id
+		cs.loops.Labels = append(cs.loops.Labels, label)
+	}
+	parent := -1
+	if len(cs.loopStack) > 0 {
+		parent = cs.loopStack[len(cs.loopStack)-1]
+	}
+	cs.loopStack = append(cs.loopStack, len(cs.loops.Scopes))
+	cs.loops.Scopes = append(cs.loops.Scopes, compiled.LoopScope{
+		Label:     id,
+		Iteration: iteration,
+		Begin:     len(cs.constraints),
+		End:       -1,
+		Parent:    parent,
+	})
+}
+
+// LoopEnd records the end of the last scope, see API.LoopEnd
+func (cs *constraintSystem) LoopEnd() {
+	if len(cs.loopStack) == 0 {
+		panic("LoopEnd without LoopBegin")
+	}
+	s := cs.loopStack[len(cs.loopStack)-1]
+	cs.loopStack = cs.loopStack[:len(cs.loopStack)-1]
+	cs.loops.Scopes[s].End = len(cs.constraints)
+}
+
+// checkLoops returns an error if a scope isn't ended, once Define returns
+func (cs *constraintSystem) checkLoops() error {
+	if len(cs.loopStack) != 0 {
+		s := cs.loopStack[len(cs.loopStack)-1]
+		return fmt.Errorf("loop iteration %s not ended by LoopEnd", cs.loops.Name(s))
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// loopCircuit iterates x <- x*x + X, and decomposes x in 4 bits every 10 iterations, in a nested loop
+type loopCircuit struct {
+	X            frontend.Variable
+	Y            frontend.Variable `gnark:",public"`
+	nbIterations int
+	noLoops      bool
+}
+
+func (circuit *loopCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsDifferent(circuit.X, 0)
+	x := circuit.X
+	for i := 0; i < circuit.nbIterations; i++ {
+		if !circuit.noLoops {
+			api.LoopBegin("square", i)
+		}
+		x = api.Add(api.Mul(x, x), circuit.X)
+		if i%10 == 0 {
+			if !circuit.noLoops {
+				api.LoopBegin("bits", i/10)
+			}
+			api.ToBinary(circuit.X, 4)
+			if !circuit.noLoops {
+				api.LoopEnd()
+			}
+		}
+		if !circuit.noLoops {
+			api.LoopEnd()
+		}
+	}
+	api.AssertIsEqual(x, circuit.Y)
+	return nil
+}
+
+func TestLoops(t *testing.T) {
+	assert := require.New(t)
+	const nbIterations = 100
+
+	for _, b := range backend.Implemented() {
+		empty, err := frontend.Compile(ecc.BN254, b, &loopCircuit{})
+		assert.NoError(err)
+		assert.Nil(frontend.ProfileLoops(empty))
+		ccs, err := frontend.Compile(ecc.BN254, b, &loopCircuit{nbIterations: nbIterations})
+		assert.NoError(err)
+
+		profile := frontend.ProfileLoops(ccs)
+		assert.Len(profile, 2, b)
+		square, bits := profile[0], profile[1]
+		assert.Equal("square", square.Label)
+		assert.Equal("bits", bits.Label)
+		assert.Len(square.Iterations, nbIterations)
+		assert.Len(bits.Iterations, nbIterations/10)
+
+		// the iterations account for the constraints of the loop, the other constraints are the ones
+		// of the circuit without iterations
+		sum := 0
+		for _, n := range square.Iterations {
+			assert.NotZero(n)
+			sum += n
+		}
+		assert.Equal(square.NbConstraints, sum, b)
+		assert.Equal(ccs.GetNbConstraints(), empty.GetNbConstraints()+sum, b)
+		sum = 0
+		for _, n := range bits.Iterations {
+			sum += n
+		}
+		assert.Equal(bits.NbConstraints, sum, b)
+		assert.True(bits.NbConstraints < square.NbConstraints)
+
+		// the metadata doesn't change the constraints, and is optional
+		noLoops, err := frontend.Compile(ecc.BN254, b, &loopCircuit{nbIterations: nbIterations, noLoops: true})
+		assert.NoError(err)
+		assert.Equal(noLoops.GetNbConstraints(), ccs.GetNbConstraints())
+		assert.Nil(frontend.ProfileLoops(noLoops))
+
+		// it is serialized
+		var buf bytes.Buffer
+		_, err = ccs.WriteTo(&buf)
+		assert.NoError(err)
+		var read frontend.CompiledConstraintSystem
+		if b == backend.GROTH16 {
+			read = &cs_bn254.R1CS{}
+		} else {
+			read = &cs_bn254.SparseR1CS{}
+		}
+		_, err = read.ReadFrom(&buf)
+		assert.NoError(err)
+		assert.Equal(profile, frontend.ProfileLoops(read))
+
+		// and annotates the constraints
+		buf.Reset()
+		assert.NoError(ccs.ToHTML(&buf))
+		assert.Contains(buf.String(), "square[42]")
+		assert.Contains(buf.String(), "square[40]/bits[4]")
+	}
+
+	// the blueprints are the ones detected without the loops
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &loopCircuit{nbIterations: nbIterations})
+	assert.NoError(err)
+	r1cs := ccs.(*cs_bn254.R1CS)
+	assert.Equal(r1cs.Constraints, compiled.NewR1CList(r1cs.Constraints.All()))
+
+	// the loops don't change the solving
+	x, y := big.NewInt(3), big.NewInt(3)
+	modulus := ecc.BN254.Info().Fr.Modulus()
+	for i := 0; i < nbIterations; i++ {
+		y.Mul(y, y).Add(y, x).Mod(y, modulus)
+	}
+	var witness loopCircuit
+	witness.X.Assign(x)
+	witness.Y.Assign(y)
+	test.NewAssert(t).ProverSucceeded(&loopCircuit{nbIterations: nbIterations}, &witness, test.WithCurves(ecc.BN254))
+}
+
+// unendedLoopCircuit doesn't end its loop iteration
+type unendedLoopCircuit struct {
+	X frontend.Variable
+}
+
+func (circuit *unendedLoopCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.LoopBegin("loop", 3)
+	api.AssertIsEqual(circuit.X, 1)
+	return nil
+}
+
+func TestUnendedLoop(t *testing.T) {
+	_, err := frontend.Compile(ecc.BN254, backend.GROTH16, &unendedLoopCircuit{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "loop[3]")
+}
@@ -0,0 +1,156 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+type metadataCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *metadataCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+func TestMetadata(t *testing.T) {
+	assert := require.New(t)
+
+	metadata := map[string]string{"version": "1.2.0", "depth": "32"}
+	otherMetadata := map[string]string{"version": "1.2.0", "depth": "16"}
+
+	var witness metadataCircuit
+	witness.X.Assign(3)
+	witness.Y.Assign(9)
+
+	for _, curve := range ecc.Implemented() {
+		for _, b := range backend.Implemented() {
+			ccs, err := frontend.Compile(curve, b, &metadataCircuit{}, frontend.WithMetadata(metadata))
+			assert.NoError(err)
+			assert.Equal(metadata, ccs.GetMetadata())
+
+			other, err := frontend.Compile(curve, b, &metadataCircuit{}, frontend.WithMetadata(otherMetadata))
+			assert.NoError(err)
+
+			// the metadata is serialized with the constraint system
+			var buf bytes.Buffer
+			_, err = ccs.WriteTo(&buf)
+			assert.NoError(err)
+			var reloadedCCS frontend.CompiledConstraintSystem
+			if b == backend.GROTH16 {
+				reloadedCCS = groth16.NewCS(curve)
+			} else {
+				reloadedCCS = plonk.NewCS(curve)
+			}
+			_, err = reloadedCCS.ReadFrom(&buf)
+			assert.NoError(err)
+			assert.Equal(metadata, reloadedCCS.GetMetadata())
+
+			switch b {
+			case backend.GROTH16:
+				pk, vk, err := groth16.Setup(ccs)
+				assert.NoError(err)
+				assert.Equal(metadata, pk.GetMetadata())
+				assert.Equal(metadata, vk.GetMetadata())
+
+				// and with the keys
+				buf.Reset()
+				_, err = vk.WriteTo(&buf)
+				assert.NoError(err)
+				reloadedVk := groth16.NewVerifyingKey(curve)
+				_, err = reloadedVk.ReadFrom(&buf)
+				assert.NoError(err)
+				assert.Equal(metadata, reloadedVk.GetMetadata())
+				assert.Equal(0, buf.Len())
+
+				buf.Reset()
+				_, err = pk.WriteRawTo(&buf)
+				assert.NoError(err)
+				reloadedPk := groth16.NewProvingKey(curve)
+				_, err = reloadedPk.ReadFrom(&buf)
+				assert.NoError(err)
+				assert.Equal(metadata, reloadedPk.GetMetadata())
+
+				buf.Reset()
+				assert.NoError(pk.WriteDump(&buf))
+				dumpedPk := groth16.NewProvingKey(curve)
+				assert.NoError(dumpedPk.ReadDump(&buf))
+				assert.Equal(metadata, dumpedPk.GetMetadata())
+
+				proof, err := groth16.Prove(reloadedCCS, reloadedPk, &witness)
+				assert.NoError(err)
+				assert.NoError(groth16.Verify(proof, reloadedVk, &witness))
+
+				// a proving key can't be used with a constraint system with different metadata
+				_, err = groth16.Prove(other, pk, &witness)
+				assert.ErrorIs(err, backend.ErrMetadataMismatch)
+
+			case backend.PLONK:
+				srs, err := test.NewKZGSRS(ccs)
+				assert.NoError(err)
+				pk, vk, err := plonk.Setup(ccs, srs)
+				assert.NoError(err)
+				assert.Equal(metadata, pk.GetMetadata())
+				assert.Equal(metadata, vk.GetMetadata())
+
+				buf.Reset()
+				_, err = vk.WriteTo(&buf)
+				assert.NoError(err)
+				reloadedVk := plonk.NewVerifyingKey(curve)
+				_, err = reloadedVk.ReadFrom(&buf)
+				assert.NoError(err)
+				assert.NoError(reloadedVk.InitKZG(srs))
+				assert.Equal(metadata, reloadedVk.GetMetadata())
+
+				buf.Reset()
+				_, err = pk.WriteTo(&buf)
+				assert.NoError(err)
+				reloadedPk := plonk.NewProvingKey(curve)
+				_, err = reloadedPk.ReadFrom(&buf)
+				assert.NoError(err)
+				assert.NoError(reloadedPk.InitKZG(srs))
+				assert.Equal(metadata, reloadedPk.GetMetadata())
+
+				proof, err := plonk.Prove(reloadedCCS, reloadedPk, &witness)
+				assert.NoError(err)
+				assert.NoError(plonk.Verify(proof, reloadedVk, &witness))
+
+				_, err = plonk.Prove(other, pk, &witness)
+				assert.ErrorIs(err, backend.ErrMetadataMismatch)
+			}
+		}
+	}
+
+	// keys and values are limited to 1KB in total
+	_, err := frontend.Compile(ecc.BN254, backend.GROTH16, &metadataCircuit{}, frontend.WithMetadata(map[string]string{
+		"description": strings.Repeat("a", frontend.MaxMetadataSize),
+	}))
+	assert.ErrorIs(err, frontend.ErrMetadataTooLarge)
+}
@@ -0,0 +1,360 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/consensys/gnark/internal/curves"
+	"github.com/consensys/gnark/internal/parser"
+)
+
+// ErrSchemaMismatch is returned by Schema.Visit, and by Compile with WithSchema, if the circuit
+// doesn't have the type or the slice lengths of the circuit the schema was parsed from
+var ErrSchemaMismatch = errors.New("schema mismatch")
+
+// Schema is a reusable description of the inputs of a circuit type, that is of its witness layout.
+//
+// It is built once by ParseSchema, and can then be given to Compile (see WithSchema) for
+// several curves or backends, without reflecting over the circuit structure again. It can be
+// serialized, for example with encoding/json.
+type Schema struct {
+	Circuit  string  `json:"circuit"` // Go type of the circuit
+	NbPublic int     `json:"nbPublic"`
+	NbSecret int     `json:"nbSecret"`
+	Fields   []Field `json:"fields"`           // inputs, in the order of definition in the circuit structure
+	Arrays   []Array `json:"arrays"`           // slices and arrays of the circuit structure
+	Strict   bool    `json:"strict,omitempty"` // parsed by ParseSchemaStrict
+}
+
+// Field is an input of a circuit, see Schema
+type Field struct {
+	Name       string     `json:"name"`               // name used by the compiler, for example "A_B_0"
+	Path       string     `json:"path"`               // dotted path of the Go field names (or slice indexes), for example "A.B.0"
+	Visibility Visibility `json:"visibility"`         // Secret or Public
+	Optional   bool       `json:"optional,omitempty"` // secret input tagged "optional", assigned 0 if missing from a witness
+	Output     bool       `json:"output,omitempty"`   // public input tagged "public,output", computed by the circuit (see API.SetOutput)
+	Index      []int      `json:"index"`              // field number (or slice index) at each level of the circuit structure
+}
+
+// Array is a slice or an array of a circuit, see Schema
+type Array struct {
+	Path  string `json:"path"`
+	Len   int    `json:"len"`
+	Index []int  `json:"index"`
+}
+
+// ParseSchema returns the schema of circuit, that is the description of its inputs
+// (names, visibilities, and length of its slices and arrays).
+//
+// A circuit with overridden visibilities (see OverrideVisibility) results in a schema with the
+// overridden visibilities.
+func ParseSchema(circuit Circuit) (*Schema, error) {
+	s, _, err := parseSchema(circuit, false)
+	return s, err
+}
+
+// ParseSchemaStrict is like ParseSchema, but rejects the circuit structures which layout is ambiguous:
+//
+//   - an exported field which isn't a Variable, a struct, or a pointer, slice or array of such (a config int,
+//     a map, ...) must be tagged `gnark:"-"`, instead of being ignored;
+//   - the visibility of each input must be given by a gnark tag ("secret" or "public") of the input or of
+//     one of its parents, or by an override (see OverrideVisibility), instead of being secret by default;
+//   - two inputs can't have the same name, for example a field A_B and the field B of a field A.
+//
+// The rules only reject structures, the schema is the one of ParseSchema: witnesses built from a circuit
+// ParseSchemaStrict accepts have the layout of the compiled constraint system, whatever the mode.
+// See WithStrictSchema.
+func ParseSchemaStrict(circuit Circuit) (*Schema, error) {
+	s, _, err := parseSchema(circuit, true)
+	return s, err
+}
+
+// parseSchema returns the schema of circuit, and, if strict isn't set, the violations of the rules of
+// ParseSchemaStrict as warnings instead of an error
+func parseSchema(circuit Circuit, strict bool) (*Schema, []string, error) {
+	leafs, arrays, err := parser.Walk(circuit, tVariable, strict)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var warnings, implicit []string
+	paths := make(map[string]string, len(leafs)) // name -> path
+	for _, l := range leafs {
+		if path, ok := paths[l.Name]; ok {
+			msg := fmt.Sprintf("inputs %s and %s have the same name %q", path, l.Path, l.Name)
+			if strict {
+				return nil, nil, errors.New(msg)
+			}
+			warnings = append(warnings, msg)
+		}
+		paths[l.Name] = l.Path
+		if !l.Explicit {
+			if strict {
+				return nil, nil, fmt.Errorf("%s: input without visibility, it must be tagged `gnark:\",secret\"` or `gnark:\",public\"` (or have such a parent)", l.Path)
+			}
+			implicit = append(implicit, l.Path)
+		}
+	}
+	if len(implicit) != 0 {
+		const maxListed = 5
+		listed := strings.Join(implicit, ", ")
+		if len(implicit) > maxListed {
+			listed = strings.Join(implicit[:maxListed], ", ") + ", ..."
+		}
+		warnings = append(warnings, fmt.Sprintf("%d inputs without visibility tag are secret (%s)", len(implicit), listed))
+	}
+
+	s := &Schema{
+		Circuit: reflect.TypeOf(unwrap(circuit)).String(),
+		Fields:  make([]Field, len(leafs)),
+		Arrays:  make([]Array, len(arrays)),
+		Strict:  strict,
+	}
+	for i, l := range leafs {
+		s.Fields[i] = Field{Name: l.Name, Path: l.Path, Visibility: l.Visibility, Optional: l.Optional, Output: l.Output, Index: l.Index}
+		switch l.Visibility {
+		case compiled.Public:
+			s.NbPublic++
+		case compiled.Secret:
+			s.NbSecret++
+		}
+	}
+	for i, a := range arrays {
+		s.Arrays[i] = Array{Path: a.Path, Len: a.Len, Index: a.Index}
+	}
+	return s, warnings, nil
+}
+
+// Visit calls handler on the inputs of circuit described by the schema, in the order of the schema.
+//
+// circuit must have the Go type of the circuit the schema was parsed from, with slices of the
+// same length, else Visit fails with ErrSchemaMismatch; the visibilities of the schema take
+// precedence over the ones of circuit.
+func (s *Schema) Visit(circuit Circuit, handler func(f *Field, v *Variable) error) error {
+	input := unwrap(circuit)
+	if t := reflect.TypeOf(input).String(); t != s.Circuit {
+		return fmt.Errorf("%w: schema of %s can't be used with %s", ErrSchemaMismatch, s.Circuit, t)
+	}
+	root := reflect.ValueOf(input)
+	if root.Kind() == reflect.Ptr {
+		root = root.Elem()
+	}
+
+	for i := 0; i < len(s.Arrays); i++ {
+		a := &s.Arrays[i]
+		v, err := fieldByIndex(root, a.Index)
+		if err != nil || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+			return fmt.Errorf("%w: schema of %s: invalid array %s", ErrSchemaMismatch, s.Circuit, a.Path)
+		}
+		if v.Len() != a.Len {
+			return fmt.Errorf("%w: schema of %s: %s has length %d, expected %d", ErrSchemaMismatch, s.Circuit, a.Path, v.Len(), a.Len)
+		}
+	}
+
+	for i := 0; i < len(s.Fields); i++ {
+		f := &s.Fields[i]
+		v, err := fieldByIndex(root, f.Index)
+		if err != nil || v.Type() != tVariable || !v.CanAddr() {
+			return fmt.Errorf("%w: schema of %s: invalid input %s", ErrSchemaMismatch, s.Circuit, f.Path)
+		}
+		if err := handler(f, v.Addr().Interface().(*Variable)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WitnessIndexes returns, for each input of the schema (in the order of Fields), its index
+// in the full witness vector [ public | secret ], or -1 if it is neither public nor secret.
+func (s *Schema) WitnessIndexes() []int {
+	r := make([]int, len(s.Fields))
+	i, j := s.NbPublic, 0 // next secret / public index
+	for k := 0; k < len(s.Fields); k++ {
+		switch s.Fields[k].Visibility {
+		case compiled.Secret:
+			r[k] = i
+			i++
+		case compiled.Public:
+			r[k] = j
+			j++
+		default:
+			r[k] = -1
+		}
+	}
+	return r
+}
+
+// SecretInputs returns the secret inputs of circuit, in the order of the secret witness vector (see
+// Visit). In Define, they are the secret wires of the circuit; for an assignment, their values.
+func (s *Schema) SecretInputs(circuit Circuit) ([]Variable, error) {
+	res := make([]Variable, 0, s.NbSecret)
+	err := s.Visit(circuit, func(f *Field, v *Variable) error {
+		if f.Visibility == compiled.Secret {
+			res = append(res, *v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// SecretInputs returns the secret inputs of circuit, see Schema.SecretInputs
+func SecretInputs(circuit Circuit) ([]Variable, error) {
+	s, err := ParseSchema(circuit)
+	if err != nil {
+		return nil, err
+	}
+	return s.SecretInputs(circuit)
+}
+
+var tVariable = reflect.TypeOf(Variable{})
+
+// unwrap returns the circuit which visibilities are overridden, if any
+func unwrap(circuit Circuit) interface{} {
+	if o, ok := circuit.(parser.VisibilityOverrider); ok {
+		input, _ := o.OverriddenVisibility()
+		return input
+	}
+	return circuit
+}
+
+// fieldByIndex returns the struct field or slice element of v at index, following the pointers
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for _, i := range index {
+		for v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			if i < 0 || i >= v.NumField() {
+				return v, errors.New("invalid field index")
+			}
+			v = v.Field(i)
+		case reflect.Slice, reflect.Array:
+			if i < 0 || i >= v.Len() {
+				return v, errors.New("invalid slice index")
+			}
+			v = v.Index(i)
+		default:
+			return v, errors.New("invalid index")
+		}
+	}
+	return v, nil
+}
+
+// witnessSchema is the subset of JSON Schema (draft-07) used by WriteWitnessSchema
+type witnessSchema struct {
+	Schema               string                   `json:"$schema,omitempty"`
+	Comment              string                   `json:"$comment,omitempty"`
+	Ref                  string                   `json:"$ref,omitempty"`
+	Title                string                   `json:"title,omitempty"`
+	Description          string                   `json:"description,omitempty"`
+	Type                 string                   `json:"type,omitempty"`
+	Pattern              string                   `json:"pattern,omitempty"`
+	Properties           map[string]witnessSchema `json:"properties,omitempty"`
+	Required             []string                 `json:"required,omitempty"`
+	AdditionalProperties *bool                    `json:"additionalProperties,omitempty"`
+	Definitions          map[string]witnessSchema `json:"definitions,omitempty"`
+}
+
+// WriteWitnessSchema writes a JSON Schema (draft-07) describing the JSON witness of circuit,
+// as produced by witness.ToJSON:
+//
+//	{
+//		"Public": {"Y": "35"},
+//		"Secret": {"X": "3", "Z": "2"}
+//	}
+//
+// The inputs are listed with the names used by the compiler (nested structures and arrays are
+// flattened, for example "A_B_0"), and their values are strings containing a decimal integer,
+// taken modulo the scalar field of the curve (see the "$comment" of the schema).
+func WriteWitnessSchema(w io.Writer, circuit Circuit) error {
+	s, err := ParseSchema(circuit)
+	if err != nil {
+		return err
+	}
+	return s.WriteWitnessSchema(w)
+}
+
+// WriteWitnessSchema writes a JSON Schema describing the JSON witness of the circuit,
+// see WriteWitnessSchema
+func (s *Schema) WriteWitnessSchema(w io.Writer) error {
+	public := objectSchema("public inputs")
+	secret := objectSchema("secret inputs")
+
+	element := witnessSchema{Ref: "#/definitions/element"}
+
+	for _, f := range s.Fields {
+		switch f.Visibility {
+		case compiled.Public:
+			public.Properties[f.Name] = element
+			public.Required = append(public.Required, f.Name)
+		case compiled.Secret:
+			secret.Properties[f.Name] = element
+			if !f.Optional {
+				secret.Required = append(secret.Required, f.Name)
+			}
+		}
+	}
+	sort.Strings(public.Required)
+	sort.Strings(secret.Required)
+
+	curves := curves.Implemented()
+	moduli := make([]string, 0, len(curves))
+	for _, curveID := range curves {
+		moduli = append(moduli, fmt.Sprintf("%s: %s", curveID, curveID.Info().Fr.Modulus()))
+	}
+
+	schema := objectSchema("public and secret inputs of the circuit, by name")
+	schema.Schema = "http://json-schema.org/draft-07/schema#"
+	schema.Title = fmt.Sprintf("witness of %s", s.Circuit)
+	schema.Comment = "values are reduced modulo the scalar field modulus of the curve; " + strings.Join(moduli, ", ")
+	schema.Properties["Public"] = public
+	schema.Properties["Secret"] = secret
+	schema.Required = []string{"Public", "Secret"}
+	schema.Definitions = map[string]witnessSchema{
+		"element": {
+			Description: "field element, as a decimal integer (negative values are taken modulo the field modulus)",
+			Type:        "string",
+			Pattern:     "^-?[0-9]+$",
+		},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(schema)
+}
+
+func objectSchema(description string) witnessSchema {
+	noAdditionalProperties := false
+	return witnessSchema{
+		Description:          description,
+		Type:                 "object",
+		Properties:           make(map[string]witnessSchema),
+		AdditionalProperties: &noAdditionalProperties,
+	}
+}
@@ -0,0 +1,353 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/logger"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaPoint struct {
+	X, Y frontend.Variable
+}
+
+type schemaCircuit struct {
+	Points [2]schemaPoint
+	Root   frontend.Variable `gnark:"root,public"`
+	Nonce  frontend.Variable
+}
+
+func (circuit *schemaCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	return nil
+}
+
+func TestWitnessSchema(t *testing.T) {
+	assert := require.New(t)
+
+	var buf bytes.Buffer
+	assert.NoError(frontend.WriteWitnessSchema(&buf, &schemaCircuit{}))
+	var schema map[string]interface{}
+	assert.NoError(json.Unmarshal(buf.Bytes(), &schema))
+
+	var w schemaCircuit
+	for i := range w.Points {
+		w.Points[i].X.Assign(i)
+		w.Points[i].Y.Assign(42)
+	}
+	w.Root.Assign(-1)
+	w.Nonce.Assign("123456789")
+
+	for _, curveID := range ecc.Implemented() {
+		j, err := witness.ToJSON(&w, curveID)
+		assert.NoError(err)
+		assert.NoError(validate(schema, schema, unmarshal(t, j), ""), "%s", curveID)
+	}
+
+	// misshaped witnesses
+	for _, j := range []string{
+		`{"Public": {"root": "1"}, "Secret": {"Points_0_X": "1", "Points_0_Y": "1", "Points_1_X": "1", "Points_1_Y": "1"}}`,
+		`{"Public": {"root": "1"}, "Secret": {"Points_0_X": "1", "Points_0_Y": "1", "Points_1_X": "1", "Points_1_Y": "1", "Nonce": "1", "Other": "1"}}`,
+		`{"Public": {}, "Secret": {"Points_0_X": "1", "Points_0_Y": "1", "Points_1_X": "1", "Points_1_Y": "1", "Nonce": "1", "root": "1"}}`,
+		`{"Public": {"root": 1}, "Secret": {"Points_0_X": "1", "Points_0_Y": "1", "Points_1_X": "1", "Points_1_Y": "1", "Nonce": "1"}}`,
+		`{"Public": {"root": "0x1"}, "Secret": {"Points_0_X": "1", "Points_0_Y": "1", "Points_1_X": "1", "Points_1_Y": "1", "Nonce": "1"}}`,
+		`{"Public": {"root": "<nil>"}, "Secret": {"Points_0_X": "1", "Points_0_Y": "1", "Points_1_X": "1", "Points_1_Y": "1", "Nonce": "1"}}`,
+		`{"Secret": {"Points_0_X": "1", "Points_0_Y": "1", "Points_1_X": "1", "Points_1_Y": "1", "Nonce": "1"}}`,
+		`{"Public": {"root": "1"}, "Secret": {"Points": [{"X": "1", "Y": "1"}, {"X": "1", "Y": "1"}], "Nonce": "1"}}`,
+	} {
+		assert.Error(validate(schema, schema, unmarshal(t, j), ""), j)
+	}
+}
+
+func unmarshal(t *testing.T, s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// validate checks value against schema; it implements the subset of JSON Schema
+// used by frontend.WriteWitnessSchema
+func validate(root, schema map[string]interface{}, value interface{}, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/definitions/")
+		return validate(root, root["definitions"].(map[string]interface{})[name].(map[string]interface{}), value, path)
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an object", path)
+		}
+		for _, name := range schema["required"].([]interface{}) {
+			if _, ok := object[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing %s", path, name)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, v := range object {
+			property, ok := properties[name]
+			if !ok {
+				if schema["additionalProperties"] == false {
+					return fmt.Errorf("%s: unexpected %s", path, name)
+				}
+				continue
+			}
+			if err := validate(root, property.(map[string]interface{}), v, path+"/"+name); err != nil {
+				return err
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected a string", path)
+		}
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(s) {
+			return fmt.Errorf("%s: %q doesn't match %s", path, s, pattern)
+		}
+	default:
+		return fmt.Errorf("%s: unsupported type %v", path, schema["type"])
+	}
+	return nil
+}
+
+type wideCircuit struct {
+	Rows [64][32]frontend.Variable
+	Sum  frontend.Variable `gnark:",public"`
+}
+
+func (circuit *wideCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	var inputs []frontend.Variable
+	for i := range circuit.Rows {
+		inputs = append(inputs, circuit.Rows[i][:]...)
+	}
+	api.AssertIsEqual(api.Sum(inputs...), circuit.Sum)
+	return nil
+}
+
+func TestSchema(t *testing.T) {
+	assert := require.New(t)
+
+	schema, err := frontend.ParseSchema(&schemaCircuit{})
+	assert.NoError(err)
+	assert.Equal("*frontend_test.schemaCircuit", schema.Circuit)
+	assert.Equal(1, schema.NbPublic)
+	assert.Equal(5, schema.NbSecret)
+	assert.Equal(frontend.Field{Name: "Points_1_Y", Path: "Points.1.Y", Visibility: frontend.Secret, Index: []int{0, 1, 1}}, schema.Fields[3])
+	assert.Equal(frontend.Field{Name: "root", Path: "Root", Visibility: frontend.Public, Index: []int{1}}, schema.Fields[4])
+	assert.Equal([]frontend.Array{{Path: "Points", Len: 2, Index: []int{0}}}, schema.Arrays)
+	assert.Equal([]int{1, 2, 3, 4, 0, 5}, schema.WitnessIndexes())
+
+	// overridden visibilities are part of the schema
+	overridden, err := frontend.ParseSchema(frontend.OverrideVisibility(&schemaCircuit{}, map[string]frontend.Visibility{"Points.0": frontend.Public}))
+	assert.NoError(err)
+	assert.Equal(schema.Circuit, overridden.Circuit)
+	assert.Equal(3, overridden.NbPublic)
+
+	// a schema only applies to circuits with the same type and slices lengths
+	handler := func(f *frontend.Field, v *frontend.Variable) error { return nil }
+	assert.NoError(schema.Visit(&schemaCircuit{}, handler))
+	assert.ErrorIs(schema.Visit(&wideCircuit{}, handler), frontend.ErrSchemaMismatch)
+
+	sliceSchema, err := frontend.ParseSchema(&sliceCircuit{A: make([]frontend.Variable, 2)})
+	assert.NoError(err)
+	assert.NoError(sliceSchema.Visit(&sliceCircuit{A: make([]frontend.Variable, 2)}, handler))
+	assert.ErrorIs(sliceSchema.Visit(&sliceCircuit{A: make([]frontend.Variable, 3)}, handler), frontend.ErrSchemaMismatch)
+}
+
+type sliceCircuit struct {
+	A []frontend.Variable
+}
+
+func (circuit *sliceCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(circuit.A[0], circuit.A[1])
+	return nil
+}
+
+func TestSchemaSerialization(t *testing.T) {
+	assert := require.New(t)
+
+	schema, err := frontend.ParseSchema(frontend.OverrideVisibility(&wideCircuit{}, map[string]frontend.Visibility{"Rows.3": frontend.Public}))
+	assert.NoError(err)
+
+	data, err := json.Marshal(schema)
+	assert.NoError(err)
+	assert.Contains(string(data), `"visibility":"public"`)
+	var decoded frontend.Schema
+	assert.NoError(json.Unmarshal(data, &decoded))
+	assert.Equal(schema, &decoded)
+
+	// compiling with the (decoded) schema gives the same constraint system
+	for _, curveID := range ecc.Implemented() {
+		for _, b := range backend.Implemented() {
+			expected, err := frontend.Compile(curveID, b, &wideCircuit{}, frontend.WithVisibilityOverride(map[string]frontend.Visibility{"Rows.3": frontend.Public}))
+			assert.NoError(err)
+			ccs, err := frontend.Compile(curveID, b, &wideCircuit{}, frontend.WithSchema(&decoded))
+			assert.NoError(err)
+
+			var bExpected, bCCS bytes.Buffer
+			_, err = expected.WriteTo(&bExpected)
+			assert.NoError(err)
+			_, err = ccs.WriteTo(&bCCS)
+			assert.NoError(err)
+			assert.True(bytes.Equal(bExpected.Bytes(), bCCS.Bytes()), "%s %s", curveID, b)
+		}
+	}
+
+	_, err = frontend.Compile(ecc.BN254, backend.GROTH16, &wideCircuit{}, frontend.WithSchema(&decoded), frontend.WithVisibilityOverride(map[string]frontend.Visibility{"Sum": frontend.Secret}))
+	assert.Error(err)
+}
+
+type strictCircuit struct {
+	Config int `gnark:"-"`
+	nbRows int
+	X      frontend.Variable   `gnark:",secret"`
+	Y      frontend.Variable   `gnark:"y,public"`
+	Point  schemaPoint         `gnark:",secret"`
+	Rows   []frontend.Variable `gnark:",public"`
+	Salt   frontend.Variable   `gnark:",secret,optional"`
+}
+
+func (circuit *strictCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	return nil
+}
+
+type configCircuit struct {
+	X      frontend.Variable `gnark:",secret"`
+	NbBits int
+}
+
+func (circuit *configCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	return nil
+}
+
+type untaggedCircuit struct {
+	X     frontend.Variable `gnark:",secret"`
+	Nonce frontend.Variable
+}
+
+func (circuit *untaggedCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	return nil
+}
+
+type duplicateCircuit struct {
+	A   schemaPoint       `gnark:",secret"`
+	A_X frontend.Variable `gnark:",public"`
+}
+
+func (circuit *duplicateCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	return nil
+}
+
+func TestStrictSchema(t *testing.T) {
+	assert := require.New(t)
+	compile := func(circuit frontend.Circuit, opts ...func(opt *frontend.CompileOption) error) error {
+		_, err := frontend.Compile(ecc.BN254, backend.GROTH16, circuit, append(opts, frontend.IgnoreUnconstrainedInputs)...)
+		return err
+	}
+
+	// the rules only reject structures: the schema is the same in both modes
+	circuit := &strictCircuit{Rows: make([]frontend.Variable, 2)}
+	strict, err := frontend.ParseSchemaStrict(circuit)
+	assert.NoError(err)
+	lenient, err := frontend.ParseSchema(circuit)
+	assert.NoError(err)
+	assert.True(strict.Strict)
+	assert.False(lenient.Strict)
+	lenient.Strict = true
+	assert.Equal(lenient, strict)
+	assert.Equal(3, strict.NbPublic)
+	assert.NoError(compile(circuit, frontend.WithStrictSchema()))
+
+	// each violation, named
+	for _, c := range []struct {
+		circuit frontend.Circuit
+		name    string
+	}{
+		{&configCircuit{}, "NbBits"},
+		{&untaggedCircuit{}, "Nonce"},
+		{&duplicateCircuit{}, "A_X"},
+		{&schemaCircuit{}, "Points.0.X"},
+	} {
+		_, err := frontend.ParseSchemaStrict(c.circuit)
+		assert.Error(err)
+		assert.Contains(err.Error(), c.name)
+		err = compile(c.circuit, frontend.WithStrictSchema())
+		assert.Error(err)
+		assert.Contains(err.Error(), c.name)
+
+		_, err = frontend.ParseSchema(c.circuit)
+		assert.NoError(err)
+	}
+
+	// without strict mode, the untagged inputs and the duplicate names are warnings
+	var logs bytes.Buffer
+	logger.SetOutput(&logs)
+	defer logger.SetOutput(os.Stdout)
+	assert.NoError(compile(&untaggedCircuit{}))
+	assert.Contains(logs.String(), "Nonce")
+	assert.NoError(compile(&duplicateCircuit{}))
+	assert.Contains(logs.String(), `same name "A_X"`)
+	logs.Reset()
+	assert.NoError(compile(&configCircuit{}))
+	assert.NoError(compile(circuit))
+	assert.Empty(logs.String())
+
+	// overridden visibilities are explicit
+	overrides := map[string]frontend.Visibility{"Points": frontend.Secret, "Nonce": frontend.Public}
+	assert.NoError(compile(&schemaCircuit{}, frontend.WithStrictSchema(), frontend.WithVisibilityOverride(overrides)))
+
+	// a schema given to a strict compilation must be strict
+	lenient, err = frontend.ParseSchema(circuit)
+	assert.NoError(err)
+	assert.Error(compile(circuit, frontend.WithStrictSchema(), frontend.WithSchema(lenient)))
+	assert.NoError(compile(circuit, frontend.WithStrictSchema(), frontend.WithSchema(strict)))
+}
+
+func BenchmarkCompileSchema(b *testing.B) {
+	curves := []ecc.ID{ecc.BN254, ecc.BLS12_381, ecc.BLS12_377, ecc.BW6_761}
+
+	b.Run("parse", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, curveID := range curves {
+				_, _ = frontend.Compile(curveID, backend.GROTH16, &wideCircuit{})
+			}
+		}
+	})
+
+	b.Run("schema", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			schema, _ := frontend.ParseSchema(&wideCircuit{})
+			for _, curveID := range curves {
+				_, _ = frontend.Compile(curveID, backend.GROTH16, &wideCircuit{}, frontend.WithSchema(schema))
+			}
+		}
+	})
+}
@@ -0,0 +1,752 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/consensys/gnark/internal/parser"
+)
+
+// Simulator executes the Define method of a circuit on the values of a witness, with big.Int
+// arithmetic modulo the curve scalar field, without compiling a constraint system. The hints are
+// called directly.
+//
+// The assertions of the circuit are checked as they are executed; the values of the outputs of the
+// circuit (see API.MarkOutput) are returned. This makes a circuit usable as the reference implementation
+// of what it computes, for example to precompute a witness.
+type Simulator struct {
+	curveID ecc.ID
+}
+
+// NewSimulator returns a Simulator of circuits on the scalar field of the given curve
+func NewSimulator(curveID ecc.ID) *Simulator {
+	return &Simulator{curveID: curveID}
+}
+
+// Run executes circuit.Define with the inputs assigned in witness, and returns the values of the outputs
+// of the circuit, marked by API.MarkOutput or by the tag `gnark:",output"`, by name.
+//
+// It returns an error if an input is not assigned, or if the execution fails (an assertion doesn't
+// hold, a hint fails, ...). The circuit is not modified.
+func (s *Simulator) Run(circuit, witness Circuit) (outputs map[string]*big.Int, err error) {
+	e := &engine{curveID: s.curveID, outputs: make(map[string]*big.Int)}
+
+	// we clone the circuit, in case the circuit has some attributes it uses in its Define function
+	// set by the user.
+	// then, we set all the variables values to the ones from the witness
+	c := shallowClone(circuit)
+	defer saveVariables(c)()
+	if err := copyWitness(c, witness); err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			outputs = nil
+			err = fmt.Errorf("%v\n%s", r, string(debug.Stack()))
+		}
+	}()
+
+	if err := c.Define(s.curveID, e); err != nil {
+		return nil, err
+	}
+	if e.loopDepth != 0 {
+		return nil, fmt.Errorf("%d loop iteration(s) not ended by LoopEnd", e.loopDepth)
+	}
+
+	// the output fields are set by Define
+	err = parser.VisitOutputs(c, func(visibility compiled.Visibility, name string, tValue reflect.Value) error {
+		v := tValue.Interface().(Variable)
+		if v.WitnessValue == nil {
+			return fmt.Errorf("output %s is not set by Define", name)
+		}
+		e.MarkOutput(v, name)
+		return nil
+	}, tVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.outputs, nil
+}
+
+// shallowClone clones given circuit
+// this is actually a shallow copy --> if the circuits contains maps or slices
+// only the reference is copied.
+func shallowClone(circuit Circuit) Circuit {
+	cValue := reflect.ValueOf(circuit).Elem()
+	newCircuit := reflect.New(cValue.Type())
+	newCircuit.Elem().Set(cValue)
+
+	return newCircuit.Interface().(Circuit)
+}
+
+// saveVariables returns a function restoring the inputs and outputs of c to their current values:
+// the clone of a circuit shares its slices with the circuit, which Run must not modify
+func saveVariables(c Circuit) (restore func()) {
+	var values []reflect.Value
+	var saved []Variable
+	save := func(visibility compiled.Visibility, name string, tValue reflect.Value) error {
+		values = append(values, tValue)
+		saved = append(saved, tValue.Interface().(Variable))
+		return nil
+	}
+	// errors (invalid tags) are reported by copyWitness
+	_ = parser.Visit(c, "", compiled.Unset, save, tVariable)
+	_ = parser.VisitOutputs(c, save, tVariable)
+
+	return func() {
+		for i := 0; i < len(values); i++ {
+			values[i].Set(reflect.ValueOf(saved[i]))
+		}
+	}
+}
+
+// copyWitness sets the inputs of to to the values assigned in from
+func copyWitness(to, from Circuit) error {
+	var wValues []interface{}
+
+	schema, err := ParseSchema(from)
+	if err != nil {
+		return err
+	}
+	collectHandler := func(f *Field, v *Variable) error {
+		if f.Visibility == compiled.Secret || f.Visibility == compiled.Public {
+			if v.WitnessValue == nil {
+				if f.Output {
+					wValues = append(wValues, nil) // computed by the circuit, see engine.SetOutput
+					return nil
+				}
+				if !f.Optional {
+					return fmt.Errorf("when parsing variable %s: missing assignment", f.Name)
+				}
+				wValues = append(wValues, 0) // missing optional input
+				return nil
+			}
+			wValues = append(wValues, v.WitnessValue)
+		}
+		return nil
+	}
+	if err := schema.Visit(from, collectHandler); err != nil {
+		return err
+	}
+
+	i := 0
+	setHandler := func(visibility compiled.Visibility, name string, tInput reflect.Value) error {
+		if visibility == compiled.Secret || visibility == compiled.Public {
+			if i >= len(wValues) {
+				return fmt.Errorf("when parsing variable %s: the witness has less inputs than the circuit", name)
+			}
+			tInput.Set(reflect.ValueOf(Value(wValues[i])))
+			i++
+		}
+		return nil
+	}
+	if err := parser.Visit(to, "", compiled.Unset, setHandler, tVariable); err != nil {
+		return err
+	}
+	if i != len(wValues) {
+		return fmt.Errorf("the witness has %d inputs, the circuit has %d", len(wValues), i)
+	}
+	return nil
+}
+
+// engine implements API
+//
+// it converts the inputs to the API to big.Int (after a mod reduce using the curve base field)
+type engine struct {
+	curveID   ecc.ID
+	tables    []lookupTable
+	outputs   map[string]*big.Int
+	loopDepth int // number of open loop iterations, see LoopBegin
+}
+
+type lookupTable struct {
+	name    string
+	entries map[string]struct{} // key: entry.String()
+}
+
+func (e *engine) MarkOutput(v Variable, name string) {
+	if _, ok := e.outputs[name]; ok {
+		panic(fmt.Sprintf("duplicate output %q", name))
+	}
+	b := e.toBigInt(v)
+	e.outputs[name] = &b
+}
+
+// SetOutput asserts that output is equal to v, if it is assigned in the witness; an unassigned
+// output can't be used by the circuit
+func (e *engine) SetOutput(output, v Variable) {
+	b := e.toBigInt(v)
+	if output.WitnessValue == nil {
+		return
+	}
+	e.AssertIsEqual(output, b)
+}
+
+func (e *engine) Add(i1, i2 interface{}, in ...interface{}) Variable {
+	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
+	b1.Add(&b1, &b2)
+	for i := 0; i < len(in); i++ {
+		bn := e.toBigInt(in[i])
+		b1.Add(&b1, &bn)
+	}
+	b1.Mod(&b1, e.modulus())
+	return Value(b1)
+}
+
+func (e *engine) Sub(i1, i2 interface{}, in ...interface{}) Variable {
+	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
+	b1.Sub(&b1, &b2)
+	for i := 0; i < len(in); i++ {
+		bn := e.toBigInt(in[i])
+		b1.Sub(&b1, &bn)
+	}
+	b1.Mod(&b1, e.modulus())
+	return Value(b1)
+}
+
+func (e *engine) Neg(i1 interface{}) Variable {
+	b1 := e.toBigInt(i1)
+	b1.Neg(&b1)
+	b1.Mod(&b1, e.modulus())
+	return Value(b1)
+}
+
+func (e *engine) Mul(i1, i2 interface{}, in ...interface{}) Variable {
+	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
+	b1.Mul(&b1, &b2).Mod(&b1, e.modulus())
+	for i := 0; i < len(in); i++ {
+		bn := e.toBigInt(in[i])
+		b1.Mul(&b1, &bn).Mod(&b1, e.modulus())
+	}
+	return Value(b1)
+}
+
+func (e *engine) Sum(vs ...Variable) Variable {
+	if len(vs) == 1 {
+		return vs[0]
+	}
+	var res big.Int
+	for i := 0; i < len(vs); i++ {
+		b := e.toBigInt(vs[i])
+		res.Add(&res, &b)
+	}
+	res.Mod(&res, e.modulus())
+	return Value(res)
+}
+
+func (e *engine) Product(vs ...Variable) Variable {
+	if len(vs) == 1 {
+		return vs[0]
+	}
+	res := big.NewInt(1)
+	for i := 0; i < len(vs); i++ {
+		b := e.toBigInt(vs[i])
+		res.Mul(res, &b).Mod(res, e.modulus())
+	}
+	return Value(res)
+}
+
+func (e *engine) Div(i1, i2 interface{}) Variable {
+	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
+	if b2.ModInverse(&b2, e.modulus()) == nil {
+		panic("no inverse")
+	}
+	b2.Mul(&b1, &b2).Mod(&b2, e.modulus())
+	return Value(b2)
+}
+
+func (e *engine) DivUnchecked(i1, i2 interface{}) Variable {
+	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
+	if b1.IsUint64() && b2.IsUint64() && b1.Uint64() == 0 && b2.Uint64() == 0 {
+		return Value(0)
+	}
+	if b2.ModInverse(&b2, e.modulus()) == nil {
+		panic("no inverse")
+	}
+	b2.Mul(&b1, &b2).Mod(&b2, e.modulus())
+	return Value(b2)
+}
+
+func (e *engine) Inverse(i1 interface{}) Variable {
+	b1 := e.toBigInt(i1)
+	if b1.ModInverse(&b1, e.modulus()) == nil {
+		panic("no inverse")
+	}
+	return Value(b1)
+}
+
+func (e *engine) InverseOrZero(i1 interface{}) Variable {
+	b1 := e.toBigInt(i1)
+	if b1.Sign() == 0 {
+		return Value(0)
+	}
+	return e.Inverse(b1)
+}
+
+func (e *engine) NewLinearCombination() LinearCombination {
+	return &engineLinearCombination{e: e}
+}
+
+type engineLinearCombination struct {
+	e   *engine
+	sum big.Int
+}
+
+func (lc *engineLinearCombination) Add(term interface{}) {
+	b := lc.e.toBigInt(term)
+	lc.sum.Add(&lc.sum, &b).Mod(&lc.sum, lc.e.modulus())
+}
+
+func (lc *engineLinearCombination) Commit() Variable {
+	return Value(new(big.Int).Set(&lc.sum))
+}
+
+func (e *engine) BatchInvert(vs []Variable) []Variable {
+	res := make([]Variable, len(vs))
+	for i := 0; i < len(vs); i++ {
+		res[i] = e.Inverse(vs[i])
+	}
+	return res
+}
+
+func (e *engine) BatchInvertOrZero(vs []Variable) []Variable {
+	res := make([]Variable, len(vs))
+	for i := 0; i < len(vs); i++ {
+		res[i] = e.InverseOrZero(vs[i])
+	}
+	return res
+}
+
+func (e *engine) ToBinary(i1 interface{}, n ...int) []Variable {
+	nbBits := e.bitLen()
+	if len(n) == 1 {
+		nbBits = n[0]
+		if nbBits < 0 {
+			panic("invalid n")
+		}
+	}
+
+	b1 := e.toBigInt(i1)
+
+	if b1.BitLen() > nbBits {
+		panic(fmt.Sprintf("[ToBinary] decomposing %s (bitLen == %d) with %d bits", b1.String(), b1.BitLen(), nbBits))
+	}
+
+	r := make([]Variable, nbBits)
+	for i := 0; i < len(r); i++ {
+		r[i] = Value(b1.Bit(i))
+	}
+
+	value := e.toBigInt(e.FromBinaryMod(r...))
+	if value.Cmp(&b1) != 0 {
+		// this is a sanitfy check, it should never happen
+		panic(fmt.Sprintf("[ToBinary] decomposing %s (bitLen == %d) with %d bits reconstructs into %s", b1.String(), b1.BitLen(), nbBits, value.String()))
+	}
+	return r
+}
+
+func (e *engine) FromBinary(v ...Variable) Variable {
+	if len(v) > e.bitLen() {
+		panic(fmt.Sprintf("[fromBinary] %d bits exceed the field bit length (%d)", len(v), e.bitLen()))
+	}
+	return e.FromBinaryMod(v...)
+}
+
+func (e *engine) FromBinaryMod(v ...Variable) Variable {
+	r := e.fromBinary(v)
+	r.Mod(&r, e.modulus())
+
+	return Value(r)
+}
+
+func (e *engine) FromBinaryChecked(v ...Variable) Variable {
+	r := e.fromBinary(v)
+	if r.Cmp(e.modulus()) >= 0 {
+		panic(fmt.Sprintf("[fromBinaryChecked] %s is not less than the modulus", r.String()))
+	}
+
+	return Value(r)
+}
+
+// fromBinary returns Σ (2**i * bits[i]), not reduced
+func (e *engine) fromBinary(v []Variable) big.Int {
+	bits := make([]big.Int, len(v))
+	for i := 0; i < len(v); i++ {
+		bits[i] = e.toBigInt(v[i])
+		e.mustBeBoolean(&bits[i])
+	}
+
+	// Σ (2**i * bits[i]) == r
+	var c, r big.Int
+	c.SetUint64(1)
+
+	for i := 0; i < len(bits); i++ {
+		bits[i].Mul(&bits[i], &c)
+		r.Add(&r, &bits[i])
+		c.Lsh(&c, 1)
+	}
+
+	return r
+}
+
+func (e *engine) Xor(i1, i2 Variable) Variable {
+	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
+	e.mustBeBoolean(&b1)
+	e.mustBeBoolean(&b2)
+	b1.Xor(&b1, &b2)
+	return Value(b1)
+}
+
+func (e *engine) Or(i1, i2 Variable) Variable {
+	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
+	e.mustBeBoolean(&b1)
+	e.mustBeBoolean(&b2)
+	b1.Or(&b1, &b2)
+	return Value(b1)
+}
+
+func (e *engine) And(i1, i2 Variable) Variable {
+	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
+	e.mustBeBoolean(&b1)
+	e.mustBeBoolean(&b2)
+	b1.And(&b1, &b2)
+	return Value(b1)
+}
+
+// Select if b is true, yields i1 else yields i2
+func (e *engine) Select(b interface{}, i1, i2 interface{}) Variable {
+	b1 := e.toBigInt(b)
+	e.mustBeBoolean(&b1)
+
+	if b1.Uint64() == 1 {
+		return Value(e.toBigInt(i1))
+	}
+	return Value(e.toBigInt(i2))
+}
+
+// IsZero returns 1 if a is zero, 0 otherwise
+func (e *engine) IsZero(i1 interface{}) Variable {
+	b1 := e.toBigInt(i1)
+
+	if b1.IsUint64() && b1.Uint64() == 0 {
+		return Value(1)
+	}
+
+	return Value(0)
+}
+
+func (e *engine) Constant(input interface{}) Variable {
+	return Value(e.toBigInt(input))
+}
+
+func (e *engine) AssertIsEqual(i1, i2 interface{}) {
+	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
+	if b1.Cmp(&b2) != 0 {
+		panic(fmt.Sprintf("[assertIsEqual] %s == %s", b1.String(), b2.String()))
+	}
+}
+
+func (e *engine) AssertIsDifferent(i1, i2 interface{}) {
+	b1, b2 := e.toBigInt(i1), e.toBigInt(i2)
+	if b1.Cmp(&b2) == 0 {
+		panic(fmt.Sprintf("[assertIsDifferent] %s != %s", b1.String(), b2.String()))
+	}
+}
+
+func (e *engine) AssertIsBoolean(i1 interface{}) {
+	b1 := e.toBigInt(i1)
+	e.mustBeBoolean(&b1)
+}
+
+func (e *engine) AssertIsLessOrEqual(v Variable, bound interface{}) {
+
+	var bValue big.Int
+	if v, ok := bound.(Variable); ok {
+		bValue = FromInterface(v.WitnessValue)
+		bValue.Mod(&bValue, e.modulus())
+	} else {
+		// note: here we don't do a mod reduce on the bound, a bound >= q-1 holds for any value
+		bValue = FromInterface(bound)
+	}
+
+	if bValue.Sign() == -1 {
+		panic(fmt.Sprintf("[assertIsLessOrEqual] bound (%s) must be positive", bValue.String()))
+	}
+
+	b1 := e.toBigInt(v)
+	if b1.Cmp(&bValue) == 1 {
+		panic(fmt.Sprintf("[assertIsLessOrEqual] %s > %s", b1.String(), bValue.String()))
+	}
+}
+
+func (e *engine) AssertIsLessOrEqualBounded(v Variable, bound interface{}, nbBits int) {
+	if nbBits < 1 || nbBits > e.bitLen()-2 {
+		panic(fmt.Sprintf("[assertIsLessOrEqualBounded] nbBits must be in [1, %d]", e.bitLen()-2))
+	}
+
+	b1, b2 := e.toBigInt(v), e.toBigInt(bound)
+	b1.Mod(&b1, e.modulus())
+	b2.Mod(&b2, e.modulus())
+	if b1.BitLen() > nbBits || b2.BitLen() > nbBits {
+		panic(fmt.Sprintf("[assertIsLessOrEqualBounded] %s or %s doesn't fit on %d bits", b1.String(), b2.String(), nbBits))
+	}
+	if b1.Cmp(&b2) == 1 {
+		panic(fmt.Sprintf("[assertIsLessOrEqualBounded] %s > %s", b1.String(), b2.String()))
+	}
+}
+
+func (e *engine) RangeCheck(i1 interface{}, nbBits int) []Variable {
+	if nbBits < 1 || nbBits > e.bitLen() {
+		panic(fmt.Sprintf("[rangeCheck] nbBits must be in [1, %d]", e.bitLen()))
+	}
+	b1 := e.toBigInt(i1)
+	if b1.BitLen() > nbBits {
+		panic(fmt.Sprintf("[rangeCheck] %s doesn't fit on %d bits", b1.String(), nbBits))
+	}
+	return e.ToBinary(b1, nbBits)
+}
+
+func (e *engine) Exp(base, exponent Variable, nbBits int) Variable {
+	if nbBits < 1 || nbBits >= e.bitLen() {
+		panic(fmt.Sprintf("[exp] nbBits must be in [1, %d]", e.bitLen()-1))
+	}
+	b1 := e.toBigInt(exponent)
+	if b1.BitLen() > nbBits {
+		panic(fmt.Sprintf("[exp] %s doesn't fit on %d bits", b1.String(), nbBits))
+	}
+	return e.ExpConstant(base, &b1)
+}
+
+func (e *engine) ExpConstant(base Variable, exponent *big.Int) Variable {
+	if exponent.Sign() == -1 {
+		panic(fmt.Sprintf("[expConstant] exponent (%s) must not be negative", exponent.String()))
+	}
+	b1 := e.toBigInt(base)
+	var res big.Int
+	res.Exp(&b1, exponent, e.modulus())
+	return Value(res)
+}
+
+func (e *engine) AssertIsMember(v Variable, set []big.Int) {
+	if len(set) == 0 {
+		panic("[assertIsMember] empty set")
+	}
+	b := e.toBigInt(v)
+	b.Mod(&b, e.modulus())
+	if !e.isMember(&b, set) {
+		panic(fmt.Sprintf("[assertIsMember] %s is not in the set", b.String()))
+	}
+}
+
+func (e *engine) AssertIsNotMember(v Variable, set []big.Int) {
+	b := e.toBigInt(v)
+	b.Mod(&b, e.modulus())
+	if e.isMember(&b, set) {
+		panic(fmt.Sprintf("[assertIsNotMember] %s is in the set", b.String()))
+	}
+}
+
+// isMember returns true if b, reduced, is an element of set modulo the scalar field
+func (e *engine) isMember(b *big.Int, set []big.Int) bool {
+	var s big.Int
+	for i := range set {
+		s.Mod(&set[i], e.modulus())
+		if s.Cmp(b) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *engine) AssertIsPermutation(a, b []Variable, opts ...func(opt *PermutationOption) error) {
+	if len(a) != len(b) {
+		panic(fmt.Sprintf("[assertIsPermutation] len(a) = %d and len(b) = %d differ", len(a), len(b)))
+	}
+	_ = newPermutationOption(opts)
+
+	count := make(map[string]int, len(a))
+	for i := 0; i < len(a); i++ {
+		v := e.toBigInt(a[i])
+		v.Mod(&v, e.modulus())
+		count[v.String()]++
+	}
+	for i := 0; i < len(b); i++ {
+		v := e.toBigInt(b[i])
+		v.Mod(&v, e.modulus())
+		if count[v.String()] == 0 {
+			panic(fmt.Sprintf("[assertIsPermutation] b is not a permutation of a: %s", v.String()))
+		}
+		count[v.String()]--
+	}
+}
+
+func (e *engine) AddTable(name string, entries []big.Int) TableID {
+	if len(entries) == 0 {
+		panic("lookup table " + name + " is empty")
+	}
+	t := lookupTable{name: name, entries: make(map[string]struct{}, len(entries))}
+	var b big.Int
+	for i := 0; i < len(entries); i++ {
+		b.Mod(&entries[i], e.modulus())
+		t.entries[b.String()] = struct{}{}
+	}
+	e.tables = append(e.tables, t)
+	return TableID(len(e.tables) - 1)
+}
+
+func (e *engine) Lookup(table TableID, v Variable) {
+	if int(table) < 0 || int(table) >= len(e.tables) {
+		panic(fmt.Sprintf("lookup table %d doesn't exist", table))
+	}
+	b := e.toBigInt(v)
+	if _, ok := e.tables[table].entries[b.String()]; !ok {
+		panic(fmt.Sprintf("[lookup] %s is not in %s", b.String(), e.tables[table].name))
+	}
+}
+
+func (e *engine) LoopBegin(label string, iteration int) {
+	e.loopDepth++
+}
+
+func (e *engine) LoopEnd() {
+	if e.loopDepth == 0 {
+		panic("LoopEnd without LoopBegin")
+	}
+	e.loopDepth--
+}
+
+func (e *engine) Tag(label string) func() {
+	checkTagLabel(label)
+	return func() {}
+}
+
+func (e *engine) Println(a ...interface{}) {
+	var sbb strings.Builder
+	sbb.WriteString("(simulator) ")
+
+	// prefix log line with file.go:line
+	if _, file, line, ok := runtime.Caller(1); ok {
+		sbb.WriteString(filepath.Base(file))
+		sbb.WriteByte(':')
+		sbb.WriteString(strconv.Itoa(line))
+		sbb.WriteByte(' ')
+	}
+
+	for i := 0; i < len(a); i++ {
+		if v, ok := a[i].(Variable); ok {
+			b := e.toBigInt(v)
+			sbb.WriteString(b.String())
+		} else {
+			sbb.WriteString(fmt.Sprint(a[i]))
+		}
+	}
+	fmt.Println(sbb.String())
+}
+
+func (e *engine) NewHint(f hint.Function, inputs ...interface{}) Variable {
+	if static, _ := splitStatic(inputs); len(static) != 0 {
+		panic(fmt.Sprintf("NewHint: hint %s doesn't accept static parameters, see hint.FunctionWithStatic", hint.Name(f)))
+	}
+	in := make([]*big.Int, len(inputs))
+
+	for i := 0; i < len(inputs); i++ {
+		v := e.toBigInt(inputs[i])
+		in[i] = &v
+	}
+
+	var result big.Int
+	err := f(e.curveID, in, &result)
+
+	if err != nil {
+		panic("NewHint: " + err.Error())
+	}
+
+	return Value(result)
+}
+
+func (e *engine) NewAnnotatedHint(f hint.AnnotatedFunction, inputs ...interface{}) []Variable {
+	static, inputs := splitStatic(inputs)
+	if err := hint.CheckStatic(f, len(static)); err != nil {
+		panic("NewAnnotatedHint: " + err.Error())
+	}
+	if nIn := f.NbInputs(); nIn >= 0 && nIn != len(inputs) {
+		panic(fmt.Sprintf("NewAnnotatedHint: hint %s expects %d inputs, got %d", f, nIn, len(inputs)))
+	}
+	in := make([]*big.Int, len(inputs))
+
+	for i := 0; i < len(inputs); i++ {
+		v := e.toBigInt(inputs[i])
+		in[i] = &v
+	}
+
+	nOut := f.NbOutputs()
+	if nOut == -1 {
+		nOut = len(inputs)
+	}
+	out := make([]*big.Int, nOut)
+	for i := 0; i < len(out); i++ {
+		out[i] = new(big.Int)
+	}
+
+	if err := hint.Invoke(f, e.curveID, static, in, out); err != nil {
+		panic("NewAnnotatedHint: " + err.Error())
+	}
+
+	res := make([]Variable, len(out))
+	for i := 0; i < len(out); i++ {
+		res[i] = Value(out[i].Mod(out[i], e.modulus()))
+	}
+	return res
+}
+
+func (e *engine) toBigInt(i1 interface{}) big.Int {
+	if v1, ok := i1.(Variable); ok {
+		return v1.GetWitnessValue(e.curveID)
+	}
+	// constants are reduced like the assigned values, such that 1 and r+1 are equal
+	b := FromInterface(i1)
+	b.Mod(&b, e.modulus())
+	return b
+}
+
+// bitLen returns the number of bits needed to represent a fr.Element
+func (e *engine) bitLen() int {
+	return e.curveID.Info().Fr.Bits
+}
+
+func (e *engine) mustBeBoolean(b *big.Int) {
+	if !b.IsUint64() || !(b.Uint64() == 0 || b.Uint64() == 1) {
+		panic(fmt.Sprintf("[assertIsBoolean] %s", b.String()))
+	}
+}
+
+func (e *engine) modulus() *big.Int {
+	return e.curveID.Info().Fr.Modulus()
+}
@@ -0,0 +1,198 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	"github.com/consensys/gnark/internal/backend/circuits"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/stretchr/testify/require"
+)
+
+type outputCircuit struct {
+	X      []frontend.Variable
+	Sum    frontend.Variable `gnark:",output"`
+	unique bool
+}
+
+func (circuit *outputCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	circuit.Sum = api.Add(circuit.X[0], circuit.X[1], circuit.X[2])
+	api.MarkOutput(api.Mul(circuit.X[0], circuit.X[1], circuit.X[2]), "product")
+	if !circuit.unique {
+		api.MarkOutput(circuit.X[0], "product")
+	}
+	return nil
+}
+
+func TestSimulator(t *testing.T) {
+	assert := require.New(t)
+
+	circuit := &outputCircuit{X: make([]frontend.Variable, 3), unique: true}
+	witness := &outputCircuit{X: []frontend.Variable{frontend.Value(2), frontend.Value(3), frontend.Value(1)}}
+
+	sim := frontend.NewSimulator(ecc.BN254)
+	outputs, err := sim.Run(circuit, witness)
+	assert.NoError(err)
+	assert.Equal(map[string]*big.Int{"Sum": big.NewInt(6), "product": big.NewInt(6)}, outputs)
+
+	// the slices shared by the circuit and its clone are restored
+	assert.Equal(make([]frontend.Variable, 3), circuit.X)
+	assert.Equal(frontend.Variable{}, circuit.Sum)
+
+	// the outputs are not part of the witness
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, circuit)
+	assert.NoError(err)
+	_, nbSecret, _ := ccs.GetNbVariables()
+	assert.Equal(3, nbSecret)
+
+	// missing assignment
+	_, err = sim.Run(circuit, &outputCircuit{X: []frontend.Variable{frontend.Value(2), frontend.Value(3), {}}})
+	assert.Error(err)
+
+	// duplicate output name
+	circuit.unique = false
+	_, err = sim.Run(circuit, witness)
+	assert.Error(err)
+	_, err = frontend.Compile(ecc.BN254, backend.GROTH16, circuit)
+	assert.Error(err)
+}
+
+type unsetOutputCircuit struct {
+	X   frontend.Variable
+	Out frontend.Variable `gnark:",output"`
+}
+
+func (circuit *unsetOutputCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsBoolean(circuit.X)
+	return nil
+}
+
+func TestSimulatorUnsetOutput(t *testing.T) {
+	assert := require.New(t)
+
+	_, err := frontend.NewSimulator(ecc.BN254).Run(&unsetOutputCircuit{}, &unsetOutputCircuit{X: frontend.Value(1)})
+	assert.Error(err)
+	_, err = frontend.Compile(ecc.BN254, backend.GROTH16, &unsetOutputCircuit{})
+	assert.Error(err)
+}
+
+// TestSimulatorSolvers checks that the simulator and the solvers of the compiled constraint systems agree on
+// the circuits of the registry, outputs included
+func TestSimulatorSolvers(t *testing.T) {
+	assert := require.New(t)
+
+	keys := make([]string, 0, len(circuits.Circuits))
+	for k := range circuits.Circuits {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	sim := frontend.NewSimulator(ecc.BN254)
+	nbOutputs := 0
+
+	for _, k := range keys {
+		tData := circuits.Circuits[k]
+		if tData.ExpectedCompileError != "" {
+			continue
+		}
+		opt, err := backend.NewProverOption(backend.WithHints(tData.HintFunctions...))
+		assert.NoError(err)
+
+		r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, tData.Circuit)
+		assert.NoError(err, k)
+		sparseR1CS, err := frontend.Compile(ecc.BN254, backend.PLONK, tData.Circuit)
+		assert.NoError(err, k)
+
+		for _, w := range tData.ValidWitnesses {
+			expected, err := sim.Run(tData.Circuit, w)
+			assert.NoError(err, k)
+			nbOutputs += len(expected)
+
+			var witness witness_bn254.Witness
+			assert.NoError(witness.FromFullAssignment(w), k)
+
+			outputs, err := solveR1CS(r1cs.(*cs_bn254.R1CS), witness, opt)
+			assert.NoError(err, k)
+			assert.Equal(expected, outputs, "%s: groth16 outputs", k)
+
+			outputs, err = solveSparseR1CS(sparseR1CS.(*cs_bn254.SparseR1CS), witness, opt)
+			assert.NoError(err, k)
+			assert.Equal(expected, outputs, "%s: plonk outputs", k)
+		}
+
+		for _, w := range tData.InvalidWitnesses {
+			_, err := sim.Run(tData.Circuit, w)
+			assert.Error(err, k)
+
+			var witness witness_bn254.Witness
+			assert.NoError(witness.FromFullAssignment(w), k)
+
+			_, err = solveR1CS(r1cs.(*cs_bn254.R1CS), witness, opt)
+			assert.Error(err, k)
+			_, err = solveSparseR1CS(sparseR1CS.(*cs_bn254.SparseR1CS), witness, opt)
+			assert.Error(err, k)
+		}
+	}
+
+	assert.NotZero(nbOutputs, "the registry should have circuits with outputs")
+}
+
+func solveR1CS(r1cs *cs_bn254.R1CS, witness witness_bn254.Witness, opt backend.ProverOption) (map[string]*big.Int, error) {
+	a := make([]fr.Element, r1cs.GetNbConstraints())
+	b := make([]fr.Element, r1cs.GetNbConstraints())
+	c := make([]fr.Element, r1cs.GetNbConstraints())
+	values, err := r1cs.Solve(witness, a, b, c, opt)
+	if err != nil {
+		return nil, err
+	}
+	return evalOutputs(r1cs.Outputs, r1cs.Coefficients, values), nil
+}
+
+func solveSparseR1CS(sparseR1CS *cs_bn254.SparseR1CS, witness witness_bn254.Witness, opt backend.ProverOption) (map[string]*big.Int, error) {
+	values, err := sparseR1CS.Solve(witness, opt)
+	if err != nil {
+		return nil, err
+	}
+	return evalOutputs(sparseR1CS.Outputs, sparseR1CS.Coefficients, values), nil
+}
+
+// evalOutputs resolves the outputs of a constraint system from the values of its wires
+func evalOutputs(outputs []compiled.Output, coefficients, values []fr.Element) map[string]*big.Int {
+	res := make(map[string]*big.Int, len(outputs))
+	for _, o := range outputs {
+		var v, t fr.Element
+		for _, term := range o.Value {
+			cID, vID, visibility := term.Unpack()
+			t = coefficients[cID]
+			if visibility != compiled.Virtual {
+				t.Mul(&t, &values[vID])
+			}
+			v.Add(&v, &t)
+		}
+		res[o.Name] = v.ToBigIntRegular(new(big.Int))
+	}
+	return res
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/consensys/gnark/internal/backend/compiled"
+)
+
+// Tag records the beginning of a scope, and returns the function recording its end, see API.Tag
+func (cs *constraintSystem) Tag(label string) func() {
+	checkTagLabel(label)
+	if cs.tags == nil {
+		cs.tags = &compiled.Tags{}
+		cs.tagIDs = make(map[string]int)
+	}
+	parent := -1
+	if len(cs.tagStack) > 0 {
+		parent = cs.tagStack[len(cs.tagStack)-1]
+		label = cs.tags.Labels[cs.tags.Scopes[parent].Label] + "/" + label
+	}
+	id, ok := cs.tagIDs[label]
+	if !ok {
+		id = len(cs.tags.Labels)
+		cs.tagIDs[label] = id
+		cs.tags.Labels = append(cs.tags.Labels, label)
+	}
+	s := len(cs.tags.Scopes)
+	cs.tagStack = append(cs.tagStack, s)
+	cs.tags.Scopes = append(cs.tags.Scopes, compiled.TagScope{
+		Label:  id,
+		Begin:  len(cs.constraints),
+		End:    -1,
+		Parent: parent,
+	})
+
+	return func() {
+		if len(cs.tagStack) == 0 || cs.tagStack[len(cs.tagStack)-1] != s {
+			panic(fmt.Sprintf("tag %s ended twice, or before the tags nested in it", label))
+		}
+		cs.tagStack = cs.tagStack[:len(cs.tagStack)-1]
+		cs.tags.Scopes[s].End = len(cs.constraints)
+	}
+}
+
+// checkTags returns an error if a scope isn't ended, once Define returns
+func (cs *constraintSystem) checkTags() error {
+	if len(cs.tagStack) != 0 {
+		s := cs.tagStack[len(cs.tagStack)-1]
+		return fmt.Errorf("tag %s not ended", cs.tags.Labels[cs.tags.Scopes[s].Label])
+	}
+	return nil
+}
+
+// checkTagLabel panics if label can't be the label of a tag, see API.Tag
+func checkTagLabel(label string) {
+	if label == "" || strings.Contains(label, "/") {
+		panic(fmt.Sprintf("invalid tag label %q: it can't be empty or contain \"/\"", label))
+	}
+}
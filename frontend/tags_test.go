@@ -0,0 +1,179 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frontend_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+// tagCircuit calls two tagged gadgets, a chain of squares with a nested tag and a decomposition in bits
+type tagCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *tagCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsDifferent(circuit.X, 0)
+	api.AssertIsEqual(squares(api, circuit.X), circuit.Y)
+	bits(api, circuit.X)
+	return nil
+}
+
+func squares(api frontend.API, x frontend.Variable) frontend.Variable {
+	defer api.Tag("squares")()
+	for i := 0; i < 4; i++ {
+		x = api.Mul(x, x)
+	}
+	end := api.Tag("last")
+	x = api.Add(api.Mul(x, x), 1)
+	end()
+	return x
+}
+
+func bits(api frontend.API, x frontend.Variable) {
+	defer api.Tag("bits")()
+	api.ToBinary(x, 8)
+}
+
+func TestTags(t *testing.T) {
+	assert := require.New(t)
+
+	for _, b := range backend.Implemented() {
+		ccs, err := frontend.Compile(ecc.BN254, b, &tagCircuit{})
+		assert.NoError(err)
+
+		perTag := ccs.ConstraintsPerTag()
+		assert.Len(perTag, 4, b)
+		sum := 0
+		for label, n := range perTag {
+			assert.NotZero(n, "%s: %s", b, label)
+			sum += n
+		}
+		assert.Equal(ccs.GetNbConstraints(), sum, b)
+		assert.Contains(perTag, "squares/last")
+		assert.Contains(perTag, "") // AssertIsDifferent and AssertIsEqual
+		assert.True(perTag["squares"] >= 4, b)
+		assert.True(perTag["bits"] >= 8, b)
+
+		// the tags are dropped by the serialization
+		var buf bytes.Buffer
+		_, err = ccs.WriteTo(&buf)
+		assert.NoError(err)
+		_, err = newCompiled(b).ReadFrom(&buf)
+		assert.NoError(err)
+		withoutTags := buf.Len()
+
+		// unless asked to
+		serialized, err := frontend.Compile(ecc.BN254, b, &tagCircuit{}, frontend.WithSerializedTags())
+		assert.NoError(err)
+		buf.Reset()
+		_, err = serialized.WriteTo(&buf)
+		assert.NoError(err)
+		assert.True(buf.Len() > withoutTags, b)
+		read := newCompiled(b)
+		_, err = read.ReadFrom(&buf)
+		assert.NoError(err)
+		assert.Equal(perTag, read.ConstraintsPerTag())
+
+		// the profile annotates the constraints
+		buf.Reset()
+		assert.NoError(ccs.ToHTML(&buf))
+		assert.Contains(buf.String(), "squares/last")
+		assert.Contains(buf.String(), "tagged bits")
+	}
+
+	// without the tags in the serialization
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &tagCircuit{})
+	assert.NoError(err)
+	var buf bytes.Buffer
+	_, err = ccs.WriteTo(&buf)
+	assert.NoError(err)
+	read := newCompiled(backend.PLONK)
+	_, err = read.ReadFrom(&buf)
+	assert.NoError(err)
+	assert.Nil(read.ConstraintsPerTag())
+
+	// the estimate counts the PLONK gates per tag
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &tagCircuit{})
+	assert.NoError(err)
+	stats, err := frontend.EstimateSparseR1CS(r1cs)
+	assert.NoError(err)
+	assert.Equal(ccs.GetNbConstraints(), stats.NbConstraints)
+	assert.Equal(ccs.ConstraintsPerTag(), stats.ByTag)
+
+	// the tags don't change the solving
+	var witness tagCircuit
+	witness.X.Assign(1)
+	witness.Y.Assign(2)
+	test.NewAssert(t).ProverSucceeded(&tagCircuit{}, &witness, test.WithCurves(ecc.BN254))
+}
+
+func newCompiled(b backend.ID) frontend.CompiledConstraintSystem {
+	if b == backend.GROTH16 {
+		return &cs_bn254.R1CS{}
+	}
+	return &cs_bn254.SparseR1CS{}
+}
+
+// misusedTagCircuit ends its tag as told
+type misusedTagCircuit struct {
+	X     frontend.Variable
+	label string
+	end   func(outer, inner func())
+}
+
+func (circuit *misusedTagCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	outer := api.Tag(circuit.label)
+	inner := api.Tag("inner")
+	api.AssertIsEqual(circuit.X, 1)
+	circuit.end(outer, inner)
+	return nil
+}
+
+func TestMisusedTag(t *testing.T) {
+	assert := require.New(t)
+	compile := func(label string, end func(outer, inner func())) error {
+		_, err := frontend.Compile(ecc.BN254, backend.GROTH16, &misusedTagCircuit{label: label, end: end})
+		return err
+	}
+
+	assert.NoError(compile("outer", func(outer, inner func()) { inner(); outer() }))
+
+	err := compile("outer", func(outer, inner func()) { inner() })
+	assert.EqualError(err, "tag outer not ended")
+	err = compile("outer", func(outer, inner func()) {})
+	assert.EqualError(err, "tag outer/inner not ended")
+
+	err = compile("outer", func(outer, inner func()) { outer(); inner() })
+	assert.EqualError(err, "tag outer ended twice, or before the tags nested in it")
+	err = compile("outer", func(outer, inner func()) { inner(); inner(); outer() })
+	assert.EqualError(err, "tag outer/inner ended twice, or before the tags nested in it")
+
+	for _, label := range []string{"", "a/b"} {
+		err = compile(label, func(outer, inner func()) { inner(); outer() })
+		assert.Error(err)
+		assert.Contains(err.Error(), "invalid tag label")
+	}
+}
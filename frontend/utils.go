@@ -26,7 +26,8 @@ type toBigIntInterface interface {
 // FromInterface converts an interface to a big.Int element
 // interface must implement ToBigIntRegular(res *big.Int) *big.Int
 // (which is the case for field generated by goff)
-// or be uint64, int, string, []byte or big.Int
+// or be an integer type (int, int8..int64, uint, uint8..uint64), bool (0 or 1), base 10 string,
+// []byte or big.Int
 // it panics if the input is invalid
 func FromInterface(i1 interface{}) big.Int {
 	var val big.Int
@@ -40,8 +41,26 @@ func FromInterface(i1 interface{}) big.Int {
 		val.SetUint64(c1)
 	case uint:
 		val.SetUint64(uint64(c1))
+	case uint32:
+		val.SetUint64(uint64(c1))
+	case uint16:
+		val.SetUint64(uint64(c1))
+	case uint8:
+		val.SetUint64(uint64(c1))
 	case int:
 		val.SetInt64(int64(c1))
+	case int64:
+		val.SetInt64(c1)
+	case int32:
+		val.SetInt64(int64(c1))
+	case int16:
+		val.SetInt64(int64(c1))
+	case int8:
+		val.SetInt64(int64(c1))
+	case bool:
+		if c1 {
+			val.SetUint64(1)
+		}
 	case string:
 		if _, ok := val.SetString(c1, 10); !ok {
 			panic("unable to set big.Int from base10 string")
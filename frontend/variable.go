@@ -25,6 +25,7 @@ import (
 	fr_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
 	fr_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
 	fr_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	fr_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
 	fr_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr"
 )
 
@@ -65,8 +66,7 @@ func (v *Variable) isConstant() bool {
 	if len(v.linExp) != 1 {
 		return false
 	}
-	_, vID, visibility := v.linExp[0].Unpack()
-	return vID == 0 && visibility == compiled.Public
+	return v.linExp[0].IsConstant()
 }
 
 func (v *Variable) constantValue(cs *constraintSystem) *big.Int {
@@ -112,6 +112,10 @@ func (v *Variable) GetWitnessValue(curveID ecc.ID) big.Int {
 		var e fr_bw6761.Element
 		e.SetBigInt(&b)
 		e.ToBigIntRegular(&b)
+	case ecc.BW6_633:
+		var e fr_bw6633.Element
+		e.SetBigInt(&b)
+		e.ToBigIntRegular(&b)
 	default:
 		panic("curve not implemented")
 	}
@@ -120,10 +124,20 @@ func (v *Variable) GetWitnessValue(curveID ecc.ID) big.Int {
 
 // Assign v = value . This must called when using a Circuit as a witness data structure
 //
-// Prefer the use of variable.WitnessValue = value
+// Prefer the use of variable.WitnessValue = value. A bool value is assigned as 0 or 1
 func (v *Variable) Assign(value interface{}) {
 	if v.WitnessValue != nil {
 		panic("variable already assigned")
 	}
+	if b, ok := value.(bool); ok {
+		value = boolToUint64(b)
+	}
 	v.WitnessValue = value
 }
+
+func boolToUint64(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
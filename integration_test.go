@@ -20,7 +20,9 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/internal/backend/circuits"
 	"github.com/consensys/gnark/test"
 )
@@ -38,6 +40,17 @@ func TestIntegrationAPI(t *testing.T) {
 	for _, k := range keys {
 		tData := circuits.Circuits[k]
 		t.Log(k)
+		if tData.ExpectedCompileError != "" {
+			assert.CompilationFailed(tData.Circuit, tData.ExpectedCompileError, test.WithCompileOpts(tData.CompileOptions...))
+			if tData.ExpectedCompileErrorIs != nil {
+				for _, b := range []backend.ID{backend.GROTH16, backend.PLONK} {
+					_, err := frontend.Compile(ecc.BN254, b, tData.Circuit, tData.CompileOptions...)
+					assert.ErrorIs(err, tData.ExpectedCompileErrorIs, k)
+				}
+			}
+			continue
+		}
+
 		for _, w := range tData.ValidWitnesses {
 			assert.ProverSucceeded(tData.Circuit, w, test.WithProverOpts(backend.WithHints(tData.HintFunctions...)))
 		}
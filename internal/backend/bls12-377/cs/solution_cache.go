@@ -0,0 +1,116 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package cs
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/internal/backend/compiled"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
+)
+
+// incrementalSolver holds what the solver needs to reuse the snapshots of a backend.SolutionCache.
+// It is computed once per R1CS, on the first solve with backend.WithSolutionCache.
+type incrementalSolver struct {
+	schedule *compiled.SolveSchedule
+	graph    *compiled.DependencyGraph
+	digest   []byte // see R1CS.digest
+}
+
+// r1csSnapshot is the content of the snapshots of a backend.SolutionCache stored by the R1CS solver
+type r1csSnapshot struct {
+	values  []fr.Element // all the wires, unpacked
+	a, b, c []fr.Element
+}
+
+// incrementalSolver returns the schedule, dependency graph and digest of the R1CS, computing them
+// on the first call. Concurrent first calls may compute them more than once.
+func (cs *R1CS) incrementalSolver() (*incrementalSolver, error) {
+	if inc, ok := cs.incremental.Load().(*incrementalSolver); ok {
+		return inc, nil
+	}
+	schedule, err := cs.NewSolveSchedule()
+	if err != nil {
+		return nil, err
+	}
+	digest, err := cs.digest()
+	if err != nil {
+		return nil, err
+	}
+	inc := &incrementalSolver{
+		schedule: schedule,
+		graph:    cs.NewDependencyGraph(schedule),
+		digest:   digest,
+	}
+	cs.incremental.Store(inc)
+	return inc, nil
+}
+
+// digest returns the SHA256 hash of the curve ID and the serialized R1CS
+func (cs *R1CS) digest() ([]byte, error) {
+	h := sha256.New()
+	var buf [2]byte
+	binary.BigEndian.PutUint16(buf[:], uint16(curve.ID))
+	h.Write(buf[:])
+	if _, err := cs.WriteTo(h); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// loadSnapshot returns the snapshot stored under opt.SolutionLabel, nil if there is none, and the
+// constraints which depend on the wires of witness which differ from the snapshot.
+func (inc *incrementalSolver) loadSnapshot(witness []fr.Element, opt backend.ProverOption) (*r1csSnapshot, compiled.BitSet, error) {
+	s, ok := opt.SolutionCache.Load(opt.SolutionLabel)
+	if !ok {
+		return nil, nil, nil
+	}
+	snapshot, ok := s.Values.(*r1csSnapshot)
+	if !ok || string(s.Digest) != string(inc.digest) {
+		return nil, nil, fmt.Errorf("%w: got digest %x, expected %x", backend.ErrSolutionCacheMismatch, s.Digest, inc.digest)
+	}
+
+	// wire 0 is the ONE_WIRE
+	var changed []int
+	for i := 0; i < len(witness); i++ {
+		if !witness[i].Equal(&snapshot.values[i+1]) {
+			changed = append(changed, i+1)
+		}
+	}
+	return snapshot, inc.graph.Reachable(changed), nil
+}
+
+// storeSnapshot stores a copy of the solution and of a, b, c under opt.SolutionLabel. The copies
+// are needed as the prover modifies the solution and the vectors in place.
+func (inc *incrementalSolver) storeSnapshot(solution *solution, a, b, c []fr.Element, opt backend.ProverOption) {
+	snapshot := &r1csSnapshot{
+		values: make([]fr.Element, len(solution.Solved)),
+		a:      append([]fr.Element(nil), a...),
+		b:      append([]fr.Element(nil), b...),
+		c:      append([]fr.Element(nil), c...),
+	}
+	for i := 0; i < len(snapshot.values); i++ {
+		snapshot.values[i] = solution.get(i)
+	}
+	opt.SolutionCache.Store(opt.SolutionLabel, backend.SolutionSnapshot{Digest: inc.digest, Values: snapshot})
+}
@@ -28,6 +28,7 @@ import (
 	"bytes"
 	bls12_377groth16 "github.com/consensys/gnark/internal/backend/bls12-377/groth16"
 	"testing"
+	"time"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
@@ -53,7 +54,10 @@ func (circuit *refCircuit) Define(curveID ecc.ID, api frontend.API) error {
 }
 
 func referenceCircuit() (frontend.CompiledConstraintSystem, frontend.Circuit) {
-	const nbConstraints = 40000
+	return referenceCircuitOfSize(40000)
+}
+
+func referenceCircuitOfSize(nbConstraints int) (frontend.CompiledConstraintSystem, frontend.Circuit) {
 	circuit := refCircuit{
 		nbConstraints: nbConstraints,
 	}
@@ -111,6 +115,42 @@ func BenchmarkProver(b *testing.B) {
 	})
 }
 
+// BenchmarkProverPrecomputed compares the prover with and without the tables of ProvingKey.Precompute,
+// computed once, on a large circuit
+func BenchmarkProverPrecomputed(b *testing.B) {
+	r1cs, _solution := referenceCircuitOfSize(500000)
+	fullWitness := bls12_377witness.Witness{}
+	err := fullWitness.FromFullAssignment(_solution)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var pk bls12_377groth16.ProvingKey
+	bls12_377groth16.DummySetup(r1cs.(*cs.R1CS), &pk)
+
+	b.ResetTimer()
+	b.Run("prover", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = bls12_377groth16.Prove(r1cs.(*cs.R1CS), &pk, fullWitness, backend.ProverOption{})
+		}
+	})
+
+	const windowSize = 16
+	b.StopTimer()
+	start := time.Now()
+	if err := pk.Precompute(windowSize); err != nil {
+		b.Fatal(err)
+	}
+	b.Logf("precomputed %d MB of tables in %s", pk.PrecomputeSize(windowSize)>>20, time.Since(start))
+	b.StartTimer()
+
+	b.Run("prover-precomputed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = bls12_377groth16.Prove(r1cs.(*cs.R1CS), &pk, fullWitness, backend.ProverOption{})
+		}
+	})
+}
+
 func BenchmarkVerifier(b *testing.B) {
 	r1cs, _solution := referenceCircuit()
 	fullWitness := bls12_377witness.Witness{}
@@ -134,9 +174,22 @@ func BenchmarkVerifier(b *testing.B) {
 
 	b.ResetTimer()
 	b.Run("verifier", func(b *testing.B) {
+		start := time.Now()
 		for i := 0; i < b.N; i++ {
 			_ = bls12_377groth16.Verify(proof, &vk, publicWitness)
 		}
+		b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "verifications/s")
+	})
+
+	// the key isn't precomputed (see VerifyingKey.Precompute), each verification computes e(α, β)
+	b.Run("verifier-no-precomputation", func(b *testing.B) {
+		start := time.Now()
+		for i := 0; i < b.N; i++ {
+			var _vk bls12_377groth16.VerifyingKey
+			_vk.G1, _vk.G2 = vk.G1, vk.G2
+			_ = bls12_377groth16.Verify(proof, &_vk, publicWitness)
+		}
+		b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "verifications/s")
 	})
 }
 
@@ -0,0 +1,238 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package groth16
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
+
+	"github.com/consensys/gnark/internal/backend/bls12-377/cs"
+
+	bls12_377witness "github.com/consensys/gnark/internal/backend/bls12-377/witness"
+
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+)
+
+func TestG1TableMultiExp(t *testing.T) {
+	const nbBases = 37
+	_, _, g1, _ := curve.Generators()
+
+	// random bases, the point at infinity, and equal or opposite bases, which the affine additions don't handle
+	var baseScalars [nbBases]fr.Element
+	for i := 0; i < nbBases; i++ {
+		baseScalars[i].SetRandom()
+	}
+	bases := curve.BatchScalarMultiplicationG1(&g1, baseScalars[:])
+	bases[3] = curve.G1Affine{}
+	for i := 6; i < 12; i++ {
+		bases[i] = bases[5]
+	}
+	bases[12].Neg(&bases[5])
+
+	// random scalars, and the edge cases of the signed digits
+	scalars := make([]fr.Element, nbBases)
+	for i := 0; i < nbBases; i++ {
+		scalars[i].SetRandom()
+	}
+	scalars[0].SetZero()
+	scalars[1].SetOne()
+	scalars[2].SetOne().Neg(&scalars[2])
+	var max big.Int
+	max.Lsh(big.NewInt(1), fr.Bits).Sub(&max, big.NewInt(1)).Mod(&max, fr.Modulus())
+	scalars[4].SetBigInt(&max)
+	scalars[5].SetUint64(1<<20 - 1)
+	for i := 6; i < 10; i++ {
+		scalars[i] = scalars[5]
+	}
+	scalars[12] = scalars[5]
+	for i := 0; i < nbBases; i++ {
+		scalars[i].FromMont()
+	}
+
+	var expected curve.G1Jac
+	if _, err := expected.MultiExp(bases, scalars, ecc.MultiExpConfig{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for c := MinPrecomputeWindowSize; c <= 16; c++ {
+		table := newG1Table(bases, c)
+		if !table.matches(bases) {
+			t.Fatalf("c == %d: the table doesn't match its bases", c)
+		}
+		for _, nbTasks := range []int{0, 1, 3, nbBases + 1} {
+			var res curve.G1Jac
+			if err := table.multiExp(&res, scalars, nbTasks); err != nil {
+				t.Fatal(err)
+			}
+			if !res.Equal(&expected) {
+				t.Fatalf("c == %d, %d tasks: the fixed-base multi exp doesn't match MultiExp", c, nbTasks)
+			}
+		}
+	}
+
+	// P + P and P - P, in the same buckets
+	var minusP curve.G1Affine
+	minusP.Neg(&bases[0])
+	for _, pair := range [][]curve.G1Affine{{bases[0], bases[0]}, {bases[0], minusP}} {
+		var expected, res curve.G1Jac
+		if _, err := expected.MultiExp(pair, scalars[4:6], ecc.MultiExpConfig{}); err != nil {
+			t.Fatal(err)
+		}
+		table := newG1Table(pair, 8)
+		if err := table.multiExp(&res, scalars[4:6], 1); err != nil {
+			t.Fatal(err)
+		}
+		if !res.Equal(&expected) {
+			t.Fatal("the fixed-base multi exp doesn't match MultiExp, with equal or opposite bases")
+		}
+	}
+
+	table := newG1Table(bases, 8)
+	var res curve.G1Jac
+	if err := table.multiExp(&res, scalars[1:], 1); err == nil {
+		t.Fatal("expected an error, the number of scalars doesn't match the number of bases")
+	}
+}
+
+type precomputeCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+// X**4 == Y, with X on 16 bits
+func (circuit *precomputeCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.FromBinary(api.ToBinary(circuit.X, 16)...), circuit.X)
+	x2 := api.Mul(circuit.X, circuit.X)
+	api.AssertIsEqual(api.Mul(x2, x2), circuit.Y)
+	return nil
+}
+
+func TestPrecomputedProve(t *testing.T) {
+	ccs, err := frontend.Compile(curve.ID, backend.GROTH16, &precomputeCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1cs := ccs.(*cs.R1CS)
+
+	var pk ProvingKey
+	var vk VerifyingKey
+	if err := Setup(r1cs, &pk, &vk); err != nil {
+		t.Fatal(err)
+	}
+
+	var assignment precomputeCircuit
+	assignment.X.Assign(1234)
+	assignment.Y.Assign(new(big.Int).Exp(big.NewInt(1234), big.NewInt(4), nil))
+	var witness, publicWitness bls12_377witness.Witness
+	if err := witness.FromFullAssignment(&assignment); err != nil {
+		t.Fatal(err)
+	}
+	if err := publicWitness.FromPublicAssignment(&assignment); err != nil {
+		t.Fatal(err)
+	}
+
+	var r, s fr.Element
+	r.SetRandom()
+	s.SetRandom()
+	options := []backend.ProverOption{{}, {PackBooleans: true}}
+
+	var expected []*Proof
+	for _, opt := range options {
+		proof, err := prove(r1cs, &pk, witness, opt, r, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected = append(expected, proof)
+	}
+
+	if err := pk.Precompute(MinPrecomputeWindowSize - 1); err == nil {
+		t.Fatal("expected an error, invalid window size")
+	}
+	for _, windowSize := range []int{MinPrecomputeWindowSize, 5, 16} {
+		if err := pk.Precompute(windowSize); err != nil {
+			t.Fatal(err)
+		}
+
+		// the tables survive a round trip
+		var buf bytes.Buffer
+		written, err := pk.WritePrecomputedTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if written != int64(buf.Len()) || written < pk.PrecomputeSize(windowSize) {
+			t.Fatalf("wrote %d bytes, buffer has %d, expected at least %d", written, buf.Len(), pk.PrecomputeSize(windowSize))
+		}
+		pk.precomputed = nil
+		read, err := pk.ReadPrecomputedFrom(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if read != written {
+			t.Fatalf("read %d bytes, wrote %d", read, written)
+		}
+		if pk.precomputation() == nil {
+			t.Fatal("the tables read don't match the key")
+		}
+
+		// the proofs are the same, with the same randomness
+		for i, opt := range options {
+			proof, err := prove(r1cs, &pk, witness, opt, r, s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proof.Ar.Equal(&expected[i].Ar) || !proof.Bs.Equal(&expected[i].Bs) || !proof.Krs.Equal(&expected[i].Krs) {
+				t.Fatalf("window size %d, %+v: the proof differs from the one computed without tables", windowSize, opt)
+			}
+			if err := Verify(proof, &vk, publicWitness); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// the tables of another key are rejected, and dropped when the key is read again
+	var buf bytes.Buffer
+	if _, err := pk.WritePrecomputedTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var other ProvingKey
+	if err := Setup(r1cs, &other, &vk); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.ReadPrecomputedFrom(bytes.NewReader(buf.Bytes())); err == nil {
+		t.Fatal("expected an error, the tables don't match the key")
+	}
+	var pkBuf bytes.Buffer
+	if _, err := other.WriteTo(&pkBuf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pk.ReadFrom(&pkBuf); err != nil {
+		t.Fatal(err)
+	}
+	if pk.precomputed != nil {
+		t.Fatal("the tables weren't dropped")
+	}
+	if _, err := pk.WritePrecomputedTo(&buf); err == nil {
+		t.Fatal("expected an error, no tables")
+	}
+}
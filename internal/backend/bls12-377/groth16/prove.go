@@ -25,13 +25,17 @@ import (
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
 
+	"bytes"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
 	bls12_377witness "github.com/consensys/gnark/internal/backend/bls12-377/witness"
 	"github.com/consensys/gnark/internal/utils"
+	"github.com/consensys/gnark/logger"
+	"io"
 	"math/big"
 	"runtime"
+	"time"
 )
 
 // Proof represents a Groth16 proof that was encoded with a ProvingKey and can be verified
@@ -42,11 +46,6 @@ type Proof struct {
 	Bs      curve.G2Affine
 }
 
-// isValid ensures proof elements are in the correct subgroup
-func (proof *Proof) isValid() bool {
-	return proof.Ar.IsInSubGroup() && proof.Krs.IsInSubGroup() && proof.Bs.IsInSubGroup()
-}
-
 // CurveID returns the curveID
 func (proof *Proof) CurveID() ecc.ID {
 	return curve.ID
@@ -54,29 +53,72 @@ func (proof *Proof) CurveID() ecc.ID {
 
 // Prove generates the proof of knoweldge of a r1cs with full witness (secret + public part).
 func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_377witness.Witness, opt backend.ProverOption) (*Proof, error) {
+	// sample random r and s
+	var r, s fr.Element
+	if err := setRandom(&r, opt.Randomness); err != nil {
+		return nil, err
+	}
+	if err := setRandom(&s, opt.Randomness); err != nil {
+		return nil, err
+	}
+	return prove(r1cs, pk, witness, opt, r, s)
+}
+
+// setRandom sets e to a random element read from rnd, or from crypto/rand if rnd is nil (see backend.WithRandomness)
+func setRandom(e *fr.Element, rnd io.Reader) error {
+	if rnd == nil {
+		_, err := e.SetRandom()
+		return err
+	}
+	// twice the size of the modulus, so that the reduction is close to uniform
+	var buf [2 * fr.Bytes]byte
+	if _, err := io.ReadFull(rnd, buf[:]); err != nil {
+		return err
+	}
+	e.SetBytes(buf[:])
+	return nil
+}
+
+// prove is Prove with the randomness r and s of the proof
+func prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_377witness.Witness, opt backend.ProverOption, _r, _s fr.Element) (*Proof, error) {
 	if len(witness) != int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables) {
 		return nil, fmt.Errorf("invalid witness size, got %d, expected %d = %d (public - ONE_WIRE) + %d (secret)", len(witness), int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables), r1cs.NbPublicVariables, r1cs.NbSecretVariables)
 	}
 
+	start := time.Now()
+
 	// solve the R1CS and compute the a, b, c vectors
-	a := make([]fr.Element, len(r1cs.Constraints), pk.Domain.Cardinality)
-	b := make([]fr.Element, len(r1cs.Constraints), pk.Domain.Cardinality)
-	c := make([]fr.Element, len(r1cs.Constraints), pk.Domain.Cardinality)
+	a := make([]fr.Element, r1cs.Constraints.Len(), pk.Domain.Cardinality)
+	b := make([]fr.Element, r1cs.Constraints.Len(), pk.Domain.Cardinality)
+	c := make([]fr.Element, r1cs.Constraints.Len(), pk.Domain.Cardinality)
 	var wireValues []fr.Element
+	var packed cs.PackedWires // if opt.PackBooleans, wireValues only holds the non boolean wires
 	var err error
-	if wireValues, err = r1cs.Solve(witness, a, b, c, opt); err != nil {
+	if opt.PackBooleans {
+		packed, err = r1cs.SolvePacked(witness, a, b, c, opt)
+		wireValues = packed.Values
+	} else {
+		wireValues, err = r1cs.Solve(witness, a, b, c, opt)
+	}
+	if err != nil {
 		if !opt.Force {
 			return nil, err
 		} else {
 			// we need to fill wireValues with random values else multi exps don't do much
 			var r fr.Element
-			_, _ = r.SetRandom()
-			for i := r1cs.NbPublicVariables + r1cs.NbSecretVariables; i < len(wireValues); i++ {
-				wireValues[i] = r
+			_ = setRandom(&r, opt.Randomness)
+			nbWires := r1cs.NbPublicVariables + r1cs.NbSecretVariables + r1cs.NbInternalVariables
+			for i := r1cs.NbPublicVariables + r1cs.NbSecretVariables; i < nbWires; i++ {
+				if !opt.PackBooleans {
+					wireValues[i] = r
+				} else if !packed.Layout.IsBoolean(i) {
+					wireValues[packed.Layout.Index(i)] = r
+				}
 				r.Double(&r)
 			}
 		}
 	}
+	logger.Debug("groth16 prover: %s, %d constraints solved in %s", curve.ID, r1cs.Constraints.Len(), time.Since(start))
 
 	// set the wire values in regular form
 	utils.Parallelize(len(wireValues), func(start, end int) {
@@ -85,6 +127,23 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_377witness.Witness, opt
 		}
 	})
 
+	// with the tables of pk.Precompute, the multi exps in G1 are fixed-base ones, over all the wire values
+	pre := pk.precomputation()
+	packedMultiExps := opt.PackBooleans && pre == nil
+	if opt.PackBooleans && pre != nil {
+		wireValues = unpackWires(&packed, packed.Layout.NbWires())
+	}
+
+	if opt.WitnessAnalysis != nil {
+		values := wireValues
+		if packedMultiExps {
+			values = unpackWires(&packed, packed.Layout.NbWires())
+		}
+		if _, err := r1cs.AnalyzeSolution(values).WriteTo(opt.WitnessAnalysis); err != nil {
+			return nil, fmt.Errorf("witness analysis: %w", err)
+		}
+	}
+
 	// H (witness reduction / FFT part)
 	var h []fr.Element
 	chHDone := make(chan struct{}, 1)
@@ -101,38 +160,38 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_377witness.Witness, opt
 	var wireValuesA, wireValuesB []fr.Element
 	chWireValuesA, chWireValuesB := make(chan struct{}, 1), make(chan struct{}, 1)
 
-	go func() {
-		wireValuesA = make([]fr.Element, len(wireValues)-int(pk.NbInfinityA))
-		for i, j := 0, 0; j < len(wireValuesA); i++ {
-			if pk.InfinityA[i] {
-				continue
-			}
-			wireValuesA[j] = wireValues[i]
-			j++
-		}
+	if packedMultiExps {
+		// the multi exps filter the packed wires themselves, see multiExpPackedG1
 		close(chWireValuesA)
-	}()
-	go func() {
-		wireValuesB = make([]fr.Element, len(wireValues)-int(pk.NbInfinityB))
-		for i, j := 0, 0; j < len(wireValuesB); i++ {
-			if pk.InfinityB[i] {
-				continue
-			}
-			wireValuesB[j] = wireValues[i]
-			j++
-		}
 		close(chWireValuesB)
-	}()
+	} else {
+		go func() {
+			wireValuesA = make([]fr.Element, len(wireValues)-int(pk.NbInfinityA))
+			for i, j := 0, 0; j < len(wireValuesA); i++ {
+				if pk.InfinityA[i] {
+					continue
+				}
+				wireValuesA[j] = wireValues[i]
+				j++
+			}
+			close(chWireValuesA)
+		}()
+		go func() {
+			wireValuesB = make([]fr.Element, len(wireValues)-int(pk.NbInfinityB))
+			for i, j := 0, 0; j < len(wireValuesB); i++ {
+				if pk.InfinityB[i] {
+					continue
+				}
+				wireValuesB[j] = wireValues[i]
+				j++
+			}
+			close(chWireValuesB)
+		}()
+	}
 
-	// sample random r and s
+	// kr = -r*s, and r, s, kr in regular form
 	var r, s big.Int
-	var _r, _s, _kr fr.Element
-	if _, err := _r.SetRandom(); err != nil {
-		return nil, err
-	}
-	if _, err := _s.SetRandom(); err != nil {
-		return nil, err
-	}
+	var _kr fr.Element
 	_kr.Mul(&_r, &_s).Neg(&_kr)
 
 	_r.FromMont()
@@ -152,7 +211,15 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_377witness.Witness, opt
 	chBs1Done := make(chan error, 1)
 	computeBS1 := func() {
 		<-chWireValuesB
-		if _, err := bs1.MultiExp(pk.G1.B, wireValuesB, ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
+		var err error
+		if pre != nil {
+			err = pre.B.multiExp(&bs1, wireValuesB, n/2)
+		} else if packedMultiExps {
+			err = multiExpPackedG1(&bs1, pk.G1.B, pk.InfinityB, &packed, 0, ecc.MultiExpConfig{NbTasks: n / 2})
+		} else {
+			_, err = bs1.MultiExp(pk.G1.B, wireValuesB, ecc.MultiExpConfig{NbTasks: n / 2})
+		}
+		if err != nil {
 			chBs1Done <- err
 			close(chBs1Done)
 			return
@@ -165,7 +232,15 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_377witness.Witness, opt
 	chArDone := make(chan error, 1)
 	computeAR1 := func() {
 		<-chWireValuesA
-		if _, err := ar.MultiExp(pk.G1.A, wireValuesA, ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
+		var err error
+		if pre != nil {
+			err = pre.A.multiExp(&ar, wireValuesA, n/2)
+		} else if packedMultiExps {
+			err = multiExpPackedG1(&ar, pk.G1.A, pk.InfinityA, &packed, 0, ecc.MultiExpConfig{NbTasks: n / 2})
+		} else {
+			_, err = ar.MultiExp(pk.G1.A, wireValuesA, ecc.MultiExpConfig{NbTasks: n / 2})
+		}
+		if err != nil {
 			chArDone <- err
 			close(chArDone)
 			return
@@ -184,10 +259,23 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_377witness.Witness, opt
 		var krs, krs2, p1 curve.G1Jac
 		chKrs2Done := make(chan error, 1)
 		go func() {
-			_, err := krs2.MultiExp(pk.G1.Z, h, ecc.MultiExpConfig{NbTasks: n / 2})
+			var err error
+			if pre != nil {
+				err = pre.Z.multiExp(&krs2, h, n/2)
+			} else {
+				_, err = krs2.MultiExp(pk.G1.Z, h, ecc.MultiExpConfig{NbTasks: n / 2})
+			}
 			chKrs2Done <- err
 		}()
-		if _, err := krs.MultiExp(pk.G1.K, wireValues[r1cs.NbPublicVariables:], ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
+		var err error
+		if pre != nil {
+			err = pre.K.multiExp(&krs, wireValues[r1cs.NbPublicVariables:], n/2)
+		} else if packedMultiExps {
+			err = multiExpPackedG1(&krs, pk.G1.K, nil, &packed, r1cs.NbPublicVariables, ecc.MultiExpConfig{NbTasks: n / 2})
+		} else {
+			_, err = krs.MultiExp(pk.G1.K, wireValues[r1cs.NbPublicVariables:], ecc.MultiExpConfig{NbTasks: n / 2})
+		}
+		if err != nil {
 			chKrsDone <- err
 			return
 		}
@@ -233,7 +321,13 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_377witness.Witness, opt
 			nbTasks *= 2
 		}
 		<-chWireValuesB
-		if _, err := Bs.MultiExp(pk.G2.B, wireValuesB, ecc.MultiExpConfig{NbTasks: nbTasks}); err != nil {
+		var err error
+		if packedMultiExps {
+			err = multiExpPackedG2(&Bs, pk.G2.B, pk.InfinityB, &packed, ecc.MultiExpConfig{NbTasks: nbTasks})
+		} else {
+			_, err = Bs.MultiExp(pk.G2.B, wireValuesB, ecc.MultiExpConfig{NbTasks: nbTasks})
+		}
+		if err != nil {
 			return err
 		}
 
@@ -261,10 +355,98 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_377witness.Witness, opt
 	if err := <-chKrsDone; err != nil {
 		return nil, err
 	}
+	logger.Debug("groth16 prover: %s, proof computed in %s", curve.ID, time.Since(start))
+
+	// the components are computed concurrently, see backend.WithProofComponents
+	if opt.ProofComponents != nil {
+		names := []string{"ar", "bs", "krs"}
+		for i, v := range []interface{}{&proof.Ar, &proof.Bs, &proof.Krs} {
+			var buf bytes.Buffer
+			if err := curve.NewEncoder(&buf).Encode(v); err != nil {
+				return nil, err
+			}
+			if err := opt.ProofComponents(names[i], buf.Bytes()); err != nil {
+				return nil, err
+			}
+		}
+	}
 
 	return proof, nil
 }
 
+// unpackWires returns the values of the nbWires wires of w, in regular form as w.Values
+func unpackWires(w *cs.PackedWires, nbWires int) []fr.Element {
+	values := make([]fr.Element, nbWires)
+	utils.Parallelize(nbWires, func(start, end int) {
+		for i := start; i < end; i++ {
+			if !w.Layout.IsBoolean(i) {
+				values[i] = w.Values[w.Layout.Index(i)]
+			} else if w.Bits.Get(w.Layout.Index(i)) {
+				values[i][0] = 1 // 1 in regular form
+			}
+		}
+	})
+	return values
+}
+
+// multiExpPackedG1 sets res to the multi exponentiation of points by the values of the wires [from, ...) of w,
+// skipping the wires i with infinity[i] set (infinity may be nil), as their points are not in points.
+// The boolean wires are accumulated with point additions, the others with a multi exponentiation.
+func multiExpPackedG1(res *curve.G1Jac, points []curve.G1Affine, infinity []bool, w *cs.PackedWires, from int, config ecc.MultiExpConfig) error {
+	var bases []curve.G1Affine
+	var scalars []fr.Element
+	var acc curve.G1Jac // point at infinity
+	for i, j := from, 0; j < len(points); i++ {
+		if infinity != nil && infinity[i] {
+			continue
+		}
+		if !w.Layout.IsBoolean(i) {
+			bases = append(bases, points[j])
+			scalars = append(scalars, w.Values[w.Layout.Index(i)])
+		} else if w.Bits.Get(w.Layout.Index(i)) {
+			acc.AddMixed(&points[j])
+		}
+		j++
+	}
+	if len(bases) == 0 {
+		res.Set(&acc)
+		return nil
+	}
+	if _, err := res.MultiExp(bases, scalars, config); err != nil {
+		return err
+	}
+	res.AddAssign(&acc)
+	return nil
+}
+
+// multiExpPackedG2 is multiExpPackedG1 in G2, for all the wires
+func multiExpPackedG2(res *curve.G2Jac, points []curve.G2Affine, infinity []bool, w *cs.PackedWires, config ecc.MultiExpConfig) error {
+	var bases []curve.G2Affine
+	var scalars []fr.Element
+	var acc curve.G2Jac // point at infinity
+	for i, j := 0, 0; j < len(points); i++ {
+		if infinity[i] {
+			continue
+		}
+		if !w.Layout.IsBoolean(i) {
+			bases = append(bases, points[j])
+			scalars = append(scalars, w.Values[w.Layout.Index(i)])
+		} else if w.Bits.Get(w.Layout.Index(i)) {
+			acc.AddMixed(&points[j])
+		}
+		j++
+	}
+	if len(bases) == 0 {
+		res.Set(&acc)
+		return nil
+	}
+	if _, err := res.MultiExp(bases, scalars, config); err != nil {
+		return err
+	}
+	res.AddAssign(&acc)
+	return nil
+}
+
 func computeH(a, b, c []fr.Element, domain *fft.Domain) []fr.Element {
 	// H part of Krs
 	// Compute H (hz=ab-c, where z=-2 on ker X^n+1 (z(x)=x^n-1))
@@ -26,8 +26,13 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
 	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/consensys/gnark/internal/utils"
+	"github.com/consensys/gnark/logger"
 	"math/big"
 	"math/bits"
+	"runtime"
+	"sync/atomic"
+	"time"
 )
 
 // ProvingKey is used by a Groth16 prover to encode a proof of a statement
@@ -53,6 +58,12 @@ type ProvingKey struct {
 	// if InfinityA[i] == true, the point G1.A[i] == infinity
 	InfinityA, InfinityB     []bool
 	NbInfinityA, NbInfinityB uint64
+
+	// metadata of the constraint system (see frontend.WithMetadata)
+	Metadata map[string]string
+
+	// fixed-base tables of the G1 points, built by Precompute, not serialized (see WritePrecomputedTo)
+	precomputed *pkPrecomputation
 }
 
 // VerifyingKey is used by a Groth16 verifier to verify the validity of a proof and a statement
@@ -65,19 +76,21 @@ type VerifyingKey struct {
 		K           []curve.G1Affine // The indexes correspond to the public wires
 	}
 
-	// [β]2, [δ]2, [γ]2,
-	// -[δ]2, -[γ]2: see proof.Verify() for more details
+	// [β]2, [δ]2, [γ]2
 	G2 struct {
 		Beta, Delta, Gamma curve.G2Affine
-		deltaNeg, gammaNeg curve.G2Affine // not serialized
 	}
 
-	// e(α, β)
-	e curve.GT // not serialized
+	// *vkPrecomputation, cached by Precompute, not serialized (see WritePreparedTo)
+	precomputed atomic.Value
+
+	// metadata of the constraint system (see frontend.WithMetadata)
+	Metadata map[string]string
 }
 
 // Setup constructs the SRS
 func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
+	start := time.Now()
 
 	/*
 		Setup
@@ -95,7 +108,7 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	nbPrivateWires := r1cs.NbSecretVariables + r1cs.NbInternalVariables
 
 	// Setting group for fft
-	domain := fft.NewDomain(uint64(len(r1cs.Constraints)), 1, true)
+	domain := fft.NewDomain(uint64(r1cs.Constraints.Len()), 1, true)
 
 	// samples toxic waste
 	toxicWaste, err := sampleToxicWaste()
@@ -251,14 +264,12 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	pk.G2.Beta = g2PointsAff[len(B)+0]
 	pk.G2.Delta = g2PointsAff[len(B)+1]
 
-	// sets vk: [δ]2, [γ]2, -[δ]2, -[γ]2
+	// sets vk: [δ]2, [γ]2
 	vk.G2.Delta = g2PointsAff[len(B)+1]
 	vk.G2.Gamma = g2PointsAff[len(B)+2]
-	vk.G2.deltaNeg.Neg(&vk.G2.Delta)
-	vk.G2.gammaNeg.Neg(&vk.G2.Gamma)
 
 	// ---------------------------------------------------------------------------------------------
-	// Pairing: vk.e
+	// Pairing: e(α, β)
 	vk.G1.Alpha = pk.G1.Alpha
 	vk.G2.Beta = pk.G2.Beta
 
@@ -266,16 +277,32 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	vk.G1.Beta = pk.G1.Beta
 	vk.G1.Delta = pk.G1.Delta
 
-	vk.e, err = curve.Pair([]curve.G1Affine{pk.G1.Alpha}, []curve.G2Affine{pk.G2.Beta})
-	if err != nil {
+	vk.resetPrecomputation()
+	if err := vk.Precompute(); err != nil {
 		return err
 	}
+
 	// set domain
 	pk.Domain = *domain
 
+	pk.Metadata = compiled.CopyMetadata(r1cs.Metadata)
+	vk.Metadata = compiled.CopyMetadata(r1cs.Metadata)
+
+	logger.Debug("groth16 setup: %s, %d constraints, done in %s", curve.ID, r1cs.Constraints.Len(), time.Since(start))
+
 	return nil
 }
 
+// setupChunkSize is the number of constraints processed at once by setupABC;
+// it bounds the scratch memory used to evaluate the Lagrange polynomials at t
+const setupChunkSize = 1 << 16
+
+// setupABC evaluates at t the QAP polynomials A, B, C of each wire.
+//
+// The constraints are streamed by chunks: the i-th Lagrange polynomial evaluated at t
+// is computed directly from i, such that no domain sized buffer is needed, and the
+// chunks don't depend on each other. Within a chunk, the Lagrange evaluations are
+// computed in parallel, then accumulated in A, B and C by tasks owning disjoint sets of wires.
 func setupABC(r1cs *cs.R1CS, domain *fft.Domain, toxicWaste toxicWaste) (A []fr.Element, B []fr.Element, C []fr.Element) {
 
 	nbWires := r1cs.NbInternalVariables + r1cs.NbPublicVariables + r1cs.NbSecretVariables
@@ -286,27 +313,12 @@ func setupABC(r1cs *cs.R1CS, domain *fft.Domain, toxicWaste toxicWaste) (A []fr.
 
 	one := fr.One()
 
-	// first we compute [t-w^i] and its inverse
-	var w fr.Element
-	w.Set(&domain.Generator)
-	wi := fr.One()
-	t := make([]fr.Element, len(r1cs.Constraints)+1)
-	for i := 0; i < len(t); i++ {
-		t[i].Sub(&toxicWaste.t, &wi)
-		wi.Mul(&wi, &w) // TODO this is already pre computed in fft.Domain
-	}
-	tInv := fr.BatchInvert(t)
-
-	// evaluation of the i-th lagrange polynomial at t
-	var L fr.Element
-
-	// L = 1/n*(t^n-1)/(t-1), Li+1 = w*Li*(t-w^i)/(t-w^(i+1))
-
-	// Setting L0
-	L.Exp(toxicWaste.t, new(big.Int).SetUint64(uint64(domain.Cardinality))).
-		Sub(&L, &one)
-	L.Mul(&L, &tInv[0]).
-		Mul(&L, &domain.CardinalityInv)
+	// L_i(t) = w^i/n * (t^n-1)/(t-w^i)
+	// we set zn = (t^n-1)/n
+	var zn fr.Element
+	zn.Exp(toxicWaste.t, new(big.Int).SetUint64(uint64(domain.Cardinality))).
+		Sub(&zn, &one).
+		Mul(&zn, &domain.CardinalityInv)
 
 	accumulate := func(res *fr.Element, t compiled.Term, value *fr.Element) {
 		cID := t.CoeffID()
@@ -328,33 +340,93 @@ func setupABC(r1cs *cs.R1CS, domain *fft.Domain, toxicWaste toxicWaste) (A []fr.
 		}
 	}
 
-	// each constraint is in the form
-	// L * R == O
-	// L, R and O being linear expressions
-	// for each term appearing in the linear expression,
-	// we compute term.Coefficient * L, and cumulate it in
-	// A, B or C at the indice of the variable
-	for i, c := range r1cs.Constraints {
+	nbTasks := runtime.NumCPU()
+	L := make([]fr.Element, setupChunkSize)
+	tInv := make([]fr.Element, setupChunkSize)
+	ABC := [3][]fr.Element{A, B, C}
+	buckets := make([][]setupTerm, nbTasks)
 
-		for _, t := range c.L {
-			accumulate(&A[t.VariableID()], t, &L)
+	nbConstraints := r1cs.Constraints.Len()
+	for start := 0; start < nbConstraints; start += setupChunkSize {
+		end := start + setupChunkSize
+		if end > nbConstraints {
+			end = nbConstraints
 		}
-		for _, t := range c.R {
-			accumulate(&B[t.VariableID()], t, &L)
+		L := L[:end-start]
+
+		// evaluation of the Lagrange polynomials at t
+		utils.Parallelize(len(L), func(s, e int) {
+			var wi, w0 fr.Element
+			w0.Exp(domain.Generator, big.NewInt(int64(start+s)))
+
+			// [t-w^i]
+			wi.Set(&w0)
+			for i := s; i < e; i++ {
+				L[i].Sub(&toxicWaste.t, &wi)
+				wi.Mul(&wi, &domain.Generator)
+			}
+
+			// batch inversion of [t-w^i], in the scratch buffer
+			acc := fr.One()
+			for i := s; i < e; i++ {
+				tInv[i] = acc
+				acc.Mul(&acc, &L[i])
+			}
+			acc.Inverse(&acc)
+			for i := e - 1; i >= s; i-- {
+				tInv[i].Mul(&tInv[i], &acc)
+				acc.Mul(&acc, &L[i])
+			}
+
+			// L_i(t) = zn * w^i / (t-w^i)
+			wi.Set(&w0)
+			for i := s; i < e; i++ {
+				L[i].Mul(&tInv[i], &wi).Mul(&L[i], &zn)
+				wi.Mul(&wi, &domain.Generator)
+			}
+		}, nbTasks)
+
+		// each constraint is in the form
+		// L * R == O
+		// L, R and O being linear expressions
+		// for each term appearing in the linear expression,
+		// we compute term.Coefficient * L, and cumulate it in
+		// A, B or C at the indice of the variable
+		// the task k owns the wires whose ID is k modulo nbTasks: the terms of the chunk are
+		// bucketed by task in one pass, then each task accumulates the terms of its bucket
+		for k := range buckets {
+			buckets[k] = buckets[k][:0]
 		}
-		for _, t := range c.O {
-			accumulate(&C[t.VariableID()], t, &L)
+		var c compiled.R1C // the constraints are expanded one at a time, see compiled.R1CList
+		for i := range L {
+			r1cs.Constraints.Load(start+i, &c)
+			for m, l := range [3]compiled.LinearExpression{c.L, c.R, c.O} {
+				for _, t := range l {
+					k := t.VariableID() % nbTasks
+					buckets[k] = append(buckets[k], setupTerm{t: t, i: int32(i), m: uint8(m)})
+				}
+			}
 		}
-
-		// Li+1 = w*Li*(t-w^i)/(t-w^(i+1))
-		L.Mul(&L, &w)
-		L.Mul(&L, &t[i])
-		L.Mul(&L, &tInv[i+1])
+		utils.Parallelize(nbTasks, func(s, e int) {
+			for k := s; k < e; k++ {
+				for _, st := range buckets[k] {
+					accumulate(&ABC[st.m][st.t.VariableID()], st.t, &L[st.i])
+				}
+			}
+		}, nbTasks)
 	}
 	return
 
 }
 
+// setupTerm is a term of the i-th constraint of a chunk of setupABC, in the linear expression L, R or O
+// (m = 0, 1 or 2)
+type setupTerm struct {
+	t compiled.Term
+	i int32
+	m uint8
+}
+
 // toxicWaste toxic waste
 type toxicWaste struct {
 
@@ -412,7 +484,7 @@ func sampleToxicWaste() (toxicWaste, error) {
 func DummySetup(r1cs *cs.R1CS, pk *ProvingKey) error {
 	// get R1CS nb constraints, wires and public/private inputs
 	nbWires := r1cs.NbInternalVariables + r1cs.NbPublicVariables + r1cs.NbSecretVariables
-	nbConstraints := len(r1cs.Constraints)
+	nbConstraints := r1cs.Constraints.Len()
 
 	// Setting group for fft
 	domain := fft.NewDomain(uint64(nbConstraints), 1, true)
@@ -478,6 +550,7 @@ func DummySetup(r1cs *cs.R1CS, pk *ProvingKey) error {
 	pk.G2.Delta = r2Aff
 
 	pk.Domain = *domain
+	pk.Metadata = compiled.CopyMetadata(r1cs.Metadata)
 
 	return nil
 }
@@ -490,7 +563,9 @@ func dummyInfinityCount(r1cs *cs.R1CS) (nbZeroesA, nbZeroesB int) {
 
 	A := make([]bool, nbWires)
 	B := make([]bool, nbWires)
-	for _, c := range r1cs.Constraints {
+	var c compiled.R1C
+	for i := 0; i < r1cs.Constraints.Len(); i++ {
+		r1cs.Constraints.Load(i, &c)
 		for _, t := range c.L {
 			A[t.VariableID()] = true
 		}
@@ -547,6 +622,16 @@ func (pk *ProvingKey) IsDifferent(_other interface{}) bool {
 	return true
 }
 
+// GetMetadata returns the metadata of the constraint system the key was generated for
+func (pk *ProvingKey) GetMetadata() map[string]string {
+	return pk.Metadata
+}
+
+// GetMetadata returns the metadata of the constraint system the key was generated for
+func (vk *VerifyingKey) GetMetadata() map[string]string {
+	return vk.Metadata
+}
+
 // CurveID returns the curveID
 func (pk *ProvingKey) CurveID() ecc.ID {
 	return curve.ID
@@ -0,0 +1,222 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package witness
+
+import (
+	"bytes"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
+)
+
+type assignmentCircuit struct {
+	Secret []frontend.Variable
+	Public []frontend.Variable `gnark:",public"`
+}
+
+func (circuit *assignmentCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	return nil
+}
+
+// newAssignment returns an assignment with n secret inputs (decimal strings) defined before
+// n public inputs (integers)
+func newAssignment(n int) *assignmentCircuit {
+	var c assignmentCircuit
+	c.Secret = make([]frontend.Variable, n)
+	c.Public = make([]frontend.Variable, n)
+	for i := 0; i < n; i++ {
+		c.Secret[i].Assign(strconv.Itoa(i*i + 1))
+		c.Public[i].Assign(i)
+	}
+	return &c
+}
+
+func TestParallelAssignment(t *testing.T) {
+	assert := require.New(t)
+
+	w := newAssignment(2 * minParallelAssignment)
+
+	var sequential, parallel Witness
+	assert.NoError(sequential.fromAssignment(w, false, 1))
+	assert.NoError(parallel.fromAssignment(w, false, 8))
+	assert.Equal(len(sequential), len(parallel))
+	for i := 0; i < len(sequential); i++ {
+		assert.True(sequential[i].Equal(&parallel[i]), "witness[%d]", i)
+	}
+
+	var sequentialPublic, parallelPublic Witness
+	assert.NoError(sequentialPublic.fromAssignment(w, true, 1))
+	assert.NoError(parallelPublic.fromAssignment(w, true, 8))
+	assert.Equal(len(w.Public), len(parallelPublic))
+	for i := 0; i < len(sequentialPublic); i++ {
+		assert.True(sequentialPublic[i].Equal(&parallelPublic[i]), "public witness[%d]", i)
+	}
+
+	// the first invalid input is reported, whatever the goroutine converting it
+	n := len(w.Secret)
+	w.Secret[n-10].WitnessValue = 1.5
+	w.Secret[n-2].WitnessValue = 2.5
+	err := parallel.fromAssignment(w, false, 8)
+	assert.Error(err)
+	assert.Contains(err.Error(), "Secret_"+strconv.Itoa(n-10)+":")
+
+	w.Secret[n-10] = frontend.Variable{}
+	err = parallel.fromAssignment(w, false, 8)
+	assert.EqualError(err, "when parsing variable Secret_"+strconv.Itoa(n-10)+": missing assignment")
+
+	// secret inputs are not needed by the public witness
+	assert.NoError(parallelPublic.fromAssignment(w, true, 8))
+}
+
+type optionalCircuit struct {
+	X frontend.Variable `gnark:",public"`
+	Y frontend.Variable
+	Z frontend.Variable `gnark:",optional"`
+}
+
+func (circuit *optionalCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	return nil
+}
+
+func TestOptionalAssignment(t *testing.T) {
+	assert := require.New(t)
+
+	// a missing optional input is set to 0, even in a reused witness vector
+	witness := Witness{fr.NewElement(1), fr.NewElement(2), fr.NewElement(3)}
+	assert.NoError(witness.FromFullAssignment(&optionalCircuit{X: frontend.Value(4), Y: frontend.Value(5)}))
+	assert.Equal(Witness{fr.NewElement(4), fr.NewElement(5), fr.NewElement(0)}, witness)
+
+	var other Witness
+	err := other.FromFullAssignment(&optionalCircuit{X: frontend.Value(4), Z: frontend.Value(6)})
+	assert.EqualError(err, "when parsing variable Y: missing assignment")
+
+	// the binary witness may omit the trailing optional values
+	var buf bytes.Buffer
+	short := witness[:2]
+	_, err = short.WriteTo(&buf)
+	assert.NoError(err)
+	data := buf.Bytes()
+
+	var decoded Witness
+	_, err = decoded.LimitReadFrom(bytes.NewReader(data), 3)
+	assert.Error(err)
+	_, err = decoded.LimitReadFromOptional(bytes.NewReader(data), 3, 1)
+	assert.NoError(err)
+	assert.Equal(witness, decoded)
+	_, err = decoded.LimitReadFromOptional(bytes.NewReader(data), 4, 1)
+	assert.Error(err)
+}
+
+func BenchmarkAssignment(b *testing.B) {
+	w := newAssignment(250000) // 500k inputs
+
+	b.Run("sequential", func(b *testing.B) {
+		var witness Witness
+		for i := 0; i < b.N; i++ {
+			if err := witness.fromAssignment(w, false, 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		var witness Witness
+		for i := 0; i < b.N; i++ {
+			if err := witness.FromFullAssignment(w); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestFrSlice(t *testing.T) {
+	assert := require.New(t)
+
+	w := newAssignment(10)
+	var expected, expectedPublic Witness
+	assert.NoError(expected.FromFullAssignment(w))
+	assert.NoError(expectedPublic.FromPublicAssignment(w))
+
+	// the vector built from the values of the assignment is the same witness
+	public, secret, err := expected.ToFrSlices(len(w.Public))
+	assert.NoError(err)
+	assert.Equal([]fr.Element(expectedPublic), public)
+	v := FromFrSlice(public, secret)
+	assert.Equal(ecc.ID(curve.ID), v.CurveID())
+	nbPublic, nbSecret := v.NbInputs()
+	assert.Equal(len(w.Public), nbPublic)
+	assert.Equal(len(w.Secret), nbSecret)
+
+	var full, publicOnly Witness
+	assert.NoError(full.FromFullAssignment(v))
+	assert.NoError(publicOnly.FromPublicAssignment(v))
+	assert.Equal(expected, full)
+	assert.Equal(expectedPublic, publicOnly)
+
+	// the values are not copied by the vector, but are by the witness
+	p, s := v.ToFrSlices()
+	assert.Equal(&public[0], &p[0])
+	assert.Equal(&secret[0], &s[0])
+	full[0].SetOne()
+	assert.True(public[0].Equal(&expectedPublic[0]))
+
+	_, _, err = expected.ToFrSlices(len(expected) + 1)
+	assert.Error(err)
+}
+
+// BenchmarkFrSlice compares the witness built from the big.Int values of a circuit structure, and from
+// a vector of field elements
+func BenchmarkFrSlice(b *testing.B) {
+	const n = 1 << 19 // 1M inputs
+	public := make([]fr.Element, n)
+	secret := make([]fr.Element, n)
+	var c assignmentCircuit
+	c.Public = make([]frontend.Variable, n)
+	c.Secret = make([]frontend.Variable, n)
+	for i := 0; i < n; i++ {
+		public[i].SetUint64(uint64(i))
+		secret[i].SetUint64(uint64(i*i + 1))
+		var bPublic, bSecret big.Int
+		c.Public[i].Assign(public[i].ToBigIntRegular(&bPublic))
+		c.Secret[i].Assign(secret[i].ToBigIntRegular(&bSecret))
+	}
+
+	b.Run("bigInt", func(b *testing.B) {
+		var witness Witness
+		for i := 0; i < b.N; i++ {
+			if err := witness.FromFullAssignment(&c); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("fr", func(b *testing.B) {
+		var witness Witness
+		v := FromFrSlice(public, secret)
+		for i := 0; i < b.N; i++ {
+			if err := witness.FromFullAssignment(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
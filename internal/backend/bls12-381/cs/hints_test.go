@@ -0,0 +1,140 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package cs
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+func TestConstantTimeHints(t *testing.T) {
+	// the 6 builtin hints computed in constant time, under their 64-bit and 32-bit IDs
+	if len(constantTimeHints) != 12 {
+		t.Fatal("expected 12 constant time hints, got", len(constantTimeHints))
+	}
+
+	q := fr.Modulus()
+	values := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(2),
+		new(big.Int).Sub(q, big.NewInt(1)),
+		new(big.Int).Sub(q, big.NewInt(2)),
+		new(big.Int).Rsh(q, 1),
+		new(big.Int).Lsh(big.NewInt(1), 64),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1)),
+		new(big.Int).Lsh(big.NewInt(1), uint(q.BitLen()-1)),
+	}
+	positions := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(63),
+		big.NewInt(64),
+		big.NewInt(int64(q.BitLen() - 1)),
+		big.NewInt(int64(q.BitLen())),
+		big.NewInt(1000),
+		new(big.Int).Lsh(big.NewInt(1), 64), // not a uint64
+	}
+
+	var expected big.Int
+	outputs := make([]fr.Element, 1)
+	check := func(name string, f hint.Function, ct constantTimeHint, inputs ...*big.Int) {
+		// the big.Int hints may modify their inputs
+		bInputs := make([]*big.Int, len(inputs))
+		frInputs := make([]fr.Element, len(inputs))
+		for i := range inputs {
+			bInputs[i] = new(big.Int).Set(inputs[i])
+			frInputs[i].SetBigInt(inputs[i])
+		}
+		if err := f(ecc.BLS12_381, bInputs, &expected); err != nil {
+			t.Fatal(err)
+		}
+		if err := ct(frInputs, outputs); err != nil {
+			t.Fatal(err)
+		}
+		var got big.Int
+		outputs[0].ToBigIntRegular(&got)
+		if got.Cmp(&expected) != 0 {
+			t.Fatalf("%s%v: expected %s, got %s", name, inputs, expected.String(), got.String())
+		}
+	}
+
+	for _, v := range values {
+		check("IsZero", hint.IsZero, isZeroConstantTime, v)
+		check("InvMod", hint.InvMod, invModConstantTime, v)
+		for _, n := range positions {
+			check("IthBit", hint.IthBit, ithBitConstantTime, v, n)
+		}
+	}
+}
+
+func TestConstantTimeIsZeroTiming(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing test")
+	}
+
+	var zero, nonZero fr.Element
+	nonZero.SetRandom()
+
+	// fastest of a few runs of n calls of the hint with input a
+	measure := func(f func(a *fr.Element), a *fr.Element) time.Duration {
+		const n, runs = 200, 5
+		best := time.Duration(1<<63 - 1)
+		for r := 0; r < runs; r++ {
+			start := time.Now()
+			for i := 0; i < n; i++ {
+				f(a)
+			}
+			if d := time.Since(start); d < best {
+				best = d
+			}
+		}
+		return best
+	}
+	ratio := func(a, b time.Duration) float64 {
+		if a < b {
+			a, b = b, a
+		}
+		return float64(a) / float64(b)
+	}
+
+	inputs, outputs := make([]fr.Element, 1), make([]fr.Element, 1)
+	constantTime := func(a *fr.Element) {
+		inputs[0] = *a
+		_ = isZeroConstantTime(inputs, outputs)
+	}
+	var result, input big.Int
+	variableTime := func(a *fr.Element) {
+		a.ToBigIntRegular(&input)
+		_ = hint.IsZero(ecc.BLS12_381, []*big.Int{&input}, &result)
+	}
+
+	ct := ratio(measure(constantTime, &zero), measure(constantTime, &nonZero))
+	vt := ratio(measure(variableTime, &zero), measure(variableTime, &nonZero))
+	t.Logf("IsZero duration ratio between zero and non-zero inputs: %.2f (big.Int), %.2f (constant time)", vt, ct)
+
+	// coarse bound, the measures are noisy
+	if ct > 1.5 {
+		t.Fatalf("the constant time IsZero takes %.2f times longer on zero or on non-zero inputs", ct)
+	}
+}
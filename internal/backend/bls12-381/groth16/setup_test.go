@@ -0,0 +1,179 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package groth16
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+
+	"github.com/consensys/gnark/internal/backend/bls12-381/cs"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/fft"
+
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark/internal/backend/compiled"
+)
+
+func TestSetupABC(t *testing.T) {
+	toxicWaste, err := sampleToxicWaste()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, nbConstraints := range []int{1, 5, setupChunkSize - 1, setupChunkSize + 3} {
+		r1cs := setupTestR1CS(nbConstraints)
+		domain := fft.NewDomain(uint64(nbConstraints), 1, true)
+
+		A, B, C := setupABC(r1cs, domain, toxicWaste)
+		expectedA, expectedB, expectedC := setupABCReference(r1cs, domain, toxicWaste)
+
+		for _, v := range []struct {
+			name          string
+			got, expected []fr.Element
+		}{
+			{"A", A, expectedA},
+			{"B", B, expectedB},
+			{"C", C, expectedC},
+		} {
+			if len(v.got) != len(v.expected) {
+				t.Fatalf("%d constraints: len(%s) == %d, expected %d", nbConstraints, v.name, len(v.got), len(v.expected))
+			}
+			for i := 0; i < len(v.got); i++ {
+				if !v.got[i].Equal(&v.expected[i]) {
+					t.Fatalf("%d constraints: %s[%d] doesn't match the reference", nbConstraints, v.name, i)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkSetupABC(b *testing.B) {
+	const nbConstraints = 1 << 20
+	r1cs := setupTestR1CS(nbConstraints)
+	domain := fft.NewDomain(uint64(nbConstraints), 1, true)
+	toxicWaste, err := sampleToxicWaste()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	b.Run("reference", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			setupABCReference(r1cs, domain, toxicWaste)
+		}
+	})
+
+	b.Run("streaming", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			setupABC(r1cs, domain, toxicWaste)
+		}
+	})
+}
+
+// setupTestR1CS returns a R1CS with nbConstraints constraints of the form
+// (x_i - 2*x_{i+1}) * (42*x_i + 1) == 2*x_{i+2}, covering all the coefficient kinds
+func setupTestR1CS(nbConstraints int) *cs.R1CS {
+	var r1cs compiled.R1CS
+	r1cs.NbPublicVariables = 1
+	r1cs.NbInternalVariables = nbConstraints + 2
+
+	coefficients := make([]big.Int, 5)
+	coefficients[1].SetInt64(1)
+	coefficients[2].SetInt64(2)
+	coefficients[3].SetInt64(-1)
+	coefficients[4].SetInt64(42)
+
+	wire := func(i int) int {
+		return 1 + i
+	}
+
+	for i := 0; i < nbConstraints; i++ {
+		r1cs.Constraints.Append(compiled.R1C{
+			L: compiled.LinearExpression{
+				compiled.Pack(wire(i), compiled.CoeffIdOne, compiled.Internal),
+				compiled.Pack(wire(i+1), compiled.CoeffIdMinusOne, compiled.Internal),
+				compiled.Pack(wire(i+1), compiled.CoeffIdMinusOne, compiled.Internal),
+			},
+			R: compiled.LinearExpression{
+				compiled.Pack(wire(i), 4, compiled.Internal),
+				compiled.Pack(0, compiled.CoeffIdOne, compiled.Public),
+			},
+			O: compiled.LinearExpression{
+				compiled.Pack(wire(i+2), compiled.CoeffIdTwo, compiled.Internal),
+				compiled.Pack(wire(i), compiled.CoeffIdZero, compiled.Internal),
+			},
+		})
+	}
+
+	return cs.NewR1CS(r1cs, coefficients)
+}
+
+// setupABCReference is the straightforward evaluation of the QAP polynomials at t,
+// which computes [t-w^i] and its inverse for all the constraints at once
+func setupABCReference(r1cs *cs.R1CS, domain *fft.Domain, toxicWaste toxicWaste) (A []fr.Element, B []fr.Element, C []fr.Element) {
+
+	nbWires := r1cs.NbInternalVariables + r1cs.NbPublicVariables + r1cs.NbSecretVariables
+
+	A = make([]fr.Element, nbWires)
+	B = make([]fr.Element, nbWires)
+	C = make([]fr.Element, nbWires)
+
+	one := fr.One()
+
+	var w fr.Element
+	w.Set(&domain.Generator)
+	wi := fr.One()
+	t := make([]fr.Element, r1cs.Constraints.Len()+1)
+	for i := 0; i < len(t); i++ {
+		t[i].Sub(&toxicWaste.t, &wi)
+		wi.Mul(&wi, &w)
+	}
+	tInv := fr.BatchInvert(t)
+
+	// L = 1/n*(t^n-1)/(t-1), Li+1 = w*Li*(t-w^i)/(t-w^(i+1))
+	var L fr.Element
+	L.Exp(toxicWaste.t, new(big.Int).SetUint64(uint64(domain.Cardinality))).
+		Sub(&L, &one)
+	L.Mul(&L, &tInv[0]).
+		Mul(&L, &domain.CardinalityInv)
+
+	accumulate := func(res *fr.Element, t compiled.Term, value *fr.Element) {
+		var buffer fr.Element
+		buffer.Mul(&r1cs.Coefficients[t.CoeffID()], value)
+		res.Add(res, &buffer)
+	}
+
+	for i, c := range r1cs.Constraints.All() {
+		for _, t := range c.L {
+			accumulate(&A[t.VariableID()], t, &L)
+		}
+		for _, t := range c.R {
+			accumulate(&B[t.VariableID()], t, &L)
+		}
+		for _, t := range c.O {
+			accumulate(&C[t.VariableID()], t, &L)
+		}
+
+		L.Mul(&L, &w)
+		L.Mul(&L, &t[i])
+		L.Mul(&L, &tInv[i+1])
+	}
+	return
+}
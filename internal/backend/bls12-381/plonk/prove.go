@@ -17,11 +17,15 @@
 package plonk
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"fmt"
+	"io"
 	"math/big"
 	"math/bits"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
 
@@ -37,9 +41,9 @@ import (
 
 	"github.com/consensys/gnark/internal/backend/bls12-381/cs"
 
-	"github.com/consensys/gnark-crypto/fiat-shamir"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/internal/utils"
+	"github.com/consensys/gnark/logger"
 )
 
 type Proof struct {
@@ -57,16 +61,21 @@ type Proof struct {
 
 	// Opening proof of Z at zeta*mu
 	ZShiftedOpening kzg.OpeningProof
+
+	// Lookup is nil if the circuit has no lookups
+	Lookup *LookupProof
 }
 
 // Prove from the public data
 func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls12_381witness.Witness, opt backend.ProverOption) (*Proof, error) {
 
+	start := time.Now()
+
 	// pick a hash function that will be used to derive the challenges
 	hFunc := sha256.New()
 
 	// create a transcript manager to apply Fiat Shamir
-	fs := fiatshamir.NewTranscript(hFunc, "gamma", "alpha", "zeta")
+	fs := newTranscript(hFunc, pk.Lookup != nil)
 
 	// result
 	proof := &Proof{}
@@ -80,7 +89,7 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls12_381witness.Witn
 		} else {
 			// we need to fill solution with random values
 			var r fr.Element
-			_, _ = r.SetRandom()
+			_ = setRandom(&r, opt.Randomness)
 			for i := spr.NbPublicVariables + spr.NbSecretVariables; i < len(solution); i++ {
 				solution[i] = r
 				r.Double(&r)
@@ -88,20 +97,36 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls12_381witness.Witn
 		}
 	}
 
+	logger.Debug("plonk prover: %s, %d constraints solved in %s", curve.ID, len(spr.Constraints), time.Since(start))
+
 	// query l, r, o in Lagrange basis, not blinded
 	ll, lr, lo := computeLRO(spr, pk, solution)
 
 	// save ll, lr, lo, and make a copy of them in canonical basis.
 	// note that we allocate more capacity to reuse for blinded polynomials
-	bcl, bcr, bco, err := computeBlindedLRO(ll, lr, lo, &pk.DomainNum)
+	bcl, bcr, bco, err := computeBlindedLRO(ll, lr, lo, &pk.DomainNum, opt.Randomness)
 	if err != nil {
 		return nil, err
 	}
+	if opt.CheckBlinding {
+		for i, p := range []polynomial.Polynomial{bcl, bcr, bco} {
+			if err := checkBlinded([]string{"l", "r", "o"}[i], p, pk.DomainNum.Cardinality, 1); err != nil {
+				return nil, err
+			}
+		}
+	}
 
 	// compute kzg commitments of bcl, bcr and bco
 	if err := commitToLRO(bcl, bcr, bco, proof, pk.Vk.KZGSRS); err != nil {
 		return nil, err
 	}
+	version := proofVersion
+	if pk.Lookup != nil {
+		version = proofVersionLookup
+	}
+	if err := writeComponent(opt, "lro", version, &proof.LRO[0], &proof.LRO[1], &proof.LRO[2]); err != nil {
+		return nil, err
+	}
 
 	// derive gamma from the Comm(blinded cl), Comm(blinded cr), Comm(blinded co)
 	gamma, err := deriveRandomness(&fs, "gamma", &proof.LRO[0], &proof.LRO[1], &proof.LRO[2])
@@ -109,6 +134,19 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls12_381witness.Witn
 		return nil, err
 	}
 
+	// compute f, h1, h2 and z for the lookups, and derive the corresponding challenges
+	var lk *lookupPolynomials
+	if pk.Lookup != nil {
+		if lk, err = proveLookup(spr, pk, ll, &fs, proof, opt.Randomness); err != nil {
+			return nil, err
+		}
+		if opt.CheckBlinding {
+			if err := checkBlindedLookup(lk, pk.DomainNum.Cardinality); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// compute Z, the permutation accumulator polynomial, in canonical basis
 	// ll, lr, lo are NOT blinded
 	var bz polynomial.Polynomial
@@ -116,7 +154,10 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls12_381witness.Witn
 	var alpha fr.Element
 	go func() {
 		var err error
-		bz, err = computeBlindedZ(ll, lr, lo, pk, gamma)
+		bz, err = computeBlindedZ(ll, lr, lo, pk, gamma, opt.Randomness)
+		if err == nil && opt.CheckBlinding {
+			err = checkBlinded("z", bz, pk.DomainNum.Cardinality, 2)
+		}
 		if err != nil {
 			chZ <- err
 			close(chZ)
@@ -134,8 +175,8 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls12_381witness.Witn
 			return
 		}
 
-		// derive alpha from the Comm(l), Comm(r), Comm(o), Com(Z)
-		alpha, err = deriveRandomness(&fs, "alpha", &proof.Z)
+		// derive alpha from the Comm(l), Comm(r), Comm(o), Com(Z) (and Com(Z) of the lookups)
+		alpha, err = deriveRandomness(&fs, "alpha", zDigests(proof)...)
 		chZ <- err
 		close(chZ)
 	}()
@@ -162,9 +203,10 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls12_381witness.Witn
 	var constraintsInd, constraintsOrdering polynomial.Polynomial
 	chConstraintInd := make(chan struct{}, 1)
 	go func() {
-		// compute qk in canonical basis, completed with the public inputs
+		// compute qk in canonical basis, completed with the public inputs (the public outputs
+		// are computed by the solver)
 		qk := make(polynomial.Polynomial, pk.DomainNum.Cardinality)
-		copy(qk, fullWitness[:spr.NbPublicVariables])
+		copy(qk, solution[:spr.NbPublicVariables])
 		copy(qk[spr.NbPublicVariables:], pk.LQk[spr.NbPublicVariables:])
 		pk.DomainNum.FFTInverse(qk, fft.DIF, 0)
 		fft.BitReverse(qk)
@@ -198,14 +240,26 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls12_381witness.Witn
 	if err := <-chConstraintOrdering; err != nil {
 		return nil, err
 	}
+	if err := writeComponent(opt, "z", &proof.Z); err != nil {
+		return nil, err
+	}
+
+	var constraintsLookup polynomial.Polynomial
+	if lk != nil {
+		constraintsLookup = evalLookupConstraints(pk, lk, evalBL, alpha)
+	}
+
 	<-chConstraintInd
 	// compute h in canonical form
-	h1, h2, h3 := computeH(pk, constraintsInd, constraintsOrdering, evalBZ, alpha)
+	h1, h2, h3 := computeH(pk, constraintsInd, constraintsOrdering, constraintsLookup, evalBZ, alpha)
 
 	// compute kzg commitments of h1, h2 and h3
 	if err := commitToH(h1, h2, h3, proof, pk.Vk.KZGSRS); err != nil {
 		return nil, err
 	}
+	if err := writeComponent(opt, "h", &proof.H[0], &proof.H[1], &proof.H[2]); err != nil {
+		return nil, err
+	}
 
 	// derive zeta
 	zeta, err := deriveRandomness(&fs, "zeta", &proof.H[0], &proof.H[1], &proof.H[2])
@@ -242,6 +296,9 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls12_381witness.Witn
 	if err != nil {
 		return nil, err
 	}
+	if err := writeComponent(opt, "zShiftedOpening", &proof.ZShiftedOpening); err != nil {
+		return nil, err
+	}
 
 	// blinded z evaluated at u*zeta
 	bzuzeta := proof.ZShiftedOpening.ClaimedValue
@@ -330,11 +387,46 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls12_381witness.Witn
 	if err != nil {
 		return nil, err
 	}
+	if err := writeComponent(opt, "batchedProof", &proof.BatchedProof); err != nil {
+		return nil, err
+	}
+
+	if lk != nil {
+		if err := openLookup(lk, pk, zeta, hFunc, proof); err != nil {
+			return nil, err
+		}
+		// the lookup commitments are computed with the wires commitments, but are encoded with the opening proofs
+		if err := writeComponent(opt, "lookup", proof.Lookup); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Debug("plonk prover: %s, proof computed in %s", curve.ID, time.Since(start))
 
 	return proof, nil
 
 }
 
+// writeComponent calls opt.ProofComponents, if set, with the encoding of the values, a component of the
+// proof (see backend.WithProofComponents). The values are encoded as in Proof.WriteTo.
+func writeComponent(opt backend.ProverOption, name string, values ...interface{}) error {
+	if opt.ProofComponents == nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := curve.NewEncoder(&buf)
+	for _, v := range values {
+		if w, ok := v.(io.WriterTo); ok {
+			if _, err := w.WriteTo(&buf); err != nil {
+				return err
+			}
+		} else if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	return opt.ProofComponents(name, buf.Bytes())
+}
+
 // fills proof.LRO with kzg commits of bcl, bcr and bco
 func commitToLRO(bcl, bcr, bco polynomial.Polynomial, proof *Proof, srs *kzg.SRS) error {
 	n := runtime.NumCPU() / 2
@@ -388,43 +480,42 @@ func commitToH(h1, h2, h3 polynomial.Polynomial, proof *Proof, srs *kzg.SRS) err
 	return err1
 }
 
-// computeBlindedLRO l, r, o in canonical basis with blinding
-func computeBlindedLRO(ll, lr, lo polynomial.Polynomial, domain *fft.Domain) (bcl, bcr, bco polynomial.Polynomial, err error) {
+// computeBlindedLRO l, r, o in canonical basis with blinding, drawn from rnd (see setRandom)
+func computeBlindedLRO(ll, lr, lo polynomial.Polynomial, domain *fft.Domain, rnd io.Reader) (bcl, bcr, bco polynomial.Polynomial, err error) {
 
 	// note that bcl, bcr and bco reuses cl, cr and co memory
 	cl := make(polynomial.Polynomial, domain.Cardinality, domain.Cardinality+2)
 	cr := make(polynomial.Polynomial, domain.Cardinality, domain.Cardinality+2)
 	co := make(polynomial.Polynomial, domain.Cardinality, domain.Cardinality+2)
 
-	chDone := make(chan error, 2)
+	chDone := make(chan struct{}, 2)
 
 	go func() {
-		var err error
 		copy(cl, ll)
 		domain.FFTInverse(cl, fft.DIF, 0)
 		fft.BitReverse(cl)
-		bcl, err = blindPoly(cl, domain.Cardinality, 1)
-		chDone <- err
+		chDone <- struct{}{}
 	}()
 	go func() {
-		var err error
 		copy(cr, lr)
 		domain.FFTInverse(cr, fft.DIF, 0)
 		fft.BitReverse(cr)
-		bcr, err = blindPoly(cr, domain.Cardinality, 1)
-		chDone <- err
+		chDone <- struct{}{}
 	}()
 	copy(co, lo)
 	domain.FFTInverse(co, fft.DIF, 0)
 	fft.BitReverse(co)
-	if bco, err = blindPoly(co, domain.Cardinality, 1); err != nil {
+	<-chDone
+	<-chDone
+
+	// the blinding polynomials are drawn in order, so that the proof is reproducible with backend.WithRandomness
+	if bcl, err = blindPoly(cl, domain.Cardinality, 1, rnd); err != nil {
 		return
 	}
-	err = <-chDone
-	if err != nil {
+	if bcr, err = blindPoly(cr, domain.Cardinality, 1, rnd); err != nil {
 		return
 	}
-	err = <-chDone
+	bco, err = blindPoly(co, domain.Cardinality, 1, rnd)
 	return
 
 }
@@ -434,11 +525,12 @@ func computeBlindedLRO(ll, lr, lo polynomial.Polynomial, domain *fft.Domain) (bc
 // * cp polynomial in canonical form
 // * rou root of unity, meaning the blinding factor is multiple of X**rou-1
 // * bo blinding order,  it's the degree of Q, where the blinding is Q(X)*(X**degree-1)
+// * rnd source of the coefficients of Q, see setRandom
 //
 // WARNING:
 // pre condition degree(cp) <= rou + bo
 // pre condition cap(cp) >= int(totalDegree + 1)
-func blindPoly(cp polynomial.Polynomial, rou, bo uint64) (polynomial.Polynomial, error) {
+func blindPoly(cp polynomial.Polynomial, rou, bo uint64, rnd io.Reader) (polynomial.Polynomial, error) {
 
 	// degree of the blinded polynomial is max(rou+order, cp.Degree)
 	totalDegree := rou + bo
@@ -449,7 +541,7 @@ func blindPoly(cp polynomial.Polynomial, rou, bo uint64) (polynomial.Polynomial,
 	// random polynomial
 	blindingPoly := make(polynomial.Polynomial, bo+1)
 	for i := uint64(0); i < bo+1; i++ {
-		if _, err := blindingPoly[i].SetRandom(); err != nil {
+		if err := setRandom(&blindingPoly[i], rnd); err != nil {
 			return nil, err
 		}
 	}
@@ -463,6 +555,36 @@ func blindPoly(cp polynomial.Polynomial, rou, bo uint64) (polynomial.Polynomial,
 	return res, nil
 }
 
+// checkBlinded returns an error wrapping backend.ErrBlindingCheckFailed if p (canonical basis, of a domain
+// of size n) isn't blinded with order bo (see blindPoly): the coefficients of degree n to n+bo, which are
+// the coefficients of the blinding, must not be zero
+func checkBlinded(name string, p polynomial.Polynomial, n, bo uint64) error {
+	if uint64(len(p)) != n+bo+1 {
+		return fmt.Errorf("%w: %s has %d coefficients, expected %d", backend.ErrBlindingCheckFailed, name, len(p), n+bo+1)
+	}
+	for i := n; i <= n+bo; i++ {
+		if p[i].IsZero() {
+			return fmt.Errorf("%w: coefficient %d of %s is zero", backend.ErrBlindingCheckFailed, i, name)
+		}
+	}
+	return nil
+}
+
+// setRandom sets e to a random element read from rnd, or from crypto/rand if rnd is nil (see backend.WithRandomness)
+func setRandom(e *fr.Element, rnd io.Reader) error {
+	if rnd == nil {
+		_, err := e.SetRandom()
+		return err
+	}
+	// twice the size of the modulus, so that the reduction is close to uniform
+	var buf [2 * fr.Bytes]byte
+	if _, err := io.ReadFull(rnd, buf[:]); err != nil {
+		return err
+	}
+	e.SetBytes(buf[:])
+	return nil
+}
+
 // computeLRO extracts the solution l, r, o, and returns it in lagrange form.
 // solution = [ public | secret | internal ]
 func computeLRO(spr *cs.SparseR1CS, pk *ProvingKey, solution []fr.Element) (polynomial.Polynomial, polynomial.Polynomial, polynomial.Polynomial) {
@@ -507,7 +629,7 @@ func computeLRO(spr *cs.SparseR1CS, pk *ProvingKey, solution []fr.Element) (poly
 //								     (l_i+s1+gamma)*(r_i+s2+gamma)*(o_i+s3+gamma)
 //
 //	* l, r, o are the solution in Lagrange basis
-func computeBlindedZ(l, r, o polynomial.Polynomial, pk *ProvingKey, gamma fr.Element) (polynomial.Polynomial, error) {
+func computeBlindedZ(l, r, o polynomial.Polynomial, pk *ProvingKey, gamma fr.Element, rnd io.Reader) (polynomial.Polynomial, error) {
 
 	// note that z has more capacity has its memory is reused for blinded z later on
 	z := make(polynomial.Polynomial, pk.DomainNum.Cardinality, pk.DomainNum.Cardinality+3)
@@ -555,7 +677,7 @@ func computeBlindedZ(l, r, o polynomial.Polynomial, pk *ProvingKey, gamma fr.Ele
 	pk.DomainNum.FFTInverse(z, fft.DIF, 0)
 	fft.BitReverse(z)
 
-	return blindPoly(z, pk.DomainNum.Cardinality, 2)
+	return blindPoly(z, pk.DomainNum.Cardinality, 2, rnd)
 
 }
 
@@ -724,12 +846,13 @@ func evaluateHDomain(poly []fr.Element, domainH *fft.Domain) []fr.Element {
 
 // computeH computes h in canonical form, split as h1+X^mh2+X^2mh3 such that
 //
-// qlL+qrR+qmL.R+qoO+k + alpha.(zu*g1*g2*g3*l-z*f1*f2*f3*l) + alpha**2*L1*(z-1)= h.Z
-// \------------------/         \------------------------/             \-----/
-//    constraintsInd			    constraintOrdering					startsAtOne
+// qlL+qrR+qmL.R+qoO+k + alpha.(zu*g1*g2*g3*l-z*f1*f2*f3*l) + alpha**2*L1*(z-1) + alpha**3*lookup = h.Z
+// \------------------/         \------------------------/             \-----/             \----/
+//    constraintsInd			    constraintOrdering					startsAtOne    constraintsLookup
 //
-// constraintInd, constraintOrdering are evaluated on the odd cosets of (Z/8mZ)/(Z/mZ)
-func computeH(pk *ProvingKey, constraintsInd, constraintOrdering, evalBZ polynomial.Polynomial, alpha fr.Element) (polynomial.Polynomial, polynomial.Polynomial, polynomial.Polynomial) {
+// constraintInd, constraintOrdering, constraintsLookup are evaluated on the odd cosets of (Z/8mZ)/(Z/mZ).
+// constraintsLookup is nil if the circuit has no lookups.
+func computeH(pk *ProvingKey, constraintsInd, constraintOrdering, constraintsLookup, evalBZ polynomial.Polynomial, alpha fr.Element) (polynomial.Polynomial, polynomial.Polynomial, polynomial.Polynomial) {
 
 	h := make(polynomial.Polynomial, pk.DomainH.Cardinality)
 
@@ -778,7 +901,7 @@ func computeH(pk *ProvingKey, constraintsInd, constraintOrdering, evalBZ polynom
 	// / ! \ note that we scaled by the coset in the previous loop, hence we pass 0 as coset here.
 	pk.DomainH.FFT(startsAtOne, fft.DIF, 0)
 
-	// evaluate qlL+qrR+qmL.R+qoO+k + alpha.(zu*g1*g2*g3*l-z*f1*f2*f3*l) + alpha**2*L1(X)(Z(X)-1)
+	// evaluate qlL+qrR+qmL.R+qoO+k + alpha.(zu*g1*g2*g3*l-z*f1*f2*f3*l) + alpha**2*L1(X)(Z(X)-1) (+ alpha**3*lookup)
 	// on the odd cosets of (Z/8mZ)/(Z/mZ)
 	nn := uint64(64 - bits.TrailingZeros64(pk.DomainH.Cardinality))
 
@@ -786,7 +909,12 @@ func computeH(pk *ProvingKey, constraintsInd, constraintOrdering, evalBZ polynom
 		var t fr.Element
 		for i := uint64(start); i < uint64(end); i++ {
 			t.Sub(&evalBZ[i], &one) // evaluates L1*(z-1) on the odd cosets of (Z/8mZ)/(Z/mZ)
-			h[i].Mul(&startsAtOne[i], &alpha).Mul(&h[i], &t).
+			h[i].Mul(&startsAtOne[i], &t)
+			if constraintsLookup != nil {
+				t.Mul(&constraintsLookup[i], &alpha)
+				h[i].Add(&h[i], &t)
+			}
+			h[i].Mul(&h[i], &alpha).
 				Add(&h[i], &constraintOrdering[i]).
 				Mul(&h[i], &alpha).
 				Add(&h[i], &constraintsInd[i])
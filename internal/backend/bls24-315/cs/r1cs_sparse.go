@@ -24,11 +24,14 @@ import (
 	"math/big"
 	"os"
 	"strings"
+	"sync/atomic"
 	"text/template"
 
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/internal/backend/common"
 	"github.com/consensys/gnark/internal/backend/compiled"
 	"github.com/consensys/gnark/internal/backend/ioutils"
+	gnarkio "github.com/consensys/gnark/io"
 
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
 )
@@ -39,6 +42,8 @@ type SparseR1CS struct {
 
 	Coefficients []fr.Element // coefficients in the constraints
 	loggerOut    io.Writer
+
+	ccsDigest atomic.Value // []byte, set by the first solve with backend.WithCheckpoint
 }
 
 // NewSparseR1CS returns a new SparseR1CS and sets r1cs.Coefficient (fr.Element) from provided big.Int values
@@ -76,7 +81,7 @@ func (cs *SparseR1CS) Solve(witness []fr.Element, opt backend.ProverOption) ([]f
 	}
 
 	// keep track of wire that have a value
-	solution, err := newSolution(nbVariables, opt.HintFunctions, cs.Coefficients)
+	solution, err := newSolution(nbVariables, nil, cs.ReferencedHints(), opt, cs.Coefficients, cs.MHints)
 	if err != nil {
 		return solution.values, err
 	}
@@ -84,12 +89,62 @@ func (cs *SparseR1CS) Solve(witness []fr.Element, opt backend.ProverOption) ([]f
 	// solution.values = [publicInputs | secretInputs | internalVariables ] -> we fill publicInputs | secretInputs
 	copy(solution.values, witness)
 	for i := 0; i < len(witness); i++ {
-		solution.solved[i] = true
+		solution.Solved[i] = true
 	}
 
 	// keep track of the number of wire instantiations we do, for a sanity check to ensure
 	// we instantiated all wires
-	solution.nbSolved += len(witness)
+	solution.NbSolved += len(witness)
+
+	// the public outputs are solved by their hint, whatever their value in the witness
+	for _, o := range cs.PublicOutputs {
+		solution.Solved[o.Index] = false
+		solution.NbSolved--
+	}
+
+	// with a shared solution, the wires solved by the R1CS solver are copied
+	if opt.SharedSolution != nil {
+		if err := seedSolution(&solution, witness, opt.SharedSolution); err != nil {
+			return solution.values, err
+		}
+	}
+
+	// with a solution cache directory, the solution of the witness stored by a previous solve is loaded,
+	// and checked against a sample of the constraints
+	var cached *checkpointer
+	start := 0
+	if opt.SolutionCacheDir != "" {
+		digest, err := cs.checkpointDigest()
+		if err != nil {
+			return solution.values, err
+		}
+		cached = newSolutionCacheEntry(digest, witness, opt)
+		if start, err = cached.load(&solution, len(cs.Constraints), nil, nil, nil); err != nil {
+			return solution.values, err
+		}
+		if start == len(cs.Constraints) {
+			if err := cs.checkCachedSolution(&solution, opt.SolutionCacheChecks); err != nil {
+				// the entry is stale: it is removed, and the witness solved again
+				if err := cached.discard(err); err != nil {
+					return solution.values, err
+				}
+				return cs.Solve(witness, opt)
+			}
+		}
+	}
+
+	// with a checkpoint, the solve resumes after the constraints it records
+	var cp *checkpointer
+	if opt.CheckpointDir != "" && start == 0 {
+		digest, err := cs.checkpointDigest()
+		if err != nil {
+			return solution.values, err
+		}
+		cp = newCheckpointer(digest, witness, opt)
+		if start, err = cp.load(&solution, len(cs.Constraints), nil, nil, nil); err != nil {
+			return solution.values, err
+		}
+	}
 
 	// defer log printing once all solution.values are computed
 	defer solution.printLogs(opt.LoggerOut, cs.Logs)
@@ -101,7 +156,10 @@ func (cs *SparseR1CS) Solve(witness []fr.Element, opt backend.ProverOption) ([]f
 	}
 
 	// loop through the constraints to solve the variables
-	for i := 0; i < len(cs.Constraints); i++ {
+	for i := start; i < len(cs.Constraints); i++ {
+		if cp != nil && cp.due(i) {
+			cp.save(&solution, i, nil, nil, nil)
+		}
 		if err := cs.solveConstraint(cs.Constraints[i], &solution, coefficientsNegInv); err != nil {
 			return solution.values, fmt.Errorf("constraint %d: %w", i, err)
 		}
@@ -114,15 +172,63 @@ func (cs *SparseR1CS) Solve(witness []fr.Element, opt backend.ProverOption) ([]f
 		}
 	}
 
+	// check the lookups
+	if err := cs.checkLookups(&solution); err != nil {
+		return solution.values, err
+	}
+
 	// sanity check; ensure all wires are marked as "instantiated"
-	if !solution.isValid() {
+	if !solution.IsValid() {
 		panic("solver didn't instantiate all wires")
 	}
 
+	if cp != nil {
+		cp.remove()
+	}
+	if cached != nil && start != len(cs.Constraints) {
+		cached.save(&solution, len(cs.Constraints), nil, nil, nil)
+	}
+	if opt.PublicOutputs != nil && len(cs.PublicOutputs) != 0 {
+		opt.PublicOutputs(solution.publicOutputs(cs.PublicOutputs, 0))
+	}
+
 	return solution.values, nil
 
 }
 
+// seedSolution sets the wires of solution mapped to the wires of the R1CS in shared, see
+// backend.WithSharedSolution. The inputs (set from witness) must have the same values in both.
+func seedSolution(solution *solution, witness []fr.Element, shared *backend.SharedSolution) error {
+	v, ok := shared.Load()
+	if !ok {
+		// the R1CS wasn't solved
+		return nil
+	}
+	values, ok := v.([]fr.Element)
+	mapping := shared.Mapping()
+	if !ok || len(mapping) != len(solution.values) {
+		return fmt.Errorf("%w: %d wires, expected %d", backend.ErrSharedSolutionMismatch, len(mapping), len(solution.values))
+	}
+	for i, w := range mapping {
+		if w < 0 {
+			continue
+		}
+		if w >= len(values) {
+			return fmt.Errorf("%w: wire %d is mapped to %d, the R1CS has %d wires", backend.ErrSharedSolutionMismatch, i, w, len(values))
+		}
+		if i < len(witness) {
+			if !values[w].Equal(&witness[i]) {
+				return fmt.Errorf("%w: input %d differs", backend.ErrSharedSolutionMismatch, i)
+			}
+			continue
+		}
+		if err := solution.set(i, values[w]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // computeHints computes wires associated with a hint function, if any
 // if there is no remaining wire to solve, returns -1
 // else returns the wire position (L -> 0, R -> 1, O -> 2)
@@ -130,7 +236,7 @@ func (cs *SparseR1CS) computeHints(c compiled.SparseR1C, solution *solution) (in
 	r := -1
 	lID, rID, oID := c.L.VariableID(), c.R.VariableID(), c.O.VariableID()
 
-	if (c.L.CoeffID() != 0 || c.M[0].CoeffID() != 0) && !solution.solved[lID] {
+	if (c.L.CoeffID() != 0 || c.M[0].CoeffID() != 0) && !solution.Solved[lID] {
 		// check if it's a hint
 		if hint, ok := cs.MHints[lID]; ok {
 			if err := solution.solveWithHint(lID, hint); err != nil {
@@ -142,7 +248,7 @@ func (cs *SparseR1CS) computeHints(c compiled.SparseR1C, solution *solution) (in
 
 	}
 
-	if (c.R.CoeffID() != 0 || c.M[1].CoeffID() != 0) && !solution.solved[rID] {
+	if (c.R.CoeffID() != 0 || c.M[1].CoeffID() != 0) && !solution.Solved[rID] {
 		// check if it's a hint
 		if hint, ok := cs.MHints[rID]; ok {
 			if err := solution.solveWithHint(rID, hint); err != nil {
@@ -153,7 +259,7 @@ func (cs *SparseR1CS) computeHints(c compiled.SparseR1C, solution *solution) (in
 		}
 	}
 
-	if (c.O.CoeffID() != 0) && !solution.solved[oID] {
+	if (c.O.CoeffID() != 0) && !solution.Solved[oID] {
 		// check if it's a hint
 		if hint, ok := cs.MHints[oID]; ok {
 			if err := solution.solveWithHint(oID, hint); err != nil {
@@ -185,7 +291,7 @@ func (cs *SparseR1CS) solveConstraint(c compiled.SparseR1C, solution *solution,
 	}
 
 	if lro == 0 { // we solve for L: u1L+u2R+u3LR+u4O+k=0 => L(u1+u3R)+u2R+u4O+k = 0
-		if !solution.solved[c.R.VariableID()] {
+		if !solution.Solved[c.R.VariableID()] {
 			panic("R wire should be instantiated when we solve L")
 		}
 		var u1, u2, u3, den, num, v1, v2 fr.Element
@@ -200,8 +306,7 @@ func (cs *SparseR1CS) solveConstraint(c compiled.SparseR1C, solution *solution,
 
 		// TODO find a way to do lazy div (/ batch inversion)
 		num.Div(&num, &den).Neg(&num)
-		solution.set(c.L.VariableID(), num)
-		return nil
+		return solution.set(c.L.VariableID(), num)
 
 	}
 	// O we solve for O
@@ -217,8 +322,42 @@ func (cs *SparseR1CS) solveConstraint(c compiled.SparseR1C, solution *solution,
 	o.Mul(&m0, &m1).Add(&o, &l).Add(&o, &r).Add(&o, &cs.Coefficients[c.K])
 	o.Mul(&o, &coefficientsNegInv[cID])
 
-	solution.set(vID, o)
+	return solution.set(vID, o)
+}
 
+// checkLookups verifies that the L wire of each lookup constraint is an entry of its table
+func (cs *SparseR1CS) checkLookups(solution *solution) error {
+	if len(cs.Lookups) == 0 {
+		return nil
+	}
+	tables := make([]map[fr.Element]struct{}, len(cs.Tables))
+	for i, t := range cs.Tables {
+		tables[i] = make(map[fr.Element]struct{}, len(t.Entries))
+		for _, cID := range t.Entries {
+			tables[i][cs.Coefficients[cID]] = struct{}{}
+		}
+	}
+
+	for _, l := range cs.Lookups {
+		vID := cs.Constraints[l.Constraint].L.VariableID()
+		if !solution.Solved[vID] {
+			// the wire is only used in lookups
+			hint, ok := cs.MHints[vID]
+			if !ok {
+				return fmt.Errorf("constraint %d: lookup wire is not instantiated", l.Constraint)
+			}
+			if err := solution.solveWithHint(vID, hint); err != nil {
+				return fmt.Errorf("constraint %d: %w", l.Constraint, err)
+			}
+		}
+		if _, ok := tables[l.Table][solution.values[vID]]; !ok {
+			if dID, ok := cs.MDebug[l.Constraint]; ok {
+				debugInfoStr := solution.logValue(cs.DebugInfo[dID])
+				return fmt.Errorf("%w: %s", ErrUnsatisfiedConstraint, debugInfoStr)
+			}
+			return fmt.Errorf("%w: %s is not in table %s", ErrUnsatisfiedConstraint, solution.values[vID].String(), cs.Tables[l.Table].Name)
+		}
+	}
 	return nil
 }
 
@@ -229,6 +368,71 @@ func (cs *SparseR1CS) IsSolved(witness []fr.Element, opt backend.ProverOption) e
 	return err
 }
 
+// AnalyzeWitness returns backend.ErrWitnessAnalysisUnsupported: the multi exponentiations of PlonK
+// are over polynomials, not over the values of the wires
+func (cs *SparseR1CS) AnalyzeWitness(values []*big.Int) (*backend.WitnessAnalysis, error) {
+	return nil, backend.ErrWitnessAnalysisUnsupported
+}
+
+// CheckAssignment evaluates every constraint, and lookup, against values, the full assignment of
+// the wires (public, secret then internal wires, as returned by Solve), without solving. It returns
+// which constraints are satisfied, and an *backend.UnsatisfiedAssignmentError counting the
+// unsatisfied ones, if any.
+func (cs *SparseR1CS) CheckAssignment(values []*big.Int) ([]bool, error) {
+	return cs.CheckAssignmentUpTo(values, 0)
+}
+
+// CheckAssignmentUpTo is CheckAssignment, stopping once maxFailures unsatisfied constraints are
+// found, see frontend.CompiledConstraintSystem
+func (cs *SparseR1CS) CheckAssignmentUpTo(values []*big.Int, maxFailures int) ([]bool, error) {
+	solution, err := assignmentSolution(values, cs.NbPublicVariables+cs.NbSecretVariables+cs.NbInternalVariables, cs.Coefficients)
+	if err != nil {
+		return nil, err
+	}
+
+	// tables of the lookup constraints
+	lookups := make(map[int]map[fr.Element]struct{}, len(cs.Lookups))
+	if len(cs.Lookups) != 0 {
+		tables := make([]map[fr.Element]struct{}, len(cs.Tables))
+		for i, t := range cs.Tables {
+			tables[i] = make(map[fr.Element]struct{}, len(t.Entries))
+			for _, cID := range t.Entries {
+				tables[i][cs.Coefficients[cID]] = struct{}{}
+			}
+		}
+		for _, l := range cs.Lookups {
+			lookups[l.Constraint] = tables[l.Table]
+		}
+	}
+
+	return common.CheckAssignment(len(cs.Constraints), maxFailures, func(i int) bool {
+		c := cs.Constraints[i]
+		if cs.checkConstraint(c, &solution) != nil {
+			return false
+		}
+		if table, ok := lookups[i]; ok {
+			_, ok = table[solution.values[c.L.VariableID()]]
+			return ok
+		}
+		return true
+	})
+}
+
+// checkCachedSolution checks a solution loaded from the solution cache directory against nbChecks
+// constraints, see backend.WithSolutionCacheChecks
+func (cs *SparseR1CS) checkCachedSolution(solution *solution, nbChecks int) error {
+	indexes, err := sampleConstraints(len(cs.Constraints), nbChecks)
+	if err != nil {
+		return err
+	}
+	for _, i := range indexes {
+		if err := cs.checkConstraint(cs.Constraints[i], solution); err != nil {
+			return fmt.Errorf("constraint %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
 // checkConstraint verifies that the constraint holds
 func (cs *SparseR1CS) checkConstraint(c compiled.SparseR1C, solution *solution) error {
 	l := solution.computeTerm(c.L)
@@ -254,8 +458,22 @@ func (cs *SparseR1CS) checkConstraint(c compiled.SparseR1C, solution *solution)
 
 }
 
-// ToHTML returns an HTML human-readable representation of the constraint system
-func (cs *SparseR1CS) ToHTML(w io.Writer) error {
+// ToHTML returns an HTML human-readable representation of the constraint system: a page rendering
+// the constraints a page at a time from their JSON export (see ToJSON), or a static table of all the
+// constraints if opt.Table is set
+func (cs *SparseR1CS) ToHTML(w io.Writer, opts ...func(opt *compiled.ExportOptions) error) error {
+	opt, err := compiled.NewExportOptions(opts...)
+	if err != nil {
+		return err
+	}
+	if !opt.Table {
+		data, err := cs.exportConstraints(opt)
+		if err != nil {
+			return err
+		}
+		return compiled.WritePage(w, data, opt)
+	}
+
 	t, err := template.New("scs.html").Funcs(template.FuncMap{
 		"toHTML":      toHTMLTerm,
 		"toHTMLCoeff": toHTMLCoeff,
@@ -269,6 +487,34 @@ func (cs *SparseR1CS) ToHTML(w io.Writer) error {
 	return t.Execute(w, cs)
 }
 
+// ToJSON writes the JSON export of the constraints of the constraint system, see compiled.ConstraintsJSON
+func (cs *SparseR1CS) ToJSON(w io.Writer, opts ...func(opt *compiled.ExportOptions) error) error {
+	opt, err := compiled.NewExportOptions(opts...)
+	if err != nil {
+		return err
+	}
+	data, err := cs.exportConstraints(opt)
+	if err != nil {
+		return err
+	}
+	_, err = data.WriteTo(w)
+	return err
+}
+
+// exportConstraints returns the JSON export of the constraints in the range of opt, the wire IDs offset
+// by 1 to match the R1CS, and k as a constant
+func (cs *SparseR1CS) exportConstraints(opt compiled.ExportOptions) (*compiled.ConstraintsJSON, error) {
+	shift := func(t compiled.Term) compiled.LinearExpression {
+		t.SetVariableID(t.VariableID() + 1)
+		return compiled.LinearExpression{t}
+	}
+	return cs.NewConstraintsJSON(compiled.SparseR1CSLayout, len(cs.Constraints), coefficientStrings(cs.Coefficients), opt, func(i int) []compiled.LinearExpression {
+		c := &cs.Constraints[i]
+		k := compiled.LinearExpression{compiled.Pack(0, c.K, compiled.Public)}
+		return []compiled.LinearExpression{shift(c.L), shift(c.R), shift(c.M[0]), shift(c.M[1]), shift(c.O), k}
+	})
+}
+
 func toHTMLTerm(t compiled.Term, coeffs []fr.Element, MHints map[int]compiled.Hint) string {
 	var sbb strings.Builder
 	termToHTML(t, &sbb, coeffs, MHints, true)
@@ -302,7 +548,13 @@ func (cs *SparseR1CS) CurveID() ecc.ID {
 	return ecc.BLS24_315
 }
 
-// WriteTo encodes SparseR1CS into provided io.Writer using cbor
+// sparseR1CSEncoding is the serialization of a SparseR1CS, its constraints in a compiled.GateList
+type sparseR1CSEncoding struct {
+	compiled.SparseR1CS
+	Coefficients []fr.Element
+}
+
+// WriteTo encodes SparseR1CS into provided io.Writer using cbor, in the format compiled.SparseR1CSVersion
 func (cs *SparseR1CS) WriteTo(w io.Writer) (int64, error) {
 	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
 	enc, err := cbor.CoreDetEncOptions().EncMode()
@@ -312,19 +564,32 @@ func (cs *SparseR1CS) WriteTo(w io.Writer) (int64, error) {
 	encoder := enc.NewEncoder(&_w)
 
 	// encode our object
-	err = encoder.Encode(cs)
+	err = encoder.Encode(&sparseR1CSEncoding{SparseR1CS: cs.SparseR1CS.Compact(), Coefficients: cs.Coefficients})
 	return _w.N, err
 }
 
-// ReadFrom attempts to decode SparseR1CS from io.Reader using cbor
+// ReadFrom attempts to decode SparseR1CS from io.Reader using cbor. It reads the SparseR1CS serialized
+// in the previous formats, and checks the gates of the format compiled.SparseR1CSVersion
 func (cs *SparseR1CS) ReadFrom(r io.Reader) (int64, error) {
+	return cs.readFrom(r, true)
+}
+
+// UnsafeReadFrom is ReadFrom, without checking that the gates reference existing wires and coefficients
+func (cs *SparseR1CS) UnsafeReadFrom(r io.Reader) (int64, error) {
+	return cs.readFrom(r, false)
+}
+
+func (cs *SparseR1CS) readFrom(r io.Reader, check bool) (int64, error) {
 	dm, err := cbor.DecOptions{MaxArrayElements: 134217728}.DecMode()
 	if err != nil {
 		return 0, err
 	}
-	decoder := dm.NewDecoder(r)
-	err = decoder.Decode(cs)
-	return int64(decoder.NumBytesRead()), err
+	// the decoder buffers the whole constraint system, up to gnarkio.MaxReadBytes
+	decoder := dm.NewDecoder(gnarkio.NewLimitReader(r))
+	if err := decoder.Decode(cs); err != nil {
+		return int64(decoder.NumBytesRead()), err
+	}
+	return int64(decoder.NumBytesRead()), cs.Expand(len(cs.Coefficients), check)
 }
 
 // SetLoggerOutput replace existing logger output with provided one
@@ -21,11 +21,10 @@ import (
 	"fmt"
 	"io"
 	"math/big"
-	"reflect"
-	"runtime"
-	"sync"
 
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/internal/backend/common"
 	"github.com/consensys/gnark/internal/backend/compiled"
 
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
@@ -38,55 +37,109 @@ var ErrUnsatisfiedConstraint = errors.New("constraint is not satisfied")
 
 // solution represents elements needed to compute
 // a solution to a R1CS or SparseR1CS
+//
+// The values of the wires are field elements; the rest (solved wires, hints, logs) is
+// common to the curves, see common.Solution.
 type solution struct {
+	common.Solution
+
+	// if Layout is set, values holds the wires which are not packed in Bits
 	values, coefficients []fr.Element
-	solved               []bool
-	nbSolved             int
-	mHintsFunctions      map[hint.ID]hint.Function
+
+	mHints map[int]compiled.Hint // hints of the constraint system, to solve the hints a hint depends on
 }
 
-func newSolution(nbWires int, hintFunctions []hint.Function, coefficients []fr.Element) (solution, error) {
-	s := solution{
-		values:          make([]fr.Element, nbWires),
-		coefficients:    coefficients,
-		solved:          make([]bool, nbWires),
-		mHintsFunctions: make(map[hint.ID]hint.Function, len(hintFunctions)+2),
+// newSolution returns a solution of nbWires wires. If layout is not nil, the boolean wires are packed.
+// The hint functions are checked against the IDs referenced by the constraint system, see common.NewSolution.
+func newSolution(nbWires int, layout *compiled.BooleanLayout, referenced map[hint.ID]string, opt backend.ProverOption, coefficients []fr.Element, mHints map[int]compiled.Hint) (solution, error) {
+	s, err := common.NewSolution(curve.ID, nbWires, layout, referenced, opt)
+	if err != nil {
+		return solution{}, err
 	}
+	return solution{
+		Solution:     s,
+		coefficients: coefficients,
+		values:       make([]fr.Element, s.NbValues()),
+		mHints:       mHints,
+	}, nil
+}
 
-	s.mHintsFunctions[hint.UUID(hint.IsZero)] = hint.IsZero
-	s.mHintsFunctions[hint.UUID(hint.IthBit)] = hint.IthBit
-
-	for i := 0; i < len(hintFunctions); i++ {
-		id := hint.UUID(hintFunctions[i])
-		if _, ok := s.mHintsFunctions[id]; ok {
-			name := runtime.FuncForPC(reflect.ValueOf(hintFunctions[i]).Pointer()).Name()
-			return solution{}, fmt.Errorf("duplicate hint function with id %d - name %s", uint32(id), name)
+// set sets the value of the wire id. It returns an error if the wire is packed (see newSolution)
+// and the value is not boolean: the constraint making the wire boolean can't be satisfied.
+func (s *solution) set(id int, value fr.Element) error {
+	if s.Solved[id] {
+		panic("solving the same wire twice should never happen.")
+	}
+	if s.Layout != nil {
+		if s.Layout.IsBoolean(id) {
+			var one fr.Element
+			one.SetOne()
+			if value.Equal(&one) {
+				s.Bits.Set(s.Layout.Index(id))
+			} else if !value.IsZero() {
+				return fmt.Errorf("%w: wire %d is constrained to be boolean, got %s", ErrUnsatisfiedConstraint, id, value.String())
+			}
+		} else {
+			s.values[s.Layout.Index(id)] = value
 		}
-		s.mHintsFunctions[id] = hintFunctions[i]
+	} else {
+		s.values[id] = value
 	}
+	s.MarkSolved(id)
+	return nil
+}
 
-	return s, nil
+// get returns the value of the wire id
+func (s *solution) get(id int) fr.Element {
+	if s.Layout == nil {
+		return s.values[id]
+	}
+	var res fr.Element
+	if s.Layout.IsBoolean(id) {
+		if s.Bits.Get(s.Layout.Index(id)) {
+			res.SetOne()
+		}
+		return res
+	}
+	return s.values[s.Layout.Index(id)]
 }
 
-func (s *solution) set(id int, value fr.Element) {
-	if s.solved[id] {
-		panic("solving the same wire twice should never happen.")
+// wireValues returns the values of all the wires, unpacking the boolean wires if needed
+func (s *solution) wireValues() []fr.Element {
+	if s.Layout == nil {
+		return s.values
+	}
+	res := make([]fr.Element, len(s.Solved))
+	for i := 0; i < len(res); i++ {
+		res[i] = s.get(i)
 	}
-	s.values[id] = value
-	s.solved[id] = true
-	s.nbSolved++
+	return res
 }
 
-func (s *solution) isValid() bool {
-	return s.nbSolved == len(s.values)
+// publicOutputs returns the values of the public outputs of the constraint system, indexed by their
+// name; the wire of an output is its witness index plus offset. See backend.WithPublicOutputs.
+func (s *solution) publicOutputs(outputs []compiled.PublicOutput, offset int) map[string]*big.Int {
+	res := make(map[string]*big.Int, len(outputs))
+	for _, o := range outputs {
+		v := s.get(o.Index + offset)
+		res[o.Name] = new(big.Int)
+		v.ToBigIntRegular(res[o.Name])
+	}
+	return res
 }
 
 // computeTerm computes coef*variable
 func (s *solution) computeTerm(t compiled.Term) fr.Element {
 	cID, vID, _ := t.Unpack()
-	if cID != 0 && !s.solved[vID] {
+	if cID != 0 && !s.Solved[vID] {
 		panic("computing a term with an unsolved wire")
 	}
+	if s.Layout != nil {
+		if s.Layout.IsBoolean(vID) {
+			return s.computeBooleanTerm(cID, s.Bits.Get(s.Layout.Index(vID)))
+		}
+		vID = s.Layout.Index(vID)
+	}
 	switch cID {
 	case compiled.CoeffIdZero:
 		return fr.Element{}
@@ -107,155 +160,155 @@ func (s *solution) computeTerm(t compiled.Term) fr.Element {
 	}
 }
 
-// solveHint compute solution.values[vID] using provided solver hint
-func (s *solution) solveWithHint(vID int, h compiled.Hint) error {
-	// ensure hint function was provided
-	f, ok := s.mHintsFunctions[h.ID]
-	if !ok {
-		return errors.New("missing hint function")
+// computeBooleanTerm computes coef*b without multiplication: the result is 0 or the coefficient
+func (s *solution) computeBooleanTerm(cID int, b bool) fr.Element {
+	var res fr.Element
+	if !b {
+		return res
 	}
-
-	// compute values for all inputs.
-	inputs := make([]*big.Int, len(h.Inputs))
-	for i := 0; i < len(inputs); i++ {
-		inputs[i] = bigIntPool.Get().(*big.Int)
-		inputs[i].SetUint64(0)
+	switch cID {
+	case compiled.CoeffIdZero:
+	case compiled.CoeffIdOne:
+		res.SetOne()
+	case compiled.CoeffIdTwo:
+		res.SetUint64(2)
+	case compiled.CoeffIdMinusOne:
+		res.SetOne()
+		res.Neg(&res)
+	default:
+		res = s.coefficients[cID]
 	}
-	lambda := bigIntPool.Get().(*big.Int)
+	return res
+}
 
-	for i := 0; i < len(h.Inputs); i++ {
-		// input is a linear expression, we must compute the value
-		for j := 0; j < len(h.Inputs[i]); j++ {
-			ciID, viID, visibility := h.Inputs[i][j].Unpack()
-			if visibility == compiled.Virtual {
-				// we have a constant, just take the coefficient value
-				s.coefficients[ciID].ToBigIntRegular(lambda)
-				inputs[i].Add(inputs[i], lambda)
-				continue
-			}
-			if !s.solved[viID] {
-				// release objects into pool
-				bigIntPool.Put(lambda)
-				for i := 0; i < len(inputs); i++ {
-					bigIntPool.Put(inputs[i])
-				}
-				return errors.New("expected wire to be instantiated while evaluating hint")
-			}
-			v := s.computeTerm(h.Inputs[i][j])
-			v.ToBigIntRegular(lambda)
-			inputs[i].Add(inputs[i], lambda)
-		}
+// computeLinearTerm computes the value of a term of a linear expression: coef*variable, or
+// the coefficient if the term is a constant
+func (s *solution) computeLinearTerm(t compiled.Term) fr.Element {
+	if t.VariableVisibility() == compiled.Virtual {
+		return s.coefficients[t.CoeffID()]
 	}
+	return s.computeTerm(t)
+}
 
-	// use lambda as the result.
-	lambda.SetUint64(0)
-
-	// ensure our inputs are mod q
-	q := fr.Modulus()
-	for i := 0; i < len(inputs); i++ {
-		// note since we're only doing additions up there, we may want to avoid the use of Mod
-		// here in favor of Cmp & Sub
-		inputs[i].Mod(inputs[i], q)
-	}
+// TermValue implements common.Wires
+func (s *solution) TermValue(t compiled.Term, res *big.Int) {
+	v := s.computeLinearTerm(t)
+	v.ToBigIntRegular(res)
+}
 
-	err := f(curve.ID, inputs, lambda)
+// SetValue implements common.Wires
+func (s *solution) SetValue(id int, v *big.Int) error {
+	var e fr.Element
+	e.SetBigInt(v)
+	return s.set(id, e)
+}
 
-	var v fr.Element
-	v.SetBigInt(lambda)
+// CoefficientString implements common.Wires
+func (s *solution) CoefficientString(cID int) string {
+	return s.coefficients[cID].String()
+}
 
-	// release objects into pool
-	bigIntPool.Put(lambda)
-	for i := 0; i < len(inputs); i++ {
-		bigIntPool.Put(inputs[i])
-	}
+// WireString implements common.Wires
+func (s *solution) WireString(vID int) string {
+	v := s.get(vID)
+	return v.String()
+}
 
-	if err != nil {
-		return err
+// SumString implements common.Wires
+func (s *solution) SumString(terms []compiled.Term) string {
+	var sum fr.Element
+	for _, t := range terms {
+		v := s.computeLinearTerm(t)
+		sum.Add(&sum, &v)
 	}
-
-	s.set(vID, v)
-	return nil
+	return sum.String()
 }
 
-func (s *solution) printLogs(w io.Writer, logs []compiled.LogEntry) {
-	if w == nil {
-		return
+// assignmentSolution returns a solution holding the full assignment values (public, secret then
+// internal wires), reduced modulo the scalar field, to evaluate constraints without solving them
+func assignmentSolution(values []*big.Int, nbWires int, coefficients []fr.Element) (solution, error) {
+	if len(values) != nbWires {
+		return solution{}, fmt.Errorf("invalid assignment size, got %d, expected %d wires", len(values), nbWires)
 	}
-
-	for i := 0; i < len(logs); i++ {
-		logLine := s.logValue(logs[i])
-		_, _ = io.WriteString(w, logLine)
+	s := solution{
+		coefficients: coefficients,
+		values:       make([]fr.Element, nbWires),
+	}
+	s.Solved = make([]bool, nbWires)
+	for i, v := range values {
+		if v == nil {
+			return solution{}, fmt.Errorf("wire %d is not assigned", i)
+		}
+		s.values[i].SetBigInt(v)
+		s.MarkSolved(i)
 	}
+	return s, nil
 }
 
-const unsolvedVariable = "<unsolved>"
+// solveHint compute solution.values[vID] using provided solver hint
+// the other wires computed by the hint function (h.Wires) are set too, after the hints it depends on
+// if they are not solved yet (see compiled.LinkHints)
+func (s *solution) solveWithHint(vID int, h compiled.Hint) error {
+	return s.solveWithHintDepth(vID, h, 0)
+}
 
-func (s *solution) logValue(log compiled.LogEntry) string {
-	var toResolve []interface{}
-	var (
-		isEval       bool
-		eval         fr.Element
-		missingValue bool
-	)
-	for j := 0; j < len(log.ToResolve); j++ {
-		if log.ToResolve[j] == compiled.TermDelimitor {
-			// this is a special case where we want to evaluate the following terms until the next delimitor.
-			if !isEval {
-				isEval = true
-				missingValue = false
-				eval.SetZero()
-				continue
-			}
-			isEval = false
-			if missingValue {
-				toResolve = append(toResolve, unsolvedVariable)
-			} else {
-				// we have to append our accumulator
-				toResolve = append(toResolve, eval.String())
-			}
+func (s *solution) solveWithHintDepth(vID int, h compiled.Hint, depth int) error {
+	if depth > len(s.mHints) {
+		// only a constraint system which wasn't compiled by gnark can have cyclic dependencies
+		return fmt.Errorf("hint of wire %d: cyclic hint dependencies", vID)
+	}
+	for _, d := range h.Deps {
+		if s.Solved[d] {
 			continue
 		}
-		cID, vID, visibility := log.ToResolve[j].Unpack()
+		if err := s.solveWithHintDepth(d, s.mHints[d], depth+1); err != nil {
+			return err
+		}
+	}
 
-		if isEval {
-			// we are evaluating
+	if s.ConstantTime {
+		if f, ok := constantTimeHints[h.ID]; ok {
+			return s.solveWithConstantTimeHint(f, vID, h)
+		}
+	}
+	return s.SolveWithHint(s, vID, h)
+}
+
+// solveWithConstantTimeHint is solveWithHint for a builtin hint, computed by f on field elements
+func (s *solution) solveWithConstantTimeHint(f constantTimeHint, vID int, h compiled.Hint) error {
+	inputs := make([]fr.Element, len(h.Inputs))
+	for i := 0; i < len(h.Inputs); i++ {
+		for j := 0; j < len(h.Inputs[i]); j++ {
+			ciID, viID, visibility := h.Inputs[i][j].Unpack()
 			if visibility == compiled.Virtual {
-				// just add the constant
-				eval.Add(&eval, &s.coefficients[cID])
+				inputs[i].Add(&inputs[i], &s.coefficients[ciID])
 				continue
 			}
-			if !s.solved[vID] {
-				missingValue = true
-				continue
+			if !s.Solved[viID] {
+				return errors.New("expected wire to be instantiated while evaluating hint")
 			}
-			tv := s.computeTerm(log.ToResolve[j])
-			eval.Add(&eval, &tv)
-			continue
+			v := s.computeTerm(h.Inputs[i][j])
+			inputs[i].Add(&inputs[i], &v)
 		}
+	}
 
-		if visibility == compiled.Virtual {
-			// it's just a constant
-			if cID == compiled.CoeffIdMinusOne {
-				toResolve = append(toResolve, "-1")
-			} else {
-				toResolve = append(toResolve, s.coefficients[cID].String())
-			}
-			continue
-		}
-		if !(cID == compiled.CoeffIdMinusOne || cID == compiled.CoeffIdOne) {
-			toResolve = append(toResolve, s.coefficients[cID].String())
-		}
-		if !s.solved[vID] {
-			toResolve = append(toResolve, unsolvedVariable)
-		} else {
-			toResolve = append(toResolve, s.values[vID].String())
+	outputs := make([]fr.Element, len(h.Wires))
+	if err := f(inputs, outputs); err != nil {
+		hf, _ := s.HintFunction(h.ID)
+		return fmt.Errorf("hint %s (wire %d): %w", hf, vID, err)
+	}
+	for i := 0; i < len(outputs); i++ {
+		if err := s.set(h.Wires[i], outputs[i]); err != nil {
+			return err
 		}
 	}
-	return fmt.Sprintf(log.Format, toResolve...)
+	return nil
 }
 
-var bigIntPool = sync.Pool{
-	New: func() interface{} {
-		return new(big.Int)
-	},
+func (s *solution) printLogs(w io.Writer, logs []compiled.LogEntry) {
+	s.PrintLogs(w, s, logs)
+}
+
+func (s *solution) logValue(log compiled.LogEntry) string {
+	return s.LogValue(s, log)
 }
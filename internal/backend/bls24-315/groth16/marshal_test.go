@@ -22,9 +22,12 @@ import (
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/fft"
 
 	"bytes"
+	"errors"
 	"math/big"
 	"reflect"
 
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/internal/backend/common"
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/prop"
 
@@ -86,6 +89,73 @@ func TestProofSerialization(t *testing.T) {
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
 
+func TestProofCBOR(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 10
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("Proof -> CBOR -> Proof should stay constant", prop.ForAll(
+		func(ar, krs curve.G1Affine, bs curve.G2Affine) bool {
+			proof := Proof{Ar: ar, Krs: krs, Bs: bs}
+			data, err := proof.MarshalCBOR()
+			if err != nil {
+				return false
+			}
+			var decoded Proof
+			if err := decoded.UnmarshalCBOR(data); err != nil {
+				return false
+			}
+			return reflect.DeepEqual(&proof, &decoded)
+		},
+		GenG1(),
+		GenG1(),
+		GenG2(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+
+	// a point with trailing bytes (or uncompressed) is rejected
+	_, _, g1, g2 := curve.Generators()
+	proof := Proof{Ar: g1, Krs: g1, Bs: g2}
+	data, err := common.CBOREncMode.Marshal(proofCBOR{
+		CBORHeader: common.NewCBORHeader(common.CBORGroth16Proof, curve.ID),
+		Ar:         append(compressedG1(&proof.Ar), 0),
+		Bs:         compressedG2(&proof.Bs),
+		Krs:        compressedG1(&proof.Krs),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := new(Proof).UnmarshalCBOR(data); err == nil {
+		t.Fatal("expected an error with an invalid point size")
+	}
+
+	// so is another type of object
+	data, err = common.CBOREncMode.Marshal(proofCBOR{
+		CBORHeader: common.NewCBORHeader(common.CBORPlonkProof, curve.ID),
+		Ar:         compressedG1(&proof.Ar),
+		Bs:         compressedG2(&proof.Bs),
+		Krs:        compressedG1(&proof.Krs),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := new(Proof).UnmarshalCBOR(data); !errors.Is(err, backend.ErrCBORHeaderMismatch) {
+		t.Fatalf("expected a header mismatch, got %v", err)
+	}
+}
+
+func compressedG1(p *curve.G1Affine) []byte {
+	b := p.Bytes()
+	return b[:]
+}
+
+func compressedG2(p *curve.G2Affine) []byte {
+	b := p.Bytes()
+	return b[:]
+}
+
 func TestVerifyingKeySerialization(t *testing.T) {
 	parameters := gopter.DefaultTestParameters()
 	parameters.MinSuccessfulTests = 10
@@ -107,15 +177,6 @@ func TestVerifyingKeySerialization(t *testing.T) {
 			vk.G2.Beta = p2
 			vk.G2.Delta = p2
 
-			var err error
-			vk.e, err = curve.Pair([]curve.G1Affine{vk.G1.Alpha}, []curve.G2Affine{vk.G2.Beta})
-			if err != nil {
-				t.Fatal(err)
-				return false
-			}
-			vk.G2.deltaNeg.Neg(&vk.G2.Delta)
-			vk.G2.gammaNeg.Neg(&vk.G2.Gamma)
-
 			vk.G1.K = make([]curve.G1Affine, nbWires)
 			for i := 0; i < nbWires; i++ {
 				vk.G1.K[i] = p1
@@ -157,7 +218,31 @@ func TestVerifyingKeySerialization(t *testing.T) {
 				return false
 			}
 
-			return reflect.DeepEqual(&vk, &vkCompressed) && reflect.DeepEqual(&vk, &vkRaw)
+			if !reflect.DeepEqual(&vk, &vkCompressed) || !reflect.DeepEqual(&vk, &vkRaw) {
+				return false
+			}
+
+			// the prepared encoding restores the precomputation
+			var bufPrepared bytes.Buffer
+			written, err = vk.WritePreparedTo(&bufPrepared)
+			if err != nil {
+				t.Log(err)
+				return false
+			}
+
+			var vkPrepared VerifyingKey
+			read, err = vkPrepared.ReadPreparedFrom(&bufPrepared)
+			if err != nil {
+				t.Log(err)
+				return false
+			}
+
+			if read != written {
+				t.Log("read prepared != written")
+				return false
+			}
+
+			return reflect.DeepEqual(&vk, &vkPrepared)
 		},
 		GenG1(),
 		GenG2(),
@@ -247,6 +332,69 @@ func TestProvingKeySerialization(t *testing.T) {
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
 
+func TestProvingKeyDump(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 10
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("ProvingKey -> dump -> ProvingKey should stay constant", prop.ForAll(
+		func(p1 curve.G1Affine, p2 curve.G2Affine) bool {
+			var pk, pkRead, pkMapped ProvingKey
+
+			// create a random pk
+			domain := fft.NewDomain(8, 1, true)
+			pk.Domain = *domain
+
+			nbWires := 6
+			nbPrivateWires := 4
+
+			// allocate our slices
+			pk.G1.A = make([]curve.G1Affine, nbWires)
+			pk.G1.B = make([]curve.G1Affine, nbWires)
+			pk.G1.K = make([]curve.G1Affine, nbPrivateWires)
+			pk.G1.Z = make([]curve.G1Affine, pk.Domain.Cardinality)
+			pk.G2.B = make([]curve.G2Affine, nbWires)
+
+			pk.G1.Alpha = p1
+			pk.G2.Beta = p2
+			pk.G1.K[1] = p1
+			pk.G1.A[3] = p1
+			pk.G1.B[0] = p1
+			pk.G1.Z[5] = p1
+			pk.G2.B[0] = p2
+
+			// infinity flags
+			pk.NbInfinityA = 1
+			pk.InfinityA = make([]bool, nbWires)
+			pk.InfinityB = make([]bool, nbWires)
+			pk.InfinityA[2] = true
+
+			var buf bytes.Buffer
+			if err := pk.WriteDump(&buf); err != nil {
+				t.Log(err)
+				return false
+			}
+
+			if err := pkRead.ReadDump(bytes.NewReader(buf.Bytes())); err != nil {
+				t.Log(err)
+				return false
+			}
+
+			if err := pkMapped.ReadDumpBytes(buf.Bytes()); err != nil {
+				t.Log(err)
+				return false
+			}
+
+			return reflect.DeepEqual(&pk, &pkRead) && reflect.DeepEqual(&pk, &pkMapped)
+		},
+		GenG1(),
+		GenG2(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
 func GenG1() gopter.Gen {
 	_, _, g1GenAff, _ := curve.Generators()
 	return func(genParams *gopter.GenParameters) *gopter.GenResult {
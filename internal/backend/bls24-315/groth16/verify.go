@@ -17,71 +17,196 @@
 package groth16
 
 import (
-	"github.com/consensys/gnark-crypto/ecc"
-
 	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
 
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+
+	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16/bls24315verifier"
 	bls24_315witness "github.com/consensys/gnark/internal/backend/bls24-315/witness"
 	"io"
-)
-
-var (
-	errPairingCheckFailed         = errors.New("pairing doesn't match")
-	errCorrectSubgroupCheckFailed = errors.New("points in the proof are not in the correct subgroup")
+	"math/big"
 )
 
 // Verify verifies a proof with given VerifyingKey and publicWitness
 func Verify(proof *Proof, vk *VerifyingKey, publicWitness bls24_315witness.Witness) error {
-
-	if len(publicWitness) != (len(vk.G1.K) - 1) {
-		return fmt.Errorf("invalid witness size, got %d, expected %d (public - ONE_WIRE)", len(publicWitness), len(vk.G1.K)-1)
+	pre, err := vk.precompute()
+	if err != nil {
+		return err
 	}
+	return pre.Verify((*bls24315verifier.Proof)(proof), vk.G1.K, publicWitness)
+}
+
+// vkPrecomputation holds the values derived from a VerifyingKey that Verify needs; the verification
+// itself is implemented in the verifier-only package bls24315verifier
+type vkPrecomputation = bls24315verifier.Prepared
+
+// Precompute computes and caches on the key the values derived from it at verification time:
+// e(α, β), -[γ]2 and -[δ]2. Verify calls it when the cache is empty; calling it beforehand saves
+// the pairing to the first verification.
+//
+// Precompute is idempotent and safe for concurrent use. Setup and ReadPreparedFrom fill the cache;
+// ReadFrom doesn't.
+func (vk *VerifyingKey) Precompute() error {
+	_, err := vk.precompute()
+	return err
+}
 
-	// check that the points in the proof are in the correct subgroup
-	if !proof.isValid() {
-		return errCorrectSubgroupCheckFailed
+// precompute returns the cached precomputation, computing it if needed. Concurrent callers may
+// compute it more than once, the result is the same.
+func (vk *VerifyingKey) precompute() (*vkPrecomputation, error) {
+	if pre, _ := vk.precomputed.Load().(*vkPrecomputation); pre != nil {
+		return pre, nil
 	}
+	pre, err := bls24315verifier.Prepare(vk.G1.Alpha, vk.G2.Beta, vk.G2.Gamma, vk.G2.Delta)
+	if err != nil {
+		return nil, err
+	}
+	vk.precomputed.Store(&pre)
+	return &pre, nil
+}
 
-	var doubleML curve.GT
-	chDone := make(chan error, 1)
+// resetPrecomputation empties the cache, when the points of the key are modified
+func (vk *VerifyingKey) resetPrecomputation() {
+	if vk.precomputed.Load() != nil {
+		vk.precomputed.Store((*vkPrecomputation)(nil))
+	}
+}
 
-	// compute (eKrsδ, eArBs)
-	go func() {
-		var errML error
-		doubleML, errML = curve.MillerLoop([]curve.G1Affine{proof.Krs, proof.Ar}, []curve.G2Affine{vk.G2.deltaNeg, proof.Bs})
-		chDone <- errML
-		close(chDone)
-	}()
+// FixPublicInputs returns a copy of vk where the public inputs at the given indexes (in the public witness,
+// without the ONE_WIRE) are fixed to the given values: their share of Σx.[Kvk(t)]1 is folded into [Kvk(0)]1,
+// the constant term, such that the returned VerifyingKey expects the remaining public inputs only, in the same order.
+func (vk *VerifyingKey) FixPublicInputs(fixed map[int]*big.Int) (*VerifyingKey, error) {
+	nbPublic := len(vk.G1.K) - 1
+	for i := range fixed {
+		if i < 0 || i >= nbPublic {
+			return nil, fmt.Errorf("invalid public input index %d, expected in [0, %d)", i, nbPublic)
+		}
+	}
 
-	// compute e(Σx.[Kvk(t)]1, -[γ]2)
-	var kSum curve.G1Jac
-	if _, err := kSum.MultiExp(vk.G1.K[1:], publicWitness, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
-		return err
+	// the points of r but Kvk are the ones of vk, and so is its precomputation
+	var r VerifyingKey
+	r.G1, r.G2, r.Metadata = vk.G1, vk.G2, vk.Metadata
+	if pre, _ := vk.precomputed.Load().(*vkPrecomputation); pre != nil {
+		r.precomputed.Store(pre)
+	}
+	r.G1.K = make([]curve.G1Affine, 1, len(vk.G1.K)-len(fixed))
+
+	var k0, p curve.G1Jac
+	k0.FromAffine(&vk.G1.K[0])
+	for i := 0; i < nbPublic; i++ {
+		v, ok := fixed[i]
+		if !ok {
+			r.G1.K = append(r.G1.K, vk.G1.K[i+1])
+			continue
+		}
+		p.FromAffine(&vk.G1.K[i+1])
+		p.ScalarMultiplication(&p, v)
+		k0.AddAssign(&p)
 	}
-	kSum.AddMixed(&vk.G1.K[0])
-	var kSumAff curve.G1Affine
-	kSumAff.FromJacobian(&kSum)
+	r.G1.K[0].FromJacobian(&k0)
 
-	right, err := curve.MillerLoop([]curve.G1Affine{kSumAff}, []curve.G2Affine{vk.G2.gammaNeg})
+	return &r, nil
+}
+
+// WriteCompactTo writes the VerifyingKey in the compact format consumed by NewVerifier:
+// the values needed at verification time are precomputed and points are not compressed,
+// such that decoding is cheap.
+//
+// format: uint16(curveID) | e(α, β) | -[γ]2 | -[δ]2 | uint32(len(Kvk)) | [Kvk]1
+func (vk *VerifyingKey) WriteCompactTo(w io.Writer) (int64, error) {
+	var buf [2]byte
+	binary.BigEndian.PutUint16(buf[:], uint16(curve.ID))
+	pre, err := vk.precompute()
 	if err != nil {
-		return err
+		return 0, err
+	}
+	n, err := w.Write(buf[:])
+	if err != nil {
+		return int64(n), err
+	}
+	e := pre.E.Bytes()
+	m, err := w.Write(e[:])
+	n += m
+	if err != nil {
+		return int64(n), err
+	}
+
+	enc := curve.NewEncoder(w, curve.RawEncoding())
+	toEncode := []interface{}{
+		&pre.GammaNeg,
+		&pre.DeltaNeg,
+		vk.G1.K,
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return int64(n) + enc.BytesWritten(), err
+		}
 	}
+	return int64(n) + enc.BytesWritten(), nil
+}
 
-	// wait for (eKrsδ, eArBs)
-	if err := <-chDone; err != nil {
+// Verifier is a lightweight Groth16 verifier, built from a compact VerifyingKey encoding
+// (see VerifyingKey.WriteCompactTo)
+type Verifier struct {
+	vkPrecomputation
+	k []curve.G1Affine
+}
+
+// NewVerifier parses a VerifyingKey written with VerifyingKey.WriteCompactTo
+func NewVerifier(vkBytes []byte) (*Verifier, error) {
+	const headerSize = 2 + curve.SizeOfGT
+	if len(vkBytes) < headerSize {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if binary.BigEndian.Uint16(vkBytes[:2]) != uint16(curve.ID) {
+		return nil, errors.New("invalid verifying key: curve mismatch")
+	}
+
+	var v Verifier
+	if err := v.E.SetBytes(vkBytes[2:headerSize]); err != nil {
+		return nil, err
+	}
+
+	dec := curve.NewDecoder(bytes.NewReader(vkBytes[headerSize:]))
+	toDecode := []interface{}{
+		&v.GammaNeg,
+		&v.DeltaNeg,
+		&v.k,
+	}
+	for _, e := range toDecode {
+		if err := dec.Decode(e); err != nil {
+			return nil, err
+		}
+	}
+	if len(v.k) == 0 {
+		return nil, errors.New("invalid verifying key: empty Kvk")
+	}
+
+	return &v, nil
+}
+
+// Verify verifies a proof (encoded with Proof.WriteTo or Proof.WriteRawTo) for the given public inputs,
+// in the order of the public witness (without the ONE_WIRE)
+func (v *Verifier) Verify(proofBytes []byte, publicInputs []*big.Int) error {
+	var proof Proof
+	if _, err := proof.ReadFrom(bytes.NewReader(proofBytes)); err != nil {
 		return err
 	}
 
-	right = curve.FinalExponentiation(&right, &doubleML)
-	if !vk.e.Equal(&right) {
-		return errPairingCheckFailed
+	publicWitness := make([]fr.Element, len(publicInputs))
+	for i := 0; i < len(publicInputs); i++ {
+		publicWitness[i].SetBigInt(publicInputs[i])
 	}
-	return nil
+
+	return v.vkPrecomputation.Verify((*bls24315verifier.Proof)(&proof), v.k, publicWitness)
 }
 
 // ExportSolidity not implemented for BLS24-315
-func (vk *VerifyingKey) ExportSolidity(w io.Writer) error {
+func (vk *VerifyingKey) ExportSolidity(w io.Writer, opts ...func(opt *backend.SetupOption) error) error {
 	return errors.New("not implemented")
 }
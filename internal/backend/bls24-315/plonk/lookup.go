@@ -0,0 +1,570 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonk
+
+import (
+	"errors"
+	"hash"
+	"io"
+	"math/big"
+	"math/bits"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/polynomial"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/kzg"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/fft"
+
+	"github.com/consensys/gnark/internal/backend/bls24-315/cs"
+
+	"github.com/consensys/gnark-crypto/fiat-shamir"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// The lookup argument follows plookup (https://eprint.iacr.org/2020/315.pdf).
+//
+// On the rows of the lookups (qlk=1), f = l + eta*qtab, elsewhere f = t[0]. t = tValue + eta*tTag is the
+// concatenation of the tables, and h1, h2 is the concatenation of f and t, sorted by t. The prover shows that
+// f is included in t with a grand product z, such that z(1) = 1 and
+//
+//	z(uX)*(gamma(1+beta)+h1+beta*h1(uX))*(gamma(1+beta)+h2+beta*h2(uX)) = z*(1+beta)*(gamma+f)*(gamma(1+beta)+t+beta*t(uX))
+//
+// on all rows but the last one, which can't be a lookup (see Setup).
+
+var (
+	errLookupProofMismatch   = errors.New("the proof and the verifying key don't agree on the lookup argument")
+	errInvalidLookupOpenings = errors.New("invalid opening proofs for the lookup argument")
+)
+
+// LookupProof stores the commitments and the opening proofs of the lookup argument
+type LookupProof struct {
+	// Commitments to f, the looked up values, h1, h2, the sorted concatenation of f and t, and z, the grand product
+	F, H1, H2, Z kzg.Digest
+
+	// Batch opening proof of f, h1, h2, z, t, qlk, qtab at zeta
+	BatchedProof kzg.BatchOpeningProof
+
+	// Batch opening proof of h1, h2, z, t at zeta*u
+	ShiftedBatchedProof kzg.BatchOpeningProof
+}
+
+// lookupPolynomials stores the challenges and the polynomials of the lookup argument,
+// in canonical basis. f, h1, h2 and z are blinded.
+type lookupPolynomials struct {
+	eta, beta, gamma fr.Element
+	f, h1, h2, z, t  polynomial.Polynomial
+	tDigest          kzg.Digest
+}
+
+// newTranscript returns the transcript used to derive the challenges. The lookup argument
+// adds eta (compression of the tables), and beta, gamma (grand product).
+func newTranscript(h hash.Hash, withLookup bool) fiatshamir.Transcript {
+	if withLookup {
+		return fiatshamir.NewTranscript(h, "gamma", "eta", "lookupBeta", "lookupGamma", "alpha", "zeta")
+	}
+	return fiatshamir.NewTranscript(h, "gamma", "alpha", "zeta")
+}
+
+// proveLookup computes f, h1, h2 and z, and sets the corresponding commitments in proof.Lookup.
+//
+// ll is the solution vector l in Lagrange basis, not blinded; the blinding is drawn from rnd (see setRandom)
+func proveLookup(spr *cs.SparseR1CS, pk *ProvingKey, ll polynomial.Polynomial, fs *fiatshamir.Transcript, proof *Proof, rnd io.Reader) (*lookupPolynomials, error) {
+	var err error
+	lk := &lookupPolynomials{}
+	proof.Lookup = &LookupProof{}
+
+	if lk.eta, err = deriveRandomness(fs, "eta"); err != nil {
+		return nil, err
+	}
+
+	nbElmt := int(pk.DomainNum.Cardinality)
+
+	// t = tValue + eta*tTag, in Lagrange and canonical basis
+	lt := make(polynomial.Polynomial, nbElmt)
+	lk.t = make(polynomial.Polynomial, nbElmt)
+	for i := 0; i < nbElmt; i++ {
+		lt[i].Mul(&pk.Lookup.LTTag[i], &lk.eta).Add(&lt[i], &pk.Lookup.LTValue[i])
+		lk.t[i].Mul(&pk.Lookup.CTTag[i], &lk.eta).Add(&lk.t[i], &pk.Lookup.CTValue[i])
+	}
+	lk.tDigest = lookupTableDigest(pk.Vk, lk.eta)
+
+	// f = l + eta*qtab on the rows of the lookups, t[0] elsewhere
+	lf := make(polynomial.Polynomial, nbElmt)
+	for i := 0; i < nbElmt; i++ {
+		lf[i].Set(&lt[0])
+	}
+	var tag fr.Element
+	for _, l := range spr.Lookups {
+		i := spr.NbPublicVariables + l.Constraint
+		tag.SetUint64(uint64(l.Table))
+		lf[i].Mul(&tag, &lk.eta).Add(&lf[i], &ll[i])
+	}
+
+	lh1, lh2 := sortLookup(lf, lt)
+
+	// commit to the blinded versions of f, h1, h2
+	if lk.f, err = toBlindedCanonical(lf, &pk.DomainNum, 1, rnd); err != nil {
+		return nil, err
+	}
+	if lk.h1, err = toBlindedCanonical(lh1, &pk.DomainNum, 2, rnd); err != nil {
+		return nil, err
+	}
+	if lk.h2, err = toBlindedCanonical(lh2, &pk.DomainNum, 2, rnd); err != nil {
+		return nil, err
+	}
+	if proof.Lookup.F, err = kzg.Commit(lk.f, pk.Vk.KZGSRS); err != nil {
+		return nil, err
+	}
+	if proof.Lookup.H1, err = kzg.Commit(lk.h1, pk.Vk.KZGSRS); err != nil {
+		return nil, err
+	}
+	if proof.Lookup.H2, err = kzg.Commit(lk.h2, pk.Vk.KZGSRS); err != nil {
+		return nil, err
+	}
+
+	// derive beta, gamma from Comm(f), Comm(h1), Comm(h2)
+	if lk.beta, err = deriveRandomness(fs, "lookupBeta", &proof.Lookup.F, &proof.Lookup.H1, &proof.Lookup.H2); err != nil {
+		return nil, err
+	}
+	if lk.gamma, err = deriveRandomness(fs, "lookupGamma"); err != nil {
+		return nil, err
+	}
+
+	lz := computeLookupZ(lf, lt, lh1, lh2, lk.beta, lk.gamma)
+	if lk.z, err = toBlindedCanonical(lz, &pk.DomainNum, 2, rnd); err != nil {
+		return nil, err
+	}
+	if proof.Lookup.Z, err = kzg.Commit(lk.z, pk.Vk.KZGSRS); err != nil {
+		return nil, err
+	}
+
+	return lk, nil
+}
+
+// toBlindedCanonical returns p (Lagrange basis) in canonical basis, blinded with order bo (see blindPoly).
+// p is not modified.
+func toBlindedCanonical(p polynomial.Polynomial, domain *fft.Domain, bo uint64, rnd io.Reader) (polynomial.Polynomial, error) {
+	res := make(polynomial.Polynomial, domain.Cardinality, domain.Cardinality+bo+1)
+	copy(res, p)
+	domain.FFTInverse(res, fft.DIF, 0)
+	fft.BitReverse(res)
+	return blindPoly(res, domain.Cardinality, bo, rnd)
+}
+
+// checkBlindedLookup checks that f, h1, h2 and z are blinded (see checkBlinded)
+func checkBlindedLookup(lk *lookupPolynomials, n uint64) error {
+	if err := checkBlinded("lookup f", lk.f, n, 1); err != nil {
+		return err
+	}
+	if err := checkBlinded("lookup h1", lk.h1, n, 2); err != nil {
+		return err
+	}
+	if err := checkBlinded("lookup h2", lk.h2, n, 2); err != nil {
+		return err
+	}
+	return checkBlinded("lookup z", lk.z, n, 2)
+}
+
+// lookupTableDigest returns Comm(t) = Comm(tValue) + eta*Comm(tTag)
+func lookupTableDigest(vk *VerifyingKey, eta fr.Element) kzg.Digest {
+	var bEta big.Int
+	eta.ToBigIntRegular(&bEta)
+	res := vk.Lookup.TTag
+	res.ScalarMultiplication(&res, &bEta)
+	res.Add(&res, &vk.Lookup.TValue)
+	return res
+}
+
+// sortLookup returns h1, h2 (Lagrange basis), such that h1 || h2[1:] is the concatenation of
+// f[:n-1] and t, sorted by t: the values of f are placed next to their first occurrence in t.
+//
+// Values of f which are not in t (the lookup is not satisfied) are placed at the end, in which
+// case the grand product won't be one and the proof won't verify.
+func sortLookup(f, t polynomial.Polynomial) (h1, h2 polynomial.Polynomial) {
+	n := len(t)
+
+	count := make(map[fr.Element]int, n)
+	for i := 0; i < n-1; i++ {
+		count[f[i]]++
+	}
+
+	s := make([]fr.Element, 0, 2*n-1)
+	for i := 0; i < n; i++ {
+		s = append(s, t[i])
+		for c := count[t[i]]; c > 0; c-- {
+			s = append(s, t[i])
+		}
+		delete(count, t[i])
+	}
+	for i := 0; i < n-1; i++ {
+		if count[f[i]] > 0 {
+			s = append(s, f[i])
+			count[f[i]]--
+		}
+	}
+
+	h1 = make(polynomial.Polynomial, n)
+	h2 = make(polynomial.Polynomial, n)
+	copy(h1, s[:n])
+	copy(h2, s[n-1:])
+
+	return h1, h2
+}
+
+// computeLookupZ computes z, the grand product of the lookup argument, in Lagrange basis:
+// z(1)=1 and, for i>0, z(u**i) = Pi_{k<i} n_k/d_k where
+//
+//	n_k = (1+beta)*(gamma+f_k)*(gamma(1+beta)+t_k+beta*t_k+1)
+//	d_k = (gamma(1+beta)+h1_k+beta*h1_k+1)*(gamma(1+beta)+h2_k+beta*h2_k+1)
+func computeLookupZ(f, t, h1, h2 polynomial.Polynomial, beta, gamma fr.Element) polynomial.Polynomial {
+	nbElmts := len(t)
+	z := make(polynomial.Polynomial, nbElmts)
+	gInv := make(polynomial.Polynomial, nbElmts)
+
+	var onePlusBeta, gammaOnePlusBeta fr.Element
+	onePlusBeta.SetOne().Add(&onePlusBeta, &beta)
+	gammaOnePlusBeta.Mul(&gamma, &onePlusBeta)
+
+	z[0].SetOne()
+	gInv[0].SetOne()
+
+	utils.Parallelize(nbElmts-1, func(start, end int) {
+		var f0, f1, g0, g1 fr.Element
+		for i := start; i < end; i++ {
+			f0.Add(&gamma, &f[i]).Mul(&f0, &onePlusBeta)                         // (1+beta)*(gamma+f_i)
+			f1.Mul(&beta, &t[i+1]).Add(&f1, &t[i]).Add(&f1, &gammaOnePlusBeta)   // gamma(1+beta)+t_i+beta*t_i+1
+			g0.Mul(&beta, &h1[i+1]).Add(&g0, &h1[i]).Add(&g0, &gammaOnePlusBeta) // gamma(1+beta)+h1_i+beta*h1_i+1
+			g1.Mul(&beta, &h2[i+1]).Add(&g1, &h2[i]).Add(&g1, &gammaOnePlusBeta) // gamma(1+beta)+h2_i+beta*h2_i+1
+
+			z[i+1].Mul(&f0, &f1)
+			gInv[i+1].Mul(&g0, &g1)
+		}
+	})
+
+	gInv = fr.BatchInvert(gInv)
+	for i := 1; i < nbElmts; i++ {
+		z[i].Mul(&z[i], &z[i-1]).
+			Mul(&z[i], &gInv[i])
+	}
+
+	return z
+}
+
+// evalLookupConstraints computes the evaluation of
+//
+//	qlk*(l+eta*qtab-f) + alpha*( L1*(z-1) + alpha*( (X-u**(n-1))*(z*(1+beta)*(gamma+f)*(gamma(1+beta)+t+beta*t(uX)) -
+//		z(uX)*(gamma(1+beta)+h1+beta*h1(uX))*(gamma(1+beta)+h2+beta*h2(uX))) + alpha*( Ln*(h1-h2(uX)) + alpha*Ln*(z-1) ) ) )
+//
+// on the odd cosets of (Z/8mZ)/(Z/mZ), where Ln is the Lagrange polynomial at u**(n-1).
+//
+// * evalL evaluation of the blinded solution vector l on the odd cosets
+// * the result is in bit reversed order
+func evalLookupConstraints(pk *ProvingKey, lk *lookupPolynomials, evalL polynomial.Polynomial, alpha fr.Element) polynomial.Polynomial {
+
+	var evalF, evalH1, evalH2, evalZ, evalT, evalQlk, evalQtab polynomial.Polynomial
+	var wg sync.WaitGroup
+	wg.Add(6)
+	go func() {
+		evalF = evaluateHDomain(lk.f, &pk.DomainH)
+		wg.Done()
+	}()
+	go func() {
+		evalH1 = evaluateHDomain(lk.h1, &pk.DomainH)
+		wg.Done()
+	}()
+	go func() {
+		evalH2 = evaluateHDomain(lk.h2, &pk.DomainH)
+		wg.Done()
+	}()
+	go func() {
+		evalZ = evaluateHDomain(lk.z, &pk.DomainH)
+		wg.Done()
+	}()
+	go func() {
+		evalT = evaluateHDomain(lk.t, &pk.DomainH)
+		wg.Done()
+	}()
+	go func() {
+		evalQlk = evaluateHDomain(pk.Lookup.Qlk, &pk.DomainH)
+		wg.Done()
+	}()
+	evalQtab = evaluateHDomain(pk.Lookup.Qtab, &pk.DomainH)
+
+	// L1 and Ln (canonical form): L_j = 1/n*Sum_k u**(-jk)*X**k
+	startsAtOne := make(polynomial.Polynomial, pk.DomainH.Cardinality)
+	endsAtOne := make(polynomial.Polynomial, pk.DomainH.Cardinality)
+	var acc fr.Element
+	acc.Set(&pk.DomainNum.CardinalityInv)
+	for i := 0; i < int(pk.DomainNum.Cardinality); i++ {
+		startsAtOne[i].Mul(&pk.DomainNum.CardinalityInv, &pk.DomainH.CosetTable[0][i])
+		endsAtOne[i].Mul(&acc, &pk.DomainH.CosetTable[0][i])
+		acc.Mul(&acc, &pk.DomainNum.Generator)
+	}
+
+	// evaluates L1, Ln on the odd cosets of (Z/8mZ)/(Z/mZ)
+	// / ! \ note that we scaled by the coset in the previous loop, hence we pass 0 as coset here.
+	pk.DomainH.FFT(startsAtOne, fft.DIF, 0)
+	pk.DomainH.FFT(endsAtOne, fft.DIF, 0)
+
+	// evalutation of ID the odd cosets of (Z/8mZ)/(Z/mZ)
+	evalID := evalIDCosets(pk)
+
+	wg.Wait()
+
+	var onePlusBeta, gammaOnePlusBeta fr.Element
+	onePlusBeta.SetOne().Add(&onePlusBeta, &lk.beta)
+	gammaOnePlusBeta.Mul(&lk.gamma, &onePlusBeta)
+
+	res := make(polynomial.Polynomial, pk.DomainH.Cardinality)
+	s := pk.DomainH.Cardinality
+	nn := uint64(64 - bits.TrailingZeros64(s))
+
+	// needed to shift h1, h2, z and t
+	toShift := pk.DomainH.Cardinality / pk.DomainNum.Cardinality
+
+	utils.Parallelize(int(pk.DomainH.Cardinality), func(start, end int) {
+		var one, acc, f, g, t fr.Element
+		one.SetOne()
+		for i := start; i < end; i++ {
+
+			// see evalConstraintOrdering
+			irev := bits.Reverse64(uint64(i)) >> nn
+			shifted := bits.Reverse64(uint64((irev+toShift)%s)) >> nn
+
+			// Ln*(z-1)
+			acc.Sub(&evalZ[i], &one).Mul(&acc, &endsAtOne[i])
+
+			// Ln*(h1-h2(uX))
+			t.Sub(&evalH1[i], &evalH2[shifted]).Mul(&t, &endsAtOne[i])
+			acc.Mul(&acc, &alpha).Add(&acc, &t)
+
+			// (X-u**(n-1))*(z*(1+beta)*(gamma+f)*(gamma(1+beta)+t+beta*t(uX)) -
+			// 		z(uX)*(gamma(1+beta)+h1+beta*h1(uX))*(gamma(1+beta)+h2+beta*h2(uX)))
+			f.Add(&lk.gamma, &evalF[i]).Mul(&f, &onePlusBeta).Mul(&f, &evalZ[i])
+			t.Mul(&lk.beta, &evalT[shifted]).Add(&t, &evalT[i]).Add(&t, &gammaOnePlusBeta)
+			f.Mul(&f, &t)
+			g.Mul(&lk.beta, &evalH1[shifted]).Add(&g, &evalH1[i]).Add(&g, &gammaOnePlusBeta).Mul(&g, &evalZ[shifted])
+			t.Mul(&lk.beta, &evalH2[shifted]).Add(&t, &evalH2[i]).Add(&t, &gammaOnePlusBeta)
+			g.Mul(&g, &t)
+			f.Sub(&f, &g)
+			t.Sub(&evalID[irev], &pk.DomainNum.GeneratorInv)
+			f.Mul(&f, &t)
+			acc.Mul(&acc, &alpha).Add(&acc, &f)
+
+			// L1*(z-1)
+			t.Sub(&evalZ[i], &one).Mul(&t, &startsAtOne[i])
+			acc.Mul(&acc, &alpha).Add(&acc, &t)
+
+			// qlk*(l+eta*qtab-f)
+			t.Mul(&evalQtab[i], &lk.eta).Add(&t, &evalL[i]).Sub(&t, &evalF[i]).Mul(&t, &evalQlk[i])
+			res[i].Mul(&acc, &alpha).Add(&res[i], &t)
+		}
+	})
+
+	return res
+}
+
+// openLookup sets the opening proofs of proof.Lookup
+func openLookup(lk *lookupPolynomials, pk *ProvingKey, zeta fr.Element, hFunc hash.Hash, proof *Proof) error {
+	var err error
+	proof.Lookup.BatchedProof, err = kzg.BatchOpenSinglePoint(
+		[]polynomial.Polynomial{
+			lk.f,
+			lk.h1,
+			lk.h2,
+			lk.z,
+			lk.t,
+			pk.Lookup.Qlk,
+			pk.Lookup.Qtab,
+		},
+		[]kzg.Digest{
+			proof.Lookup.F,
+			proof.Lookup.H1,
+			proof.Lookup.H2,
+			proof.Lookup.Z,
+			lk.tDigest,
+			pk.Vk.Lookup.Qlk,
+			pk.Vk.Lookup.Qtab,
+		},
+		&zeta,
+		hFunc,
+		&pk.DomainH,
+		pk.Vk.KZGSRS,
+	)
+	if err != nil {
+		return err
+	}
+
+	var zetaShifted fr.Element
+	zetaShifted.Mul(&zeta, &pk.Vk.Generator)
+	proof.Lookup.ShiftedBatchedProof, err = kzg.BatchOpenSinglePoint(
+		[]polynomial.Polynomial{
+			lk.h1,
+			lk.h2,
+			lk.z,
+			lk.t,
+		},
+		[]kzg.Digest{
+			proof.Lookup.H1,
+			proof.Lookup.H2,
+			proof.Lookup.Z,
+			lk.tDigest,
+		},
+		&zetaShifted,
+		hFunc,
+		&pk.DomainH,
+		pk.Vk.KZGSRS,
+	)
+	return err
+}
+
+// lookupChallenges stores the challenges of the lookup argument, derived by the verifier
+type lookupChallenges struct {
+	eta, beta, gamma fr.Element
+}
+
+func deriveLookupChallenges(fs *fiatshamir.Transcript, proof *Proof) (lookupChallenges, error) {
+	var c lookupChallenges
+	var err error
+	if c.eta, err = deriveRandomness(fs, "eta"); err != nil {
+		return c, err
+	}
+	if c.beta, err = deriveRandomness(fs, "lookupBeta", &proof.Lookup.F, &proof.Lookup.H1, &proof.Lookup.H2); err != nil {
+		return c, err
+	}
+	c.gamma, err = deriveRandomness(fs, "lookupGamma")
+	return c, err
+}
+
+// evalLookupConstraintsAtZeta computes the evaluation at zeta of the lookup constraints (see evalLookupConstraints)
+// from the claimed values of proof.Lookup.
+//
+// * l is the claimed value of l at zeta
+// * lagrangeOne is L1(zeta)
+func evalLookupConstraintsAtZeta(proof *Proof, vk *VerifyingKey, c lookupChallenges, l, lagrangeOne, zeta, alpha fr.Element) (fr.Element, error) {
+	var res fr.Element
+
+	var zetaShifted fr.Element
+	zetaShifted.Mul(&zeta, &vk.Generator)
+	if len(proof.Lookup.BatchedProof.ClaimedValues) != 7 || len(proof.Lookup.ShiftedBatchedProof.ClaimedValues) != 4 ||
+		!proof.Lookup.BatchedProof.Point.Equal(&zeta) || !proof.Lookup.ShiftedBatchedProof.Point.Equal(&zetaShifted) {
+		return res, errInvalidLookupOpenings
+	}
+
+	f := proof.Lookup.BatchedProof.ClaimedValues[0]
+	h1 := proof.Lookup.BatchedProof.ClaimedValues[1]
+	h2 := proof.Lookup.BatchedProof.ClaimedValues[2]
+	z := proof.Lookup.BatchedProof.ClaimedValues[3]
+	t := proof.Lookup.BatchedProof.ClaimedValues[4]
+	qlk := proof.Lookup.BatchedProof.ClaimedValues[5]
+	qtab := proof.Lookup.BatchedProof.ClaimedValues[6]
+	h1u := proof.Lookup.ShiftedBatchedProof.ClaimedValues[0]
+	h2u := proof.Lookup.ShiftedBatchedProof.ClaimedValues[1]
+	zu := proof.Lookup.ShiftedBatchedProof.ClaimedValues[2]
+	tu := proof.Lookup.ShiftedBatchedProof.ClaimedValues[3]
+
+	// Ln(zeta) = u**(n-1)/n*(zeta**n-1)/(zeta-u**(n-1))
+	var lagrangeN, uInv, den fr.Element
+	one := fr.One()
+	uInv.Inverse(&vk.Generator)
+	lagrangeN.Exp(zeta, new(big.Int).SetUint64(vk.Size)).Sub(&lagrangeN, &one)
+	den.Sub(&zeta, &uInv)
+	lagrangeN.Div(&lagrangeN, &den).Mul(&lagrangeN, &uInv).Mul(&lagrangeN, &vk.SizeInv)
+
+	var onePlusBeta, gammaOnePlusBeta, acc, a, b fr.Element
+	onePlusBeta.Add(&one, &c.beta)
+	gammaOnePlusBeta.Mul(&c.gamma, &onePlusBeta)
+
+	// Ln*(z-1)
+	acc.Sub(&z, &one).Mul(&acc, &lagrangeN)
+
+	// Ln*(h1-h2(uX))
+	a.Sub(&h1, &h2u).Mul(&a, &lagrangeN)
+	acc.Mul(&acc, &alpha).Add(&acc, &a)
+
+	// (X-u**(n-1))*(z*(1+beta)*(gamma+f)*(gamma(1+beta)+t+beta*t(uX)) -
+	// 		z(uX)*(gamma(1+beta)+h1+beta*h1(uX))*(gamma(1+beta)+h2+beta*h2(uX)))
+	a.Add(&c.gamma, &f).Mul(&a, &onePlusBeta).Mul(&a, &z)
+	b.Mul(&c.beta, &tu).Add(&b, &t).Add(&b, &gammaOnePlusBeta)
+	a.Mul(&a, &b)
+	b.Mul(&c.beta, &h1u).Add(&b, &h1).Add(&b, &gammaOnePlusBeta).Mul(&b, &zu)
+	den.Mul(&c.beta, &h2u).Add(&den, &h2).Add(&den, &gammaOnePlusBeta)
+	b.Mul(&b, &den)
+	a.Sub(&a, &b)
+	b.Sub(&zeta, &uInv)
+	a.Mul(&a, &b)
+	acc.Mul(&acc, &alpha).Add(&acc, &a)
+
+	// L1*(z-1)
+	a.Sub(&z, &one).Mul(&a, &lagrangeOne)
+	acc.Mul(&acc, &alpha).Add(&acc, &a)
+
+	// qlk*(l+eta*qtab-f)
+	a.Mul(&qtab, &c.eta).Add(&a, &l).Sub(&a, &f).Mul(&a, &qlk)
+	res.Mul(&acc, &alpha).Add(&res, &a)
+
+	return res, nil
+}
+
+// foldLookupProofs folds the batch opening proofs of proof.Lookup (see kzg.FoldProof)
+func foldLookupProofs(proof *Proof, vk *VerifyingKey, c lookupChallenges, hFunc hash.Hash) ([]kzg.Digest, []kzg.OpeningProof, error) {
+	tDigest := lookupTableDigest(vk, c.eta)
+
+	foldedProof, foldedDigest, err := kzg.FoldProof([]kzg.Digest{
+		proof.Lookup.F,
+		proof.Lookup.H1,
+		proof.Lookup.H2,
+		proof.Lookup.Z,
+		tDigest,
+		vk.Lookup.Qlk,
+		vk.Lookup.Qtab,
+	},
+		&proof.Lookup.BatchedProof,
+		hFunc,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	foldedShiftedProof, foldedShiftedDigest, err := kzg.FoldProof([]kzg.Digest{
+		proof.Lookup.H1,
+		proof.Lookup.H2,
+		proof.Lookup.Z,
+		tDigest,
+	},
+		&proof.Lookup.ShiftedBatchedProof,
+		hFunc,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []kzg.Digest{foldedDigest, foldedShiftedDigest}, []kzg.OpeningProof{foldedProof, foldedShiftedProof}, nil
+}
+
+// zDigests returns the commitments binded to alpha
+func zDigests(proof *Proof) []*curve.G1Affine {
+	if proof.Lookup == nil {
+		return []*curve.G1Affine{&proof.Z}
+	}
+	return []*curve.G1Affine{&proof.Z, &proof.Lookup.Z}
+}
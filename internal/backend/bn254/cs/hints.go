@@ -0,0 +1,93 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package cs
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark/backend/hint"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// constantTimeHint computes the outputs of a builtin hint with the field arithmetic, in a time
+// which doesn't depend on the values of the inputs (see backend.WithConstantTimeHints)
+type constantTimeHint func(inputs, outputs []fr.Element) error
+
+// constantTimeHints are the constant time implementations of the builtin hints, by hint ID: the
+// hints with a stable name, and the hint functions under their legacy IDs
+var constantTimeHints = func() map[hint.ID]constantTimeHint {
+	byName := map[string]constantTimeHint{
+		hint.IthBitNamed.String(): ithBitConstantTime,
+		hint.IsZeroNamed.String(): isZeroConstantTime,
+		hint.InvModNamed.String(): invModConstantTime,
+		hint.Name(hint.IthBit):    ithBitConstantTime,
+		hint.Name(hint.IsZero):    isZeroConstantTime,
+		hint.Name(hint.InvMod):    invModConstantTime,
+	}
+	res := make(map[hint.ID]constantTimeHint)
+	for _, f := range hint.Builtins() {
+		if ct, ok := byName[f.String()]; ok {
+			res[f.UUID()] = ct
+		}
+	}
+	return res
+}()
+
+// public exponents of the zero test and of the inversion
+var qMinusOne, qMinusTwo big.Int
+
+func init() {
+	qMinusOne.Sub(fr.Modulus(), big.NewInt(1))
+	qMinusTwo.Sub(fr.Modulus(), big.NewInt(2))
+}
+
+// ithBitConstantTime is hint.IthBit; the position of the bit (inputs[1]) is not a secret
+func ithBitConstantTime(inputs, outputs []fr.Element) error {
+	if len(inputs) != 2 {
+		return errors.New("ithBit expects 2 inputs; inputs[0] == value, inputs[1] == bit position")
+	}
+	a, n := inputs[0].ToRegular(), inputs[1].ToRegular()
+	if !n.IsUint64() {
+		outputs[0].SetZero()
+		return nil
+	}
+	outputs[0].SetUint64(a.Bit(n[0]))
+	return nil
+}
+
+// isZeroConstantTime is hint.IsZero: outputs[0] = 1 - a^(q-1)
+func isZeroConstantTime(inputs, outputs []fr.Element) error {
+	if len(inputs) != 1 {
+		return errors.New("IsZero expects one input")
+	}
+	var one fr.Element
+	one.SetOne()
+	outputs[0].Exp(inputs[0], &qMinusOne)
+	outputs[0].Sub(&one, &outputs[0])
+	return nil
+}
+
+// invModConstantTime is hint.InvMod: outputs[0] = a^(q-2), that is 1/a, or 0 if a == 0
+func invModConstantTime(inputs, outputs []fr.Element) error {
+	if len(inputs) != 1 {
+		return errors.New("InvMod expects one input")
+	}
+	outputs[0].Exp(inputs[0], &qMinusTwo)
+	return nil
+}
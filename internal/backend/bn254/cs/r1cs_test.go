@@ -18,14 +18,23 @@ package cs_test
 
 import (
 	"bytes"
+	"errors"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/internal/backend/circuits"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	"math/big"
 	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/consensys/gnark/internal/backend/bn254/cs"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+
+	bn254witness "github.com/consensys/gnark/internal/backend/bn254/witness"
 )
 
 func TestSerialization(t *testing.T) {
@@ -33,6 +42,9 @@ func TestSerialization(t *testing.T) {
 	var buffer, buffer2 bytes.Buffer
 
 	for name, circuit := range circuits.Circuits {
+		if circuit.ExpectedCompileError != "" {
+			continue // the circuit doesn't compile
+		}
 
 		r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, circuit.Circuit)
 		if err != nil {
@@ -111,3 +123,342 @@ func TestSerialization(t *testing.T) {
 		}
 	}
 }
+
+type checkAssignmentCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *checkAssignmentCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	x3 := api.Mul(circuit.X, circuit.X, circuit.X)
+	api.AssertIsEqual(api.Add(x3, circuit.X, 5), circuit.Y)
+	return nil
+}
+
+func TestCheckAssignment(t *testing.T) {
+	// x = 3, y = 35: the witness is [public | secret]
+	var witness [2]fr.Element
+	witness[0].SetUint64(35)
+	witness[1].SetUint64(3)
+
+	for _, b := range backend.Implemented() {
+		ccs, err := frontend.Compile(ecc.BN254, b, &checkAssignmentCircuit{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		internal, secret, public := ccs.GetNbVariables()
+
+		var wires []fr.Element
+		switch c := ccs.(type) {
+		case *cs.R1CS:
+			n := c.Constraints.Len()
+			wires, err = c.Solve(witness[:], make([]fr.Element, n), make([]fr.Element, n), make([]fr.Element, n), backend.ProverOption{})
+		case *cs.SparseR1CS:
+			wires, err = c.Solve(witness[:], backend.ProverOption{})
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := make([]*big.Int, len(wires))
+		for i := range wires {
+			values[i] = new(big.Int)
+			wires[i].ToBigIntRegular(values[i])
+		}
+
+		// the solution satisfies all the constraints
+		satisfied, err := ccs.CheckAssignment(values)
+		if err != nil {
+			t.Fatal(b, err)
+		}
+		if len(satisfied) != ccs.GetNbConstraints() {
+			t.Fatal(b, "the bitmap should have a bit per constraint")
+		}
+		for i := range satisfied {
+			if !satisfied[i] {
+				t.Fatal(b, "constraint", i, "should be satisfied")
+			}
+		}
+
+		// a perturbed internal wire breaks the constraints using it
+		wire := public + secret + internal - 1
+		values[wire] = new(big.Int).Add(values[wire], big.NewInt(1))
+		satisfied, err = ccs.CheckAssignment(values)
+		var summary *backend.UnsatisfiedAssignmentError
+		if !errors.As(err, &summary) || !errors.Is(err, backend.ErrUnsatisfiedAssignment) {
+			t.Fatal(b, "expected an unsatisfied assignment error, got", err)
+		}
+		nbUnsatisfied := 0
+		for i := range satisfied {
+			if !satisfied[i] {
+				nbUnsatisfied++
+			}
+		}
+		if nbUnsatisfied == 0 || summary.NbUnsatisfied != nbUnsatisfied || summary.NbChecked != ccs.GetNbConstraints() {
+			t.Fatal(b, "the summary doesn't match the bitmap", summary, nbUnsatisfied)
+		}
+		if _, err = ccs.CheckAssignmentUpTo(values, 1); !errors.As(err, &summary) || summary.NbUnsatisfied < 1 {
+			t.Fatal(b, "expected an unsatisfied assignment error, got", err)
+		}
+
+		// the assignment must have a value per wire
+		if _, err = ccs.CheckAssignment(values[1:]); err == nil || errors.Is(err, backend.ErrUnsatisfiedAssignment) {
+			t.Fatal(b, "expected an invalid assignment size error, got", err)
+		}
+	}
+}
+
+type constantTimeHintsCircuit struct {
+	X frontend.Variable
+}
+
+func (circuit *constantTimeHintsCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	isZero := api.IsZero(circuit.X)
+	api.AssertIsEqual(api.NewHint(hint.IsZero, circuit.X), isZero)
+	api.AssertIsEqual(api.NewAnnotatedHint(hint.IsZeroNamed, circuit.X)[0], isZero)
+	api.AssertIsEqual(api.NewHint(hint.InvMod, circuit.X), api.InverseOrZero(circuit.X))
+	api.ToBinary(circuit.X)
+	return nil
+}
+
+func TestConstantTimeHints(t *testing.T) {
+	q := fr.Modulus()
+	values := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		new(big.Int).Sub(q, big.NewInt(1)),
+		new(big.Int).Lsh(big.NewInt(1), 64),
+	}
+
+	for _, b := range backend.Implemented() {
+		ccs, err := frontend.Compile(ecc.BN254, b, &constantTimeHintsCircuit{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		solve := func(witness []fr.Element, opt backend.ProverOption) []fr.Element {
+			var wires []fr.Element
+			switch c := ccs.(type) {
+			case *cs.R1CS:
+				n := c.Constraints.Len()
+				wires, err = c.Solve(witness, make([]fr.Element, n), make([]fr.Element, n), make([]fr.Element, n), opt)
+			case *cs.SparseR1CS:
+				wires, err = c.Solve(witness, opt)
+			}
+			if err != nil {
+				t.Fatal(b, err)
+			}
+			return wires
+		}
+
+		for _, v := range values {
+			witness := make([]fr.Element, 1)
+			witness[0].SetBigInt(v)
+			expected := solve(witness, backend.ProverOption{})
+			wires := solve(witness, backend.ProverOption{ConstantTime: true})
+			if !reflect.DeepEqual(expected, wires) {
+				t.Fatal(b, v, "the solutions with and without constant time hints differ")
+			}
+		}
+	}
+}
+
+// shapesCircuit has constraints of all the fast path shapes of the solver, see compiled.R1CShape
+type shapesCircuit struct {
+	X, Y frontend.Variable `gnark:",secret"`
+	Z    frontend.Variable `gnark:",public"`
+}
+
+func (circuit *shapesCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	xy := api.Mul(circuit.X, circuit.Y)                                 // ShapeOne
+	s := api.Mul(api.Add(circuit.X, circuit.Y), api.Add(circuit.Y, xy)) // ShapeSumOne
+	t := api.Mul(api.Mul(circuit.X, 3), circuit.Y)                      // ShapeTerm
+	d := api.Div(s, circuit.Y)                                          // solved in L or R
+	api.AssertIsEqual(api.Add(t, d, xy), circuit.Z)
+	return nil
+}
+
+func TestShapes(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &shapesCircuit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1cs := ccs.(*cs.R1CS)
+
+	// the constraints have all the shapes
+	seen := make(map[compiled.LinearShape]bool)
+	for _, b := range r1cs.Constraints.Blueprints {
+		if b.Shape.Fast() {
+			seen[b.Shape.L()], seen[b.Shape.R()], seen[b.Shape.O()] = true, true, true
+		}
+	}
+	for _, shape := range []compiled.LinearShape{compiled.ShapeOne, compiled.ShapeTerm, compiled.ShapeSumOne} {
+		if !seen[shape] {
+			t.Fatal("no constraint of shape", shape)
+		}
+	}
+
+	// the same constraint system, solved without the fast paths
+	var buf bytes.Buffer
+	if _, err := r1cs.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var generic cs.R1CS
+	if _, err := generic.ReadFrom(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	for i := range generic.Constraints.Blueprints {
+		generic.Constraints.Blueprints[i].Shape = 0
+	}
+
+	// x = 3, y = 5: z = 3*x*y + (x+y)*(y+x*y)/y + x*y = 92
+	solve := func(r1cs *cs.R1CS, z uint64) ([]fr.Element, [3][]fr.Element, error) {
+		var witness [3]fr.Element // [public | secret]
+		witness[0].SetUint64(z)
+		witness[1].SetUint64(3)
+		witness[2].SetUint64(5)
+		n := r1cs.Constraints.Len()
+		abc := [3][]fr.Element{make([]fr.Element, n), make([]fr.Element, n), make([]fr.Element, n)}
+		wires, err := r1cs.Solve(witness[:], abc[0], abc[1], abc[2], backend.ProverOption{})
+		return wires, abc, err
+	}
+	wires, abc, err := solve(r1cs, 92)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedWires, expectedABC, err := solve(&generic, 92)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(wires, expectedWires) || !reflect.DeepEqual(abc, expectedABC) {
+		t.Fatal("the solutions with and without the fast paths differ")
+	}
+	if _, _, err := solve(r1cs, 93); !errors.Is(err, cs.ErrUnsatisfiedConstraint) {
+		t.Fatal("expected an unsatisfied constraint, got", err)
+	}
+
+	// a shape which isn't the one of the terms of its blueprint is rejected when decoded
+	for i, b := range r1cs.Constraints.Blueprints {
+		if b.Shape.Fast() {
+			generic.Constraints.Blueprints[i].Shape = b.Shape ^ 0b01
+			break
+		}
+	}
+	buf.Reset()
+	if _, err := generic.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var tampered cs.R1CS
+	if _, err := tampered.ReadFrom(&buf); err == nil {
+		t.Fatal("expected an invalid shape error")
+	}
+}
+
+// mulChainCircuit multiplies X by itself, or by itself plus X, in turns: its constraints are the
+// common shapes of the fast paths of the solver, see compiled.R1CShape
+type mulChainCircuit struct {
+	nbConstraints int
+	X             frontend.Variable `gnark:",secret"`
+	Y             frontend.Variable `gnark:",public"`
+}
+
+func (circuit *mulChainCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	x := circuit.X
+	for i := 0; i < circuit.nbConstraints; i++ {
+		if i%2 == 0 {
+			x = api.Mul(x, x)
+		} else {
+			x = api.Mul(api.Add(x, circuit.X), x)
+		}
+	}
+	api.AssertIsEqual(x, circuit.Y)
+	return nil
+}
+
+// mulChainWitness returns the witness of mulChainCircuit for x
+func mulChainWitness(nbConstraints int, x uint64) []fr.Element {
+	var v, y, s fr.Element
+	v.SetUint64(x)
+	y.Set(&v)
+	for i := 0; i < nbConstraints; i++ {
+		if i%2 == 0 {
+			y.Square(&y)
+		} else {
+			s.Add(&y, &v)
+			y.Mul(&s, &y)
+		}
+	}
+	return []fr.Element{y, v} // [public | secret]
+}
+
+// BenchmarkSolve measures the solver on a chain of multiplications, with and without the fast paths
+// (mul-generic clears the shapes of the constraints), and on the circuits of the registry
+func BenchmarkSolve(b *testing.B) {
+	for _, generic := range []bool{false, true} {
+		name := "mul"
+		if generic {
+			name = "mul-generic"
+		}
+		b.Run(name, func(b *testing.B) {
+			const nbConstraints = 40000
+			ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &mulChainCircuit{nbConstraints: nbConstraints})
+			if err != nil {
+				b.Fatal(err)
+			}
+			r1cs := ccs.(*cs.R1CS)
+			if generic {
+				for i := range r1cs.Constraints.Blueprints {
+					r1cs.Constraints.Blueprints[i].Shape = 0
+				}
+			}
+			witness := mulChainWitness(nbConstraints, 3)
+			n := r1cs.Constraints.Len()
+			a, bb, c := make([]fr.Element, n), make([]fr.Element, n), make([]fr.Element, n)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := r1cs.Solve(witness, a, bb, c, backend.ProverOption{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+
+	b.Run("registry", func(b *testing.B) {
+		type instance struct {
+			r1cs    *cs.R1CS
+			witness []fr.Element
+			opt     backend.ProverOption
+		}
+		names := make([]string, 0, len(circuits.Circuits))
+		for name, circuit := range circuits.Circuits {
+			if circuit.ExpectedCompileError == "" {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		var instances []instance
+		for _, name := range names {
+			circuit := circuits.Circuits[name]
+			ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, circuit.Circuit)
+			if err != nil {
+				b.Fatal(name, err)
+			}
+			var w bn254witness.Witness
+			if err := w.FromFullAssignment(circuit.ValidWitnesses[0]); err != nil {
+				b.Fatal(name, err)
+			}
+			opt, err := backend.NewProverOption(backend.WithHints(circuit.HintFunctions...))
+			if err != nil {
+				b.Fatal(name, err)
+			}
+			instances = append(instances, instance{ccs.(*cs.R1CS), w, opt})
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for _, inst := range instances {
+				n := inst.r1cs.Constraints.Len()
+				if _, err := inst.r1cs.Solve(inst.witness, make([]fr.Element, n), make([]fr.Element, n), make([]fr.Element, n), inst.opt); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
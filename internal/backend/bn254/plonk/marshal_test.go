@@ -21,8 +21,13 @@ import (
 
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 
-	"bytes"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+
+	"bytes"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"math/big"
+
+	"github.com/consensys/gnark/internal/backend/common"
 	"reflect"
 	"testing"
 )
@@ -89,6 +94,70 @@ func TestProvingKeySerialization(t *testing.T) {
 	}
 }
 
+func TestProofCBOR(t *testing.T) {
+	_, _, g1gen, _ := curve.Generators()
+	var g1, g2 curve.G1Affine
+	g1.ScalarMultiplication(&g1gen, big.NewInt(2))
+	g2.ScalarMultiplication(&g1gen, big.NewInt(3))
+
+	var proof Proof
+	proof.LRO = [3]kzg.Digest{g1gen, g1, g2}
+	proof.Z = g1
+	proof.H = [3]kzg.Digest{g2, g1, g1gen}
+	proof.BatchedProof.H = g2
+	proof.BatchedProof.Point.SetUint64(7)
+	proof.BatchedProof.ClaimedValues = make([]fr.Element, 6)
+	for i := range proof.BatchedProof.ClaimedValues {
+		proof.BatchedProof.ClaimedValues[i].SetUint64(uint64(i))
+		proof.BatchedProof.ClaimedValues[i].Neg(&proof.BatchedProof.ClaimedValues[i])
+	}
+	proof.ZShiftedOpening.H = g1gen
+	proof.ZShiftedOpening.Point.SetUint64(42)
+	proof.ZShiftedOpening.ClaimedValue.SetOne()
+
+	roundTrip := func() {
+		data, err := proof.MarshalCBOR()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded Proof
+		if err := decoded.UnmarshalCBOR(data); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(&proof, &decoded) {
+			t.Fatal("reconstructed proof doesn't match the original")
+		}
+	}
+	roundTrip()
+
+	proof.Lookup = &LookupProof{F: g1, H1: g2, H2: g1gen, Z: g1}
+	proof.Lookup.BatchedProof = proof.BatchedProof
+	proof.Lookup.ShiftedBatchedProof.H = g1
+	proof.Lookup.ShiftedBatchedProof.ClaimedValues = make([]fr.Element, 4)
+	roundTrip()
+
+	// a field element which is not reduced is rejected
+	p := proofCBOR{
+		CBORHeader:   common.NewCBORHeader(common.CBORPlonkProof, curve.ID),
+		LRO:          [][]byte{g1Bytes(&g1), g1Bytes(&g1), g1Bytes(&g1)},
+		Z:            g1Bytes(&g1),
+		H:            [][]byte{g1Bytes(&g1), g1Bytes(&g1), g1Bytes(&g1)},
+		BatchedProof: batchOpeningProofToCBOR(&proof.BatchedProof),
+		ZShiftedOpening: openingProofCBOR{
+			H:            g1Bytes(&g1),
+			Point:        bytes.Repeat([]byte{0xff}, fr.Bytes),
+			ClaimedValue: frBytes(&proof.ZShiftedOpening.ClaimedValue),
+		},
+	}
+	data, err := common.CBOREncMode.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := new(Proof).UnmarshalCBOR(data); err == nil {
+		t.Fatal("expected an error with a field element which is not reduced")
+	}
+}
+
 func TestVerifyingKeySerialization(t *testing.T) {
 	// create a random vk
 	var vk VerifyingKey
@@ -106,6 +175,8 @@ func TestVerifyingKeySerialization(t *testing.T) {
 	vk.Qo = g1gen
 	vk.Qk = g1gen
 
+	vk.Lookup = &LookupVerifyingKey{Qlk: g1gen, Qtab: g1gen, TValue: g1gen, TTag: g1gen}
+
 	var buf bytes.Buffer
 	written, err := vk.WriteTo(&buf)
 	if err != nil {
@@ -126,4 +197,21 @@ func TestVerifyingKeySerialization(t *testing.T) {
 	if written != read {
 		t.Fatal("bytes written / read don't match")
 	}
+
+	// without lookups, the key has no lookup section
+	vk.Lookup = nil
+	buf.Reset()
+	if _, err := vk.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), lookupMarker) {
+		t.Fatal("unexpected lookup section")
+	}
+	reconstructed = VerifyingKey{}
+	if _, err := reconstructed.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&vk, &reconstructed) {
+		t.Fatal("reconstructed object don't match original")
+	}
 }
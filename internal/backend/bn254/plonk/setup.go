@@ -23,8 +23,11 @@ import (
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/polynomial"
 	"github.com/consensys/gnark/internal/backend/bn254/cs"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	"time"
 
 	kzgg "github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/logger"
 )
 
 // ProvingKey stores the data needed to generate a proof:
@@ -55,6 +58,22 @@ type ProvingKey struct {
 
 	// position -> permuted position (position in [0,3*sizeSystem-1])
 	Permutation []int64
+
+	// Lookup is nil if the circuit has no lookups
+	Lookup *LookupProvingKey
+}
+
+// LookupProvingKey stores the data needed to prove the lookups (plookup):
+// * qlk, set to one on the rows of the lookups
+// * qtab, the index of the looked up table on the rows of the lookups
+// * t, the concatenation of the tables, split in values and table indexes, padded with its last entry
+type LookupProvingKey struct {
+	// qlk, qtab (in canonical basis)
+	Qlk, Qtab polynomial.Polynomial
+
+	// entries and table indexes of t (L=Lagrange basis, C=canonical basis)
+	LTValue, LTTag polynomial.Polynomial
+	CTValue, CTTag polynomial.Polynomial
 }
 
 // VerifyingKey stores the data needed to verify a proof:
@@ -82,10 +101,23 @@ type VerifyingKey struct {
 	// Commitments to ql, qr, qm, qo prepended with as many zeroes (ones for l) as there are public inputs.
 	// In particular Qk is not complete.
 	Ql, Qr, Qm, Qo, Qk kzg.Digest
+
+	// Lookup is nil if the circuit has no lookups
+	Lookup *LookupVerifyingKey
+
+	// metadata of the constraint system (see frontend.WithMetadata)
+	Metadata map[string]string
+}
+
+// LookupVerifyingKey stores the commitments to qlk, qtab and to the entries and
+// table indexes of t (see LookupProvingKey)
+type LookupVerifyingKey struct {
+	Qlk, Qtab, TValue, TTag kzg.Digest
 }
 
 // Setup sets proving and verifying keys
 func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error) {
+	start := time.Now()
 	var pk ProvingKey
 	var vk VerifyingKey
 
@@ -96,6 +128,23 @@ func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error)
 
 	// fft domains
 	sizeSystem := uint64(nbConstraints + spr.NbPublicVariables) // spr.NbPublicVariables is for the placeholder constraints
+	if len(spr.Lookups) != 0 {
+		// the last row can't be a lookup (plookup ignores the last entry of f),
+		// and the concatenated tables must fit in the domain
+		sizeSystem++
+		nbEntries := 0
+		for _, table := range spr.Tables {
+			nbEntries += len(table.Entries)
+		}
+		if uint64(nbEntries) > sizeSystem {
+			sizeSystem = uint64(nbEntries)
+		}
+	}
+	if sizeSystem < 2 {
+		// with a single row, the quotient doesn't fit in DomainH (computeH needs DomainH to be 4 or 8
+		// times bigger than DomainNum)
+		sizeSystem = 2
+	}
 	pk.DomainNum = *fft.NewDomain(sizeSystem, 0, false)
 
 	// h, the quotient polynomial is of degree 3(n+1)+2, so it's in a 3(n+2) dim vector space,
@@ -192,6 +241,27 @@ func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error)
 		return nil, nil, err
 	}
 
+	if len(spr.Lookups) != 0 {
+		setupLookup(spr, &pk)
+		vk.Lookup = &LookupVerifyingKey{}
+		if vk.Lookup.Qlk, err = kzg.Commit(pk.Lookup.Qlk, vk.KZGSRS); err != nil {
+			return nil, nil, err
+		}
+		if vk.Lookup.Qtab, err = kzg.Commit(pk.Lookup.Qtab, vk.KZGSRS); err != nil {
+			return nil, nil, err
+		}
+		if vk.Lookup.TValue, err = kzg.Commit(pk.Lookup.CTValue, vk.KZGSRS); err != nil {
+			return nil, nil, err
+		}
+		if vk.Lookup.TTag, err = kzg.Commit(pk.Lookup.CTTag, vk.KZGSRS); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	vk.Metadata = compiled.CopyMetadata(spr.Metadata)
+
+	logger.Debug("plonk setup: %d constraints, domain size %d, done in %s", nbConstraints, pk.DomainNum.Cardinality, time.Since(start))
+
 	return &pk, &vk, nil
 
 }
@@ -256,6 +326,55 @@ func buildPermutation(spr *cs.SparseR1CS, pk *ProvingKey) {
 	}
 }
 
+// setupLookup sets pk.Lookup: qlk, qtab and t.
+//
+// t is the concatenation of the tables [ table_0 | table_1 | .. ], each entry being
+// tagged with the index of its table. It is padded with its last entry to reach the domain size.
+func setupLookup(spr *cs.SparseR1CS, pk *ProvingKey) {
+	nbElmt := int(pk.DomainNum.Cardinality)
+
+	lk := &LookupProvingKey{
+		Qlk:     make(polynomial.Polynomial, nbElmt),
+		Qtab:    make(polynomial.Polynomial, nbElmt),
+		LTValue: make(polynomial.Polynomial, nbElmt),
+		LTTag:   make(polynomial.Polynomial, nbElmt),
+		CTValue: make(polynomial.Polynomial, nbElmt),
+		CTTag:   make(polynomial.Polynomial, nbElmt),
+	}
+
+	offset := spr.NbPublicVariables
+	for _, l := range spr.Lookups {
+		lk.Qlk[offset+l.Constraint].SetOne()
+		lk.Qtab[offset+l.Constraint].SetUint64(uint64(l.Table))
+	}
+
+	i := 0
+	for j, table := range spr.Tables {
+		for _, cID := range table.Entries {
+			lk.LTValue[i].Set(&spr.Coefficients[cID])
+			lk.LTTag[i].SetUint64(uint64(j))
+			i++
+		}
+	}
+	for ; i < nbElmt; i++ {
+		lk.LTValue[i].Set(&lk.LTValue[i-1])
+		lk.LTTag[i].Set(&lk.LTTag[i-1])
+	}
+	copy(lk.CTValue, lk.LTValue)
+	copy(lk.CTTag, lk.LTTag)
+
+	pk.DomainNum.FFTInverse(lk.Qlk, fft.DIF, 0)
+	pk.DomainNum.FFTInverse(lk.Qtab, fft.DIF, 0)
+	pk.DomainNum.FFTInverse(lk.CTValue, fft.DIF, 0)
+	pk.DomainNum.FFTInverse(lk.CTTag, fft.DIF, 0)
+	fft.BitReverse(lk.Qlk)
+	fft.BitReverse(lk.Qtab)
+	fft.BitReverse(lk.CTValue)
+	fft.BitReverse(lk.CTTag)
+
+	pk.Lookup = lk
+}
+
 // computeLDE computes the LDE (Lagrange basis) of the permutations
 // s1, s2, s3.
 //
@@ -343,3 +462,13 @@ func (vk *VerifyingKey) NbPublicWitness() int {
 func (pk *ProvingKey) VerifyingKey() interface{} {
 	return pk.Vk
 }
+
+// GetMetadata returns the metadata of the constraint system the key was generated for
+func (pk *ProvingKey) GetMetadata() map[string]string {
+	return pk.Vk.Metadata
+}
+
+// GetMetadata returns the metadata of the constraint system the key was generated for
+func (vk *VerifyingKey) GetMetadata() map[string]string {
+	return vk.Metadata
+}
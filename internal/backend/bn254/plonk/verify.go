@@ -42,8 +42,12 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 	// pick a hash function to derive the challenge (the same as in the prover)
 	hFunc := sha256.New()
 
+	if (vk.Lookup == nil) != (proof.Lookup == nil) {
+		return errLookupProofMismatch
+	}
+
 	// transcript to derive the challenge
-	fs := fiatshamir.NewTranscript(hFunc, "gamma", "alpha", "zeta")
+	fs := newTranscript(hFunc, vk.Lookup != nil)
 
 	// derive gamma from Comm(l), Comm(r), Comm(o)
 	gamma, err := deriveRandomness(&fs, "gamma", &proof.LRO[0], &proof.LRO[1], &proof.LRO[2])
@@ -51,8 +55,16 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 		return err
 	}
 
-	// derive alpha from Comm(l), Comm(r), Comm(o), Com(Z)
-	alpha, err := deriveRandomness(&fs, "alpha", &proof.Z)
+	// derive eta, beta, gamma for the lookups from Comm(f), Comm(h1), Comm(h2)
+	var lkChallenges lookupChallenges
+	if vk.Lookup != nil {
+		if lkChallenges, err = deriveLookupChallenges(&fs, proof); err != nil {
+			return err
+		}
+	}
+
+	// derive alpha from Comm(l), Comm(r), Comm(o), Com(Z) (and Com(Z) of the lookups)
+	alpha, err := deriveRandomness(&fs, "alpha", zDigests(proof)...)
 	if err != nil {
 		return err
 	}
@@ -120,6 +132,18 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 									Add(&linearizedPolynomialZeta, &_s1).                // linearizedpolynomial+pi(zeta)+alpha*Z(u*zeta)*(a+s1+gamma)*(b+s2+gamma)*(c+gamma)
 									Sub(&linearizedPolynomialZeta, &alphaSquareLagrange) // linearizedpolynomial+pi(zeta)+(Z(u*zeta))*(a+s1+gamma)*(b+s2+gamma)*(c+gamma)*alpha-alpha**2*L1(zeta)
 
+	if vk.Lookup != nil {
+		// add alpha**3*lookup(zeta)
+		lookupZeta, err := evalLookupConstraintsAtZeta(proof, vk, lkChallenges, l, lagrangeOne, zeta, alpha)
+		if err != nil {
+			return err
+		}
+		var alphaCube fr.Element
+		alphaCube.Square(&alpha).Mul(&alphaCube, &alpha)
+		lookupZeta.Mul(&lookupZeta, &alphaCube)
+		linearizedPolynomialZeta.Add(&linearizedPolynomialZeta, &lookupZeta)
+	}
+
 	// Compute H(zeta) using the previous result: H(zeta) = prev_result/(zeta**n-1)
 	var zetaPowerMMinusOne fr.Element
 	zetaPowerMMinusOne.Sub(&zetaPowerM, &one)
@@ -200,17 +224,27 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bn254witness.Witness)
 		return err
 	}
 
-	// Batch verify
-	return kzg.BatchVerifyMultiPoints([]kzg.Digest{
+	digests := []kzg.Digest{
 		foldedDigest,
 		proof.Z,
-	},
-		[]kzg.OpeningProof{
-			foldedProof,
-			proof.ZShiftedOpening,
-		},
-		vk.KZGSRS,
-	)
+	}
+	proofs := []kzg.OpeningProof{
+		foldedProof,
+		proof.ZShiftedOpening,
+	}
+
+	// Fold the proofs of the lookups
+	if vk.Lookup != nil {
+		lkDigests, lkProofs, err := foldLookupProofs(proof, vk, lkChallenges, hFunc)
+		if err != nil {
+			return err
+		}
+		digests = append(digests, lkDigests...)
+		proofs = append(proofs, lkProofs...)
+	}
+
+	// Batch verify
+	return kzg.BatchVerifyMultiPoints(digests, proofs, vk.KZGSRS)
 }
 
 func deriveRandomness(fs *fiatshamir.Transcript, challenge string, points ...*curve.G1Affine) (fr.Element, error) {
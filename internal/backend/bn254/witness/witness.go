@@ -22,11 +22,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"reflect"
+	"runtime"
+	"sync"
 
+	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/internal/backend/compiled"
-	"github.com/consensys/gnark/internal/parser"
+	"github.com/consensys/gnark/internal/utils"
+	gnarkio "github.com/consensys/gnark/io"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 
@@ -54,22 +57,35 @@ func (witness *Witness) WriteTo(w io.Writer) (int64, error) {
 // LimitReadFrom decodes witness from reader; first 4 bytes (uint32) must equal to expectedSize
 // this method won't read more than expectedSize * size(fr.Element)
 func (witness *Witness) LimitReadFrom(r io.Reader, expectedSize int) (int64, error) {
+	return witness.LimitReadFromOptional(r, expectedSize, 0)
+}
+
+// LimitReadFromOptional behaves like LimitReadFrom, but the last nbOptional values (optional secret
+// inputs) may be omitted: first 4 bytes (uint32) must be in [expectedSize - nbOptional, expectedSize],
+// and the omitted values are set to 0
+func (witness *Witness) LimitReadFromOptional(r io.Reader, expectedSize, nbOptional int) (int64, error) {
+	lr := gnarkio.NewLimitReader(r)
 
 	var buf [4]byte
-	if read, err := io.ReadFull(r, buf[:4]); err != nil {
+	if read, err := io.ReadFull(lr, buf[:4]); err != nil {
 		return int64(read), err
 	}
 	sliceLen := binary.BigEndian.Uint32(buf[:4])
-	if int(sliceLen) != expectedSize {
+	if int(sliceLen) > expectedSize || int(sliceLen) < expectedSize-nbOptional {
 		return 4, errors.New("invalid witness size")
 	}
+	if err := lr.Check("witness", uint64(sliceLen), fr.Bytes, fr.Bytes); err != nil {
+		return 4, err
+	}
 
-	if len(*witness) != int(sliceLen) {
-		*witness = make([]fr.Element, sliceLen)
+	if len(*witness) != expectedSize {
+		*witness = make([]fr.Element, expectedSize)
+	}
+	for i := int(sliceLen); i < expectedSize; i++ {
+		(*witness)[i].SetZero()
 	}
 
-	lr := io.LimitReader(r, int64(expectedSize*fr.Limbs*8))
-	dec := curve.NewDecoder(lr)
+	dec := curve.NewDecoder(io.LimitReader(lr, int64(expectedSize*fr.Limbs*8)))
 
 	for i := 0; i < int(sliceLen); i++ {
 		if err := dec.Decode(&(*witness)[i]); err != nil {
@@ -80,93 +96,183 @@ func (witness *Witness) LimitReadFrom(r io.Reader, expectedSize int) (int64, err
 	return dec.BytesRead() + 4, nil
 }
 
-// FromFullAssignment extracts the full witness [ public | secret ]
+// FromFullAssignment extracts the full witness [ public | secret ]; missing optional secret inputs
+// are set to 0
 func (witness *Witness) FromFullAssignment(w frontend.Circuit) error {
-	nbSecret, nbPublic := count(w)
+	return witness.fromAssignment(w, false, runtime.NumCPU())
+}
 
-	if len(*witness) < (nbPublic + nbSecret) {
-		(*witness) = make(Witness, nbPublic+nbSecret)
-	} else {
-		(*witness) = (*witness)[:nbPublic+nbSecret]
+// FromPublicAssignment extracts the public part of witness
+func (witness *Witness) FromPublicAssignment(w frontend.Circuit) error {
+	return witness.fromAssignment(w, true, runtime.NumCPU())
+}
+
+// minParallelAssignment is the number of inputs under which a witness is built on a single goroutine
+const minParallelAssignment = 1 << 12
+
+// fromAssignment collects the inputs of w and their index in the witness vector in a single walk
+// of the circuit structure, then converts their values to field elements in parallel chunks
+// (on at most nbTasks goroutines), writing directly into the witness vector.
+func (witness *Witness) fromAssignment(w frontend.Circuit, publicOnly bool, nbTasks int) error {
+	if v, ok := w.(frVector); ok {
+		vector, ok := v.(*Vector)
+		if !ok {
+			return fmt.Errorf("witness vector of %s, expected %s", v.CurveID(), curve.ID)
+		}
+		witness.fromVector(vector, publicOnly)
+		return nil
 	}
 
-	var i, j int // indexes for secret / public variables
-	i = nbPublic // offset
+	schema, err := frontend.ParseSchema(w)
+	if err != nil {
+		return err
+	}
 
-	var collectHandler parser.LeafHandler = func(visibility compiled.Visibility, name string, tInput reflect.Value) error {
-		v := tInput.Interface().(frontend.Variable)
+	// note: does not contain ONE_WIRE for Groth16
+	n := schema.NbPublic
+	if !publicOnly {
+		n += schema.NbSecret
+	}
+	if len(*witness) < n {
+		(*witness) = make(Witness, n)
+	} else {
+		(*witness) = (*witness)[:n]
+	}
 
-		if v.WitnessValue == nil {
-			return fmt.Errorf("when parsing variable %s: missing assignment", name)
+	indexes := schema.WitnessIndexes()
+	fields := make([]*frontend.Field, 0, n)
+	values := make([]*frontend.Variable, 0, n)
+	wires := make([]int, 0, n)
+
+	var k int // index of the visited input in the schema
+	collectHandler := func(f *frontend.Field, v *frontend.Variable) error {
+		wire := indexes[k]
+		k++
+		// secret inputs are after the public ones in the witness vector
+		if wire < 0 || wire >= n {
+			return nil
 		}
+		fields = append(fields, f)
+		values = append(values, v)
+		wires = append(wires, wire)
+		return nil
+	}
+	if err := schema.Visit(w, collectHandler); err != nil {
+		return err
+	}
+
+	if len(values) < minParallelAssignment {
+		nbTasks = 1
+	}
 
-		if visibility == compiled.Secret {
-			if _, err := (*witness)[i].SetInterface(v.WitnessValue); err != nil {
-				return fmt.Errorf("when parsing variable %s: %v", name, err)
+	var (
+		lock     sync.Mutex
+		firstErr error
+		firstIdx = len(values)
+	)
+	utils.Parallelize(len(values), func(start, end int) {
+		for i := start; i < end; i++ {
+			var err error
+			if values[i].WitnessValue == nil {
+				// the public outputs are computed by the solver, the verifier must assign them
+				if fields[i].Optional || (fields[i].Output && !publicOnly) {
+					(*witness)[wires[i]].SetZero()
+					continue
+				}
+				err = fmt.Errorf("when parsing variable %s: missing assignment", fields[i].Name)
+			} else if _, errSet := (*witness)[wires[i]].SetInterface(values[i].WitnessValue); errSet != nil {
+				err = fmt.Errorf("when parsing variable %s: %v", fields[i].Name, errSet)
 			}
-			i++
-		} else if visibility == compiled.Public {
-			if _, err := (*witness)[j].SetInterface(v.WitnessValue); err != nil {
-				return fmt.Errorf("when parsing variable %s: %v", name, err)
+			if err != nil {
+				// report the first invalid input in the order of the schema, whatever the scheduling
+				lock.Lock()
+				if i < firstIdx {
+					firstIdx, firstErr = i, err
+				}
+				lock.Unlock()
+				return
 			}
-			j++
 		}
-		return nil
-	}
-	return parser.Visit(w, "", compiled.Unset, collectHandler, reflect.TypeOf(frontend.Variable{}))
+	}, nbTasks)
+
+	return firstErr
 }
 
-// FromPublicAssignment extracts the public part of witness
-func (witness *Witness) FromPublicAssignment(w frontend.Circuit) error {
-	_, nbPublic := count(w)
+// Vector is a witness of field elements: the values of the public and secret inputs, in the order
+// of the compiled constraint system (see witness.WriteSequence in gnark/backend/witness).
+//
+// It can be used wherever an assignment is expected, for example in Prove and Verify: the values
+// are copied to the witness vector, without the conversion of the values of a circuit structure.
+type Vector struct {
+	public, secret []fr.Element
+}
 
-	// note: does not contain ONE_WIRE for Groth16
-	if len(*witness) < (nbPublic) {
-		(*witness) = make(Witness, nbPublic)
-	} else {
-		(*witness) = (*witness)[:nbPublic]
-	}
-	var j int // index for public variables
+// frVector is a witness vector of any curve (see FrVector in gnark/backend/witness)
+type frVector interface {
+	CurveID() ecc.ID
+	NbInputs() (nbPublic, nbSecret int)
+}
 
-	var collectHandler parser.LeafHandler = func(visibility compiled.Visibility, name string, tInput reflect.Value) error {
-		if visibility == compiled.Public {
-			v := tInput.Interface().(frontend.Variable)
+// FromFrSlice returns a witness holding public and secret, which are not copied. secret may be empty
+// to build a public witness. The sizes are validated against the compiled constraint system by
+// Prove, IsSolved and Verify.
+func FromFrSlice(public, secret []fr.Element) *Vector {
+	return &Vector{public: public, secret: secret}
+}
 
-			if v.WitnessValue == nil {
-				return fmt.Errorf("when parsing variable %s: missing assignment", name)
-			}
+// Define is not meant to be called, a Vector is only an assignment
+func (v *Vector) Define(curveID ecc.ID, api frontend.API) error {
+	return errors.New("a witness vector is not a circuit definition")
+}
 
-			if _, err := (*witness)[j].SetInterface(v.WitnessValue); err != nil {
-				return fmt.Errorf("when parsing variable %s: %v", name, err)
-			}
-			j++
-		}
-		return nil
-	}
-	return parser.Visit(w, "", compiled.Unset, collectHandler, reflect.TypeOf(frontend.Variable{}))
+// CurveID returns the curve of the scalar field of the values
+func (v *Vector) CurveID() ecc.ID {
+	return curve.ID
 }
 
-func count(w frontend.Circuit) (nbSecret, nbPublic int) {
-	var collectHandler parser.LeafHandler = func(visibility compiled.Visibility, name string, tInput reflect.Value) error {
-		if visibility == compiled.Secret {
-			nbSecret++
-		} else if visibility == compiled.Public {
-			nbPublic++
-		}
-		return nil
+// NbInputs returns the number of public and secret values
+func (v *Vector) NbInputs() (nbPublic, nbSecret int) {
+	return len(v.public), len(v.secret)
+}
+
+// ToFrSlices returns the public and secret values, which are not copied
+func (v *Vector) ToFrSlices() (public, secret []fr.Element) {
+	return v.public, v.secret
+}
+
+// ToFrSlices splits the full witness [ public | secret ] in its nbPublic public values and its
+// secret values, which are not copied
+func (witness Witness) ToFrSlices(nbPublic int) (public, secret []fr.Element, err error) {
+	if nbPublic < 0 || nbPublic > len(witness) {
+		return nil, nil, fmt.Errorf("invalid number of public inputs: got %d, witness has %d values", nbPublic, len(witness))
 	}
+	return witness[:nbPublic], witness[nbPublic:], nil
+}
 
-	err := parser.Visit(w, "", compiled.Unset, collectHandler, reflect.TypeOf(frontend.Variable{}))
-	if err != nil {
-		panic("count handler doesn't return an error -- this panic should not happen")
+// fromVector copies the values of v to the witness vector
+func (witness *Witness) fromVector(v *Vector, publicOnly bool) {
+	n := len(v.public)
+	if !publicOnly {
+		n += len(v.secret)
+	}
+	if len(*witness) < n {
+		(*witness) = make(Witness, n)
+	} else {
+		(*witness) = (*witness)[:n]
+	}
+	copy(*witness, v.public)
+	if !publicOnly {
+		copy((*witness)[len(v.public):], v.secret)
 	}
-	return
 }
 
 // ToJSON extracts the full witness [ public | secret ] and returns a JSON string
 // or an error if it can't convert values to field elements
 func ToJSON(w frontend.Circuit) (string, error) {
-	nbSecret, nbPublic := count(w)
+	schema, err := frontend.ParseSchema(w)
+	if err != nil {
+		return "", err
+	}
 
 	type jsonStruct struct {
 		Public map[string]string
@@ -174,37 +280,35 @@ func ToJSON(w frontend.Circuit) (string, error) {
 	}
 
 	toPrint := jsonStruct{
-		Public: make(map[string]string, nbPublic),
-		Secret: make(map[string]string, nbSecret),
+		Public: make(map[string]string, schema.NbPublic),
+		Secret: make(map[string]string, schema.NbSecret),
 	}
 
 	var e fr.Element
 
-	var collectHandler parser.LeafHandler = func(visibility compiled.Visibility, name string, tInput reflect.Value) error {
-		v := tInput.Interface().(frontend.Variable)
-
-		if visibility == compiled.Secret {
+	collectHandler := func(f *frontend.Field, v *frontend.Variable) error {
+		if f.Visibility == compiled.Secret {
 			if v.WitnessValue == nil {
-				toPrint.Secret[name] = "<nil>"
+				toPrint.Secret[f.Name] = "<nil>"
 			} else {
 				if _, err := e.SetInterface(v.WitnessValue); err != nil {
-					return fmt.Errorf("when parsing variable %s: %v", name, err)
+					return fmt.Errorf("when parsing variable %s: %v", f.Name, err)
 				}
-				toPrint.Secret[name] = e.String()
+				toPrint.Secret[f.Name] = e.String()
 			}
-		} else if visibility == compiled.Public {
+		} else if f.Visibility == compiled.Public {
 			if v.WitnessValue == nil {
-				toPrint.Public[name] = "<nil>"
+				toPrint.Public[f.Name] = "<nil>"
 			} else {
 				if _, err := e.SetInterface(v.WitnessValue); err != nil {
-					return fmt.Errorf("when parsing variable %s: %v", name, err)
+					return fmt.Errorf("when parsing variable %s: %v", f.Name, err)
 				}
-				toPrint.Public[name] = e.String()
+				toPrint.Public[f.Name] = e.String()
 			}
 		}
 		return nil
 	}
-	if err := parser.Visit(w, "", compiled.Unset, collectHandler, reflect.TypeOf(frontend.Variable{})); err != nil {
+	if err := schema.Visit(w, collectHandler); err != nil {
 		return "", err
 	}
 
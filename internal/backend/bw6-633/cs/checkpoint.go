@@ -0,0 +1,462 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package cs
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/logger"
+
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bw6-633"
+)
+
+// checkpointMagic starts the checkpoint files, followed by their version
+const (
+	checkpointMagic   = "gnarkckp"
+	checkpointVersion = 1
+)
+
+// checkpointStride is the number of constraints solved between two checks of the checkpoint interval
+const checkpointStride = 1024
+
+// checkpointer saves the state of a solver periodically, and restores it, see backend.WithCheckpoint.
+//
+// The checkpoint of a constraint system and a witness is the file <digest>.checkpoint, where the
+// digest covers the constraint system and the witness. It is written to a temporary file renamed in
+// place, such that the file is always the latest complete checkpoint:
+//
+//	magic, version (uint16), digest (32 bytes)
+//	nbWires, next constraint, nbSolved (uint64)
+//	bitmap of the solved wires (nbWires bits, padded to bytes)
+//	values of the wires (raw Montgomery limbs, little endian; zero for the unsolved wires)
+//	n (uint64), and the n first values of a, b, c (R1CS only, n = next constraint)
+//	SHA256 of the above
+//
+// The hint outputs are wires: the bitmap records the hints already called.
+//
+// The entries of a solution cache directory (see backend.WithSolutionCacheDir) are the checkpoints of the
+// solved witnesses, <digest>.solution, the digest covering the hint functions of the prover too.
+type checkpointer struct {
+	name     string // in the messages: solver checkpoint, or solution cache
+	path     string
+	digest   []byte
+	interval time.Duration
+	last     time.Time
+}
+
+// newCheckpointer returns the checkpointer of the solve of witness, nil if opt has no checkpoint directory
+func newCheckpointer(ccsDigest []byte, witness []fr.Element, opt backend.ProverOption) *checkpointer {
+	if opt.CheckpointDir == "" {
+		return nil
+	}
+	h := sha256.New()
+	h.Write(ccsDigest)
+	_ = writeElements(h, witness)
+	digest := h.Sum(nil)
+	return &checkpointer{
+		name:     "solver checkpoint",
+		path:     filepath.Join(opt.CheckpointDir, hex.EncodeToString(digest)+".checkpoint"),
+		digest:   digest,
+		interval: opt.CheckpointInterval,
+		last:     time.Now(),
+	}
+}
+
+// newSolutionCacheEntry returns the checkpointer of the entry of the solution of witness in the solution
+// cache directory, nil if opt has none. The entry is loaded and written once, it isn't due.
+func newSolutionCacheEntry(ccsDigest []byte, witness []fr.Element, opt backend.ProverOption) *checkpointer {
+	if opt.SolutionCacheDir == "" {
+		return nil
+	}
+	h := sha256.New()
+	h.Write(ccsDigest)
+	_ = writeElements(h, witness)
+
+	// the hint functions given to the prover, by ID
+	hints := append([]hint.AnnotatedFunction(nil), opt.HintFunctions...)
+	sort.Slice(hints, func(i, j int) bool { return hints[i].UUID() < hints[j].UUID() })
+	for _, f := range hints {
+		_ = writeUint64s(h, uint64(f.UUID()))
+		h.Write([]byte(f.String()))
+	}
+	digest := h.Sum(nil)
+	return &checkpointer{
+		name:   "solution cache",
+		path:   filepath.Join(opt.SolutionCacheDir, hex.EncodeToString(digest)+".solution"),
+		digest: digest,
+	}
+}
+
+// sampleConstraints returns the indexes of the constraints a solution loaded from the solution cache
+// directory is checked against: nbChecks of the n constraints, at random, all of them if nbChecks < 0
+// or nbChecks >= n, backend.DefaultSolutionCacheChecks if nbChecks == 0
+func sampleConstraints(n, nbChecks int) ([]int, error) {
+	if nbChecks == 0 {
+		nbChecks = backend.DefaultSolutionCacheChecks
+	}
+	if nbChecks < 0 || nbChecks >= n {
+		res := make([]int, n)
+		for i := range res {
+			res[i] = i
+		}
+		return res, nil
+	}
+	res := make([]int, nbChecks)
+	max := big.NewInt(int64(n))
+	for i := range res {
+		j, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = int(j.Int64())
+	}
+	return res, nil
+}
+
+// due returns true if the last checkpoint is older than the interval. It is called every
+// checkpointStride constraints.
+func (cp *checkpointer) due(constraint int) bool {
+	return constraint%checkpointStride == 0 && time.Since(cp.last) >= cp.interval
+}
+
+// save writes the checkpoint of the solver, which solved the constraints before next. a, b, c are the
+// vectors of the R1CS solver, nil for the SparseR1CS solver. A failure is logged, the solver goes on.
+func (cp *checkpointer) save(s *solution, next int, a, b, c []fr.Element) {
+	start := time.Now()
+	if err := cp.write(s, next, a, b, c); err != nil {
+		logger.Warn("%s: %v", cp.name, err)
+		return
+	}
+	cp.last = time.Now()
+	logger.Debug("%s: %s, %d constraints solved, saved in %s", cp.name, curve.ID, next, time.Since(start))
+}
+
+func (cp *checkpointer) write(s *solution, next int, a, b, c []fr.Element) error {
+	dir := filepath.Dir(cp.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, "*.checkpoint.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // once renamed, fails silently
+
+	h := sha256.New()
+	w := bufio.NewWriterSize(io.MultiWriter(f, h), 1<<20)
+	if err := cp.encode(w, s, next, a, b, c); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(h.Sum(nil)); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), cp.path)
+}
+
+func (cp *checkpointer) encode(w io.Writer, s *solution, next int, a, b, c []fr.Element) error {
+	nbWires := len(s.Solved)
+	var header [len(checkpointMagic) + 2]byte
+	copy(header[:], checkpointMagic)
+	binary.BigEndian.PutUint16(header[len(checkpointMagic):], checkpointVersion)
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(cp.digest); err != nil {
+		return err
+	}
+	if err := writeUint64s(w, uint64(nbWires), uint64(next), uint64(s.NbSolved)); err != nil {
+		return err
+	}
+
+	bitmap := make([]byte, (nbWires+7)/8)
+	for i := 0; i < nbWires; i++ {
+		if s.Solved[i] {
+			bitmap[i/8] |= 1 << (i % 8)
+		}
+	}
+	if _, err := w.Write(bitmap); err != nil {
+		return err
+	}
+	var buf [fr.Limbs * 8]byte
+	for i := 0; i < nbWires; i++ {
+		var v fr.Element
+		if s.Solved[i] {
+			v = s.get(i)
+		}
+		putElement(buf[:], &v)
+		if _, err := w.Write(buf[:]); err != nil {
+			return err
+		}
+	}
+
+	n := 0
+	if a != nil {
+		n = next
+	}
+	if err := writeUint64s(w, uint64(n)); err != nil {
+		return err
+	}
+	for _, v := range [][]fr.Element{a, b, c} {
+		if err := writeElements(w, v[:n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load restores the checkpoint in s, and in a, b, c for the R1CS solver. It returns the constraint
+// to resume the solve at: 0 if there is no checkpoint, or if it is invalid (for example, written for
+// another constraint system or witness, or truncated).
+//
+// The wires set in s before the call (the witness, ...) must have the values of the checkpoint. The
+// file is validated before s is modified: an error is returned only if the restore fails midway.
+func (cp *checkpointer) load(s *solution, nbConstraints int, a, b, c []fr.Element) (int, error) {
+	next, restoring, err := cp.read(s, nbConstraints, a, b, c)
+	if err != nil {
+		if restoring {
+			return 0, fmt.Errorf("%s %s: %w", cp.name, cp.path, err)
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warn("%s: ignoring %s: %v", cp.name, cp.path, err)
+		}
+		return 0, nil
+	}
+	logger.Info("%s: %s, resuming at constraint %d/%d", cp.name, curve.ID, next, nbConstraints)
+	return next, nil
+}
+
+// read restores the checkpoint, see load. restoring is set once s is modified.
+func (cp *checkpointer) read(s *solution, nbConstraints int, a, b, c []fr.Element) (next int, restoring bool, err error) {
+	// check the integrity of the file first, as the solution is restored while it's decoded
+	f, err := os.Open(cp.path)
+	if err != nil {
+		return 0, false, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return 0, false, err
+	}
+	size := stat.Size() - sha256.Size
+	if size < 0 {
+		return 0, false, errors.New("truncated checkpoint")
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, io.LimitReader(f, size)); err != nil {
+		return 0, false, err
+	}
+	expected := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(f, expected); err != nil {
+		return 0, false, err
+	}
+	if !bytes.Equal(h.Sum(nil), expected) {
+		return 0, false, errors.New("corrupted checkpoint")
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, false, err
+	}
+	r := bufio.NewReaderSize(io.LimitReader(f, size), 1<<20)
+
+	// header
+	nbWires := len(s.Solved)
+	header := make([]byte, len(checkpointMagic)+2+sha256.Size)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, false, err
+	}
+	if string(header[:len(checkpointMagic)]) != checkpointMagic {
+		return 0, false, errors.New("not a checkpoint")
+	}
+	if v := binary.BigEndian.Uint16(header[len(checkpointMagic):]); v != checkpointVersion {
+		return 0, false, fmt.Errorf("unsupported checkpoint version %d", v)
+	}
+	if !bytes.Equal(header[len(checkpointMagic)+2:], cp.digest) {
+		return 0, false, errors.New("checkpoint of another constraint system or witness")
+	}
+	counts, err := readUint64s(r, 3)
+	if err != nil {
+		return 0, false, err
+	}
+	if counts[0] != uint64(nbWires) || counts[1] > uint64(nbConstraints) {
+		return 0, false, errors.New("checkpoint doesn't match the constraint system")
+	}
+	next = int(counts[1])
+	n := 0
+	if a != nil {
+		n = next
+	}
+	const elementSize = fr.Limbs * 8
+	if expectedSize := int64(len(header) + 3*8 + (nbWires+7)/8 + nbWires*elementSize + 8 + 3*n*elementSize); size != expectedSize {
+		return 0, false, errors.New("checkpoint doesn't match the solver")
+	}
+
+	// solution
+	bitmap := make([]byte, (nbWires+7)/8)
+	if _, err := io.ReadFull(r, bitmap); err != nil {
+		return 0, false, err
+	}
+	restoring = true
+	var buf [elementSize]byte
+	for i := 0; i < nbWires; i++ {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return 0, restoring, err
+		}
+		if bitmap[i/8]&(1<<(i%8)) == 0 || s.Solved[i] {
+			continue
+		}
+		var v fr.Element
+		getElement(&v, buf[:])
+		if err := s.set(i, v); err != nil {
+			return 0, restoring, err
+		}
+	}
+
+	// a, b, c
+	if _, err := readUint64s(r, 1); err != nil {
+		return 0, restoring, err
+	}
+	for _, v := range [][]fr.Element{a, b, c} {
+		for i := 0; i < n; i++ {
+			if _, err := io.ReadFull(r, buf[:]); err != nil {
+				return 0, restoring, err
+			}
+			getElement(&v[i], buf[:])
+		}
+	}
+	return next, restoring, nil
+}
+
+// remove deletes the checkpoint, once the witness is solved
+func (cp *checkpointer) remove() {
+	if err := os.Remove(cp.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Warn("%s: %v", cp.name, err)
+	}
+}
+
+// discard removes the entry of the solution cache directory whose solution failed the checks
+func (cp *checkpointer) discard(err error) error {
+	logger.Warn("%s: removing %s: %v", cp.name, cp.path, err)
+	return os.Remove(cp.path)
+}
+
+// checkpointDigest returns the digest of the R1CS, computing it on the first call
+func (cs *R1CS) checkpointDigest() ([]byte, error) {
+	if d, ok := cs.ccsDigest.Load().([]byte); ok {
+		return d, nil
+	}
+	d, err := cs.digest()
+	if err != nil {
+		return nil, err
+	}
+	cs.ccsDigest.Store(d)
+	return d, nil
+}
+
+// checkpointDigest returns the digest of the SparseR1CS, computing it on the first call
+func (cs *SparseR1CS) checkpointDigest() ([]byte, error) {
+	if d, ok := cs.ccsDigest.Load().([]byte); ok {
+		return d, nil
+	}
+	h := sha256.New()
+	var buf [2]byte
+	binary.BigEndian.PutUint16(buf[:], uint16(curve.ID))
+	h.Write(buf[:])
+	if _, err := cs.WriteTo(h); err != nil {
+		return nil, err
+	}
+	d := h.Sum(nil)
+	cs.ccsDigest.Store(d)
+	return d, nil
+}
+
+// putElement writes the Montgomery limbs of v in buf, little endian
+func putElement(buf []byte, v *fr.Element) {
+	for j := 0; j < fr.Limbs; j++ {
+		binary.LittleEndian.PutUint64(buf[j*8:], v[j])
+	}
+}
+
+// getElement sets v from the Montgomery limbs in buf, see putElement
+func getElement(v *fr.Element, buf []byte) {
+	for j := 0; j < fr.Limbs; j++ {
+		v[j] = binary.LittleEndian.Uint64(buf[j*8:])
+	}
+}
+
+func writeElements(w io.Writer, v []fr.Element) error {
+	var buf [fr.Limbs * 8]byte
+	for i := 0; i < len(v); i++ {
+		putElement(buf[:], &v[i])
+		if _, err := w.Write(buf[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeUint64s(w io.Writer, v ...uint64) error {
+	var buf [8]byte
+	for _, x := range v {
+		binary.BigEndian.PutUint64(buf[:], x)
+		if _, err := w.Write(buf[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readUint64s(r io.Reader, n int) ([]uint64, error) {
+	res := make([]uint64, n)
+	var buf [8]byte
+	for i := 0; i < n; i++ {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return nil, err
+		}
+		res[i] = binary.BigEndian.Uint64(buf[:])
+	}
+	return res, nil
+}
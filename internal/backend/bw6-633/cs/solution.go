@@ -0,0 +1,314 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package cs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/internal/backend/common"
+	"github.com/consensys/gnark/internal/backend/compiled"
+
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bw6-633"
+)
+
+// ErrUnsatisfiedConstraint can be generated when solving a R1CS
+var ErrUnsatisfiedConstraint = errors.New("constraint is not satisfied")
+
+// solution represents elements needed to compute
+// a solution to a R1CS or SparseR1CS
+//
+// The values of the wires are field elements; the rest (solved wires, hints, logs) is
+// common to the curves, see common.Solution.
+type solution struct {
+	common.Solution
+
+	// if Layout is set, values holds the wires which are not packed in Bits
+	values, coefficients []fr.Element
+
+	mHints map[int]compiled.Hint // hints of the constraint system, to solve the hints a hint depends on
+}
+
+// newSolution returns a solution of nbWires wires. If layout is not nil, the boolean wires are packed.
+// The hint functions are checked against the IDs referenced by the constraint system, see common.NewSolution.
+func newSolution(nbWires int, layout *compiled.BooleanLayout, referenced map[hint.ID]string, opt backend.ProverOption, coefficients []fr.Element, mHints map[int]compiled.Hint) (solution, error) {
+	s, err := common.NewSolution(curve.ID, nbWires, layout, referenced, opt)
+	if err != nil {
+		return solution{}, err
+	}
+	return solution{
+		Solution:     s,
+		coefficients: coefficients,
+		values:       make([]fr.Element, s.NbValues()),
+		mHints:       mHints,
+	}, nil
+}
+
+// set sets the value of the wire id. It returns an error if the wire is packed (see newSolution)
+// and the value is not boolean: the constraint making the wire boolean can't be satisfied.
+func (s *solution) set(id int, value fr.Element) error {
+	if s.Solved[id] {
+		panic("solving the same wire twice should never happen.")
+	}
+	if s.Layout != nil {
+		if s.Layout.IsBoolean(id) {
+			var one fr.Element
+			one.SetOne()
+			if value.Equal(&one) {
+				s.Bits.Set(s.Layout.Index(id))
+			} else if !value.IsZero() {
+				return fmt.Errorf("%w: wire %d is constrained to be boolean, got %s", ErrUnsatisfiedConstraint, id, value.String())
+			}
+		} else {
+			s.values[s.Layout.Index(id)] = value
+		}
+	} else {
+		s.values[id] = value
+	}
+	s.MarkSolved(id)
+	return nil
+}
+
+// get returns the value of the wire id
+func (s *solution) get(id int) fr.Element {
+	if s.Layout == nil {
+		return s.values[id]
+	}
+	var res fr.Element
+	if s.Layout.IsBoolean(id) {
+		if s.Bits.Get(s.Layout.Index(id)) {
+			res.SetOne()
+		}
+		return res
+	}
+	return s.values[s.Layout.Index(id)]
+}
+
+// wireValues returns the values of all the wires, unpacking the boolean wires if needed
+func (s *solution) wireValues() []fr.Element {
+	if s.Layout == nil {
+		return s.values
+	}
+	res := make([]fr.Element, len(s.Solved))
+	for i := 0; i < len(res); i++ {
+		res[i] = s.get(i)
+	}
+	return res
+}
+
+// publicOutputs returns the values of the public outputs of the constraint system, indexed by their
+// name; the wire of an output is its witness index plus offset. See backend.WithPublicOutputs.
+func (s *solution) publicOutputs(outputs []compiled.PublicOutput, offset int) map[string]*big.Int {
+	res := make(map[string]*big.Int, len(outputs))
+	for _, o := range outputs {
+		v := s.get(o.Index + offset)
+		res[o.Name] = new(big.Int)
+		v.ToBigIntRegular(res[o.Name])
+	}
+	return res
+}
+
+// computeTerm computes coef*variable
+func (s *solution) computeTerm(t compiled.Term) fr.Element {
+	cID, vID, _ := t.Unpack()
+	if cID != 0 && !s.Solved[vID] {
+		panic("computing a term with an unsolved wire")
+	}
+	if s.Layout != nil {
+		if s.Layout.IsBoolean(vID) {
+			return s.computeBooleanTerm(cID, s.Bits.Get(s.Layout.Index(vID)))
+		}
+		vID = s.Layout.Index(vID)
+	}
+	switch cID {
+	case compiled.CoeffIdZero:
+		return fr.Element{}
+	case compiled.CoeffIdOne:
+		return s.values[vID]
+	case compiled.CoeffIdTwo:
+		var res fr.Element
+		res.Double(&s.values[vID])
+		return res
+	case compiled.CoeffIdMinusOne:
+		var res fr.Element
+		res.Neg(&s.values[vID])
+		return res
+	default:
+		var res fr.Element
+		res.Mul(&s.coefficients[cID], &s.values[vID])
+		return res
+	}
+}
+
+// computeBooleanTerm computes coef*b without multiplication: the result is 0 or the coefficient
+func (s *solution) computeBooleanTerm(cID int, b bool) fr.Element {
+	var res fr.Element
+	if !b {
+		return res
+	}
+	switch cID {
+	case compiled.CoeffIdZero:
+	case compiled.CoeffIdOne:
+		res.SetOne()
+	case compiled.CoeffIdTwo:
+		res.SetUint64(2)
+	case compiled.CoeffIdMinusOne:
+		res.SetOne()
+		res.Neg(&res)
+	default:
+		res = s.coefficients[cID]
+	}
+	return res
+}
+
+// computeLinearTerm computes the value of a term of a linear expression: coef*variable, or
+// the coefficient if the term is a constant
+func (s *solution) computeLinearTerm(t compiled.Term) fr.Element {
+	if t.VariableVisibility() == compiled.Virtual {
+		return s.coefficients[t.CoeffID()]
+	}
+	return s.computeTerm(t)
+}
+
+// TermValue implements common.Wires
+func (s *solution) TermValue(t compiled.Term, res *big.Int) {
+	v := s.computeLinearTerm(t)
+	v.ToBigIntRegular(res)
+}
+
+// SetValue implements common.Wires
+func (s *solution) SetValue(id int, v *big.Int) error {
+	var e fr.Element
+	e.SetBigInt(v)
+	return s.set(id, e)
+}
+
+// CoefficientString implements common.Wires
+func (s *solution) CoefficientString(cID int) string {
+	return s.coefficients[cID].String()
+}
+
+// WireString implements common.Wires
+func (s *solution) WireString(vID int) string {
+	v := s.get(vID)
+	return v.String()
+}
+
+// SumString implements common.Wires
+func (s *solution) SumString(terms []compiled.Term) string {
+	var sum fr.Element
+	for _, t := range terms {
+		v := s.computeLinearTerm(t)
+		sum.Add(&sum, &v)
+	}
+	return sum.String()
+}
+
+// assignmentSolution returns a solution holding the full assignment values (public, secret then
+// internal wires), reduced modulo the scalar field, to evaluate constraints without solving them
+func assignmentSolution(values []*big.Int, nbWires int, coefficients []fr.Element) (solution, error) {
+	if len(values) != nbWires {
+		return solution{}, fmt.Errorf("invalid assignment size, got %d, expected %d wires", len(values), nbWires)
+	}
+	s := solution{
+		coefficients: coefficients,
+		values:       make([]fr.Element, nbWires),
+	}
+	s.Solved = make([]bool, nbWires)
+	for i, v := range values {
+		if v == nil {
+			return solution{}, fmt.Errorf("wire %d is not assigned", i)
+		}
+		s.values[i].SetBigInt(v)
+		s.MarkSolved(i)
+	}
+	return s, nil
+}
+
+// solveHint compute solution.values[vID] using provided solver hint
+// the other wires computed by the hint function (h.Wires) are set too, after the hints it depends on
+// if they are not solved yet (see compiled.LinkHints)
+func (s *solution) solveWithHint(vID int, h compiled.Hint) error {
+	return s.solveWithHintDepth(vID, h, 0)
+}
+
+func (s *solution) solveWithHintDepth(vID int, h compiled.Hint, depth int) error {
+	if depth > len(s.mHints) {
+		// only a constraint system which wasn't compiled by gnark can have cyclic dependencies
+		return fmt.Errorf("hint of wire %d: cyclic hint dependencies", vID)
+	}
+	for _, d := range h.Deps {
+		if s.Solved[d] {
+			continue
+		}
+		if err := s.solveWithHintDepth(d, s.mHints[d], depth+1); err != nil {
+			return err
+		}
+	}
+
+	if s.ConstantTime {
+		if f, ok := constantTimeHints[h.ID]; ok {
+			return s.solveWithConstantTimeHint(f, vID, h)
+		}
+	}
+	return s.SolveWithHint(s, vID, h)
+}
+
+// solveWithConstantTimeHint is solveWithHint for a builtin hint, computed by f on field elements
+func (s *solution) solveWithConstantTimeHint(f constantTimeHint, vID int, h compiled.Hint) error {
+	inputs := make([]fr.Element, len(h.Inputs))
+	for i := 0; i < len(h.Inputs); i++ {
+		for j := 0; j < len(h.Inputs[i]); j++ {
+			ciID, viID, visibility := h.Inputs[i][j].Unpack()
+			if visibility == compiled.Virtual {
+				inputs[i].Add(&inputs[i], &s.coefficients[ciID])
+				continue
+			}
+			if !s.Solved[viID] {
+				return errors.New("expected wire to be instantiated while evaluating hint")
+			}
+			v := s.computeTerm(h.Inputs[i][j])
+			inputs[i].Add(&inputs[i], &v)
+		}
+	}
+
+	outputs := make([]fr.Element, len(h.Wires))
+	if err := f(inputs, outputs); err != nil {
+		hf, _ := s.HintFunction(h.ID)
+		return fmt.Errorf("hint %s (wire %d): %w", hf, vID, err)
+	}
+	for i := 0; i < len(outputs); i++ {
+		if err := s.set(h.Wires[i], outputs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *solution) printLogs(w io.Writer, logs []compiled.LogEntry) {
+	s.PrintLogs(w, s, logs)
+}
+
+func (s *solution) logValue(log compiled.LogEntry) string {
+	return s.LogValue(s, log)
+}
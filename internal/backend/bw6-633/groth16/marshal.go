@@ -0,0 +1,798 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package groth16
+
+import (
+	curve "github.com/consensys/gnark-crypto/ecc/bw6-633"
+
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
+
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr/fft"
+	"github.com/consensys/gnark/internal/backend/common"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	gnarkio "github.com/consensys/gnark/io"
+	"io"
+	"reflect"
+	"unsafe"
+)
+
+// WriteTo writes binary encoding of the Proof elements to writer
+// points are stored in compressed form Ar | Krs | Bs
+// use WriteRawTo(...) to encode the proof without point compression
+func (proof *Proof) WriteTo(w io.Writer) (n int64, err error) {
+	return proof.writeTo(w, false)
+}
+
+// WriteRawTo writes binary encoding of the Proof elements to writer
+// points are stored in uncompressed form Ar | Krs | Bs
+// use WriteTo(...) to encode the proof with point compression
+func (proof *Proof) WriteRawTo(w io.Writer) (n int64, err error) {
+	return proof.writeTo(w, true)
+}
+
+func (proof *Proof) writeTo(w io.Writer, raw bool) (int64, error) {
+	var enc *curve.Encoder
+	if raw {
+		enc = curve.NewEncoder(w, curve.RawEncoding())
+	} else {
+		enc = curve.NewEncoder(w)
+	}
+
+	if err := enc.Encode(&proof.Ar); err != nil {
+		return enc.BytesWritten(), err
+	}
+	if err := enc.Encode(&proof.Bs); err != nil {
+		return enc.BytesWritten(), err
+	}
+	if err := enc.Encode(&proof.Krs); err != nil {
+		return enc.BytesWritten(), err
+	}
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom attempts to decode a Proof from reader
+// Proof must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
+func (proof *Proof) ReadFrom(r io.Reader) (n int64, err error) {
+
+	dec := curve.NewDecoder(r)
+
+	if err := dec.Decode(&proof.Ar); err != nil {
+		return dec.BytesRead(), err
+	}
+	if err := dec.Decode(&proof.Bs); err != nil {
+		return dec.BytesRead(), err
+	}
+	if err := dec.Decode(&proof.Krs); err != nil {
+		return dec.BytesRead(), err
+	}
+
+	return dec.BytesRead(), nil
+}
+
+// proofCBOR is the CBOR encoding of Proof, see MarshalCBOR
+type proofCBOR struct {
+	common.CBORHeader
+	Ar  []byte `cbor:"Ar"`
+	Bs  []byte `cbor:"Bs"`
+	Krs []byte `cbor:"Krs"`
+}
+
+// MarshalCBOR returns the canonical CBOR encoding of the proof: a map holding the header
+// (see common.CBORHeader) and the compressed points "Ar", "Bs" and "Krs"
+func (proof *Proof) MarshalCBOR() ([]byte, error) {
+	ar, bs, krs := proof.Ar.Bytes(), proof.Bs.Bytes(), proof.Krs.Bytes()
+	return common.CBOREncMode.Marshal(proofCBOR{
+		CBORHeader: common.NewCBORHeader(common.CBORGroth16Proof, curve.ID),
+		Ar:         ar[:],
+		Bs:         bs[:],
+		Krs:        krs[:],
+	})
+}
+
+// UnmarshalCBOR decodes a proof encoded with MarshalCBOR. It fails with backend.ErrCBORHeaderMismatch
+// if data is not a Groth16 proof over this curve, and if the points are not in the correct subgroups.
+func (proof *Proof) UnmarshalCBOR(data []byte) error {
+	var p proofCBOR
+	if err := common.CBORDecMode.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if err := p.Check(common.CBORGroth16Proof, curve.ID); err != nil {
+		return err
+	}
+	if err := setCompressed(&proof.Ar, p.Ar, curve.SizeOfG1AffineCompressed, "Ar"); err != nil {
+		return err
+	}
+	if err := setCompressed(&proof.Bs, p.Bs, curve.SizeOfG2AffineCompressed, "Bs"); err != nil {
+		return err
+	}
+	return setCompressed(&proof.Krs, p.Krs, curve.SizeOfG1AffineCompressed, "Krs")
+}
+
+// setCompressed sets p from its compressed encoding b, of size bytes
+func setCompressed(p interface{ SetBytes([]byte) (int, error) }, b []byte, size int, name string) error {
+	if len(b) != size {
+		return fmt.Errorf("%s: invalid compressed point size %d, expected %d", name, len(b), size)
+	}
+	if _, err := p.SetBytes(b); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// WriteTo writes binary encoding of the key elements to writer
+// points are compressed
+// use WriteRawTo(...) to encode the key without point compression
+func (vk *VerifyingKey) WriteTo(w io.Writer) (n int64, err error) {
+	return vk.writeTo(w, false)
+}
+
+// WriteRawTo writes binary encoding of the key elements to writer
+// points are not compressed
+// use WriteTo(...) to encode the key with point compression
+func (vk *VerifyingKey) WriteRawTo(w io.Writer) (n int64, err error) {
+	return vk.writeTo(w, true)
+}
+
+// writeTo serialization format:
+// follows bellman format:
+// https://github.com/zkcrypto/bellman/blob/fa9be45588227a8c6ec34957de3f68705f07bd92/src/groth16/mod.rs#L143
+// [α]1,[β]1,[β]2,[γ]2,[δ]1,[δ]2,uint32(len(Kvk)),[Kvk]1
+// followed by the metadata section, if any (see compiled.WriteMetadataSection)
+func (vk *VerifyingKey) writeTo(w io.Writer, raw bool) (int64, error) {
+	var enc *curve.Encoder
+	if raw {
+		enc = curve.NewEncoder(w, curve.RawEncoding())
+	} else {
+		enc = curve.NewEncoder(w)
+	}
+
+	// [α]1,[β]1,[β]2,[γ]2,[δ]1,[δ]2
+	if err := enc.Encode(&vk.G1.Alpha); err != nil {
+		return enc.BytesWritten(), err
+	}
+	if err := enc.Encode(&vk.G1.Beta); err != nil {
+		return enc.BytesWritten(), err
+	}
+	if err := enc.Encode(&vk.G2.Beta); err != nil {
+		return enc.BytesWritten(), err
+	}
+	if err := enc.Encode(&vk.G2.Gamma); err != nil {
+		return enc.BytesWritten(), err
+	}
+	if err := enc.Encode(&vk.G1.Delta); err != nil {
+		return enc.BytesWritten(), err
+	}
+	if err := enc.Encode(&vk.G2.Delta); err != nil {
+		return enc.BytesWritten(), err
+	}
+
+	// uint32(len(Kvk)),[Kvk]1
+	if err := enc.Encode(vk.G1.K); err != nil {
+		return enc.BytesWritten(), err
+	}
+
+	n, err := compiled.WriteMetadataSection(w, vk.Metadata)
+	return enc.BytesWritten() + n, err
+}
+
+// ReadFrom attempts to decode a VerifyingKey from reader
+// VerifyingKey must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
+// serialization format:
+// https://github.com/zkcrypto/bellman/blob/fa9be45588227a8c6ec34957de3f68705f07bd92/src/groth16/mod.rs#L143
+// [α]1,[β]1,[β]2,[γ]2,[δ]1,[δ]2,uint32(len(Kvk)),[Kvk]1
+// optionally followed by the metadata section, such that keys in the bellman format can be read. As the
+// section starts with a marker, a key without it can be followed by other data in r, read from the same
+// gnarkio.LimitReader (see gnarkio.LimitReader.ReadMarker).
+func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
+	return vk.readFrom(r)
+}
+
+// UnsafeReadFrom has the same behavior as ReadFrom, except that it will not check that decode points
+// are on the curve and in the correct subgroup.
+func (vk *VerifyingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
+	return vk.readFrom(r, curve.NoSubgroupChecks())
+}
+
+func (vk *VerifyingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder)) (int64, error) {
+	lr := gnarkio.NewLimitReader(r)
+	dec := curve.NewDecoder(lr, decOptions...)
+
+	// [α]1,[β]1,[β]2,[γ]2,[δ]1,[δ]2
+	if err := dec.Decode(&vk.G1.Alpha); err != nil {
+		return dec.BytesRead(), err
+	}
+	if err := dec.Decode(&vk.G1.Beta); err != nil {
+		return dec.BytesRead(), err
+	}
+	if err := dec.Decode(&vk.G2.Beta); err != nil {
+		return dec.BytesRead(), err
+	}
+	if err := dec.Decode(&vk.G2.Gamma); err != nil {
+		return dec.BytesRead(), err
+	}
+	if err := dec.Decode(&vk.G1.Delta); err != nil {
+		return dec.BytesRead(), err
+	}
+	if err := dec.Decode(&vk.G2.Delta); err != nil {
+		return dec.BytesRead(), err
+	}
+
+	// uint32(len(Kvk)),[Kvk]1
+	if err := decodeLimited(dec, lr, &vk.G1.K); err != nil {
+		return dec.BytesRead(), err
+	}
+
+	metadata, n, err := compiled.ReadMetadataSection(lr)
+	if err != nil {
+		return dec.BytesRead() + n, err
+	}
+	vk.Metadata = metadata
+
+	// e(α, β), -[δ]2, -[γ]2 are computed by the first verification, see Precompute
+	vk.resetPrecomputation()
+
+	return dec.BytesRead() + n, nil
+}
+
+// WritePreparedTo writes the VerifyingKey followed by its precomputation (see Precompute), such that
+// ReadPreparedFrom returns a key ready to verify, without computing a pairing.
+//
+// format: e(α, β) | -[γ]2 | -[δ]2 | VerifyingKey (see WriteRawTo), points are not compressed
+func (vk *VerifyingKey) WritePreparedTo(w io.Writer) (int64, error) {
+	pre, err := vk.precompute()
+	if err != nil {
+		return 0, err
+	}
+	e := pre.E.Bytes()
+	n, err := w.Write(e[:])
+	if err != nil {
+		return int64(n), err
+	}
+
+	enc := curve.NewEncoder(w, curve.RawEncoding())
+	if err := enc.Encode(&pre.GammaNeg); err != nil {
+		return int64(n) + enc.BytesWritten(), err
+	}
+	if err := enc.Encode(&pre.DeltaNeg); err != nil {
+		return int64(n) + enc.BytesWritten(), err
+	}
+
+	m, err := vk.WriteRawTo(w)
+	return int64(n) + enc.BytesWritten() + m, err
+}
+
+// ReadPreparedFrom decodes a VerifyingKey written with WritePreparedTo, and fills its precomputation cache.
+// -[γ]2 and -[δ]2 are checked against the key; e(α, β) is trusted, such that the key must come from a trusted source.
+func (vk *VerifyingKey) ReadPreparedFrom(r io.Reader) (int64, error) {
+	var pre vkPrecomputation
+	var e [curve.SizeOfGT]byte
+	n, err := io.ReadFull(r, e[:])
+	if err != nil {
+		return int64(n), err
+	}
+	if err := pre.E.SetBytes(e[:]); err != nil {
+		return int64(n), err
+	}
+
+	dec := curve.NewDecoder(r)
+	if err := dec.Decode(&pre.GammaNeg); err != nil {
+		return int64(n) + dec.BytesRead(), err
+	}
+	if err := dec.Decode(&pre.DeltaNeg); err != nil {
+		return int64(n) + dec.BytesRead(), err
+	}
+
+	m, err := vk.readFrom(r)
+	if err != nil {
+		return int64(n) + dec.BytesRead() + m, err
+	}
+
+	var gammaNeg, deltaNeg curve.G2Affine
+	gammaNeg.Neg(&vk.G2.Gamma)
+	deltaNeg.Neg(&vk.G2.Delta)
+	if !gammaNeg.Equal(&pre.GammaNeg) || !deltaNeg.Equal(&pre.DeltaNeg) {
+		return int64(n) + dec.BytesRead() + m, errors.New("invalid prepared verifying key: precomputation doesn't match the key")
+	}
+	vk.precomputed.Store(&pre)
+
+	return int64(n) + dec.BytesRead() + m, nil
+}
+
+// WriteTo writes binary encoding of the key elements to writer
+// points are compressed
+// use WriteRawTo(...) to encode the key without point compression
+func (pk *ProvingKey) WriteTo(w io.Writer) (n int64, err error) {
+	return pk.writeTo(w, false)
+}
+
+// WriteRawTo writes binary encoding of the key elements to writer
+// points are not compressed
+// use WriteTo(...) to encode the key with point compression
+func (pk *ProvingKey) WriteRawTo(w io.Writer) (n int64, err error) {
+	return pk.writeTo(w, true)
+}
+
+func (pk *ProvingKey) writeTo(w io.Writer, raw bool) (int64, error) {
+	n, err := pk.Domain.WriteTo(w)
+	if err != nil {
+		return n, err
+	}
+
+	var enc *curve.Encoder
+	if raw {
+		enc = curve.NewEncoder(w, curve.RawEncoding())
+	} else {
+		enc = curve.NewEncoder(w)
+	}
+	nbWires := uint64(len(pk.InfinityA))
+
+	toEncode := []interface{}{
+		&pk.G1.Alpha,
+		&pk.G1.Beta,
+		&pk.G1.Delta,
+		pk.G1.A,
+		pk.G1.B,
+		pk.G1.Z,
+		pk.G1.K,
+		&pk.G2.Beta,
+		&pk.G2.Delta,
+		pk.G2.B,
+		nbWires,
+		pk.NbInfinityA,
+		pk.NbInfinityB,
+		pk.InfinityA,
+		pk.InfinityB,
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return n + enc.BytesWritten(), err
+		}
+	}
+
+	n2, err := compiled.WriteMetadataSection(w, pk.Metadata)
+	return n + enc.BytesWritten() + n2, err
+
+}
+
+// ReadFrom attempts to decode a ProvingKey from reader
+// ProvingKey must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
+// note that we don't check that the points are on the curve or in the correct subgroup at this point
+func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
+	return pk.readFrom(r)
+}
+
+// UnsafeReadFrom behaves like ReadFrom excepts it doesn't check if the decoded points are on the curve
+// or in the correct subgroup
+func (pk *ProvingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
+	return pk.readFrom(r, curve.NoSubgroupChecks())
+}
+
+// WriteSplitTo writes the key as WriteTo, in chunk files of at most chunkSize bytes in the directory dir,
+// followed by their manifest, see gnarkio.WriteSplitTo
+func (pk *ProvingKey) WriteSplitTo(dir string, chunkSize int64) (int64, error) {
+	return gnarkio.WriteSplitTo(dir, chunkSize, pk)
+}
+
+// ReadSplitFrom reads a key written with WriteSplitTo, checking its chunks against the manifest,
+// see gnarkio.ReadSplitFrom
+func (pk *ProvingKey) ReadSplitFrom(dir string) (int64, error) {
+	return gnarkio.ReadSplitFrom(dir, pk)
+}
+
+// UnsafeReadSplitFrom behaves like ReadSplitFrom excepts it doesn't check if the decoded points are on the curve
+// or in the correct subgroup
+func (pk *ProvingKey) UnsafeReadSplitFrom(dir string) (int64, error) {
+	return gnarkio.UnsafeReadSplitFrom(dir, pk)
+}
+
+func (pk *ProvingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder)) (int64, error) {
+	pk.precomputed = nil
+	lr := gnarkio.NewLimitReader(r)
+	n, err := readDomain(lr, &pk.Domain)
+	if err != nil {
+		return n, err
+	}
+
+	dec := curve.NewDecoder(lr, decOptions...)
+
+	var nbWires uint64
+
+	toDecode := []interface{}{
+		&pk.G1.Alpha,
+		&pk.G1.Beta,
+		&pk.G1.Delta,
+		&pk.G1.A,
+		&pk.G1.B,
+		&pk.G1.Z,
+		&pk.G1.K,
+		&pk.G2.Beta,
+		&pk.G2.Delta,
+		&pk.G2.B,
+		&nbWires,
+		&pk.NbInfinityA,
+		&pk.NbInfinityB,
+	}
+
+	if err := decodeLimited(dec, lr, toDecode...); err != nil {
+		return n + dec.BytesRead(), err
+	}
+	if err := lr.Check("infinity flags", nbWires, 2, 2); err != nil {
+		return n + dec.BytesRead(), err
+	}
+	pk.InfinityA = make([]bool, nbWires)
+	pk.InfinityB = make([]bool, nbWires)
+
+	if err := dec.Decode(&pk.InfinityA); err != nil {
+		return n + dec.BytesRead(), err
+	}
+	if err := dec.Decode(&pk.InfinityB); err != nil {
+		return n + dec.BytesRead(), err
+	}
+
+	var n2 int64
+	pk.Metadata, n2, err = compiled.ReadMetadataSection(lr)
+	return n + dec.BytesRead() + n2, err
+}
+
+// decodeLimited decodes the values with dec, which reads from lr: the length prefixes of the slices of
+// points are checked before the slices are allocated, see gnarkio.LimitReader
+func decodeLimited(dec *curve.Decoder, lr *gnarkio.LimitReader, toDecode ...interface{}) error {
+	for _, v := range toDecode {
+		var err error
+		switch v.(type) {
+		case *[]curve.G1Affine:
+			err = lr.CheckSlice("G1 points", curve.SizeOfG1AffineCompressed, sizeG1)
+		case *[]curve.G2Affine:
+			err = lr.CheckSlice("G2 points", curve.SizeOfG2AffineCompressed, sizeG2)
+		}
+		if err != nil {
+			return err
+		}
+		if err := dec.Decode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDomain reads d from lr, once the size of its precomputed tables (twiddles and coset tables, about
+// 6 field elements per element of the domain) is checked against the read limit
+func readDomain(lr *gnarkio.LimitReader, d *fft.Domain) (int64, error) {
+	if b, err := lr.Peek(8); err == nil {
+		if err := lr.Check("domain", binary.BigEndian.Uint64(b), 0, 6*fr.Bytes); err != nil {
+			return 0, err
+		}
+	}
+	return d.ReadFrom(lr)
+}
+
+// dumpMagic prefixes a ProvingKey dump (see WriteDump)
+const dumpMagic uint64 = 0x676e61726b706b31 // "gnarkpk1"
+
+// dumpHeaderSize is the size in bytes of magic | curveID | len(meta)
+const dumpHeaderSize = 24
+
+var (
+	sizeG1 = int(unsafe.Sizeof(curve.G1Affine{}))
+	sizeG2 = int(unsafe.Sizeof(curve.G2Affine{}))
+)
+
+// WriteDump writes the ProvingKey in a raw layout that can be loaded with ReadDump,
+// or memory mapped and used in place with ReadDumpBytes.
+//
+// layout: magic | curveID | len(meta) | meta | [len(s) | s] for s in G1.A, G1.B, G1.Z, G1.K, G2.B
+//
+// meta holds the domain, the single points, the infinity flags (raw encoding) and the metadata, padded to 8 bytes.
+// The large point tables are written as their in-memory representation (Montgomery form, host endianness)
+// such that they can be used without any decoding or allocation. As a consequence, a dump is not portable
+// and must be read on a machine with the same architecture.
+func (pk *ProvingKey) WriteDump(w io.Writer) error {
+	var meta bytes.Buffer
+	if _, err := pk.Domain.WriteTo(&meta); err != nil {
+		return err
+	}
+	enc := curve.NewEncoder(&meta, curve.RawEncoding())
+	nbWires := uint64(len(pk.InfinityA))
+	toEncode := []interface{}{
+		&pk.G1.Alpha,
+		&pk.G1.Beta,
+		&pk.G1.Delta,
+		&pk.G2.Beta,
+		&pk.G2.Delta,
+		nbWires,
+		pk.NbInfinityA,
+		pk.NbInfinityB,
+		pk.InfinityA,
+		pk.InfinityB,
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	if _, err := compiled.WriteMetadata(&meta, pk.Metadata); err != nil {
+		return err
+	}
+	// the point tables must be 8 bytes aligned
+	for meta.Len()%8 != 0 {
+		meta.WriteByte(0)
+	}
+
+	var buf [8]byte
+	writeUint64 := func(v uint64) error {
+		binary.BigEndian.PutUint64(buf[:], v)
+		_, err := w.Write(buf[:])
+		return err
+	}
+
+	if err := writeUint64(dumpMagic); err != nil {
+		return err
+	}
+	if err := writeUint64(uint64(curve.ID)); err != nil {
+		return err
+	}
+	if err := writeUint64(uint64(meta.Len())); err != nil {
+		return err
+	}
+	if _, err := w.Write(meta.Bytes()); err != nil {
+		return err
+	}
+
+	for _, s := range [][]curve.G1Affine{pk.G1.A, pk.G1.B, pk.G1.Z, pk.G1.K} {
+		if err := writeUint64(uint64(len(s))); err != nil {
+			return err
+		}
+		if _, err := w.Write(g1Bytes(s)); err != nil {
+			return err
+		}
+	}
+	if err := writeUint64(uint64(len(pk.G2.B))); err != nil {
+		return err
+	}
+	_, err := w.Write(g2Bytes(pk.G2.B))
+	return err
+}
+
+// ReadDump reads a ProvingKey written with WriteDump. The point tables are read
+// directly in their final memory location, without per-point decoding.
+func (pk *ProvingKey) ReadDump(r io.Reader) error {
+	lr := gnarkio.NewLimitReader(r)
+	var header [dumpHeaderSize]byte
+	if _, err := io.ReadFull(lr, header[:]); err != nil {
+		return err
+	}
+	metaLen, err := checkDumpHeader(header[:])
+	if err != nil {
+		return err
+	}
+	if err := lr.Check("proving key dump metadata", uint64(metaLen), 1, 1); err != nil {
+		return err
+	}
+	meta := make([]byte, metaLen)
+	if _, err := io.ReadFull(lr, meta); err != nil {
+		return err
+	}
+	if err := pk.readDumpMeta(meta); err != nil {
+		return err
+	}
+
+	var buf [8]byte
+	readLen := func(what string, elementSize int) (int, error) {
+		if _, err := io.ReadFull(lr, buf[:]); err != nil {
+			return 0, err
+		}
+		n := binary.BigEndian.Uint64(buf[:])
+		if err := lr.Check(what, n, elementSize, elementSize); err != nil {
+			return 0, err
+		}
+		return int(n), nil
+	}
+
+	for _, s := range []*[]curve.G1Affine{&pk.G1.A, &pk.G1.B, &pk.G1.Z, &pk.G1.K} {
+		n, err := readLen("G1 points", sizeG1)
+		if err != nil {
+			return err
+		}
+		*s = make([]curve.G1Affine, n)
+		if _, err := io.ReadFull(lr, g1Bytes(*s)); err != nil {
+			return err
+		}
+	}
+	n, err := readLen("G2 points", sizeG2)
+	if err != nil {
+		return err
+	}
+	pk.G2.B = make([]curve.G2Affine, n)
+	_, err = io.ReadFull(lr, g2Bytes(pk.G2.B))
+	return err
+}
+
+// ReadDumpBytes sets the ProvingKey from a dump written with WriteDump.
+//
+// The point tables are not copied: pk aliases data, which typically is a memory mapped file.
+// data must outlive pk and must not be modified while pk is in use.
+func (pk *ProvingKey) ReadDumpBytes(data []byte) error {
+	if len(data) < dumpHeaderSize {
+		return io.ErrUnexpectedEOF
+	}
+	metaLen, err := checkDumpHeader(data[:dumpHeaderSize])
+	if err != nil {
+		return err
+	}
+	offset := dumpHeaderSize + metaLen
+	if len(data) < offset {
+		return io.ErrUnexpectedEOF
+	}
+	if err := pk.readDumpMeta(data[dumpHeaderSize:offset]); err != nil {
+		return err
+	}
+
+	// next returns the next n bytes of the table section
+	next := func(elementSize int) ([]byte, int, error) {
+		if len(data) < offset+8 {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		n := binary.BigEndian.Uint64(data[offset : offset+8])
+		offset += 8
+		if n > uint64((len(data)-offset)/elementSize) {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+		size := int(n) * elementSize
+		b := data[offset : offset+size]
+		offset += size
+		if size != 0 && uintptr(unsafe.Pointer(&b[0]))%8 != 0 {
+			return nil, 0, errors.New("proving key dump: point table is not aligned")
+		}
+		return b, int(n), nil
+	}
+
+	for _, s := range []*[]curve.G1Affine{&pk.G1.A, &pk.G1.B, &pk.G1.Z, &pk.G1.K} {
+		b, n, err := next(sizeG1)
+		if err != nil {
+			return err
+		}
+		*s = bytesToG1(b, n)
+	}
+	b, n, err := next(sizeG2)
+	if err != nil {
+		return err
+	}
+	pk.G2.B = bytesToG2(b, n)
+
+	return nil
+}
+
+func (pk *ProvingKey) readDumpMeta(meta []byte) error {
+	pk.precomputed = nil
+	r := gnarkio.NewLimitReader(bytes.NewReader(meta))
+	if _, err := readDomain(r, &pk.Domain); err != nil {
+		return err
+	}
+	dec := curve.NewDecoder(r)
+
+	var nbWires uint64
+	toDecode := []interface{}{
+		&pk.G1.Alpha,
+		&pk.G1.Beta,
+		&pk.G1.Delta,
+		&pk.G2.Beta,
+		&pk.G2.Delta,
+		&nbWires,
+		&pk.NbInfinityA,
+		&pk.NbInfinityB,
+	}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return err
+		}
+	}
+	if err := r.Check("infinity flags", nbWires, 2, 2); err != nil {
+		return err
+	}
+	pk.InfinityA = make([]bool, nbWires)
+	pk.InfinityB = make([]bool, nbWires)
+
+	if err := dec.Decode(&pk.InfinityA); err != nil {
+		return err
+	}
+	if err := dec.Decode(&pk.InfinityB); err != nil {
+		return err
+	}
+
+	// dumps written without metadata end right after the infinity flags, with at most 7 bytes of padding
+	metadata, _, err := compiled.ReadMetadata(r)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	pk.Metadata = metadata
+	return nil
+}
+
+// checkDumpHeader checks magic and curve ID and returns the size of the metadata section
+func checkDumpHeader(header []byte) (int, error) {
+	if binary.BigEndian.Uint64(header[0:8]) != dumpMagic {
+		return 0, errors.New("invalid proving key dump: bad magic")
+	}
+	if binary.BigEndian.Uint64(header[8:16]) != uint64(curve.ID) {
+		return 0, errors.New("invalid proving key dump: curve mismatch")
+	}
+	metaLen := binary.BigEndian.Uint64(header[16:24])
+	if metaLen%8 != 0 {
+		return 0, errors.New("invalid proving key dump: unaligned metadata")
+	}
+	if limit := gnarkio.MaxReadBytes(); metaLen > uint64(limit) {
+		return 0, &gnarkio.LimitExceededError{What: "proving key dump metadata", Size: int64(metaLen), Limit: limit}
+	}
+	return int(metaLen), nil
+}
+
+// g1Bytes returns the memory backing s as a []byte, without copy
+func g1Bytes(s []curve.G1Affine) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	var b []byte
+	h := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	h.Data = uintptr(unsafe.Pointer(&s[0]))
+	h.Len = len(s) * sizeG1
+	h.Cap = h.Len
+	return b
+}
+
+// g2Bytes returns the memory backing s as a []byte, without copy
+func g2Bytes(s []curve.G2Affine) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	var b []byte
+	h := (*reflect.SliceHeader)(unsafe.Pointer(&b))
+	h.Data = uintptr(unsafe.Pointer(&s[0]))
+	h.Len = len(s) * sizeG2
+	h.Cap = h.Len
+	return b
+}
+
+// bytesToG1 interprets b as n points, without copy
+func bytesToG1(b []byte, n int) []curve.G1Affine {
+	s := []curve.G1Affine{}
+	if n == 0 {
+		return s
+	}
+	h := (*reflect.SliceHeader)(unsafe.Pointer(&s))
+	h.Data = uintptr(unsafe.Pointer(&b[0]))
+	h.Len = n
+	h.Cap = n
+	return s
+}
+
+// bytesToG2 interprets b as n points, without copy
+func bytesToG2(b []byte, n int) []curve.G2Affine {
+	s := []curve.G2Affine{}
+	if n == 0 {
+		return s
+	}
+	h := (*reflect.SliceHeader)(unsafe.Pointer(&s))
+	h.Data = uintptr(unsafe.Pointer(&b[0]))
+	h.Len = n
+	h.Cap = n
+	return s
+}
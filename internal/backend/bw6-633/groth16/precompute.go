@@ -0,0 +1,396 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package groth16
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
+
+	"encoding/binary"
+	"errors"
+	"fmt"
+	curve "github.com/consensys/gnark-crypto/ecc/bw6-633"
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fp"
+	"github.com/consensys/gnark/internal/utils"
+	"io"
+	"sync"
+)
+
+const (
+	// MinPrecomputeWindowSize is the smallest window size of ProvingKey.Precompute
+	MinPrecomputeWindowSize = 2
+
+	// MaxPrecomputeWindowSize is the largest window size of ProvingKey.Precompute
+	MaxPrecomputeWindowSize = 20
+)
+
+// pkPrecomputation holds the fixed-base tables of the G1 points of a ProvingKey, see ProvingKey.Precompute
+type pkPrecomputation struct {
+	windowSize int
+	A, B, Z, K g1Table
+}
+
+// g1Table holds, for each base P of a fixed-base multi exponentiation, the points 2**(c*j)·P for j in [0, nbWindows):
+// a scalar decomposed in signed base 2**c digits d[j] is then multiplied with Σ d[j]·(2**(c*j)·P), without doublings,
+// and all the windows of all the bases share the same buckets.
+type g1Table struct {
+	c         int
+	nbWindows int
+	points    []curve.G1Affine // points[i*nbWindows+j] = 2**(c*j)·bases[i]
+}
+
+// nbPrecomputeWindows returns the number of windows of the tables of window size c: the signed digits
+// of a scalar on fr.Bits bits carry into an extra window
+func nbPrecomputeWindows(c int) int {
+	return fr.Bits/c + 1
+}
+
+// Precompute builds and caches on the key the tables of the fixed-base multi exponentiations of Prove over
+// the G1 points of the key, [A(t)]1, [B(t)]1, [Z(t)]1 and [Kpk(t)]1, with windows of windowSize bits. Prove then
+// doesn't double any point in G1, and reduces its buckets once per multi exponentiation instead of once per window.
+// The proofs are the same as without the tables.
+//
+// The tables hold fr.Bits/windowSize + 1 points per point of the key (see PrecomputeSize): larger windows mean
+// smaller tables but more buckets, 2**(windowSize-1) points per task of Prove. On large circuits, windows of 12 to 18
+// bits beat the multi exponentiations without tables, 16 bits being the fastest (about 1.4x on BN254 with 2**17
+// points); windowSize must be in [MinPrecomputeWindowSize, MaxPrecomputeWindowSize]. With
+// backend.WithPackedBooleans, Prove unpacks the boolean wires to use the tables.
+//
+// Precompute isn't safe for concurrent use with Prove. The tables are dropped when the key is read again,
+// they can be persisted with WritePrecomputedTo.
+func (pk *ProvingKey) Precompute(windowSize int) error {
+	if windowSize < MinPrecomputeWindowSize || windowSize > MaxPrecomputeWindowSize {
+		return fmt.Errorf("invalid window size %d, expected in [%d, %d]", windowSize, MinPrecomputeWindowSize, MaxPrecomputeWindowSize)
+	}
+	pre := pkPrecomputation{windowSize: windowSize}
+	pre.A = newG1Table(pk.G1.A, windowSize)
+	pre.B = newG1Table(pk.G1.B, windowSize)
+	pre.Z = newG1Table(pk.G1.Z, windowSize)
+	pre.K = newG1Table(pk.G1.K, windowSize)
+	pk.precomputed = &pre
+	return nil
+}
+
+// PrecomputeSize returns the size in bytes of the tables of Precompute with the given window size, in memory
+// and as written by WritePrecomputedTo, give or take a few bytes of headers
+func (pk *ProvingKey) PrecomputeSize(windowSize int) int64 {
+	if windowSize < MinPrecomputeWindowSize || windowSize > MaxPrecomputeWindowSize {
+		return 0
+	}
+	nbPoints := len(pk.G1.A) + len(pk.G1.B) + len(pk.G1.Z) + len(pk.G1.K)
+	return int64(nbPoints) * int64(nbPrecomputeWindows(windowSize)) * curve.SizeOfG1AffineUncompressed
+}
+
+// precomputation returns the tables of Precompute, or nil if there are none or they don't match the key
+func (pk *ProvingKey) precomputation() *pkPrecomputation {
+	pre := pk.precomputed
+	if pre == nil ||
+		!pre.A.matches(pk.G1.A) || !pre.B.matches(pk.G1.B) ||
+		!pre.Z.matches(pk.G1.Z) || !pre.K.matches(pk.G1.K) {
+		return nil
+	}
+	return pre
+}
+
+// WritePrecomputedTo writes the tables of Precompute, to be read with ReadPrecomputedFrom on the same key.
+// The tables are large (see PrecomputeSize), and written as is, points are not compressed.
+//
+// format: uint32(windowSize) | [uint32(len(table)) | table] for the tables of A, B, Z and Kpk
+func (pk *ProvingKey) WritePrecomputedTo(w io.Writer) (int64, error) {
+	pre := pk.precomputation()
+	if pre == nil {
+		return 0, errors.New("the proving key has no precomputed tables, see Precompute")
+	}
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], uint32(pre.windowSize))
+	n, err := w.Write(buf[:])
+	if err != nil {
+		return int64(n), err
+	}
+
+	enc := curve.NewEncoder(w, curve.RawEncoding())
+	for _, t := range []*g1Table{&pre.A, &pre.B, &pre.Z, &pre.K} {
+		if err := enc.Encode(t.points); err != nil {
+			return int64(n) + enc.BytesWritten(), err
+		}
+	}
+	return int64(n) + enc.BytesWritten(), nil
+}
+
+// ReadPrecomputedFrom reads tables written with WritePrecomputedTo, and caches them on the key as Precompute does.
+// The first point of each row of the tables is checked against the key, the other ones are not checked:
+// like the key, the tables must come from a trusted source.
+func (pk *ProvingKey) ReadPrecomputedFrom(r io.Reader) (int64, error) {
+	var buf [4]byte
+	n, err := io.ReadFull(r, buf[:])
+	if err != nil {
+		return int64(n), err
+	}
+	windowSize := int(binary.BigEndian.Uint32(buf[:]))
+	if windowSize < MinPrecomputeWindowSize || windowSize > MaxPrecomputeWindowSize {
+		return int64(n), fmt.Errorf("invalid window size %d, expected in [%d, %d]", windowSize, MinPrecomputeWindowSize, MaxPrecomputeWindowSize)
+	}
+
+	pre := pkPrecomputation{windowSize: windowSize}
+	dec := curve.NewDecoder(r, curve.NoSubgroupChecks())
+	tables := []*g1Table{&pre.A, &pre.B, &pre.Z, &pre.K}
+	bases := [][]curve.G1Affine{pk.G1.A, pk.G1.B, pk.G1.Z, pk.G1.K}
+	for i, t := range tables {
+		t.c, t.nbWindows = windowSize, nbPrecomputeWindows(windowSize)
+		if err := dec.Decode(&t.points); err != nil {
+			return int64(n) + dec.BytesRead(), err
+		}
+		if !t.matches(bases[i]) {
+			return int64(n) + dec.BytesRead(), errors.New("invalid precomputed tables: the tables don't match the proving key")
+		}
+	}
+	pk.precomputed = &pre
+	return int64(n) + dec.BytesRead(), nil
+}
+
+// newG1Table computes the table of the bases with windows of c bits
+func newG1Table(bases []curve.G1Affine, c int) g1Table {
+	t := g1Table{c: c, nbWindows: nbPrecomputeWindows(c)}
+	t.points = make([]curve.G1Affine, len(bases)*t.nbWindows)
+	utils.Parallelize(len(bases), func(start, end int) {
+		row := make([]curve.G1Jac, t.nbWindows)
+		for i := start; i < end; i++ {
+			row[0].FromAffine(&bases[i])
+			for j := 1; j < t.nbWindows; j++ {
+				row[j] = row[j-1]
+				for k := 0; k < c; k++ {
+					row[j].DoubleAssign()
+				}
+			}
+			curve.BatchJacobianToAffineG1(row, t.points[i*t.nbWindows:(i+1)*t.nbWindows])
+		}
+	})
+	return t
+}
+
+// matches returns true if the table holds as many rows as there are bases, starting with the bases;
+// it doesn't check the other points
+func (t *g1Table) matches(bases []curve.G1Affine) bool {
+	if t.nbWindows == 0 || len(t.points) != len(bases)*t.nbWindows {
+		return false
+	}
+	if len(bases) == 0 {
+		return true
+	}
+	last := len(bases) - 1
+	return t.points[0].Equal(&bases[0]) && t.points[last*t.nbWindows].Equal(&bases[last])
+}
+
+// multiExp sets res to Σ scalars[i]·bases[i], the scalars being in regular form, splitting the bases in nbTasks
+func (t *g1Table) multiExp(res *curve.G1Jac, scalars []fr.Element, nbTasks int) error {
+	nbBases := len(t.points) / t.nbWindows
+	if len(scalars) != nbBases {
+		return errors.New("len(points) != len(scalars)")
+	}
+	if nbTasks < 1 {
+		nbTasks = 1
+	}
+	if nbTasks > nbBases {
+		nbTasks = nbBases
+	}
+	res.Set(&curve.G1Jac{})
+	if nbBases == 0 {
+		return nil
+	}
+
+	partial := make([]curve.G1Jac, nbTasks)
+	var wg sync.WaitGroup
+	chunk := (nbBases + nbTasks - 1) / nbTasks
+	for task := 0; task < nbTasks; task++ {
+		start, end := task*chunk, (task+1)*chunk
+		if end > nbBases {
+			end = nbBases
+		}
+		wg.Add(1)
+		go func(task, start, end int) {
+			defer wg.Done()
+			t.multiExpChunk(&partial[task], scalars[start:end], start)
+		}(task, start, end)
+	}
+	wg.Wait()
+
+	for i := 0; i < nbTasks; i++ {
+		res.AddAssign(&partial[i])
+	}
+	return nil
+}
+
+// multiExpChunk sets res to Σ scalars[i]·bases[from+i]: the points of all the windows are accumulated in 2**(c-1) buckets,
+// by the absolute value of their signed digit
+func (t *g1Table) multiExpChunk(res *curve.G1Jac, scalars []fr.Element, from int) {
+	half := 1 << (t.c - 1)
+	buckets := newBatchAffineBuckets(half) // buckets[k] holds the points of digit k+1
+	for i := range scalars {
+		row := t.points[(from+i)*t.nbWindows : (from+i+1)*t.nbWindows]
+		carry := 0
+		for j := 0; j < t.nbWindows; j++ {
+			d := scalarDigit(&scalars[i], j*t.c, t.c) + carry
+			carry = 0
+			if d > half {
+				d -= 1 << t.c
+				carry = 1
+			}
+			if d > 0 {
+				buckets.add(d-1, &row[j], false)
+			} else if d < 0 {
+				buckets.add(-d-1, &row[j], true)
+			}
+		}
+	}
+	buckets.flush()
+
+	// Σ (k+1)·buckets[k]
+	var runningSum, total curve.G1Jac
+	for k := len(buckets.points) - 1; k >= 0; k-- {
+		runningSum.AddMixed(&buckets.points[k])
+		runningSum.AddAssign(&buckets.conflicts[k])
+		total.AddAssign(&runningSum)
+	}
+	res.Set(&total)
+}
+
+// batchAffineBuckets accumulates points in affine buckets, the additions being batched such that they share a
+// field inversion: an affine addition then costs about 6 multiplications, a mixed Jacobian one about 11.
+// An addition to a bucket already in the batch goes to a second, Jacobian, bucket: the digits of the top window
+// are small, such that a few buckets get most of its points.
+type batchAffineBuckets struct {
+	points    []curve.G1Affine // the buckets, (0, 0) being the point at infinity
+	conflicts []curve.G1Jac    // the second buckets
+	inBatch   []bool           // inBatch[k] if an addition to the bucket k is in the batch
+
+	// the batch: batch[i] is added to points[batchBuckets[i]]
+	batchSize    int
+	batch        []curve.G1Affine
+	batchBuckets []int
+	denominators []fp.Element
+	products     []fp.Element
+}
+
+// newBatchAffineBuckets returns nbBuckets empty buckets; the batches hold about nbBuckets/8 additions,
+// such that few of them hit the same bucket
+func newBatchAffineBuckets(nbBuckets int) *batchAffineBuckets {
+	batchSize := nbBuckets / 8
+	if batchSize < 1 {
+		batchSize = 1
+	} else if batchSize > 1024 {
+		batchSize = 1024
+	}
+	return &batchAffineBuckets{
+		points:       make([]curve.G1Affine, nbBuckets),
+		conflicts:    make([]curve.G1Jac, nbBuckets),
+		inBatch:      make([]bool, nbBuckets),
+		batchSize:    batchSize,
+		batch:        make([]curve.G1Affine, 0, batchSize),
+		batchBuckets: make([]int, 0, batchSize),
+		denominators: make([]fp.Element, batchSize),
+		products:     make([]fp.Element, batchSize),
+	}
+}
+
+// add adds p, or -p if neg, to the bucket k
+func (b *batchAffineBuckets) add(k int, p *curve.G1Affine, neg bool) {
+	if p.X.IsZero() && p.Y.IsZero() {
+		return
+	}
+	q := *p
+	if neg {
+		q.Y.Neg(&q.Y)
+	}
+	if b.inBatch[k] {
+		b.conflicts[k].AddMixed(&q)
+		return
+	}
+
+	bucket := &b.points[k]
+	if bucket.X.IsZero() && bucket.Y.IsZero() {
+		*bucket = q
+		return
+	}
+	if bucket.X.Equal(&q.X) {
+		// the affine formula doesn't apply, q == ±bucket
+		if bucket.Y.Equal(&q.Y) {
+			var double curve.G1Jac
+			double.FromAffine(&q)
+			double.DoubleAssign()
+			bucket.FromJacobian(&double)
+		} else {
+			*bucket = curve.G1Affine{}
+		}
+		return
+	}
+
+	b.inBatch[k] = true
+	b.batch = append(b.batch, q)
+	b.batchBuckets = append(b.batchBuckets, k)
+	if len(b.batch) == b.batchSize {
+		b.addBatch()
+	}
+}
+
+// addBatch does the additions of the batch
+func (b *batchAffineBuckets) addBatch() {
+	n := len(b.batch)
+
+	// 1 / (x(q) - x(bucket)) for all the additions, with a single inversion
+	var acc fp.Element
+	acc.SetOne()
+	for i := 0; i < n; i++ {
+		b.denominators[i].Sub(&b.batch[i].X, &b.points[b.batchBuckets[i]].X)
+		b.products[i] = acc
+		acc.Mul(&acc, &b.denominators[i])
+	}
+	acc.Inverse(&acc)
+
+	var inv, λ, x, y fp.Element
+	for i := n - 1; i >= 0; i-- {
+		inv.Mul(&acc, &b.products[i])
+		acc.Mul(&acc, &b.denominators[i])
+
+		bucket, q := &b.points[b.batchBuckets[i]], &b.batch[i]
+		λ.Sub(&q.Y, &bucket.Y).Mul(&λ, &inv)
+		x.Square(&λ).Sub(&x, &bucket.X).Sub(&x, &q.X)
+		y.Sub(&bucket.X, &x).Mul(&y, &λ).Sub(&y, &bucket.Y)
+		bucket.X, bucket.Y = x, y
+		b.inBatch[b.batchBuckets[i]] = false
+	}
+	b.batch = b.batch[:0]
+	b.batchBuckets = b.batchBuckets[:0]
+}
+
+// flush does the additions left in the batch
+func (b *batchAffineBuckets) flush() {
+	if len(b.batch) != 0 {
+		b.addBatch()
+	}
+}
+
+// scalarDigit returns the c bits of s (in regular form) starting at bit start
+func scalarDigit(s *fr.Element, start, c int) int {
+	limb, shift := start/64, uint(start%64)
+	if limb >= fr.Limbs {
+		return 0
+	}
+	d := s[limb] >> shift
+	if int(shift)+c > 64 && limb+1 < fr.Limbs {
+		d |= s[limb+1] << (64 - shift)
+	}
+	return int(d & (1<<uint(c) - 1))
+}
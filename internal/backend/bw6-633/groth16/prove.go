@@ -0,0 +1,499 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package groth16
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bw6-633"
+
+	"github.com/consensys/gnark/internal/backend/bw6-633/cs"
+
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr/fft"
+
+	"bytes"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	bw6_633witness "github.com/consensys/gnark/internal/backend/bw6-633/witness"
+	"github.com/consensys/gnark/internal/utils"
+	"github.com/consensys/gnark/logger"
+	"io"
+	"math/big"
+	"runtime"
+	"time"
+)
+
+// Proof represents a Groth16 proof that was encoded with a ProvingKey and can be verified
+// with a valid statement and a VerifyingKey
+// Notation follows Figure 4. in DIZK paper https://eprint.iacr.org/2018/691.pdf
+type Proof struct {
+	Ar, Krs curve.G1Affine
+	Bs      curve.G2Affine
+}
+
+// CurveID returns the curveID
+func (proof *Proof) CurveID() ecc.ID {
+	return curve.ID
+}
+
+// Prove generates the proof of knoweldge of a r1cs with full witness (secret + public part).
+func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt backend.ProverOption) (*Proof, error) {
+	// sample random r and s
+	var r, s fr.Element
+	if err := setRandom(&r, opt.Randomness); err != nil {
+		return nil, err
+	}
+	if err := setRandom(&s, opt.Randomness); err != nil {
+		return nil, err
+	}
+	return prove(r1cs, pk, witness, opt, r, s)
+}
+
+// setRandom sets e to a random element read from rnd, or from crypto/rand if rnd is nil (see backend.WithRandomness)
+func setRandom(e *fr.Element, rnd io.Reader) error {
+	if rnd == nil {
+		_, err := e.SetRandom()
+		return err
+	}
+	// twice the size of the modulus, so that the reduction is close to uniform
+	var buf [2 * fr.Bytes]byte
+	if _, err := io.ReadFull(rnd, buf[:]); err != nil {
+		return err
+	}
+	e.SetBytes(buf[:])
+	return nil
+}
+
+// prove is Prove with the randomness r and s of the proof
+func prove(r1cs *cs.R1CS, pk *ProvingKey, witness bw6_633witness.Witness, opt backend.ProverOption, _r, _s fr.Element) (*Proof, error) {
+	if len(witness) != int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables) {
+		return nil, fmt.Errorf("invalid witness size, got %d, expected %d = %d (public - ONE_WIRE) + %d (secret)", len(witness), int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables), r1cs.NbPublicVariables, r1cs.NbSecretVariables)
+	}
+
+	start := time.Now()
+
+	// solve the R1CS and compute the a, b, c vectors
+	a := make([]fr.Element, r1cs.Constraints.Len(), pk.Domain.Cardinality)
+	b := make([]fr.Element, r1cs.Constraints.Len(), pk.Domain.Cardinality)
+	c := make([]fr.Element, r1cs.Constraints.Len(), pk.Domain.Cardinality)
+	var wireValues []fr.Element
+	var packed cs.PackedWires // if opt.PackBooleans, wireValues only holds the non boolean wires
+	var err error
+	if opt.PackBooleans {
+		packed, err = r1cs.SolvePacked(witness, a, b, c, opt)
+		wireValues = packed.Values
+	} else {
+		wireValues, err = r1cs.Solve(witness, a, b, c, opt)
+	}
+	if err != nil {
+		if !opt.Force {
+			return nil, err
+		} else {
+			// we need to fill wireValues with random values else multi exps don't do much
+			var r fr.Element
+			_ = setRandom(&r, opt.Randomness)
+			nbWires := r1cs.NbPublicVariables + r1cs.NbSecretVariables + r1cs.NbInternalVariables
+			for i := r1cs.NbPublicVariables + r1cs.NbSecretVariables; i < nbWires; i++ {
+				if !opt.PackBooleans {
+					wireValues[i] = r
+				} else if !packed.Layout.IsBoolean(i) {
+					wireValues[packed.Layout.Index(i)] = r
+				}
+				r.Double(&r)
+			}
+		}
+	}
+	logger.Debug("groth16 prover: %s, %d constraints solved in %s", curve.ID, r1cs.Constraints.Len(), time.Since(start))
+
+	// set the wire values in regular form
+	utils.Parallelize(len(wireValues), func(start, end int) {
+		for i := start; i < end; i++ {
+			wireValues[i].FromMont()
+		}
+	})
+
+	// with the tables of pk.Precompute, the multi exps in G1 are fixed-base ones, over all the wire values
+	pre := pk.precomputation()
+	packedMultiExps := opt.PackBooleans && pre == nil
+	if opt.PackBooleans && pre != nil {
+		wireValues = unpackWires(&packed, packed.Layout.NbWires())
+	}
+
+	if opt.WitnessAnalysis != nil {
+		values := wireValues
+		if packedMultiExps {
+			values = unpackWires(&packed, packed.Layout.NbWires())
+		}
+		if _, err := r1cs.AnalyzeSolution(values).WriteTo(opt.WitnessAnalysis); err != nil {
+			return nil, fmt.Errorf("witness analysis: %w", err)
+		}
+	}
+
+	// H (witness reduction / FFT part)
+	var h []fr.Element
+	chHDone := make(chan struct{}, 1)
+	go func() {
+		h = computeH(a, b, c, &pk.Domain)
+		a = nil
+		b = nil
+		c = nil
+		chHDone <- struct{}{}
+	}()
+
+	// we need to copy and filter the wireValues for each multi exp
+	// as pk.G1.A, pk.G1.B and pk.G2.B may have (a significant) number of point at infinity
+	var wireValuesA, wireValuesB []fr.Element
+	chWireValuesA, chWireValuesB := make(chan struct{}, 1), make(chan struct{}, 1)
+
+	if packedMultiExps {
+		// the multi exps filter the packed wires themselves, see multiExpPackedG1
+		close(chWireValuesA)
+		close(chWireValuesB)
+	} else {
+		go func() {
+			wireValuesA = make([]fr.Element, len(wireValues)-int(pk.NbInfinityA))
+			for i, j := 0, 0; j < len(wireValuesA); i++ {
+				if pk.InfinityA[i] {
+					continue
+				}
+				wireValuesA[j] = wireValues[i]
+				j++
+			}
+			close(chWireValuesA)
+		}()
+		go func() {
+			wireValuesB = make([]fr.Element, len(wireValues)-int(pk.NbInfinityB))
+			for i, j := 0, 0; j < len(wireValuesB); i++ {
+				if pk.InfinityB[i] {
+					continue
+				}
+				wireValuesB[j] = wireValues[i]
+				j++
+			}
+			close(chWireValuesB)
+		}()
+	}
+
+	// kr = -r*s, and r, s, kr in regular form
+	var r, s big.Int
+	var _kr fr.Element
+	_kr.Mul(&_r, &_s).Neg(&_kr)
+
+	_r.FromMont()
+	_s.FromMont()
+	_kr.FromMont()
+	_r.ToBigInt(&r)
+	_s.ToBigInt(&s)
+
+	// computes r[δ], s[δ], kr[δ]
+	deltas := curve.BatchScalarMultiplicationG1(&pk.G1.Delta, []fr.Element{_r, _s, _kr})
+
+	proof := &Proof{}
+	var bs1, ar curve.G1Jac
+
+	n := runtime.NumCPU()
+
+	chBs1Done := make(chan error, 1)
+	computeBS1 := func() {
+		<-chWireValuesB
+		var err error
+		if pre != nil {
+			err = pre.B.multiExp(&bs1, wireValuesB, n/2)
+		} else if packedMultiExps {
+			err = multiExpPackedG1(&bs1, pk.G1.B, pk.InfinityB, &packed, 0, ecc.MultiExpConfig{NbTasks: n / 2})
+		} else {
+			_, err = bs1.MultiExp(pk.G1.B, wireValuesB, ecc.MultiExpConfig{NbTasks: n / 2})
+		}
+		if err != nil {
+			chBs1Done <- err
+			close(chBs1Done)
+			return
+		}
+		bs1.AddMixed(&pk.G1.Beta)
+		bs1.AddMixed(&deltas[1])
+		chBs1Done <- nil
+	}
+
+	chArDone := make(chan error, 1)
+	computeAR1 := func() {
+		<-chWireValuesA
+		var err error
+		if pre != nil {
+			err = pre.A.multiExp(&ar, wireValuesA, n/2)
+		} else if packedMultiExps {
+			err = multiExpPackedG1(&ar, pk.G1.A, pk.InfinityA, &packed, 0, ecc.MultiExpConfig{NbTasks: n / 2})
+		} else {
+			_, err = ar.MultiExp(pk.G1.A, wireValuesA, ecc.MultiExpConfig{NbTasks: n / 2})
+		}
+		if err != nil {
+			chArDone <- err
+			close(chArDone)
+			return
+		}
+		ar.AddMixed(&pk.G1.Alpha)
+		ar.AddMixed(&deltas[0])
+		proof.Ar.FromJacobian(&ar)
+		chArDone <- nil
+	}
+
+	chKrsDone := make(chan error, 1)
+	computeKRS := func() {
+		// we could NOT split the Krs multiExp in 2, and just append pk.G1.K and pk.G1.Z
+		// however, having similar lengths for our tasks helps with parallelism
+
+		var krs, krs2, p1 curve.G1Jac
+		chKrs2Done := make(chan error, 1)
+		go func() {
+			var err error
+			if pre != nil {
+				err = pre.Z.multiExp(&krs2, h, n/2)
+			} else {
+				_, err = krs2.MultiExp(pk.G1.Z, h, ecc.MultiExpConfig{NbTasks: n / 2})
+			}
+			chKrs2Done <- err
+		}()
+		var err error
+		if pre != nil {
+			err = pre.K.multiExp(&krs, wireValues[r1cs.NbPublicVariables:], n/2)
+		} else if packedMultiExps {
+			err = multiExpPackedG1(&krs, pk.G1.K, nil, &packed, r1cs.NbPublicVariables, ecc.MultiExpConfig{NbTasks: n / 2})
+		} else {
+			_, err = krs.MultiExp(pk.G1.K, wireValues[r1cs.NbPublicVariables:], ecc.MultiExpConfig{NbTasks: n / 2})
+		}
+		if err != nil {
+			chKrsDone <- err
+			return
+		}
+		krs.AddMixed(&deltas[2])
+		n := 3
+		for n != 0 {
+			select {
+			case err := <-chKrs2Done:
+				if err != nil {
+					chKrsDone <- err
+					return
+				}
+				krs.AddAssign(&krs2)
+			case err := <-chArDone:
+				if err != nil {
+					chKrsDone <- err
+					return
+				}
+				p1.ScalarMultiplication(&ar, &s)
+				krs.AddAssign(&p1)
+			case err := <-chBs1Done:
+				if err != nil {
+					chKrsDone <- err
+					return
+				}
+				p1.ScalarMultiplication(&bs1, &r)
+				krs.AddAssign(&p1)
+			}
+			n--
+		}
+
+		proof.Krs.FromJacobian(&krs)
+		chKrsDone <- nil
+	}
+
+	computeBS2 := func() error {
+		// Bs2 (1 multi exp G2 - size = len(wires))
+		var Bs, deltaS curve.G2Jac
+
+		nbTasks := n
+		if nbTasks <= 16 {
+			// if we don't have a lot of CPUs, this may artificially split the MSM
+			nbTasks *= 2
+		}
+		<-chWireValuesB
+		var err error
+		if packedMultiExps {
+			err = multiExpPackedG2(&Bs, pk.G2.B, pk.InfinityB, &packed, ecc.MultiExpConfig{NbTasks: nbTasks})
+		} else {
+			_, err = Bs.MultiExp(pk.G2.B, wireValuesB, ecc.MultiExpConfig{NbTasks: nbTasks})
+		}
+		if err != nil {
+			return err
+		}
+
+		deltaS.FromAffine(&pk.G2.Delta)
+		deltaS.ScalarMultiplication(&deltaS, &s)
+		Bs.AddAssign(&deltaS)
+		Bs.AddMixed(&pk.G2.Beta)
+
+		proof.Bs.FromJacobian(&Bs)
+		return nil
+	}
+
+	// wait for FFT to end, as it uses all our CPUs
+	<-chHDone
+
+	// schedule our proof part computations
+	go computeKRS()
+	go computeAR1()
+	go computeBS1()
+	if err := computeBS2(); err != nil {
+		return nil, err
+	}
+
+	// wait for all parts of the proof to be computed.
+	if err := <-chKrsDone; err != nil {
+		return nil, err
+	}
+	logger.Debug("groth16 prover: %s, proof computed in %s", curve.ID, time.Since(start))
+
+	// the components are computed concurrently, see backend.WithProofComponents
+	if opt.ProofComponents != nil {
+		names := []string{"ar", "bs", "krs"}
+		for i, v := range []interface{}{&proof.Ar, &proof.Bs, &proof.Krs} {
+			var buf bytes.Buffer
+			if err := curve.NewEncoder(&buf).Encode(v); err != nil {
+				return nil, err
+			}
+			if err := opt.ProofComponents(names[i], buf.Bytes()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return proof, nil
+}
+
+// unpackWires returns the values of the nbWires wires of w, in regular form as w.Values
+func unpackWires(w *cs.PackedWires, nbWires int) []fr.Element {
+	values := make([]fr.Element, nbWires)
+	utils.Parallelize(nbWires, func(start, end int) {
+		for i := start; i < end; i++ {
+			if !w.Layout.IsBoolean(i) {
+				values[i] = w.Values[w.Layout.Index(i)]
+			} else if w.Bits.Get(w.Layout.Index(i)) {
+				values[i][0] = 1 // 1 in regular form
+			}
+		}
+	})
+	return values
+}
+
+// multiExpPackedG1 sets res to the multi exponentiation of points by the values of the wires [from, ...) of w,
+// skipping the wires i with infinity[i] set (infinity may be nil), as their points are not in points.
+// The boolean wires are accumulated with point additions, the others with a multi exponentiation.
+func multiExpPackedG1(res *curve.G1Jac, points []curve.G1Affine, infinity []bool, w *cs.PackedWires, from int, config ecc.MultiExpConfig) error {
+	var bases []curve.G1Affine
+	var scalars []fr.Element
+	var acc curve.G1Jac // point at infinity
+	for i, j := from, 0; j < len(points); i++ {
+		if infinity != nil && infinity[i] {
+			continue
+		}
+		if !w.Layout.IsBoolean(i) {
+			bases = append(bases, points[j])
+			scalars = append(scalars, w.Values[w.Layout.Index(i)])
+		} else if w.Bits.Get(w.Layout.Index(i)) {
+			acc.AddMixed(&points[j])
+		}
+		j++
+	}
+	if len(bases) == 0 {
+		res.Set(&acc)
+		return nil
+	}
+	if _, err := res.MultiExp(bases, scalars, config); err != nil {
+		return err
+	}
+	res.AddAssign(&acc)
+	return nil
+}
+
+// multiExpPackedG2 is multiExpPackedG1 in G2, for all the wires
+func multiExpPackedG2(res *curve.G2Jac, points []curve.G2Affine, infinity []bool, w *cs.PackedWires, config ecc.MultiExpConfig) error {
+	var bases []curve.G2Affine
+	var scalars []fr.Element
+	var acc curve.G2Jac // point at infinity
+	for i, j := 0, 0; j < len(points); i++ {
+		if infinity[i] {
+			continue
+		}
+		if !w.Layout.IsBoolean(i) {
+			bases = append(bases, points[j])
+			scalars = append(scalars, w.Values[w.Layout.Index(i)])
+		} else if w.Bits.Get(w.Layout.Index(i)) {
+			acc.AddMixed(&points[j])
+		}
+		j++
+	}
+	if len(bases) == 0 {
+		res.Set(&acc)
+		return nil
+	}
+	if _, err := res.MultiExp(bases, scalars, config); err != nil {
+		return err
+	}
+	res.AddAssign(&acc)
+	return nil
+}
+
+func computeH(a, b, c []fr.Element, domain *fft.Domain) []fr.Element {
+	// H part of Krs
+	// Compute H (hz=ab-c, where z=-2 on ker X^n+1 (z(x)=x^n-1))
+	// 	1 - _a = ifft(a), _b = ifft(b), _c = ifft(c)
+	// 	2 - ca = fft_coset(_a), ba = fft_coset(_b), cc = fft_coset(_c)
+	// 	3 - h = ifft_coset(ca o cb - cc)
+
+	n := len(a)
+
+	// add padding to ensure input length is domain cardinality
+	padding := make([]fr.Element, int(domain.Cardinality)-n)
+	a = append(a, padding...)
+	b = append(b, padding...)
+	c = append(c, padding...)
+	n = len(a)
+
+	domain.FFTInverse(a, fft.DIF, 0)
+	domain.FFTInverse(b, fft.DIF, 0)
+	domain.FFTInverse(c, fft.DIF, 0)
+
+	domain.FFT(a, fft.DIT, 1)
+	domain.FFT(b, fft.DIT, 1)
+	domain.FFT(c, fft.DIT, 1)
+
+	var minusTwoInv fr.Element
+	minusTwoInv.SetUint64(2)
+	minusTwoInv.Neg(&minusTwoInv).
+		Inverse(&minusTwoInv)
+
+	// h = ifft_coset(ca o cb - cc)
+	// reusing a to avoid unecessary memalloc
+	utils.Parallelize(n, func(start, end int) {
+		for i := start; i < end; i++ {
+			a[i].Mul(&a[i], &b[i]).
+				Sub(&a[i], &c[i]).
+				Mul(&a[i], &minusTwoInv)
+		}
+	})
+
+	// ifft_coset
+	domain.FFTInverse(a, fft.DIF, 1)
+
+	utils.Parallelize(len(a), func(start, end int) {
+		for i := start; i < end; i++ {
+			a[i].FromMont()
+		}
+	})
+
+	return a
+}
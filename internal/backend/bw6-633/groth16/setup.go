@@ -0,0 +1,681 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package groth16
+
+import (
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bw6-633"
+
+	"github.com/consensys/gnark/internal/backend/bw6-633/cs"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr/fft"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/consensys/gnark/internal/utils"
+	"github.com/consensys/gnark/logger"
+	"math/big"
+	"math/bits"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// ProvingKey is used by a Groth16 prover to encode a proof of a statement
+// Notation follows Figure 4. in DIZK paper https://eprint.iacr.org/2018/691.pdf
+type ProvingKey struct {
+	// domain
+	Domain fft.Domain
+
+	// [α]1, [β]1, [δ]1
+	// [A(t)]1, [B(t)]1, [Kpk(t)]1, [Z(t)]1
+	G1 struct {
+		Alpha, Beta, Delta curve.G1Affine
+		A, B, Z            []curve.G1Affine
+		K                  []curve.G1Affine // the indexes correspond to the private wires
+	}
+
+	// [β]2, [δ]2, [B(t)]2
+	G2 struct {
+		Beta, Delta curve.G2Affine
+		B           []curve.G2Affine
+	}
+
+	// if InfinityA[i] == true, the point G1.A[i] == infinity
+	InfinityA, InfinityB     []bool
+	NbInfinityA, NbInfinityB uint64
+
+	// metadata of the constraint system (see frontend.WithMetadata)
+	Metadata map[string]string
+
+	// fixed-base tables of the G1 points, built by Precompute, not serialized (see WritePrecomputedTo)
+	precomputed *pkPrecomputation
+}
+
+// VerifyingKey is used by a Groth16 verifier to verify the validity of a proof and a statement
+// Notation follows Figure 4. in DIZK paper https://eprint.iacr.org/2018/691.pdf
+type VerifyingKey struct {
+	// [α]1, [Kvk]1
+	G1 struct {
+		Alpha       curve.G1Affine
+		Beta, Delta curve.G1Affine   // unused, here for compatibility purposes
+		K           []curve.G1Affine // The indexes correspond to the public wires
+	}
+
+	// [β]2, [δ]2, [γ]2
+	G2 struct {
+		Beta, Delta, Gamma curve.G2Affine
+	}
+
+	// *vkPrecomputation, cached by Precompute, not serialized (see WritePreparedTo)
+	precomputed atomic.Value
+
+	// metadata of the constraint system (see frontend.WithMetadata)
+	Metadata map[string]string
+}
+
+// Setup constructs the SRS
+func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
+	start := time.Now()
+
+	/*
+		Setup
+		-----
+		To build the verifying keys:
+		- compile the r1cs system -> the number of gates is len(GateOrdering)+len(PureStructuralConstraints)+len(InpureStructuralConstraints)
+		- loop through the ordered computational constraints (=gate in r1cs system structure), eValuate A(X), B(X), C(X) with simple formula (the gate number is the current iterator)
+		- loop through the inpure structural constraints, eValuate A(X), B(X), C(X) with simple formula, the gate number is len(gateOrdering)+ current iterator
+		- loop through the pure structural constraints, eValuate A(X), B(X), C(X) with simple formula, the gate number is len(gateOrdering)+len(InpureStructuralConstraints)+current iterator
+	*/
+
+	// get R1CS nb constraints, wires and public/private inputs
+	nbWires := r1cs.NbInternalVariables + r1cs.NbPublicVariables + r1cs.NbSecretVariables
+	nbPublicWires := int(r1cs.NbPublicVariables)
+	nbPrivateWires := r1cs.NbSecretVariables + r1cs.NbInternalVariables
+
+	// Setting group for fft
+	domain := fft.NewDomain(uint64(r1cs.Constraints.Len()), 1, true)
+
+	// samples toxic waste
+	toxicWaste, err := sampleToxicWaste()
+	if err != nil {
+		return err
+	}
+
+	// Setup coeffs to compute pk.G1.A, pk.G1.B, pk.G1.K
+	A, B, C := setupABC(r1cs, domain, toxicWaste)
+
+	// To fill in the Proving and Verifying keys, we need to perform a lot of ecc scalar multiplication (with generator)
+	// and convert the resulting points to affine
+	// this is done using the curve.BatchScalarMultiplicationGX API, which takes as input the base point
+	// (in our case the generator) and the list of scalars, and outputs a list of points (len(points) == len(scalars))
+	// to use this batch call, we need to order our scalars in the same slice
+	// we have 1 batch call for G1 and 1 batch call for G1
+	// scalars are fr.Element in non montgomery form
+	_, _, g1, g2 := curve.Generators()
+
+	// ---------------------------------------------------------------------------------------------
+	// G1 scalars
+
+	// the G1 scalars are ordered (arbitrary) as follow:
+	//
+	// [[α], [β], [δ], [A(i)], [B(i)], [pk.K(i)], [Z(i)], [vk.K(i)]]
+	// len(A) == len(B) == nbWires
+	// len(pk.K) == nbPrivateWires
+	// len(vk.K) == nbPublicWires
+	// len(Z) == domain.Cardinality
+
+	// compute scalars for pkK and vkK
+	pkK := make([]fr.Element, nbPrivateWires)
+	vkK := make([]fr.Element, nbPublicWires)
+
+	var t0, t1 fr.Element
+
+	for i := 0; i < nbPublicWires; i++ {
+		t1.Mul(&A[i], &toxicWaste.beta)
+		t0.Mul(&B[i], &toxicWaste.alpha)
+		t1.Add(&t1, &t0).
+			Add(&t1, &C[i]).
+			Mul(&t1, &toxicWaste.gammaInv)
+		vkK[i] = t1.ToRegular()
+	}
+
+	for i := 0; i < nbPrivateWires; i++ {
+		t1.Mul(&A[i+nbPublicWires], &toxicWaste.beta)
+		t0.Mul(&B[i+nbPublicWires], &toxicWaste.alpha)
+		t1.Add(&t1, &t0).
+			Add(&t1, &C[i+nbPublicWires]).
+			Mul(&t1, &toxicWaste.deltaInv)
+		pkK[i] = t1.ToRegular()
+	}
+
+	// convert A and B to regular form
+	for i := 0; i < int(nbWires); i++ {
+		A[i].FromMont()
+	}
+	for i := 0; i < int(nbWires); i++ {
+		B[i].FromMont()
+	}
+
+	// Z part of the proving key (scalars)
+	Z := make([]fr.Element, domain.Cardinality)
+	one := fr.One()
+	var zdt fr.Element
+
+	zdt.Exp(toxicWaste.t, new(big.Int).SetUint64(domain.Cardinality)).
+		Sub(&zdt, &one).
+		Mul(&zdt, &toxicWaste.deltaInv) // sets Zdt to Zdt/delta
+
+	for i := 0; i < int(domain.Cardinality); i++ {
+		Z[i] = zdt.ToRegular()
+		zdt.Mul(&zdt, &toxicWaste.t)
+	}
+
+	// mark points at infinity and filter them
+	pk.InfinityA = make([]bool, len(A))
+	pk.InfinityB = make([]bool, len(B))
+
+	n := 0
+	for i, e := range A {
+		if e.IsZero() {
+			pk.InfinityA[i] = true
+			continue
+		}
+		A[n] = A[i]
+		n++
+	}
+	A = A[:n]
+	pk.NbInfinityA = uint64(nbWires - n)
+	n = 0
+	for i, e := range B {
+		if e.IsZero() {
+			pk.InfinityB[i] = true
+			continue
+		}
+		B[n] = B[i]
+		n++
+	}
+	B = B[:n]
+	pk.NbInfinityB = uint64(nbWires - n)
+
+	// compute our batch scalar multiplication with g1 elements
+	g1Scalars := make([]fr.Element, 0, (nbWires*3)+int(domain.Cardinality)+3)
+	g1Scalars = append(g1Scalars, toxicWaste.alphaReg, toxicWaste.betaReg, toxicWaste.deltaReg)
+	g1Scalars = append(g1Scalars, A...)
+	g1Scalars = append(g1Scalars, B...)
+	g1Scalars = append(g1Scalars, pkK...)
+	g1Scalars = append(g1Scalars, Z...)
+	g1Scalars = append(g1Scalars, vkK...)
+
+	g1PointsAff := curve.BatchScalarMultiplicationG1(&g1, g1Scalars)
+
+	// sets pk: [α]1, [β]1, [δ]1
+	pk.G1.Alpha = g1PointsAff[0]
+	pk.G1.Beta = g1PointsAff[1]
+	pk.G1.Delta = g1PointsAff[2]
+
+	offset := 3
+	pk.G1.A = g1PointsAff[offset : offset+len(A)]
+	offset += len(A)
+
+	pk.G1.B = g1PointsAff[offset : offset+len(B)]
+	offset += len(B)
+
+	pk.G1.K = g1PointsAff[offset : offset+nbPrivateWires]
+	offset += nbPrivateWires
+
+	pk.G1.Z = g1PointsAff[offset : offset+int(domain.Cardinality)]
+	bitReverse(pk.G1.Z)
+
+	offset += int(domain.Cardinality)
+
+	vk.G1.K = g1PointsAff[offset:]
+
+	// ---------------------------------------------------------------------------------------------
+	// G2 scalars
+
+	// the G2 scalars are ordered as follow:
+	//
+	// [[B(i)], [β], [δ], [γ]]
+	// len(B) == nbWires
+
+	// compute our batch scalar multiplication with g2 elements
+	g2Scalars := append(B, toxicWaste.betaReg, toxicWaste.deltaReg, toxicWaste.gammaReg)
+
+	g2PointsAff := curve.BatchScalarMultiplicationG2(&g2, g2Scalars)
+
+	pk.G2.B = g2PointsAff[:len(B)]
+
+	// sets pk: [β]2, [δ]2
+	pk.G2.Beta = g2PointsAff[len(B)+0]
+	pk.G2.Delta = g2PointsAff[len(B)+1]
+
+	// sets vk: [δ]2, [γ]2
+	vk.G2.Delta = g2PointsAff[len(B)+1]
+	vk.G2.Gamma = g2PointsAff[len(B)+2]
+
+	// ---------------------------------------------------------------------------------------------
+	// Pairing: e(α, β)
+	vk.G1.Alpha = pk.G1.Alpha
+	vk.G2.Beta = pk.G2.Beta
+
+	// unused, here for compatibility purposes
+	vk.G1.Beta = pk.G1.Beta
+	vk.G1.Delta = pk.G1.Delta
+
+	vk.resetPrecomputation()
+	if err := vk.Precompute(); err != nil {
+		return err
+	}
+
+	// set domain
+	pk.Domain = *domain
+
+	pk.Metadata = compiled.CopyMetadata(r1cs.Metadata)
+	vk.Metadata = compiled.CopyMetadata(r1cs.Metadata)
+
+	logger.Debug("groth16 setup: %s, %d constraints, done in %s", curve.ID, r1cs.Constraints.Len(), time.Since(start))
+
+	return nil
+}
+
+// setupChunkSize is the number of constraints processed at once by setupABC;
+// it bounds the scratch memory used to evaluate the Lagrange polynomials at t
+const setupChunkSize = 1 << 16
+
+// setupABC evaluates at t the QAP polynomials A, B, C of each wire.
+//
+// The constraints are streamed by chunks: the i-th Lagrange polynomial evaluated at t
+// is computed directly from i, such that no domain sized buffer is needed, and the
+// chunks don't depend on each other. Within a chunk, the Lagrange evaluations are
+// computed in parallel, then accumulated in A, B and C by tasks owning disjoint sets of wires.
+func setupABC(r1cs *cs.R1CS, domain *fft.Domain, toxicWaste toxicWaste) (A []fr.Element, B []fr.Element, C []fr.Element) {
+
+	nbWires := r1cs.NbInternalVariables + r1cs.NbPublicVariables + r1cs.NbSecretVariables
+
+	A = make([]fr.Element, nbWires)
+	B = make([]fr.Element, nbWires)
+	C = make([]fr.Element, nbWires)
+
+	one := fr.One()
+
+	// L_i(t) = w^i/n * (t^n-1)/(t-w^i)
+	// we set zn = (t^n-1)/n
+	var zn fr.Element
+	zn.Exp(toxicWaste.t, new(big.Int).SetUint64(uint64(domain.Cardinality))).
+		Sub(&zn, &one).
+		Mul(&zn, &domain.CardinalityInv)
+
+	accumulate := func(res *fr.Element, t compiled.Term, value *fr.Element) {
+		cID := t.CoeffID()
+		switch cID {
+		case compiled.CoeffIdZero:
+			return
+		case compiled.CoeffIdOne:
+			res.Add(res, value)
+		case compiled.CoeffIdMinusOne:
+			res.Sub(res, value)
+		case compiled.CoeffIdTwo:
+			var buffer fr.Element
+			buffer.Double(value)
+			res.Add(res, &buffer)
+		default:
+			var buffer fr.Element
+			buffer.Mul(&r1cs.Coefficients[cID], value)
+			res.Add(res, &buffer)
+		}
+	}
+
+	nbTasks := runtime.NumCPU()
+	L := make([]fr.Element, setupChunkSize)
+	tInv := make([]fr.Element, setupChunkSize)
+	ABC := [3][]fr.Element{A, B, C}
+	buckets := make([][]setupTerm, nbTasks)
+
+	nbConstraints := r1cs.Constraints.Len()
+	for start := 0; start < nbConstraints; start += setupChunkSize {
+		end := start + setupChunkSize
+		if end > nbConstraints {
+			end = nbConstraints
+		}
+		L := L[:end-start]
+
+		// evaluation of the Lagrange polynomials at t
+		utils.Parallelize(len(L), func(s, e int) {
+			var wi, w0 fr.Element
+			w0.Exp(domain.Generator, big.NewInt(int64(start+s)))
+
+			// [t-w^i]
+			wi.Set(&w0)
+			for i := s; i < e; i++ {
+				L[i].Sub(&toxicWaste.t, &wi)
+				wi.Mul(&wi, &domain.Generator)
+			}
+
+			// batch inversion of [t-w^i], in the scratch buffer
+			acc := fr.One()
+			for i := s; i < e; i++ {
+				tInv[i] = acc
+				acc.Mul(&acc, &L[i])
+			}
+			acc.Inverse(&acc)
+			for i := e - 1; i >= s; i-- {
+				tInv[i].Mul(&tInv[i], &acc)
+				acc.Mul(&acc, &L[i])
+			}
+
+			// L_i(t) = zn * w^i / (t-w^i)
+			wi.Set(&w0)
+			for i := s; i < e; i++ {
+				L[i].Mul(&tInv[i], &wi).Mul(&L[i], &zn)
+				wi.Mul(&wi, &domain.Generator)
+			}
+		}, nbTasks)
+
+		// each constraint is in the form
+		// L * R == O
+		// L, R and O being linear expressions
+		// for each term appearing in the linear expression,
+		// we compute term.Coefficient * L, and cumulate it in
+		// A, B or C at the indice of the variable
+		// the task k owns the wires whose ID is k modulo nbTasks: the terms of the chunk are
+		// bucketed by task in one pass, then each task accumulates the terms of its bucket
+		for k := range buckets {
+			buckets[k] = buckets[k][:0]
+		}
+		var c compiled.R1C // the constraints are expanded one at a time, see compiled.R1CList
+		for i := range L {
+			r1cs.Constraints.Load(start+i, &c)
+			for m, l := range [3]compiled.LinearExpression{c.L, c.R, c.O} {
+				for _, t := range l {
+					k := t.VariableID() % nbTasks
+					buckets[k] = append(buckets[k], setupTerm{t: t, i: int32(i), m: uint8(m)})
+				}
+			}
+		}
+		utils.Parallelize(nbTasks, func(s, e int) {
+			for k := s; k < e; k++ {
+				for _, st := range buckets[k] {
+					accumulate(&ABC[st.m][st.t.VariableID()], st.t, &L[st.i])
+				}
+			}
+		}, nbTasks)
+	}
+	return
+
+}
+
+// setupTerm is a term of the i-th constraint of a chunk of setupABC, in the linear expression L, R or O
+// (m = 0, 1 or 2)
+type setupTerm struct {
+	t compiled.Term
+	i int32
+	m uint8
+}
+
+// toxicWaste toxic waste
+type toxicWaste struct {
+
+	// Montgomery form of params
+	t, alpha, beta, gamma, delta fr.Element
+	gammaInv, deltaInv           fr.Element
+
+	// Non Montgomery form of params
+	alphaReg, betaReg, gammaReg, deltaReg fr.Element
+}
+
+func sampleToxicWaste() (toxicWaste, error) {
+
+	res := toxicWaste{}
+
+	for res.t.IsZero() {
+		if _, err := res.t.SetRandom(); err != nil {
+			return res, err
+		}
+	}
+	for res.alpha.IsZero() {
+		if _, err := res.alpha.SetRandom(); err != nil {
+			return res, err
+		}
+	}
+	for res.beta.IsZero() {
+		if _, err := res.beta.SetRandom(); err != nil {
+			return res, err
+		}
+	}
+	for res.gamma.IsZero() {
+		if _, err := res.gamma.SetRandom(); err != nil {
+			return res, err
+		}
+	}
+	for res.delta.IsZero() {
+		if _, err := res.delta.SetRandom(); err != nil {
+			return res, err
+		}
+	}
+
+	res.gammaInv.Inverse(&res.gamma)
+	res.deltaInv.Inverse(&res.delta)
+
+	res.alphaReg = res.alpha.ToRegular()
+	res.betaReg = res.beta.ToRegular()
+	res.gammaReg = res.gamma.ToRegular()
+	res.deltaReg = res.delta.ToRegular()
+
+	return res, nil
+}
+
+// DummySetup fills a random ProvingKey
+// used for test or benchmarking purposes
+func DummySetup(r1cs *cs.R1CS, pk *ProvingKey) error {
+	// get R1CS nb constraints, wires and public/private inputs
+	nbWires := r1cs.NbInternalVariables + r1cs.NbPublicVariables + r1cs.NbSecretVariables
+	nbConstraints := r1cs.Constraints.Len()
+
+	// Setting group for fft
+	domain := fft.NewDomain(uint64(nbConstraints), 1, true)
+
+	// count number of infinity points we would have had we a normal setup
+	// in pk.G1.A, pk.G1.B, and pk.G2.B
+	nbZeroesA, nbZeroesB := dummyInfinityCount(r1cs)
+
+	// initialize proving key
+	pk.G1.A = make([]curve.G1Affine, nbWires-nbZeroesA)
+	pk.G1.B = make([]curve.G1Affine, nbWires-nbZeroesB)
+	pk.G1.K = make([]curve.G1Affine, nbWires-r1cs.NbPublicVariables)
+	pk.G1.Z = make([]curve.G1Affine, domain.Cardinality)
+	pk.G2.B = make([]curve.G2Affine, nbWires-nbZeroesB)
+
+	// set infinity markers
+	pk.InfinityA = make([]bool, nbWires)
+	pk.InfinityB = make([]bool, nbWires)
+	pk.NbInfinityA = uint64(nbZeroesA)
+	pk.NbInfinityB = uint64(nbZeroesB)
+	for i := 0; i < nbZeroesA; i++ {
+		pk.InfinityA[i] = true
+	}
+	for i := 0; i < nbZeroesB; i++ {
+		pk.InfinityB[i] = true
+	}
+
+	// samples toxic waste
+	toxicWaste, err := sampleToxicWaste()
+	if err != nil {
+		return err
+	}
+
+	var r1Jac curve.G1Jac
+	var r1Aff curve.G1Affine
+	var b big.Int
+	g1, g2, _, _ := curve.Generators()
+	r1Jac.ScalarMultiplication(&g1, toxicWaste.alphaReg.ToBigInt(&b))
+	r1Aff.FromJacobian(&r1Jac)
+	var r2Jac curve.G2Jac
+	var r2Aff curve.G2Affine
+	r2Jac.ScalarMultiplication(&g2, &b)
+	r2Aff.FromJacobian(&r2Jac)
+	for i := 0; i < len(pk.G1.A); i++ {
+		pk.G1.A[i] = r1Aff
+	}
+	for i := 0; i < len(pk.G1.B); i++ {
+		pk.G1.B[i] = r1Aff
+	}
+	for i := 0; i < len(pk.G2.B); i++ {
+		pk.G2.B[i] = r2Aff
+	}
+	for i := 0; i < len(pk.G1.Z); i++ {
+		pk.G1.Z[i] = r1Aff
+	}
+	for i := 0; i < len(pk.G1.K); i++ {
+		pk.G1.K[i] = r1Aff
+	}
+	pk.G1.Alpha = r1Aff
+	pk.G1.Beta = r1Aff
+	pk.G1.Delta = r1Aff
+	pk.G2.Beta = r2Aff
+	pk.G2.Delta = r2Aff
+
+	pk.Domain = *domain
+	pk.Metadata = compiled.CopyMetadata(r1cs.Metadata)
+
+	return nil
+}
+
+// dummyInfinityCount helps us simulate the number of infinity points we have with the given R1CS
+// in A and B as it directly impacts prover performance
+func dummyInfinityCount(r1cs *cs.R1CS) (nbZeroesA, nbZeroesB int) {
+
+	nbWires := r1cs.NbInternalVariables + r1cs.NbPublicVariables + r1cs.NbSecretVariables
+
+	A := make([]bool, nbWires)
+	B := make([]bool, nbWires)
+	var c compiled.R1C
+	for i := 0; i < r1cs.Constraints.Len(); i++ {
+		r1cs.Constraints.Load(i, &c)
+		for _, t := range c.L {
+			A[t.VariableID()] = true
+		}
+		for _, t := range c.R {
+			B[t.VariableID()] = true
+		}
+	}
+	for i := 0; i < nbWires; i++ {
+		if !A[i] {
+			nbZeroesA++
+		}
+		if !B[i] {
+			nbZeroesB++
+		}
+	}
+	return
+
+}
+
+// IsDifferent returns true if provided vk is different than self
+// this is used by groth16.Assert to ensure random sampling
+func (vk *VerifyingKey) IsDifferent(_other interface{}) bool {
+	vk2 := _other.(*VerifyingKey)
+	for i := 0; i < len(vk.G1.K); i++ {
+		if !vk.G1.K[i].IsInfinity() {
+			if vk.G1.K[i].Equal(&vk2.G1.K[i]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// IsDifferent returns true if provided pk is different than self
+// this is used by groth16.Assert to ensure random sampling
+func (pk *ProvingKey) IsDifferent(_other interface{}) bool {
+	pk2 := _other.(*ProvingKey)
+
+	if pk.G1.Alpha.Equal(&pk2.G1.Alpha) ||
+		pk.G1.Beta.Equal(&pk2.G1.Beta) ||
+		pk.G1.Delta.Equal(&pk2.G1.Delta) {
+		return false
+	}
+
+	for i := 0; i < len(pk.G1.K); i++ {
+		if !pk.G1.K[i].IsInfinity() {
+			if pk.G1.K[i].Equal(&pk2.G1.K[i]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// GetMetadata returns the metadata of the constraint system the key was generated for
+func (pk *ProvingKey) GetMetadata() map[string]string {
+	return pk.Metadata
+}
+
+// GetMetadata returns the metadata of the constraint system the key was generated for
+func (vk *VerifyingKey) GetMetadata() map[string]string {
+	return vk.Metadata
+}
+
+// CurveID returns the curveID
+func (pk *ProvingKey) CurveID() ecc.ID {
+	return curve.ID
+}
+
+// CurveID returns the curveID
+func (vk *VerifyingKey) CurveID() ecc.ID {
+	return curve.ID
+}
+
+// NbPublicWitness returns the number of elements in the expected public witness
+func (vk *VerifyingKey) NbPublicWitness() int {
+	return (len(vk.G1.K) - 1)
+}
+
+// NbG1 returns the number of G1 elements in the VerifyingKey
+func (vk *VerifyingKey) NbG1() int {
+	return 3 + len(vk.G1.K)
+}
+
+// NbG2 returns the number of G2 elements in the VerifyingKey
+func (vk *VerifyingKey) NbG2() int {
+	return 3
+}
+
+// NbG1 returns the number of G1 elements in the ProvingKey
+func (pk *ProvingKey) NbG1() int {
+	return 3 + len(pk.G1.A) + len(pk.G1.B) + len(pk.G1.Z) + len(pk.G1.K)
+}
+
+// NbG2 returns the number of G2 elements in the ProvingKey
+func (pk *ProvingKey) NbG2() int {
+	return 2 + len(pk.G2.B)
+}
+
+// bitRerverse permutation as in fft.BitReverse , but with []curve.G1Affine
+func bitReverse(a []curve.G1Affine) {
+	n := uint(len(a))
+	nn := uint(bits.UintSize - bits.TrailingZeros(n))
+
+	for i := uint(0); i < n; i++ {
+		irev := bits.Reverse(i) >> nn
+		if irev > i {
+			a[i], a[irev] = a[irev], a[i]
+		}
+	}
+}
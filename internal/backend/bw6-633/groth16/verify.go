@@ -0,0 +1,212 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package groth16
+
+import (
+	curve "github.com/consensys/gnark-crypto/ecc/bw6-633"
+
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
+
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16/bw6633verifier"
+	bw6_633witness "github.com/consensys/gnark/internal/backend/bw6-633/witness"
+	"io"
+	"math/big"
+)
+
+// Verify verifies a proof with given VerifyingKey and publicWitness
+func Verify(proof *Proof, vk *VerifyingKey, publicWitness bw6_633witness.Witness) error {
+	pre, err := vk.precompute()
+	if err != nil {
+		return err
+	}
+	return pre.Verify((*bw6633verifier.Proof)(proof), vk.G1.K, publicWitness)
+}
+
+// vkPrecomputation holds the values derived from a VerifyingKey that Verify needs; the verification
+// itself is implemented in the verifier-only package bw6633verifier
+type vkPrecomputation = bw6633verifier.Prepared
+
+// Precompute computes and caches on the key the values derived from it at verification time:
+// e(α, β), -[γ]2 and -[δ]2. Verify calls it when the cache is empty; calling it beforehand saves
+// the pairing to the first verification.
+//
+// Precompute is idempotent and safe for concurrent use. Setup and ReadPreparedFrom fill the cache;
+// ReadFrom doesn't.
+func (vk *VerifyingKey) Precompute() error {
+	_, err := vk.precompute()
+	return err
+}
+
+// precompute returns the cached precomputation, computing it if needed. Concurrent callers may
+// compute it more than once, the result is the same.
+func (vk *VerifyingKey) precompute() (*vkPrecomputation, error) {
+	if pre, _ := vk.precomputed.Load().(*vkPrecomputation); pre != nil {
+		return pre, nil
+	}
+	pre, err := bw6633verifier.Prepare(vk.G1.Alpha, vk.G2.Beta, vk.G2.Gamma, vk.G2.Delta)
+	if err != nil {
+		return nil, err
+	}
+	vk.precomputed.Store(&pre)
+	return &pre, nil
+}
+
+// resetPrecomputation empties the cache, when the points of the key are modified
+func (vk *VerifyingKey) resetPrecomputation() {
+	if vk.precomputed.Load() != nil {
+		vk.precomputed.Store((*vkPrecomputation)(nil))
+	}
+}
+
+// FixPublicInputs returns a copy of vk where the public inputs at the given indexes (in the public witness,
+// without the ONE_WIRE) are fixed to the given values: their share of Σx.[Kvk(t)]1 is folded into [Kvk(0)]1,
+// the constant term, such that the returned VerifyingKey expects the remaining public inputs only, in the same order.
+func (vk *VerifyingKey) FixPublicInputs(fixed map[int]*big.Int) (*VerifyingKey, error) {
+	nbPublic := len(vk.G1.K) - 1
+	for i := range fixed {
+		if i < 0 || i >= nbPublic {
+			return nil, fmt.Errorf("invalid public input index %d, expected in [0, %d)", i, nbPublic)
+		}
+	}
+
+	// the points of r but Kvk are the ones of vk, and so is its precomputation
+	var r VerifyingKey
+	r.G1, r.G2, r.Metadata = vk.G1, vk.G2, vk.Metadata
+	if pre, _ := vk.precomputed.Load().(*vkPrecomputation); pre != nil {
+		r.precomputed.Store(pre)
+	}
+	r.G1.K = make([]curve.G1Affine, 1, len(vk.G1.K)-len(fixed))
+
+	var k0, p curve.G1Jac
+	k0.FromAffine(&vk.G1.K[0])
+	for i := 0; i < nbPublic; i++ {
+		v, ok := fixed[i]
+		if !ok {
+			r.G1.K = append(r.G1.K, vk.G1.K[i+1])
+			continue
+		}
+		p.FromAffine(&vk.G1.K[i+1])
+		p.ScalarMultiplication(&p, v)
+		k0.AddAssign(&p)
+	}
+	r.G1.K[0].FromJacobian(&k0)
+
+	return &r, nil
+}
+
+// WriteCompactTo writes the VerifyingKey in the compact format consumed by NewVerifier:
+// the values needed at verification time are precomputed and points are not compressed,
+// such that decoding is cheap.
+//
+// format: uint16(curveID) | e(α, β) | -[γ]2 | -[δ]2 | uint32(len(Kvk)) | [Kvk]1
+func (vk *VerifyingKey) WriteCompactTo(w io.Writer) (int64, error) {
+	var buf [2]byte
+	binary.BigEndian.PutUint16(buf[:], uint16(curve.ID))
+	pre, err := vk.precompute()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(buf[:])
+	if err != nil {
+		return int64(n), err
+	}
+	e := pre.E.Bytes()
+	m, err := w.Write(e[:])
+	n += m
+	if err != nil {
+		return int64(n), err
+	}
+
+	enc := curve.NewEncoder(w, curve.RawEncoding())
+	toEncode := []interface{}{
+		&pre.GammaNeg,
+		&pre.DeltaNeg,
+		vk.G1.K,
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return int64(n) + enc.BytesWritten(), err
+		}
+	}
+	return int64(n) + enc.BytesWritten(), nil
+}
+
+// Verifier is a lightweight Groth16 verifier, built from a compact VerifyingKey encoding
+// (see VerifyingKey.WriteCompactTo)
+type Verifier struct {
+	vkPrecomputation
+	k []curve.G1Affine
+}
+
+// NewVerifier parses a VerifyingKey written with VerifyingKey.WriteCompactTo
+func NewVerifier(vkBytes []byte) (*Verifier, error) {
+	const headerSize = 2 + curve.SizeOfGT
+	if len(vkBytes) < headerSize {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if binary.BigEndian.Uint16(vkBytes[:2]) != uint16(curve.ID) {
+		return nil, errors.New("invalid verifying key: curve mismatch")
+	}
+
+	var v Verifier
+	if err := v.E.SetBytes(vkBytes[2:headerSize]); err != nil {
+		return nil, err
+	}
+
+	dec := curve.NewDecoder(bytes.NewReader(vkBytes[headerSize:]))
+	toDecode := []interface{}{
+		&v.GammaNeg,
+		&v.DeltaNeg,
+		&v.k,
+	}
+	for _, e := range toDecode {
+		if err := dec.Decode(e); err != nil {
+			return nil, err
+		}
+	}
+	if len(v.k) == 0 {
+		return nil, errors.New("invalid verifying key: empty Kvk")
+	}
+
+	return &v, nil
+}
+
+// Verify verifies a proof (encoded with Proof.WriteTo or Proof.WriteRawTo) for the given public inputs,
+// in the order of the public witness (without the ONE_WIRE)
+func (v *Verifier) Verify(proofBytes []byte, publicInputs []*big.Int) error {
+	var proof Proof
+	if _, err := proof.ReadFrom(bytes.NewReader(proofBytes)); err != nil {
+		return err
+	}
+
+	publicWitness := make([]fr.Element, len(publicInputs))
+	for i := 0; i < len(publicInputs); i++ {
+		publicWitness[i].SetBigInt(publicInputs[i])
+	}
+
+	return v.vkPrecomputation.Verify((*bw6633verifier.Proof)(&proof), v.k, publicWitness)
+}
+
+// ExportSolidity not implemented for BW6-633
+func (vk *VerifyingKey) ExportSolidity(w io.Writer, opts ...func(opt *backend.SetupOption) error) error {
+	return errors.New("not implemented")
+}
@@ -0,0 +1,657 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonk
+
+import (
+	curve "github.com/consensys/gnark-crypto/ecc/bw6-633"
+
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
+
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr/kzg"
+
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr/fft"
+	"github.com/consensys/gnark/internal/backend/common"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	gnarkio "github.com/consensys/gnark/io"
+	"io"
+)
+
+// versions of the binary encoding of Proof
+const (
+	proofVersion       uint8 = 0 // no lookups
+	proofVersionLookup uint8 = 1 // with lookups, see LookupProof
+)
+
+var errUnknownProofVersion = errors.New("unknown proof version")
+
+// lookupMarker precedes the lookup section of the keys, only written for the circuits with lookups, such
+// that the keys of the other circuits are encoded as before the lookups
+var lookupMarker = []byte("gnarklk\x01")
+
+// WriteTo writes binary encoding of Proof to w
+func (proof *Proof) WriteTo(w io.Writer) (int64, error) {
+	enc := curve.NewEncoder(w)
+
+	version := proofVersion
+	if proof.Lookup != nil {
+		version = proofVersionLookup
+	}
+
+	toEncode := []interface{}{
+		version,
+		&proof.LRO[0],
+		&proof.LRO[1],
+		&proof.LRO[2],
+		&proof.Z,
+		&proof.H[0],
+		&proof.H[1],
+		&proof.H[2],
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	n, err := proof.BatchedProof.WriteTo(w)
+	if err != nil {
+		return n + enc.BytesWritten(), err
+	}
+	n2, err := proof.ZShiftedOpening.WriteTo(w)
+	n += n2
+	if err != nil || proof.Lookup == nil {
+		return n + enc.BytesWritten(), err
+	}
+
+	n2, err = proof.Lookup.WriteTo(w)
+	return n + n2 + enc.BytesWritten(), err
+}
+
+// ReadFrom reads binary representation of Proof from r
+func (proof *Proof) ReadFrom(r io.Reader) (int64, error) {
+	lr := gnarkio.NewLimitReader(r)
+	dec := curve.NewDecoder(lr)
+
+	var version uint8
+	if err := dec.Decode(&version); err != nil {
+		return dec.BytesRead(), err
+	}
+	if version != proofVersion && version != proofVersionLookup {
+		return dec.BytesRead(), errUnknownProofVersion
+	}
+
+	toDecode := []interface{}{
+		&proof.LRO[0],
+		&proof.LRO[1],
+		&proof.LRO[2],
+		&proof.Z,
+		&proof.H[0],
+		&proof.H[1],
+		&proof.H[2],
+	}
+
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	if err := readBatchOpeningProof(dec, lr, &proof.BatchedProof); err != nil {
+		return dec.BytesRead(), err
+	}
+	n, err := proof.ZShiftedOpening.ReadFrom(lr)
+	if err != nil || version != proofVersionLookup {
+		proof.Lookup = nil
+		return n + dec.BytesRead(), err
+	}
+
+	proof.Lookup = &LookupProof{}
+	n2, err := proof.Lookup.ReadFrom(lr)
+	return n + n2 + dec.BytesRead(), err
+}
+
+// WriteTo writes binary encoding of LookupProof to w
+func (proof *LookupProof) WriteTo(w io.Writer) (int64, error) {
+	enc := curve.NewEncoder(w)
+
+	toEncode := []interface{}{
+		&proof.F,
+		&proof.H1,
+		&proof.H2,
+		&proof.Z,
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	n, err := proof.BatchedProof.WriteTo(w)
+	if err != nil {
+		return n + enc.BytesWritten(), err
+	}
+	n2, err := proof.ShiftedBatchedProof.WriteTo(w)
+
+	return n + n2 + enc.BytesWritten(), err
+}
+
+// ReadFrom reads binary representation of LookupProof from r
+func (proof *LookupProof) ReadFrom(r io.Reader) (int64, error) {
+	lr := gnarkio.NewLimitReader(r)
+	dec := curve.NewDecoder(lr)
+	toDecode := []interface{}{
+		&proof.F,
+		&proof.H1,
+		&proof.H2,
+		&proof.Z,
+	}
+
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	if err := readBatchOpeningProof(dec, lr, &proof.BatchedProof); err != nil {
+		return dec.BytesRead(), err
+	}
+	err := readBatchOpeningProof(dec, lr, &proof.ShiftedBatchedProof)
+	return dec.BytesRead(), err
+}
+
+// readBatchOpeningProof reads proof as kzg.BatchOpeningProof.ReadFrom does, checking the number of claimed
+// values before they are allocated
+func readBatchOpeningProof(dec *curve.Decoder, lr *gnarkio.LimitReader, proof *kzg.BatchOpeningProof) error {
+	return decodeLimited(dec, lr, &proof.H, &proof.Point, &proof.ClaimedValues)
+}
+
+// decodeLimited decodes the values with dec, which reads from lr: the length prefixes of the slices of
+// field elements are checked before the slices are allocated, see gnarkio.LimitReader
+func decodeLimited(dec *curve.Decoder, lr *gnarkio.LimitReader, toDecode ...interface{}) error {
+	for _, v := range toDecode {
+		if _, ok := v.(*[]fr.Element); ok {
+			if err := lr.CheckSlice("field elements", fr.Bytes, fr.Bytes); err != nil {
+				return err
+			}
+		}
+		if err := dec.Decode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDomain reads d from lr, once the size of its precomputed tables (twiddles and coset tables, about
+// 6 field elements per element of the domain) is checked against the read limit
+func readDomain(lr *gnarkio.LimitReader, d *fft.Domain) (int64, error) {
+	if b, err := lr.Peek(8); err == nil {
+		if err := lr.Check("domain", binary.BigEndian.Uint64(b), 0, 6*fr.Bytes); err != nil {
+			return 0, err
+		}
+	}
+	return d.ReadFrom(lr)
+}
+
+// proofCBOR is the CBOR encoding of Proof, see MarshalCBOR
+type proofCBOR struct {
+	common.CBORHeader
+	LRO             [][]byte              `cbor:"LRO"`
+	Z               []byte                `cbor:"Z"`
+	H               [][]byte              `cbor:"H"`
+	BatchedProof    batchOpeningProofCBOR `cbor:"BatchedProof"`
+	ZShiftedOpening openingProofCBOR      `cbor:"ZShiftedOpening"`
+	Lookup          *lookupProofCBOR      `cbor:"Lookup,omitempty"`
+}
+
+// lookupProofCBOR is the CBOR encoding of LookupProof
+type lookupProofCBOR struct {
+	F                   []byte                `cbor:"F"`
+	H1                  []byte                `cbor:"H1"`
+	H2                  []byte                `cbor:"H2"`
+	Z                   []byte                `cbor:"Z"`
+	BatchedProof        batchOpeningProofCBOR `cbor:"BatchedProof"`
+	ShiftedBatchedProof batchOpeningProofCBOR `cbor:"ShiftedBatchedProof"`
+}
+
+// openingProofCBOR is the CBOR encoding of a kzg.OpeningProof
+type openingProofCBOR struct {
+	H            []byte `cbor:"H"`
+	Point        []byte `cbor:"Point"`
+	ClaimedValue []byte `cbor:"ClaimedValue"`
+}
+
+// batchOpeningProofCBOR is the CBOR encoding of a kzg.BatchOpeningProof
+type batchOpeningProofCBOR struct {
+	H             []byte   `cbor:"H"`
+	Point         []byte   `cbor:"Point"`
+	ClaimedValues [][]byte `cbor:"ClaimedValues"`
+}
+
+// MarshalCBOR returns the canonical CBOR encoding of the proof: a map holding the header (see
+// common.CBORHeader) and the components of the proof under their names ("LRO", "Z", "H", ...,
+// "Lookup" if the circuit has lookups). The points are compressed, the field elements are big endian.
+func (proof *Proof) MarshalCBOR() ([]byte, error) {
+	p := proofCBOR{
+		CBORHeader:   common.NewCBORHeader(common.CBORPlonkProof, curve.ID),
+		LRO:          [][]byte{g1Bytes(&proof.LRO[0]), g1Bytes(&proof.LRO[1]), g1Bytes(&proof.LRO[2])},
+		Z:            g1Bytes(&proof.Z),
+		H:            [][]byte{g1Bytes(&proof.H[0]), g1Bytes(&proof.H[1]), g1Bytes(&proof.H[2])},
+		BatchedProof: batchOpeningProofToCBOR(&proof.BatchedProof),
+		ZShiftedOpening: openingProofCBOR{
+			H:            g1Bytes(&proof.ZShiftedOpening.H),
+			Point:        frBytes(&proof.ZShiftedOpening.Point),
+			ClaimedValue: frBytes(&proof.ZShiftedOpening.ClaimedValue),
+		},
+	}
+	if proof.Lookup != nil {
+		p.Lookup = &lookupProofCBOR{
+			F:                   g1Bytes(&proof.Lookup.F),
+			H1:                  g1Bytes(&proof.Lookup.H1),
+			H2:                  g1Bytes(&proof.Lookup.H2),
+			Z:                   g1Bytes(&proof.Lookup.Z),
+			BatchedProof:        batchOpeningProofToCBOR(&proof.Lookup.BatchedProof),
+			ShiftedBatchedProof: batchOpeningProofToCBOR(&proof.Lookup.ShiftedBatchedProof),
+		}
+	}
+	return common.CBOREncMode.Marshal(p)
+}
+
+// UnmarshalCBOR decodes a proof encoded with MarshalCBOR. It fails with backend.ErrCBORHeaderMismatch
+// if data is not a PlonK proof over this curve, and if the points are not in the correct subgroup
+// or the field elements are not reduced.
+func (proof *Proof) UnmarshalCBOR(data []byte) error {
+	var p proofCBOR
+	if err := common.CBORDecMode.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if err := p.Check(common.CBORPlonkProof, curve.ID); err != nil {
+		return err
+	}
+	if len(p.LRO) != 3 || len(p.H) != 3 {
+		return errors.New("LRO and H must have 3 commitments")
+	}
+	for i := 0; i < 3; i++ {
+		if err := setG1(&proof.LRO[i], p.LRO[i], "LRO"); err != nil {
+			return err
+		}
+		if err := setG1(&proof.H[i], p.H[i], "H"); err != nil {
+			return err
+		}
+	}
+	if err := setG1(&proof.Z, p.Z, "Z"); err != nil {
+		return err
+	}
+	if err := batchOpeningProofFromCBOR(&proof.BatchedProof, &p.BatchedProof, "BatchedProof"); err != nil {
+		return err
+	}
+	if err := setG1(&proof.ZShiftedOpening.H, p.ZShiftedOpening.H, "ZShiftedOpening.H"); err != nil {
+		return err
+	}
+	if err := setFr(&proof.ZShiftedOpening.Point, p.ZShiftedOpening.Point, "ZShiftedOpening.Point"); err != nil {
+		return err
+	}
+	if err := setFr(&proof.ZShiftedOpening.ClaimedValue, p.ZShiftedOpening.ClaimedValue, "ZShiftedOpening.ClaimedValue"); err != nil {
+		return err
+	}
+
+	proof.Lookup = nil
+	if p.Lookup == nil {
+		return nil
+	}
+	lookup := &LookupProof{}
+	for _, c := range []struct {
+		p    *curve.G1Affine
+		b    []byte
+		name string
+	}{
+		{&lookup.F, p.Lookup.F, "Lookup.F"},
+		{&lookup.H1, p.Lookup.H1, "Lookup.H1"},
+		{&lookup.H2, p.Lookup.H2, "Lookup.H2"},
+		{&lookup.Z, p.Lookup.Z, "Lookup.Z"},
+	} {
+		if err := setG1(c.p, c.b, c.name); err != nil {
+			return err
+		}
+	}
+	if err := batchOpeningProofFromCBOR(&lookup.BatchedProof, &p.Lookup.BatchedProof, "Lookup.BatchedProof"); err != nil {
+		return err
+	}
+	if err := batchOpeningProofFromCBOR(&lookup.ShiftedBatchedProof, &p.Lookup.ShiftedBatchedProof, "Lookup.ShiftedBatchedProof"); err != nil {
+		return err
+	}
+	proof.Lookup = lookup
+	return nil
+}
+
+func batchOpeningProofToCBOR(proof *kzg.BatchOpeningProof) batchOpeningProofCBOR {
+	res := batchOpeningProofCBOR{
+		H:             g1Bytes(&proof.H),
+		Point:         frBytes(&proof.Point),
+		ClaimedValues: make([][]byte, len(proof.ClaimedValues)),
+	}
+	for i := range proof.ClaimedValues {
+		res.ClaimedValues[i] = frBytes(&proof.ClaimedValues[i])
+	}
+	return res
+}
+
+func batchOpeningProofFromCBOR(proof *kzg.BatchOpeningProof, p *batchOpeningProofCBOR, name string) error {
+	if err := setG1(&proof.H, p.H, name+".H"); err != nil {
+		return err
+	}
+	if err := setFr(&proof.Point, p.Point, name+".Point"); err != nil {
+		return err
+	}
+	proof.ClaimedValues = make([]fr.Element, len(p.ClaimedValues))
+	for i := range p.ClaimedValues {
+		if err := setFr(&proof.ClaimedValues[i], p.ClaimedValues[i], name+".ClaimedValues"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// g1Bytes returns the compressed encoding of p
+func g1Bytes(p *curve.G1Affine) []byte {
+	b := p.Bytes()
+	return b[:]
+}
+
+// frBytes returns the big endian encoding of e
+func frBytes(e *fr.Element) []byte {
+	b := e.Bytes()
+	return b[:]
+}
+
+// setG1 sets p from its compressed encoding b
+func setG1(p *curve.G1Affine, b []byte, name string) error {
+	if len(b) != curve.SizeOfG1AffineCompressed {
+		return fmt.Errorf("%s: invalid compressed point size %d, expected %d", name, len(b), curve.SizeOfG1AffineCompressed)
+	}
+	if _, err := p.SetBytes(b); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// setFr sets e from its big endian encoding b, which must be reduced modulo r
+func setFr(e *fr.Element, b []byte, name string) error {
+	if len(b) != fr.Bytes {
+		return fmt.Errorf("%s: invalid field element size %d, expected %d", name, len(b), fr.Bytes)
+	}
+	e.SetBytes(b)
+	if c := e.Bytes(); !bytes.Equal(c[:], b) {
+		return fmt.Errorf("%s: field element is not reduced", name)
+	}
+	return nil
+}
+
+// WriteTo writes binary encoding of ProvingKey to w
+func (pk *ProvingKey) WriteTo(w io.Writer) (n int64, err error) {
+	// encode the verifying key
+	n, err = pk.Vk.WriteTo(w)
+	if err != nil {
+		return
+	}
+
+	// fft domains
+	n2, err := pk.DomainNum.WriteTo(w)
+	if err != nil {
+		return
+	}
+	n += n2
+
+	n2, err = pk.DomainH.WriteTo(w)
+	if err != nil {
+		return
+	}
+	n += n2
+
+	// sanity check len(Permutation) == 3*int(pk.DomainNum.Cardinality)
+	if len(pk.Permutation) != (3 * int(pk.DomainNum.Cardinality)) {
+		return n, errors.New("invalid permutation size, expected 3*domain cardinality")
+	}
+
+	enc := curve.NewEncoder(w)
+	// note: type Polynomial, which is handled by default binary.Write(...) op and doesn't
+	// encode the size (nor does it convert from Montgomery to Regular form)
+	// so we explicitly transmit []fr.Element
+	toEncode := []interface{}{
+		([]fr.Element)(pk.Ql),
+		([]fr.Element)(pk.Qr),
+		([]fr.Element)(pk.Qm),
+		([]fr.Element)(pk.Qo),
+		([]fr.Element)(pk.CQk),
+		([]fr.Element)(pk.LQk),
+		([]fr.Element)(pk.LS1),
+		([]fr.Element)(pk.LS2),
+		([]fr.Element)(pk.LS3),
+		([]fr.Element)(pk.CS1),
+		([]fr.Element)(pk.CS2),
+		([]fr.Element)(pk.CS3),
+		pk.Permutation,
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return n + enc.BytesWritten(), err
+		}
+	}
+	if pk.Lookup == nil {
+		return n + enc.BytesWritten(), nil
+	}
+
+	// lookup section
+	m, err := w.Write(lookupMarker)
+	n += int64(m)
+	if err != nil {
+		return n + enc.BytesWritten(), err
+	}
+	toEncode = []interface{}{
+		([]fr.Element)(pk.Lookup.Qlk),
+		([]fr.Element)(pk.Lookup.Qtab),
+		([]fr.Element)(pk.Lookup.LTValue),
+		([]fr.Element)(pk.Lookup.LTTag),
+		([]fr.Element)(pk.Lookup.CTValue),
+		([]fr.Element)(pk.Lookup.CTTag),
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return n + enc.BytesWritten(), err
+		}
+	}
+
+	return n + enc.BytesWritten(), nil
+}
+
+// ReadFrom reads from binary representation in r into ProvingKey
+func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
+	lr := gnarkio.NewLimitReader(r)
+	pk.Vk = &VerifyingKey{}
+	n, err := pk.Vk.ReadFrom(lr)
+	if err != nil {
+		return n, err
+	}
+
+	n2, err := readDomain(lr, &pk.DomainNum)
+	n += n2
+	if err != nil {
+		return n, err
+	}
+
+	n2, err = readDomain(lr, &pk.DomainH)
+	n += n2
+	if err != nil {
+		return n, err
+	}
+
+	if err := lr.Check("permutation", 3*pk.DomainNum.Cardinality, 8, 8); err != nil {
+		return n, err
+	}
+	pk.Permutation = make([]int64, 3*pk.DomainNum.Cardinality)
+
+	dec := curve.NewDecoder(lr)
+	toDecode := []interface{}{
+		(*[]fr.Element)(&pk.Ql),
+		(*[]fr.Element)(&pk.Qr),
+		(*[]fr.Element)(&pk.Qm),
+		(*[]fr.Element)(&pk.Qo),
+		(*[]fr.Element)(&pk.CQk),
+		(*[]fr.Element)(&pk.LQk),
+		(*[]fr.Element)(&pk.LS1),
+		(*[]fr.Element)(&pk.LS2),
+		(*[]fr.Element)(&pk.LS3),
+		(*[]fr.Element)(&pk.CS1),
+		(*[]fr.Element)(&pk.CS2),
+		(*[]fr.Element)(&pk.CS3),
+		&pk.Permutation,
+	}
+
+	if err := decodeLimited(dec, lr, toDecode...); err != nil {
+		return n + dec.BytesRead(), err
+	}
+
+	pk.Lookup = nil
+	if !lr.ReadMarker(lookupMarker) {
+		return n + dec.BytesRead(), nil
+	}
+	n += int64(len(lookupMarker))
+
+	pk.Lookup = &LookupProvingKey{}
+	toDecode = []interface{}{
+		(*[]fr.Element)(&pk.Lookup.Qlk),
+		(*[]fr.Element)(&pk.Lookup.Qtab),
+		(*[]fr.Element)(&pk.Lookup.LTValue),
+		(*[]fr.Element)(&pk.Lookup.LTTag),
+		(*[]fr.Element)(&pk.Lookup.CTValue),
+		(*[]fr.Element)(&pk.Lookup.CTTag),
+	}
+
+	if err := decodeLimited(dec, lr, toDecode...); err != nil {
+		return n + dec.BytesRead(), err
+	}
+
+	return n + dec.BytesRead(), nil
+
+}
+
+// WriteTo writes binary encoding of VerifyingKey to w
+func (vk *VerifyingKey) WriteTo(w io.Writer) (n int64, err error) {
+	enc := curve.NewEncoder(w)
+
+	toEncode := []interface{}{
+		vk.Size,
+		&vk.SizeInv,
+		&vk.Generator,
+		vk.NbPublicVariables,
+		&vk.Shifter[0],
+		&vk.Shifter[1],
+		&vk.S[0],
+		&vk.S[1],
+		&vk.S[2],
+		&vk.Ql,
+		&vk.Qr,
+		&vk.Qm,
+		&vk.Qo,
+		&vk.Qk,
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	// lookup section
+	var m int
+	if vk.Lookup != nil {
+		if m, err = w.Write(lookupMarker); err != nil {
+			return enc.BytesWritten() + int64(m), err
+		}
+		toEncode = []interface{}{
+			&vk.Lookup.Qlk,
+			&vk.Lookup.Qtab,
+			&vk.Lookup.TValue,
+			&vk.Lookup.TTag,
+		}
+		for _, v := range toEncode {
+			if err := enc.Encode(v); err != nil {
+				return enc.BytesWritten() + int64(m), err
+			}
+		}
+	}
+
+	n, err = compiled.WriteMetadataSection(w, vk.Metadata)
+	return enc.BytesWritten() + int64(m) + n, err
+}
+
+// ReadFrom reads from binary representation in r into VerifyingKey
+func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
+	lr := gnarkio.NewLimitReader(r)
+	dec := curve.NewDecoder(lr)
+	toDecode := []interface{}{
+		&vk.Size,
+		&vk.SizeInv,
+		&vk.Generator,
+		&vk.NbPublicVariables,
+		&vk.Shifter[0],
+		&vk.Shifter[1],
+		&vk.S[0],
+		&vk.S[1],
+		&vk.S[2],
+		&vk.Ql,
+		&vk.Qr,
+		&vk.Qm,
+		&vk.Qo,
+		&vk.Qk,
+	}
+
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	vk.Lookup = nil
+	var m int64
+	if lr.ReadMarker(lookupMarker) {
+		m = int64(len(lookupMarker))
+		vk.Lookup = &LookupVerifyingKey{}
+		toDecode = []interface{}{
+			&vk.Lookup.Qlk,
+			&vk.Lookup.Qtab,
+			&vk.Lookup.TValue,
+			&vk.Lookup.TTag,
+		}
+
+		for _, v := range toDecode {
+			if err := dec.Decode(v); err != nil {
+				return dec.BytesRead() + m, err
+			}
+		}
+	}
+
+	metadata, n, err := compiled.ReadMetadataSection(lr)
+	vk.Metadata = metadata
+	return dec.BytesRead() + m + n, err
+}
@@ -0,0 +1,217 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonk
+
+import (
+	curve "github.com/consensys/gnark-crypto/ecc/bw6-633"
+
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
+
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr/fft"
+
+	"bytes"
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr/kzg"
+	"math/big"
+
+	"github.com/consensys/gnark/internal/backend/common"
+	"reflect"
+	"testing"
+)
+
+func TestProvingKeySerialization(t *testing.T) {
+	// create a random vk
+	var vk VerifyingKey
+	vk.Size = 42
+	vk.SizeInv = fr.One()
+	vk.Shifter[1].SetUint64(12)
+
+	_, _, g1gen, _ := curve.Generators()
+	vk.S[0] = g1gen
+	vk.S[1] = g1gen
+	vk.S[2] = g1gen
+	vk.Ql = g1gen
+	vk.Qr = g1gen
+	vk.Qm = g1gen
+	vk.Qo = g1gen
+	vk.Qk = g1gen
+	vk.NbPublicVariables = 8000
+
+	// random pk
+	var pk ProvingKey
+	pk.Vk = &vk
+	pk.DomainNum = *fft.NewDomain(42, 3, false)
+	pk.DomainH = *fft.NewDomain(4*42, 1, false)
+	pk.Ql = make([]fr.Element, pk.DomainNum.Cardinality)
+	pk.Qr = make([]fr.Element, pk.DomainNum.Cardinality)
+	pk.Qm = make([]fr.Element, pk.DomainNum.Cardinality)
+	pk.Qo = make([]fr.Element, pk.DomainNum.Cardinality)
+	pk.CQk = make([]fr.Element, pk.DomainNum.Cardinality)
+	pk.LQk = make([]fr.Element, pk.DomainNum.Cardinality)
+
+	for i := 0; i < 12; i++ {
+		pk.Ql[i].SetOne().Neg(&pk.Ql[i])
+		pk.Qr[i].SetOne()
+		pk.Qo[i].SetUint64(42)
+	}
+
+	pk.Permutation = make([]int64, 3*pk.DomainNum.Cardinality)
+	pk.Permutation[0] = -12
+	pk.Permutation[len(pk.Permutation)-1] = 8888
+
+	var buf bytes.Buffer
+	written, err := pk.WriteTo(&buf)
+	if err != nil {
+		t.Fatal("coudln't serialize", err)
+	}
+
+	var reconstructed ProvingKey
+
+	read, err := reconstructed.ReadFrom(&buf)
+	if err != nil {
+		t.Fatal("coudln't deserialize", err)
+	}
+
+	if !reflect.DeepEqual(&pk, &reconstructed) {
+		t.Fatal("reconstructed object don't match original")
+	}
+
+	if written != read {
+		t.Fatal("bytes written / read don't match")
+	}
+}
+
+func TestProofCBOR(t *testing.T) {
+	_, _, g1gen, _ := curve.Generators()
+	var g1, g2 curve.G1Affine
+	g1.ScalarMultiplication(&g1gen, big.NewInt(2))
+	g2.ScalarMultiplication(&g1gen, big.NewInt(3))
+
+	var proof Proof
+	proof.LRO = [3]kzg.Digest{g1gen, g1, g2}
+	proof.Z = g1
+	proof.H = [3]kzg.Digest{g2, g1, g1gen}
+	proof.BatchedProof.H = g2
+	proof.BatchedProof.Point.SetUint64(7)
+	proof.BatchedProof.ClaimedValues = make([]fr.Element, 6)
+	for i := range proof.BatchedProof.ClaimedValues {
+		proof.BatchedProof.ClaimedValues[i].SetUint64(uint64(i))
+		proof.BatchedProof.ClaimedValues[i].Neg(&proof.BatchedProof.ClaimedValues[i])
+	}
+	proof.ZShiftedOpening.H = g1gen
+	proof.ZShiftedOpening.Point.SetUint64(42)
+	proof.ZShiftedOpening.ClaimedValue.SetOne()
+
+	roundTrip := func() {
+		data, err := proof.MarshalCBOR()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded Proof
+		if err := decoded.UnmarshalCBOR(data); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(&proof, &decoded) {
+			t.Fatal("reconstructed proof doesn't match the original")
+		}
+	}
+	roundTrip()
+
+	proof.Lookup = &LookupProof{F: g1, H1: g2, H2: g1gen, Z: g1}
+	proof.Lookup.BatchedProof = proof.BatchedProof
+	proof.Lookup.ShiftedBatchedProof.H = g1
+	proof.Lookup.ShiftedBatchedProof.ClaimedValues = make([]fr.Element, 4)
+	roundTrip()
+
+	// a field element which is not reduced is rejected
+	p := proofCBOR{
+		CBORHeader:   common.NewCBORHeader(common.CBORPlonkProof, curve.ID),
+		LRO:          [][]byte{g1Bytes(&g1), g1Bytes(&g1), g1Bytes(&g1)},
+		Z:            g1Bytes(&g1),
+		H:            [][]byte{g1Bytes(&g1), g1Bytes(&g1), g1Bytes(&g1)},
+		BatchedProof: batchOpeningProofToCBOR(&proof.BatchedProof),
+		ZShiftedOpening: openingProofCBOR{
+			H:            g1Bytes(&g1),
+			Point:        bytes.Repeat([]byte{0xff}, fr.Bytes),
+			ClaimedValue: frBytes(&proof.ZShiftedOpening.ClaimedValue),
+		},
+	}
+	data, err := common.CBOREncMode.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := new(Proof).UnmarshalCBOR(data); err == nil {
+		t.Fatal("expected an error with a field element which is not reduced")
+	}
+}
+
+func TestVerifyingKeySerialization(t *testing.T) {
+	// create a random vk
+	var vk VerifyingKey
+	vk.Size = 42
+	vk.SizeInv = fr.One()
+	vk.Shifter[1].SetUint64(12)
+
+	_, _, g1gen, _ := curve.Generators()
+	vk.S[0] = g1gen
+	vk.S[1] = g1gen
+	vk.S[2] = g1gen
+	vk.Ql = g1gen
+	vk.Qr = g1gen
+	vk.Qm = g1gen
+	vk.Qo = g1gen
+	vk.Qk = g1gen
+
+	vk.Lookup = &LookupVerifyingKey{Qlk: g1gen, Qtab: g1gen, TValue: g1gen, TTag: g1gen}
+
+	var buf bytes.Buffer
+	written, err := vk.WriteTo(&buf)
+	if err != nil {
+		t.Fatal("coudln't serialize", err)
+	}
+
+	var reconstructed VerifyingKey
+
+	read, err := reconstructed.ReadFrom(&buf)
+	if err != nil {
+		t.Fatal("coudln't deserialize", err)
+	}
+
+	if !reflect.DeepEqual(&vk, &reconstructed) {
+		t.Fatal("reconstructed object don't match original")
+	}
+
+	if written != read {
+		t.Fatal("bytes written / read don't match")
+	}
+
+	// without lookups, the key has no lookup section
+	vk.Lookup = nil
+	buf.Reset()
+	if _, err := vk.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), lookupMarker) {
+		t.Fatal("unexpected lookup section")
+	}
+	reconstructed = VerifyingKey{}
+	if _, err := reconstructed.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&vk, &reconstructed) {
+		t.Fatal("reconstructed object don't match original")
+	}
+}
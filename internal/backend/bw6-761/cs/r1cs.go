@@ -22,12 +22,16 @@ import (
 	"io"
 	"math/big"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/fxamacker/cbor/v2"
 
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/internal/backend/common"
 	"github.com/consensys/gnark/internal/backend/compiled"
 	"github.com/consensys/gnark/internal/backend/ioutils"
+	gnarkio "github.com/consensys/gnark/io"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"text/template"
@@ -39,6 +43,9 @@ import (
 type R1CS struct {
 	compiled.R1CS
 	Coefficients []fr.Element // R1C coefficients indexes point here
+
+	incremental atomic.Value // *incrementalSolver, set by the first solve with backend.WithSolutionCache
+	ccsDigest   atomic.Value // []byte, set by the first solve with backend.WithCheckpoint
 }
 
 // NewR1CS returns a new R1CS and sets cs.Coefficient (fr.Element) from provided big.Int values
@@ -60,32 +67,202 @@ func NewR1CS(cs compiled.R1CS, coefficients []big.Int) *R1CS {
 // witness = [publicWires | secretWires] (without the ONE_WIRE !)
 // returns  [publicWires | secretWires | internalWires ]
 func (cs *R1CS) Solve(witness, a, b, c []fr.Element, opt backend.ProverOption) ([]fr.Element, error) {
+	solution, err := cs.solve(nil, witness, a, b, c, opt)
+	return solution.wireValues(), err
+}
+
+// SolveMany solves the R1CS for each witness with the precomputed schedule, see compiled.R1CS.NewSolveSchedule.
+// It sets a[i], b[i], c[i] and returns the wire values of witnesses[i], as Solve would.
+// If nbTasks > 1, up to nbTasks witnesses are solved concurrently, each in its own solution: the hint
+// functions must then be safe for concurrent use.
+// It returns the first error encountered, wrapped with the index of the witness.
+func (cs *R1CS) SolveMany(schedule *compiled.SolveSchedule, witnesses, a, b, c [][]fr.Element, opt backend.ProverOption, nbTasks int) ([][]fr.Element, error) {
+	if len(schedule.Constraints) != cs.Constraints.Len() {
+		return nil, fmt.Errorf("invalid schedule: got %d constraints, expected %d", len(schedule.Constraints), cs.Constraints.Len())
+	}
+	if len(a) != len(witnesses) || len(b) != len(witnesses) || len(c) != len(witnesses) {
+		return nil, errors.New("invalid input size: len(a, b, c) == len(witnesses)")
+	}
+	if nbTasks < 1 {
+		nbTasks = 1
+	}
+	if nbTasks > len(witnesses) {
+		nbTasks = len(witnesses)
+	}
+
+	values := make([][]fr.Element, len(witnesses))
+	errs := make([]error, len(witnesses))
+	chNext := make(chan int, len(witnesses))
+	for i := range witnesses {
+		chNext <- i
+	}
+	close(chNext)
+
+	var wg sync.WaitGroup
+	wg.Add(nbTasks)
+	for t := 0; t < nbTasks; t++ {
+		go func() {
+			defer wg.Done()
+			for i := range chNext {
+				solution, err := cs.solve(schedule, witnesses[i], a[i], b[i], c[i], opt)
+				values[i], errs[i] = solution.wireValues(), err
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return values, fmt.Errorf("witness %d: %w", i, err)
+		}
+	}
+	return values, nil
+}
+
+// PackedWires holds the wire values computed by SolvePacked
+type PackedWires struct {
+	Layout *compiled.BooleanLayout
+	Values []fr.Element    // values of the non boolean wires, in wire order
+	Bits   compiled.BitSet // values of the boolean wires, in wire order
+}
+
+// Get returns the value of the wire
+func (w *PackedWires) Get(wire int) fr.Element {
+	var res fr.Element
+	if w.Layout.IsBoolean(wire) {
+		if w.Bits.Get(w.Layout.Index(wire)) {
+			res.SetOne()
+		}
+		return res
+	}
+	return w.Values[w.Layout.Index(wire)]
+}
+
+// SolvePacked is like Solve, with backend.WithPackedBooleans: the values of the wires constrained to
+// be boolean are returned packed in a bitset, the values of the other wires in a vector of field elements.
+func (cs *R1CS) SolvePacked(witness, a, b, c []fr.Element, opt backend.ProverOption) (PackedWires, error) {
+	opt.PackBooleans = true
+	solution, err := cs.solve(nil, witness, a, b, c, opt)
+	return PackedWires{Layout: solution.Layout, Values: solution.values, Bits: solution.Bits}, err
+}
+
+// solve sets all the wires and the a, b, c vectors, see Solve. The boolean wires are packed
+// if opt.PackBooleans is set. If schedule is not nil, the constraints are solved following it
+// instead of searching for the wire to solve in each constraint.
+func (cs *R1CS) solve(schedule *compiled.SolveSchedule, witness, a, b, c []fr.Element, opt backend.ProverOption) (*solution, error) {
 
 	nbWires := cs.NbPublicVariables + cs.NbSecretVariables + cs.NbInternalVariables
-	solution, err := newSolution(nbWires, opt.HintFunctions, cs.Coefficients)
+	var layout *compiled.BooleanLayout
+	if opt.PackBooleans {
+		booleans := cs.Booleans
+		if booleans == nil {
+			// R1CS compiled before the boolean wires were recorded
+			booleans = compiled.NewBitSet(nbWires)
+		}
+		layout = compiled.NewBooleanLayout(booleans, nbWires)
+	}
+	solution, err := newSolution(nbWires, layout, cs.ReferencedHints(), opt, cs.Coefficients, cs.MHints)
 	if err != nil {
-		return make([]fr.Element, nbWires), err
+		solution, _ = newSolution(nbWires, layout, nil, backend.ProverOption{}, cs.Coefficients, cs.MHints)
+		return &solution, err
 	}
 
 	if len(witness) != int(cs.NbPublicVariables-1+cs.NbSecretVariables) { // - 1 for ONE_WIRE
-		return solution.values, fmt.Errorf("invalid witness size, got %d, expected %d = %d (public - ONE_WIRE) + %d (secret)", len(witness), int(cs.NbPublicVariables-1+cs.NbSecretVariables), cs.NbPublicVariables-1, cs.NbSecretVariables)
+		return &solution, fmt.Errorf("invalid witness size, got %d, expected %d = %d (public - ONE_WIRE) + %d (secret)", len(witness), int(cs.NbPublicVariables-1+cs.NbSecretVariables), cs.NbPublicVariables-1, cs.NbSecretVariables)
 	}
 
 	// compute the wires and the a, b, c polynomials
-	if len(a) != len(cs.Constraints) || len(b) != len(cs.Constraints) || len(c) != len(cs.Constraints) {
-		return solution.values, errors.New("invalid input size: len(a, b, c) == len(Constraints)")
+	nbConstraints := cs.Constraints.Len()
+	if len(a) != nbConstraints || len(b) != nbConstraints || len(c) != nbConstraints {
+		return &solution, errors.New("invalid input size: len(a, b, c) == len(Constraints)")
 	}
 
-	solution.solved[0] = true // ONE_WIRE
-	solution.values[0].SetOne()
-	copy(solution.values[1:], witness) // TODO factorize
-	for i := 0; i < len(witness); i++ {
-		solution.solved[i+1] = true
+	// with a solution cache, the constraints which don't depend on the inputs which changed since
+	// the snapshot are not solved again
+	var inc *incrementalSolver
+	var snapshot *r1csSnapshot
+	var dirty compiled.BitSet
+	if opt.SolutionCache != nil {
+		if inc, err = cs.incrementalSolver(); err != nil {
+			return &solution, err
+		}
+		if snapshot, dirty, err = inc.loadSnapshot(witness, opt); err != nil {
+			return &solution, err
+		}
+		schedule = inc.schedule
+	}
+
+	if layout == nil {
+		solution.Solved[0] = true // ONE_WIRE
+		solution.values[0].SetOne()
+		copy(solution.values[1:], witness) // TODO factorize
+		for i := 0; i < len(witness); i++ {
+			solution.Solved[i+1] = true
+		}
+
+		// keep track of the number of wire instantiations we do, for a sanity check to ensure
+		// we instantiated all wires
+		solution.NbSolved += len(witness) + 1
+
+		// the public outputs are solved by their hint, whatever their value in the witness
+		for _, o := range cs.PublicOutputs {
+			solution.Solved[o.Index+1] = false
+			solution.NbSolved--
+		}
+	} else {
+		var one fr.Element
+		one.SetOne()
+		_ = solution.set(0, one) // ONE_WIRE
+		outputs := make(map[int]bool, len(cs.PublicOutputs))
+		for _, o := range cs.PublicOutputs {
+			outputs[o.Index] = true
+		}
+		for i := 0; i < len(witness); i++ {
+			if outputs[i] {
+				continue
+			}
+			if err := solution.set(i+1, witness[i]); err != nil {
+				return &solution, err
+			}
+		}
 	}
 
-	// keep track of the number of wire instantiations we do, for a sanity check to ensure
-	// we instantiated all wires
-	solution.nbSolved += len(witness) + 1
+	// with a solution cache directory, the solution of the witness stored by a previous solve is loaded,
+	// and checked against a sample of the constraints
+	var cached *checkpointer
+	start := 0
+	if opt.SolutionCacheDir != "" {
+		digest, err := cs.checkpointDigest()
+		if err != nil {
+			return &solution, err
+		}
+		cached = newSolutionCacheEntry(digest, witness, opt)
+		if start, err = cached.load(&solution, nbConstraints, a, b, c); err != nil {
+			return &solution, err
+		}
+		if start == nbConstraints {
+			if err := cs.checkCachedSolution(&solution, a, b, c, opt.SolutionCacheChecks); err != nil {
+				// the entry is stale: it is removed, and the witness solved again
+				if err := cached.discard(err); err != nil {
+					return &solution, err
+				}
+				return cs.solve(schedule, witness, a, b, c, opt)
+			}
+		}
+	}
+
+	// with a checkpoint, the solve resumes after the constraints it records
+	var cp *checkpointer
+	if opt.CheckpointDir != "" && start == 0 {
+		digest, err := cs.checkpointDigest()
+		if err != nil {
+			return &solution, err
+		}
+		cp = newCheckpointer(digest, witness, opt)
+		if start, err = cp.load(&solution, nbConstraints, a, b, c); err != nil {
+			return &solution, err
+		}
+	}
 
 	// now that we know all inputs are set, defer log printing once all solution.values are computed
 	// (or sooner, if a constraint is not satisfied)
@@ -99,48 +276,143 @@ func (cs *R1CS) Solve(witness, a, b, c []fr.Element, opt backend.ProverOption) (
 	// first we solve the unsolved wire (if any)
 	// then we check that the constraint is valid
 	// if a[i] * b[i] != c[i]; it means the constraint is not satisfied
-	for i := 0; i < len(cs.Constraints); i++ {
+	// the constraints are expanded one at a time in r1c, see compiled.R1CList
+	var r1c compiled.R1C
+	for i := start; i < nbConstraints; i++ {
+		if cp != nil && cp.due(i) {
+			cp.save(&solution, i, a, b, c)
+		}
+		if snapshot != nil && !dirty.Get(i) {
+			// the constraint depends on no changed input: copy the wires it solves and its values
+			for _, w := range inc.graph.Writes[i] {
+				if err := solution.set(w, snapshot.values[w]); err != nil {
+					return &solution, err
+				}
+			}
+			a[i], b[i], c[i] = snapshot.a[i], snapshot.b[i], snapshot.c[i]
+			continue
+		}
+
 		// solve the constraint, this will compute the missing wire of the gate
-		if err := cs.solveConstraint(cs.Constraints[i], &solution); err != nil {
+		// and the values for the R1C (ie value * coeff)
+		var err error
+		satisfied := false
+		if shape, terms, wires := cs.Constraints.Shape(i); schedule == nil && shape.Fast() {
+			a[i], b[i], c[i], satisfied, err = cs.solveShapedConstraint(shape, terms, wires, &solution)
+		} else {
+			cs.Constraints.Load(i, &r1c)
+			if schedule == nil {
+				a[i], b[i], c[i], err = cs.solveConstraint(r1c, &solution)
+			} else {
+				a[i], b[i], c[i], err = cs.solveScheduledConstraint(r1c, schedule.Constraints[i], &solution)
+			}
+		}
+		if err != nil {
 			if dID, ok := cs.MDebug[i]; ok {
 				debugInfoStr := solution.logValue(cs.DebugInfo[dID])
-				return solution.values, fmt.Errorf("%w: %s", err, debugInfoStr)
+				return &solution, fmt.Errorf("%w: %s", err, debugInfoStr)
 			}
-			return solution.values, err
+			return &solution, err
+		}
+		if satisfied {
+			continue
 		}
-
-		// compute values for the R1C (ie value * coeff)
-		a[i], b[i], c[i] = cs.instantiateR1C(cs.Constraints[i], &solution)
 
 		// ensure a[i] * b[i] == c[i]
 		check.Mul(&a[i], &b[i])
 		if !check.Equal(&c[i]) {
 			if dID, ok := cs.MDebug[i]; ok {
 				debugInfoStr := solution.logValue(cs.DebugInfo[dID])
-				return solution.values, fmt.Errorf("%w: %s", ErrUnsatisfiedConstraint, debugInfoStr)
+				return &solution, fmt.Errorf("%w: %s", ErrUnsatisfiedConstraint, debugInfoStr)
 			}
-			return solution.values, ErrUnsatisfiedConstraint
+			return &solution, ErrUnsatisfiedConstraint
 		}
 	}
 
 	// sanity check; ensure all wires are marked as "instantiated"
-	if !solution.isValid() {
+	if !solution.IsValid() {
 		panic("solver didn't instantiate all wires")
 	}
 
-	return solution.values, nil
+	if cp != nil {
+		cp.remove()
+	}
+	if cached != nil && start != nbConstraints {
+		cached.save(&solution, nbConstraints, a, b, c)
+	}
+	if inc != nil {
+		inc.storeSnapshot(&solution, a, b, c, opt)
+	}
+	if opt.SharedSolution != nil {
+		// a copy, as the prover modifies the solution in place
+		values := make([]fr.Element, len(solution.Solved))
+		for i := 0; i < len(values); i++ {
+			values[i] = solution.get(i)
+		}
+		opt.SharedSolution.Store(values)
+	}
+	if opt.PublicOutputs != nil && len(cs.PublicOutputs) != 0 {
+		opt.PublicOutputs(solution.publicOutputs(cs.PublicOutputs, 1))
+	}
+
+	return &solution, nil
 }
 
 // IsSolved returns nil if given witness solves the R1CS and error otherwise
 // this method wraps cs.Solve() and allocates cs.Solve() inputs
 func (cs *R1CS) IsSolved(witness []fr.Element, opt backend.ProverOption) error {
-	a := make([]fr.Element, len(cs.Constraints))
-	b := make([]fr.Element, len(cs.Constraints))
-	c := make([]fr.Element, len(cs.Constraints))
-	_, err := cs.Solve(witness, a, b, c, opt)
+	a := make([]fr.Element, cs.Constraints.Len())
+	b := make([]fr.Element, cs.Constraints.Len())
+	c := make([]fr.Element, cs.Constraints.Len())
+	_, err := cs.solve(nil, witness, a, b, c, opt)
 	return err
 }
 
+// CheckAssignment evaluates every constraint against values, the full assignment of the wires
+// (public wires starting with the constant wire 1, secret then internal wires, as returned by
+// Solve), without solving. It returns which constraints are satisfied, and an
+// *backend.UnsatisfiedAssignmentError counting the unsatisfied ones, if any.
+func (cs *R1CS) CheckAssignment(values []*big.Int) ([]bool, error) {
+	return cs.CheckAssignmentUpTo(values, 0)
+}
+
+// CheckAssignmentUpTo is CheckAssignment, stopping once maxFailures unsatisfied constraints are
+// found, see frontend.CompiledConstraintSystem
+func (cs *R1CS) CheckAssignmentUpTo(values []*big.Int, maxFailures int) ([]bool, error) {
+	solution, err := assignmentSolution(values, cs.NbPublicVariables+cs.NbSecretVariables+cs.NbInternalVariables, cs.Coefficients)
+	if err != nil {
+		return nil, err
+	}
+	return common.CheckAssignment(cs.Constraints.Len(), maxFailures, func(i int) bool {
+		var r1c compiled.R1C
+		cs.Constraints.Load(i, &r1c)
+		a, b, c := cs.instantiateR1C(r1c, &solution)
+		a.Mul(&a, &b)
+		return a.Equal(&c)
+	})
+}
+
+// AnalyzeWitness returns the distribution of values, the full assignment of the wires (public wires
+// starting with the constant wire 1, secret then internal wires, as returned by Solve), per multi
+// exponentiation of the Groth16 prover, see backend.WitnessAnalysis
+func (cs *R1CS) AnalyzeWitness(values []*big.Int) (*backend.WitnessAnalysis, error) {
+	solution, err := assignmentSolution(values, cs.NbPublicVariables+cs.NbSecretVariables+cs.NbInternalVariables, cs.Coefficients)
+	if err != nil {
+		return nil, err
+	}
+	for i := range solution.values {
+		solution.values[i].FromMont()
+	}
+	return cs.AnalyzeSolution(solution.values), nil
+}
+
+// AnalyzeSolution is AnalyzeWitness, on the values of all the wires in regular form
+func (cs *R1CS) AnalyzeSolution(values []fr.Element) *backend.WitnessAnalysis {
+	return common.AnalyzeR1CSWitness(&cs.R1CS, fr.Bits, func(i int) []uint64 {
+		return values[i][:]
+	})
+}
+
 // mulByCoeff sets res = res * t.Coeff
 func (cs *R1CS) mulByCoeff(res *fr.Element, t compiled.Term) {
 	cID := t.CoeffID()
@@ -158,6 +430,26 @@ func (cs *R1CS) mulByCoeff(res *fr.Element, t compiled.Term) {
 	}
 }
 
+// checkCachedSolution checks a solution loaded from the solution cache directory, and its a, b, c vectors,
+// against nbChecks constraints, see backend.WithSolutionCacheChecks
+func (cs *R1CS) checkCachedSolution(solution *solution, a, b, c []fr.Element, nbChecks int) error {
+	indexes, err := sampleConstraints(cs.Constraints.Len(), nbChecks)
+	if err != nil {
+		return err
+	}
+	var r1c compiled.R1C
+	var check fr.Element
+	for _, i := range indexes {
+		cs.Constraints.Load(i, &r1c)
+		ai, bi, ci := cs.instantiateR1C(r1c, solution)
+		check.Mul(&ai, &bi)
+		if !check.Equal(&ci) || !ai.Equal(&a[i]) || !bi.Equal(&b[i]) || !ci.Equal(&c[i]) {
+			return fmt.Errorf("constraint %d: %w", i, ErrUnsatisfiedConstraint)
+		}
+	}
+	return nil
+}
+
 // compute left, right, o part of a cs constraint
 // this function is called when all the wires have been computed
 // it instantiates the l, r o part of a R1C
@@ -184,23 +476,20 @@ func (cs *R1CS) instantiateR1C(r compiled.R1C, solution *solution) (a, b, c fr.E
 // , eg when doing a binary decomposition: either way the missing wire can
 // be computed without ambiguity because the cs is correctly ordered)
 //
-// It returns the 1 if the the position to solve is in the quadratic part (it
-// means that there is a division and serves to navigate in the log info for the
-// computational constraints), and 0 otherwise.
-func (cs *R1CS) solveConstraint(r compiled.R1C, solution *solution) error {
+// It returns the values of L, R and O once the wire is solved, as instantiateR1C would.
+func (cs *R1CS) solveConstraint(r compiled.R1C, solution *solution) (a, b, c fr.Element, err error) {
 
 	// the index of the non zero entry shows if L, R or O has an uninstantiated wire
 	// the content is the ID of the wire non instantiated
 	var loc uint8
 
-	var a, b, c fr.Element
 	var termToCompute compiled.Term
 
 	processTerm := func(t compiled.Term, val *fr.Element, locValue uint8) error {
 		vID := t.VariableID()
 
 		// wire is already computed, we just accumulate in val
-		if solution.solved[vID] {
+		if solution.Solved[vID] {
 			v := solution.computeTerm(t)
 			val.Add(val, &v)
 			return nil
@@ -208,7 +497,12 @@ func (cs *R1CS) solveConstraint(r compiled.R1C, solution *solution) error {
 
 		// first we check if this is a hint wire
 		if hint, ok := cs.MHints[vID]; ok {
-			return solution.solveWithHint(vID, hint)
+			if err := solution.solveWithHint(vID, hint); err != nil {
+				return err
+			}
+			v := solution.computeTerm(t)
+			val.Add(val, &v)
+			return nil
 		}
 
 		if loc != 0 {
@@ -220,20 +514,20 @@ func (cs *R1CS) solveConstraint(r compiled.R1C, solution *solution) error {
 	}
 
 	for _, t := range r.L {
-		if err := processTerm(t, &a, 1); err != nil {
-			return err
+		if err = processTerm(t, &a, 1); err != nil {
+			return
 		}
 	}
 
 	for _, t := range r.R {
-		if err := processTerm(t, &b, 2); err != nil {
-			return err
+		if err = processTerm(t, &b, 2); err != nil {
+			return
 		}
 	}
 
 	for _, t := range r.O {
-		if err := processTerm(t, &c, 3); err != nil {
-			return err
+		if err = processTerm(t, &c, 3); err != nil {
+			return
 		}
 	}
 
@@ -241,43 +535,200 @@ func (cs *R1CS) solveConstraint(r compiled.R1C, solution *solution) error {
 		// there is nothing to solve, may happen if we have an assertion
 		// (ie a constraints that doesn't yield any output)
 		// or if we solved the unsolved wires with hint functions
-		return nil
+		return
 	}
 
-	// we compute the wire value and instantiate it
+	// we compute the wire value and instantiate it, and add its term to the R1C values
 	vID := termToCompute.VariableID()
+	if err = solution.set(vID, cs.solveWire(loc, termToCompute, &a, &b, &c)); err != nil {
+		return
+	}
+	v := solution.computeTerm(termToCompute)
+	switch loc {
+	case 1:
+		a.Add(&a, &v)
+	case 2:
+		b.Add(&b, &v)
+	case 3:
+		c.Add(&c, &v)
+	}
+	return
+}
+
+// solveScheduledConstraint is solveConstraint followed by instantiateR1C, using the schedule s
+// of the constraint r instead of checking if its wires are solved.
+func (cs *R1CS) solveScheduledConstraint(r compiled.R1C, s compiled.ScheduledR1C, solution *solution) (a, b, c fr.Element, err error) {
+	for _, h := range s.Hints {
+		if err = solution.solveWithHint(h.Wire, h.Hint); err != nil {
+			return
+		}
+	}
+
+	accumulate := func(l compiled.LinearExpression, val *fr.Element, loc uint8) {
+		for j, t := range l {
+			if loc == s.Loc && j == s.Term {
+				continue
+			}
+			v := solution.computeTerm(t)
+			val.Add(val, &v)
+		}
+	}
+	accumulate(r.L, &a, 1)
+	accumulate(r.R, &b, 2)
+	accumulate(r.O, &c, 3)
+
+	if s.Loc == 0 {
+		return
+	}
+
+	// solve the wire, and add its term to the R1C values
+	var t compiled.Term
+	var val *fr.Element
+	switch s.Loc {
+	case 1:
+		t, val = r.L[s.Term], &a
+	case 2:
+		t, val = r.R[s.Term], &b
+	case 3:
+		t, val = r.O[s.Term], &c
+	}
+	if err = solution.set(t.VariableID(), cs.solveWire(s.Loc, t, &a, &b, &c)); err != nil {
+		return
+	}
+	v := solution.computeTerm(t)
+	val.Add(val, &v)
+	return
+}
+
+// solveShapedConstraint is solveConstraint followed by instantiateR1C, for a constraint whose linear
+// expressions all have a fast path (see compiled.R1CShape), terms and wires being the ones of its
+// blueprint (see compiled.R1CList.Shape). It returns satisfied == true if the constraint is satisfied
+// by construction, the wire it solves being in O with the coefficient 1: a * b == c needs no check.
+func (cs *R1CS) solveShapedConstraint(shape compiled.R1CShape, terms []compiled.Term, wires []uint32, solution *solution) (a, b, c fr.Element, satisfied bool, err error) {
+	var loc uint8
+	var unsolved int // index in terms of the term of the wire to solve
+
+	j := 0
+	for k, s := range [3]compiled.LinearShape{shape.L(), shape.R(), shape.O()} {
+		val := &a
+		if k == 1 {
+			val = &b
+		} else if k == 2 {
+			val = &c
+		}
+		for end := j + s.NbTerms(); j < end; j++ {
+			vID := int(wires[j])
+			if !solution.Solved[vID] {
+				hint, ok := cs.MHints[vID]
+				if !ok {
+					if loc != 0 {
+						panic("found more than one wire to instantiate")
+					}
+					loc, unsolved = uint8(k+1), j
+					continue
+				}
+				if err = solution.solveWithHint(vID, hint); err != nil {
+					return
+				}
+			}
+			if s == compiled.ShapeTerm {
+				v := solution.computeTerm(terms[j] | compiled.Term(vID))
+				val.Add(val, &v)
+			} else {
+				v := solution.get(vID)
+				val.Add(val, &v)
+			}
+		}
+	}
+
+	if loc == 0 {
+		return
+	}
+
+	// solve the wire, and add its term to the R1C values
+	t := terms[unsolved] | compiled.Term(wires[unsolved])
+	var wire fr.Element
+	switch {
+	case t.CoeffID() != compiled.CoeffIdOne:
+		wire = cs.solveWire(loc, t, &a, &b, &c)
+	case loc == 1:
+		if !b.IsZero() {
+			wire.Div(&c, &b).Sub(&wire, &a)
+		}
+		a.Add(&a, &wire)
+	case loc == 2:
+		if !a.IsZero() {
+			wire.Div(&c, &a).Sub(&wire, &b)
+		}
+		b.Add(&b, &wire)
+	default:
+		wire.Set(&c)
+		c.Mul(&a, &b)
+		wire.Sub(&c, &wire)
+		satisfied = true
+	}
+	if err = solution.set(t.VariableID(), wire); err != nil {
+		return
+	}
+	if t.CoeffID() != compiled.CoeffIdOne {
+		v := solution.computeTerm(t)
+		switch loc {
+		case 1:
+			a.Add(&a, &v)
+		case 2:
+			b.Add(&b, &v)
+		case 3:
+			c.Add(&c, &v)
+		}
+	}
+	return
+}
 
-	// solver result
+// solveWire returns the value of the wire of t at location loc (1, 2 or 3 for L, R or O), such that
+// the R1C is satisfied, a, b and c being the values of L, R and O without t.
+func (cs *R1CS) solveWire(loc uint8, t compiled.Term, a, b, c *fr.Element) fr.Element {
 	var wire fr.Element
 
 	switch loc {
 	case 1:
 		if !b.IsZero() {
-			wire.Div(&c, &b).
-				Sub(&wire, &a)
-			cs.mulByCoeff(&wire, termToCompute)
+			wire.Div(c, b).
+				Sub(&wire, a)
+			cs.mulByCoeff(&wire, t)
 		}
 	case 2:
 		if !a.IsZero() {
-			wire.Div(&c, &a).
-				Sub(&wire, &b)
-			cs.mulByCoeff(&wire, termToCompute)
+			wire.Div(c, a).
+				Sub(&wire, b)
+			cs.mulByCoeff(&wire, t)
 		}
 	case 3:
-		wire.Mul(&a, &b).
-			Sub(&wire, &c)
-		cs.mulByCoeff(&wire, termToCompute)
+		wire.Mul(a, b).
+			Sub(&wire, c)
+		cs.mulByCoeff(&wire, t)
 	}
 
-	solution.set(vID, wire)
-
-	return nil
+	return wire
 }
 
 // TODO @gbotrel clean logs and html see https://github.com/ConsenSys/gnark/issues/140
 
-// ToHTML returns an HTML human-readable representation of the constraint system
-func (cs *R1CS) ToHTML(w io.Writer) error {
+// ToHTML returns an HTML human-readable representation of the constraint system: a page rendering
+// the constraints a page at a time from their JSON export (see ToJSON), or a static table of all the
+// constraints if opt.Table is set
+func (cs *R1CS) ToHTML(w io.Writer, opts ...func(opt *compiled.ExportOptions) error) error {
+	opt, err := compiled.NewExportOptions(opts...)
+	if err != nil {
+		return err
+	}
+	if !opt.Table {
+		data, err := cs.exportConstraints(opt)
+		if err != nil {
+			return err
+		}
+		return compiled.WritePage(w, data, opt)
+	}
+
 	t, err := template.New("cs.html").Funcs(template.FuncMap{
 		"toHTML": toHTML,
 		"add":    add,
@@ -290,6 +741,38 @@ func (cs *R1CS) ToHTML(w io.Writer) error {
 	return t.Execute(w, cs)
 }
 
+// ToJSON writes the JSON export of the constraints of the constraint system, see compiled.ConstraintsJSON
+func (cs *R1CS) ToJSON(w io.Writer, opts ...func(opt *compiled.ExportOptions) error) error {
+	opt, err := compiled.NewExportOptions(opts...)
+	if err != nil {
+		return err
+	}
+	data, err := cs.exportConstraints(opt)
+	if err != nil {
+		return err
+	}
+	_, err = data.WriteTo(w)
+	return err
+}
+
+// exportConstraints returns the JSON export of the constraints in the range of opt
+func (cs *R1CS) exportConstraints(opt compiled.ExportOptions) (*compiled.ConstraintsJSON, error) {
+	var r1c compiled.R1C
+	return cs.NewConstraintsJSON(compiled.R1CSLayout, cs.Constraints.Len(), coefficientStrings(cs.Coefficients), opt, func(i int) []compiled.LinearExpression {
+		cs.Constraints.Load(i, &r1c)
+		return []compiled.LinearExpression{r1c.L, r1c.R, r1c.O}
+	})
+}
+
+// coefficientStrings returns the coefficients in base 10
+func coefficientStrings(coeffs []fr.Element) []string {
+	res := make([]string, len(coeffs))
+	for i := range coeffs {
+		res[i] = coeffs[i].String()
+	}
+	return res
+}
+
 func add(a, b int) int {
 	return a + b
 }
@@ -386,10 +869,14 @@ func (cs *R1CS) ReadFrom(r io.Reader) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	decoder := dm.NewDecoder(r)
+	// the decoder buffers the whole constraint system, up to gnarkio.MaxReadBytes
+	decoder := dm.NewDecoder(gnarkio.NewLimitReader(r))
 	if err := decoder.Decode(&cs); err != nil {
 		return int64(decoder.NumBytesRead()), err
 	}
+	if err := cs.CheckVersion(); err != nil {
+		return int64(decoder.NumBytesRead()), err
+	}
 
-	return int64(decoder.NumBytesRead()), nil
+	return int64(decoder.NumBytesRead()), cs.Constraints.CheckShapes()
 }
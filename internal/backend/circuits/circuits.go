@@ -11,6 +11,15 @@ type TestCircuit struct {
 	Circuit                          frontend.Circuit
 	ValidWitnesses, InvalidWitnesses []frontend.Circuit // good and bad witness for the prover + public verifier data
 	HintFunctions                    []hint.Function
+
+	// ExpectedCompileError, if set, marks a circuit which must fail to compile with an error
+	// containing it; such entries have no witnesses
+	ExpectedCompileError string
+
+	// ExpectedCompileErrorIs, if set, is the error the compile error must match with errors.Is,
+	// the circuit being compiled with CompileOptions
+	ExpectedCompileErrorIs error
+	CompileOptions         []func(opt *frontend.CompileOption) error
 }
 
 // Circuits are used for test purposes (backend.Groth16 and gnark/integration_test.go)
@@ -24,7 +33,7 @@ func addEntry(name string, circuit, proverGood, proverBad frontend.Circuit) {
 		panic("name " + name + "already taken by another test circuit ")
 	}
 
-	Circuits[name] = TestCircuit{circuit, []frontend.Circuit{proverGood}, []frontend.Circuit{proverBad}, nil}
+	Circuits[name] = TestCircuit{circuit, []frontend.Circuit{proverGood}, []frontend.Circuit{proverBad}, nil, "", nil, nil}
 }
 
 func addNewEntry(name string, circuit frontend.Circuit, proverGood, proverBad []frontend.Circuit, hintFunctions ...hint.Function) {
@@ -35,5 +44,29 @@ func addNewEntry(name string, circuit frontend.Circuit, proverGood, proverBad []
 		panic("name " + name + "already taken by another test circuit ")
 	}
 
-	Circuits[name] = TestCircuit{circuit, proverGood, proverBad, hintFunctions}
+	Circuits[name] = TestCircuit{circuit, proverGood, proverBad, hintFunctions, "", nil, nil}
+}
+
+func addCompileErrorEntry(name string, circuit frontend.Circuit, expectedError string) {
+	if Circuits == nil {
+		Circuits = make(map[string]TestCircuit)
+	}
+	if _, ok := Circuits[name]; ok {
+		panic("name " + name + "already taken by another test circuit ")
+	}
+
+	Circuits[name] = TestCircuit{Circuit: circuit, ExpectedCompileError: expectedError}
+}
+
+// addCompileErrorIsEntry adds a circuit which must fail to compile with opts, with an error matching
+// expectedError with errors.Is
+func addCompileErrorIsEntry(name string, circuit frontend.Circuit, expectedError error, opts ...func(opt *frontend.CompileOption) error) {
+	if Circuits == nil {
+		Circuits = make(map[string]TestCircuit)
+	}
+	if _, ok := Circuits[name]; ok {
+		panic("name " + name + "already taken by another test circuit ")
+	}
+
+	Circuits[name] = TestCircuit{Circuit: circuit, ExpectedCompileError: expectedError.Error(), ExpectedCompileErrorIs: expectedError, CompileOptions: opts}
 }
@@ -0,0 +1,51 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+// test circuit using a variable which is ignored by Compile, hence never allocated
+type unsetVariableCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:"-"`
+}
+
+func (circuit *unsetVariableCircuit) Define(curveID ecc.ID, cs frontend.API) error {
+	cs.AssertIsEqual(circuit.X, circuit.Y)
+	return nil
+}
+
+// test circuit asserting an equality between two different constants
+type constantAssertionCircuit struct {
+	X frontend.Variable
+}
+
+func (circuit *constantAssertionCircuit) Define(curveID ecc.ID, cs frontend.API) error {
+	cs.AssertIsEqual(circuit.X, 2)
+	cs.AssertIsEqual(cs.Mul(2, 2), 5)
+	return nil
+}
+
+// test circuit compiled with the schema of a circuit with more inputs
+type shapeMismatchCircuit struct {
+	X []frontend.Variable
+}
+
+func (circuit *shapeMismatchCircuit) Define(curveID ecc.ID, cs frontend.API) error {
+	for i := 0; i < len(circuit.X); i++ {
+		cs.AssertIsBoolean(circuit.X[i])
+	}
+	return nil
+}
+
+func init() {
+	addCompileErrorEntry("compile_error_unset_variable", &unsetVariableCircuit{}, "variable is not allocated")
+	addCompileErrorEntry("compile_error_constant_assertion", &constantAssertionCircuit{}, "assertIsEqual failed: constant(4) == constant(5)")
+
+	schema, err := frontend.ParseSchema(&shapeMismatchCircuit{X: make([]frontend.Variable, 3)})
+	if err != nil {
+		panic(err)
+	}
+	addCompileErrorIsEntry("compile_error_shape_mismatch", &shapeMismatchCircuit{X: make([]frontend.Variable, 2)}, frontend.ErrSchemaMismatch, frontend.WithSchema(schema))
+}
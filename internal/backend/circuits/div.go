@@ -8,10 +8,12 @@ import (
 type divCircuit struct {
 	X, Y frontend.Variable
 	Z    frontend.Variable `gnark:",public"`
+	Q    frontend.Variable `gnark:",output"`
 }
 
 func (circuit *divCircuit) Define(curveID ecc.ID, cs frontend.API) error {
-	cs.AssertIsEqual(cs.DivUnchecked(circuit.X, circuit.Y), circuit.Z)
+	circuit.Q = cs.DivUnchecked(circuit.X, circuit.Y)
+	cs.AssertIsEqual(circuit.Q, circuit.Z)
 	return nil
 }
 
@@ -14,6 +14,7 @@ type hintCircuit struct {
 func (circuit *hintCircuit) Define(curveID ecc.ID, api frontend.API) error {
 	a7 := api.NewHint(mulBy7, circuit.A)
 	_a7 := api.Mul(circuit.A, 7)
+	api.MarkOutput(a7, "a7")
 
 	api.AssertIsEqual(a7, _a7)
 	api.AssertIsEqual(a7, circuit.B)
@@ -12,6 +12,7 @@ type invCircuit struct {
 func (circuit *invCircuit) Define(curveID ecc.ID, cs frontend.API) error {
 	m := cs.Mul(circuit.X, circuit.Y)
 	u := cs.Inverse(circuit.Y)
+	cs.MarkOutput(u, "invY")
 	v := cs.Mul(m, u)
 	cs.AssertIsEqual(v, circuit.Z)
 	return nil
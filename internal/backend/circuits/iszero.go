@@ -13,6 +13,8 @@ func (circuit *isZero) Define(curveID ecc.ID, cs frontend.API) error {
 
 	a := cs.IsZero(circuit.X)
 	b := cs.IsZero(circuit.Y)
+	cs.MarkOutput(a, "a")
+	cs.MarkOutput(b, "b")
 	cs.AssertIsEqual(a, 1)
 	cs.AssertIsEqual(b, 0)
 
@@ -30,4 +32,28 @@ func init() {
 	bad.Y.Assign(0)
 
 	addEntry("isZero", &circuit, &good, &bad)
+
+	isZeroOutput()
+}
+
+// isZeroOutputCircuit checks the output of IsZero, in particular at the edges of the field
+type isZeroOutputCircuit struct {
+	X frontend.Variable
+	Z frontend.Variable `gnark:",public"`
+}
+
+func (circuit *isZeroOutputCircuit) Define(curveID ecc.ID, cs frontend.API) error {
+	cs.AssertIsEqual(cs.IsZero(circuit.X), circuit.Z)
+	return nil
+}
+
+func isZeroOutput() {
+	witness := func(x, z interface{}) frontend.Circuit {
+		return &isZeroOutputCircuit{X: frontend.Value(x), Z: frontend.Value(z)}
+	}
+
+	good := []frontend.Circuit{witness(0, 1), witness(1, 0), witness(-1, 0)}
+	bad := []frontend.Circuit{witness(0, 0), witness(1, 1), witness(-1, 1)}
+
+	addNewEntry("isZero_output", &isZeroOutputCircuit{}, good, bad)
 }
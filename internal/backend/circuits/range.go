@@ -1,6 +1,8 @@
 package circuits
 
 import (
+	"math/big"
+
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/frontend"
 )
@@ -61,7 +63,56 @@ func rangeCheck() {
 	addEntry("range", &circuit, &good, &bad)
 }
 
+// rangeCheckModulusCircuit compares values close to the modulus (-1 == q - 1)
+type rangeCheckModulusCircuit struct {
+	X     frontend.Variable
+	Bound frontend.Variable `gnark:",public"`
+}
+
+func (circuit *rangeCheckModulusCircuit) Define(curveID ecc.ID, cs frontend.API) error {
+	cs.AssertIsLessOrEqual(circuit.X, circuit.Bound)
+	return nil
+}
+
+func rangeCheckModulus() {
+	witness := func(x, bound int) frontend.Circuit {
+		return &rangeCheckModulusCircuit{X: frontend.Value(x), Bound: frontend.Value(bound)}
+	}
+
+	good := []frontend.Circuit{witness(-2, -1), witness(-1, -1), witness(0, -1), witness(1, 1)}
+	bad := []frontend.Circuit{witness(-1, -2), witness(-1, 0), witness(1, 0), witness(-1, 1)}
+
+	addNewEntry("range_modulus", &rangeCheckModulusCircuit{}, good, bad)
+}
+
+type rangeCheckBoundedCircuit struct {
+	X     frontend.Variable
+	Bound frontend.Variable `gnark:",public"`
+}
+
+func (circuit *rangeCheckBoundedCircuit) Define(curveID ecc.ID, cs frontend.API) error {
+	cs.AssertIsLessOrEqualBounded(circuit.X, circuit.Bound, 64)
+	cs.AssertIsLessOrEqualBounded(circuit.X, uint64(1<<63), 64)
+	return nil
+}
+
+func rangeCheckBounded() {
+	witness := func(x, bound interface{}) frontend.Circuit {
+		return &rangeCheckBoundedCircuit{X: frontend.Value(x), Bound: frontend.Value(bound)}
+	}
+
+	var two64 big.Int
+	two64.Lsh(big.NewInt(1), 64)
+
+	good := []frontend.Circuit{witness(3, 5), witness(5, 5), witness(0, uint64(1<<64-1)), witness(uint64(1<<63), uint64(1<<64-1))}
+	bad := []frontend.Circuit{witness(6, 5), witness(-1, 5), witness(3, two64), witness(uint64(1<<63+1), uint64(1<<64-1))}
+
+	addNewEntry("range_bounded", &rangeCheckBoundedCircuit{}, good, bad)
+}
+
 func init() {
 	rangeCheckConstant()
 	rangeCheck()
+	rangeCheckModulus()
+	rangeCheckBounded()
 }
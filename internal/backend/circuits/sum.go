@@ -0,0 +1,45 @@
+package circuits
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+type sumProductCircuit struct {
+	X        [5]frontend.Variable
+	Sum, Prd frontend.Variable `gnark:",public"`
+}
+
+func (circuit *sumProductCircuit) Define(curveID ecc.ID, cs frontend.API) error {
+	vs := append(circuit.X[:], cs.Constant(2))
+
+	cs.AssertIsEqual(cs.Sum(vs...), circuit.Sum)
+	cs.AssertIsEqual(cs.Product(vs...), circuit.Prd)
+	cs.AssertIsEqual(cs.Sum(circuit.X[0]), circuit.X[0])
+	cs.AssertIsEqual(cs.Product(), 1)
+	return nil
+}
+
+func init() {
+	witness := func(x [5]int, sum, prd int) frontend.Circuit {
+		var w sumProductCircuit
+		for i := range x {
+			w.X[i].Assign(x[i])
+		}
+		w.Sum.Assign(sum)
+		w.Prd.Assign(prd)
+		return &w
+	}
+
+	good := []frontend.Circuit{
+		witness([5]int{1, 2, 3, 4, 5}, 17, 240),
+		witness([5]int{-1, 0, 3, 4, 5}, 13, 0),
+	}
+
+	bad := []frontend.Circuit{
+		witness([5]int{1, 2, 3, 4, 5}, 15, 240),
+		witness([5]int{1, 2, 3, 4, 5}, 17, 120),
+	}
+
+	addNewEntry("sum_product", &sumProductCircuit{}, good, bad)
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// CBORVersion is the version of the CBOR encodings of the witnesses and the proofs
+const CBORVersion = 1
+
+// types of the CBOR encodings, see CBORHeader
+const (
+	CBORPublicWitness = "gnark/witness/public"
+	CBORGroth16Proof  = "gnark/groth16/proof"
+	CBORPlonkProof    = "gnark/plonk/proof"
+
+	CBORVerificationBundle = "gnark/bundle"
+)
+
+// CBORHeader is the header of the CBOR encodings of the witnesses and the proofs: they are maps
+// with the keys "type", "version" and "curve", followed by the keys of the encoded object.
+type CBORHeader struct {
+	Type    string `cbor:"type"`
+	Version uint   `cbor:"version"`
+	Curve   string `cbor:"curve"` // ecc.ID.String()
+}
+
+// NewCBORHeader returns the header of an object of type typ over curveID
+func NewCBORHeader(typ string, curveID ecc.ID) CBORHeader {
+	return CBORHeader{Type: typ, Version: CBORVersion, Curve: curveID.String()}
+}
+
+// Check returns an error wrapping backend.ErrCBORHeaderMismatch if h is not the header of an
+// object of type typ over curveID
+func (h *CBORHeader) Check(typ string, curveID ecc.ID) error {
+	if h.Type != typ {
+		return fmt.Errorf("%w: got a %q, expected a %q", backend.ErrCBORHeaderMismatch, h.Type, typ)
+	}
+	if h.Version != CBORVersion {
+		return fmt.Errorf("%w: unsupported version %d", backend.ErrCBORHeaderMismatch, h.Version)
+	}
+	if h.Curve != curveID.String() {
+		return fmt.Errorf("%w: encoded on curve %q, expected %s", backend.ErrCBORHeaderMismatch, h.Curve, curveID)
+	}
+	return nil
+}
+
+// CBOREncMode encodes in canonical CBOR (RFC 7049 section 3.9): the maps are sorted, the integers
+// and lengths use their shortest form, such that an object has a single encoding
+var CBOREncMode cbor.EncMode
+
+// CBORDecMode rejects duplicate map keys and indefinite lengths, which canonical CBOR doesn't have
+var CBORDecMode cbor.DecMode
+
+func init() {
+	var err error
+	if CBOREncMode, err = cbor.CanonicalEncOptions().EncMode(); err != nil {
+		panic(err)
+	}
+	CBORDecMode, err = cbor.DecOptions{
+		DupMapKey:        cbor.DupMapKeyEnforcedAPF,
+		IndefLength:      cbor.IndefLengthForbidden,
+		MaxArrayElements: 134217728,
+		MaxMapPairs:      134217728,
+	}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,383 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package common holds the part of the constraint system solvers which doesn't depend on the curve.
+//
+// The solvers of the curves (internal/backend/{curve}/cs, generated) hold the values of the wires as
+// field elements, and embed a Solution for the rest: which wires are solved, the hint functions, the
+// calls of the hints and the formatting of the logs. They give access to the values through the
+// Wires interface.
+package common
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/internal/backend/compiled"
+)
+
+// Wires gives access to the values of the wires of a solution, held by the curve specific solver
+type Wires interface {
+	// TermValue sets res to the value of the term t of a linear expression: its coefficient times the
+	// value of its wire, or its coefficient if t is a constant. The wire is solved.
+	TermValue(t compiled.Term, res *big.Int)
+
+	// SetValue sets the value of the wire id to v, reduced modulo the scalar field. It marks the
+	// wire as solved (see Solution.MarkSolved).
+	SetValue(id int, v *big.Int) error
+
+	// CoefficientString returns the coefficient cID, in base 10
+	CoefficientString(cID int) string
+
+	// WireString returns the value of the solved wire vID, in base 10
+	WireString(vID int) string
+
+	// SumString returns the sum of the terms, whose wires are solved, in base 10
+	SumString(terms []compiled.Term) string
+}
+
+// Solution is the curve independent part of a solution of a constraint system
+type Solution struct {
+	Solved   []bool
+	NbSolved int
+
+	// if Layout is set, the boolean wires are packed in Bits (see backend.WithPackedBooleans)
+	Layout *compiled.BooleanLayout
+	Bits   compiled.BitSet
+
+	ConstantTime bool // see backend.WithConstantTimeHints
+
+	curveID         ecc.ID
+	modulus         *big.Int
+	mHintsFunctions map[hint.ID]hint.AnnotatedFunction
+	hintTimeout     time.Duration // see backend.WithHintTimeout
+}
+
+// NewSolution returns a solution of nbWires wires over the scalar field of curveID. If layout is
+// not nil, the boolean wires are packed.
+//
+// referenced holds the IDs of the hint functions the constraint system calls (see
+// compiled.CS.ReferencedHints). Unless opt.UnsafeHints is set, NewSolution returns an
+// *backend.UnexpectedHintsError if opt.HintFunctions has functions of other IDs. The hint functions are
+// then the builtins and the registered hints of these IDs, and opt.HintFunctions; an error reports two
+// functions sharing an ID, and where they come from. If referenced is nil, all the hint functions are
+// accepted.
+func NewSolution(curveID ecc.ID, nbWires int, layout *compiled.BooleanLayout, referenced map[hint.ID]string, opt backend.ProverOption) (Solution, error) {
+	s := Solution{
+		Solved:       make([]bool, nbWires),
+		Layout:       layout,
+		ConstantTime: opt.ConstantTime,
+		curveID:      curveID,
+		modulus:      curveID.Info().Fr.Modulus(),
+		hintTimeout:  opt.HintTimeout,
+	}
+	if layout != nil {
+		s.Bits = compiled.NewBitSet(layout.NbBooleans())
+	}
+
+	if referenced != nil && !opt.UnsafeHints {
+		var unexpected []string
+		for _, f := range opt.HintFunctions {
+			if _, ok := referenced[f.UUID()]; !ok {
+				unexpected = append(unexpected, fmt.Sprintf("%q (id %#x)", f.String(), uint64(f.UUID())))
+			}
+		}
+		if len(unexpected) != 0 {
+			return Solution{}, &backend.UnexpectedHintsError{Hints: unexpected}
+		}
+	}
+
+	var err error
+	if s.mHintsFunctions, err = hint.FunctionsFor(referenced, opt.HintFunctions); err != nil {
+		return Solution{}, err
+	}
+	return s, nil
+}
+
+// NbValues returns the number of wires whose values are stored as field elements, that is all the
+// wires but the packed boolean ones
+func (s *Solution) NbValues() int {
+	if s.Layout == nil {
+		return len(s.Solved)
+	}
+	return len(s.Solved) - s.Layout.NbBooleans()
+}
+
+// MarkSolved records that the wire id is solved; it panics if it already was
+func (s *Solution) MarkSolved(id int) {
+	if s.Solved[id] {
+		panic("solving the same wire twice should never happen.")
+	}
+	s.Solved[id] = true
+	s.NbSolved++
+}
+
+// IsValid returns true if all the wires are solved
+func (s *Solution) IsValid() bool {
+	return s.NbSolved == len(s.Solved)
+}
+
+// HintFunction returns the hint function of ID id, if any
+func (s *Solution) HintFunction(id hint.ID) (hint.AnnotatedFunction, bool) {
+	f, ok := s.mHintsFunctions[id]
+	return f, ok
+}
+
+// SolveWithHint computes the wires h.Wires (among them vID) with the hint function of h, and sets
+// them in w
+func (s *Solution) SolveWithHint(w Wires, vID int, h compiled.Hint) error {
+	// ensure hint function was provided
+	f, ok := s.mHintsFunctions[h.ID]
+	if !ok {
+		if h.Name != "" {
+			return fmt.Errorf("hint '%s' not found (id %d)", h.Name, uint32(h.ID))
+		}
+		return fmt.Errorf("missing hint function with id %d", uint32(h.ID))
+	}
+
+	// compute values for all inputs.
+	inputs := make([]*big.Int, len(h.Inputs))
+	for i := 0; i < len(inputs); i++ {
+		inputs[i] = bigIntPool.Get().(*big.Int)
+		inputs[i].SetUint64(0)
+	}
+	outputs := make([]*big.Int, len(h.Wires))
+	for i := 0; i < len(outputs); i++ {
+		outputs[i] = bigIntPool.Get().(*big.Int)
+		outputs[i].SetUint64(0)
+	}
+	lambda := bigIntPool.Get().(*big.Int)
+
+	// release objects into pool
+	defer func() {
+		bigIntPool.Put(lambda)
+		for i := 0; i < len(inputs); i++ {
+			bigIntPool.Put(inputs[i])
+		}
+		for i := 0; i < len(outputs); i++ {
+			bigIntPool.Put(outputs[i])
+		}
+	}()
+
+	for i := 0; i < len(h.Inputs); i++ {
+		// input is a linear expression, we must compute the value
+		for j := 0; j < len(h.Inputs[i]); j++ {
+			_, viID, visibility := h.Inputs[i][j].Unpack()
+			if visibility != compiled.Virtual && !s.Solved[viID] {
+				return errors.New("expected wire to be instantiated while evaluating hint")
+			}
+			w.TermValue(h.Inputs[i][j], lambda)
+			inputs[i].Add(inputs[i], lambda)
+		}
+	}
+
+	// ensure our inputs are mod q
+	for i := 0; i < len(inputs); i++ {
+		// note since we're only doing additions up there, we may want to avoid the use of Mod
+		// here in favor of Cmp & Sub
+		inputs[i].Mod(inputs[i], s.modulus)
+	}
+
+	if err := s.callHint(f, h.Static, inputs, outputs); err != nil {
+		return fmt.Errorf("hint %s (wire %d): %w", f, vID, err)
+	}
+
+	for i := 0; i < len(outputs); i++ {
+		if err := w.SetValue(h.Wires[i], outputs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callHint calls f, and turns a panic of f into an error. If s.hintTimeout is set and f doesn't
+// return in time, it returns backend.ErrHintTimeout; f is then left running in its goroutine, on
+// copies of inputs and outputs, such that the solution is not modified.
+func (s *Solution) callHint(f hint.AnnotatedFunction, static [][]byte, inputs, outputs []*big.Int) error {
+	if s.hintTimeout <= 0 {
+		return callHint(f, s.curveID, static, inputs, outputs)
+	}
+
+	in := make([]*big.Int, len(inputs))
+	for i := 0; i < len(inputs); i++ {
+		in[i] = new(big.Int).Set(inputs[i])
+	}
+	out := make([]*big.Int, len(outputs))
+	for i := 0; i < len(outputs); i++ {
+		out[i] = new(big.Int)
+	}
+
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- callHint(f, s.curveID, static, in, out)
+	}()
+
+	timer := time.NewTimer(s.hintTimeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-chErr:
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(outputs); i++ {
+			outputs[i].Set(out[i])
+		}
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("%w (%s)", backend.ErrHintTimeout, s.hintTimeout)
+	}
+}
+
+// callHint calls f with its static parameters (see hint.Invoke), and turns a panic of f into an error
+func callHint(f hint.AnnotatedFunction, curveID ecc.ID, static [][]byte, inputs, outputs []*big.Int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	return hint.Invoke(f, curveID, static, inputs, outputs)
+}
+
+// PrintLogs writes the logs of the circuit (api.Println) to w, with the values of w
+func (s *Solution) PrintLogs(w io.Writer, wires Wires, logs []compiled.LogEntry) {
+	if w == nil {
+		return
+	}
+
+	for i := 0; i < len(logs); i++ {
+		logLine := s.LogValue(wires, logs[i])
+		_, _ = io.WriteString(w, logLine)
+	}
+}
+
+const unsolvedVariable = "<unsolved>"
+
+// LogValue formats log with the values of w; the unsolved wires are written "<unsolved>"
+func (s *Solution) LogValue(w Wires, log compiled.LogEntry) string {
+	var toResolve []interface{}
+	var (
+		isEval       bool
+		eval         []compiled.Term
+		missingValue bool
+	)
+	for j := 0; j < len(log.ToResolve); j++ {
+		if log.ToResolve[j] == compiled.TermDelimitor {
+			// this is a special case where we want to evaluate the following terms until the next delimitor.
+			if !isEval {
+				isEval = true
+				missingValue = false
+				eval = eval[:0]
+				continue
+			}
+			isEval = false
+			if missingValue {
+				toResolve = append(toResolve, unsolvedVariable)
+			} else {
+				// we have to append our accumulator
+				toResolve = append(toResolve, w.SumString(eval))
+			}
+			continue
+		}
+		cID, vID, visibility := log.ToResolve[j].Unpack()
+
+		if isEval {
+			// we are evaluating
+			if visibility != compiled.Virtual && !s.Solved[vID] {
+				missingValue = true
+				continue
+			}
+			eval = append(eval, log.ToResolve[j])
+			continue
+		}
+
+		if visibility == compiled.Virtual {
+			// it's just a constant
+			if cID == compiled.CoeffIdMinusOne {
+				toResolve = append(toResolve, "-1")
+			} else {
+				toResolve = append(toResolve, w.CoefficientString(cID))
+			}
+			continue
+		}
+		if !(cID == compiled.CoeffIdMinusOne || cID == compiled.CoeffIdOne) {
+			toResolve = append(toResolve, w.CoefficientString(cID))
+		}
+		if !s.Solved[vID] {
+			toResolve = append(toResolve, unsolvedVariable)
+		} else {
+			toResolve = append(toResolve, w.WireString(vID))
+		}
+	}
+	return fmt.Sprintf(log.Format, toResolve...)
+}
+
+// CheckAssignment evaluates the constraints with isSatisfied, in parallel chunks, and returns the
+// bitmap of the satisfied constraints. With maxFailures > 0, the chunks stop once maxFailures
+// constraints are found unsatisfied.
+func CheckAssignment(nbConstraints, maxFailures int, isSatisfied func(i int) bool) ([]bool, error) {
+	satisfied := make([]bool, nbConstraints)
+	var nbUnsatisfied, nbChecked int64
+
+	nbTasks := runtime.NumCPU()
+	chunkSize := (nbConstraints + nbTasks - 1) / nbTasks
+	var wg sync.WaitGroup
+	for start := 0; start < nbConstraints; start += chunkSize {
+		end := start + chunkSize
+		if end > nbConstraints {
+			end = nbConstraints
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			i := start
+			for ; i < end; i++ {
+				if maxFailures > 0 && atomic.LoadInt64(&nbUnsatisfied) >= int64(maxFailures) {
+					break
+				}
+				if satisfied[i] = isSatisfied(i); !satisfied[i] {
+					atomic.AddInt64(&nbUnsatisfied, 1)
+				}
+			}
+			atomic.AddInt64(&nbChecked, int64(i-start))
+		}(start, end)
+	}
+	wg.Wait()
+
+	if nbUnsatisfied != 0 {
+		return satisfied, &backend.UnsatisfiedAssignmentError{
+			NbUnsatisfied: int(nbUnsatisfied),
+			NbChecked:     int(nbChecked),
+			NbConstraints: nbConstraints,
+		}
+	}
+	return satisfied, nil
+}
+
+var bigIntPool = sync.Pool{
+	New: func() interface{} {
+		return new(big.Int)
+	},
+}
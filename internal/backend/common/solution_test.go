@@ -0,0 +1,225 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/stretchr/testify/require"
+)
+
+// bigWires implements Wires with big.Int values, modulo the scalar field of BN254
+type bigWires struct {
+	s            *Solution
+	values       []*big.Int
+	coefficients []*big.Int
+	modulus      *big.Int
+}
+
+func newBigWires(t *testing.T, nbWires int, hintFunctions ...hint.AnnotatedFunction) *bigWires {
+	s, err := NewSolution(ecc.BN254, nbWires, nil, nil, backend.ProverOption{HintFunctions: hintFunctions})
+	require.NoError(t, err)
+	w := &bigWires{
+		s:            &s,
+		values:       make([]*big.Int, nbWires),
+		coefficients: []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), big.NewInt(-1), big.NewInt(3)},
+		modulus:      ecc.BN254.Info().Fr.Modulus(),
+	}
+	w.coefficients[3].Mod(w.coefficients[3], w.modulus)
+	return w
+}
+
+func (w *bigWires) TermValue(t compiled.Term, res *big.Int) {
+	cID, vID, visibility := t.Unpack()
+	res.Set(w.coefficients[cID])
+	if visibility != compiled.Virtual {
+		res.Mul(res, w.values[vID]).Mod(res, w.modulus)
+	}
+}
+
+func (w *bigWires) SetValue(id int, v *big.Int) error {
+	w.values[id] = new(big.Int).Mod(v, w.modulus)
+	w.s.MarkSolved(id)
+	return nil
+}
+
+func (w *bigWires) CoefficientString(cID int) string {
+	return w.coefficients[cID].String()
+}
+
+func (w *bigWires) WireString(vID int) string {
+	return w.values[vID].String()
+}
+
+func (w *bigWires) SumString(terms []compiled.Term) string {
+	var sum, v big.Int
+	for _, t := range terms {
+		w.TermValue(t, &v)
+		sum.Add(&sum, &v)
+	}
+	return sum.Mod(&sum, w.modulus).String()
+}
+
+func TestSolveWithHint(t *testing.T) {
+	assert := require.New(t)
+
+	w := newBigWires(t, 3)
+	assert.NoError(w.SetValue(0, big.NewInt(5)))
+
+	// wire 1 = 1 / (3 * wire 0 + 2)
+	h := compiled.Hint{
+		ID:     hint.InvModNamed.UUID(),
+		Inputs: []compiled.LinearExpression{{compiled.Pack(0, 4, compiled.Internal), compiled.Pack(0, 2, compiled.Virtual)}},
+		Wires:  []int{1},
+	}
+	assert.NoError(w.s.SolveWithHint(w, 1, h))
+	var expected big.Int
+	expected.ModInverse(big.NewInt(17), w.modulus)
+	assert.Equal(expected.String(), w.values[1].String())
+	assert.Equal(2, w.s.NbSolved)
+	assert.False(w.s.IsValid())
+
+	// the inputs must be solved
+	h.Inputs = []compiled.LinearExpression{{compiled.Pack(2, compiled.CoeffIdOne, compiled.Internal)}}
+	h.Wires = []int{0}
+	assert.Error(w.s.SolveWithHint(w, 0, h))
+
+	// unknown hint
+	h.ID, h.Name = 42, "unknown"
+	err := w.s.SolveWithHint(w, 2, h)
+	assert.Error(err)
+	assert.Contains(err.Error(), "hint 'unknown' not found")
+}
+
+func TestSolveWithHintErrors(t *testing.T) {
+	assert := require.New(t)
+
+	panicking := hint.NewFixedHintNamed("test/panic", func(_ ecc.ID, inputs, outputs []*big.Int) error {
+		panic("boom")
+	}, 0, 1)
+	sleeping := hint.NewFixedHintNamed("test/sleep", func(_ ecc.ID, inputs, outputs []*big.Int) error {
+		time.Sleep(time.Second)
+		return nil
+	}, 0, 1)
+
+	// a panic of the hint is an error
+	w := newBigWires(t, 1, panicking, sleeping)
+	err := w.s.SolveWithHint(w, 0, compiled.Hint{ID: panicking.UUID(), Wires: []int{0}})
+	assert.Error(err)
+	assert.Contains(err.Error(), "panic: boom")
+
+	// the hint timeout
+	s, err := NewSolution(ecc.BN254, 1, nil, nil, backend.ProverOption{HintFunctions: []hint.AnnotatedFunction{sleeping}, HintTimeout: 10 * time.Millisecond})
+	assert.NoError(err)
+	w.s = &s
+	err = w.s.SolveWithHint(w, 0, compiled.Hint{ID: sleeping.UUID(), Wires: []int{0}})
+	assert.True(errors.Is(err, backend.ErrHintTimeout), "%v", err)
+	assert.False(w.s.Solved[0])
+}
+
+func TestNewSolutionReferencedHints(t *testing.T) {
+	assert := require.New(t)
+
+	expected := hint.NewFixedHintNamed("test/expected", func(_ ecc.ID, inputs, outputs []*big.Int) error { return nil }, 1, 1)
+	extra := hint.NewFixedHintNamed("test/extra", func(_ ecc.ID, inputs, outputs []*big.Int) error { return nil }, 1, 1)
+	referenced := map[hint.ID]string{expected.UUID(): "test/expected", hint.InvModNamed.UUID(): "gnark/invmod"}
+
+	s, err := NewSolution(ecc.BN254, 1, nil, referenced, backend.ProverOption{HintFunctions: []hint.AnnotatedFunction{expected}})
+	assert.NoError(err)
+	_, ok := s.HintFunction(expected.UUID())
+	assert.True(ok)
+	_, ok = s.HintFunction(hint.InvModNamed.UUID())
+	assert.True(ok)
+	// the builtins the constraint system doesn't reference are not available
+	_, ok = s.HintFunction(hint.IthBitNamed.UUID())
+	assert.False(ok)
+
+	// a hint function the constraint system doesn't reference is rejected
+	_, err = NewSolution(ecc.BN254, 1, nil, referenced, backend.ProverOption{HintFunctions: []hint.AnnotatedFunction{expected, extra}})
+	var unexpected *backend.UnexpectedHintsError
+	assert.True(errors.As(err, &unexpected), "%v", err)
+	assert.True(errors.Is(err, backend.ErrUnexpectedHint))
+	assert.Len(unexpected.Hints, 1)
+	assert.Contains(unexpected.Hints[0], "test/extra")
+
+	// unless the hints are unsafe
+	s, err = NewSolution(ecc.BN254, 1, nil, referenced, backend.ProverOption{HintFunctions: []hint.AnnotatedFunction{expected, extra}, UnsafeHints: true})
+	assert.NoError(err)
+	_, ok = s.HintFunction(extra.UUID())
+	assert.True(ok)
+}
+
+func TestLogValue(t *testing.T) {
+	assert := require.New(t)
+
+	w := newBigWires(t, 3)
+	assert.NoError(w.SetValue(0, big.NewInt(5)))
+	assert.NoError(w.SetValue(1, big.NewInt(7)))
+
+	log := compiled.LogEntry{
+		Format: "%s %s %s*%s %s %s",
+		ToResolve: []compiled.Term{
+			compiled.Pack(0, compiled.CoeffIdOne, compiled.Internal),
+			compiled.Pack(0, compiled.CoeffIdMinusOne, compiled.Virtual),
+			compiled.Pack(1, 4, compiled.Internal),
+			// (3*wire 0 + wire 1 + 2)
+			compiled.TermDelimitor,
+			compiled.Pack(0, 4, compiled.Internal),
+			compiled.Pack(1, compiled.CoeffIdOne, compiled.Internal),
+			compiled.Pack(0, compiled.CoeffIdTwo, compiled.Virtual),
+			compiled.TermDelimitor,
+			// unsolved
+			compiled.Pack(2, compiled.CoeffIdOne, compiled.Internal),
+		},
+	}
+	assert.Equal("5 -1 3*7 24 <unsolved>", w.s.LogValue(w, log))
+
+	var sb strings.Builder
+	w.s.PrintLogs(&sb, w, []compiled.LogEntry{log, log})
+	assert.Equal("5 -1 3*7 24 <unsolved>5 -1 3*7 24 <unsolved>", sb.String())
+}
+
+func TestCheckAssignment(t *testing.T) {
+	assert := require.New(t)
+
+	const n = 1000
+	satisfied, err := CheckAssignment(n, 0, func(i int) bool { return true })
+	assert.NoError(err)
+	assert.Len(satisfied, n)
+
+	satisfied, err = CheckAssignment(n, 0, func(i int) bool { return i%100 != 0 })
+	var summary *backend.UnsatisfiedAssignmentError
+	assert.True(errors.As(err, &summary))
+	assert.Equal(backend.UnsatisfiedAssignmentError{NbUnsatisfied: 10, NbChecked: n, NbConstraints: n}, *summary)
+	assert.False(satisfied[100])
+	assert.True(satisfied[101])
+
+	_, err = CheckAssignment(n, 1, func(i int) bool { return false })
+	assert.True(errors.As(err, &summary))
+	assert.GreaterOrEqual(summary.NbUnsatisfied, 1)
+	assert.Less(summary.NbChecked, n)
+
+	satisfied, err = CheckAssignment(0, 0, func(i int) bool { return false })
+	assert.NoError(err)
+	assert.Empty(satisfied)
+}
@@ -0,0 +1,222 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+)
+
+// MaxProofComponentSize is the maximum size of a component of a streamed proof
+const MaxProofComponentSize = 1 << 20
+
+// proofStreamMagic prefixes the streamed proofs
+var proofStreamMagic = []byte{'g', 'n', 'a', 'r', 'k', 's', 't', 'm'}
+
+// ProofStreamWriter writes the components of a proof to a stream, in frames, as they are computed:
+//
+//	magic | backend (uint16) | curve (uint16) | frame | frame | ... | 0 (uint8) | crc32 (uint32)
+//
+// where a frame is: name size (uint8) | name | component size (uint32) | component, and the checksum
+// covers all the bytes before it. A stream without its checksum is truncated: the prover failed, or
+// the transfer was interrupted.
+type ProofStreamWriter struct {
+	w         io.Writer
+	crc       hash.Hash32
+	backendID backend.ID
+	curveID   ecc.ID
+	started   bool
+}
+
+// NewProofStreamWriter returns a ProofStreamWriter of the proofs of backendID on curveID to w. The
+// header is written with the first component.
+func NewProofStreamWriter(w io.Writer, backendID backend.ID, curveID ecc.ID) *ProofStreamWriter {
+	crc := crc32.NewIEEE()
+	return &ProofStreamWriter{
+		w:         io.MultiWriter(w, crc),
+		crc:       crc,
+		backendID: backendID,
+		curveID:   curveID,
+	}
+}
+
+// WriteComponent writes the frame of a component of the proof, encoded as in Proof.WriteTo
+func (s *ProofStreamWriter) WriteComponent(name string, data []byte) error {
+	if len(name) == 0 || len(name) > 255 {
+		return fmt.Errorf("invalid proof component name %q", name)
+	}
+	if len(data) > MaxProofComponentSize {
+		return fmt.Errorf("proof component %s exceeds %d bytes", name, MaxProofComponentSize)
+	}
+	if err := s.writeHeader(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(uint8(len(name)))
+	buf.WriteString(name)
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+	buf.Write(data)
+	_, err := s.w.Write(buf.Bytes())
+	return err
+}
+
+// ProverOption returns the Prover option writing the components of the proof, before calling the
+// function set with backend.WithProofComponents, if any
+func (s *ProofStreamWriter) ProverOption() func(opt *backend.ProverOption) error {
+	return func(opt *backend.ProverOption) error {
+		next := opt.ProofComponents
+		opt.ProofComponents = func(name string, data []byte) error {
+			if err := s.WriteComponent(name, data); err != nil {
+				return err
+			}
+			if next != nil {
+				return next(name, data)
+			}
+			return nil
+		}
+		return nil
+	}
+}
+
+// Close writes the end of the stream and its checksum, once all the components are written
+func (s *ProofStreamWriter) Close() error {
+	if err := s.writeHeader(); err != nil {
+		return err
+	}
+	if _, err := s.w.Write([]byte{0}); err != nil {
+		return err
+	}
+	return binary.Write(s.w, binary.BigEndian, s.crc.Sum32())
+}
+
+func (s *ProofStreamWriter) writeHeader() error {
+	if s.started {
+		return nil
+	}
+	s.started = true
+	var buf bytes.Buffer
+	buf.Write(proofStreamMagic)
+	_ = binary.Write(&buf, binary.BigEndian, uint16(s.backendID))
+	_ = binary.Write(&buf, binary.BigEndian, uint16(s.curveID))
+	_, err := s.w.Write(buf.Bytes())
+	return err
+}
+
+// ReadProofStream reads a stream written by a ProofStreamWriter of the proofs of backendID, and returns
+// the curve of the proof and its components by name. It fails with backend.ErrInvalidProofStream
+// (wrapped) if the stream is truncated or corrupted, or if a component is repeated.
+func ReadProofStream(r io.Reader, backendID backend.ID) (ecc.ID, map[string][]byte, error) {
+	crc := crc32.NewIEEE()
+	br := bufio.NewReader(r)
+	tr := io.TeeReader(br, crc)
+
+	header := make([]byte, len(proofStreamMagic)+4)
+	if _, err := io.ReadFull(tr, header); err != nil {
+		return ecc.UNKNOWN, nil, streamError(err)
+	}
+	if string(header[:len(proofStreamMagic)]) != string(proofStreamMagic) {
+		return ecc.UNKNOWN, nil, fmt.Errorf("%w: missing magic", backend.ErrInvalidProofStream)
+	}
+	header = header[len(proofStreamMagic):]
+	if id := backend.ID(binary.BigEndian.Uint16(header[:2])); id != backendID {
+		return ecc.UNKNOWN, nil, fmt.Errorf("%w: proof of %s, expected %s", backend.ErrInvalidProofStream, id, backendID)
+	}
+	curveID := ecc.ID(binary.BigEndian.Uint16(header[2:]))
+	switch curveID {
+	case ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BW6_761, ecc.BW6_633, ecc.BLS24_315:
+	default:
+		return ecc.UNKNOWN, nil, fmt.Errorf("%w: unknown curve", backend.ErrInvalidProofStream)
+	}
+
+	components := make(map[string][]byte)
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(tr, size[:1]); err != nil {
+			return ecc.UNKNOWN, nil, streamError(err)
+		}
+		if size[0] == 0 {
+			break
+		}
+		name := make([]byte, size[0])
+		if _, err := io.ReadFull(tr, name); err != nil {
+			return ecc.UNKNOWN, nil, streamError(err)
+		}
+		if _, err := io.ReadFull(tr, size[:]); err != nil {
+			return ecc.UNKNOWN, nil, streamError(err)
+		}
+		n := binary.BigEndian.Uint32(size[:])
+		if n > MaxProofComponentSize {
+			return ecc.UNKNOWN, nil, fmt.Errorf("%w: component %s exceeds %d bytes", backend.ErrInvalidProofStream, name, MaxProofComponentSize)
+		}
+		if _, ok := components[string(name)]; ok {
+			return ecc.UNKNOWN, nil, fmt.Errorf("%w: repeated component %s", backend.ErrInvalidProofStream, name)
+		}
+		data := make([]byte, n)
+		if _, err := io.ReadFull(tr, data); err != nil {
+			return ecc.UNKNOWN, nil, streamError(err)
+		}
+		components[string(name)] = data
+	}
+
+	// the checksum isn't part of what it covers
+	expected := crc.Sum32()
+	var checksum [4]byte
+	if _, err := io.ReadFull(br, checksum[:]); err != nil {
+		return ecc.UNKNOWN, nil, streamError(err)
+	}
+	if binary.BigEndian.Uint32(checksum[:]) != expected {
+		return ecc.UNKNOWN, nil, fmt.Errorf("%w: checksum mismatch", backend.ErrInvalidProofStream)
+	}
+	return curveID, components, nil
+}
+
+// AssembleProofStream returns the concatenation of the components, in the order of names: the encoding
+// of the proof with Proof.WriteTo. The components of optional are not required. It fails with
+// backend.ErrInvalidProofStream (wrapped) if a required component is missing, or if a component is unknown.
+func AssembleProofStream(components map[string][]byte, names []string, optional map[string]bool) ([]byte, error) {
+	known := make(map[string]bool, len(names))
+	var res []byte
+	for _, name := range names {
+		known[name] = true
+		data, ok := components[name]
+		if !ok && !optional[name] {
+			return nil, fmt.Errorf("%w: missing component %s", backend.ErrInvalidProofStream, name)
+		}
+		res = append(res, data...)
+	}
+	for name := range components {
+		if !known[name] {
+			return nil, fmt.Errorf("%w: unknown component %s", backend.ErrInvalidProofStream, name)
+		}
+	}
+	return res, nil
+}
+
+// streamError returns the error of a read of a stream
+func streamError(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: truncated", backend.ErrInvalidProofStream)
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/internal/backend/compiled"
+)
+
+// AnalyzeR1CSWitness returns the distribution of the values of the wires of r1cs per multi exponentiation
+// of the Groth16 prover, see backend.WitnessAnalysis. value(i) returns the limbs (little endian) of the
+// value of the wire i in regular form; nbBits is the bit size of the scalar field.
+func AnalyzeR1CSWitness(r1cs *compiled.R1CS, nbBits int, value func(i int) []uint64) *backend.WitnessAnalysis {
+	nbWires := r1cs.NbInternalVariables + r1cs.NbPublicVariables + r1cs.NbSecretVariables
+
+	// the wires of the L and R linear expressions, whose points of the proving key are not at infinity
+	inL, inR := make([]bool, nbWires), make([]bool, nbWires)
+	var r1c compiled.R1C
+	for i := 0; i < r1cs.Constraints.Len(); i++ {
+		r1cs.Constraints.Load(i, &r1c)
+		for _, t := range r1c.L {
+			inL[t.VariableID()] = true
+		}
+		for _, t := range r1c.R {
+			inR[t.VariableID()] = true
+		}
+	}
+
+	a := &backend.WitnessAnalysis{
+		NbWires: nbWires,
+		NbBits:  nbBits,
+		Regions: []backend.WitnessRegion{{Name: "A"}, {Name: "B"}, {Name: "C"}},
+	}
+	regionA, regionB, regionC := &a.Regions[0].ScalarCounts, &a.Regions[1].ScalarCounts, &a.Regions[2].ScalarCounts
+	for i := 0; i < nbWires; i++ {
+		class := scalarClassOf(value(i))
+		class.count(&a.Wires)
+		if inL[i] {
+			class.count(regionA)
+		}
+		if inR[i] {
+			class.count(regionB)
+		}
+		if i >= r1cs.NbPublicVariables {
+			class.count(regionC)
+		}
+	}
+	for i := range a.Regions {
+		a.Regions[i].Cost = backend.MultiExpCost(a.Regions[i].ScalarCounts, nbBits)
+	}
+	return a
+}
+
+// scalarClass is the size of a scalar, see backend.ScalarCounts
+type scalarClass uint8
+
+const (
+	zeroScalar scalarClass = iota
+	oneScalar
+	smallScalar
+	fullScalar
+)
+
+// scalarClassOf returns the class of the scalar of limbs (little endian)
+func scalarClassOf(limbs []uint64) scalarClass {
+	for _, l := range limbs[1:] {
+		if l != 0 {
+			return fullScalar
+		}
+	}
+	switch {
+	case limbs[0] == 0:
+		return zeroScalar
+	case limbs[0] == 1:
+		return oneScalar
+	case limbs[0] < 1<<backend.SmallScalarBits:
+		return smallScalar
+	default:
+		return fullScalar
+	}
+}
+
+func (c scalarClass) count(counts *backend.ScalarCounts) {
+	switch c {
+	case zeroScalar:
+		counts.Zero++
+	case oneScalar:
+		counts.One++
+	case smallScalar:
+		counts.Small++
+	default:
+		counts.Full++
+	}
+}
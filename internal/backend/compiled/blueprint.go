@@ -0,0 +1,345 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiled
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrTooManyTerms is returned when the constraints of a R1CS have more than MaxNbTerms terms
+var ErrTooManyTerms = errors.New("too many terms: a R1CList can't address more than 2^32 terms")
+
+// MaxNbTerms is the capacity of a R1CList, in terms
+const MaxNbTerms = math.MaxUint32
+
+// R1CList is a compact list of R1C. The constraints with the same shape (the coefficients and
+// visibilities of their terms, in order) are instances of a shared Blueprint, and store only their
+// wire IDs: a constraint of n terms takes 8 + 4n bytes, instead of 72 + 8n bytes for a R1C.
+//
+// The shapes are detected when the constraints are appended. A circuit repeating a gadget (hash
+// function, binary decomposition, ...) has a few hundred shapes for millions of constraints.
+type R1CList struct {
+	Blueprints []Blueprint
+	Instances  []Instance
+	Wires      WireList // wire IDs of the terms of the instances, in order
+
+	shapes map[string]int // index of the blueprint of each shape, see Append
+	key    []byte         // buffer of Append
+}
+
+// Blueprint is a constraint shape: the terms of the L, R and O linear expressions of its instances,
+// with the wire IDs set to 0
+type Blueprint struct {
+	Terms    []Term // L | R | O
+	NbL, NbR int
+
+	// Shape selects the fast paths of the solvers for the instances, see R1CShape. It is set when the
+	// blueprint is created; the blueprints of a list serialized before it existed are generic.
+	Shape R1CShape `cbor:",omitempty"`
+}
+
+// LinearShape is the shape of a linear expression, for the fast paths of the solvers: the common
+// expressions of one or two terms are evaluated without the generic loop over the terms
+type LinearShape uint8
+
+const (
+	ShapeGeneric LinearShape = iota // any linear expression, evaluated term by term
+	ShapeOne                        // a single term, of coefficient 1
+	ShapeTerm                       // a single term, of another coefficient (but 0)
+	ShapeSumOne                     // two terms, of coefficient 1
+)
+
+// ShapeOf returns the shape of the linear expression e
+func ShapeOf(e LinearExpression) LinearShape {
+	switch len(e) {
+	case 1:
+		switch e[0].CoeffID() {
+		case CoeffIdOne:
+			return ShapeOne
+		case CoeffIdZero:
+			return ShapeGeneric
+		default:
+			return ShapeTerm
+		}
+	case 2:
+		if e[0].CoeffID() == CoeffIdOne && e[1].CoeffID() == CoeffIdOne {
+			return ShapeSumOne
+		}
+	}
+	return ShapeGeneric
+}
+
+// R1CShape is a small tag holding the shapes of the L, R and O linear expressions of a constraint, 2
+// bits each. The solvers take a fast path for the constraints whose expressions all have a non generic
+// shape, see Fast.
+type R1CShape uint8
+
+// NewR1CShape returns the tag of the shapes l, r and o
+func NewR1CShape(l, r, o LinearShape) R1CShape {
+	return R1CShape(l) | R1CShape(r)<<2 | R1CShape(o)<<4
+}
+
+// ShapeOfR1C returns the tag of the shapes of the linear expressions of r1c
+func ShapeOfR1C(r1c R1C) R1CShape {
+	return NewR1CShape(ShapeOf(r1c.L), ShapeOf(r1c.R), ShapeOf(r1c.O))
+}
+
+// L returns the shape of the L linear expression
+func (s R1CShape) L() LinearShape {
+	return LinearShape(s & 0b11)
+}
+
+// R returns the shape of the R linear expression
+func (s R1CShape) R() LinearShape {
+	return LinearShape(s >> 2 & 0b11)
+}
+
+// O returns the shape of the O linear expression
+func (s R1CShape) O() LinearShape {
+	return LinearShape(s >> 4 & 0b11)
+}
+
+// Fast returns true if none of the linear expressions is generic
+func (s R1CShape) Fast() bool {
+	return s.L() != ShapeGeneric && s.R() != ShapeGeneric && s.O() != ShapeGeneric
+}
+
+// NbTerms returns the number of terms of the linear expressions of shape l
+func (l LinearShape) NbTerms() int {
+	if l == ShapeSumOne {
+		return 2
+	}
+	return 1
+}
+
+// Instance is a constraint of a R1CList
+type Instance struct {
+	Blueprint uint32 // index in R1CList.Blueprints
+	Offset    uint32 // index in R1CList.Wires of the wire ID of the first term
+}
+
+// WireList is a list of wire IDs. It is serialized as a byte string of 4 bytes per wire, which is
+// more compact than an array, and not bound by the maximum number of elements of a decoded array.
+type WireList []uint32
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (w WireList) MarshalBinary() ([]byte, error) {
+	res := make([]byte, 4*len(w))
+	for i, id := range w {
+		binary.BigEndian.PutUint32(res[4*i:], id)
+	}
+	return res, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (w *WireList) UnmarshalBinary(data []byte) error {
+	if len(data)%4 != 0 {
+		return fmt.Errorf("invalid wire list: %d bytes", len(data))
+	}
+	*w = make(WireList, len(data)/4)
+	for i := range *w {
+		(*w)[i] = binary.BigEndian.Uint32(data[4*i:])
+	}
+	return nil
+}
+
+// NewR1CList returns the compact list of the constraints. It panics with ErrTooManyTerms if the
+// constraints have more than MaxNbTerms terms.
+func NewR1CList(constraints []R1C) R1CList {
+	return NewR1CListWithLoops(constraints, nil)
+}
+
+// NewR1CListWithLoops is NewR1CList for the constraints of a circuit with loops (see Loops): the
+// constraint at the same position in the previous iteration of a loop is the first candidate for the
+// blueprint of a constraint, compared before the shape is looked up.
+func NewR1CListWithLoops(constraints []R1C, loops *Loops) R1CList {
+	var l R1CList
+	l.Instances = make([]Instance, 0, len(constraints))
+	var candidates []int
+	if loops != nil {
+		candidates = loops.candidates(len(constraints))
+	}
+	for i, r1c := range constraints {
+		blueprint := -1
+		if candidates != nil && candidates[i] != -1 {
+			blueprint = int(l.Instances[candidates[i]].Blueprint)
+		}
+		l.append(r1c, blueprint)
+	}
+
+	// the index of the shapes is rebuilt by the next Append, if any
+	l.shapes, l.key = nil, nil
+	return l
+}
+
+// Len returns the number of constraints in the list
+func (l *R1CList) Len() int {
+	return len(l.Instances)
+}
+
+// Append adds a copy of r1c at the end of the list. It panics with ErrTooManyTerms if the list
+// holds more than MaxNbTerms terms.
+func (l *R1CList) Append(r1c R1C) {
+	l.append(r1c, -1)
+}
+
+// append appends r1c, trying the blueprint candidate first if it isn't -1
+func (l *R1CList) append(r1c R1C, candidate int) {
+	nbTerms := len(r1c.L) + len(r1c.R) + len(r1c.O)
+	if len(l.Wires)+nbTerms > MaxNbTerms {
+		panic(ErrTooManyTerms)
+	}
+
+	// the key of the shape is built in l.key, without allocation if the shape is known
+	if l.shapes == nil {
+		l.indexShapes()
+	}
+	b, ok := candidate, candidate != -1 && l.Blueprints[candidate].matches(r1c)
+	if !ok {
+		l.key = appendShapeKey(l.key[:0], len(r1c.L), len(r1c.R), r1c.L, r1c.R, r1c.O)
+		b, ok = l.shapes[string(l.key)]
+	}
+	if !ok {
+		b = len(l.Blueprints)
+		blueprint := Blueprint{Terms: make([]Term, 0, nbTerms), NbL: len(r1c.L), NbR: len(r1c.R), Shape: ShapeOfR1C(r1c)}
+		for _, e := range [3]LinearExpression{r1c.L, r1c.R, r1c.O} {
+			for _, t := range e {
+				blueprint.Terms = append(blueprint.Terms, t&^Term(maskVariableID))
+			}
+		}
+		l.Blueprints = append(l.Blueprints, blueprint)
+		l.shapes[string(l.key)] = b
+	}
+
+	l.Instances = append(l.Instances, Instance{Blueprint: uint32(b), Offset: uint32(len(l.Wires))})
+	for _, e := range [3]LinearExpression{r1c.L, r1c.R, r1c.O} {
+		for _, t := range e {
+			l.Wires = append(l.Wires, uint32(t.VariableID()))
+		}
+	}
+}
+
+// Get returns the constraint i, in newly allocated linear expressions
+func (l *R1CList) Get(i int) R1C {
+	var r1c R1C
+	l.Load(i, &r1c)
+	return r1c
+}
+
+// Load sets r1c to the constraint i, reusing the linear expressions of r1c: the solver and the
+// setup expand the constraints one at a time in the same R1C, without allocation.
+func (l *R1CList) Load(i int, r1c *R1C) {
+	instance := l.Instances[i]
+	b := &l.Blueprints[instance.Blueprint]
+	wires := l.Wires[instance.Offset : int(instance.Offset)+len(b.Terms)]
+
+	expand := func(e LinearExpression, terms []Term, wires []uint32) LinearExpression {
+		if len(terms) == 0 {
+			return e[:0]
+		}
+		e = append(e[:0], terms...)
+		for j := range e {
+			e[j] |= Term(wires[j])
+		}
+		return e
+	}
+	nbLR := b.NbL + b.NbR
+	r1c.L = expand(r1c.L, b.Terms[:b.NbL], wires[:b.NbL])
+	r1c.R = expand(r1c.R, b.Terms[b.NbL:nbLR], wires[b.NbL:nbLR])
+	r1c.O = expand(r1c.O, b.Terms[nbLR:], wires[nbLR:])
+}
+
+// Shape returns the tag of the shapes of the constraint i, and the terms of its blueprint and its wire
+// IDs (L | R | O): the fast paths of the solver read the constraint without expanding it.
+func (l *R1CList) Shape(i int) (shape R1CShape, terms []Term, wires []uint32) {
+	instance := l.Instances[i]
+	b := &l.Blueprints[instance.Blueprint]
+	return b.Shape, b.Terms, l.Wires[instance.Offset : int(instance.Offset)+len(b.Terms)]
+}
+
+// CheckShapes returns an error if the tag of a blueprint isn't generic nor the one of its terms. The
+// solvers trust the tags: they are checked when the list is decoded.
+func (l *R1CList) CheckShapes() error {
+	for i := range l.Blueprints {
+		b := &l.Blueprints[i]
+		if b.Shape == 0 {
+			continue
+		}
+		if b.NbL < 0 || b.NbR < 0 || b.NbL+b.NbR > len(b.Terms) {
+			return fmt.Errorf("invalid blueprint %d: %d terms, %d in L and %d in R", i, len(b.Terms), b.NbL, b.NbR)
+		}
+		nbLR := b.NbL + b.NbR
+		r1c := R1C{L: b.Terms[:b.NbL], R: b.Terms[b.NbL:nbLR], O: b.Terms[nbLR:]}
+		if shape := ShapeOfR1C(r1c); shape != b.Shape {
+			return fmt.Errorf("invalid blueprint %d: shape %#x, expected %#x", i, b.Shape, shape)
+		}
+	}
+	return nil
+}
+
+// All returns all the constraints, in newly allocated linear expressions
+func (l *R1CList) All() []R1C {
+	res := make([]R1C, l.Len())
+	for i := range res {
+		l.Load(i, &res[i])
+	}
+	return res
+}
+
+// matches returns true if r1c is an instance of the blueprint
+func (b *Blueprint) matches(r1c R1C) bool {
+	if b.NbL != len(r1c.L) || b.NbR != len(r1c.R) || len(b.Terms) != b.NbL+b.NbR+len(r1c.O) {
+		return false
+	}
+	terms := b.Terms
+	for _, e := range [3]LinearExpression{r1c.L, r1c.R, r1c.O} {
+		for j, t := range e {
+			if t&^Term(maskVariableID) != terms[j] {
+				return false
+			}
+		}
+		terms = terms[len(e):]
+	}
+	return true
+}
+
+// indexShapes rebuilds the index of the shapes, for a list decoded without it
+func (l *R1CList) indexShapes() {
+	l.shapes = make(map[string]int, len(l.Blueprints))
+	for i, b := range l.Blueprints {
+		key := appendShapeKey(nil, b.NbL, b.NbR, b.Terms)
+		l.shapes[string(key)] = i
+	}
+}
+
+// appendShapeKey appends to key the key of a shape in R1CList.shapes: the lengths of L and R, and the
+// terms without their wire ID
+func appendShapeKey(key []byte, nbL, nbR int, terms ...LinearExpression) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(nbL))
+	key = append(key, buf[:]...)
+	binary.LittleEndian.PutUint64(buf[:], uint64(nbR))
+	key = append(key, buf[:]...)
+	for _, e := range terms {
+		for _, t := range e {
+			binary.LittleEndian.PutUint64(buf[:], uint64(t)&^maskVariableID)
+			key = append(key, buf[:]...)
+		}
+	}
+	return key
+}
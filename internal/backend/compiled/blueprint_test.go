@@ -0,0 +1,233 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compiled_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/consensys/gnark/test"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// sha256Circuit checks that Digest is the SHA-256 hash of a message of one block: Block is the
+// padded message, in big endian words
+type sha256Circuit struct {
+	Block  [16]frontend.Variable
+	Digest [8]frontend.Variable `gnark:",public"`
+}
+
+var sha256K = [64]uint32{
+	0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+	0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+	0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+	0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+	0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+	0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+	0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+	0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2,
+}
+
+var sha256H0 = [8]uint32{0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19}
+
+// sha256Word is a 32 bits word, least significant bit first
+type sha256Word [32]frontend.Variable
+
+func (circuit *sha256Circuit) Define(curveID ecc.ID, api frontend.API) error {
+	constant := func(x uint32) (w sha256Word) {
+		for i := 0; i < 32; i++ {
+			w[i] = api.Constant(uint64(x>>i) & 1)
+		}
+		return
+	}
+	toWord := func(x frontend.Variable, nbBits int) (w sha256Word) {
+		copy(w[:], api.ToBinary(x, nbBits))
+		return
+	}
+	fromWord := func(w sha256Word) frontend.Variable {
+		res := api.Constant(0)
+		for i := 0; i < 32; i++ {
+			res = api.Add(res, api.Mul(1<<i, w[i]))
+		}
+		return res
+	}
+	// add returns the sum of the words modulo 2^32
+	add := func(ws ...sha256Word) sha256Word {
+		sum := api.Constant(0)
+		for _, w := range ws {
+			sum = api.Add(sum, fromWord(w))
+		}
+		return toWord(sum, 32+3) // at most 5 words
+	}
+	xor := func(a, b frontend.Variable) frontend.Variable {
+		return api.Sub(api.Add(a, b), api.Mul(2, api.Mul(a, b)))
+	}
+	// sigma returns rotr(x, r0) ^ rotr(x, r1) ^ (rotr or shr)(x, r2)
+	sigma := func(x sha256Word, r0, r1, r2 int, shift bool) (res sha256Word) {
+		for i := 0; i < 32; i++ {
+			res[i] = xor(x[(i+r0)%32], x[(i+r1)%32])
+			if !shift || i+r2 < 32 {
+				res[i] = xor(res[i], x[(i+r2)%32])
+			}
+		}
+		return
+	}
+
+	var w [64]sha256Word
+	for t := 0; t < 16; t++ {
+		w[t] = toWord(circuit.Block[t], 32)
+	}
+	for t := 16; t < 64; t++ {
+		w[t] = add(sigma(w[t-2], 17, 19, 10, true), w[t-7], sigma(w[t-15], 7, 18, 3, true), w[t-16])
+	}
+
+	var h [8]sha256Word
+	for i := range h {
+		h[i] = constant(sha256H0[i])
+	}
+	a, b, c, d, e, f, g, hh := h[0], h[1], h[2], h[3], h[4], h[5], h[6], h[7]
+	for t := 0; t < 64; t++ {
+		var ch, maj sha256Word
+		for i := 0; i < 32; i++ {
+			// ch = g + e(f - g), maj = ab + c(a + b - 2ab)
+			ch[i] = api.Add(g[i], api.Mul(e[i], api.Sub(f[i], g[i])))
+			ab := api.Mul(a[i], b[i])
+			maj[i] = api.Add(ab, api.Mul(c[i], api.Sub(api.Add(a[i], b[i]), api.Mul(2, ab))))
+		}
+		t1 := add(hh, sigma(e, 6, 11, 25, false), ch, constant(sha256K[t]), w[t])
+		t2 := add(sigma(a, 2, 13, 22, false), maj)
+		hh, g, f, e, d, c, b, a = g, f, e, add(d, t1), c, b, a, add(t1, t2)
+	}
+
+	for i, v := range [8]sha256Word{a, b, c, d, e, f, g, hh} {
+		api.AssertIsEqual(circuit.Digest[i], fromWord(add(h[i], v)))
+	}
+	return nil
+}
+
+// sha256Assignment returns the assignment of sha256Circuit for msg, shorter than 56 bytes
+func sha256Assignment(msg []byte) *sha256Circuit {
+	var block [64]byte
+	copy(block[:], msg)
+	block[len(msg)] = 0x80
+	binary.BigEndian.PutUint64(block[56:], uint64(8*len(msg)))
+	digest := sha256.Sum256(msg)
+
+	var witness sha256Circuit
+	for i := 0; i < 16; i++ {
+		witness.Block[i].Assign(uint64(binary.BigEndian.Uint32(block[4*i:])))
+	}
+	for i := 0; i < 8; i++ {
+		witness.Digest[i].Assign(uint64(binary.BigEndian.Uint32(digest[4*i:])))
+	}
+	return &witness
+}
+
+// TestBlueprintsSHA256 proves a SHA-256 hash with the compact R1CS, and checks that its thousands of
+// constraints are instances of a few blueprints which expand to the original constraints
+func TestBlueprintsSHA256(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	var circuit sha256Circuit
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+	r1cs := ccs.(*cs_bn254.R1CS)
+	assert.Less(len(r1cs.Constraints.Blueprints), 100)
+	assert.Greater(r1cs.GetNbConstraints(), 10000)
+
+	// the list expands to the constraints it was built from
+	assert.Equal(r1cs.Constraints, compiled.NewR1CList(r1cs.Constraints.All()))
+
+	// serialization round trip
+	var buf bytes.Buffer
+	_, err = r1cs.WriteTo(&buf)
+	assert.NoError(err)
+	var decoded cs_bn254.R1CS
+	_, err = decoded.ReadFrom(&buf)
+	assert.NoError(err)
+	assert.Equal(r1cs.Constraints, decoded.Constraints)
+
+	// a R1CS of another format version is rejected
+	decoded.Version = 0
+	buf.Reset()
+	_, err = decoded.WriteTo(&buf)
+	assert.NoError(err)
+	_, err = new(cs_bn254.R1CS).ReadFrom(&buf)
+	assert.Error(err)
+
+	good := sha256Assignment([]byte("gnark blueprints"))
+	bad := sha256Assignment([]byte("gnark blueprints"))
+	bad.Digest[0] = frontend.Variable{}
+	bad.Digest[0].Assign(42)
+
+	assert.ProverSucceeded(&circuit, good, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+	assert.ProverFailed(&circuit, bad, test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+}
+
+// BenchmarkBlueprints compiles the SHA-256 circuit, and reports the memory and the serialized size of
+// its constraints, stored as R1C and as a R1CList
+func BenchmarkBlueprints(b *testing.B) {
+	var circuit sha256Circuit
+	var ccs frontend.CompiledConstraintSystem
+	var err error
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if ccs, err = frontend.Compile(ecc.BN254, backend.GROTH16, &circuit); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	r1cs := ccs.(*cs_bn254.R1CS)
+
+	// 3 slice headers per R1C, and the terms
+	l := &r1cs.Constraints
+	constraints := l.All()
+	memR1C := 0
+	for _, r1c := range constraints {
+		memR1C += 3*24 + 8*(len(r1c.L)+len(r1c.R)+len(r1c.O))
+	}
+	memList := 8*len(l.Instances) + 4*len(l.Wires)
+	for _, bp := range l.Blueprints {
+		memList += 8*len(bp.Terms) + 40
+	}
+
+	sizeR1C, err := cbor.Marshal(constraints)
+	if err != nil {
+		b.Fatal(err)
+	}
+	sizeList, err := cbor.Marshal(l)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var ccsFile bytes.Buffer
+	if _, err := r1cs.WriteTo(&ccsFile); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportMetric(float64(r1cs.GetNbConstraints()), "constraints")
+	b.ReportMetric(float64(len(l.Blueprints)), "blueprints")
+	b.ReportMetric(float64(memR1C)/float64(memList), "memory-reduction")
+	b.ReportMetric(float64(len(sizeR1C))/float64(len(sizeList)), "size-reduction")
+	b.ReportMetric(float64(ccsFile.Len()+len(sizeR1C)-len(sizeList))/float64(ccsFile.Len()), "ccs-size-reduction")
+}
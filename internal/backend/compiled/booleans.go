@@ -0,0 +1,94 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiled
+
+import "math/bits"
+
+// BitSet is a set of non-negative integers (wire IDs, or indexes of boolean values), one bit each
+type BitSet []uint64
+
+// NewBitSet returns an empty BitSet able to hold the integers [0, n)
+func NewBitSet(n int) BitSet {
+	return make(BitSet, (n+63)/64)
+}
+
+// Get returns true if i is in the set
+func (b BitSet) Get(i int) bool {
+	return b[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+// Set adds i to the set
+func (b BitSet) Set(i int) {
+	b[i/64] |= 1 << (uint(i) % 64)
+}
+
+// Count returns the number of integers in the set
+func (b BitSet) Count() int {
+	n := 0
+	for _, w := range b {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// BooleanLayout maps the wires of a constraint system to the storage of a packed solution:
+// the values of the boolean wires (see CS.Booleans) are stored in a BitSet, the values of the
+// other wires in a vector of field elements, both in the order of the wires.
+type BooleanLayout struct {
+	booleans   BitSet
+	rank       []uint32 // rank[k] is the number of boolean wires in booleans[:k]
+	nbWires    int
+	nbBooleans int
+}
+
+// NewBooleanLayout returns the layout of a solution of nbWires wires, of which booleans are boolean
+func NewBooleanLayout(booleans BitSet, nbWires int) *BooleanLayout {
+	l := &BooleanLayout{
+		booleans: booleans,
+		rank:     make([]uint32, len(booleans)),
+		nbWires:  nbWires,
+	}
+	for k, w := range booleans {
+		l.rank[k] = uint32(l.nbBooleans)
+		l.nbBooleans += bits.OnesCount64(w)
+	}
+	return l
+}
+
+// IsBoolean returns true if the wire is boolean
+func (l *BooleanLayout) IsBoolean(wire int) bool {
+	return l.booleans.Get(wire)
+}
+
+// Index returns the index of the wire in the BitSet if it is boolean, or in the vector of
+// field elements otherwise
+func (l *BooleanLayout) Index(wire int) int {
+	k, r := wire/64, uint(wire)%64
+	before := int(l.rank[k]) + bits.OnesCount64(l.booleans[k]&(1<<r-1))
+	if l.booleans[k]&(1<<r) != 0 {
+		return before
+	}
+	return wire - before
+}
+
+// NbWires returns the number of wires of the layout
+func (l *BooleanLayout) NbWires() int {
+	return l.nbWires
+}
+
+// NbBooleans returns the number of boolean wires
+func (l *BooleanLayout) NbBooleans() int {
+	return l.nbBooleans
+}
@@ -1,9 +1,13 @@
 package compiled
 
 import (
+	"fmt"
 	"io"
+	"math/big"
+	"sort"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/hint"
 )
 
@@ -28,6 +32,40 @@ type CS struct {
 	// maps constraint id to debugInfo id
 	// several constraints may point to the same debug info
 	MDebug map[int]int
+
+	// iterations of the unrolled loops and their constraints (see frontend.API.LoopBegin),
+	// nil if the circuit doesn't record any
+	Loops *Loops `cbor:",omitempty"`
+
+	// scopes of the tags and their constraints (see frontend.API.Tag), nil if the circuit doesn't
+	// record any; serialized only if Tags.Serialize is set
+	Tags *Tags `cbor:",omitempty"`
+
+	// wires constrained to be boolean (R1CS only), see backend.WithPackedBooleans
+	Booleans BitSet
+
+	// named values of the circuit, see frontend.API.MarkOutput
+	Outputs []Output
+
+	// indexes (among the secret inputs, in increasing order) of the secret inputs tagged "optional",
+	// which a witness may omit
+	OptionalSecrets []int `cbor:",omitempty"`
+
+	// public inputs tagged "public,output", in increasing order of index: their wire is computed by
+	// the solver (a hint of the wire), instead of being read from the witness
+	PublicOutputs []PublicOutput `cbor:",omitempty"`
+
+	// user-defined description of the circuit (version, parameters, ...), copied in the keys at setup
+	Metadata map[string]string
+
+	// number of range checks per strategy which fired (see frontend.WithRangeCheckStrategy)
+	RangeChecks map[string]int `cbor:",omitempty"`
+
+	// number of R1C the elimination of the common subexpressions didn't add (see frontend.WithCSE)
+	NbEliminatedConstraints int `cbor:",omitempty"`
+
+	// number of hint input expressions reduced to a wire (see frontend.WithHintInputThreshold)
+	NbReducedHintInputs int `cbor:",omitempty"`
 }
 
 // Visibility encodes a Variable (or wire) visibility
@@ -42,12 +80,49 @@ const (
 	Virtual
 )
 
+var visibilityNames = [...]string{"unset", "internal", "secret", "public", "virtual"}
+
+// MarshalText implements encoding.TextMarshaler
+func (v Visibility) MarshalText() ([]byte, error) {
+	if int(v) >= len(visibilityNames) {
+		return nil, fmt.Errorf("invalid visibility %d", v)
+	}
+	return []byte(visibilityNames[v]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (v *Visibility) UnmarshalText(text []byte) error {
+	for i, name := range visibilityNames {
+		if string(text) == name {
+			*v = Visibility(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid visibility %q", text)
+}
+
 // Hint represents a solver hint
-// it enables the solver to compute a Wire with a function provided at solving time
+// it enables the solver to compute Wires with a function provided at solving time
 // using pre-defined inputs
 type Hint struct {
 	ID     hint.ID            // hint function id
+	Name   string             // hint function name, for error messages (see hint.AnnotatedFunction)
 	Inputs []LinearExpression // terms to inject in the hint function
+	Wires  []int              // IDs of the wires computed by the hint function (one per output)
+	Static [][]byte           `cbor:",omitempty"` // static parameters of the hint function (see hint.FunctionWithStatic)
+	Deps   []int              `cbor:",omitempty"` // a wire of each hint computing an input, solved first (see LinkHints)
+}
+
+// Output is a named value of a circuit, the linear expression is resolved by the solver
+type Output struct {
+	Name  string
+	Value LinearExpression
+}
+
+// PublicOutput is a public input computed by the circuit, see frontend.API.SetOutput
+type PublicOutput struct {
+	Name  string
+	Index int // among the public inputs, not counting the constant wire of R1CS: the index in the witness
 }
 
 // GetNbVariables return number of internal, secret and public variables
@@ -55,6 +130,97 @@ func (cs *CS) GetNbVariables() (internal, secret, public int) {
 	return cs.NbInternalVariables, cs.NbSecretVariables, cs.NbPublicVariables
 }
 
+// ReferencedHints returns the IDs of the hint functions the constraint system calls, with their names
+// (empty for the constraint systems compiled before the names were recorded). The solvers only accept
+// these hint functions, see backend.WithUnsafeHints
+func (cs *CS) ReferencedHints() map[hint.ID]string {
+	res := make(map[hint.ID]string)
+	for _, h := range cs.MHints {
+		// the hints of a same function may be named or not, the name is kept whatever the order
+		// of the map
+		if h.Name != "" || res[h.ID] == "" {
+			res[h.ID] = h.Name
+		}
+	}
+	return res
+}
+
+// RequiredHints returns the hint functions the constraint system calls, sorted by name then ID, see
+// ReferencedHints
+func (cs *CS) RequiredHints() []hint.Reference {
+	res := make([]hint.Reference, 0, len(cs.MHints))
+	for id, name := range cs.ReferencedHints() {
+		res = append(res, hint.Reference{UUID: id, Name: name})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+		return res[i].UUID < res[j].UUID
+	})
+	return res
+}
+
+// GetMetadata returns the user-defined metadata of the constraint system (see frontend.WithMetadata)
+func (cs *CS) GetMetadata() map[string]string {
+	return cs.Metadata
+}
+
+// GetRangeChecks returns the number of range checks per strategy, see RangeChecks
+func (cs *CS) GetRangeChecks() map[string]int {
+	return cs.RangeChecks
+}
+
+// GetNbEliminatedConstraints returns the number of constraints eliminated at compile time, see
+// NbEliminatedConstraints
+func (cs *CS) GetNbEliminatedConstraints() int {
+	return cs.NbEliminatedConstraints
+}
+
+// GetNbReducedHintInputs returns the number of hint input expressions reduced to a wire, see
+// NbReducedHintInputs
+func (cs *CS) GetNbReducedHintInputs() int {
+	return cs.NbReducedHintInputs
+}
+
+// GetOptionalSecrets returns the indexes of the optional secret inputs, see OptionalSecrets
+func (cs *CS) GetOptionalSecrets() []int {
+	return cs.OptionalSecrets
+}
+
+// GetPublicOutputs returns the names of the public inputs computed by the circuit, see PublicOutputs
+func (cs *CS) GetPublicOutputs() []string {
+	if len(cs.PublicOutputs) == 0 {
+		return nil
+	}
+	res := make([]string, len(cs.PublicOutputs))
+	for i, o := range cs.PublicOutputs {
+		res[i] = o.Name
+	}
+	return res
+}
+
+// NbTrailingOptionalSecrets returns the number of optional secret inputs at the end of the
+// secret inputs, that is the number of values a (full) witness may omit
+func (cs *CS) NbTrailingOptionalSecrets() int {
+	return NbTrailingOptional(cs.OptionalSecrets, cs.NbSecretVariables)
+}
+
+// NbTrailingOptional returns the number of indexes of optional (in increasing order) that are
+// contiguous up to n-1
+func NbTrailingOptional(optional []int, n int) int {
+	k := 0
+	for i := len(optional) - 1; i >= 0 && optional[i] == n-1-k; i-- {
+		k++
+	}
+	return k
+}
+
+// GetLoops returns the iterations of the unrolled loops of the circuit, or nil
+func (cs *CS) GetLoops() *Loops {
+	return cs.Loops
+}
+
 // FrSize panics
 func (cs *CS) FrSize() int { panic("not implemented") }
 
@@ -64,6 +230,19 @@ func (cs *CS) GetNbCoefficients() int { panic("not implemented") }
 // CurveID returns ecc.UNKNOWN
 func (cs *CS) CurveID() ecc.ID { return ecc.UNKNOWN }
 
+// CheckAssignment panics
+func (cs *CS) CheckAssignment(values []*big.Int) ([]bool, error) { panic("not implemented") }
+
+// CheckAssignmentUpTo panics
+func (cs *CS) CheckAssignmentUpTo(values []*big.Int, maxFailures int) ([]bool, error) {
+	panic("not implemented")
+}
+
+// AnalyzeWitness panics
+func (cs *CS) AnalyzeWitness(values []*big.Int) (*backend.WitnessAnalysis, error) {
+	panic("not implemented")
+}
+
 // WriteTo panics
 func (cs *CS) WriteTo(w io.Writer) (n int64, err error) { panic("not implemented") }
 
@@ -71,4 +250,11 @@ func (cs *CS) WriteTo(w io.Writer) (n int64, err error) { panic("not implemented
 func (cs *CS) ReadFrom(r io.Reader) (n int64, err error) { panic("not implemented") }
 
 // ToHTML panics
-func (cs *CS) ToHTML(w io.Writer) error { panic("not implemtened") }
+func (cs *CS) ToHTML(w io.Writer, opts ...func(opt *ExportOptions) error) error {
+	panic("not implemtened")
+}
+
+// ToJSON panics
+func (cs *CS) ToJSON(w io.Writer, opts ...func(opt *ExportOptions) error) error {
+	panic("not implemtened")
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiled
+
+// DependencyGraph links the wires and the constraints of a R1CS solved with a SolveSchedule: the
+// constraints reading each wire, and the wires solved by each constraint (including the outputs of
+// the hints it calls). The solver uses it to solve again only the constraints depending on the
+// inputs which changed, see backend.WithSolutionCache.
+type DependencyGraph struct {
+	Readers [][]int // Readers[w] are the constraints reading the wire w, in increasing order
+	Writes  [][]int // Writes[i] are the wires solved by the constraint i
+}
+
+// NewDependencyGraph returns the dependency graph of the R1CS solved with schedule
+func (r1cs *R1CS) NewDependencyGraph(schedule *SolveSchedule) *DependencyGraph {
+	nbWires := r1cs.NbPublicVariables + r1cs.NbSecretVariables + r1cs.NbInternalVariables
+	g := &DependencyGraph{
+		Readers: make([][]int, nbWires),
+		Writes:  make([][]int, r1cs.Constraints.Len()),
+	}
+
+	var r1c R1C
+	for i := range g.Writes {
+		r1cs.Constraints.Load(i, &r1c)
+		s := schedule.Constraints[i]
+		l := [3]LinearExpression{r1c.L, r1c.R, r1c.O}
+
+		// the hints read their inputs, and solve their wires
+		for _, h := range s.Hints {
+			for _, in := range h.Hint.Inputs {
+				for _, t := range in {
+					if t.VariableVisibility() != Virtual {
+						g.addReader(t.VariableID(), i)
+					}
+				}
+			}
+			g.Writes[i] = append(g.Writes[i], h.Hint.Wires...)
+		}
+		if s.Loc != 0 {
+			g.Writes[i] = append(g.Writes[i], l[s.Loc-1][s.Term].VariableID())
+		}
+
+		// the constraint reads all its other wires
+		for _, le := range l {
+			for _, t := range le {
+				if t.VariableVisibility() == Virtual || g.writes(i, t.VariableID()) {
+					continue
+				}
+				g.addReader(t.VariableID(), i)
+			}
+		}
+	}
+
+	return g
+}
+
+// Reachable returns the constraints reading one of the wires, or one of the wires solved by a
+// reachable constraint
+func (g *DependencyGraph) Reachable(wires []int) BitSet {
+	res := NewBitSet(len(g.Writes))
+	stack := append([]int(nil), wires...)
+	for len(stack) != 0 {
+		w := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, c := range g.Readers[w] {
+			if res.Get(c) {
+				continue
+			}
+			res.Set(c)
+			stack = append(stack, g.Writes[c]...)
+		}
+	}
+	return res
+}
+
+// addReader records that the constraint c reads the wire w. The constraints are processed in
+// increasing order, so a duplicate is the last reader of w.
+func (g *DependencyGraph) addReader(w, c int) {
+	if n := len(g.Readers[w]); n != 0 && g.Readers[w][n-1] == c {
+		return
+	}
+	g.Readers[w] = append(g.Readers[w], c)
+}
+
+// writes returns true if the constraint c solves the wire w
+func (g *DependencyGraph) writes(c, w int) bool {
+	for _, v := range g.Writes[c] {
+		if v == w {
+			return true
+		}
+	}
+	return false
+}
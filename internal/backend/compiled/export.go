@@ -0,0 +1,191 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiled
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DefaultHTMLPageSize is the number of constraints per page of ToHTML, unless set with
+// frontend.WithHTMLPageSize
+const DefaultHTMLPageSize = 100
+
+// ExportOptions configures the exports of the constraints, ToJSON and ToHTML
+type ExportOptions struct {
+	From, To int  // range [From, To) of the exported constraints, all of them if To is 0
+	PageSize int  // number of constraints per page of ToHTML
+	Table    bool // ToHTML writes a static table of all the constraints, for tiny circuits
+}
+
+// NewExportOptions returns the default ExportOptions with opts applied
+func NewExportOptions(opts ...func(opt *ExportOptions) error) (ExportOptions, error) {
+	opt := ExportOptions{PageSize: DefaultHTMLPageSize}
+	for _, o := range opts {
+		if err := o(&opt); err != nil {
+			return ExportOptions{}, err
+		}
+	}
+	return opt, nil
+}
+
+// ConstraintsLayout describes the constraints of a kind of constraint system, for their exports
+type ConstraintsLayout struct {
+	System   string
+	Equation string   // satisfied by the constraints
+	Columns  []string // names of the linear expressions of a constraint
+
+	// shift of the wire IDs in the exports: the IDs of the SparseR1CS are shifted by 1 to match
+	// the ones of the R1CS, whose first public wire is the constant 1
+	Offset int
+}
+
+var (
+	R1CSLayout       = ConstraintsLayout{System: "R1CS", Equation: "L * R == O", Columns: []string{"L", "R", "O"}}
+	SparseR1CSLayout = ConstraintsLayout{System: "SparseR1CS", Equation: "L + R + M0*M1 + O + k == 0", Columns: []string{"L", "R", "M0", "M1", "O", "k"}, Offset: 1}
+)
+
+// ConstraintsJSON is the JSON export of (a range of) the constraints of a constraint system, written
+// by ToJSON and embedded in the page of ToHTML.
+//
+// A constraint is a list of linear expressions, one per column of the layout, and a linear expression
+// is a flat list of (coefficient, visibility, wire) triples: the index of the coefficient in
+// Coefficients, the Visibility of the wire, and its ID. A public wire 0 stands for the constant 1.
+type ConstraintsJSON struct {
+	System        string           `json:"system"`
+	Equation      string           `json:"equation"`
+	Columns       []string         `json:"columns"`
+	NbPublic      int              `json:"nbPublic"`
+	NbSecret      int              `json:"nbSecret"`
+	NbInternal    int              `json:"nbInternal"`
+	NbHints       int              `json:"nbHints"`
+	NbConstraints int              `json:"nbConstraints"`
+	From          int              `json:"from"` // index of the first exported constraint
+	Coefficients  []string         `json:"coefficients"`
+	Constraints   [][][]int        `json:"constraints"`
+	Hints         map[int]string   `json:"hints,omitempty"`   // name of the hint computing each wire of the constraints
+	Outputs       map[string][]int `json:"outputs,omitempty"` // wires of each output, see frontend.API.MarkOutput
+	Loops         []ScopeRun       `json:"loops,omitempty"`
+	Tags          []ScopeRun       `json:"tags,omitempty"`
+	PerTag        map[string]int   `json:"perTag,omitempty"` // see Tags.Count
+}
+
+// ScopeRun is a run of exported constraints created in the same scope of a loop or a tag, from
+// the constraint Begin to the beginning of the next run; Name is empty out of any scope
+type ScopeRun struct {
+	Begin int    `json:"b"`
+	Name  string `json:"n"`
+}
+
+// NewConstraintsJSON returns the export of the constraints of cs in the range of opt. The constraint
+// system has nbConstraints constraints, and constraint(i) returns the linear expressions of the
+// constraint i, in the columns of layout, with the wire IDs shifted by layout.Offset.
+func (cs *CS) NewConstraintsJSON(layout ConstraintsLayout, nbConstraints int, coefficients []string, opt ExportOptions, constraint func(i int) []LinearExpression) (*ConstraintsJSON, error) {
+	from, to := opt.From, opt.To
+	if to == 0 || to > nbConstraints {
+		to = nbConstraints
+	}
+	if from < 0 || from > to {
+		return nil, fmt.Errorf("invalid range [%d, %d) of %d constraints", opt.From, opt.To, nbConstraints)
+	}
+
+	res := &ConstraintsJSON{
+		System:        layout.System,
+		Equation:      layout.Equation,
+		Columns:       layout.Columns,
+		NbPublic:      cs.NbPublicVariables,
+		NbSecret:      cs.NbSecretVariables,
+		NbInternal:    cs.NbInternalVariables,
+		NbHints:       len(cs.MHints),
+		NbConstraints: nbConstraints,
+		From:          from,
+		Coefficients:  coefficients,
+		Constraints:   make([][][]int, 0, to-from),
+	}
+
+	for i := from; i < to; i++ {
+		l := constraint(i)
+		c := make([][]int, len(l))
+		for j, le := range l {
+			c[j] = make([]int, 0, 3*len(le))
+			for _, t := range le {
+				cID, vID, visibility := t.Unpack()
+				c[j] = append(c[j], cID, int(visibility), vID)
+				if visibility != Internal {
+					continue
+				}
+				if h, ok := cs.MHints[vID-layout.Offset]; ok {
+					if res.Hints == nil {
+						res.Hints = make(map[int]string)
+					}
+					res.Hints[vID] = hintName(h)
+				}
+			}
+		}
+		res.Constraints = append(res.Constraints, c)
+	}
+
+	for _, o := range cs.Outputs {
+		if res.Outputs == nil {
+			res.Outputs = make(map[string][]int)
+		}
+		wires := make([]int, 0, len(o.Value))
+		for _, t := range o.Value {
+			if !(layout.Offset == 0 && t.IsConstant()) {
+				wires = append(wires, t.VariableID()+layout.Offset)
+			}
+		}
+		res.Outputs[o.Name] = wires
+	}
+
+	if cs.Loops != nil {
+		res.Loops = scopeRuns(from, to, cs.Loops.Innermost, cs.Loops.Name)
+	}
+	if cs.Tags != nil {
+		res.Tags = scopeRuns(from, to, cs.Tags.Innermost, func(s int) string { return cs.Tags.Labels[cs.Tags.Scopes[s].Label] })
+		res.PerTag = cs.Tags.Count(nbConstraints)
+	}
+	return res, nil
+}
+
+// scopeRuns returns the runs of the constraints [from, to) in the same innermost scope
+func scopeRuns(from, to int, innermost func(cID int) int, name func(s int) string) []ScopeRun {
+	var res []ScopeRun
+	previous := -2
+	for i := from; i < to; i++ {
+		s := innermost(i)
+		if s == previous {
+			continue
+		}
+		previous = s
+		run := ScopeRun{Begin: i}
+		if s != -1 {
+			run.Name = name(s)
+		}
+		res = append(res, run)
+	}
+	return res
+}
+
+// WriteTo writes the JSON encoding of c to w
+func (c *ConstraintsJSON) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
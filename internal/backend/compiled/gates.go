@@ -0,0 +1,170 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiled
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// SparseR1CSVersion is the version of the serialization format of the SparseR1CS. SparseR1CS
+// serialized before the versions (with the constraints stored as SparseR1C, before GateList) are
+// version 0, and can still be read.
+const SparseR1CSVersion = 1
+
+// GateList is the serialization of the constraints of a SparseR1CS. The selectors of a gate (the
+// coefficients and visibilities of its terms, and its constant) come from a small set in most circuits:
+// they are stored once in Selectors, and each gate stores the index of its selectors and its wire IDs
+// as varints, in Gates:
+//
+//	uvarint(selectors) | uvarint(L) | uvarint(R) | uvarint(M[0]) | uvarint(M[1]) | uvarint(O)
+//
+// A gate takes from 6 to 30 bytes, instead of 50 to 90 bytes for a SparseR1C.
+type GateList struct {
+	Selectors []Selectors
+	Gates     []byte
+	NbGates   int
+}
+
+// Selectors are the terms of a SparseR1C with the wire IDs set to 0, and its constant
+type Selectors struct {
+	_       struct{} `cbor:",toarray"`
+	L, R, O Term
+	M0, M1  Term
+	K       int
+}
+
+// NewGateList returns the serialization of the constraints
+func NewGateList(constraints []SparseR1C) *GateList {
+	res := &GateList{NbGates: len(constraints)}
+	index := make(map[Selectors]int)
+	var buf [binary.MaxVarintLen64]byte
+	for _, c := range constraints {
+		s := Selectors{
+			L: c.L.selector(), R: c.R.selector(), O: c.O.selector(),
+			M0: c.M[0].selector(), M1: c.M[1].selector(),
+			K: c.K,
+		}
+		i, ok := index[s]
+		if !ok {
+			i = len(res.Selectors)
+			index[s] = i
+			res.Selectors = append(res.Selectors, s)
+		}
+		for _, v := range [6]int{i, c.L.VariableID(), c.R.VariableID(), c.M[0].VariableID(), c.M[1].VariableID(), c.O.VariableID()} {
+			n := binary.PutUvarint(buf[:], uint64(v))
+			res.Gates = append(res.Gates, buf[:n]...)
+		}
+	}
+	return res
+}
+
+// errInvalidGates is returned when the gates of a GateList can't be decoded
+var errInvalidGates = errors.New("invalid gate list: truncated or overlong")
+
+// Constraints returns the constraints of the list. If check is set, it returns an error if a selector or
+// a wire of a gate doesn't exist, nbWires and nbCoefficients being the numbers of wires and coefficients
+// of the SparseR1CS; else, the gates are only decoded.
+func (l *GateList) Constraints(nbWires, nbCoefficients int, check bool) ([]SparseR1C, error) {
+	if check {
+		for i, s := range l.Selectors {
+			for _, t := range [5]Term{s.L, s.R, s.O, s.M0, s.M1} {
+				if t.VariableID() != 0 || t.CoeffID() >= nbCoefficients {
+					return nil, fmt.Errorf("invalid gate selectors %d", i)
+				}
+			}
+			if s.K < 0 || s.K >= nbCoefficients {
+				return nil, fmt.Errorf("invalid gate selectors %d", i)
+			}
+		}
+	}
+	// a gate takes at least 6 bytes
+	if l.NbGates < 0 || l.NbGates > len(l.Gates)/6 {
+		return nil, fmt.Errorf("invalid number of gates %d", l.NbGates)
+	}
+
+	res := make([]SparseR1C, l.NbGates)
+	data := l.Gates
+	var v [6]uint64
+	for i := range res {
+		for j := range v {
+			x, n := binary.Uvarint(data)
+			if n <= 0 {
+				return nil, errInvalidGates
+			}
+			v[j], data = x, data[n:]
+		}
+		if v[0] >= uint64(len(l.Selectors)) {
+			return nil, fmt.Errorf("gate %d: invalid selectors %d", i, v[0])
+		}
+		if check {
+			for _, w := range v[1:] {
+				if w != 0 && w >= uint64(nbWires) {
+					return nil, fmt.Errorf("gate %d: invalid wire %d", i, w)
+				}
+			}
+		}
+		s := &l.Selectors[v[0]]
+		res[i] = SparseR1C{
+			L: s.L | Term(v[1]&maskVariableID),
+			R: s.R | Term(v[2]&maskVariableID),
+			M: [2]Term{s.M0 | Term(v[3]&maskVariableID), s.M1 | Term(v[4]&maskVariableID)},
+			O: s.O | Term(v[5]&maskVariableID),
+			K: s.K,
+		}
+	}
+	if len(data) != 0 {
+		return nil, errInvalidGates
+	}
+	return res, nil
+}
+
+// selector returns t with a wire ID set to 0
+func (t Term) selector() Term {
+	return t &^ Term(maskVariableID)
+}
+
+// Compact returns the SparseR1CS to serialize: cs with its constraints in Gates, in the format
+// SparseR1CSVersion
+func (cs *SparseR1CS) Compact() SparseR1CS {
+	res := *cs
+	res.Version = SparseR1CSVersion
+	res.Gates = NewGateList(cs.Constraints)
+	res.Constraints = nil
+	return res
+}
+
+// Expand sets the constraints of a deserialized SparseR1CS, from their Gates for the format
+// SparseR1CSVersion. nbCoefficients is the number of coefficients of the SparseR1CS, and if check is
+// set, the gates are validated, see GateList.Constraints.
+func (cs *SparseR1CS) Expand(nbCoefficients int, check bool) error {
+	switch cs.Version {
+	case 0:
+		return nil
+	case SparseR1CSVersion:
+	default:
+		return fmt.Errorf("unsupported SparseR1CS format version %d, expected %d", cs.Version, SparseR1CSVersion)
+	}
+	if cs.Gates == nil {
+		return errors.New("missing gate list")
+	}
+	constraints, err := cs.Gates.Constraints(cs.NbPublicVariables+cs.NbSecretVariables+cs.NbInternalVariables, nbCoefficients, check)
+	if err != nil {
+		return err
+	}
+	cs.Constraints, cs.Gates = constraints, nil
+	return nil
+}
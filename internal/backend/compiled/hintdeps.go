@@ -0,0 +1,106 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiled
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LinkHints sets the dependencies of the hints of mHints (wire -> hint, see Hint.Deps): for each hint,
+// the first wire of each hint computing one of its inputs. The solver calls a hint when a constraint
+// reads one of its wires, so a hint reading the wires of another hint may be called first; it then
+// calls the hints it depends on.
+//
+// It returns an error naming two of the hints if the dependencies have a cycle.
+func LinkHints(mHints map[int]Hint) error {
+	for w, h := range mHints {
+		h.Deps = nil
+		for _, in := range h.Inputs {
+			for _, t := range in {
+				if t.VariableVisibility() == Virtual {
+					continue
+				}
+				if up, ok := mHints[t.VariableID()]; ok && !containsInt(h.Deps, up.Wires[0]) {
+					h.Deps = append(h.Deps, up.Wires[0])
+				}
+			}
+		}
+		mHints[w] = h
+	}
+
+	// depth first search from each hint (by first wire, in increasing order), a hint met again on the
+	// path to it closes a cycle
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := make(map[int]int)
+	var visit func(w int) error
+	visit = func(w int) error {
+		state[w] = onPath
+		h := mHints[w]
+		for _, d := range h.Deps {
+			switch state[d] {
+			case onPath:
+				return fmt.Errorf("cyclic hint dependencies between %s and %s", hintString(h), hintString(mHints[d]))
+			case unvisited:
+				if err := visit(d); err != nil {
+					return err
+				}
+			}
+		}
+		state[w] = done
+		return nil
+	}
+	wires := make([]int, 0, len(mHints))
+	for w, h := range mHints {
+		if w == h.Wires[0] {
+			wires = append(wires, w)
+		}
+	}
+	sort.Ints(wires)
+	for _, w := range wires {
+		if state[w] == unvisited {
+			if err := visit(w); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hintString returns the name of the hint function of h, and its first wire
+func hintString(h Hint) string {
+	return fmt.Sprintf("hint %s (wire %d)", hintName(h), h.Wires[0])
+}
+
+// hintName returns the name of the hint function of h, or its ID if it has no name
+func hintName(h Hint) string {
+	if h.Name == "" {
+		return fmt.Sprintf("%#x", uint64(h.ID))
+	}
+	return h.Name
+}
+
+func containsInt(s []int, v int) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -1,5 +1,13 @@
 package compiled
 
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
 const R1CSTemplate = `
 <!doctype html>
 <html lang="en">
@@ -25,11 +33,14 @@ const R1CSTemplate = `
 	<div class="container">
 	<h1>R1CS</h1>
 	{{ $nbHints := len .MHints }}
-	{{ $nbConstraints := len .Constraints}}
+	{{ $nbConstraints := .Constraints.Len}}
 	<span class="internal">{{.NbInternalVariables}} internal </span> (includes <span class="hint">{{$nbHints}} hints</span>)</br>
 	<span class="public">{{.NbPublicVariables}} public</span></br>
 	<span class="secret">{{.NbSecretVariables}} secret</span></br>
 	<span>{{$nbConstraints}} constraints</span></br>
+	{{- range $label, $n := .ConstraintsPerTag}}
+	<span>{{$n}} tagged {{if $label}}{{$label}}{{else}}(none){{end}}</span></br>
+	{{- end}}
   <p class="fw-bold">L * R == O</p>
   <p class="fst-italic">-</p>
 </div>
@@ -37,15 +48,27 @@ const R1CSTemplate = `
   <thead>
     <tr>
       <th scope="col">#</th>
+      {{- if .Loops}}
+      <th scope="col">loop</th>
+      {{- end}}
+      {{- if .Tags}}
+      <th scope="col">tag</th>
+      {{- end}}
       <th scope="col">L</th>
       <th scope="col">R</th>
       <th scope="col">O</th>
     </tr>
   </thead>
   <tbody>
-    {{- range $i, $c := .Constraints}}
+    {{- range $i, $c := .Constraints.All}}
     <tr>
       <th scope="row">{{$i}}</th>
+      {{- if $.Loops}}
+      <td> {{ $.Loops.ScopeName $i }} </td>
+      {{- end}}
+      {{- if $.Tags}}
+      <td> {{ $.Tags.ScopeName $i }} </td>
+      {{- end}}
 	  <td> {{ toHTML $c.L $.Coefficients $.MHints}} </td>
       <td> {{ toHTML $c.R $.Coefficients $.MHints}} </td>
       <td> {{ toHTML $c.O $.Coefficients $.MHints}} </td>
@@ -88,6 +111,9 @@ const SparseR1CSTemplate = `
 	<span class="public">{{.NbPublicVariables}} public</span></br>
 	<span class="secret">{{.NbSecretVariables}} secret</span></br>
 	<span>{{$nbConstraints}} constraints</span></br>
+	{{- range $label, $n := .ConstraintsPerTag}}
+	<span>{{$n}} tagged {{if $label}}{{$label}}{{else}}(none){{end}}</span></br>
+	{{- end}}
 	<p class="fw-bold">L + R + M0*M1 + O + k == 0</p>
   <p class="fst-italic">all variable id are offseted by 1 to match R1CS</p>
 </div>
@@ -96,6 +122,12 @@ const SparseR1CSTemplate = `
   <thead>
     <tr>
       <th scope="col">#</th>
+      {{- if .Loops}}
+      <th scope="col">loop</th>
+      {{- end}}
+      {{- if .Tags}}
+      <th scope="col">tag</th>
+      {{- end}}
       <th scope="col">L</th>
       <th scope="col">R</th>
       <th scope="col">M0</th>
@@ -108,6 +140,12 @@ const SparseR1CSTemplate = `
     {{- range $i, $c := .Constraints}}
     <tr>
 		<th scope="row">{{$i}}</th>
+      {{- if $.Loops}}
+      <td> {{ $.Loops.ScopeName $i }} </td>
+      {{- end}}
+      {{- if $.Tags}}
+      <td> {{ $.Tags.ScopeName $i }} </td>
+      {{- end}}
 	  <td> {{ toHTML $c.L $.Coefficients $.MHints}} </td>
       <td> {{ toHTML $c.R $.Coefficients $.MHints}} </td>
 	  <td> {{ toHTML (index $c.M 0) $.Coefficients $.MHints}} </td>
@@ -121,3 +159,204 @@ const SparseR1CSTemplate = `
   </body>
 </html>
 `
+
+// PageTemplate is the page written by ToHTML: the constraints are rendered lazily, a page at a time,
+// from their JSON export (see ConstraintsJSON) embedded in the page
+const PageTemplate = `<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Data.System}}</title>
+<style>
+	body {font-family: sans-serif; margin: 1em 2em;}
+	table {border-collapse: collapse; margin-top: 1em;}
+	th, td {border: 1px solid #ddd; padding: 0.2em 0.5em; text-align: left; vertical-align: top;}
+	.coefficient {color:gray;}
+	.internal {color:blue;font-weight: bold;}
+	.hint {color:purple;font-weight: bold;}
+	.public {color:green;font-weight: bold;}
+	.secret {color:orange;font-weight: bold;}
+	.virtual {color:red;font-weight: bold;}
+	.unset {color:red;font-weight: bold;}
+	.wire {cursor: pointer;}
+	.selected {background: yellow;}
+</style>
+</head>
+<body>
+<h1>{{.Data.System}}</h1>
+<span class="internal">{{.Data.NbInternal}} internal</span> (includes <span class="hint">{{.Data.NbHints}} hints</span>)<br>
+<span class="public">{{.Data.NbPublic}} public</span><br>
+<span class="secret">{{.Data.NbSecret}} secret</span><br>
+<span>{{.Data.NbConstraints}} constraints</span><br>
+{{- range $label, $n := .Data.PerTag}}
+<span>{{$n}} tagged {{if $label}}{{html $label}}{{else}}(none){{end}}</span><br>
+{{- end}}
+<p><b>{{.Data.Equation}}</b></p>
+<p>
+	<input id="search" size="40" placeholder="wire (v12 or 12), hint or output name, or #constraint">
+	<button id="find">find</button> <button id="clear">clear</button> <span id="status"></span>
+</p>
+<p><button id="prev">&lt;</button> <span id="page"></span> <button id="next">&gt;</button></p>
+<table><thead id="head"></thead><tbody id="body"></tbody></table>
+<script type="application/json" id="data">{{.JSON}}</script>
+<script>
+(function () {
+	const data = JSON.parse(document.getElementById("data").textContent);
+	const pageSize = {{.PageSize}};
+	const classes = ["unset", "internal", "secret", "public", "virtual"];
+	const hints = data.hints || {};
+	let rows = null;     // indexes (in data.constraints) of the listed constraints, all of them if null
+	let selected = null; // highlighted wires
+	let page = 0;
+
+	const esc = (s) => String(s).replace(/[&<>"]/g, (c) => ({"&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;"})[c]);
+	const $ = (id) => document.getElementById(id);
+
+	// scope returns the name of the run of runs containing the constraint i
+	function scope(runs, i) {
+		let lo = 0, hi = runs.length - 1, res = "";
+		while (lo <= hi) {
+			const mid = (lo + hi) >> 1;
+			if (runs[mid].b <= i) { res = runs[mid].n; lo = mid + 1; } else { hi = mid - 1; }
+		}
+		return res;
+	}
+
+	function term(c, v, w) {
+		if (v === 3 && w === 0) {
+			return '<span class="coefficient">' + data.coefficients[c] + "</span>";
+		}
+		let s = "";
+		if (c === 0) {
+			return '<span class="coefficient">0</span>';
+		} else if (c === 3) {
+			s = '<span class="coefficient">-</span>';
+		} else if (c !== 1) {
+			s = '<span class="coefficient">' + data.coefficients[c] + "</span>*";
+		}
+		let cls = classes[v], title = cls;
+		if (v === 1 && hints[w] !== undefined) {
+			cls = "hint";
+			title = "hint " + hints[w];
+		}
+		if (selected && selected.has(w)) {
+			cls += " selected";
+		}
+		return s + '<span class="wire ' + cls + '" data-wire="' + w + '" title="' + esc(title) + '">v' + w + "</span>";
+	}
+
+	function expression(l) {
+		const terms = [];
+		for (let k = 0; k < l.length; k += 3) {
+			terms.push(term(l[k], l[k + 1], l[k + 2]));
+		}
+		return terms.join(" + ");
+	}
+
+	function render() {
+		const n = rows ? rows.length : data.constraints.length;
+		const nbPages = Math.max(1, Math.ceil(n / pageSize));
+		page = Math.min(Math.max(page, 0), nbPages - 1);
+		$("page").textContent = "page " + (page + 1) + " / " + nbPages + " (" + n + " constraints)";
+
+		let head = "<tr><th>#</th>";
+		if (data.loops) head += "<th>loop</th>";
+		if (data.tags) head += "<th>tag</th>";
+		for (const c of data.columns) head += "<th>" + c + "</th>";
+		$("head").innerHTML = head + "</tr>";
+
+		const html = [];
+		for (let r = page * pageSize; r < Math.min(n, (page + 1) * pageSize); r++) {
+			const j = rows ? rows[r] : r, i = data.from + j;
+			let row = "<tr><th>" + i + "</th>";
+			if (data.loops) row += "<td>" + esc(scope(data.loops, i)) + "</td>";
+			if (data.tags) row += "<td>" + esc(scope(data.tags, i)) + "</td>";
+			for (const l of data.constraints[j]) row += "<td>" + expression(l) + "</td>";
+			html.push(row + "</tr>");
+		}
+		$("body").innerHTML = html.join("");
+	}
+
+	// wires returns the wires matching the query: a wire ID, or the wires of the hints and the outputs
+	// whose name contains it
+	function wires(q) {
+		const m = q.match(/^v?(\d+)$/);
+		if (m) return new Set([Number(m[1])]);
+		const res = new Set();
+		for (const w in hints) {
+			if (hints[w].includes(q)) res.add(Number(w));
+		}
+		for (const name in data.outputs || {}) {
+			if (name.includes(q)) data.outputs[name].forEach((w) => res.add(w));
+		}
+		return res;
+	}
+
+	function find(q) {
+		q = q.trim();
+		if (q === "") return clear();
+		const m = q.match(/^#(\d+)$/);
+		if (m) {
+			rows = null;
+			page = Math.floor((Number(m[1]) - data.from) / pageSize);
+			$("status").textContent = "";
+			return render();
+		}
+		selected = wires(q);
+		rows = [];
+		data.constraints.forEach((c, j) => {
+			if (c.some((l) => { for (let k = 2; k < l.length; k += 3) { if (selected.has(l[k])) return true; } return false; })) {
+				rows.push(j);
+			}
+		});
+		page = 0;
+		$("status").textContent = rows.length + " constraints touch " + (selected.size > 8 ? selected.size + " wires" : [...selected].map((w) => "v" + w).join(", "));
+		render();
+	}
+
+	function clear() {
+		rows = null;
+		selected = null;
+		$("search").value = "";
+		$("status").textContent = "";
+		render();
+	}
+
+	$("find").onclick = () => find($("search").value);
+	$("search").onkeydown = (e) => { if (e.key === "Enter") find($("search").value); };
+	$("clear").onclick = clear;
+	$("prev").onclick = () => { page--; render(); };
+	$("next").onclick = () => { page++; render(); };
+	$("body").onclick = (e) => {
+		const w = e.target.getAttribute("data-wire");
+		if (w !== null) {
+			$("search").value = "v" + w;
+			find($("search").value);
+		}
+	};
+	render();
+})();
+</script>
+</body>
+</html>
+`
+
+// WritePage writes the page of ToHTML for the constraints exported in data, see PageTemplate
+func WritePage(w io.Writer, data *ConstraintsJSON, opt ExportOptions) error {
+	if opt.PageSize <= 0 {
+		return fmt.Errorf("invalid page size %d", opt.PageSize)
+	}
+	t, err := template.New("page.html").Parse(PageTemplate)
+	if err != nil {
+		return err
+	}
+	var blob bytes.Buffer
+	if _, err := data.WriteTo(&blob); err != nil {
+		return err
+	}
+	return t.Execute(w, struct {
+		Data     *ConstraintsJSON
+		JSON     string
+		PageSize int
+	}{data, strings.TrimSpace(blob.String()), opt.PageSize})
+}
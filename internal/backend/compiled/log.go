@@ -27,7 +27,7 @@ func (l *LogEntry) WriteLinearExpression(le LinearExpression, sbb *strings.Build
 
 func (l *LogEntry) WriteTerm(t Term, sbb *strings.Builder) {
 	// virtual == only a coeff, we discard the wire
-	if t.VariableVisibility() == Public && t.VariableID() == 0 {
+	if t.IsConstant() {
 		sbb.WriteString("%s")
 		t.SetVariableVisibility(Virtual)
 		l.ToResolve = append(l.ToResolve, t)
@@ -0,0 +1,114 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiled
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Loops records the iterations of the unrolled loops of a circuit (see frontend.API.LoopBegin),
+// and the constraints each of them created
+type Loops struct {
+	Labels []string    // the labels of the loops, a scope refers to its label by index
+	Scopes []LoopScope // in the order the iterations began: a scope comes after its parent
+}
+
+// LoopScope is an iteration of a loop: the constraints [Begin, End) were created by it, or by the
+// loops nested in it
+type LoopScope struct {
+	Label      int // index in Loops.Labels
+	Iteration  int
+	Begin, End int
+	Parent     int // index of the enclosing scope in Loops.Scopes, or -1
+}
+
+// Name returns the name of the scope s: label[iteration], prefixed by the name of its parent
+func (l *Loops) Name(s int) string {
+	var parts []string
+	for ; s != -1; s = l.Scopes[s].Parent {
+		scope := &l.Scopes[s]
+		parts = append(parts, l.Labels[scope.Label]+"["+strconv.Itoa(scope.Iteration)+"]")
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, "/")
+}
+
+// Innermost returns the index of the innermost scope which created the constraint cID, or -1
+func (l *Loops) Innermost(cID int) int {
+	// the last scope beginning at or before cID, or one of its ancestors, if any contains cID
+	s := sort.Search(len(l.Scopes), func(i int) bool { return l.Scopes[i].Begin > cID }) - 1
+	for ; s != -1; s = l.Scopes[s].Parent {
+		if cID < l.Scopes[s].End {
+			return s
+		}
+	}
+	return -1
+}
+
+// ScopeName returns the name of the innermost scope which created the constraint cID (see Name),
+// or an empty string
+func (l *Loops) ScopeName(cID int) string {
+	if s := l.Innermost(cID); s != -1 {
+		return l.Name(s)
+	}
+	return ""
+}
+
+// Remap returns the scopes for the constraints of another constraint system, the constraint i
+// having become the constraints [starts[i], starts[i+1]); len(starts) is the number of constraints
+// plus one
+func (l *Loops) Remap(starts []int) *Loops {
+	res := &Loops{Labels: l.Labels, Scopes: make([]LoopScope, len(l.Scopes))}
+	for i, s := range l.Scopes {
+		s.Begin, s.End = starts[s.Begin], starts[s.End]
+		res.Scopes[i] = s
+	}
+	return res
+}
+
+// candidates returns, for each constraint, the constraint at the same position in the previous
+// iteration of its loop if that iteration has the same number of constraints, or -1: they are
+// likely instances of the same blueprint (see NewR1CList)
+func (l *Loops) candidates(nbConstraints int) []int {
+	res := make([]int, nbConstraints)
+	for i := range res {
+		res[i] = -1
+	}
+
+	// the previous iteration of a loop, by parent and label
+	type loop struct{ parent, label int }
+	previous := make(map[loop]int)
+
+	for s, scope := range l.Scopes {
+		key := loop{scope.Parent, scope.Label}
+		p, ok := previous[key]
+		previous[key] = s
+		if !ok {
+			continue
+		}
+		prev := l.Scopes[p]
+		if prev.Iteration+1 != scope.Iteration || prev.End-prev.Begin != scope.End-scope.Begin {
+			continue
+		}
+		for c := scope.Begin; c < scope.End && c < nbConstraints; c++ {
+			res[c] = prev.Begin + c - scope.Begin
+		}
+	}
+	return res
+}
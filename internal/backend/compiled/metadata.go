@@ -0,0 +1,182 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiled
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	gnarkio "github.com/consensys/gnark/io"
+)
+
+// MaxMetadataSize is the maximum size in bytes of the keys and values of a constraint system metadata
+const MaxMetadataSize = 1024
+
+// ErrMetadataTooLarge is returned when the keys and values of a metadata exceed MaxMetadataSize bytes
+var ErrMetadataTooLarge = errors.New("metadata too large: keys and values can't exceed 1KB in total")
+
+// MetadataSize returns the size in bytes of the keys and values of m
+func MetadataSize(m map[string]string) int {
+	size := 0
+	for k, v := range m {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+// EqualMetadata returns true if a and b hold the same entries; a nil metadata equals an empty one
+func EqualMetadata(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// CopyMetadata returns a copy of m, or nil if m is empty
+func CopyMetadata(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	r := make(map[string]string, len(m))
+	for k, v := range m {
+		r[k] = v
+	}
+	return r
+}
+
+// metadataMarker precedes the metadata section of the binary encodings of the keys; its last byte is the
+// version of the section
+var metadataMarker = []byte("gnarkmd\x01")
+
+// WriteMetadataSection writes the metadata section which ends the binary encodings of the keys: nothing if
+// m is empty, such that the key is encoded as before the metadata, else a marker followed by WriteMetadata
+func WriteMetadataSection(w io.Writer, m map[string]string) (int64, error) {
+	if len(m) == 0 {
+		return 0, nil
+	}
+	n, err := w.Write(metadataMarker)
+	if err != nil {
+		return int64(n), err
+	}
+	n2, err := WriteMetadata(w, m)
+	return int64(n) + n2, err
+}
+
+// ReadMetadataSection reads a section written with WriteMetadataSection. If r doesn't continue with its
+// marker, the key has no metadata, or was written before the metadata: it returns nil, and nothing is
+// consumed, see gnarkio.LimitReader.ReadMarker.
+func ReadMetadataSection(r *gnarkio.LimitReader) (map[string]string, int64, error) {
+	if !r.ReadMarker(metadataMarker) {
+		return nil, 0, nil
+	}
+	m, n, err := ReadMetadata(r)
+	if err == nil && m == nil {
+		err = errors.New("invalid metadata section: no entry")
+	}
+	return m, int64(len(metadataMarker)) + n, err
+}
+
+// WriteMetadata writes the binary encoding of m, with keys in increasing order:
+//
+//	uint32(len(m)) | [uint32(len(key)) | key | uint32(len(value)) | value]
+func WriteMetadata(w io.Writer, m map[string]string) (int64, error) {
+	if MetadataSize(m) > MaxMetadataSize {
+		return 0, ErrMetadataTooLarge
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	buf := make([]byte, 4, 4+8*len(m)+MetadataSize(m))
+	binary.BigEndian.PutUint32(buf, uint32(len(m)))
+	for _, k := range keys {
+		buf = appendString(buf, k)
+		buf = appendString(buf, m[k])
+	}
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// ReadMetadata reads a metadata written with WriteMetadata; it returns nil if the metadata is empty
+func ReadMetadata(r io.Reader) (map[string]string, int64, error) {
+	var n int64
+	readUint32 := func() (uint32, error) {
+		var buf [4]byte
+		read, err := io.ReadFull(r, buf[:])
+		n += int64(read)
+		return binary.BigEndian.Uint32(buf[:]), err
+	}
+
+	nbEntries, err := readUint32()
+	if err != nil {
+		return nil, n, err
+	}
+	if nbEntries == 0 {
+		return nil, n, nil
+	}
+	if nbEntries > MaxMetadataSize {
+		return nil, n, ErrMetadataTooLarge
+	}
+
+	m := make(map[string]string, nbEntries)
+	size := 0
+	readString := func() (string, error) {
+		l, err := readUint32()
+		if err != nil {
+			return "", err
+		}
+		if l > MaxMetadataSize || size+int(l) > MaxMetadataSize {
+			return "", ErrMetadataTooLarge
+		}
+		size += int(l)
+		buf := make([]byte, l)
+		read, err := io.ReadFull(r, buf)
+		n += int64(read)
+		return string(buf), err
+	}
+	for i := uint32(0); i < nbEntries; i++ {
+		k, err := readString()
+		if err != nil {
+			return nil, n, err
+		}
+		v, err := readString()
+		if err != nil {
+			return nil, n, err
+		}
+		if _, ok := m[k]; ok {
+			return nil, n, fmt.Errorf("invalid metadata: duplicate key %q", k)
+		}
+		m[k] = v
+	}
+
+	return m, n, nil
+}
+
+func appendString(buf []byte, s string) []byte {
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(len(s)))
+	buf = append(buf, l[:]...)
+	return append(buf, s...)
+}
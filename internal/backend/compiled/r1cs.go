@@ -14,13 +14,37 @@
 
 package compiled
 
+import "fmt"
+
+// R1CSVersion is the version of the serialization format of the R1CS. R1CS serialized before the
+// versions (with the constraints stored as R1C, before R1CList) are version 0.
+const R1CSVersion = 1
+
 // R1CS decsribes a set of R1C constraint
 type R1CS struct {
 	CS
-	Constraints []R1C
+	Version     int     // serialization format, R1CSVersion
+	Constraints R1CList // see R1CList.Load to iterate over the constraints
 }
 
 // GetNbConstraints returns the number of constraints
 func (r1cs *R1CS) GetNbConstraints() int {
-	return len(r1cs.Constraints)
+	return r1cs.Constraints.Len()
+}
+
+// ConstraintsPerTag returns the number of constraints per tag label (see Tags.Count), or nil if
+// the circuit doesn't record any tag
+func (r1cs *R1CS) ConstraintsPerTag() map[string]int {
+	if r1cs.Tags == nil {
+		return nil
+	}
+	return r1cs.Tags.Count(r1cs.GetNbConstraints())
+}
+
+// CheckVersion returns an error if the R1CS was serialized in another format than R1CSVersion
+func (r1cs *R1CS) CheckVersion() error {
+	if r1cs.Version != R1CSVersion {
+		return fmt.Errorf("unsupported R1CS format version %d, expected %d", r1cs.Version, R1CSVersion)
+	}
+	return nil
 }
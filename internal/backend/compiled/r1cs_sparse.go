@@ -17,10 +17,40 @@ package compiled
 // R1CS decsribes a set of SparseR1C constraint
 type SparseR1CS struct {
 	CS
-	Constraints []SparseR1C
+	Version     int         `cbor:",omitempty"` // serialization format, SparseR1CSVersion
+	Constraints []SparseR1C `cbor:",omitempty"` // serialized in Gates (see Compact), except in the format 0
+	Gates       *GateList   `cbor:",omitempty"` // only set between Compact and Expand
+
+	// lookup tables, referenced by Lookups
+	Tables []LookupTable
+
+	// lookup constraints: the value of the L wire of Constraints[Lookup.Constraint]
+	// must be an entry of Tables[Lookup.Table]
+	Lookups []Lookup
+}
+
+// LookupTable is a named list of values
+type LookupTable struct {
+	Name    string
+	Entries []int // coefficient IDs of the entries
+}
+
+// Lookup binds a constraint to the table its L wire is looked up in
+type Lookup struct {
+	Constraint int
+	Table      int
 }
 
 // GetNbConstraints returns the number of constraints
 func (cs *SparseR1CS) GetNbConstraints() int {
 	return len(cs.Constraints)
 }
+
+// ConstraintsPerTag returns the number of constraints per tag label (see Tags.Count), or nil if
+// the circuit doesn't record any tag
+func (cs *SparseR1CS) ConstraintsPerTag() map[string]int {
+	if cs.Tags == nil {
+		return nil
+	}
+	return cs.Tags.Count(cs.GetNbConstraints())
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiled
+
+import "fmt"
+
+// SolveSchedule is the witness independent part of solving a R1CS: for each constraint, the hints
+// to call and the wire the constraint solves. It is built once with NewSolveSchedule, and can be
+// shared by concurrent solvers.
+type SolveSchedule struct {
+	Constraints []ScheduledR1C // one per constraint of the R1CS, in the same order
+}
+
+// ScheduledR1C describes how the solver processes a R1C
+type ScheduledR1C struct {
+	Hints []HintCall // hints to call before solving the constraint, in order
+	Loc   uint8      // 0 if the constraint solves no wire, 1, 2 or 3 if the solved wire is in L, R or O
+	Term  int        // index of the term of the solved wire in L, R or O
+}
+
+// HintCall is a call of a hint by the solver
+type HintCall struct {
+	Wire int // wire which triggered the call, one of Hint.Wires
+	Hint Hint
+}
+
+// NewSolveSchedule walks the constraints in the order of the solver and returns the resulting
+// schedule. It returns an error if a constraint has more than one wire to solve, or if a wire is
+// never solved.
+func (r1cs *R1CS) NewSolveSchedule() (*SolveSchedule, error) {
+	nbWires := r1cs.NbPublicVariables + r1cs.NbSecretVariables + r1cs.NbInternalVariables
+	solved := NewBitSet(nbWires)
+	nbSolved := 0
+	solve := func(wire int) {
+		solved.Set(wire)
+		nbSolved++
+	}
+
+	// public and secret wires are set by the witness, except the public outputs: they are solved by
+	// their hint (the wire 0 is the constant wire)
+	outputs := NewBitSet(r1cs.NbPublicVariables)
+	for _, o := range r1cs.PublicOutputs {
+		outputs.Set(o.Index + 1)
+	}
+	for i := 0; i < r1cs.NbPublicVariables+r1cs.NbSecretVariables; i++ {
+		if i < r1cs.NbPublicVariables && outputs.Get(i) {
+			continue
+		}
+		solve(i)
+	}
+
+	// a hint is called after the hints it depends on, see LinkHints
+	var scheduleHint func(s *ScheduledR1C, vID int, hint Hint, depth int) error
+	scheduleHint = func(s *ScheduledR1C, vID int, hint Hint, depth int) error {
+		if depth > len(r1cs.MHints) {
+			return fmt.Errorf("hint of wire %d: cyclic hint dependencies", vID)
+		}
+		for _, d := range hint.Deps {
+			if solved.Get(d) {
+				continue
+			}
+			if err := scheduleHint(s, d, r1cs.MHints[d], depth+1); err != nil {
+				return err
+			}
+		}
+		s.Hints = append(s.Hints, HintCall{Wire: vID, Hint: hint})
+		for _, w := range hint.Wires {
+			solve(w)
+		}
+		return nil
+	}
+
+	schedule := &SolveSchedule{Constraints: make([]ScheduledR1C, r1cs.Constraints.Len())}
+	var r1c R1C
+	for i := range schedule.Constraints {
+		r1cs.Constraints.Load(i, &r1c)
+		s := &schedule.Constraints[i]
+		toSolve := -1
+		for loc, l := range [3]LinearExpression{r1c.L, r1c.R, r1c.O} {
+			for j, t := range l {
+				vID := t.VariableID()
+				if solved.Get(vID) {
+					continue
+				}
+				if hint, ok := r1cs.MHints[vID]; ok {
+					if err := scheduleHint(s, vID, hint, 0); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				if s.Loc != 0 {
+					return nil, fmt.Errorf("constraint %d: found more than one wire to instantiate", i)
+				}
+				s.Loc, s.Term, toSolve = uint8(loc+1), j, vID
+			}
+		}
+		if toSolve != -1 {
+			solve(toSolve)
+		}
+	}
+
+	if nbSolved != nbWires {
+		return nil, fmt.Errorf("solver doesn't instantiate all wires: %d solved out of %d", nbSolved, nbWires)
+	}
+	return schedule, nil
+}
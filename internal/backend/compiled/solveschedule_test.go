@@ -0,0 +1,191 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compiled_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	"github.com/consensys/gnark/internal/backend/circuits"
+	"github.com/stretchr/testify/require"
+)
+
+// bitsCircuit decomposes each X in 64 bits
+type bitsCircuit struct {
+	X []frontend.Variable
+}
+
+func (circuit *bitsCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	for i := 0; i < len(circuit.X); i++ {
+		api.ToBinary(circuit.X[i], 64)
+	}
+	return nil
+}
+
+func newBitsCircuit(n int) *bitsCircuit {
+	return &bitsCircuit{X: make([]frontend.Variable, n)}
+}
+
+func newBitsWitness(n int) *bitsCircuit {
+	w := newBitsCircuit(n)
+	for i := 0; i < n; i++ {
+		w.X[i].Assign(rand.Uint64()) //#nosec G404 weak rng is fine here
+	}
+	return w
+}
+
+// newSolveVectors returns n a, b, c vectors for r1cs
+func newSolveVectors(r1cs *cs_bn254.R1CS, n int) (a, b, c [][]fr.Element) {
+	a, b, c = make([][]fr.Element, n), make([][]fr.Element, n), make([][]fr.Element, n)
+	for i := 0; i < n; i++ {
+		a[i] = make([]fr.Element, r1cs.GetNbConstraints())
+		b[i] = make([]fr.Element, r1cs.GetNbConstraints())
+		c[i] = make([]fr.Element, r1cs.GetNbConstraints())
+	}
+	return
+}
+
+// TestSolveMany checks that SolveMany computes the same wire values and a, b, c vectors (the inputs
+// of the Groth16 prover) as Solve, for the circuits of the registry
+func TestSolveMany(t *testing.T) {
+	assert := require.New(t)
+
+	keys := make([]string, 0, len(circuits.Circuits))
+	for k := range circuits.Circuits {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		tData := circuits.Circuits[k]
+		if tData.ExpectedCompileError != "" {
+			continue
+		}
+		ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, tData.Circuit)
+		assert.NoError(err, k)
+		r1cs := ccs.(*cs_bn254.R1CS)
+
+		schedule, err := r1cs.NewSolveSchedule()
+		assert.NoError(err, k)
+
+		for _, packBooleans := range []bool{false, true} {
+			opt, err := backend.NewProverOption(backend.WithHints(tData.HintFunctions...))
+			assert.NoError(err)
+			opt.PackBooleans = packBooleans
+
+			witnesses := make([][]fr.Element, 0, len(tData.ValidWitnesses))
+			for _, w := range tData.ValidWitnesses {
+				var witness witness_bn254.Witness
+				assert.NoError(witness.FromFullAssignment(w), k)
+				witnesses = append(witnesses, witness)
+			}
+
+			for _, nbTasks := range []int{1, 2} {
+				a, b, c := newSolveVectors(r1cs, len(witnesses))
+				values, err := r1cs.SolveMany(schedule, witnesses, a, b, c, opt, nbTasks)
+				assert.NoError(err, k)
+
+				for i := range witnesses {
+					ea, eb, ec := newSolveVectors(r1cs, 1)
+					expected, err := r1cs.Solve(witnesses[i], ea[0], eb[0], ec[0], opt)
+					assert.NoError(err, k)
+					assert.Equal(expected, values[i], "%s: wire values of witness %d", k, i)
+					assert.Equal(ea[0], a[i], "%s: a of witness %d", k, i)
+					assert.Equal(eb[0], b[i], "%s: b of witness %d", k, i)
+					assert.Equal(ec[0], c[i], "%s: c of witness %d", k, i)
+				}
+			}
+
+			for _, w := range tData.InvalidWitnesses {
+				var witness witness_bn254.Witness
+				assert.NoError(witness.FromFullAssignment(w), k)
+				a, b, c := newSolveVectors(r1cs, 1)
+				_, err := r1cs.SolveMany(schedule, [][]fr.Element{witness}, a, b, c, opt, 1)
+				assert.Error(err, k)
+			}
+		}
+	}
+}
+
+// BenchmarkSolveMany compares the per witness solving time of Solve and SolveMany on a circuit of
+// 200k constraints.
+func BenchmarkSolveMany(b *testing.B) {
+	const nbWitnesses = 4
+	const nbX = 200_000 / 65 // ToBinary(x, 64) is 65 constraints
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, newBitsCircuit(nbX))
+	if err != nil {
+		b.Fatal(err)
+	}
+	r1cs := ccs.(*cs_bn254.R1CS)
+
+	witnesses := make([][]fr.Element, nbWitnesses)
+	for i := range witnesses {
+		var w witness_bn254.Witness
+		if err := w.FromFullAssignment(newBitsWitness(nbX)); err != nil {
+			b.Fatal(err)
+		}
+		witnesses[i] = w
+	}
+	opt, err := backend.NewProverOption()
+	if err != nil {
+		b.Fatal(err)
+	}
+	va, vb, vc := newSolveVectors(r1cs, nbWitnesses)
+
+	run := func(b *testing.B, solve func() error) {
+		var elapsed time.Duration
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			start := time.Now()
+			if err := solve(); err != nil {
+				b.Fatal(err)
+			}
+			elapsed += time.Since(start)
+		}
+		b.ReportMetric(float64(elapsed.Microseconds())/float64(b.N*nbWitnesses), "µs/witness")
+	}
+
+	b.Run("solve", func(b *testing.B) {
+		run(b, func() error {
+			for i := range witnesses {
+				if _, err := r1cs.Solve(witnesses[i], va[i], vb[i], vc[i], opt); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+
+	b.Run("solve-many", func(b *testing.B) {
+		schedule, err := r1cs.NewSolveSchedule()
+		if err != nil {
+			b.Fatal(err)
+		}
+		run(b, func() error {
+			_, err := r1cs.SolveMany(schedule, witnesses, va, vb, vc, opt, 1)
+			return err
+		})
+	})
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compiled
+
+import (
+	"sort"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Tags records the scopes of the tags of a circuit (see frontend.API.Tag), and the constraints
+// created in each of them. They are only serialized with the constraint system if Serialize is set
+// (see frontend.WithSerializedTags)
+type Tags struct {
+	Labels []string   // the labels of the tags, nested ones joined with "/"; a scope refers to its label by index
+	Scopes []TagScope // in the order the scopes began: a scope comes after its parent
+
+	Serialize bool `cbor:"-"`
+}
+
+// TagScope is a scope of a tag: the constraints [Begin, End) were created in it, or in the scopes
+// nested in it
+type TagScope struct {
+	Label      int // index in Tags.Labels
+	Begin, End int
+	Parent     int // index of the enclosing scope in Tags.Scopes, or -1
+}
+
+// Innermost returns the index of the innermost scope in which the constraint cID was created, or -1
+func (t *Tags) Innermost(cID int) int {
+	// the last scope beginning at or before cID, or one of its ancestors, if any contains cID
+	s := sort.Search(len(t.Scopes), func(i int) bool { return t.Scopes[i].Begin > cID }) - 1
+	for ; s != -1; s = t.Scopes[s].Parent {
+		if cID < t.Scopes[s].End {
+			return s
+		}
+	}
+	return -1
+}
+
+// ScopeName returns the label of the innermost scope in which the constraint cID was created,
+// or an empty string
+func (t *Tags) ScopeName(cID int) string {
+	if s := t.Innermost(cID); s != -1 {
+		return t.Labels[t.Scopes[s].Label]
+	}
+	return ""
+}
+
+// Count returns the number of constraints per label, among the nbConstraints of the constraint
+// system: a constraint counts for the label of the innermost scope it was created in only, so that
+// the counts sum to nbConstraints. The constraints created out of any scope count for the empty
+// label, present only if there are some
+func (t *Tags) Count(nbConstraints int) map[string]int {
+	res := make(map[string]int, len(t.Labels)+1)
+	for _, label := range t.Labels {
+		res[label] = 0
+	}
+	res[""] = nbConstraints
+	for _, s := range t.Scopes {
+		n := s.End - s.Begin
+		res[t.Labels[s.Label]] += n
+		if s.Parent == -1 {
+			res[""] -= n
+		} else {
+			res[t.Labels[t.Scopes[s.Parent].Label]] -= n
+		}
+	}
+	if res[""] == 0 {
+		delete(res, "")
+	}
+	return res
+}
+
+// Remap returns the scopes for the constraints of another constraint system, the constraint i
+// having become the constraints [starts[i], starts[i+1]); len(starts) is the number of constraints
+// plus one
+func (t *Tags) Remap(starts []int) *Tags {
+	res := &Tags{Labels: t.Labels, Scopes: make([]TagScope, len(t.Scopes)), Serialize: t.Serialize}
+	for i, s := range t.Scopes {
+		s.Begin, s.End = starts[s.Begin], starts[s.End]
+		res.Scopes[i] = s
+	}
+	return res
+}
+
+// MarshalCBOR implements cbor.Marshaler: the tags are encoded as nil unless Serialize is set
+func (t *Tags) MarshalCBOR() ([]byte, error) {
+	if !t.Serialize {
+		return cbor.Marshal(nil)
+	}
+	enc, err := cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		return nil, err
+	}
+	type tags Tags // without the methods of Tags
+	return enc.Marshal((*tags)(t))
+}
+
+// UnmarshalCBOR implements cbor.Unmarshaler; the tags read were serialized, so they are again
+func (t *Tags) UnmarshalCBOR(data []byte) error {
+	type tags Tags // without the methods of Tags
+	if err := cbor.Unmarshal(data, (*tags)(t)); err != nil {
+		return err
+	}
+	t.Serialize = true
+	return nil
+}
@@ -15,6 +15,7 @@
 package compiled
 
 import (
+	"errors"
 	"math/big"
 	"strconv"
 	"strings"
@@ -51,6 +52,21 @@ const (
 	nbBitsVariableVisibility = 3
 )
 
+// capacity of the packed Term; a constraint system can't have more wires (resp. distinct coefficients)
+// than what fits in the bits reserved for the variable ID (resp. the coefficient ID)
+const (
+	MaxNbVariables    = 1 << nbBitsVariableID
+	MaxNbCoefficients = 1 << nbBitsCoeffID
+)
+
+var (
+	// ErrTooManyWires is returned when a constraint system has more than MaxNbVariables wires
+	ErrTooManyWires = errors.New("too many wires: a Term can't address more than 2^29 variables")
+
+	// ErrTooManyCoefficients is returned when a constraint system has more than MaxNbCoefficients coefficients
+	ErrTooManyCoefficients = errors.New("too many coefficients: a Term can't address more than 2^30 coefficients")
+)
+
 // TermDelimitor is reserved for internal use
 // the constraint solver will evaluate the sum of all terms appearing between two TermDelimitor
 const TermDelimitor Term = Term(maskDelimitor)
@@ -84,6 +100,12 @@ func Pack(variableID, coeffID int, variableVisiblity Visibility) Term {
 	return t
 }
 
+// IsConstant returns true if the term is a coefficient times the ONE_WIRE, that is the first
+// public variable of the frontend constraint system; such a term is the constant of a linear expression
+func (t Term) IsConstant() bool {
+	return t.VariableID() == 0 && t.VariableVisibility() == Public
+}
+
 // Unpack returns coeffID, variableID and visibility
 func (t Term) Unpack() (coeffID, variableID int, variableVisiblity Visibility) {
 	coeffID = t.CoeffID()
@@ -132,7 +154,7 @@ func (t *Term) SetVariableVisibility(v Visibility) {
 func (t *Term) SetCoeffID(cID int) {
 	_coeffID := uint64(cID)
 	if (_coeffID & (maskCoeffID >> shiftCoeffID)) != uint64(cID) {
-		panic("coeffID is too large, unsupported")
+		panic(ErrTooManyCoefficients)
 	}
 	_coeffID <<= shiftCoeffID
 	*t = Term((uint64(*t) & (^maskCoeffID)) | _coeffID)
@@ -142,7 +164,7 @@ func (t *Term) SetCoeffID(cID int) {
 func (t *Term) SetVariableID(cID int) {
 	_variableID := uint64(cID)
 	if (_variableID & maskVariableID) != uint64(cID) {
-		panic("variableID is too large, unsupported")
+		panic(ErrTooManyWires)
 	}
 	*t = Term((uint64(*t) & (^maskVariableID)) | _variableID)
 }
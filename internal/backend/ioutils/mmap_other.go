@@ -0,0 +1,17 @@
+//go:build !linux
+// +build !linux
+
+package ioutils
+
+import "os"
+
+// MapFile reads the file at path in memory.
+// On this platform memory mapping is not supported and the file is fully loaded
+// on the heap; unmap is a no-op.
+func MapFile(path string) (data []byte, unmap func() error, err error) {
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package curves lists the curves supported by gnark
+package curves
+
+import "github.com/consensys/gnark-crypto/ecc"
+
+// Implemented returns the curves supported by gnark: the ones of ecc.Implemented, which doesn't
+// list BW6_633, and BW6_633
+func Implemented() []ecc.ID {
+	return append(ecc.Implemented(), ecc.BW6_633)
+}
+
+// IsImplemented returns true if curveID is one of Implemented
+func IsImplemented(curveID ecc.ID) bool {
+	for _, id := range Implemented() {
+		if id == curveID {
+			return true
+		}
+	}
+	return false
+}
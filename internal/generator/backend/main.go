@@ -46,7 +46,13 @@ func main() {
 		CurveID:  "BLS24_315",
 		Package:  "bls24315",
 	}
-	datas := []templateData{bls12_377, bls12_381, bn254, bw6_761, bls24_315}
+	bw6_633 := templateData{
+		RootPath: "../../../internal/backend/bw6-633/",
+		Curve:    "BW6-633",
+		CurveID:  "BW6_633",
+		Package:  "bw6633",
+	}
+	datas := []templateData{bls12_377, bls12_381, bn254, bw6_761, bls24_315, bw6_633}
 
 	const importCurve = "../imports.go.tmpl"
 
@@ -77,6 +83,10 @@ func main() {
 				{File: filepath.Join(backendCSDir, "r1cs.go"), Templates: []string{"r1cs.go.tmpl", importCurve}},
 				{File: filepath.Join(backendCSDir, "r1cs_sparse.go"), Templates: []string{"r1cs.sparse.go.tmpl", importCurve}},
 				{File: filepath.Join(backendCSDir, "solution.go"), Templates: []string{"solution.go.tmpl", importCurve}},
+				{File: filepath.Join(backendCSDir, "solution_cache.go"), Templates: []string{"solution_cache.go.tmpl", importCurve}},
+				{File: filepath.Join(backendCSDir, "checkpoint.go"), Templates: []string{"checkpoint.go.tmpl", importCurve}},
+				{File: filepath.Join(backendCSDir, "hints.go"), Templates: []string{"hints.go.tmpl", importCurve}},
+				{File: filepath.Join(backendCSDir, "hints_test.go"), Templates: []string{"tests/hints.go.tmpl", importCurve}},
 			}
 			if err := bgen.Generate(d, "cs", "./template/representations/", entries...); err != nil {
 				panic(err)
@@ -91,6 +101,7 @@ func main() {
 
 			entries = []bavard.Entry{
 				{File: filepath.Join(witnessDir, "witness.go"), Templates: []string{"witness.go.tmpl", importCurve}},
+				{File: filepath.Join(witnessDir, "witness_test.go"), Templates: []string{"tests/witness.go.tmpl", importCurve}},
 			}
 			if err := bgen.Generate(d, "witness", "./template/representations/", entries...); err != nil {
 				panic(err)
@@ -100,13 +111,28 @@ func main() {
 				{File: filepath.Join(groth16Dir, "verify.go"), Templates: []string{"groth16/groth16.verify.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "prove.go"), Templates: []string{"groth16/groth16.prove.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "setup.go"), Templates: []string{"groth16/groth16.setup.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "precompute.go"), Templates: []string{"groth16/groth16.precompute.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "marshal.go"), Templates: []string{"groth16/groth16.marshal.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "marshal_test.go"), Templates: []string{"groth16/tests/groth16.marshal.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "setup_test.go"), Templates: []string{"groth16/tests/groth16.setup.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "precompute_test.go"), Templates: []string{"groth16/tests/groth16.precompute.go.tmpl", importCurve}},
 			}
 			if err := bgen.Generate(d, "groth16", "./template/zkpschemes/", entries...); err != nil {
 				panic(err) // TODO handle
 			}
 
+			// groth16 verifier-only package, see backend/groth16
+			verifierDir := filepath.Join("../../../backend/groth16", d.Package+"verifier")
+			if err := os.MkdirAll(verifierDir, 0700); err != nil {
+				panic(err)
+			}
+			entries = []bavard.Entry{
+				{File: filepath.Join(verifierDir, "verifier.go"), Templates: []string{"groth16/groth16.verifier.go.tmpl", importCurve}},
+			}
+			if err := bgen.Generate(d, d.Package+"verifier", "./template/zkpschemes/", entries...); err != nil {
+				panic(err)
+			}
+
 			entries = []bavard.Entry{
 				{File: filepath.Join(groth16Dir, "groth16_test.go"), Templates: []string{"groth16/tests/groth16.go.tmpl", importCurve}},
 			}
@@ -119,6 +145,7 @@ func main() {
 				{File: filepath.Join(plonkDir, "verify.go"), Templates: []string{"plonk/plonk.verify.go.tmpl", importCurve}},
 				{File: filepath.Join(plonkDir, "prove.go"), Templates: []string{"plonk/plonk.prove.go.tmpl", importCurve}},
 				{File: filepath.Join(plonkDir, "setup.go"), Templates: []string{"plonk/plonk.setup.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkDir, "lookup.go"), Templates: []string{"plonk/plonk.lookup.go.tmpl", importCurve}},
 				{File: filepath.Join(plonkDir, "marshal.go"), Templates: []string{"plonk/plonk.marshal.go.tmpl", importCurve}},
 				{File: filepath.Join(plonkDir, "marshal_test.go"), Templates: []string{"plonk/tests/marshal.go.tmpl", importCurve}},
 			}
@@ -151,7 +178,7 @@ func main() {
 
 type templateData struct {
 	RootPath string
-	Curve    string // BLS381, BLS377, BN254, BW761
+	Curve    string // BLS381, BLS377, BN254, BW761, BW633
 	Package  string
 	CurveID  string
 }
@@ -0,0 +1,226 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package normalize gives a curve agnostic and canonical view of compiled constraint systems,
+// to compare them
+package normalize
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/consensys/gnark/frontend"
+	cs_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
+	cs_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
+	cs_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	cs_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
+	cs_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+	"github.com/consensys/gnark/internal/backend/compiled"
+)
+
+// System is a curve agnostic view of a compiled constraint system
+type System struct {
+	R1CS   *compiled.R1CS       // nil if the system is a SparseR1CS
+	Sparse *compiled.SparseR1CS // nil if the system is a R1CS
+
+	// Coefficients are the coefficients of the system, written in decimal
+	Coefficients []string
+}
+
+// CS returns the elements common to R1CS and SparseR1CS
+func (s System) CS() *compiled.CS {
+	if s.R1CS != nil {
+		return &s.R1CS.CS
+	}
+	return &s.Sparse.CS
+}
+
+// View returns the curve agnostic view of ccs. It panics if ccs is not one of the R1CS or
+// SparseR1CS types of the internal backends.
+func View(ccs frontend.CompiledConstraintSystem) System {
+	var s System
+	switch c := ccs.(type) {
+	case *cs_bn254.R1CS:
+		s.R1CS = &c.R1CS
+		s.Coefficients = coefficientsToString(c.Coefficients)
+	case *cs_bls12377.R1CS:
+		s.R1CS = &c.R1CS
+		s.Coefficients = coefficientsToString(c.Coefficients)
+	case *cs_bls12381.R1CS:
+		s.R1CS = &c.R1CS
+		s.Coefficients = coefficientsToString(c.Coefficients)
+	case *cs_bw6761.R1CS:
+		s.R1CS = &c.R1CS
+		s.Coefficients = coefficientsToString(c.Coefficients)
+	case *cs_bw6633.R1CS:
+		s.R1CS = &c.R1CS
+		s.Coefficients = coefficientsToString(c.Coefficients)
+	case *cs_bls24315.R1CS:
+		s.R1CS = &c.R1CS
+		s.Coefficients = coefficientsToString(c.Coefficients)
+	case *cs_bn254.SparseR1CS:
+		s.Sparse = &c.SparseR1CS
+		s.Coefficients = coefficientsToString(c.Coefficients)
+	case *cs_bls12377.SparseR1CS:
+		s.Sparse = &c.SparseR1CS
+		s.Coefficients = coefficientsToString(c.Coefficients)
+	case *cs_bls12381.SparseR1CS:
+		s.Sparse = &c.SparseR1CS
+		s.Coefficients = coefficientsToString(c.Coefficients)
+	case *cs_bw6761.SparseR1CS:
+		s.Sparse = &c.SparseR1CS
+		s.Coefficients = coefficientsToString(c.Coefficients)
+	case *cs_bw6633.SparseR1CS:
+		s.Sparse = &c.SparseR1CS
+		s.Coefficients = coefficientsToString(c.Coefficients)
+	case *cs_bls24315.SparseR1CS:
+		s.Sparse = &c.SparseR1CS
+		s.Coefficients = coefficientsToString(c.Coefficients)
+	default:
+		panic("unrecognized constraint system type")
+	}
+	return s
+}
+
+// Constraints returns a canonical representation of the constraint system: a sorted list
+// of constraints, lookups and hints, where internal wires are renumbered in order of first appearance
+// and coefficients are written by value
+func Constraints(ccs frontend.CompiledConstraintSystem) []string {
+	return View(ccs).Constraints()
+}
+
+// Constraints returns a canonical representation of the constraint system, see Constraints
+func (s System) Constraints() []string {
+	constraints, hints := s.Canonical()
+	res := append(constraints, hints...)
+	sort.Strings(res)
+	return res
+}
+
+// Canonical returns the canonical representations of the constraints (and lookups) and of
+// the hints of the system, each sorted; the wires are numbered consistently across both lists
+func (s System) Canonical() (constraints, hints []string) {
+	r1cs, sparse, coeffs := s.R1CS, s.Sparse, s.Coefficients
+
+	n := normalizer{coeffs: coeffs, ids: make(map[int]int)}
+
+	if r1cs != nil {
+		n.nbInputs = r1cs.NbPublicVariables + r1cs.NbSecretVariables
+		var r1c compiled.R1C
+		for i := 0; i < r1cs.Constraints.Len(); i++ {
+			r1cs.Constraints.Load(i, &r1c)
+			constraints = append(constraints, n.linearExpression(r1c.L)+"*"+n.linearExpression(r1c.R)+"="+n.linearExpression(r1c.O))
+		}
+		hints = n.hints(r1cs.MHints)
+	} else {
+		n.nbInputs = sparse.NbPublicVariables + sparse.NbSecretVariables
+		for _, c := range sparse.Constraints {
+			terms := []string{n.term(c.L), n.term(c.R), n.term(c.M[0]), n.term(c.M[1]), n.term(c.O), coeffs[c.K]}
+			constraints = append(constraints, strings.Join(terms, "|"))
+		}
+		for _, l := range sparse.Lookups {
+			entries := make([]string, len(sparse.Tables[l.Table].Entries))
+			for i, cID := range sparse.Tables[l.Table].Entries {
+				entries[i] = coeffs[cID]
+			}
+			constraints = append(constraints, n.term(sparse.Constraints[l.Constraint].L)+" in ["+strings.Join(entries, ",")+"]")
+		}
+		hints = n.hints(sparse.MHints)
+	}
+
+	sort.Strings(constraints)
+	sort.Strings(hints)
+	return
+}
+
+type normalizer struct {
+	coeffs   []string
+	nbInputs int         // public and secret wires keep their IDs
+	ids      map[int]int // internal wire ID -> canonical ID
+}
+
+func (n *normalizer) wire(vID int) string {
+	if vID < n.nbInputs {
+		return "w" + strconv.Itoa(vID)
+	}
+	id, ok := n.ids[vID]
+	if !ok {
+		id = len(n.ids)
+		n.ids[vID] = id
+	}
+	return "i" + strconv.Itoa(id)
+}
+
+func (n *normalizer) term(t compiled.Term) string {
+	if t == 0 {
+		return "0"
+	}
+	cID, vID, visibility := t.Unpack()
+	if visibility == compiled.Virtual {
+		return n.coeffs[cID]
+	}
+	return n.coeffs[cID] + "." + n.wire(vID)
+}
+
+func (n *normalizer) linearExpression(l compiled.LinearExpression) string {
+	terms := make([]string, len(l))
+	for i, t := range l {
+		terms[i] = n.term(t)
+	}
+	sort.Strings(terms)
+	return "(" + strings.Join(terms, "+") + ")"
+}
+
+func (n *normalizer) hints(mHints map[int]compiled.Hint) []string {
+	// iterate over the hints in wire order, for the renumbering to be deterministic
+	vIDs := make([]int, 0, len(mHints))
+	for vID := range mHints {
+		vIDs = append(vIDs, vID)
+	}
+	sort.Ints(vIDs)
+
+	res := make([]string, 0, len(mHints))
+	for _, vID := range vIDs {
+		h := mHints[vID]
+		inputs := make([]string, len(h.Inputs))
+		for i, in := range h.Inputs {
+			inputs[i] = n.linearExpression(in)
+		}
+		output := ""
+		if len(h.Wires) > 1 {
+			for i, w := range h.Wires {
+				if w == vID {
+					output = "[" + strconv.Itoa(i) + "]"
+				}
+			}
+		}
+		res = append(res, n.wire(vID)+"=hint"+strconv.Itoa(int(h.ID))+output+"("+strings.Join(inputs, ",")+")")
+	}
+	return res
+}
+
+func coefficientsToString(coeffs interface{}) []string {
+	v := reflect.ValueOf(coeffs)
+	res := make([]string, v.Len())
+	for i := 0; i < len(res); i++ {
+		res[i] = v.Index(i).Addr().Interface().(fmt.Stringer).String()
+	}
+	return res
+}
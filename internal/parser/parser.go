@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/consensys/gnark/logger"
 )
 
 // Tag is a (optional) struct tag one can add to Variable
@@ -27,28 +28,179 @@ import (
 //			Z frontend.Variable `gnark:"-"`
 // 		}
 // it is then the developer responsability to do circuit.Z = circuit.Y in the Define() method
+//
+// "output" marks a variable set by the Define() method as an output of the circuit, see
+// frontend.API.MarkOutput. It is not an input: like "-", it is ignored when allocating the inputs
+//
+// "optional" marks a secret input (and its sub-fields) which may be missing from a witness, in which
+// case it is assigned 0. Public inputs can't be optional
+//
+// "public,output" marks a public input (and its sub-fields) whose value is computed by the Define()
+// method, see frontend.API.SetOutput: the solver assigns it, it may be missing from a full witness
 type Tag string
 
 const (
-	tagKey    Tag = "gnark"
-	optPublic Tag = "public"
-	optSecret Tag = "secret"
-	optEmbed  Tag = "embed"
-	optOmit   Tag = "-"
+	tagKey      Tag = "gnark"
+	optPublic   Tag = "public"
+	optSecret   Tag = "secret"
+	optEmbed    Tag = "embed"
+	optOutput   Tag = "output"
+	optOptional Tag = "optional"
+	optOmit     Tag = "-"
 )
 
 func appendName(baseName, name string) string {
 	if baseName == "" {
 		return name
 	}
+	if name == "" {
+		return baseName // embedded field
+	}
 	return baseName + "_" + name
 }
 
+func appendPath(basePath, name string) string {
+	if basePath == "" {
+		return name
+	}
+	return basePath + "." + name
+}
+
 // LeafHandler is the handler function that will be called when Visit reaches leafs of the struct
 type LeafHandler func(visibility compiled.Visibility, name string, tValue reflect.Value) error
 
+// VisibilityOverrider is implemented by inputs whose fields visibility is overridden.
+// Visit parses the returned input instead, and the visibility of the fields at the given paths
+// takes precedence over their gnark tag (and over the visibility of their parent).
+// A path is the dotted list of the Go field names (or slice indexes) leading to the field,
+// for example "Inner.X" or "Points.0.X"
+type VisibilityOverrider interface {
+	OverriddenVisibility() (input interface{}, overrides map[string]compiled.Visibility)
+}
+
+// Leaf is an input of a circuit, as found by Walk
+type Leaf struct {
+	Visibility compiled.Visibility
+	Optional   bool   // the leaf is a secret input tagged "optional" (or has such a parent)
+	Explicit   bool   // the visibility is given by a gnark tag (or an override) of the leaf or of one of its parents
+	Output     bool   // the leaf is a public input tagged "public,output" (or has such a parent)
+	Name       string // name of the leaf, as given to a LeafHandler by Visit
+	Path       string // dotted list of the Go field names (or slice indexes) leading to the leaf
+	Index      []int  // struct field number or slice index leading to the leaf, at each level
+}
+
+// Array is a slice or an array of a circuit, as found by Walk
+type Array struct {
+	Path  string
+	Index []int
+	Len   int
+}
+
 // Visit using reflect, browse through exposed addressable fields from input, and calls handler() if leaf.type == target
 func Visit(input interface{}, baseName string, parentVisibility compiled.Visibility, handler LeafHandler, target reflect.Type) error {
+	v := visitor{
+		target: target,
+		handler: func(visibility compiled.Visibility, optional, explicit, output bool, name, path string, index []int, tValue reflect.Value) error {
+			return handler(visibility, name, tValue)
+		},
+	}
+	return v.visitOverridden(input, baseName, parentVisibility)
+}
+
+// VisitOutputs browses through input like Visit, and calls handler() on the leafs of the fields tagged
+// "output" (and of their sub-fields), with visibility compiled.Virtual. The other leafs are ignored.
+func VisitOutputs(input interface{}, handler LeafHandler, target reflect.Type) error {
+	v := visitor{
+		target:  target,
+		outputs: true,
+		handler: func(visibility compiled.Visibility, optional, explicit, output bool, name, path string, index []int, tValue reflect.Value) error {
+			if visibility != compiled.Virtual {
+				return nil
+			}
+			return handler(visibility, name, tValue)
+		},
+	}
+	return v.visitOverridden(input, "", compiled.Unset)
+}
+
+// Walk browses through input like Visit, and returns the leafs of type target, and the
+// (non empty) slices and arrays leading to them, in the order of the traversal.
+//
+// If strict is set, the exported fields which can't hold a target (int, string, map, ...) must be tagged "-",
+// instead of being ignored.
+func Walk(input interface{}, target reflect.Type, strict bool) (leafs []Leaf, arrays []Array, err error) {
+	v := visitor{
+		target: target,
+		strict: strict,
+		handler: func(visibility compiled.Visibility, optional, explicit, output bool, name, path string, index []int, tValue reflect.Value) error {
+			leafs = append(leafs, Leaf{Visibility: visibility, Optional: optional, Explicit: explicit, Output: output, Name: name, Path: path, Index: index})
+			return nil
+		},
+		arrayHandler: func(path string, index []int, length int) {
+			arrays = append(arrays, Array{Path: path, Index: index, Len: length})
+		},
+	}
+	if err := v.visitOverridden(input, "", compiled.Unset); err != nil {
+		return nil, nil, err
+	}
+	return leafs, arrays, nil
+}
+
+type visitor struct {
+	target       reflect.Type
+	handler      func(visibility compiled.Visibility, optional, explicit, output bool, name, path string, index []int, tValue reflect.Value) error
+	arrayHandler func(path string, index []int, length int)
+	overrides    map[string]compiled.Visibility
+	seen         map[string]struct{}
+	outputs      bool // visit the fields tagged "output" instead of skipping them, see VisitOutputs
+	strict       bool // reject the exported fields which can't hold a target, see Walk
+}
+
+func (v *visitor) visitOverridden(input interface{}, baseName string, parentVisibility compiled.Visibility) error {
+	o, ok := input.(VisibilityOverrider)
+	if !ok {
+		return v.visit(input, baseName, "", nil, parentVisibility, false, false, false)
+	}
+
+	input, v.overrides = o.OverriddenVisibility()
+	for path, visibility := range v.overrides {
+		if visibility != compiled.Secret && visibility != compiled.Public {
+			return fmt.Errorf("invalid visibility override for %q: must be secret or public", path)
+		}
+	}
+	v.seen = make(map[string]struct{}, len(v.overrides))
+	if err := v.visit(input, baseName, "", nil, parentVisibility, false, false, false); err != nil {
+		return err
+	}
+	for path := range v.overrides {
+		if _, ok := v.seen[path]; !ok {
+			return fmt.Errorf("invalid visibility override: unknown field %q", path)
+		}
+	}
+	return nil
+}
+
+// appendIndex returns a copy of index, with i appended
+func appendIndex(index []int, i int) []int {
+	res := make([]int, len(index)+1)
+	copy(res, index)
+	res[len(index)] = i
+	return res
+}
+
+// canHold returns true if a field of type t can hold a target, that is if t is a struct, or a pointer, a slice or an array of such
+func canHold(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return canHold(t.Elem())
+	default:
+		return false
+	}
+}
+
+func (v *visitor) visit(input interface{}, baseName, path string, index []int, parentVisibility compiled.Visibility, parentOptional, parentExplicit, parentOutput bool) error {
 
 	// types we are lOoutputoking for
 	// tVariable := reflect.TypeOf(frontend.Variable{})
@@ -64,8 +216,14 @@ func Visit(input interface{}, baseName string, parentVisibility compiled.Visibil
 	switch tValue.Kind() {
 	case reflect.Struct:
 		switch tValue.Type() {
-		case target:
-			return handler(parentVisibility, baseName, tValue)
+		case v.target:
+			if parentOptional && parentVisibility == compiled.Public {
+				return fmt.Errorf("%s: public inputs can't be optional", path)
+			}
+			if parentOutput && parentVisibility != compiled.Public {
+				return fmt.Errorf("%s: only public inputs can be outputs", path)
+			}
+			return v.handler(parentVisibility, parentOptional, parentExplicit, parentOutput, baseName, path, index, tValue)
 		default:
 			for i := 0; i < tValue.NumField(); i++ {
 				field := tValue.Type().Field((i))
@@ -76,7 +234,14 @@ func Visit(input interface{}, baseName string, parentVisibility compiled.Visibil
 					continue // skipping "-"
 				}
 
+				if v.strict && field.PkgPath == "" && !canHold(field.Type) {
+					return fmt.Errorf("%s: exported field of type %s isn't an input, it must be tagged `gnark:\"-\"`", appendPath(path, field.Name), field.Type)
+				}
+
 				visibility := compiled.Secret
+				optional := parentOptional
+				explicit := parentExplicit
+				output := parentOutput
 				name := field.Name
 
 				if tag != "" {
@@ -87,35 +252,57 @@ func Visit(input interface{}, baseName string, parentVisibility compiled.Visibil
 						name = field.Name
 					}
 					opts = tagOptions(strings.TrimSpace(string(opts)))
-					if opts == "" || opts.Contains(string(optSecret)) {
+					if opts.Contains(string(optOptional)) {
+						optional = true
+					}
+					if opts == "" || opts == tagOptions(optOptional) || opts.Contains(string(optSecret)) {
 						visibility = compiled.Secret
+						explicit = explicit || opts.Contains(string(optSecret))
+						if opts.Contains(string(optOutput)) {
+							return fmt.Errorf("%s: only public inputs can be outputs", appendPath(path, field.Name))
+						}
 					} else if opts.Contains(string(optPublic)) {
 						visibility = compiled.Public
+						explicit = true
+						output = output || opts.Contains(string(optOutput))
 					} else if opts.Contains(string(optEmbed)) {
 						name = ""
 						visibility = compiled.Unset
+					} else if opts.Contains(string(optOutput)) {
+						if !v.outputs {
+							continue // outputs are not inputs
+						}
+						visibility = compiled.Virtual
 					} else {
-						return errors.New("invalid gnark struct tag option. must be \"public\", \"secret\",\"embed\" or \"-\"")
+						return errors.New("invalid gnark struct tag option. must be \"public\", \"secret\",\"embed\", \"output\", \"optional\" or \"-\"")
 					}
 				}
-				if parentVisibility != compiled.Unset {
+				if parentVisibility != compiled.Unset && visibility != compiled.Virtual {
 					visibility = parentVisibility // parent visibility overhides
+					explicit = parentExplicit
+				}
+
+				fieldPath := appendPath(path, field.Name)
+				if o, ok := v.overrides[fieldPath]; ok {
+					visibility = o
+					explicit = true
+					v.seen[fieldPath] = struct{}{}
 				}
 
 				fullName := appendName(baseName, name)
 
-				f := tValue.FieldByName(field.Name)
+				f := tValue.Field(i)
 				if f.CanAddr() && f.Addr().CanInterface() {
 					value := f.Addr().Interface()
-					if err := Visit(value, fullName, visibility, handler, target); err != nil {
+					if err := v.visit(value, fullName, fieldPath, appendIndex(index, i), visibility, optional, explicit, output); err != nil {
 						return err
 					}
 				} else {
 					if f.Kind() == reflect.Ptr {
 						f = f.Elem()
 					}
-					if (f.Kind() == reflect.Struct) && (f.Type() == target) {
-						fmt.Println("warning: Variable is unexported or unadressable", fullName)
+					if (f.Kind() == reflect.Struct) && (f.Type() == v.target) {
+						logger.Warn("Variable is unexported or unadressable: %s", fullName)
 					}
 				}
 			}
@@ -123,21 +310,30 @@ func Visit(input interface{}, baseName string, parentVisibility compiled.Visibil
 
 	case reflect.Slice, reflect.Array:
 		if tValue.Len() == 0 {
-			fmt.Printf("%s: ignoring unitizalized slice (or empty array)\n", baseName)
+			logger.Warn("%s: ignoring unitizalized slice (or empty array)", baseName)
 			return nil
 		}
+		if v.arrayHandler != nil {
+			v.arrayHandler(path, index, tValue.Len())
+		}
 		for j := 0; j < tValue.Len(); j++ {
 
 			val := tValue.Index(j)
 			if val.CanAddr() && val.Addr().CanInterface() {
-				if err := Visit(val.Addr().Interface(), appendName(baseName, strconv.Itoa(j)), parentVisibility, handler, target); err != nil {
+				elemPath := appendPath(path, strconv.Itoa(j))
+				visibility, explicit := parentVisibility, parentExplicit
+				if o, ok := v.overrides[elemPath]; ok {
+					visibility, explicit = o, true
+					v.seen[elemPath] = struct{}{}
+				}
+				if err := v.visit(val.Addr().Interface(), appendName(baseName, strconv.Itoa(j)), elemPath, appendIndex(index, j), visibility, parentOptional, explicit, parentOutput); err != nil {
 					return err
 				}
 			}
 
 		}
 	case reflect.Map:
-		fmt.Println("warning: map values are not addressable, ignoring")
+		logger.Warn("map values are not addressable, ignoring")
 	}
 
 	return nil
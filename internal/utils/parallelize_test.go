@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestParallelize(t *testing.T) {
+	const nbIterations = 100
+	for _, maxCpus := range [][]int{nil, {1}, {3}, {nbIterations + 1}, {0}, {-1}} {
+		var counts [nbIterations]int32
+		Parallelize(nbIterations, func(start, end int) {
+			for i := start; i < end; i++ {
+				atomic.AddInt32(&counts[i], 1)
+			}
+		}, maxCpus...)
+		for i, c := range counts {
+			if c != 1 {
+				t.Fatalf("maxCpus %v: iteration %d done %d times", maxCpus, i, c)
+			}
+		}
+	}
+}
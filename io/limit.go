@@ -0,0 +1,205 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync/atomic"
+)
+
+// DefaultMaxReadBytes is the default of MaxReadBytes
+const DefaultMaxReadBytes int64 = 1 << 36
+
+// maxReadBytes is the cap set by SetMaxReadBytes
+var maxReadBytes = DefaultMaxReadBytes
+
+// ErrLimitExceeded is returned by the ReadFrom implementations, wrapped in a *LimitExceededError, when
+// the object read (or an allocation derived from its length fields) is larger than MaxReadBytes, or
+// than what is left in the reader
+var ErrLimitExceeded = errors.New("read limit exceeded")
+
+// LimitExceededError is the error returned when reading an object exceeds a limit, see ErrLimitExceeded
+type LimitExceededError struct {
+	What  string // field or object whose size exceeds the limit
+	Size  int64  // size in bytes claimed by the length fields, or read
+	Limit int64  // MaxReadBytes, or the number of bytes left in the reader
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s: %s needs %d bytes, limit is %d", ErrLimitExceeded, e.What, e.Size, e.Limit)
+}
+
+func (e *LimitExceededError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// SetMaxReadBytes sets the maximum number of bytes the ReadFrom implementations of gnark objects (constraint
+// systems, keys, proofs, witnesses) read and allocate for an object, and returns the previous value. The
+// allocations derived from length fields are also checked against the remaining length of the reader, when
+// it is known (bytes.Reader, bytes.Buffer, strings.Reader, os.File, io.LimitedReader). n <= 0 restores
+// DefaultMaxReadBytes.
+func SetMaxReadBytes(n int64) int64 {
+	if n <= 0 {
+		n = DefaultMaxReadBytes
+	}
+	return atomic.SwapInt64(&maxReadBytes, n)
+}
+
+// MaxReadBytes returns the maximum number of bytes read for an object, see SetMaxReadBytes
+func MaxReadBytes() int64 {
+	return atomic.LoadInt64(&maxReadBytes)
+}
+
+// LimitReader wraps the reader of a ReadFrom implementation to bound what it reads and allocates. It fails
+// with a *LimitExceededError once more than MaxReadBytes are read, and checks the length prefixes of the
+// slices before they are decoded (see CheckSlice). It never reads ahead of what is consumed: the bytes
+// peeked by CheckSlice are returned by the next Read.
+type LimitReader struct {
+	r      io.Reader
+	read   int64 // bytes read from r, peeked included
+	left   int64 // bytes left in r when it was wrapped, -1 if unknown
+	limit  int64
+	peeked []byte
+}
+
+// NewLimitReader returns a LimitReader reading from r, or r if it is already one, such that nested
+// ReadFrom calls share the limit
+func NewLimitReader(r io.Reader) *LimitReader {
+	if lr, ok := r.(*LimitReader); ok {
+		return lr
+	}
+	return &LimitReader{r: r, left: remaining(r), limit: MaxReadBytes()}
+}
+
+// Read implements io.Reader
+func (r *LimitReader) Read(p []byte) (int, error) {
+	if len(r.peeked) != 0 {
+		n := copy(p, r.peeked)
+		r.peeked = r.peeked[n:]
+		return n, nil
+	}
+	if r.read >= r.limit {
+		return 0, &LimitExceededError{What: "input", Size: r.read + 1, Limit: r.limit}
+	}
+	if int64(len(p)) > r.limit-r.read {
+		p = p[:r.limit-r.read]
+	}
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+	return n, err
+}
+
+// CheckSlice checks the uint32 length prefix of the next slice (as written by the gnark-crypto encoders),
+// of elements of encodedSize bytes in the reader and memSize bytes in memory: it returns a
+// *LimitExceededError if they don't fit in what is left in the reader, or in MaxReadBytes. A truncated
+// prefix isn't an error here, the decoder reports it.
+func (r *LimitReader) CheckSlice(what string, encodedSize, memSize int) error {
+	b, err := r.Peek(4)
+	if err != nil {
+		return nil
+	}
+	return r.check(what, uint64(binary.BigEndian.Uint32(b)), encodedSize, memSize, 4)
+}
+
+// Check returns a *LimitExceededError if n elements of encodedSize bytes don't fit in what is left in the
+// reader, or if n elements of memSize bytes don't fit in MaxReadBytes
+func (r *LimitReader) Check(what string, n uint64, encodedSize, memSize int) error {
+	return r.check(what, n, encodedSize, memSize, 0)
+}
+
+// check is Check, the elements being after the next skip bytes
+func (r *LimitReader) check(what string, n uint64, encodedSize, memSize, skip int) error {
+	if n == 0 {
+		return nil
+	}
+	if mem := mulSize(n, memSize); mem > r.limit {
+		return &LimitExceededError{What: what, Size: mem, Limit: r.limit}
+	}
+	if r.left >= 0 {
+		left := r.left - r.read + int64(len(r.peeked)-skip)
+		if encoded := mulSize(n, encodedSize); encoded > left {
+			return &LimitExceededError{What: what, Size: encoded, Limit: left}
+		}
+	}
+	return nil
+}
+
+// Peek returns the next n bytes, without consuming them
+func (r *LimitReader) Peek(n int) ([]byte, error) {
+	if len(r.peeked) < n {
+		buf := make([]byte, n)
+		copy(buf, r.peeked)
+		read, err := io.ReadFull(r.r, buf[len(r.peeked):])
+		r.read += int64(read)
+		r.peeked = buf[:len(r.peeked)+read]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r.peeked[:n], nil
+}
+
+// ReadMarker consumes the next bytes if they are marker, and returns true; else, fewer bytes being left
+// included, it consumes nothing and returns false. A marker precedes an optional section appended to an
+// encoding, such that the encodings written without it are still read, whether they end the reader or are
+// followed by other data read from the same LimitReader.
+func (r *LimitReader) ReadMarker(marker []byte) bool {
+	b, err := r.Peek(len(marker))
+	if err != nil || !bytes.Equal(b, marker) {
+		return false
+	}
+	r.peeked = r.peeked[len(marker):]
+	return true
+}
+
+// mulSize returns n * size, saturated to math.MaxInt64
+func mulSize(n uint64, size int) int64 {
+	if size > 0 && n > uint64(math.MaxInt64)/uint64(size) {
+		return math.MaxInt64
+	}
+	return int64(n * uint64(size))
+}
+
+// remaining returns the number of bytes left in r, or -1 if it isn't known
+func remaining(r io.Reader) int64 {
+	switch t := r.(type) {
+	case interface{ Len() int }: // bytes.Reader, bytes.Buffer, strings.Reader
+		return int64(t.Len())
+	case *io.LimitedReader:
+		if left := remaining(t.R); left >= 0 && left < t.N {
+			return left
+		}
+		return t.N
+	case *os.File:
+		info, err := t.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := t.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	}
+	return -1
+}
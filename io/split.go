@@ -0,0 +1,323 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// SplitManifestName is the name of the manifest of a split serialization, in its directory
+const SplitManifestName = "manifest.json"
+
+// splitManifestVersion is the version of the layout of SplitManifest
+const splitManifestVersion = 1
+
+// SplitManifest describes a serialization split in chunk files by WriteSplitTo; the chunks concatenated in
+// order are the serialization
+type SplitManifest struct {
+	Version int
+	Size    int64        // size of the serialization, in bytes
+	Digest  string       // hex SHA-256 of the serialization
+	Chunks  []SplitChunk // in order
+}
+
+// SplitChunk is a chunk file of a split serialization
+type SplitChunk struct {
+	File   string // name of the file, in the directory of the manifest
+	Size   int64  // in bytes
+	Digest string // hex SHA-256 of the file
+}
+
+// ChunkError is the error of a chunk of a split serialization which is missing or corrupted
+type ChunkError struct {
+	Index int    // of the chunk in SplitManifest.Chunks
+	File  string // path of the chunk file
+	Err   error
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("chunk %d (%s): %v", e.Index, e.File, e.Err)
+}
+
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// ErrSplitDigest is returned when the chunks of a split serialization match the manifest, but their
+// concatenation doesn't
+var ErrSplitDigest = errors.New("split serialization: digest mismatch")
+
+// WriteSplitTo writes the serialization of o in the directory dir (created if needed), in chunk files of at
+// most chunkSize bytes, followed by their SplitManifest (see SplitManifestName), written last. It returns the
+// size of the serialization. Read it back with ReadSplitFrom, or any reader with OpenSplit.
+func WriteSplitTo(dir string, chunkSize int64, o io.WriterTo) (int64, error) {
+	if chunkSize <= 0 {
+		return 0, fmt.Errorf("invalid chunk size %d", chunkSize)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return 0, err
+	}
+
+	w := &chunkWriter{dir: dir, chunkSize: chunkSize, digest: sha256.New()}
+	w.manifest.Version = splitManifestVersion
+	n, err := o.WriteTo(w)
+	if err == nil {
+		err = w.closeChunk()
+	} else {
+		_ = w.closeChunk()
+	}
+	if err != nil {
+		return n, err
+	}
+	w.manifest.Size = n
+	w.manifest.Digest = hex.EncodeToString(w.digest.Sum(nil))
+
+	data, err := json.MarshalIndent(&w.manifest, "", "\t")
+	if err != nil {
+		return n, err
+	}
+	return n, ioutil.WriteFile(filepath.Join(dir, SplitManifestName), data, 0600)
+}
+
+// chunkWriter writes the bytes in a new chunk file each chunkSize bytes, and records them in manifest
+type chunkWriter struct {
+	dir       string
+	chunkSize int64
+	manifest  SplitManifest
+	digest    hash.Hash // of the serialization
+
+	// current chunk
+	f       *os.File
+	bw      *bufio.Writer
+	size    int64
+	chunkMD hash.Hash
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if w.f == nil || w.size == w.chunkSize {
+			if err := w.closeChunk(); err != nil {
+				return written, err
+			}
+			if err := w.openChunk(); err != nil {
+				return written, err
+			}
+		}
+		k := len(p)
+		if int64(k) > w.chunkSize-w.size {
+			k = int(w.chunkSize - w.size)
+		}
+		n, err := w.bw.Write(p[:k])
+		w.chunkMD.Write(p[:n])
+		w.digest.Write(p[:n])
+		w.size += int64(n)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[k:]
+	}
+	return written, nil
+}
+
+func (w *chunkWriter) openChunk() error {
+	name := fmt.Sprintf("chunk-%06d.bin", len(w.manifest.Chunks))
+	f, err := os.Create(filepath.Join(w.dir, name))
+	if err != nil {
+		return err
+	}
+	w.f, w.bw, w.size, w.chunkMD = f, bufio.NewWriter(f), 0, sha256.New()
+	w.manifest.Chunks = append(w.manifest.Chunks, SplitChunk{File: name})
+	return nil
+}
+
+// closeChunk flushes and closes the current chunk, if any, and records its size and digest
+func (w *chunkWriter) closeChunk() error {
+	if w.f == nil {
+		return nil
+	}
+	err := w.bw.Flush()
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	chunk := &w.manifest.Chunks[len(w.manifest.Chunks)-1]
+	chunk.Size = w.size
+	chunk.Digest = hex.EncodeToString(w.chunkMD.Sum(nil))
+	w.f = nil
+	return err
+}
+
+// ReadSplitFrom reads o from the split serialization in the directory dir (see WriteSplitTo), streaming the
+// chunks to its ReadFrom method. The chunks are checked against the manifest as they are read: it returns a
+// *ChunkError if a chunk is missing, or doesn't have the size and digest of the manifest.
+func ReadSplitFrom(dir string, o io.ReaderFrom) (int64, error) {
+	return readSplit(dir, o.ReadFrom)
+}
+
+// UnsafeReadSplitFrom is ReadSplitFrom with the UnsafeReadFrom method of o
+func UnsafeReadSplitFrom(dir string, o UnsafeReaderFrom) (int64, error) {
+	return readSplit(dir, o.UnsafeReadFrom)
+}
+
+func readSplit(dir string, readFrom func(io.Reader) (int64, error)) (int64, error) {
+	r, err := OpenSplit(dir)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	n, err := readFrom(bufio.NewReader(r))
+
+	// the whole serialization is checked, even if readFrom didn't read it all, and its errors come
+	// second to the ones of the chunks: a corrupted chunk is likely to make readFrom fail too
+	if _, cerr := io.Copy(ioutil.Discard, r); cerr != nil {
+		return n, cerr
+	}
+	return n, err
+}
+
+// OpenSplit returns a reader of the split serialization in the directory dir (see WriteSplitTo). All the
+// chunk files must exist with the size of the manifest, else it returns a *ChunkError. The digests are
+// checked as the chunks are read: the reader returns a *ChunkError at the end of a corrupted chunk, and
+// ErrSplitDigest at the end of the serialization if it doesn't match the manifest.
+func OpenSplit(dir string) (io.ReadCloser, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, SplitManifestName))
+	if err != nil {
+		return nil, err
+	}
+	var manifest SplitManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%s: %v", SplitManifestName, err)
+	}
+	if manifest.Version != splitManifestVersion {
+		return nil, fmt.Errorf("%s: unsupported version %d", SplitManifestName, manifest.Version)
+	}
+
+	var size int64
+	for i, chunk := range manifest.Chunks {
+		path := filepath.Join(dir, chunk.File)
+		if filepath.Base(chunk.File) != chunk.File {
+			return nil, &ChunkError{Index: i, File: path, Err: errors.New("not in the directory of the manifest")}
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			return nil, &ChunkError{Index: i, File: path, Err: err}
+		}
+		if fi.Size() != chunk.Size {
+			return nil, &ChunkError{Index: i, File: path, Err: fmt.Errorf("%d bytes, expected %d", fi.Size(), chunk.Size)}
+		}
+		size += chunk.Size
+	}
+	if size != manifest.Size {
+		return nil, fmt.Errorf("%s: the chunks have %d bytes, expected %d", SplitManifestName, size, manifest.Size)
+	}
+
+	return &chunkReader{dir: dir, manifest: manifest, digest: sha256.New()}, nil
+}
+
+// chunkReader reads the chunks of a split serialization in order, checking their digests
+type chunkReader struct {
+	dir      string
+	manifest SplitManifest
+	digest   hash.Hash // of the serialization
+	err      error     // sticky
+
+	// current chunk
+	index   int
+	f       *os.File
+	chunkMD hash.Hash
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for r.err == nil {
+		if r.f == nil {
+			if r.index == len(r.manifest.Chunks) {
+				r.err = r.checkDigest()
+				break
+			}
+			if r.err = r.openChunk(); r.err != nil {
+				break
+			}
+		}
+		n, err := r.f.Read(p)
+		r.chunkMD.Write(p[:n])
+		r.digest.Write(p[:n])
+		if err == io.EOF {
+			r.err = r.closeChunk()
+			err = nil
+		}
+		if err != nil {
+			r.err = r.chunkError(err)
+		}
+		if n > 0 || len(p) == 0 {
+			return n, nil
+		}
+	}
+	return 0, r.err
+}
+
+func (r *chunkReader) openChunk() error {
+	f, err := os.Open(filepath.Join(r.dir, r.manifest.Chunks[r.index].File))
+	if err != nil {
+		return r.chunkError(err)
+	}
+	r.f, r.chunkMD = f, sha256.New()
+	return nil
+}
+
+// closeChunk closes the current chunk, and checks its digest
+func (r *chunkReader) closeChunk() error {
+	_ = r.f.Close()
+	r.f = nil
+	if hex.EncodeToString(r.chunkMD.Sum(nil)) != r.manifest.Chunks[r.index].Digest {
+		return r.chunkError(errors.New("digest mismatch"))
+	}
+	r.index++
+	return nil
+}
+
+func (r *chunkReader) checkDigest() error {
+	if hex.EncodeToString(r.digest.Sum(nil)) != r.manifest.Digest {
+		return ErrSplitDigest
+	}
+	return io.EOF
+}
+
+func (r *chunkReader) chunkError(err error) error {
+	return &ChunkError{Index: r.index, File: filepath.Join(r.dir, r.manifest.Chunks[r.index].File), Err: err}
+}
+
+// Close closes the current chunk, if any
+func (r *chunkReader) Close() error {
+	if r.f != nil {
+		err := r.f.Close()
+		r.f = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gnark
+
+import (
+	gnarkio "github.com/consensys/gnark/io"
+)
+
+// SetMaxReadBytes sets the maximum number of bytes read and allocated by the ReadFrom implementations
+// (constraint systems, keys, proofs, witnesses) for an object, and returns the previous value; see
+// io.SetMaxReadBytes. Readers of untrusted inputs should set it to the size of the largest object they
+// expect; a larger object, or length fields claiming one, fail with io.ErrLimitExceeded.
+func SetMaxReadBytes(n int64) int64 {
+	return gnarkio.SetMaxReadBytes(n)
+}
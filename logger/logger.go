@@ -0,0 +1,118 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logger provides the logger used by gnark to report framework messages,
+// such as the progress of the setup and prover algorithms, or compile warnings.
+//
+// By default, messages of level Info and above are written to os.Stdout.
+//
+// Circuit logs (api.Println) are not affected by this package; they are written
+// to backend.ProverOption.LoggerOut.
+package logger
+
+import (
+	"io"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// Level of a log message
+type Level int32
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	Disabled // SetLevel(Disabled) disables the logger
+)
+
+// String returns the prefix of the messages of level l
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "DBG"
+	case InfoLevel:
+		return "INF"
+	case WarnLevel:
+		return "WRN"
+	default:
+		return "???"
+	}
+}
+
+// Printer is implemented by the loggers accepted by Set, in particular
+// the standard library *log.Logger and zerolog.Logger
+type Printer interface {
+	Printf(format string, v ...interface{})
+}
+
+var (
+	level   = int32(InfoLevel)
+	lock    sync.RWMutex
+	printer Printer = log.New(os.Stdout, "", log.LstdFlags)
+)
+
+// SetOutput sets the destination of the messages; it is safe for concurrent use
+func SetOutput(w io.Writer) {
+	Set(log.New(w, "", log.LstdFlags))
+}
+
+// Set sets the logger writing the messages; it is safe for concurrent use.
+//
+// Messages are filtered by level before being passed to p.Printf,
+// and are prefixed with their level.
+func Set(p Printer) {
+	lock.Lock()
+	printer = p
+	lock.Unlock()
+}
+
+// SetLevel sets the minimum level of the messages to write (default: InfoLevel)
+func SetLevel(l Level) {
+	atomic.StoreInt32(&level, int32(l))
+}
+
+// Enabled returns true if messages of level l are written
+func Enabled(l Level) bool {
+	return l >= Level(atomic.LoadInt32(&level)) && l < Disabled
+}
+
+// Debug logs a message at DebugLevel, arguments are handled in the manner of fmt.Printf
+func Debug(format string, v ...interface{}) {
+	printf(DebugLevel, format, v...)
+}
+
+// Info logs a message at InfoLevel, arguments are handled in the manner of fmt.Printf
+func Info(format string, v ...interface{}) {
+	printf(InfoLevel, format, v...)
+}
+
+// Warn logs a message at WarnLevel, arguments are handled in the manner of fmt.Printf
+func Warn(format string, v ...interface{}) {
+	printf(WarnLevel, format, v...)
+}
+
+func printf(l Level, format string, v ...interface{}) {
+	if !Enabled(l) {
+		return
+	}
+	lock.RLock()
+	p := printer
+	lock.RUnlock()
+	p.Printf(l.String()+" "+format, v...)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevel(t *testing.T) {
+	assert := require.New(t)
+
+	var buf bytes.Buffer
+	logger.Set(log.New(&buf, "", 0))
+	defer reset()
+
+	logger.SetLevel(logger.WarnLevel)
+	logger.Debug("debug %d", 1)
+	logger.Info("info %d", 2)
+	logger.Warn("warn %d", 3)
+	assert.Equal("WRN warn 3\n", buf.String())
+
+	buf.Reset()
+	logger.SetLevel(logger.DebugLevel)
+	logger.Debug("debug %d", 1)
+	logger.Info("info %d", 2)
+	assert.Equal("DBG debug 1\nINF info 2\n", buf.String())
+
+	buf.Reset()
+	logger.SetLevel(logger.Disabled)
+	logger.Warn("warn %d", 3)
+	assert.Equal("", buf.String())
+}
+
+type printlnCircuit struct {
+	A, B frontend.Variable
+}
+
+func (circuit *printlnCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	c := api.Add(circuit.A, circuit.B)
+	api.Println(c, "is the addition")
+	api.AssertIsEqual(c, 5)
+	return nil
+}
+
+func TestCircuitLogs(t *testing.T) {
+	assert := require.New(t)
+
+	var logs bytes.Buffer
+	logger.SetOutput(&logs)
+	logger.SetLevel(logger.DebugLevel)
+	defer reset()
+
+	var circuit, witness printlnCircuit
+	witness.A.Assign(2)
+	witness.B.Assign(3)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+	pk, err := groth16.DummySetup(ccs)
+	assert.NoError(err)
+
+	var trace bytes.Buffer
+	_, err = groth16.Prove(ccs, pk, &witness, backend.WithOutput(&trace))
+	assert.NoError(err)
+
+	// framework messages are written by the logger, circuit logs to ProverOption.LoggerOut
+	assert.Equal("logger_test.go:65 5 is the addition\n", trace.String())
+	assert.True(strings.Contains(logs.String(), "DBG groth16 prover"), logs.String())
+	assert.False(strings.Contains(logs.String(), "is the addition"), logs.String())
+}
+
+func reset() {
+	logger.SetOutput(os.Stdout)
+	logger.SetLevel(logger.InfoLevel)
+}
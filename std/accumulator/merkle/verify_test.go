@@ -18,7 +18,10 @@ package merkle
 
 import (
 	"bytes"
+	"flag"
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/accumulator/merkletree"
@@ -30,6 +33,8 @@ import (
 	"github.com/consensys/gnark/test"
 )
 
+var update = flag.Bool("update", false, "update the golden file of the constraint counts")
+
 type merkleCircuit struct {
 	RootHash     frontend.Variable `gnark:",public"`
 	Path, Helper []frontend.Variable
@@ -96,3 +101,34 @@ func TestVerify(t *testing.T) {
 	assert := test.NewAssert(t)
 	assert.ProverSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
 }
+
+// TestVerifyConstraintCount catches the regressions of the number of constraints of VerifyProof, on all the
+// curves with the golden file (run the tests with -update to record it again)
+func TestVerifyConstraintCount(t *testing.T) {
+	circuits := make(map[string]frontend.Circuit)
+	for _, depth := range []int{4, 16} {
+		circuits[fmt.Sprintf("depth %d", depth)] = &merkleCircuit{
+			Path:   make([]frontend.Variable, depth+1),
+			Helper: make([]frontend.Variable, depth),
+		}
+	}
+
+	golden := filepath.Join("testdata", "constraints.json")
+	if *update {
+		f, err := os.Create(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = test.RecordConstraintCounts(circuits, f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.Open(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	test.CheckConstraintCounts(t, circuits, f, 0.05)
+}
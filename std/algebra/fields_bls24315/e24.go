@@ -0,0 +1,383 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fields_bls24315
+
+import (
+	bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark/frontend"
+)
+
+// E24 element in a cubic extension (Fp24 = Fp8(i), i**3 = w)
+type E24 struct {
+	D0, D1, D2 E8
+}
+
+// frobenius coefficients (p-th powers of the generators of the tower)
+const (
+	frobCoeffA = "14265754707630841383590096931465005402246260064523506653409458152869013672931584279153351926943"
+	frobCoeffB = "13266452002786802757645810648664867986567631927642464177452792960815113608167203350720036682455"
+	frobCoeffC = "37719635718874797449167165011304104204868932892052995456614707782168504515295626008356825673023"
+	frobCoeffD = "17432737665785421589107433512831558061649422754130449334965277047994983947893909429238815314776"
+	frobCoeffE = "39705142672498995661671850106945620852186608752525090699191017895721506694646055668218723303427"
+	frobCoeffF = "29019463919452620058839222695754364428302059305947724697987901631588253225470374568267230540725"
+	frobCoeffG = "39705142672498995661671850106945620852186608752525090699191017895721506694646055668218723303426"
+	frobCoeffH = "27033956928813979172980697816649498888237489781085970819538323908118873647639658229550439080179"
+	frobCoeffI = "36538159751358858129508353309042417085530339727307806653508466610511913818164017196988153745736"
+	frobCoeffJ = "33342866563749162527758572927163102293238492708847648721152723115703639794013692274261201232097"
+	frobCoeffK = "20076414560962359770112762278498234306670860781205184543699930154888526185846488923541164549642"
+)
+
+// SetZero returns a newly allocated element equal to 0
+func (e *E24) SetZero(api frontend.API) *E24 {
+	e.D0.SetZero(api)
+	e.D1.SetZero(api)
+	e.D2.SetZero(api)
+	return e
+}
+
+// SetOne returns a newly allocated element equal to 1
+func (e *E24) SetOne(api frontend.API) *E24 {
+	e.D0.SetOne(api)
+	e.D1.SetZero(api)
+	e.D2.SetZero(api)
+	return e
+}
+
+// Neg negates an Fp24 elmt
+func (e *E24) Neg(api frontend.API, e1 E24) *E24 {
+	e.D0.Neg(api, e1.D0)
+	e.D1.Neg(api, e1.D1)
+	e.D2.Neg(api, e1.D2)
+	return e
+}
+
+// Add adds 2 elmts in Fp24
+func (e *E24) Add(api frontend.API, e1, e2 E24) *E24 {
+	e.D0.Add(api, e1.D0, e2.D0)
+	e.D1.Add(api, e1.D1, e2.D1)
+	e.D2.Add(api, e1.D2, e2.D2)
+	return e
+}
+
+// Sub substracts 2 elmts in Fp24
+func (e *E24) Sub(api frontend.API, e1, e2 E24) *E24 {
+	e.D0.Sub(api, e1.D0, e2.D0)
+	e.D1.Sub(api, e1.D1, e2.D1)
+	e.D2.Sub(api, e1.D2, e2.D2)
+	return e
+}
+
+// Mul multiplies 2 elmts in Fp24
+func (e *E24) Mul(api frontend.API, e1, e2 E24) *E24 {
+
+	// Algorithm 13 from https://eprint.iacr.org/2010/354.pdf
+	var t0, t1, t2, c0, c1, c2, tmp E8
+	t0.Mul(api, e1.D0, e2.D0)
+	t1.Mul(api, e1.D1, e2.D1)
+	t2.Mul(api, e1.D2, e2.D2)
+
+	c0.Add(api, e1.D1, e1.D2)
+	tmp.Add(api, e2.D1, e2.D2)
+	c0.Mul(api, c0, tmp).Sub(api, c0, t1).Sub(api, c0, t2).MulByNonResidue(api, c0).Add(api, c0, t0)
+
+	c1.Add(api, e1.D0, e1.D1)
+	tmp.Add(api, e2.D0, e2.D1)
+	c1.Mul(api, c1, tmp).Sub(api, c1, t0).Sub(api, c1, t1)
+	tmp.MulByNonResidue(api, t2)
+	c1.Add(api, c1, tmp)
+
+	tmp.Add(api, e1.D0, e1.D2)
+	c2.Add(api, e2.D0, e2.D2).Mul(api, c2, tmp).Sub(api, c2, t0).Sub(api, c2, t2).Add(api, c2, t1)
+
+	e.D0 = c0
+	e.D1 = c1
+	e.D2 = c2
+
+	return e
+}
+
+// Square squares an element in Fp24
+func (e *E24) Square(api frontend.API, x E24) *E24 {
+
+	// Algorithm 16 from https://eprint.iacr.org/2010/354.pdf
+	var c4, c5, c1, c2, c3, c0 E8
+	c4.Mul(api, x.D0, x.D1).Double(api, c4)
+	c5.Square(api, x.D2)
+	c1.MulByNonResidue(api, c5).Add(api, c1, c4)
+	c2.Sub(api, c4, c5)
+	c3.Square(api, x.D0)
+	c4.Sub(api, x.D0, x.D1).Add(api, c4, x.D2)
+	c5.Mul(api, x.D1, x.D2).Double(api, c5)
+	c4.Square(api, c4)
+	c0.MulByNonResidue(api, c5).Add(api, c0, c3)
+	e.D2.Add(api, c2, c4).Add(api, e.D2, c5).Sub(api, e.D2, c3)
+	e.D0 = c0
+	e.D1 = c1
+
+	return e
+}
+
+// CyclotomicSquare squares an element of the cyclotomic subgroup of Fp24
+// Granger-Scott's cyclotomic square
+// https://eprint.iacr.org/2009/565.pdf, 3.2
+func (e *E24) CyclotomicSquare(api frontend.API, x E24) *E24 {
+
+	var A, B, C, D E8
+
+	A = x.D0
+	B = x.D2
+	C = x.D1
+
+	e.D0.Square(api, x.D0)
+	D.Double(api, e.D0)
+	e.D0.Add(api, e.D0, D)
+	A.Conjugate(api, A).Neg(api, A)
+	A.Double(api, A)
+	e.D0.Add(api, e.D0, A)
+	B.Square(api, B)
+	B.MulByNonResidue(api, B)
+	D.Double(api, B)
+	B.Add(api, B, D)
+	C.Square(api, C)
+	D.Double(api, C)
+	C.Add(api, C, D)
+	e.D1.Conjugate(api, x.D1)
+	e.D1.Double(api, e.D1)
+	e.D2.Conjugate(api, x.D2).Neg(api, e.D2)
+	e.D2.Double(api, e.D2)
+	e.D1.Add(api, e.D1, B)
+	e.D2.Add(api, e.D2, C)
+
+	return e
+}
+
+// Inverse inverses an elmt in Fp24
+func (e *E24) Inverse(api frontend.API, e1 E24) *E24 {
+
+	// Algorithm 17 from https://eprint.iacr.org/2010/354.pdf
+	// step 9 is wrong in the paper it's t1-t4
+	var t0, t1, t2, t3, t4, t5, t6, c0, c1, c2, d1, d2 E8
+	t0.Square(api, e1.D0)
+	t1.Square(api, e1.D1)
+	t2.Square(api, e1.D2)
+	t3.Mul(api, e1.D0, e1.D1)
+	t4.Mul(api, e1.D0, e1.D2)
+	t5.Mul(api, e1.D1, e1.D2)
+	c0.MulByNonResidue(api, t5).Sub(api, t0, c0)
+	c1.MulByNonResidue(api, t2).Sub(api, c1, t3)
+	c2.Sub(api, t1, t4)
+	t6.Mul(api, e1.D0, c0)
+	d1.Mul(api, e1.D2, c1)
+	d2.Mul(api, e1.D1, c2)
+	d1.Add(api, d1, d2).MulByNonResidue(api, d1)
+	t6.Add(api, t6, d1)
+	t6.Inverse(api, t6)
+	e.D0.Mul(api, c0, t6)
+	e.D1.Mul(api, c1, t6)
+	e.D2.Mul(api, c2, t6)
+
+	return e
+}
+
+// Conjugate applies Frob**12 (conjugation over Fp12)
+func (e *E24) Conjugate(api frontend.API, e1 E24) *E24 {
+	e.D0.Conjugate(api, e1.D0)
+	e.D1.Conjugate(api, e1.D1).Neg(api, e.D1)
+	e.D2.Conjugate(api, e1.D2)
+	return e
+}
+
+// MulBy012 multiplication by sparse element (c0 + c1*w + c2*i)
+// https://eprint.iacr.org/2019/077.pdf
+func (e *E24) MulBy012(api frontend.API, c0, c1, c2 E4) *E24 {
+
+	var d0, v0, v1, tmp E8
+
+	d0.C0 = c0
+	d0.C1 = c1
+
+	v0.Mul(api, e.D0, d0)
+	v1.C0.Mul(api, e.D1.C0, c2)
+	v1.C1.Mul(api, e.D1.C1, c2)
+
+	e.D1.Add(api, e.D1, e.D0)
+	tmp = d0
+	tmp.C0.Add(api, tmp.C0, c2)
+	e.D1.Mul(api, e.D1, tmp)
+	e.D1.Sub(api, e.D1, v0)
+	e.D1.Sub(api, e.D1, v1)
+
+	e.D0.C0.Mul(api, e.D2.C0, c2)
+	e.D0.C1.Mul(api, e.D2.C1, c2)
+	e.D0.MulByNonResidue(api, e.D0)
+	e.D0.Add(api, e.D0, v0)
+
+	e.D2.Mul(api, e.D2, d0)
+	e.D2.Add(api, e.D2, v1)
+
+	return e
+}
+
+// Frobenius applies frob to an fp24 elmt
+func (e *E24) Frobenius(api frontend.API, e1 E24) *E24 {
+
+	e.D0.C0.B0.Conjugate(api, e1.D0.C0.B0)
+	e.D0.C0.B1.Conjugate(api, e1.D0.C0.B1).MulByFp(api, e.D0.C0.B1, frobCoeffA)
+	e.D0.C1.B0.Conjugate(api, e1.D0.C1.B0).MulByFp(api, e.D0.C1.B0, frobCoeffB)
+	e.D0.C1.B1.Conjugate(api, e1.D0.C1.B1).MulByFp(api, e.D0.C1.B1, frobCoeffF)
+	e.D1.C0.B0.Conjugate(api, e1.D1.C0.B0).MulByFp(api, e.D1.C0.B0, frobCoeffC)
+	e.D1.C0.B1.Conjugate(api, e1.D1.C0.B1).MulByFp(api, e.D1.C0.B1, frobCoeffJ)
+	e.D1.C1.B0.Conjugate(api, e1.D1.C1.B0).MulByFp(api, e.D1.C1.B0, frobCoeffE)
+	e.D1.C1.B1.Conjugate(api, e1.D1.C1.B1).MulByFp(api, e.D1.C1.B1, frobCoeffI)
+	e.D2.C0.B0.Conjugate(api, e1.D2.C0.B0).MulByFp(api, e.D2.C0.B0, frobCoeffD)
+	e.D2.C0.B1.Conjugate(api, e1.D2.C0.B1).MulByFp(api, e.D2.C0.B1, frobCoeffG)
+	e.D2.C1.B0.Conjugate(api, e1.D2.C1.B0).MulByFp(api, e.D2.C1.B0, frobCoeffH)
+	e.D2.C1.B1.Conjugate(api, e1.D2.C1.B1).MulByFp(api, e.D2.C1.B1, frobCoeffK)
+
+	return e
+}
+
+// FrobeniusSquare applies frob**2 to an fp24 elmt
+func (e *E24) FrobeniusSquare(api frontend.API, e1 E24) *E24 {
+
+	e.D0.C0.Conjugate(api, e1.D0.C0)
+	e.D0.C1.Conjugate(api, e1.D0.C1).MulByFp(api, e.D0.C1, frobCoeffA)
+	e.D1.C0.Conjugate(api, e1.D1.C0).MulByFp(api, e.D1.C0, frobCoeffD)
+	e.D1.C1.Conjugate(api, e1.D1.C1).MulByFp(api, e.D1.C1, frobCoeffG)
+	e.D2.C0.Conjugate(api, e1.D2.C0).MulByFp(api, e.D2.C0, frobCoeffE)
+	e.D2.C1.Conjugate(api, e1.D2.C1).MulByFp(api, e.D2.C1, frobCoeffI)
+
+	return e
+}
+
+// FrobeniusQuad applies frob**4 to an fp24 elmt
+func (e *E24) FrobeniusQuad(api frontend.API, e1 E24) *E24 {
+
+	e.D0.Conjugate(api, e1.D0)
+	e.D1.Conjugate(api, e1.D1).MulByFp(api, e.D1, frobCoeffE)
+	e.D2.Conjugate(api, e1.D2).MulByFp(api, e.D2, frobCoeffG)
+
+	return e
+}
+
+// Select sets e to r1 if b=1, r2 otherwise
+func (e *E24) Select(api frontend.API, b frontend.Variable, r1, r2 E24) *E24 {
+	e.D0.Select(api, b, r1.D0, r2.D0)
+	e.D1.Select(api, b, r1.D1, r2.D1)
+	e.D2.Select(api, b, r1.D2, r2.D2)
+	return e
+}
+
+// nSquare repeated cyclotomic square
+func (e *E24) nSquare(api frontend.API, n int) {
+	for i := 0; i < n; i++ {
+		e.CyclotomicSquare(api, *e)
+	}
+}
+
+// Expt compute e1**x, where x = -2**32+2**30+2**22-2**20+1 is the seed of bls24315
+// This function is only used for the final expo of the pairing for bls24315.
+func (e *E24) Expt(api frontend.API, e1 E24) *E24 {
+
+	var res, x20, x22, x30, x32 E24
+	res = e1
+
+	res.nSquare(api, 20)
+	x20.Conjugate(api, res)
+	res.nSquare(api, 2)
+	x22 = res
+	res.nSquare(api, 8)
+	x30 = res
+	x32.CyclotomicSquare(api, x30).
+		CyclotomicSquare(api, x32).
+		Conjugate(api, x32)
+
+	res.Mul(api, e1, x20).
+		Mul(api, res, x22).
+		Mul(api, res, x30).
+		Mul(api, res, x32)
+
+	*e = res
+
+	return e
+}
+
+// FinalExponentiation computes the final expo x**(p**24-1)/r
+func (e *E24) FinalExponentiation(api frontend.API, e1 E24) *E24 {
+
+	result := e1
+
+	// https://eprint.iacr.org/2012/232.pdf, section 7
+	var t [3]E24
+
+	// easy part
+	t[0].Conjugate(api, result)
+	result.Inverse(api, result)
+	t[0].Mul(api, t[0], result)
+	result.FrobeniusQuad(api, t[0]).
+		Mul(api, result, t[0])
+
+	// hard part (up to permutation)
+	// Daiki Hayashida and Kenichiro Hayasaka
+	// and Tadanori Teruya
+	// https://eprint.iacr.org/2020/875.pdf
+	// 3*Phi_24(p)/r = (u-1)^2 * (u+p) * (u^2+p^2) * (u^4+p^4-1) + 3
+	t[0].CyclotomicSquare(api, result)
+	t[1].Expt(api, result)
+	t[2].Conjugate(api, result)
+	t[1].Mul(api, t[1], t[2])
+	t[2].Expt(api, t[1])
+	t[1].Conjugate(api, t[1])
+	t[1].Mul(api, t[1], t[2])
+	t[2].Expt(api, t[1])
+	t[1].Frobenius(api, t[1])
+	t[1].Mul(api, t[1], t[2])
+	result.Mul(api, result, t[0])
+	t[0].Expt(api, t[1])
+	t[2].Expt(api, t[0])
+	t[0].FrobeniusSquare(api, t[1])
+	t[2].Mul(api, t[0], t[2])
+	t[1].Expt(api, t[2])
+	t[1].Expt(api, t[1])
+	t[1].Expt(api, t[1])
+	t[1].Expt(api, t[1])
+	t[0].FrobeniusQuad(api, t[2])
+	t[0].Mul(api, t[0], t[1])
+	t[2].Conjugate(api, t[2])
+	t[0].Mul(api, t[0], t[2])
+	result.Mul(api, result, t[0])
+
+	*e = result
+	return e
+}
+
+// Assign a value to self (witness assignment)
+func (e *E24) Assign(a *bls24315.GT) {
+	e.D0.C0.Assign(&a.D0.C0)
+	e.D0.C1.Assign(&a.D0.C1)
+	e.D1.C0.Assign(&a.D1.C0)
+	e.D1.C1.Assign(&a.D1.C1)
+	e.D2.C0.Assign(&a.D2.C0)
+	e.D2.C1.Assign(&a.D2.C1)
+}
+
+// MustBeEqual constraint self to be equal to other into the given constraint system
+func (e *E24) MustBeEqual(api frontend.API, other E24) {
+	e.D0.MustBeEqual(api, other.D0)
+	e.D1.MustBeEqual(api, other.D1)
+	e.D2.MustBeEqual(api, other.D2)
+}
@@ -0,0 +1,319 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fields_bls24315
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+type fp24Mul struct {
+	A, B E24
+	C    E24 `gnark:",public"`
+}
+
+func (circuit *fp24Mul) Define(curveID ecc.ID, api frontend.API) error {
+	var expected E24
+	expected.Mul(api, circuit.A, circuit.B)
+	expected.MustBeEqual(api, circuit.C)
+	return nil
+}
+
+func TestMulFp24(t *testing.T) {
+
+	var circuit, witness fp24Mul
+
+	// witness values
+	var a, b, c bls24315.GT
+	a.SetRandom()
+	b.SetRandom()
+	c.Mul(&a, &b)
+
+	witness.A.Assign(&a)
+	witness.B.Assign(&b)
+	witness.C.Assign(&c)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_633))
+
+}
+
+type fp24Square struct {
+	A E24
+	B E24 `gnark:",public"`
+}
+
+func (circuit *fp24Square) Define(curveID ecc.ID, api frontend.API) error {
+	var expected E24
+	expected.Square(api, circuit.A)
+	expected.MustBeEqual(api, circuit.B)
+	return nil
+}
+
+func TestSquareFp24(t *testing.T) {
+
+	var circuit, witness fp24Square
+
+	// witness values
+	var a, b bls24315.GT
+	a.SetRandom()
+	b.Square(&a)
+
+	witness.A.Assign(&a)
+	witness.B.Assign(&b)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_633))
+
+}
+
+type fp24CycloSquare struct {
+	A E24
+	B E24 `gnark:",public"`
+}
+
+func (circuit *fp24CycloSquare) Define(curveID ecc.ID, api frontend.API) error {
+	var u, v E24
+	u.Square(api, circuit.A)
+	v.CyclotomicSquare(api, circuit.A)
+	u.MustBeEqual(api, v)
+	u.MustBeEqual(api, circuit.B)
+	return nil
+}
+
+func TestFp24CyclotomicSquare(t *testing.T) {
+
+	var circuit, witness fp24CycloSquare
+
+	// witness values
+	var a, b bls24315.GT
+	a.SetRandom()
+
+	// put a in the cyclotomic subgroup
+	var tmp bls24315.GT
+	tmp.Conjugate(&a)
+	a.Inverse(&a)
+	tmp.Mul(&tmp, &a)
+	a.FrobeniusQuad(&tmp).Mul(&a, &tmp)
+
+	b.CyclotomicSquare(&a)
+	witness.A.Assign(&a)
+	witness.B.Assign(&b)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_633))
+
+}
+
+type fp24Conjugate struct {
+	A E24
+	C E24 `gnark:",public"`
+}
+
+func (circuit *fp24Conjugate) Define(curveID ecc.ID, api frontend.API) error {
+	var expected E24
+	expected.Conjugate(api, circuit.A)
+	expected.MustBeEqual(api, circuit.C)
+	return nil
+}
+
+func TestConjugateFp24(t *testing.T) {
+
+	var circuit, witness fp24Conjugate
+
+	// witness values
+	var a, c bls24315.GT
+	a.SetRandom()
+	c.Conjugate(&a)
+
+	witness.A.Assign(&a)
+	witness.C.Assign(&c)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_633))
+}
+
+type fp24Frobenius struct {
+	A       E24
+	C, D, E E24 `gnark:",public"`
+}
+
+func (circuit *fp24Frobenius) Define(curveID ecc.ID, api frontend.API) error {
+	var result E24
+	result.Frobenius(api, circuit.A)
+	result.MustBeEqual(api, circuit.C)
+
+	result.FrobeniusSquare(api, circuit.A)
+	result.MustBeEqual(api, circuit.D)
+
+	result.FrobeniusQuad(api, circuit.A)
+	result.MustBeEqual(api, circuit.E)
+
+	return nil
+}
+
+func TestFrobeniusFp24(t *testing.T) {
+
+	var circuit, witness fp24Frobenius
+
+	// witness values
+	var a, c, d, e bls24315.GT
+	a.SetRandom()
+	c.Frobenius(&a)
+	d.FrobeniusSquare(&a)
+	e.FrobeniusQuad(&a)
+
+	witness.A.Assign(&a)
+	witness.C.Assign(&c)
+	witness.D.Assign(&d)
+	witness.E.Assign(&e)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_633))
+}
+
+type fp24Inverse struct {
+	A E24
+	C E24 `gnark:",public"`
+}
+
+func (circuit *fp24Inverse) Define(curveID ecc.ID, api frontend.API) error {
+	var expected E24
+	expected.Inverse(api, circuit.A)
+	expected.MustBeEqual(api, circuit.C)
+	return nil
+}
+
+func TestInverseFp24(t *testing.T) {
+
+	var circuit, witness fp24Inverse
+
+	// witness values
+	var a, c bls24315.GT
+	a.SetRandom()
+	c.Inverse(&a)
+
+	witness.A.Assign(&a)
+	witness.C.Assign(&c)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_633))
+}
+
+type fp24MulBy012 struct {
+	A       E24
+	W       E24 `gnark:",public"`
+	B, C, D E4
+}
+
+func (circuit *fp24MulBy012) Define(curveID ecc.ID, api frontend.API) error {
+	circuit.A.MulBy012(api, circuit.B, circuit.C, circuit.D)
+	circuit.A.MustBeEqual(api, circuit.W)
+	return nil
+}
+
+func TestFp24MulBy012(t *testing.T) {
+
+	var circuit, witness fp24MulBy012
+
+	// witness values
+	var a, w bls24315.GT
+	var b, c, d bls24315.E4
+	a.SetRandom()
+	b.SetRandom()
+	c.SetRandom()
+	d.SetRandom()
+	w.Set(&a)
+	w.MulBy012(&b, &c, &d)
+
+	witness.A.Assign(&a)
+	witness.B.Assign(&b)
+	witness.C.Assign(&c)
+	witness.D.Assign(&d)
+	witness.W.Assign(&w)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_633))
+}
+
+type fp24Expt struct {
+	A E24
+	C E24 `gnark:",public"`
+}
+
+func (circuit *fp24Expt) Define(curveID ecc.ID, api frontend.API) error {
+	var expected E24
+	expected.Expt(api, circuit.A)
+	expected.MustBeEqual(api, circuit.C)
+	return nil
+}
+
+func TestExptFp24(t *testing.T) {
+
+	var circuit, witness fp24Expt
+
+	// witness values
+	var a, c bls24315.GT
+	a.SetRandom()
+
+	// put a in the cyclotomic subgroup
+	var tmp bls24315.GT
+	tmp.Conjugate(&a)
+	a.Inverse(&a)
+	tmp.Mul(&tmp, &a)
+	a.FrobeniusQuad(&tmp).Mul(&a, &tmp)
+
+	c.Expt(&a)
+
+	witness.A.Assign(&a)
+	witness.C.Assign(&c)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_633))
+}
+
+type fp24FinalExpo struct {
+	A E24
+	C E24 `gnark:",public"`
+}
+
+func (circuit *fp24FinalExpo) Define(curveID ecc.ID, api frontend.API) error {
+	var expected E24
+	expected.FinalExponentiation(api, circuit.A)
+	expected.MustBeEqual(api, circuit.C)
+	return nil
+}
+
+func TestExpFinalExpoFp24(t *testing.T) {
+
+	var circuit, witness fp24FinalExpo
+
+	// witness values
+	var a, c bls24315.GT
+	a.SetRandom()
+	c = bls24315.FinalExponentiation(&a)
+
+	witness.A.Assign(&a)
+	witness.C.Assign(&c)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_633))
+}
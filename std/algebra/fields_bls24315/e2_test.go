@@ -0,0 +1,140 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fields_bls24315
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+type e2Mul struct {
+	A, B E2
+	C    E2 `gnark:",public"`
+}
+
+func (circuit *e2Mul) Define(curveID ecc.ID, api frontend.API) error {
+	var expected E2
+	expected.Mul(api, circuit.A, circuit.B)
+	expected.MustBeEqual(api, circuit.C)
+	return nil
+}
+
+func TestMulFp2(t *testing.T) {
+
+	// witness values
+	var a, b, c bls24315.E2
+	a.SetRandom()
+	b.SetRandom()
+	c.Mul(&a, &b)
+
+	var witness e2Mul
+	witness.A.Assign(&a)
+	witness.B.Assign(&b)
+	witness.C.Assign(&c)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&e2Mul{}, &witness, test.WithCurves(ecc.BW6_633))
+
+}
+
+type e2Square struct {
+	A E2
+	C E2 `gnark:",public"`
+}
+
+func (circuit *e2Square) Define(curveID ecc.ID, api frontend.API) error {
+	var expected E2
+	expected.Square(api, circuit.A)
+	expected.MustBeEqual(api, circuit.C)
+	return nil
+}
+
+func TestSquareFp2(t *testing.T) {
+
+	// witness values
+	var a, c bls24315.E2
+	a.SetRandom()
+	c.Square(&a)
+
+	var witness e2Square
+	witness.A.Assign(&a)
+	witness.C.Assign(&c)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&e2Square{}, &witness, test.WithCurves(ecc.BW6_633))
+
+}
+
+type e2MulByNonResidue struct {
+	A E2
+	C E2 `gnark:",public"`
+}
+
+func (circuit *e2MulByNonResidue) Define(curveID ecc.ID, api frontend.API) error {
+	var expected E2
+	expected.MulByNonResidue(api, circuit.A)
+	expected.MustBeEqual(api, circuit.C)
+	return nil
+}
+
+func TestMulByNonResidueFp2(t *testing.T) {
+
+	// witness values
+	var a, c bls24315.E2
+	a.SetRandom()
+	c.MulByNonResidue(&a)
+
+	var witness e2MulByNonResidue
+	witness.A.Assign(&a)
+	witness.C.Assign(&c)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&e2MulByNonResidue{}, &witness, test.WithCurves(ecc.BW6_633))
+
+}
+
+type e2Inverse struct {
+	A E2
+	C E2 `gnark:",public"`
+}
+
+func (circuit *e2Inverse) Define(curveID ecc.ID, api frontend.API) error {
+	var expected E2
+	expected.Inverse(api, circuit.A)
+	expected.MustBeEqual(api, circuit.C)
+	return nil
+}
+
+func TestInverseFp2(t *testing.T) {
+
+	// witness values
+	var a, c bls24315.E2
+	a.SetRandom()
+	c.Inverse(&a)
+
+	var witness e2Inverse
+	witness.A.Assign(&a)
+	witness.C.Assign(&c)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&e2Inverse{}, &witness, test.WithCurves(ecc.BW6_633))
+
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw
+
+import (
+	"math/big"
+
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fp"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/fields"
+)
+
+// G1AffinePublic is a point of G1 (BLS12-377) as public inputs of a circuit (BW6-761), its coordinates
+// X then Y, a base field element of BLS12-377 being a single input. See G1PublicInputs.
+//
+// As the visibility of a field overrides the one of its sub-fields, a field of type G1AffinePublic is tagged
+// `gnark:",public"` (or `gnark:",embed"`), else its coordinates are secret inputs.
+type G1AffinePublic struct {
+	X, Y frontend.Variable `gnark:",public"`
+}
+
+// G1Affine returns the point p, for the arithmetic of G1Affine
+func (p *G1AffinePublic) G1Affine() G1Affine {
+	return G1Affine{X: p.X, Y: p.Y}
+}
+
+// Assign a value to self (witness assignment)
+func (p *G1AffinePublic) Assign(p1 *bls12377.G1Affine) {
+	p.X.Assign(bls12377FpTobw6761fr(&p1.X))
+	p.Y.Assign(bls12377FpTobw6761fr(&p1.Y))
+}
+
+// G1PublicInputs returns the values of the public inputs of a G1AffinePublic assigned p (see Assign), in
+// their order: X, Y
+func G1PublicInputs(p *bls12377.G1Affine) []*big.Int {
+	return []*big.Int{p.X.ToBigIntRegular(new(big.Int)), p.Y.ToBigIntRegular(new(big.Int))}
+}
+
+// G2AffinePublic is a point of G2 (BLS12-377) as public inputs of a circuit (BW6-761), the components of
+// its coordinates in E2: X.A0, X.A1, Y.A0, Y.A1. See G2PublicInputs.
+// Like a G1AffinePublic, a field of type G2AffinePublic is tagged `gnark:",public"`.
+type G2AffinePublic struct {
+	X, Y fields.E2 `gnark:",public"`
+}
+
+// G2Affine returns the point p, for the arithmetic of G2Affine
+func (p *G2AffinePublic) G2Affine() G2Affine {
+	return G2Affine{X: p.X, Y: p.Y}
+}
+
+// Assign a value to self (witness assignment)
+func (p *G2AffinePublic) Assign(p1 *bls12377.G2Affine) {
+	p.X.Assign(&p1.X)
+	p.Y.Assign(&p1.Y)
+}
+
+// G2PublicInputs returns the values of the public inputs of a G2AffinePublic assigned p (see Assign), in
+// their order: X.A0, X.A1, Y.A0, Y.A1
+func G2PublicInputs(p *bls12377.G2Affine) []*big.Int {
+	res := make([]*big.Int, 4)
+	for i, c := range []*fp.Element{&p.X.A0, &p.X.A1, &p.Y.A0, &p.Y.A1} {
+		res[i] = c.ToBigIntRegular(new(big.Int))
+	}
+	return res
+}
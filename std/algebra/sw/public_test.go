@@ -0,0 +1,67 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// publicPoints checks that the public points are the secret ones
+type publicPoints struct {
+	G1       G1AffinePublic `gnark:",public"`
+	G2       G2AffinePublic `gnark:",public"`
+	SecretG1 G1Affine       `gnark:",secret"`
+	SecretG2 G2Affine       `gnark:",secret"`
+}
+
+func (circuit *publicPoints) Define(curveID ecc.ID, api frontend.API) error {
+	g1 := circuit.G1.G1Affine()
+	g1.MustBeEqual(api, circuit.SecretG1)
+	g2 := circuit.G2.G2Affine()
+	g2.MustBeEqual(api, circuit.SecretG2)
+	return nil
+}
+
+func TestPublicPoints(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	_, _, g1, g2 := bls12377.Generators()
+	g1.ScalarMultiplication(&g1, big.NewInt(3))
+	g2.ScalarMultiplication(&g2, big.NewInt(5))
+
+	var circuit, assignment publicPoints
+	assignment.G1.Assign(&g1)
+	assignment.G2.Assign(&g2)
+	assignment.SecretG1.Assign(&g1)
+	assignment.SecretG2.Assign(&g2)
+	assert.SolvingSucceeded(&circuit, &assignment, test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16))
+
+	// the public inputs are the ones of the helpers, in the order of the fields
+	ccs, err := frontend.Compile(ecc.BW6_761, backend.GROTH16, &circuit)
+	assert.NoError(err)
+	public, _, err := witness.ToVector(&assignment, ccs)
+	assert.NoError(err)
+	assert.Equal(append(G1PublicInputs(&g1), G2PublicInputs(&g2)...), public)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw_bls24315
+
+import (
+	"math/big"
+
+	bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fp"
+	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr"
+	"github.com/consensys/gnark/frontend"
+)
+
+// G1Affine point in affine coords
+type G1Affine struct {
+	X, Y frontend.Variable
+}
+
+// Neg outputs -p
+func (p *G1Affine) Neg(api frontend.API, p1 G1Affine) *G1Affine {
+	p.X = p1.X
+	p.Y = api.Sub(0, p1.Y)
+	return p
+}
+
+// AddAssign adds p1 to p using the affine formulas with division, and return p
+func (p *G1Affine) AddAssign(api frontend.API, p1 G1Affine) *G1Affine {
+
+	// compute lambda = (p1.y-p.y)/(p1.x-p.x)
+	lambda := api.DivUnchecked(api.Sub(p1.Y, p.Y), api.Sub(p1.X, p.X))
+
+	// xr = lambda**2-p.x-p1.x
+	xr := api.Sub(api.Mul(lambda, lambda), api.Add(p.X, p1.X))
+
+	// p.y = lambda(p.x-xr) - p.y
+	p.Y = api.Sub(api.Mul(lambda, api.Sub(p.X, xr)), p.Y)
+
+	//p.x = xr
+	p.X = xr
+	return p
+}
+
+// Select sets p1 if b=1, p2 if b=0, and returns it. b must be boolean constrained
+func (p *G1Affine) Select(api frontend.API, b frontend.Variable, p1, p2 G1Affine) *G1Affine {
+
+	p.X = api.Select(b, p1.X, p2.X)
+	p.Y = api.Select(b, p1.Y, p2.Y)
+
+	return p
+
+}
+
+// Double double a point in affine coords
+func (p *G1Affine) Double(api frontend.API, p1 G1Affine) *G1Affine {
+
+	var three, two big.Int
+	three.SetInt64(3)
+	two.SetInt64(2)
+
+	// compute lambda = (3*p1.x**2+a)/2*p1.y, here we assume a=0 (j invariant 0 curve)
+	lambda := api.DivUnchecked(api.Mul(p1.X, p1.X, three), api.Mul(p1.Y, two))
+
+	// xr = lambda**2-p1.x-p1.x
+	xr := api.Sub(api.Mul(lambda, lambda), api.Mul(p1.X, two))
+
+	// p.y = lambda(p.x-xr) - p.y
+	p.Y = api.Sub(api.Mul(lambda, api.Sub(p1.X, xr)), p1.Y)
+
+	//p.x = xr
+	p.X = xr
+
+	return p
+}
+
+// ScalarMul computes scalar*p1, affect the result to p, and returns it.
+// The scalar must not be 1 (the last step would then compute P-P, which the incomplete addition law can't handle).
+func (p *G1Affine) ScalarMul(api frontend.API, p1 G1Affine, s interface{}) *G1Affine {
+	// scalar bits
+	scalar := api.Constant(s)
+	bits := api.ToBinary(scalar)
+
+	var base G1Affine
+	base.Double(api, p1)
+	r1 := p1
+
+	// start from 1 and use right-to-left scalar multiplication to avoid bugs due to incomplete addition law
+	for i := 1; i < len(bits); i++ {
+		tmp := r1
+		tmp.AddAssign(api, base)
+
+		// if bits[i] == 0, do nothing, if bits[i] == 1, res += 2**p1
+		r1.Select(api, bits[i], tmp, r1)
+
+		base.Double(api, base)
+	}
+
+	// now check the lsb, if it's one, leave the result as is, otherwise substract P
+	var r2 G1Affine
+	r2.Neg(api, p1).AddAssign(api, r1)
+
+	p.Select(api, bits[0], r1, r2)
+
+	return p
+
+}
+
+func bls24315FpTobw6633fr(a *fp.Element) (r fr.Element) {
+	for i, v := range a {
+		r[i] = v
+	}
+	return
+}
+
+// Assign a value to self (witness assignment)
+func (p *G1Affine) Assign(p1 *bls24315.G1Affine) {
+	p.X.Assign(bls24315FpTobw6633fr(&p1.X))
+	p.Y.Assign(bls24315FpTobw6633fr(&p1.Y))
+}
+
+// MustBeEqual constraint self to be equal to other into the given constraint system
+func (p *G1Affine) MustBeEqual(api frontend.API, other G1Affine) {
+	api.AssertIsEqual(p.X, other.X)
+	api.AssertIsEqual(p.Y, other.Y)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw_bls24315
+
+import (
+	bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/fields_bls24315"
+)
+
+// G2Affine point in affine coords
+type G2Affine struct {
+	X, Y fields_bls24315.E4
+}
+
+// Neg outputs -p
+func (p *G2Affine) Neg(api frontend.API, p1 *G2Affine) *G2Affine {
+	p.Y.Neg(api, p1.Y)
+	p.X = p1.X
+	return p
+}
+
+// AddAssign add p1 to p and return p
+func (p *G2Affine) AddAssign(api frontend.API, p1 *G2Affine) *G2Affine {
+
+	var n, d, l, xr, yr fields_bls24315.E4
+
+	// compute lambda = (p1.y-p.y)/(p1.x-p.x)
+	n.Sub(api, p1.Y, p.Y)
+	d.Sub(api, p1.X, p.X)
+	l.Inverse(api, d).Mul(api, l, n)
+
+	// xr =lambda**2-p1.x-p.x
+	xr.Square(api, l).
+		Sub(api, xr, p1.X).
+		Sub(api, xr, p.X)
+
+	// yr = lambda(p.x - xr)-p.y
+	yr.Sub(api, p.X, xr).
+		Mul(api, l, yr).
+		Sub(api, yr, p.Y)
+
+	p.X = xr
+	p.Y = yr
+
+	return p
+}
+
+// Double compute 2*p1, assign the result to p and return it
+// Only for curve with j invariant 0 (a=0).
+func (p *G2Affine) Double(api frontend.API, p1 *G2Affine) *G2Affine {
+
+	var n, d, l, xr, yr fields_bls24315.E4
+
+	// lambda = 3*p1.x**2/2*p.y
+	n.Square(api, p1.X).MulByFp(api, n, 3)
+	d.MulByFp(api, p1.Y, 2)
+	l.Inverse(api, d).Mul(api, l, n)
+
+	// xr = lambda**2-2*p1.x
+	xr.Square(api, l).
+		Sub(api, xr, p1.X).
+		Sub(api, xr, p1.X)
+
+	// yr = lambda*(p.x-xr)-p.y
+	yr.Sub(api, p1.X, xr).
+		Mul(api, l, yr).
+		Sub(api, yr, p1.Y)
+
+	p.X = xr
+	p.Y = yr
+
+	return p
+
+}
+
+// Assign a value to self (witness assignment)
+func (p *G2Affine) Assign(p1 *bls24315.G2Affine) {
+	p.X.Assign(&p1.X)
+	p.Y.Assign(&p1.Y)
+}
+
+// MustBeEqual constraint self to be equal to other into the given constraint system
+func (p *G2Affine) MustBeEqual(api frontend.API, other G2Affine) {
+	p.X.MustBeEqual(api, other.X)
+	p.Y.MustBeEqual(api, other.Y)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw_bls24315
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/fields_bls24315"
+)
+
+// loopCounter is the NAF decomposition of the ate loop |x| = 3218079743 (little endian),
+// where x = -2**32+2**30+2**22-2**20+1 is the seed of bls24315
+var loopCounter [33]int8
+
+func init() {
+	ecc.NafDecomposition(big.NewInt(3218079743), loopCounter[:])
+}
+
+// LineEvaluation represents a sparse Fp24 Elmt (result of the line evaluation)
+// the line is R0*y + R1 + R2*x, up to an Fp4 factor which is killed by the final exponentiation
+type LineEvaluation struct {
+	R0, R1, R2 fields_bls24315.E4
+}
+
+// MillerLoop computes the miller loop
+func MillerLoop(api frontend.API, P G1Affine, Q G2Affine, res *fields_bls24315.E24) *fields_bls24315.E24 {
+
+	res.SetOne(api)
+	var l LineEvaluation
+
+	var QCur, QNeg G2Affine
+	QCur = Q
+	QNeg.Neg(api, &Q)
+
+	mulLine := func() {
+		l.R0.MulByFp(api, l.R0, P.Y)
+		l.R2.MulByFp(api, l.R2, P.X)
+		res.MulBy012(api, l.R0, l.R1, l.R2)
+	}
+
+	// i == 31
+	QCur, l = DoubleStep(api, &QCur)
+	mulLine()
+
+	for i := len(loopCounter) - 3; i >= 0; i-- {
+		res.Square(api, *res)
+
+		QCur, l = DoubleStep(api, &QCur)
+		mulLine()
+
+		if loopCounter[i] == 1 {
+			QCur, l = AddStep(api, &QCur, &Q)
+			mulLine()
+		} else if loopCounter[i] == -1 {
+			QCur, l = AddStep(api, &QCur, &QNeg)
+			mulLine()
+		}
+	}
+
+	// the seed is negative
+	res.Conjugate(api, *res)
+
+	return res
+}
+
+// AddStep adds p2 to p1 and evaluates the line passing through p1 and p2
+func AddStep(api frontend.API, p1, p2 *G2Affine) (G2Affine, LineEvaluation) {
+
+	var n, d, l, xr, yr fields_bls24315.E4
+	var line LineEvaluation
+	var p G2Affine
+
+	// compute lambda = (p1.y-p2.y)/(p1.x-p2.x)
+	n.Sub(api, p1.Y, p2.Y)
+	d.Sub(api, p1.X, p2.X)
+	l.Inverse(api, d).Mul(api, l, n)
+
+	// xr =lambda**2-p1.x-p2.x
+	xr.Square(api, l).
+		Sub(api, xr, p1.X).
+		Sub(api, xr, p2.X)
+
+	// yr = lambda(p1.x - xr)-p1.y
+	yr.Sub(api, p1.X, xr).
+		Mul(api, l, yr).
+		Sub(api, yr, p1.Y)
+
+	p.X = xr
+	p.Y = yr
+
+	line.R0.SetOne(api)
+	line.R1.Mul(api, l, p1.X).Sub(api, line.R1, p1.Y)
+	line.R2.Neg(api, l)
+
+	return p, line
+}
+
+// DoubleStep doubles p1 and evaluates the tangent line at p1
+func DoubleStep(api frontend.API, p1 *G2Affine) (G2Affine, LineEvaluation) {
+
+	var n, d, l, xr, yr fields_bls24315.E4
+	var p G2Affine
+	var line LineEvaluation
+
+	// lambda = 3*p1.x**2/2*p.y
+	n.Square(api, p1.X).MulByFp(api, n, 3)
+	d.MulByFp(api, p1.Y, 2)
+	l.Inverse(api, d).Mul(api, l, n)
+
+	// xr = lambda**2-2*p1.x
+	xr.Square(api, l).
+		Sub(api, xr, p1.X).
+		Sub(api, xr, p1.X)
+
+	// yr = lambda*(p.x-xr)-p.y
+	yr.Sub(api, p1.X, xr).
+		Mul(api, l, yr).
+		Sub(api, yr, p1.Y)
+
+	p.X = xr
+	p.Y = yr
+
+	line.R0.SetOne(api)
+	line.R1.Mul(api, l, p1.X).Sub(api, line.R1, p1.Y)
+	line.R2.Neg(api, l)
+
+	return p, line
+
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw_bls24315
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/fields_bls24315"
+	"github.com/consensys/gnark/test"
+)
+
+type pairingBLS24315 struct {
+	P          G1Affine `gnark:",public"`
+	Q          G2Affine
+	PairingRes fields_bls24315.E24 `gnark:",public"`
+}
+
+func (circuit *pairingBLS24315) Define(curveID ecc.ID, api frontend.API) error {
+
+	milRes := fields_bls24315.E24{}
+	MillerLoop(api, circuit.P, circuit.Q, &milRes)
+
+	pairingRes := fields_bls24315.E24{}
+	pairingRes.FinalExponentiation(api, milRes)
+
+	pairingRes.MustBeEqual(api, circuit.PairingRes)
+
+	return nil
+}
+
+func TestPairingBLS24315(t *testing.T) {
+
+	// pairing test data
+	P, Q, pairingRes := pairingData()
+
+	// create cs
+	var circuit, witness pairingBLS24315
+
+	// assign values to witness
+	witness.P.Assign(&P)
+	witness.Q.Assign(&Q)
+	witness.PairingRes.Assign(&pairingRes)
+
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_633))
+
+}
+
+func pairingData() (P bls24315.G1Affine, Q bls24315.G2Affine, pairingRes bls24315.GT) {
+	_, _, P, Q = bls24315.Generators()
+
+	var u, v fr.Element
+	var _u, _v big.Int
+	u.SetRandom()
+	v.SetRandom()
+	u.ToBigIntRegular(&_u)
+	v.ToBigIntRegular(&_v)
+	P.ScalarMultiplication(&P, &_u)
+	Q.ScalarMultiplication(&Q, &_v)
+
+	var err error
+	pairingRes, err = bls24315.Pair([]bls24315.G1Affine{P}, []bls24315.G2Affine{Q})
+	if err != nil {
+		panic(err)
+	}
+	return
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw_bls24315
+
+import (
+	"math/big"
+
+	bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fp"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/fields_bls24315"
+)
+
+// G1AffinePublic is a point of G1 (BLS24-315) as public inputs of a circuit (BW6-633), its coordinates
+// X then Y, a base field element of BLS24-315 being a single input. See G1PublicInputs.
+//
+// As the visibility of a field overrides the one of its sub-fields, a field of type G1AffinePublic is tagged
+// `gnark:",public"` (or `gnark:",embed"`), else its coordinates are secret inputs.
+type G1AffinePublic struct {
+	X, Y frontend.Variable `gnark:",public"`
+}
+
+// G1Affine returns the point p, for the arithmetic of G1Affine
+func (p *G1AffinePublic) G1Affine() G1Affine {
+	return G1Affine{X: p.X, Y: p.Y}
+}
+
+// Assign a value to self (witness assignment)
+func (p *G1AffinePublic) Assign(p1 *bls24315.G1Affine) {
+	p.X.Assign(bls24315FpTobw6633fr(&p1.X))
+	p.Y.Assign(bls24315FpTobw6633fr(&p1.Y))
+}
+
+// G1PublicInputs returns the values of the public inputs of a G1AffinePublic assigned p (see Assign), in
+// their order: X, Y
+func G1PublicInputs(p *bls24315.G1Affine) []*big.Int {
+	return []*big.Int{p.X.ToBigIntRegular(new(big.Int)), p.Y.ToBigIntRegular(new(big.Int))}
+}
+
+// G2AffinePublic is a point of G2 (BLS24-315) as public inputs of a circuit (BW6-633), the components of
+// its coordinates in E4: X.B0.A0, X.B0.A1, X.B1.A0, X.B1.A1, then the ones of Y. See G2PublicInputs.
+// Like a G1AffinePublic, a field of type G2AffinePublic is tagged `gnark:",public"`.
+type G2AffinePublic struct {
+	X, Y fields_bls24315.E4 `gnark:",public"`
+}
+
+// G2Affine returns the point p, for the arithmetic of G2Affine
+func (p *G2AffinePublic) G2Affine() G2Affine {
+	return G2Affine{X: p.X, Y: p.Y}
+}
+
+// Assign a value to self (witness assignment)
+func (p *G2AffinePublic) Assign(p1 *bls24315.G2Affine) {
+	p.X.Assign(&p1.X)
+	p.Y.Assign(&p1.Y)
+}
+
+// G2PublicInputs returns the values of the public inputs of a G2AffinePublic assigned p (see Assign), in
+// their order: X.B0.A0, X.B0.A1, X.B1.A0, X.B1.A1, Y.B0.A0, Y.B0.A1, Y.B1.A0, Y.B1.A1
+func G2PublicInputs(p *bls24315.G2Affine) []*big.Int {
+	res := make([]*big.Int, 8)
+	for i, c := range []*fp.Element{&p.X.B0.A0, &p.X.B0.A1, &p.X.B1.A0, &p.X.B1.A1, &p.Y.B0.A0, &p.Y.B0.A1, &p.Y.B1.A0, &p.Y.B1.A1} {
+		res[i] = c.ToBigIntRegular(new(big.Int))
+	}
+	return res
+}
@@ -53,7 +53,7 @@ func NewEdCurve(id ecc.ID) (EdCurve, error) {
 func newBandersnatch() EdCurve {
 
 	edcurve := bandersnatch.GetEdwardsCurve()
-	edcurve.Cofactor.FromMont()
+	edcurve.Cofactor.ToMont() // gnark-crypto stores the cofactor out of Montgomery form
 
 	return EdCurve{
 		A:        frontend.FromInterface(edcurve.A),
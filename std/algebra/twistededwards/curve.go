@@ -25,6 +25,7 @@ import (
 	edbls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/twistededwards"
 	edbls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/twistededwards"
 	edbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	edbw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/twistededwards"
 	edbw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/twistededwards"
 	"github.com/consensys/gnark/frontend"
 )
@@ -45,6 +46,7 @@ func init() {
 		ecc.BLS12_377: newEdBLS377,
 		ecc.BW6_761:   newEdBW761,
 		ecc.BLS24_315: newEdBLS315,
+		ecc.BW6_633:   newEdBW633,
 	}
 }
 
@@ -62,7 +64,7 @@ func NewEdCurve(id ecc.ID) (EdCurve, error) {
 func newEdBN254() EdCurve {
 
 	edcurve := edbn254.GetEdwardsCurve()
-	edcurve.Cofactor.FromMont()
+	edcurve.Cofactor.ToMont() // gnark-crypto stores the cofactor out of Montgomery form
 
 	return EdCurve{
 		A:        frontend.FromInterface(edcurve.A),
@@ -79,7 +81,7 @@ func newEdBN254() EdCurve {
 func newEdBLS381() EdCurve {
 
 	edcurve := edbls12381.GetEdwardsCurve()
-	edcurve.Cofactor.FromMont()
+	edcurve.Cofactor.ToMont() // gnark-crypto stores the cofactor out of Montgomery form
 
 	return EdCurve{
 		A:        frontend.FromInterface(edcurve.A),
@@ -96,7 +98,7 @@ func newEdBLS381() EdCurve {
 func newEdBLS377() EdCurve {
 
 	edcurve := edbls12377.GetEdwardsCurve()
-	edcurve.Cofactor.FromMont()
+	edcurve.Cofactor.ToMont() // gnark-crypto stores the cofactor out of Montgomery form
 
 	return EdCurve{
 		A:        frontend.FromInterface(edcurve.A),
@@ -113,7 +115,7 @@ func newEdBLS377() EdCurve {
 func newEdBW761() EdCurve {
 
 	edcurve := edbw6761.GetEdwardsCurve()
-	edcurve.Cofactor.FromMont()
+	edcurve.Cofactor.ToMont() // gnark-crypto stores the cofactor out of Montgomery form
 
 	return EdCurve{
 		A:        frontend.FromInterface(edcurve.A),
@@ -130,7 +132,7 @@ func newEdBW761() EdCurve {
 func newEdBLS315() EdCurve {
 
 	edcurve := edbls24315.GetEdwardsCurve()
-	edcurve.Cofactor.FromMont()
+	edcurve.Cofactor.ToMont() // gnark-crypto stores the cofactor out of Montgomery form
 
 	return EdCurve{
 		A:        frontend.FromInterface(edcurve.A),
@@ -143,3 +145,20 @@ func newEdBLS315() EdCurve {
 	}
 
 }
+
+func newEdBW633() EdCurve {
+
+	edcurve := edbw6633.GetEdwardsCurve()
+	edcurve.Cofactor.ToMont() // gnark-crypto stores the cofactor out of Montgomery form
+
+	return EdCurve{
+		A:        frontend.FromInterface(edcurve.A),
+		D:        frontend.FromInterface(edcurve.D),
+		Cofactor: frontend.FromInterface(edcurve.Cofactor),
+		Order:    frontend.FromInterface(edcurve.Order),
+		BaseX:    frontend.FromInterface(edcurve.Base.X),
+		BaseY:    frontend.FromInterface(edcurve.Base.Y),
+		ID:       ecc.BW6_633,
+	}
+
+}
@@ -181,3 +181,31 @@ func (p *Point) Neg(api frontend.API, p1 *Point) *Point {
 	p.Y = p1.Y
 	return p
 }
+
+// AssertIsOnCurve fails if p is not on the twisted Edwards curve
+// a*x^2 + y^2 = 1 + d*x^2*y^2.
+func AssertIsOnCurve(api frontend.API, p Point, curve EdCurve) {
+	p.MustBeOnCurve(api, curve)
+}
+
+// AssertIsInSubgroup fails if p has a small order, that is if [cofactor]p is the identity.
+//
+// p must be on the curve (see AssertIsOnCurve). The cofactor clearing costs log2(cofactor)
+// doublings (3 for cofactor 8); it rejects the points of the small-order subgroup, but not
+// the sum of a point of prime order and a point of small order: protocols checking
+// [cofactor]-multiplied equations, as EdDSA Verify does, are not affected by the latter.
+func AssertIsInSubgroup(api frontend.API, p Point, curve EdCurve) {
+
+	// [cofactor]p, left to right double and add on the (constant) bits of the cofactor
+	res := Point{p.X, p.Y}
+	for i := curve.Cofactor.BitLen() - 2; i >= 0; i-- {
+		res.Double(api, &res, curve)
+		if curve.Cofactor.Bit(i) == 1 {
+			res.AddGeneric(api, &res, &p, curve)
+		}
+	}
+
+	// the only points of the curve with x == 0 are (0, 1) and (0, -1). [cofactor]p is in the
+	// prime order subgroup, so it can't be (0, -1) (of order 2): x != 0 iff it's not the identity
+	api.AssertIsDifferent(res.X, 0)
+}
@@ -349,3 +349,95 @@ func TestNeg(t *testing.T) {
 	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
 
 }
+
+type assertIsOnCurve struct {
+	P Point
+}
+
+func (circuit *assertIsOnCurve) Define(curveID ecc.ID, api frontend.API) error {
+
+	// get edwards curve params
+	params, err := NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+
+	AssertIsOnCurve(api, circuit.P, params)
+
+	return nil
+}
+
+type assertIsInSubgroup struct {
+	P Point
+}
+
+func (circuit *assertIsInSubgroup) Define(curveID ecc.ID, api frontend.API) error {
+
+	// get edwards curve params
+	params, err := NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+
+	AssertIsOnCurve(api, circuit.P, params)
+	AssertIsInSubgroup(api, circuit.P, params)
+
+	return nil
+}
+
+func TestAssertIsOnCurveAndInSubgroup(t *testing.T) {
+
+	assert := test.NewAssert(t)
+
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+
+		params, err := NewEdCurve(curveID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		modulus := curveID.Info().Fr.Modulus()
+
+		// the base point is on the curve and in the prime order subgroup
+		{
+			var witness assertIsOnCurve
+			witness.P.X.Assign(params.BaseX)
+			witness.P.Y.Assign(params.BaseY)
+			assert.ProverSucceeded(&assertIsOnCurve{}, &witness, test.WithCurves(curveID))
+		}
+		{
+			var witness assertIsInSubgroup
+			witness.P.X.Assign(params.BaseX)
+			witness.P.Y.Assign(params.BaseY)
+			assert.ProverSucceeded(&assertIsInSubgroup{}, &witness, test.WithCurves(curveID))
+		}
+
+		// (baseX, baseY+1) is not on the curve
+		{
+			var witness assertIsOnCurve
+			witness.P.X.Assign(params.BaseX)
+			witness.P.Y.Assign(new(big.Int).Add(&params.BaseY, big.NewInt(1)))
+			assert.ProverFailed(&assertIsOnCurve{}, &witness, test.WithCurves(curveID))
+		}
+
+		// (0, -1) has order 2, (sqrt(1/a), 0) has order 4
+		var minusOne, x4 big.Int
+		minusOne.Sub(modulus, big.NewInt(1))
+		x4.ModInverse(&params.A, modulus)
+		x4.Mod(&x4, modulus).ModSqrt(&x4, modulus)
+		for _, p := range [][2]*big.Int{{big.NewInt(0), &minusOne}, {&x4, big.NewInt(0)}} {
+			var witness assertIsInSubgroup
+			witness.P.X.Assign(p[0])
+			witness.P.Y.Assign(p[1])
+			assert.ProverFailed(&assertIsInSubgroup{}, &witness, test.WithCurves(curveID))
+		}
+
+		// a point of prime order, plus a point of order 2, is not rejected by the cofactor clearing
+		{
+			var witness assertIsInSubgroup
+			witness.P.X.Assign(new(big.Int).Sub(modulus, &params.BaseX))
+			witness.P.Y.Assign(new(big.Int).Sub(modulus, &params.BaseY))
+			assert.ProverSucceeded(&assertIsInSubgroup{}, &witness, test.WithCurves(curveID))
+		}
+	}
+
+}
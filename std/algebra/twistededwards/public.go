@@ -0,0 +1,115 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package twistededwards
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	edbls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/twistededwards"
+	edbls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/twistededwards"
+	edbls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/twistededwards"
+	edbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	edbw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/twistededwards"
+	edbw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/twistededwards"
+	"github.com/consensys/gnark/frontend"
+)
+
+// ErrPointNotOnCurve is returned by PublicInputs for a point which isn't on its twisted Edwards curve
+var ErrPointNotOnCurve = errors.New("point not on the twisted Edwards curve")
+
+// PointPublic is a point of the twisted Edwards curve as public inputs of a circuit, its coordinates X
+// then Y (for example the public key of a signature). It is assigned from the native point, see Assign,
+// and its public inputs are the values of PublicInputs.
+//
+// As the visibility of a field overrides the one of its sub-fields, a field of type PointPublic is tagged
+// `gnark:",public"` (or `gnark:",embed"`), else its coordinates are secret inputs.
+type PointPublic struct {
+	X, Y frontend.Variable `gnark:",public"`
+}
+
+// Point returns the point p, for the arithmetic of Point
+func (p *PointPublic) Point() Point {
+	return Point{X: p.X, Y: p.Y}
+}
+
+// Assign sets the coordinates of p to the ones of the native point (witness assignment), a PointAffine of
+// the twisted Edwards curve of one of the curves of gnark-crypto, or a pointer to one. It panics if the
+// point isn't one, see PublicInputs.
+func (p *PointPublic) Assign(point interface{}) {
+	inputs, err := PublicInputs(point)
+	if err != nil {
+		panic(err)
+	}
+	p.X.Assign(inputs[0])
+	p.Y.Assign(inputs[1])
+}
+
+// PublicInputs returns the values of the public inputs of a PointPublic assigned the native point (see
+// Assign), in their order: the coordinates X, Y of the point. The public inputs of a circuit are the ones
+// of its fields in the order of their declaration, a point taking two of them.
+//
+// It returns ErrPointNotOnCurve if the point isn't on its curve.
+func PublicInputs(point interface{}) ([]*big.Int, error) {
+	var onCurve bool
+	var x, y big.Int
+	switch p := point.(type) {
+	case edbn254.PointAffine:
+		return PublicInputs(&p)
+	case *edbn254.PointAffine:
+		onCurve = p.IsOnCurve()
+		p.X.ToBigIntRegular(&x)
+		p.Y.ToBigIntRegular(&y)
+	case edbls12381.PointAffine:
+		return PublicInputs(&p)
+	case *edbls12381.PointAffine:
+		onCurve = p.IsOnCurve()
+		p.X.ToBigIntRegular(&x)
+		p.Y.ToBigIntRegular(&y)
+	case edbls12377.PointAffine:
+		return PublicInputs(&p)
+	case *edbls12377.PointAffine:
+		onCurve = p.IsOnCurve()
+		p.X.ToBigIntRegular(&x)
+		p.Y.ToBigIntRegular(&y)
+	case edbw6761.PointAffine:
+		return PublicInputs(&p)
+	case *edbw6761.PointAffine:
+		onCurve = p.IsOnCurve()
+		p.X.ToBigIntRegular(&x)
+		p.Y.ToBigIntRegular(&y)
+	case edbls24315.PointAffine:
+		return PublicInputs(&p)
+	case *edbls24315.PointAffine:
+		onCurve = p.IsOnCurve()
+		p.X.ToBigIntRegular(&x)
+		p.Y.ToBigIntRegular(&y)
+	case edbw6633.PointAffine:
+		return PublicInputs(&p)
+	case *edbw6633.PointAffine:
+		onCurve = p.IsOnCurve()
+		p.X.ToBigIntRegular(&x)
+		p.Y.ToBigIntRegular(&y)
+	default:
+		return nil, fmt.Errorf("%T is not a point of a twisted Edwards curve", point)
+	}
+	if !onCurve {
+		return nil, ErrPointNotOnCurve
+	}
+	return []*big.Int{&x, &y}, nil
+}
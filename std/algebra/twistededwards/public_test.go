@@ -0,0 +1,106 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package twistededwards
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// publicKey checks that PublicKey is [S]Base
+type publicKey struct {
+	PublicKey PointPublic       `gnark:",public"`
+	S         frontend.Variable `gnark:",secret"`
+}
+
+func (circuit *publicKey) Define(curveID ecc.ID, api frontend.API) error {
+	params, err := NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	var p Point
+	p.ScalarMulFixedBase(api, params.BaseX, params.BaseY, circuit.S, params)
+
+	pk := circuit.PublicKey.Point()
+	api.AssertIsEqual(p.X, pk.X)
+	api.AssertIsEqual(p.Y, pk.Y)
+	return nil
+}
+
+// nativePublicKey returns [s]Base on the twisted Edwards curve of BN254
+func nativePublicKey(s *big.Int) twistededwards.PointAffine {
+	var p twistededwards.PointAffine
+	base := twistededwards.GetEdwardsCurve().Base
+	p.ScalarMul(&base, s)
+	return p
+}
+
+func TestPointPublic(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	s := big.NewInt(928323002)
+	pk := nativePublicKey(s)
+	var circuit, assignment publicKey
+	assignment.PublicKey.Assign(pk)
+	assignment.S.Assign(s)
+	assert.ProverSucceeded(&circuit, &assignment, test.WithCurves(ecc.BN254))
+
+	// the proof verifies with the public witness of the helper
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+	pkey, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	proof, err := groth16.Prove(ccs, pkey, &assignment)
+	assert.NoError(err)
+
+	inputs, err := PublicInputs(&pk)
+	assert.NoError(err)
+	public, _, err := witness.ToVector(&assignment, ccs)
+	assert.NoError(err)
+	assert.Equal(public, inputs)
+	publicWitness, err := witness.FromVector(ccs, inputs, nil)
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, publicWitness))
+
+	// and not with another point
+	other := nativePublicKey(big.NewInt(2))
+	inputs, err = PublicInputs(other)
+	assert.NoError(err)
+	publicWitness, err = witness.FromVector(ccs, inputs, nil)
+	assert.NoError(err)
+	assert.Error(groth16.Verify(proof, vk, publicWitness))
+}
+
+func TestPublicInputsInvalidPoint(t *testing.T) {
+	pk := nativePublicKey(big.NewInt(3))
+	pk.X.SetUint64(1)
+	if _, err := PublicInputs(&pk); !errors.Is(err, ErrPointNotOnCurve) {
+		t.Fatalf("expected ErrPointNotOnCurve, got %v", err)
+	}
+	if _, err := PublicInputs(big.NewInt(3)); err == nil {
+		t.Fatal("expected an error for a value which isn't a point")
+	}
+}
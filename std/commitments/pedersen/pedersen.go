@@ -0,0 +1,267 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pedersen provides Pedersen vector commitments over the twisted Edwards curve embedded in
+// the SNARK field (see std/algebra/twistededwards), in a circuit and natively:
+//
+//	C = [v_0]G_0 + ... + [v_{n-1}]G_{n-1} + [r]H
+//
+// where v are the committed values, r the blinding factor, and G, H the points of a Basis.
+//
+// The points of the basis are in the subgroup of prime order l of the curve (Basis.Curve.Order),
+// smaller than the modulus of the SNARK field: the values and the blinding factor are committed
+// modulo l, not modulo the SNARK field. They are decomposed in a circuit on the bit length of l,
+// less than the one of the modulus, so that a value has a single decomposition, and must be less
+// than 2^l.BitLen(). v and v + l (when it is in range) have the same commitment: an opening binds
+// the values modulo l only, a circuit which needs them to be binding in the SNARK field must
+// constrain them below l.
+package pedersen
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+)
+
+// domain separates the hash to curve of the basis points from the other uses of the seed
+const domain = "gnark/std/commitments/pedersen"
+
+// PointAffine is a point of the twisted Edwards curve, out of a circuit
+type PointAffine struct {
+	X, Y big.Int
+}
+
+// Basis is the set of points a vector is committed with: G[i] for the i-th value, H for the
+// blinding factor.
+//
+// Nobody knows the discrete logarithm of a point in the basis relatively to the others: they are
+// hashed to the curve, see Setup.
+type Basis struct {
+	G     []PointAffine
+	H     PointAffine
+	Curve twistededwards.EdCurve
+
+	modulus *big.Int // of the SNARK field, in which the coordinates are
+}
+
+// Setup returns the basis to commit to vectors of nbElements values, on the twisted Edwards curve
+// embedded in the SNARK field of curveID. The basis is a deterministic function of seed.
+//
+// The i-th point (H being the nbElements-th) is hashed to the curve by try and increment: x is the
+// SHA-512 digest of domain || seed || i || counter (big endian uint32) reduced in the field, for the
+// first counter such that x is the abscissa of a point (x, y) (y the smallest of the two
+// square roots). The point is then multiplied by the cofactor to be in the prime order subgroup.
+func Setup(curveID ecc.ID, seed string, nbElements int) (Basis, error) {
+	if nbElements < 0 {
+		return Basis{}, errors.New("negative number of elements")
+	}
+	curve, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return Basis{}, err
+	}
+	basis := Basis{
+		G:       make([]PointAffine, nbElements),
+		Curve:   curve,
+		modulus: curveID.Info().Fr.Modulus(),
+	}
+	for i := 0; i < nbElements; i++ {
+		basis.G[i] = basis.hashToCurve(seed, uint32(i))
+	}
+	basis.H = basis.hashToCurve(seed, uint32(nbElements))
+	return basis, nil
+}
+
+// Commit returns the commitment to values with blinding factor blinding, out of a circuit.
+// It is the point Commit computes in a circuit for the same values. The values and the blinding
+// factor must be in [0, 2^l.BitLen()), the range a circuit opens.
+func (basis *Basis) Commit(values []*big.Int, blinding *big.Int) (PointAffine, error) {
+	if len(values) != len(basis.G) {
+		return PointAffine{}, fmt.Errorf("%d values for a basis of %d elements", len(values), len(basis.G))
+	}
+	nbBits := basis.Curve.Order.BitLen()
+	for _, v := range append(append([]*big.Int{}, values...), blinding) {
+		if v.Sign() < 0 || v.BitLen() > nbBits {
+			return PointAffine{}, fmt.Errorf("%s doesn't fit on %d bits", v, nbBits)
+		}
+	}
+	var res, tmp PointAffine
+	res.Y.SetInt64(1)
+	for i := 0; i < len(values); i++ {
+		basis.scalarMul(&tmp, &basis.G[i], values[i])
+		basis.add(&res, &res, &tmp)
+	}
+	basis.scalarMul(&tmp, &basis.H, blinding)
+	basis.add(&res, &res, &tmp)
+	return res, nil
+}
+
+// Commit returns the commitment to values with blinding factor blinding. basis must be set up for
+// the curve the circuit is compiled with, and len(values) elements.
+//
+// The scalar multiplications by the (constant) points of the basis are windowed: the values are
+// decomposed on the bit length of l (basis.Curve.Order), and each window of 2 bits selects one of the 4 precomputed multiples of the
+// point, added to the result. The cost is linear in the number of values.
+func Commit(api frontend.API, basis Basis, values []frontend.Variable, blinding frontend.Variable) twistededwards.Point {
+	if len(values) != len(basis.G) {
+		panic(fmt.Sprintf("%d values for a basis of %d elements", len(values), len(basis.G)))
+	}
+	res := twistededwards.Point{X: api.Constant(0), Y: api.Constant(1)}
+	for i := 0; i < len(values); i++ {
+		basis.scalarMulFixedBase(api, &res, &basis.G[i], values[i])
+	}
+	basis.scalarMulFixedBase(api, &res, &basis.H, blinding)
+	return res
+}
+
+// AssertOpening fails if commitment isn't the commitment to values with blinding factor blinding.
+//
+// The values and the blinding factor must be less than 2^l.BitLen(), l being the order of the basis
+// points (basis.Curve.Order): they have a single decomposition on this many bits, which is less than
+// the bit length of the SNARK field. The opening is binding modulo l only: it also holds for
+// values + l when they are in range, see the package documentation.
+func AssertOpening(api frontend.API, basis Basis, commitment twistededwards.Point, values []frontend.Variable, blinding frontend.Variable) {
+	c := Commit(api, basis, values, blinding)
+	api.AssertIsEqual(c.X, commitment.X)
+	api.AssertIsEqual(c.Y, commitment.Y)
+}
+
+// scalarMulFixedBase adds [scalar]p to res, 2 bits of the scalar at a time. The scalar is decomposed
+// on the bit length of the order of p: 2^l.BitLen() is less than the modulus, so the decomposition of
+// the scalar is unique, a prover can't use the bits of scalar + modulus instead.
+func (basis *Basis) scalarMulFixedBase(api frontend.API, res *twistededwards.Point, p *PointAffine, scalar frontend.Variable) {
+	bits := api.ToBinary(scalar, basis.Curve.Order.BitLen())
+
+	// table holds [0]q, [1]q, [2]q, [3]q, with q = [4^j]p for the window j
+	var q PointAffine
+	q.X.Set(&p.X)
+	q.Y.Set(&p.Y)
+	for j := 0; j < len(bits); j += 2 {
+		var table [4]PointAffine
+		table[0].Y.SetInt64(1)
+		table[1].X.Set(&q.X)
+		table[1].Y.Set(&q.Y)
+		basis.add(&table[2], &q, &q)
+		basis.add(&table[3], &table[2], &q)
+
+		var selected twistededwards.Point
+		if j+1 < len(bits) {
+			selected = lookup2(api, bits[j], bits[j+1], &table)
+		} else {
+			selected.X = api.Add(&table[0].X, api.Mul(bits[j], new(big.Int).Sub(&table[1].X, &table[0].X)))
+			selected.Y = api.Add(&table[0].Y, api.Mul(bits[j], new(big.Int).Sub(&table[1].Y, &table[0].Y)))
+		}
+		res.AddGeneric(api, res, &selected, basis.Curve)
+
+		basis.add(&q, &table[2], &table[2])
+	}
+}
+
+// lookup2 returns table[b0 + 2*b1], the entries of the table being constants: a single constraint
+// (b0*b1) is shared by the coordinates
+func lookup2(api frontend.API, b0, b1 frontend.Variable, table *[4]PointAffine) twistededwards.Point {
+	b0b1 := api.Mul(b0, b1)
+	coordinate := func(c0, c1, c2, c3 *big.Int) frontend.Variable {
+		d1 := new(big.Int).Sub(c1, c0)
+		d2 := new(big.Int).Sub(c2, c0)
+		d3 := new(big.Int).Sub(c3, c2)
+		d3.Sub(d3, c1).Add(d3, c0)
+		return api.Add(c0, api.Mul(b0, d1), api.Mul(b1, d2), api.Mul(b0b1, d3))
+	}
+	return twistededwards.Point{
+		X: coordinate(&table[0].X, &table[1].X, &table[2].X, &table[3].X),
+		Y: coordinate(&table[0].Y, &table[1].Y, &table[2].Y, &table[3].Y),
+	}
+}
+
+// hashToCurve returns the point of index i of the basis, see Setup
+func (basis *Basis) hashToCurve(seed string, i uint32) PointAffine {
+	var x, xx, num, den, y big.Int
+	for counter := uint32(0); ; counter++ {
+		h := sha512.New()
+		h.Write([]byte(domain))
+		h.Write([]byte(seed))
+		var buf [8]byte
+		binary.BigEndian.PutUint32(buf[:4], i)
+		binary.BigEndian.PutUint32(buf[4:], counter)
+		h.Write(buf[:])
+		x.SetBytes(h.Sum(nil)).Mod(&x, basis.modulus)
+
+		// a*x^2 + y^2 = 1 + d*x^2*y^2 <=> y^2 = (1 - a*x^2) / (1 - d*x^2)
+		xx.Mul(&x, &x)
+		num.Mul(&basis.Curve.A, &xx).Sub(big.NewInt(1), &num).Mod(&num, basis.modulus)
+		den.Mul(&basis.Curve.D, &xx).Sub(big.NewInt(1), &den).Mod(&den, basis.modulus)
+		if den.Sign() == 0 {
+			continue
+		}
+		den.ModInverse(&den, basis.modulus)
+		num.Mul(&num, &den).Mod(&num, basis.modulus)
+		if y.ModSqrt(&num, basis.modulus) == nil {
+			continue
+		}
+		if negY := new(big.Int).Sub(basis.modulus, &y); negY.Cmp(&y) < 0 {
+			y.Set(negY)
+		}
+
+		// clear the cofactor; the points of small order end at the identity, which has x == 0
+		var p, res PointAffine
+		p.X.Set(&x)
+		p.Y.Set(&y)
+		basis.scalarMul(&res, &p, &basis.Curve.Cofactor)
+		if res.X.Sign() != 0 {
+			return res
+		}
+	}
+}
+
+// add sets res = p1 + p2, with the (complete) addition law of the twisted Edwards curve
+func (basis *Basis) add(res, p1, p2 *PointAffine) {
+	var x1y2, y1x2, y1y2, x1x2, dxy, n1, n2, d1, d2 big.Int
+	x1y2.Mul(&p1.X, &p2.Y)
+	y1x2.Mul(&p1.Y, &p2.X)
+	y1y2.Mul(&p1.Y, &p2.Y)
+	x1x2.Mul(&p1.X, &p2.X)
+
+	n1.Add(&x1y2, &y1x2)
+	n2.Mul(&basis.Curve.A, &x1x2).Sub(&y1y2, &n2)
+
+	dxy.Mul(&x1y2, &y1x2).Mul(&dxy, &basis.Curve.D).Mod(&dxy, basis.modulus)
+	d1.Add(big.NewInt(1), &dxy).ModInverse(&d1, basis.modulus)
+	d2.Sub(big.NewInt(1), &dxy).Mod(&d2, basis.modulus).ModInverse(&d2, basis.modulus)
+
+	res.X.Mul(&n1, &d1).Mod(&res.X, basis.modulus)
+	res.Y.Mul(&n2, &d2).Mod(&res.Y, basis.modulus)
+}
+
+// scalarMul sets res = [scalar]p, scalar being reduced in the SNARK field as a circuit variable is
+func (basis *Basis) scalarMul(res, p *PointAffine, scalar *big.Int) {
+	s := new(big.Int).Mod(scalar, basis.modulus)
+	var acc PointAffine
+	acc.Y.SetInt64(1)
+	for i := s.BitLen() - 1; i >= 0; i-- {
+		basis.add(&acc, &acc, &acc)
+		if s.Bit(i) == 1 {
+			basis.add(&acc, &acc, p)
+		}
+	}
+	res.X.Set(&acc.X)
+	res.Y.Set(&acc.Y)
+}
@@ -0,0 +1,235 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pedersen
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/bn254/cs"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+const seed = "pedersen test"
+
+type openingCircuit struct {
+	Values     []frontend.Variable
+	Blinding   frontend.Variable
+	Commitment twistededwards.Point `gnark:",public"`
+}
+
+func (circuit *openingCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	basis, err := Setup(curveID, seed, len(circuit.Values))
+	if err != nil {
+		return err
+	}
+	AssertOpening(api, basis, circuit.Commitment, circuit.Values, circuit.Blinding)
+	return nil
+}
+
+// randomScalars returns n random values less than max
+func randomScalars(t *testing.T, max *big.Int, n int) []*big.Int {
+	res := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		r, err := rand.Int(rand.Reader, max)
+		require.NoError(t, err)
+		res[i] = r
+	}
+	return res
+}
+
+func TestCommit(t *testing.T) {
+	assert := test.NewAssert(t)
+	const nbElements = 4
+
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		basis, err := Setup(curveID, seed, nbElements)
+		assert.NoError(err)
+
+		values := randomScalars(t, &basis.Curve.Order, nbElements+1)
+		commitment, err := basis.Commit(values[:nbElements], values[nbElements])
+		assert.NoError(err)
+
+		circuit := openingCircuit{Values: make([]frontend.Variable, nbElements)}
+		witness := openingCircuit{Values: make([]frontend.Variable, nbElements)}
+		for i := 0; i < nbElements; i++ {
+			witness.Values[i].Assign(values[i])
+		}
+		witness.Blinding.Assign(values[nbElements])
+		witness.Commitment.X.Assign(&commitment.X)
+		witness.Commitment.Y.Assign(&commitment.Y)
+		assert.ProverSucceeded(&circuit, &witness, test.WithCurves(curveID))
+
+		// a different blinding factor doesn't open the commitment
+		witness.Blinding = frontend.Variable{}
+		witness.Blinding.Assign(new(big.Int).Add(values[nbElements], big.NewInt(1)))
+		assert.ProverFailed(&circuit, &witness, test.WithCurves(curveID))
+	}
+}
+
+func TestNativeCommit(t *testing.T) {
+	assert := require.New(t)
+	const nbElements = 3
+
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		basis, err := Setup(curveID, seed, nbElements)
+		assert.NoError(err)
+
+		// the basis is deterministic, and its points are distinct, in the prime order subgroup
+		other, err := Setup(curveID, seed, nbElements)
+		assert.NoError(err)
+		assert.Equal(basis.G, other.G)
+		points := append(append([]PointAffine{}, basis.G...), basis.H)
+		for i := range points {
+			var p PointAffine
+			basis.scalarMul(&p, &points[i], &basis.Curve.Order)
+			assert.Equal(0, p.X.Sign())
+			assert.Equal(int64(1), p.Y.Int64())
+			for j := 0; j < i; j++ {
+				assert.NotEqual(points[i].X.String(), points[j].X.String())
+			}
+		}
+		other, err = Setup(curveID, "another seed", nbElements)
+		assert.NoError(err)
+		assert.NotEqual(basis.G[0].X.String(), other.G[0].X.String())
+
+		// the commitment is homomorphic, as long as the sums are in range: the values are less than
+		// half the order of the subgroup
+		half := new(big.Int).Rsh(&basis.Curve.Order, 1)
+		v1 := randomScalars(t, half, nbElements+1)
+		v2 := randomScalars(t, half, nbElements+1)
+		sum := make([]*big.Int, nbElements+1)
+		for i := range sum {
+			sum[i] = new(big.Int).Add(v1[i], v2[i])
+		}
+		c1, err := basis.Commit(v1[:nbElements], v1[nbElements])
+		assert.NoError(err)
+		c2, err := basis.Commit(v2[:nbElements], v2[nbElements])
+		assert.NoError(err)
+		c, err := basis.Commit(sum[:nbElements], sum[nbElements])
+		assert.NoError(err)
+		var expected PointAffine
+		basis.add(&expected, &c1, &c2)
+		assert.Equal(expected.X.String(), c.X.String())
+		assert.Equal(expected.Y.String(), c.Y.String())
+
+		// the values are committed modulo the order l of the subgroup: v + l has the same commitment,
+		// when it fits on the bit length of l
+		nbBits := basis.Curve.Order.BitLen()
+		bound := new(big.Int).Lsh(big.NewInt(1), uint(nbBits))
+		v1 = randomScalars(t, new(big.Int).Sub(bound, &basis.Curve.Order), nbElements+1)
+		c1, err = basis.Commit(v1[:nbElements], v1[nbElements])
+		assert.NoError(err)
+		shifted := append([]*big.Int{}, v1...)
+		shifted[0] = new(big.Int).Add(v1[0], &basis.Curve.Order)
+		shifted[nbElements] = new(big.Int).Add(v1[nbElements], &basis.Curve.Order)
+		c, err = basis.Commit(shifted[:nbElements], shifted[nbElements])
+		assert.NoError(err)
+		assert.Equal(c1.X.String(), c.X.String())
+		assert.Equal(c1.Y.String(), c.Y.String())
+
+		_, err = basis.Commit(v1, v1[0])
+		assert.Error(err)
+
+		// the values out of the range a circuit opens are rejected
+		_, err = basis.Commit(v1[:nbElements], bound)
+		assert.Error(err)
+		_, err = basis.Commit(v1[:nbElements], big.NewInt(-1))
+		assert.Error(err)
+	}
+}
+
+// nonCanonicalBit behaves like hint.IthBit, except it decomposes x + r instead of x, r being the
+// modulus of the SNARK field
+func nonCanonicalBit(curveID ecc.ID, inputs []*big.Int, result *big.Int) error {
+	x := new(big.Int).Add(inputs[0], curveID.Info().Fr.Modulus())
+	result.SetUint64(uint64(x.Bit(int(inputs[1].Uint64()))))
+	return nil
+}
+
+// TestCommitNonCanonical ensures a malicious prover can't open the commitment to v + r, r being the
+// modulus of the SNARK field, with the value v decomposed as v + r
+func TestCommitNonCanonical(t *testing.T) {
+	assert := require.New(t)
+	const nbElements = 1
+	curveID := ecc.BN254
+
+	basis, err := Setup(curveID, seed, nbElements)
+	assert.NoError(err)
+	r := curveID.Info().Fr.Modulus()
+	shifted := func(v int64) *big.Int {
+		s := new(big.Int).Add(big.NewInt(v), r)
+		return s.Mod(s, &basis.Curve.Order)
+	}
+	commitment, err := basis.Commit([]*big.Int{shifted(1)}, shifted(2))
+	assert.NoError(err)
+
+	var witness openingCircuit
+	witness.Values = make([]frontend.Variable, nbElements)
+	witness.Values[0].Assign(1)
+	witness.Blinding.Assign(2)
+	witness.Commitment.X.Assign(&commitment.X)
+	witness.Commitment.Y.Assign(&commitment.Y)
+
+	ccs, err := frontend.Compile(curveID, backend.GROTH16, &openingCircuit{Values: make([]frontend.Variable, nbElements)})
+	assert.NoError(err)
+
+	// replace the bit decomposition hints by the malicious one
+	replaced := 0
+	hints := ccs.(*cs.R1CS).MHints
+	for vID, h := range hints {
+		if h.ID == hint.IthBitNamed.UUID() {
+			h.ID = hint.UUID(nonCanonicalBit)
+			hints[vID] = h
+			replaced++
+		}
+	}
+	assert.True(replaced > 0)
+
+	pk, err := groth16.DummySetup(ccs)
+	assert.NoError(err)
+	_, err = groth16.Prove(ccs, pk, &witness, backend.WithHints(nonCanonicalBit))
+	assert.Error(err)
+}
+
+// TestCommitCost checks that the number of constraints is linear in the number of values
+func TestCommitCost(t *testing.T) {
+	assert := require.New(t)
+
+	for _, b := range backend.Implemented() {
+		var nbConstraints []int
+		for n := 0; n < 4; n++ {
+			ccs, err := frontend.Compile(ecc.BN254, b, &openingCircuit{Values: make([]frontend.Variable, n)})
+			assert.NoError(err)
+			nbConstraints = append(nbConstraints, ccs.GetNbConstraints())
+		}
+		perValue := nbConstraints[1] - nbConstraints[0]
+		assert.True(perValue > 0)
+		for n := 1; n < len(nbConstraints); n++ {
+			assert.Equal(nbConstraints[0]+n*perValue, nbConstraints[n], "%s: %d values", b, n)
+		}
+		t.Logf("%s: %d constraints per value, %d for the blinding factor", b, perValue, nbConstraints[0])
+	}
+}
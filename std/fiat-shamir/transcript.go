@@ -17,20 +17,22 @@ limitations under the License.
 package fiatshamir
 
 import (
-	"errors"
-
+	"github.com/consensys/gnark/backend/fiatshamir"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/hash"
 )
 
-// errChallengeNotFound is returned when a wrong challenge name is provided.
+// the errors of the native transcript, such that the gadget fails like gnark/backend/fiatshamir
 var (
-	errChallengeNotFound            = errors.New("challenge not recorded in the Transcript")
-	errChallengeAlreadyComputed     = errors.New("challenge already computed, cannot be binded to other values")
-	errPreviousChallengeNotComputed = errors.New("the previous challenge is needed and has not been computed")
+	errChallengeNotFound            = fiatshamir.ErrChallengeNotFound
+	errChallengeAlreadyComputed     = fiatshamir.ErrChallengeAlreadyComputed
+	errPreviousChallengeNotComputed = fiatshamir.ErrPreviousChallengeNotComputed
 )
 
 // Transcript handles the creation of challenges for Fiat Shamir.
+//
+// With the MiMC hash function, it derives the same challenges as the native transcript of
+// gnark/backend/fiatshamir (see NewMiMCTranscript), for the same bound values.
 type Transcript struct {
 	// hash function that is used.
 	h hash.Hash
@@ -17,13 +17,16 @@ limitations under the License.
 package fiatshamir
 
 import (
+	"errors"
 	"math/big"
+	"strconv"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
 	"github.com/consensys/gnark-crypto/hash"
 	"github.com/consensys/gnark/backend"
+	native "github.com/consensys/gnark/backend/fiatshamir"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/hash/mimc"
 	"github.com/consensys/gnark/test"
@@ -100,6 +103,7 @@ func TestFiatShamir(t *testing.T) {
 		ecc.BLS12_381: hash.MIMC_BLS12_381,
 		ecc.BLS24_315: hash.MIMC_BLS24_315,
 		ecc.BW6_761:   hash.MIMC_BW6_761,
+		ecc.BW6_633:   hash.MIMC_BW6_633,
 	}
 
 	// compute the witness for each curve
@@ -148,6 +152,110 @@ func TestFiatShamir(t *testing.T) {
 
 }
 
+// plonkChallengesCircuit derives the challenges of a transcript with names of any length, as
+// outputs of the circuit
+type plonkChallengesCircuit struct {
+	Bindings   [3][2]frontend.Variable
+	Challenges [3]frontend.Variable `gnark:",output"`
+}
+
+var plonkChallenges = []string{"gamma", "alpha", "zeta"}
+
+func (circuit *plonkChallengesCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	h, err := mimc.NewMiMC("seed", curveID, api)
+	if err != nil {
+		return err
+	}
+	ts := NewTranscript(api, &h, plonkChallenges...)
+	for i, id := range plonkChallenges {
+		if err := ts.Bind(id, circuit.Bindings[i][:]); err != nil {
+			return err
+		}
+	}
+	for i, id := range plonkChallenges {
+		if circuit.Challenges[i], err = ts.ComputeChallenge(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestNativeTranscript(t *testing.T) {
+	for _, curveID := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BLS24_315, ecc.BW6_761, ecc.BW6_633} {
+		ts := native.NewMiMCTranscript(curveID, "seed", plonkChallenges...)
+
+		var witness plonkChallengesCircuit
+		for i, id := range plonkChallenges {
+			values := []*big.Int{big.NewInt(int64(i + 1)), new(big.Int).Neg(big.NewInt(int64(i)))}
+			if err := ts.Bind(id, values); err != nil {
+				t.Fatal(err)
+			}
+			for j := range values {
+				witness.Bindings[i][j] = frontend.Value(values[j])
+			}
+		}
+
+		outputs, err := frontend.NewSimulator(curveID).Run(&plonkChallengesCircuit{}, &witness)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, id := range plonkChallenges {
+			expected, err := ts.ComputeChallenge(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if outputs["Challenges_"+strconv.Itoa(i)].Cmp(expected) != 0 {
+				t.Fatalf("%s: challenge %s doesn't match the native transcript", curveID, id)
+			}
+		}
+	}
+}
+
+// orderingCircuit calls the transcript out of order, and checks it fails like the native transcript
+type orderingCircuit struct {
+	X frontend.Variable
+}
+
+func (circuit *orderingCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	h, err := mimc.NewMiMC("seed", curveID, api)
+	if err != nil {
+		return err
+	}
+	ts := NewTranscript(api, &h, plonkChallenges...)
+	nativeTs := native.NewMiMCTranscript(curveID, "seed", plonkChallenges...)
+
+	check := func(err, nativeErr, expected error) error {
+		if !errors.Is(err, expected) || !errors.Is(nativeErr, expected) {
+			return errors.New("transcripts don't fail as expected")
+		}
+		return nil
+	}
+
+	_, err = ts.ComputeChallenge("alpha")
+	_, nativeErr := nativeTs.ComputeChallenge("alpha")
+	if err := check(err, nativeErr, native.ErrPreviousChallengeNotComputed); err != nil {
+		return err
+	}
+	if err := check(ts.Bind("beta", nil), nativeTs.Bind("beta", nil), native.ErrChallengeNotFound); err != nil {
+		return err
+	}
+	if _, err := ts.ComputeChallenge("gamma"); err != nil {
+		return err
+	}
+	if _, err := nativeTs.ComputeChallenge("gamma"); err != nil {
+		return err
+	}
+	err = ts.Bind("gamma", []frontend.Variable{circuit.X})
+	nativeErr = nativeTs.Bind("gamma", []*big.Int{big.NewInt(1)})
+	return check(err, nativeErr, native.ErrChallengeAlreadyComputed)
+}
+
+func TestTranscriptOrdering(t *testing.T) {
+	if _, err := frontend.NewSimulator(ecc.BN254).Run(&orderingCircuit{}, &orderingCircuit{X: frontend.Value(1)}); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func BenchmarkCompile(b *testing.B) {
 	// create an empty cs
 	var circuit FiatShamirCircuit
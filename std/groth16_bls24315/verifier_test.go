@@ -0,0 +1,145 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package groth16_bls24315
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	backend_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	groth16_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/groth16"
+	"github.com/consensys/gnark/internal/backend/bls24-315/witness"
+	"github.com/consensys/gnark/std/algebra/sw_bls24315"
+	"github.com/consensys/gnark/test"
+)
+
+//--------------------------------------------------------------------
+// utils
+
+// cubicCircuit defines a simple circuit
+// x**3 + x + 5 == y
+type cubicCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (circuit *cubicCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	x3 := api.Mul(circuit.X, circuit.X, circuit.X)
+	api.AssertIsEqual(circuit.Y, api.Add(x3, circuit.X, 5))
+	return nil
+}
+
+// Prepare the data for the inner proof.
+func generateBls24315InnerProof(t *testing.T, vk *groth16_bls24315.VerifyingKey, proof *groth16_bls24315.Proof) {
+
+	// create a mock cs: knowing x such that x**3 + x + 5 == y
+	var circuit, w cubicCircuit
+	r1cs, err := frontend.Compile(ecc.BLS24_315, backend.GROTH16, &circuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.X.Assign(3)
+	w.Y.Assign(35)
+
+	correctAssignment := witness.Witness{}
+
+	err = correctAssignment.FromFullAssignment(&w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// generate the data to return for the bls24315 proof
+	var pk groth16_bls24315.ProvingKey
+	groth16_bls24315.Setup(r1cs.(*backend_bls24315.R1CS), &pk, vk)
+	_proof, err := groth16_bls24315.Prove(r1cs.(*backend_bls24315.R1CS), &pk, correctAssignment, backend.ProverOption{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof.Ar = _proof.Ar
+	proof.Bs = _proof.Bs
+	proof.Krs = _proof.Krs
+
+	correctAssignmentPublic := witness.Witness{}
+	err = correctAssignmentPublic.FromPublicAssignment(&w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// before returning verifies that the proof passes on bls24315
+	if err := groth16_bls24315.Verify(proof, vk, correctAssignmentPublic); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type verifierCircuit struct {
+	InnerProof Proof
+	InnerVk    VerifyingKey
+	Y          frontend.Variable `gnark:",public"`
+}
+
+func (circuit *verifierCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	Verify(api, circuit.InnerVk, circuit.InnerProof, []frontend.Variable{circuit.Y})
+	return nil
+}
+
+func TestVerifier(t *testing.T) {
+
+	// get the data
+	var innerVk groth16_bls24315.VerifyingKey
+	var innerProof groth16_bls24315.Proof
+	generateBls24315InnerProof(t, &innerVk, &innerProof) // get public inputs of the inner proof
+
+	// create an empty cs
+	var circuit verifierCircuit
+	circuit.InnerVk.G1 = make([]sw_bls24315.G1Affine, len(innerVk.G1.K))
+
+	// create assignment, the private part consists of the proof,
+	// the public part is exactly the public part of the inner proof,
+	// up to the renaming of the inner ONE_WIRE to not conflict with the one wire of the outer proof.
+	var witness verifierCircuit
+	witness.InnerProof.Ar.Assign(&innerProof.Ar)
+	witness.InnerProof.Krs.Assign(&innerProof.Krs)
+	witness.InnerProof.Bs.Assign(&innerProof.Bs)
+
+	// compute vk.e
+	e, err := bls24315.Pair([]bls24315.G1Affine{innerVk.G1.Alpha}, []bls24315.G2Affine{innerVk.G2.Beta})
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness.InnerVk.E.Assign(&e)
+
+	witness.InnerVk.G1 = make([]sw_bls24315.G1Affine, len(innerVk.G1.K))
+	for i, vkg := range innerVk.G1.K {
+		witness.InnerVk.G1[i].Assign(&vkg)
+	}
+	var deltaNeg, gammaNeg bls24315.G2Affine
+	deltaNeg.Neg(&innerVk.G2.Delta)
+	gammaNeg.Neg(&innerVk.G2.Gamma)
+	witness.InnerVk.G2.DeltaNeg.Assign(&deltaNeg)
+	witness.InnerVk.G2.GammaNeg.Assign(&gammaNeg)
+	witness.Y.Assign(35)
+
+	// verifies the cs
+	assert := test.NewAssert(t)
+
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_633))
+
+}
@@ -24,6 +24,7 @@ import (
 	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/mimc"
 	bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr/mimc"
 	bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr/mimc"
 	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr/mimc"
 
 	"github.com/consensys/gnark/frontend"
@@ -39,6 +40,7 @@ func init() {
 	encryptFuncs[ecc.BLS12_377] = encryptBLS377
 	encryptFuncs[ecc.BW6_761] = encryptBW761
 	encryptFuncs[ecc.BLS24_315] = encryptBLS315
+	encryptFuncs[ecc.BW6_633] = encryptBW633
 
 	newMimc = make(map[ecc.ID]func(string, frontend.API) MiMC)
 	newMimc[ecc.BN254] = newMimcBN254
@@ -46,6 +48,7 @@ func init() {
 	newMimc[ecc.BLS12_377] = newMimcBLS377
 	newMimc[ecc.BW6_761] = newMimcBW761
 	newMimc[ecc.BLS24_315] = newMimcBLS315
+	newMimc[ecc.BW6_633] = newMimcBW633
 }
 
 // -------------------------------------------------------------------------------------------------
@@ -121,6 +124,20 @@ func newMimcBLS315(seed string, api frontend.API) MiMC {
 	return res
 }
 
+func newMimcBW633(seed string, api frontend.API) MiMC {
+	res := MiMC{}
+	params := bw6633.NewParams(seed)
+	for _, v := range params {
+		var cpy big.Int
+		v.ToBigIntRegular(&cpy)
+		res.params = append(res.params, cpy)
+	}
+	res.id = ecc.BW6_633
+	res.h = api.Constant(0)
+	res.api = api
+	return res
+}
+
 // -------------------------------------------------------------------------------------------------
 // encryptions functions
 
@@ -200,3 +217,18 @@ func encryptBLS315(api frontend.API, h MiMC, message frontend.Variable, key fron
 	return res
 
 }
+
+// encryptBW633 of a mimc run expressed as r1cs
+func encryptBW633(api frontend.API, h MiMC, message frontend.Variable, key frontend.Variable) frontend.Variable {
+	res := message
+	for i := 0; i < len(h.params); i++ {
+		tmp := api.Add(res, h.params[i], key)
+		// res = (res+k+c)^5
+		res = api.Mul(tmp, tmp) // square
+		res = api.Mul(res, res) // square
+		res = api.Mul(res, tmp) // mul
+	}
+	res = api.Add(res, key)
+	return res
+
+}
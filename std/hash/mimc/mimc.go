@@ -70,3 +70,33 @@ func (h *MiMC) Sum() frontend.Variable {
 	return h.h
 
 }
+
+// SumVariableLength hashes the data already written, then the first length elements of data, then length
+// itself, and returns the hash as Sum does. length is only known when proving: it is a variable of
+// [0, len(data)], the circuit is unsatisfiable if it is out of this range, and the elements data[length:]
+// aren't hashed.
+//
+// As length is absorbed last, the hashes of two messages differ when one is a prefix of the other. The
+// hash is the one of gnark-crypto's MiMC of the elements data[:length] then length, each written as a
+// block of the field's size.
+//
+// Each element of data costs a MiMC encryption, an IsZero and a Select, whether it is hashed or not.
+func (h *MiMC) SumVariableLength(data []frontend.Variable, length frontend.Variable) frontend.Variable {
+	h.Sum()
+
+	// active is 1 while i < length: it drops to 0 at the index i == length, which must be in [0, len(data)]
+	active := h.api.Constant(1)
+	for i, stream := range data {
+		active = h.api.Sub(active, h.api.IsZero(h.api.Sub(length, i)))
+		next := encryptFuncs[h.id](h.api, *h, stream, h.h)
+		next = h.api.Add(next, stream)
+		h.h = h.api.Select(active, next, h.h)
+	}
+	h.api.AssertIsEqual(active, h.api.IsZero(h.api.Sub(length, len(data))))
+
+	// padding block
+	h.h = encryptFuncs[h.id](h.api, *h, length, h.h)
+	h.h = h.api.Add(h.h, length)
+
+	return h.h
+}
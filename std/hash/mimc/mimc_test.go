@@ -17,15 +17,21 @@ limitations under the License.
 package mimc
 
 import (
+	"flag"
 	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/test"
 )
 
+var update = flag.Bool("update", false, "update the golden file of the constraint counts")
+
 type mimcCircuit struct {
 	ExpectedResult frontend.Variable `gnark:"data,public"`
 	Data           frontend.Variable
@@ -57,6 +63,7 @@ func TestMimcAll(t *testing.T) {
 		ecc.BLS12_377: hash.MIMC_BLS12_377,
 		ecc.BW6_761:   hash.MIMC_BW6_761,
 		ecc.BLS24_315: hash.MIMC_BLS24_315,
+		ecc.BW6_633:   hash.MIMC_BW6_633,
 	}
 
 	for curve, hashFunc := range curves {
@@ -81,3 +88,113 @@ func TestMimcAll(t *testing.T) {
 	}
 
 }
+
+const maxLen = 4
+
+type mimcVariableLengthCircuit struct {
+	ExpectedResult frontend.Variable `gnark:",public"`
+	Data           [maxLen]frontend.Variable
+	Length         frontend.Variable
+}
+
+func (circuit *mimcVariableLengthCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	mimc, err := NewMiMC("seed", curveID, api)
+	if err != nil {
+		return err
+	}
+	result := mimc.SumVariableLength(circuit.Data[:], circuit.Length)
+	api.AssertIsEqual(result, circuit.ExpectedResult)
+	return nil
+}
+
+// sumVariableLength is the native hash of SumVariableLength
+func sumVariableLength(hashFunc hash.Hash, data []big.Int, length int) []byte {
+	h := hashFunc.New("seed")
+	block := make([]byte, h.BlockSize())
+	for i := 0; i < length; i++ {
+		h.Write(data[i].FillBytes(block))
+	}
+	h.Write(big.NewInt(int64(length)).FillBytes(block))
+	return h.Sum(nil)
+}
+
+func TestMimcVariableLength(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// the last element is 0, so that the message of length maxLen-1 is a prefix of the one of length maxLen
+	data := make([]big.Int, maxLen)
+	data[0].SetString("7808462342289447506325013279997289618334122576263655295146895675168642919487", 10)
+	data[1].SetUint64(42)
+	data[2].SetUint64(1)
+
+	witness := func(length interface{}, expected []byte) *mimcVariableLengthCircuit {
+		var w mimcVariableLengthCircuit
+		for i := range data {
+			w.Data[i].Assign(data[i])
+		}
+		w.Length.Assign(length)
+		w.ExpectedResult.Assign(expected)
+		return &w
+	}
+
+	curves := map[ecc.ID]hash.Hash{
+		ecc.BN254:     hash.MIMC_BN254,
+		ecc.BLS12_381: hash.MIMC_BLS12_381,
+		ecc.BLS12_377: hash.MIMC_BLS12_377,
+		ecc.BW6_761:   hash.MIMC_BW6_761,
+		ecc.BLS24_315: hash.MIMC_BLS24_315,
+		ecc.BW6_633:   hash.MIMC_BW6_633,
+	}
+
+	for curve, hashFunc := range curves {
+		for _, length := range []int{0, maxLen} {
+			expected := sumVariableLength(hashFunc, data, length)
+			assert.ProverSucceeded(&mimcVariableLengthCircuit{}, witness(length, expected), test.WithCurves(curve))
+		}
+	}
+
+	hashFunc := hash.MIMC_BN254
+	for length := 1; length < maxLen; length++ {
+		expected := sumVariableLength(hashFunc, data, length)
+		assert.ProverSucceeded(&mimcVariableLengthCircuit{}, witness(length, expected), test.WithCurves(ecc.BN254))
+
+		// a wrong claimed length doesn't hash the same elements
+		assert.ProverFailed(&mimcVariableLengthCircuit{}, witness(length+1, expected), test.WithCurves(ecc.BN254))
+		assert.ProverFailed(&mimcVariableLengthCircuit{}, witness(length-1, expected), test.WithCurves(ecc.BN254))
+	}
+
+	// a message and its prefix hash differently
+	assert.NotEqual(sumVariableLength(hashFunc, data, maxLen-1), sumVariableLength(hashFunc, data, maxLen))
+
+	// a length out of range
+	expected := sumVariableLength(hashFunc, data, maxLen)
+	assert.ProverFailed(&mimcVariableLengthCircuit{}, witness(maxLen+1, expected), test.WithCurves(ecc.BN254))
+	assert.ProverFailed(&mimcVariableLengthCircuit{}, witness(-1, expected), test.WithCurves(ecc.BN254))
+}
+
+// TestMimcConstraintCount catches the regressions of the number of constraints of MiMC, on all the curves
+// with the golden file (run the tests with -update to record it again)
+func TestMimcConstraintCount(t *testing.T) {
+	test.AssertConstraintCount(t, &mimcCircuit{}, backend.GROTH16, ecc.BN254, 274, 0.05)
+	test.AssertConstraintCount(t, &mimcCircuit{}, backend.PLONK, ecc.BN254, 274, 0.05)
+
+	circuits := map[string]frontend.Circuit{"mimc": &mimcCircuit{}}
+	golden := filepath.Join("testdata", "constraints.json")
+	if *update {
+		f, err := os.Create(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = test.RecordConstraintCounts(circuits, f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.Open(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	test.CheckConstraintCounts(t, circuits, f, 0.05)
+}
@@ -0,0 +1,199 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bls provides a ZKP-circuit function to verify BLS12_377 BLS signatures inside a BW6_761 circuit.
+//
+// The signatures are in G1 and the public keys in G2: the signature of a message m by the secret key sk
+// is σ = [sk]H(m), its public key is pk = [sk]g2, and Verify checks e(σ, g2) == e(H(m), pk).
+//
+// The message isn't hashed to the curve in the circuit: H(m) is computed natively, with
+// bls12377.HashToCurveG1Svdw (the signer and the verifier must use the same domain separation tag), and
+// given to the circuit, which checks that the point is on the curve. Nothing else binds it to the
+// message: make it a public input, which the verifier of the proof recomputes from the message, or the
+// prover may choose it.
+//
+// The points are checked to be on their curves, not to be in the subgroups of order r. The pairing of a
+// point of G1 of small order (dividing the cofactor of G1) with the keys is 1, so σ plus such a point is
+// accepted too: the signatures are malleable, which doesn't let anyone forge the signature of a message.
+// The public keys are expected to be valid keys of G2, checked out of the circuit.
+package bls
+
+import (
+	"errors"
+	"math/big"
+
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fp"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/fields"
+	"github.com/consensys/gnark/std/algebra/sw"
+)
+
+// PublicKey stores a BLS public key [sk]g2 (to be used in gnark circuit)
+type PublicKey struct {
+	A sw.G2Affine
+}
+
+// Signature stores a BLS signature [sk]H(m) (to be used in gnark circuit)
+type Signature struct {
+	S sw.G1Affine
+}
+
+// VerifyOption configures Verify and VerifyAggregate
+type VerifyOption struct {
+	SkipPublicKeyCheck bool
+}
+
+// SkipPublicKeyCheck disables the check that the public keys are on the curve,
+// for keys which are validated out of the circuit
+func SkipPublicKeyCheck(opt *VerifyOption) error {
+	opt.SkipPublicKeyCheck = true
+	return nil
+}
+
+// ateLoop is the parameter of the ate pairing of BLS12_377, the seed of the curve
+const ateLoop = 9586122913090633729
+
+// bTwist is the coefficient b of the twist of BLS12_377, y**2 = x**3 + b, on which G2 is
+const bTwist = "155198655607781456406391640216936120121836107652948796323930557600032281009004493664981332883744016074664192874906"
+
+// Verify verifies the BLS signature sig of the message point msg (H(m), see the package documentation)
+// by pubKey: e(sig, g2) == e(msg, pubKey).
+//
+// sig and msg are constrained to be on the curve, and so is the public key, unless SkipPublicKeyCheck is set
+func Verify(api frontend.API, sig Signature, msg sw.G1Affine, pubKey PublicKey, opts ...func(opt *VerifyOption) error) error {
+	var opt VerifyOption
+	for _, o := range opts {
+		if err := o(&opt); err != nil {
+			return err
+		}
+	}
+	pairingInfo := pairingContext(api)
+
+	assertG1IsOnCurve(api, sig.S)
+	assertG1IsOnCurve(api, msg)
+	if !opt.SkipPublicKeyCheck {
+		assertG2IsOnCurve(api, pubKey.A, pairingInfo)
+	}
+
+	// e(sig, -g2) * e(msg, pk) == 1, with a single final exponentiation
+	var eSig, eMsg fields.E12
+	sw.MillerLoop(api, sig.S, g2Neg(api), &eSig, pairingInfo)
+	sw.MillerLoop(api, msg, pubKey.A, &eMsg, pairingInfo)
+
+	var preFinalExpo, res, one fields.E12
+	preFinalExpo.Mul(api, eSig, eMsg, pairingInfo.Extension)
+	res.FinalExponentiation(api, preFinalExpo, pairingInfo.AteLoop, pairingInfo.Extension)
+	one.SetOne(api)
+	res.MustBeEqual(api, one)
+
+	return nil
+}
+
+// VerifyAggregate verifies sig, the aggregation (sum) of the BLS signatures of the message point msg by
+// each of pubKeys, against the aggregation of the keys: it is Verify with the sum of pubKeys, which
+// costs one addition in G2 per key on top of a single verification.
+//
+// The keys are added with the incomplete affine formulas: they must be distinct. As for any aggregation
+// of BLS signatures of the same message, the keys must come with a proof of possession of their secret
+// key, else a rogue key chosen from the others forges the aggregated signature.
+func VerifyAggregate(api frontend.API, sig Signature, msg sw.G1Affine, pubKeys []PublicKey, opts ...func(opt *VerifyOption) error) error {
+	if len(pubKeys) == 0 {
+		return errors.New("no public key to aggregate")
+	}
+	var opt VerifyOption
+	for _, o := range opts {
+		if err := o(&opt); err != nil {
+			return err
+		}
+	}
+	pairingInfo := pairingContext(api)
+
+	aggregated := pubKeys[0]
+	for i := range pubKeys {
+		if !opt.SkipPublicKeyCheck {
+			assertG2IsOnCurve(api, pubKeys[i].A, pairingInfo)
+		}
+		if i > 0 {
+			aggregated.A.AddAssign(api, &pubKeys[i].A, pairingInfo.Extension)
+		}
+	}
+
+	// the keys are checked, not their sum
+	return Verify(api, sig, msg, aggregated, SkipPublicKeyCheck)
+}
+
+// Assign a value to self (witness assignment)
+func (pk *PublicKey) Assign(p *bls12377.G2Affine) {
+	pk.A.Assign(p)
+}
+
+// Assign a value to self (witness assignment)
+func (sig *Signature) Assign(p *bls12377.G1Affine) {
+	sig.S.Assign(p)
+}
+
+// AssignAggregate assigns the aggregation (sum) of sigs to self, see VerifyAggregate
+func (sig *Signature) AssignAggregate(sigs []bls12377.G1Affine) {
+	var sum bls12377.G1Jac
+	for i := range sigs {
+		sum.AddMixed(&sigs[i])
+	}
+	var p bls12377.G1Affine
+	p.FromJacobian(&sum)
+	sig.S.Assign(&p)
+}
+
+// pairingContext returns the parameters of the pairing of BLS12_377
+func pairingContext(api frontend.API) sw.PairingContext {
+	pairingInfo := sw.PairingContext{AteLoop: ateLoop, Extension: fields.GetBLS377ExtensionFp12(api)}
+	pairingInfo.BTwistCoeff.A0 = api.Constant(0)
+	pairingInfo.BTwistCoeff.A1 = api.Constant(bTwist)
+	return pairingInfo
+}
+
+// g2Neg returns the opposite of the generator of G2, as constants
+func g2Neg(api frontend.API) sw.G2Affine {
+	_, _, _, g2 := bls12377.Generators()
+	g2.Neg(&g2)
+	var res sw.G2Affine
+	res.X.A0 = api.Constant(fpToBigInt(&g2.X.A0))
+	res.X.A1 = api.Constant(fpToBigInt(&g2.X.A1))
+	res.Y.A0 = api.Constant(fpToBigInt(&g2.Y.A0))
+	res.Y.A1 = api.Constant(fpToBigInt(&g2.Y.A1))
+	return res
+}
+
+func fpToBigInt(a *fp.Element) *big.Int {
+	var res big.Int
+	a.ToBigIntRegular(&res)
+	return &res
+}
+
+// assertG1IsOnCurve checks that p is on BLS12_377, y**2 = x**3 + 1
+func assertG1IsOnCurve(api frontend.API, p sw.G1Affine) {
+	api.AssertIsEqual(api.Mul(p.Y, p.Y), api.Add(api.Mul(p.X, p.X, p.X), 1))
+}
+
+// assertG2IsOnCurve checks that p is on the twist of BLS12_377, y**2 = x**3 + b
+func assertG2IsOnCurve(api frontend.API, p sw.G2Affine, pairingInfo sw.PairingContext) {
+	var left, right fields.E2
+	left.Square(api, p.Y, pairingInfo.Extension)
+	right.Square(api, p.X, pairingInfo.Extension).
+		Mul(api, right, p.X, pairingInfo.Extension).
+		Add(api, right, pairingInfo.BTwistCoeff)
+	left.MustBeEqual(api, right)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bls
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/sw"
+	"github.com/consensys/gnark/test"
+)
+
+var dst = []byte("BLS_SIG_BLS12377G1_XMD:SHA-256_SVDW_RO_NUL_")
+
+// sign returns a new public key, and its signature of msg with the message point
+func sign(t *testing.T, msg []byte) (pk bls12377.G2Affine, hm, sig bls12377.G1Affine) {
+	var sk fr.Element
+	if _, err := sk.SetRandom(); err != nil {
+		t.Fatal(err)
+	}
+	var s big.Int
+	sk.ToBigIntRegular(&s)
+
+	hm, err := bls12377.HashToCurveG1Svdw(msg, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, g2 := bls12377.Generators()
+	pk.ScalarMultiplication(&g2, &s)
+	sig.ScalarMultiplication(&hm, &s)
+	return
+}
+
+type verifyCircuit struct {
+	PublicKey PublicKey   `gnark:",public"`
+	Message   sw.G1Affine `gnark:",public"`
+	Signature Signature
+}
+
+func (circuit *verifyCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	return Verify(api, circuit.Signature, circuit.Message, circuit.PublicKey)
+}
+
+func TestVerify(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	msg := []byte("the message")
+	pk, hm, sig := sign(t, msg)
+
+	// the pairing equation holds natively
+	_, _, _, g2 := bls12377.Generators()
+	left, err := bls12377.Pair([]bls12377.G1Affine{sig}, []bls12377.G2Affine{g2})
+	assert.NoError(err)
+	right, err := bls12377.Pair([]bls12377.G1Affine{hm}, []bls12377.G2Affine{pk})
+	assert.NoError(err)
+	assert.True(left.Equal(&right))
+
+	newWitness := func(hm, sig *bls12377.G1Affine) *verifyCircuit {
+		var witness verifyCircuit
+		witness.PublicKey.Assign(&pk)
+		witness.Message.Assign(hm)
+		witness.Signature.Assign(sig)
+		return &witness
+	}
+	opts := []func(opt *test.TestingOption) error{test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16)}
+	assert.SolvingSucceeded(&verifyCircuit{}, newWitness(&hm, &sig), opts...)
+
+	// the signature of another message
+	hm2, err := bls12377.HashToCurveG1Svdw([]byte("another message"), dst)
+	assert.NoError(err)
+	assert.SolvingFailed(&verifyCircuit{}, newWitness(&hm2, &sig), opts...)
+
+	// a corrupted signature, on the curve
+	var corrupted bls12377.G1Affine
+	corrupted.Add(&sig, &hm)
+	assert.SolvingFailed(&verifyCircuit{}, newWitness(&hm, &corrupted), opts...)
+
+	// a signature off the curve
+	corrupted = sig
+	corrupted.Y.SetOne()
+	assert.SolvingFailed(&verifyCircuit{}, newWitness(&hm, &corrupted), opts...)
+
+	ccs, err := frontend.Compile(ecc.BW6_761, backend.GROTH16, &verifyCircuit{})
+	assert.NoError(err)
+	t.Logf("Verify: %d constraints", ccs.GetNbConstraints())
+}
+
+type aggregateCircuit struct {
+	PublicKeys []PublicKey `gnark:",public"`
+	Message    sw.G1Affine `gnark:",public"`
+	Signature  Signature
+}
+
+func (circuit *aggregateCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	return VerifyAggregate(api, circuit.Signature, circuit.Message, circuit.PublicKeys)
+}
+
+func TestVerifyAggregate(t *testing.T) {
+	assert := test.NewAssert(t)
+	const n = 4
+
+	msg := []byte("the message")
+	circuit := aggregateCircuit{PublicKeys: make([]PublicKey, n)}
+	pks := make([]bls12377.G2Affine, n)
+	sigs := make([]bls12377.G1Affine, n)
+	var hm bls12377.G1Affine
+	for i := 0; i < n; i++ {
+		pks[i], hm, sigs[i] = sign(t, msg)
+	}
+	newWitness := func(sigs []bls12377.G1Affine) *aggregateCircuit {
+		witness := aggregateCircuit{PublicKeys: make([]PublicKey, n)}
+		for i := range pks {
+			witness.PublicKeys[i].Assign(&pks[i])
+		}
+		witness.Message.Assign(&hm)
+		witness.Signature.AssignAggregate(sigs)
+		return &witness
+	}
+	opts := []func(opt *test.TestingOption) error{test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16)}
+	assert.SolvingSucceeded(&circuit, newWitness(sigs), opts...)
+
+	// a signature is missing
+	assert.SolvingFailed(&circuit, newWitness(sigs[1:]), opts...)
+
+	ccs, err := frontend.Compile(ecc.BW6_761, backend.GROTH16, &circuit)
+	assert.NoError(err)
+	t.Logf("VerifyAggregate of %d signatures: %d constraints", n, ccs.GetNbConstraints())
+}
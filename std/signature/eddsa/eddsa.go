@@ -40,9 +40,34 @@ type Signature struct {
 	S frontend.Variable
 }
 
+// VerifyOption configures Verify
+type VerifyOption struct {
+	SkipPublicKeyCheck bool
+}
+
+// SkipPublicKeyCheck disables the check that the public key is on the curve,
+// for keys which are validated out of the circuit
+func SkipPublicKeyCheck(opt *VerifyOption) error {
+	opt.SkipPublicKeyCheck = true
+	return nil
+}
+
 // Verify verifies an eddsa signature
 // cf https://en.wikipedia.org/wiki/EdDSA
-func Verify(api frontend.API, sig Signature, msg frontend.Variable, pubKey PublicKey) error {
+//
+// The public key is constrained to be on the curve, unless SkipPublicKeyCheck is set
+func Verify(api frontend.API, sig Signature, msg frontend.Variable, pubKey PublicKey, opts ...func(opt *VerifyOption) error) error {
+
+	var opt VerifyOption
+	for _, o := range opts {
+		if err := o(&opt); err != nil {
+			return err
+		}
+	}
+
+	if !opt.SkipPublicKeyCheck {
+		twistededwards.AssertIsOnCurve(api, pubKey.A, pubKey.Curve)
+	}
 
 	// compute H(R, A, M), all parameters in data are in Montgomery form
 	data := []frontend.Variable{
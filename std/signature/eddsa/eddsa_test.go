@@ -30,6 +30,8 @@ import (
 	eddsabls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/twistededwards/eddsa"
 	edwardsbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
 	eddsabn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	edwardsbw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/twistededwards"
+	eddsabw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/twistededwards/eddsa"
 	edwardsbw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/twistededwards"
 	eddsabw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/twistededwards/eddsa"
 	"github.com/consensys/gnark-crypto/hash"
@@ -53,6 +55,7 @@ func parseSignature(id ecc.ID, buf []byte) ([]byte, []byte, []byte) {
 	var pointbls12377 edwardsbls12377.PointAffine
 	var pointbw6761 edwardsbw6761.PointAffine
 	var pointbls24315 edwardsbls24315.PointAffine
+	var pointbw6633 edwardsbw6633.PointAffine
 
 	switch id {
 	case ecc.BN254:
@@ -80,6 +83,11 @@ func parseSignature(id ecc.ID, buf []byte) ([]byte, []byte, []byte) {
 		a, b := parsePoint(id, buf)
 		s := buf[32:]
 		return a[:], b[:], s
+	case ecc.BW6_633:
+		pointbw6633.SetBytes(buf[:40])
+		a, b := parsePoint(id, buf)
+		s := buf[40:]
+		return a[:], b[:], s
 	default:
 		return buf, buf, buf
 	}
@@ -91,6 +99,7 @@ func parsePoint(id ecc.ID, buf []byte) ([]byte, []byte) {
 	var pointbls12377 edwardsbls12377.PointAffine
 	var pointbw6761 edwardsbw6761.PointAffine
 	var pointbls24315 edwardsbls24315.PointAffine
+	var pointbw6633 edwardsbw6633.PointAffine
 	switch id {
 	case ecc.BN254:
 		pointbn254.SetBytes(buf[:32])
@@ -117,6 +126,11 @@ func parsePoint(id ecc.ID, buf []byte) ([]byte, []byte) {
 		a := pointbls24315.X.Bytes()
 		b := pointbls24315.Y.Bytes()
 		return a[:], b[:]
+	case ecc.BW6_633:
+		pointbw6633.SetBytes(buf[:40])
+		a := pointbw6633.X.Bytes()
+		b := pointbw6633.Y.Bytes()
+		return a[:], b[:]
 	default:
 		return buf, buf
 	}
@@ -150,6 +164,7 @@ func TestEddsa(t *testing.T) {
 	signature.Register(signature.EDDSA_BLS12_377, eddsabls12377.GenerateKeyInterfaces)
 	signature.Register(signature.EDDSA_BW6_761, eddsabw6761.GenerateKeyInterfaces)
 	signature.Register(signature.EDDSA_BLS24_315, eddsabls24315.GenerateKeyInterfaces)
+	signature.Register(signature.EDDSA_BW6_633, eddsabw6633.GenerateKeyInterfaces)
 
 	confs := map[ecc.ID]confSig{
 		ecc.BN254:     {hash.MIMC_BN254, signature.EDDSA_BN254},
@@ -157,6 +172,7 @@ func TestEddsa(t *testing.T) {
 		ecc.BLS12_377: {hash.MIMC_BLS12_377, signature.EDDSA_BLS12_377},
 		ecc.BW6_761:   {hash.MIMC_BW6_761, signature.EDDSA_BW6_761},
 		ecc.BLS24_315: {hash.MIMC_BLS24_315, signature.EDDSA_BLS24_315},
+		ecc.BW6_633:   {hash.MIMC_BW6_633, signature.EDDSA_BW6_633},
 	}
 	for id, conf := range confs {
 
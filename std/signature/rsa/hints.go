@@ -0,0 +1,117 @@
+/*
+Copyright © 2021 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rsa
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+)
+
+// Hints returns the hint functions used by VerifyPKCS1v15, they must be given to the prover
+// (see backend.WithAnnotatedHints)
+func Hints() []hint.AnnotatedFunction {
+	return []hint.AnnotatedFunction{mulModHint{}}
+}
+
+// mulModHint computes, for a, b, n of nbLimbs limbs each, the limbs of q and r such that
+// a*b = q*n + r with 0 <= r < n, followed by the 2*nbLimbs-2 carries of a*b-q*n-r (see mulMod).
+//
+// Its ID doesn't depend on nbLimbs, which is deduced from the inputs when solving: a single
+// instance solves the products of any size.
+type mulModHint struct {
+	nbLimbs int
+}
+
+// mulModHintID is derived from the hint name, as hint.UUID does for hint functions
+var mulModHintID = func() hint.ID {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("rsa.mulMod"))
+	return hint.ID(h.Sum64())
+}()
+
+func (h mulModHint) UUID() hint.ID  { return mulModHintID }
+func (h mulModHint) String() string { return "rsa.mulMod" }
+func (h mulModHint) NbInputs() int {
+	if h.nbLimbs == 0 {
+		return -1
+	}
+	return 3 * h.nbLimbs
+}
+func (h mulModHint) NbOutputs() int {
+	return 4*h.nbLimbs - 2
+}
+
+func (h mulModHint) Call(_ ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	if len(inputs)%3 != 0 || len(inputs) == 0 {
+		return errors.New("rsa.mulMod expects the limbs of a, b and n")
+	}
+	nbLimbs := len(inputs) / 3
+	if len(outputs) != 4*nbLimbs-2 {
+		return fmt.Errorf("rsa.mulMod: got %d outputs, expected %d", len(outputs), 4*nbLimbs-2)
+	}
+	a, b, n := inputs[:nbLimbs], inputs[nbLimbs:2*nbLimbs], inputs[2*nbLimbs:]
+
+	nInt := fromLimbs(n)
+	if nInt.Sign() == 0 {
+		return errors.New("rsa.mulMod: modulus is zero")
+	}
+	var q, r big.Int
+	q.Mul(fromLimbs(a), fromLimbs(b))
+	q.QuoRem(&q, nInt, &r)
+	if q.BitLen() > nbLimbs*LimbBits {
+		return errors.New("rsa.mulMod: quotient overflows, operands must be smaller than the modulus")
+	}
+	qLimbs, rLimbs := toLimbs(&q, nbLimbs), toLimbs(&r, nbLimbs)
+
+	// c[k] = a[k]*b[0]+...+a[0]*b[k] - (q[k]*n[0]+...+q[0]*n[k]) - r[k]
+	// carry[k] = (c[k] + carry[k-1]) / 2**LimbBits
+	var offset, carry, c, t big.Int
+	offset.Lsh(big.NewInt(1), uint(carryBits(nbLimbs)-1))
+	for k := 0; k < 2*nbLimbs-1; k++ {
+		c.Set(&carry)
+		for i := 0; i <= k; i++ {
+			if i >= nbLimbs || k-i >= nbLimbs {
+				continue
+			}
+			c.Add(&c, t.Mul(a[i], b[k-i]))
+			c.Sub(&c, t.Mul(qLimbs[i], n[k-i]))
+		}
+		if k < nbLimbs {
+			c.Sub(&c, rLimbs[k])
+		}
+		carry.Rsh(&c, LimbBits)
+
+		if k == 2*nbLimbs-2 {
+			if c.Sign() != 0 {
+				return errors.New("rsa.mulMod: inconsistent carries")
+			}
+			break
+		}
+		outputs[2*nbLimbs+k].Add(&carry, &offset)
+	}
+
+	for i := 0; i < nbLimbs; i++ {
+		outputs[i].Set(qLimbs[i])
+		outputs[nbLimbs+i].Set(rLimbs[i])
+	}
+	return nil
+}
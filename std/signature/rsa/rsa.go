@@ -0,0 +1,236 @@
+/*
+Copyright © 2021 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rsa provides a ZKP-circuit function to verify a RSA PKCS #1 v1.5 signature.
+//
+// Integers larger than the scalar field (modulus, signature) are represented as slices of
+// LimbBits bits limbs, in little endian. Modular multiplications are checked with the help of a
+// hint (see Hints) which must be given to the prover:
+//
+//	proof, err := groth16.Prove(ccs, pk, witness, backend.WithAnnotatedHints(rsa.Hints()...))
+package rsa
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// LimbBits is the number of bits of a limb
+const LimbBits = 64
+
+// supportedExponent is the only public exponent VerifyPKCS1v15 accepts
+const supportedExponent = 65537
+
+// sha256Prefix is the DER encoding of the DigestInfo of a SHA-256 digest, see RFC 8017 section 9.2
+var sha256Prefix = []byte{0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20}
+
+// PublicKey stores a RSA public key (to be used in gnark circuit)
+type PublicKey struct {
+	N []frontend.Variable // modulus, in little endian limbs
+	E int                 // public exponent, fixed when the circuit is compiled
+}
+
+// NbLimbs returns the number of limbs needed to represent a nbBits bits integer
+func NbLimbs(nbBits int) int {
+	return (nbBits + LimbBits - 1) / LimbBits
+}
+
+// Limbs returns the nbLimbs limbs of v, to be used in a witness assignment.
+// It panics if v is negative or doesn't fit in nbLimbs limbs
+func Limbs(v *big.Int, nbLimbs int) []frontend.Variable {
+	if v.Sign() < 0 || v.BitLen() > nbLimbs*LimbBits {
+		panic(fmt.Sprintf("rsa: value doesn't fit in %d limbs", nbLimbs))
+	}
+	res := make([]frontend.Variable, nbLimbs)
+	for i, l := range toLimbs(v, nbLimbs) {
+		res[i].Assign(l)
+	}
+	return res
+}
+
+// VerifyPKCS1v15 verifies a RSASSA-PKCS1-v1_5 signature of a SHA-256 digest
+// cf https://datatracker.ietf.org/doc/html/rfc8017#section-8.2
+//
+// signature has as many limbs as the modulus, digest holds the 32 bytes of the SHA-256 digest.
+// The signature is not required to be reduced modulo the public key modulus.
+// An error is returned if the public exponent is not 65537 or if the sizes of the inputs are invalid.
+func VerifyPKCS1v15(api frontend.API, pubKey PublicKey, signature, digest []frontend.Variable) error {
+	if pubKey.E != supportedExponent {
+		return fmt.Errorf("rsa: unsupported public exponent %d", pubKey.E)
+	}
+	nbLimbs := len(pubKey.N)
+	if nbLimbs*LimbBits/8 < len(sha256Prefix)+32+11 {
+		return errors.New("rsa: modulus too small")
+	}
+	if len(signature) != nbLimbs {
+		return fmt.Errorf("rsa: signature has %d limbs, modulus has %d", len(signature), nbLimbs)
+	}
+	if len(digest) != 32 {
+		return fmt.Errorf("rsa: digest has %d bytes, expected 32", len(digest))
+	}
+
+	// the arithmetic is sound only if all the limbs are range checked
+	for i := 0; i < nbLimbs; i++ {
+		api.ToBinary(pubKey.N[i], LimbBits)
+		api.ToBinary(signature[i], LimbBits)
+	}
+	for i := 0; i < len(digest); i++ {
+		api.ToBinary(digest[i], 8)
+	}
+
+	// m = signature**e mod N, by square and multiply
+	m := signature
+	for i := bits.Len(supportedExponent) - 2; i >= 0; i-- {
+		m = mulMod(api, m, m, pubKey.N)
+		if (supportedExponent>>i)&1 == 1 {
+			m = mulMod(api, m, signature, pubKey.N)
+		}
+	}
+
+	// m must be the encoded message 0x00 || 0x01 || 0xff...0xff || 0x00 || sha256Prefix || digest
+	// encoded[i] is the i-th least significant byte
+	nbBytes := nbLimbs * LimbBits / 8
+	encoded := make([]interface{}, nbBytes)
+	for i := 0; i < 32; i++ {
+		encoded[i] = digest[31-i]
+	}
+	for i := 0; i < len(sha256Prefix); i++ {
+		encoded[32+i] = int(sha256Prefix[len(sha256Prefix)-1-i])
+	}
+	encoded[32+len(sha256Prefix)] = 0
+	for i := 33 + len(sha256Prefix); i < nbBytes-2; i++ {
+		encoded[i] = 0xff
+	}
+	encoded[nbBytes-2] = 1
+	encoded[nbBytes-1] = 0
+
+	for i := 0; i < nbLimbs; i++ {
+		limb := api.Constant(0)
+		for j := 0; j < LimbBits/8; j++ {
+			limb = api.Add(limb, api.Mul(encoded[i*LimbBits/8+j], new(big.Int).Lsh(big.NewInt(1), uint(8*j))))
+		}
+		api.AssertIsEqual(m[i], limb)
+	}
+
+	return nil
+}
+
+// mulMod returns r such that a*b == r mod n. The limbs of r are range checked, but r is
+// not necessarily reduced modulo n.
+//
+// The hint computes q, r such that a*b = q*n + r, and the carries c needed to check this identity
+// limb by limb. Seeing the limbs of an integer as the coefficients of a polynomial p(x), such that
+// the integer is p(2**LimbBits), we check
+//
+//	a(x)b(x) = q(x)n(x) + r(x) + (2**LimbBits - x) c(x)
+//
+// at 2*len(n)-1 points x. As all coefficients are small compared to the scalar field, this is an
+// identity over the integers, which gives a*b = q*n + r at x = 2**LimbBits.
+func mulMod(api frontend.API, a, b, n []frontend.Variable) []frontend.Variable {
+	nbLimbs := len(n)
+	inputs := make([]interface{}, 0, 3*nbLimbs)
+	for _, l := range [][]frontend.Variable{a, b, n} {
+		for i := 0; i < nbLimbs; i++ {
+			inputs = append(inputs, l[i])
+		}
+	}
+	res := api.NewAnnotatedHint(mulModHint{nbLimbs: nbLimbs}, inputs...)
+	q, r, carries := res[:nbLimbs], res[nbLimbs:2*nbLimbs], res[2*nbLimbs:]
+
+	// the carries are shifted by 2**(nbCarryBits-1) to be positive
+	var offset big.Int
+	nbCarryBits := carryBits(nbLimbs)
+	offset.Lsh(big.NewInt(1), uint(nbCarryBits-1))
+
+	var x, xPow, c, base big.Int
+	base.Lsh(big.NewInt(1), LimbBits)
+	for ix := 0; ix < 2*nbLimbs-1; ix++ {
+		x.SetInt64(int64(ix))
+
+		// (2**LimbBits - x) c(x), with c[k] = carries[k] - offset
+		var carrySum, offsetSum big.Int
+		carry := api.Constant(0)
+		xPow.SetUint64(1)
+		for k := 0; k < len(carries); k++ {
+			c.Sub(&base, &x).Mul(&c, &xPow)
+			carry = api.Add(carry, api.Mul(carries[k], new(big.Int).Set(&c)))
+			carrySum.Add(&carrySum, &c)
+			xPow.Mul(&xPow, &x)
+		}
+		offsetSum.Mul(&carrySum, &offset)
+
+		lhs := api.Add(api.Mul(eval(api, a, &x), eval(api, b, &x)), &offsetSum)
+		rhs := api.Add(api.Mul(eval(api, q, &x), eval(api, n, &x)), eval(api, r, &x), carry)
+		api.AssertIsEqual(lhs, rhs)
+	}
+
+	// the range checks come last: the solver evaluates the hint when it first meets one of its
+	// outputs in a constraint, and the bits of an output can't be computed before
+	for i := 0; i < nbLimbs; i++ {
+		api.ToBinary(q[i], LimbBits)
+		api.ToBinary(r[i], LimbBits)
+	}
+	for i := 0; i < len(carries); i++ {
+		api.ToBinary(carries[i], nbCarryBits)
+	}
+
+	return r
+}
+
+// eval returns Σ p[i] x**i
+func eval(api frontend.API, p []frontend.Variable, x *big.Int) frontend.Variable {
+	res := api.Constant(0)
+	var xPow big.Int
+	xPow.SetUint64(1)
+	for i := 0; i < len(p); i++ {
+		res = api.Add(res, api.Mul(p[i], new(big.Int).Set(&xPow)))
+		xPow.Mul(&xPow, x)
+	}
+	return res
+}
+
+// carryBits returns the number of bits of the (shifted) carries of a product of nbLimbs limbs integers.
+// The coefficients of a(X)b(X)-q(X)n(X)-r(X) are bounded by nbLimbs*2**(2*LimbBits), so the carries
+// are bounded by nbLimbs*2**(LimbBits+1) in absolute value
+func carryBits(nbLimbs int) int {
+	return LimbBits + bits.Len(uint(nbLimbs)) + 2
+}
+
+// toLimbs returns the nbLimbs least significant limbs of v >= 0
+func toLimbs(v *big.Int, nbLimbs int) []*big.Int {
+	res := make([]*big.Int, nbLimbs)
+	var mask big.Int
+	mask.Lsh(big.NewInt(1), LimbBits).Sub(&mask, big.NewInt(1))
+	t := new(big.Int).Set(v)
+	for i := 0; i < nbLimbs; i++ {
+		res[i] = new(big.Int).And(t, &mask)
+		t.Rsh(t, LimbBits)
+	}
+	return res
+}
+
+// fromLimbs returns Σ limbs[i] 2**(i*LimbBits)
+func fromLimbs(limbs []*big.Int) *big.Int {
+	res := new(big.Int)
+	for i := len(limbs) - 1; i >= 0; i-- {
+		res.Lsh(res, LimbBits).Add(res, limbs[i])
+	}
+	return res
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © 2021 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+const nbBits = 2048
+
+type rsaCircuit struct {
+	PublicKey PublicKey           `gnark:",public"`
+	Signature []frontend.Variable `gnark:",secret"`
+	Digest    []frontend.Variable `gnark:",public"`
+}
+
+func newCircuit(e int) *rsaCircuit {
+	return &rsaCircuit{
+		PublicKey: PublicKey{N: make([]frontend.Variable, NbLimbs(nbBits)), E: e},
+		Signature: make([]frontend.Variable, NbLimbs(nbBits)),
+		Digest:    make([]frontend.Variable, 32),
+	}
+}
+
+func (circuit *rsaCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	return VerifyPKCS1v15(api, circuit.PublicKey, circuit.Signature, circuit.Digest)
+}
+
+func newWitness(pub *rsa.PublicKey, signature []byte, digest []byte) *rsaCircuit {
+	var witness rsaCircuit
+	witness.PublicKey.N = Limbs(pub.N, NbLimbs(nbBits))
+	witness.Signature = Limbs(new(big.Int).SetBytes(signature), NbLimbs(nbBits))
+	witness.Digest = make([]frontend.Variable, len(digest))
+	for i := 0; i < len(digest); i++ {
+		witness.Digest[i].Assign(int(digest[i]))
+	}
+	return &witness
+}
+
+func TestVerifyPKCS1v15(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	privKey, err := rsa.GenerateKey(rand.Reader, nbBits)
+	assert.NoError(err)
+	digest := sha256.Sum256([]byte("notarized document"))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privKey, crypto.SHA256, digest[:])
+	assert.NoError(err)
+	assert.NoError(rsa.VerifyPKCS1v15(&privKey.PublicKey, crypto.SHA256, digest[:], signature))
+
+	opts := []func(opt *test.TestingOption) error{
+		test.WithCurves(ecc.BN254),
+		test.WithProverOpts(backend.WithAnnotatedHints(Hints()...)),
+	}
+
+	assert.SolvingSucceeded(newCircuit(privKey.E), newWitness(&privKey.PublicKey, signature, digest[:]), opts...)
+
+	// flipped bit in the signature
+	signature[len(signature)-1] ^= 1
+	assert.SolvingFailed(newCircuit(privKey.E), newWitness(&privKey.PublicKey, signature, digest[:]), opts...)
+	signature[len(signature)-1] ^= 1
+
+	// other digest
+	otherDigest := sha256.Sum256([]byte("forged document"))
+	assert.SolvingFailed(newCircuit(privKey.E), newWitness(&privKey.PublicKey, signature, otherDigest[:]), opts...)
+}
+
+func TestVerifyPKCS1v15UnsupportedExponent(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	_, err := frontend.Compile(ecc.BN254, backend.GROTH16, newCircuit(3))
+	assert.Error(err)
+}
+
+func TestVerifyPKCS1v15NbConstraints(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// 17 modular multiplications, mostly range checks of the quotients, remainders and carries
+	maxNbConstraints := map[backend.ID]int{
+		backend.GROTH16: 156000,
+		backend.PLONK:   473000,
+	}
+	for _, b := range backend.Implemented() {
+		ccs, err := frontend.Compile(ecc.BN254, b, newCircuit(65537))
+		assert.NoError(err)
+		t.Logf("%s: %d constraints", b, ccs.GetNbConstraints())
+		assert.LessOrEqual(ccs.GetNbConstraints(), maxNbConstraints[b])
+	}
+}
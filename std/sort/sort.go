@@ -0,0 +1,160 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sort provides ZKP-circuit functions to sort an array of variables.
+//
+// The sorted array is computed by a hint (the solver sorts the values natively), and the circuit
+// checks that it is ordered and that it is a permutation of the input. The hints are registered
+// (see hint.Register) when the package is imported.
+package sort
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	gosort "sort"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+)
+
+// SortHint sorts its inputs in increasing order, as integers in [0, r), r being the size of the scalar field
+var SortHint = hint.NewFixedHintNamed("gnark/std/sort", sortValues, -1, -1)
+
+// IndexesHint returns the indexes of its inputs sorted in increasing order, the indexes of equal values
+// being in increasing order (stable sort)
+var IndexesHint = hint.NewFixedHintNamed("gnark/std/sort-indexes", sortIndexes, -1, -1)
+
+func init() {
+	hint.Register(SortHint)
+	hint.Register(IndexesHint)
+}
+
+// Sort returns a copy of values sorted in increasing order. The values must fit on nbBits bits (nbBits must
+// be in [1, fr.Bits - 2]), the constraints are not satisfied otherwise; duplicated values are allowed.
+//
+// The adjacent sorted values are ordered by AssertIsLessOrEqualBounded, about 3*nbBits constraints per value,
+// and the sorted values are a permutation of values by AssertIsPermutation, with opts.
+func Sort(api frontend.API, values []frontend.Variable, nbBits int, opts ...func(opt *frontend.PermutationOption) error) []frontend.Variable {
+	return sortWith(api, SortHint, values, nbBits, opts...)
+}
+
+// SortStable returns a copy of values sorted in increasing order, and the indexes of the sorted values in
+// values: sorted[i] == values[indexes[i]]. The indexes of equal values are in increasing order.
+//
+// As with Sort, the values must fit on nbBits bits. The pairs (sorted[i], indexes[i]) are packed in keys
+// sorted[i] * 2**k + indexes[i], k being the bit length of len(values) - 1 (at least 1): the keys are
+// strictly increasing, by AssertIsLessOrEqualBounded on nbBits + k bits, and a permutation of the keys of
+// the input, by AssertIsPermutation with opts. The values, the sorted values and the indexes are range
+// checked (see frontend.API.RangeCheck) for the packing to be unique.
+func SortStable(api frontend.API, values []frontend.Variable, nbBits int, opts ...func(opt *frontend.PermutationOption) error) (sorted, indexes []frontend.Variable) {
+	return sortStableWith(api, SortHint, IndexesHint, values, nbBits, opts...)
+}
+
+func sortWith(api frontend.API, sortHint hint.AnnotatedFunction, values []frontend.Variable, nbBits int, opts ...func(opt *frontend.PermutationOption) error) []frontend.Variable {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := api.NewAnnotatedHint(sortHint, toInterfaces(values)...)
+	if len(sorted) == 1 {
+		api.RangeCheck(sorted[0], nbBits)
+	}
+	for i := 1; i < len(sorted); i++ {
+		api.AssertIsLessOrEqualBounded(sorted[i-1], sorted[i], nbBits)
+	}
+	api.AssertIsPermutation(values, sorted, opts...)
+	return sorted
+}
+
+func sortStableWith(api frontend.API, sortHint, indexesHint hint.AnnotatedFunction, values []frontend.Variable, nbBits int, opts ...func(opt *frontend.PermutationOption) error) (sorted, indexes []frontend.Variable) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	k := bits.Len(uint(len(values) - 1))
+	if k == 0 {
+		k = 1
+	}
+	var shift big.Int
+	shift.Lsh(big.NewInt(1), uint(k))
+
+	sorted = api.NewAnnotatedHint(sortHint, toInterfaces(values)...)
+	indexes = api.NewAnnotatedHint(indexesHint, toInterfaces(values)...)
+
+	// key = value * 2**k + index
+	keys, sortedKeys := make([]frontend.Variable, len(values)), make([]frontend.Variable, len(values))
+	for i := range values {
+		api.RangeCheck(values[i], nbBits)
+		api.RangeCheck(sorted[i], nbBits)
+		api.RangeCheck(indexes[i], k)
+		keys[i] = api.Add(api.Mul(values[i], &shift), i)
+		sortedKeys[i] = api.Add(api.Mul(sorted[i], &shift), indexes[i])
+	}
+	for i := 1; i < len(sortedKeys); i++ {
+		api.AssertIsLessOrEqualBounded(api.Add(sortedKeys[i-1], 1), sortedKeys[i], nbBits+k)
+	}
+	api.AssertIsPermutation(keys, sortedKeys, opts...)
+	return sorted, indexes
+}
+
+func toInterfaces(values []frontend.Variable) []interface{} {
+	res := make([]interface{}, len(values))
+	for i := range values {
+		res[i] = values[i]
+	}
+	return res
+}
+
+func sortValues(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	indexes, err := sortedIndexes(curveID, inputs, outputs)
+	if err != nil {
+		return err
+	}
+	q := curveID.Info().Fr.Modulus()
+	for i, j := range indexes {
+		outputs[i].Mod(inputs[j], q)
+	}
+	return nil
+}
+
+func sortIndexes(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	indexes, err := sortedIndexes(curveID, inputs, outputs)
+	if err != nil {
+		return err
+	}
+	for i, j := range indexes {
+		outputs[i].SetUint64(uint64(j))
+	}
+	return nil
+}
+
+// sortedIndexes returns the indexes of the inputs, reduced modulo the scalar field, in increasing order
+func sortedIndexes(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) ([]int, error) {
+	if len(outputs) != len(inputs) {
+		return nil, fmt.Errorf("sort: got %d outputs, expected %d", len(outputs), len(inputs))
+	}
+	q := curveID.Info().Fr.Modulus()
+	values := make([]big.Int, len(inputs))
+	indexes := make([]int, len(inputs))
+	for i := range inputs {
+		values[i].Mod(inputs[i], q)
+		indexes[i] = i
+	}
+	gosort.SliceStable(indexes, func(a, b int) bool {
+		return values[indexes[a]].Cmp(&values[indexes[b]]) < 0
+	})
+	return indexes, nil
+}
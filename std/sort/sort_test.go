@@ -0,0 +1,234 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sort
+
+import (
+	"flag"
+	"math/big"
+	"math/rand"
+	"os"
+	"path/filepath"
+	gosort "sort"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+var update = flag.Bool("update", false, "update the golden file of the constraint counts")
+
+const (
+	nbValues = 64
+	nbBits   = 16
+)
+
+type sortCircuit struct {
+	Values [nbValues]frontend.Variable `gnark:",secret"`
+	Sorted [nbValues]frontend.Variable `gnark:",public"`
+
+	sortHint hint.AnnotatedFunction                        // SortHint if nil
+	opts     []func(opt *frontend.PermutationOption) error // of AssertIsPermutation
+}
+
+func (circuit *sortCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	h := circuit.sortHint
+	if h == nil {
+		h = SortHint
+	}
+	sorted := sortWith(api, h, circuit.Values[:], nbBits, circuit.opts...)
+	for i := range sorted {
+		api.AssertIsEqual(sorted[i], circuit.Sorted[i])
+	}
+	return nil
+}
+
+type sortStableCircuit struct {
+	Values  [nbValues]frontend.Variable `gnark:",secret"`
+	Sorted  [nbValues]frontend.Variable `gnark:",public"`
+	Indexes [nbValues]frontend.Variable `gnark:",public"`
+
+	indexesHint hint.AnnotatedFunction                        // IndexesHint if nil
+	opts        []func(opt *frontend.PermutationOption) error // of AssertIsPermutation
+}
+
+func (circuit *sortStableCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	h := circuit.indexesHint
+	if h == nil {
+		h = IndexesHint
+	}
+	sorted, indexes := sortStableWith(api, SortHint, h, circuit.Values[:], nbBits, circuit.opts...)
+	for i := range sorted {
+		api.AssertIsEqual(sorted[i], circuit.Sorted[i])
+		api.AssertIsEqual(indexes[i], circuit.Indexes[i])
+	}
+	return nil
+}
+
+// randomValues returns nbValues random values on nbBits bits, with duplicates, their sorted copy and
+// the indexes of the sorted values (stable sort)
+func randomValues(rng *rand.Rand) (values, sorted, indexes [nbValues]int) {
+	for i := range values {
+		values[i] = rng.Intn(1 << nbBits)
+		if i > 0 && rng.Intn(4) == 0 {
+			values[i] = values[rng.Intn(i)]
+		}
+		indexes[i] = i
+	}
+	gosort.SliceStable(indexes[:], func(a, b int) bool { return values[indexes[a]] < values[indexes[b]] })
+	for i, j := range indexes {
+		sorted[i] = values[j]
+	}
+	return
+}
+
+// network is the permutation option of the circuits of the tests, the grand product with its challenge
+// hashed in the circuit being ten times larger
+var network = []func(opt *frontend.PermutationOption) error{frontend.WithPermutationNetwork()}
+
+func toVariables(v [nbValues]int) (res [nbValues]frontend.Variable) {
+	for i := range v {
+		res[i] = frontend.Value(v[i])
+	}
+	return
+}
+
+func TestSort(t *testing.T) {
+	assert := test.NewAssert(t)
+	rng := rand.New(rand.NewSource(42)) //#nosec G404 reproducible test
+
+	for i := 0; i < 2; i++ {
+		values, sorted, _ := randomValues(rng)
+		assert.ProverSucceeded(&sortCircuit{opts: network}, &sortCircuit{Values: toVariables(values), Sorted: toVariables(sorted)},
+			test.WithCurves(ecc.BN254))
+	}
+
+	// the values of the input, not sorted
+	values, _, _ := randomValues(rng)
+	assert.ProverFailed(&sortCircuit{opts: network}, &sortCircuit{Values: toVariables(values), Sorted: toVariables(values)},
+		test.WithCurves(ecc.BN254))
+
+	// values which don't fit on nbBits bits
+	values, sorted, _ := randomValues(rng)
+	tooLarge := toVariables(values)
+	tooLarge[0] = frontend.Value(1 << nbBits)
+	sorted[nbValues-1] = 1 << nbBits
+	assert.ProverFailed(&sortCircuit{opts: network}, &sortCircuit{Values: tooLarge, Sorted: toVariables(sorted)},
+		test.WithCurves(ecc.BN254))
+}
+
+func TestSortStable(t *testing.T) {
+	assert := test.NewAssert(t)
+	rng := rand.New(rand.NewSource(42)) //#nosec G404 reproducible test
+
+	values, sorted, indexes := randomValues(rng)
+	assert.ProverSucceeded(&sortStableCircuit{opts: network}, &sortStableCircuit{Values: toVariables(values), Sorted: toVariables(sorted), Indexes: toVariables(indexes)},
+		test.WithCurves(ecc.BN254))
+
+	// equal values swapped: not stable
+	for i := 1; i < nbValues; i++ {
+		if sorted[i] == sorted[i-1] {
+			indexes[i], indexes[i-1] = indexes[i-1], indexes[i]
+			break
+		}
+	}
+	assert.ProverFailed(&sortStableCircuit{opts: network}, &sortStableCircuit{Values: toVariables(values), Sorted: toVariables(sorted), Indexes: toVariables(indexes)},
+		test.WithCurves(ecc.BN254))
+}
+
+// badSortHint sorts its inputs, then replaces the largest value by the smallest one: the result is still
+// sorted, but not a permutation of the inputs
+var badSortHint = hint.NewFixedHintNamed("test/bad-sort", func(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+	if err := sortValues(curveID, inputs, outputs); err != nil {
+		return err
+	}
+	copy(outputs[1:], outputs[:len(outputs)-1])
+	return nil
+}, -1, -1)
+
+// TestAdversarialHint checks that the constraints aren't satisfied if the hints return sorted values which
+// are not a permutation of the input, or indexes which are not a permutation
+func TestAdversarialHint(t *testing.T) {
+	assert := test.NewAssert(t)
+	rng := rand.New(rand.NewSource(42)) //#nosec G404 reproducible test
+
+	values, sorted, _ := randomValues(rng)
+	copy(sorted[1:], sorted[:nbValues-1])
+	assert.ProverFailed(&sortCircuit{sortHint: badSortHint, opts: network}, &sortCircuit{Values: toVariables(values), Sorted: toVariables(sorted)},
+		test.WithCurves(ecc.BN254), test.WithProverOpts(backend.WithAnnotatedHints(badSortHint)))
+
+	// the index of the smallest value, everywhere
+	badIndexes := hint.NewFixedHintNamed("test/bad-indexes", func(curveID ecc.ID, inputs []*big.Int, outputs []*big.Int) error {
+		if err := sortIndexes(curveID, inputs, outputs); err != nil {
+			return err
+		}
+		for i := range outputs {
+			outputs[i].Set(outputs[0])
+		}
+		return nil
+	}, -1, -1)
+
+	values, sorted, indexes := randomValues(rng)
+	for i := range indexes {
+		indexes[i] = indexes[0]
+	}
+	assert.ProverFailed(&sortStableCircuit{indexesHint: badIndexes, opts: network}, &sortStableCircuit{Values: toVariables(values), Sorted: toVariables(sorted), Indexes: toVariables(indexes)},
+		test.WithCurves(ecc.BN254), test.WithProverOpts(backend.WithAnnotatedHints(badIndexes)))
+}
+
+// TestAdversarialHintGrandProduct checks that the grand product argument, with its challenge hashed in the
+// circuit, rejects sorted values which are not a permutation of the input
+func TestAdversarialHintGrandProduct(t *testing.T) {
+	assert := test.NewAssert(t)
+	rng := rand.New(rand.NewSource(42)) //#nosec G404 reproducible test
+
+	values, sorted, _ := randomValues(rng)
+	copy(sorted[1:], sorted[:nbValues-1])
+	assert.ProverFailed(&sortCircuit{sortHint: badSortHint}, &sortCircuit{Values: toVariables(values), Sorted: toVariables(sorted)},
+		test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16), test.WithProverOpts(backend.WithAnnotatedHints(badSortHint)))
+}
+
+// TestSortConstraintCount catches the regressions of the number of constraints of the sorts with the
+// golden file (run the tests with -update to record it again)
+func TestSortConstraintCount(t *testing.T) {
+	circuits := map[string]frontend.Circuit{
+		"sort":                &sortCircuit{},
+		"sort-stable":         &sortStableCircuit{},
+		"sort-network":        &sortCircuit{opts: network},
+		"sort-stable-network": &sortStableCircuit{opts: network},
+	}
+	golden := filepath.Join("testdata", "constraints.json")
+	if *update {
+		f, err := os.Create(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = test.RecordConstraintCounts(circuits, f)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.Open(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	test.CheckConstraintCounts(t, circuits, f, 0.05)
+}
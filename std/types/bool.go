@@ -0,0 +1,107 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package types provides typed wrappers of frontend.Variable: Bool, Uint64 and UintN carry a
+// variable together with its invariant (0 or 1, less than 2**64, less than 2**n).
+//
+// The constructors (NewBool, NewUint64, NewUintN) constrain the variable, once: the boolean
+// constraints and the range checks are cached by the constraint system (see
+// frontend.API.RangeCheck), such that wrapping a variable again doesn't add constraints. The
+// methods return values which satisfy the invariant by construction, without new checks.
+//
+// The wrappers may be fields of a circuit: their variable is an input named after the field, and
+// is assigned from a Go bool or uint64 in the witness. They are constrained when Define wraps them
+// with the constructors:
+//
+//	type Circuit struct {
+//		B types.Bool
+//		U types.Uint64 `gnark:",public"`
+//	}
+//
+//	func (circuit *Circuit) Define(curveID ecc.ID, api frontend.API) error {
+//		b := types.NewBool(api, circuit.B)
+//		u := types.NewUint64(api, circuit.U)
+//		...
+//	}
+package types
+
+import (
+	"github.com/consensys/gnark/frontend"
+)
+
+// Bool is a variable constrained to be 0 or 1
+type Bool struct {
+	V frontend.Variable `gnark:",embed"`
+}
+
+// NewBool constrains v to be 0 or 1, and returns it as a Bool.
+// v is a frontend.Variable, a Bool (an input of the circuit), or a constant
+func NewBool(api frontend.API, v interface{}) Bool {
+	res := Bool{V: toVariable(api, v)}
+	api.AssertIsBoolean(res.V)
+	return res
+}
+
+// Assign sets the value of b in a witness
+func (b *Bool) Assign(value bool) {
+	b.V.Assign(value)
+}
+
+// Variable returns the variable of b
+func (b Bool) Variable() frontend.Variable {
+	return b.V
+}
+
+// Not returns !b
+func (b Bool) Not(api frontend.API) Bool {
+	return Bool{V: api.Sub(1, b.V)}
+}
+
+// And returns b && other
+func (b Bool) And(api frontend.API, other Bool) Bool {
+	return Bool{V: api.And(b.V, other.V)}
+}
+
+// Or returns b || other
+func (b Bool) Or(api frontend.API, other Bool) Bool {
+	return Bool{V: api.Or(b.V, other.V)}
+}
+
+// Xor returns b != other
+func (b Bool) Xor(api frontend.API, other Bool) Bool {
+	return Bool{V: api.Xor(b.V, other.V)}
+}
+
+// Select returns i1 if b is true, i2 otherwise
+func (b Bool) Select(api frontend.API, i1, i2 interface{}) frontend.Variable {
+	return api.Select(b.V, i1, i2)
+}
+
+// toVariable returns the variable of v, a frontend.Variable, one of the wrappers or a constant
+func toVariable(api frontend.API, v interface{}) frontend.Variable {
+	switch t := v.(type) {
+	case frontend.Variable:
+		return t
+	case Bool:
+		return t.V
+	case Uint64:
+		return t.V
+	case UintN:
+		return t.V
+	default:
+		return api.Constant(v)
+	}
+}
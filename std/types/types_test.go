@@ -0,0 +1,189 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"math"
+	"math/bits"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+type arithmeticCircuit struct {
+	A, B                Uint64
+	C                   Bool
+	Sum, Diff, Lo, Hi   Uint64 `gnark:",public"`
+	Carry, Borrow, NotC Bool   `gnark:",public"`
+}
+
+func (circuit *arithmeticCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	a, b := NewUint64(api, circuit.A), NewUint64(api, circuit.B)
+	c := NewBool(api, circuit.C)
+
+	sum, carry := a.Add(api, b)
+	diff, borrow := a.Sub(api, b)
+	lo, hi := a.Mul(api, b)
+	for _, e := range [][2]frontend.Variable{
+		{sum.Variable(), circuit.Sum.V},
+		{carry.Variable(), circuit.Carry.V},
+		{diff.Variable(), circuit.Diff.V},
+		{borrow.Variable(), circuit.Borrow.V},
+		{lo.Variable(), circuit.Lo.V},
+		{hi.Variable(), circuit.Hi.V},
+		{c.Not(api).Variable(), circuit.NotC.V},
+	} {
+		api.AssertIsEqual(e[0], e[1])
+	}
+	return nil
+}
+
+func newArithmeticWitness(a, b uint64, c bool) *arithmeticCircuit {
+	var witness arithmeticCircuit
+	witness.A.Assign(a)
+	witness.B.Assign(b)
+	witness.C.Assign(c)
+
+	sum, carry := bits.Add64(a, b, 0)
+	diff, borrow := bits.Sub64(a, b, 0)
+	hi, lo := bits.Mul64(a, b)
+	witness.Sum.Assign(sum)
+	witness.Carry.Assign(carry == 1)
+	witness.Diff.Assign(diff)
+	witness.Borrow.Assign(borrow == 1)
+	witness.Lo.Assign(lo)
+	witness.Hi.Assign(hi)
+	witness.NotC.Assign(!c)
+	return &witness
+}
+
+func TestArithmetic(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, w := range []struct {
+		a, b uint64
+		c    bool
+	}{
+		{3, 5, false},
+		{math.MaxUint64, 2, true},
+		{1 << 63, 1 << 63, false},
+	} {
+		assert.ProverSucceeded(&arithmeticCircuit{}, newArithmeticWitness(w.a, w.b, w.c), test.WithCurves(ecc.BN254))
+	}
+
+	// the inputs are range checked
+	witness := newArithmeticWitness(3, 5, false)
+	witness.A = Uint64{}
+	witness.A.V.Assign("18446744073709551619") // 2**64 + 3
+	assert.ProverFailed(&arithmeticCircuit{}, witness, test.WithCurves(ecc.BN254))
+
+	witness = newArithmeticWitness(3, 5, false)
+	witness.C, witness.NotC = Bool{}, Bool{}
+	witness.C.V.Assign(2)
+	witness.NotC.V.Assign(-1)
+	assert.ProverFailed(&arithmeticCircuit{}, witness, test.WithCurves(ecc.BN254))
+}
+
+type lessOrEqualCircuit struct {
+	A, B UintN
+}
+
+func (circuit *lessOrEqualCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	NewUintN(api, circuit.A, 16).AssertIsLessOrEqual(api, NewUintN(api, circuit.B, 16))
+	return nil
+}
+
+func TestAssertIsLessOrEqual(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, w := range [][2]uint64{{0, 0}, {3, 5}, {5, 5}, {0, 1<<16 - 1}} {
+		var witness lessOrEqualCircuit
+		witness.A.Assign(w[0])
+		witness.B.Assign(w[1])
+		assert.ProverSucceeded(&lessOrEqualCircuit{}, &witness, test.WithCurves(ecc.BN254))
+	}
+	for _, w := range [][2]uint64{{6, 5}, {1 << 16, 1 << 16}, {1<<16 - 1, 0}} {
+		var witness lessOrEqualCircuit
+		witness.A.Assign(w[0])
+		witness.B.Assign(w[1])
+		assert.ProverFailed(&lessOrEqualCircuit{}, &witness, test.WithCurves(ecc.BN254))
+	}
+}
+
+// wrapCircuit wraps its inputs nbWraps times
+type wrapCircuit struct {
+	A       Uint64
+	B       Bool
+	X       frontend.Variable
+	nbWraps int
+}
+
+func (circuit *wrapCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	bits := api.ToBinary(circuit.X, 64)
+	for i := 0; i < circuit.nbWraps; i++ {
+		NewUint64(api, circuit.A)
+		NewUintN(api, circuit.A, 64)
+		NewBool(api, circuit.B)
+		NewBool(api, bits[0])
+
+		// X is already decomposed
+		NewUint64(api, circuit.X)
+	}
+	return nil
+}
+
+// TestNoDuplicateConstraints checks that wrapping a variable again doesn't add constraints
+func TestNoDuplicateConstraints(t *testing.T) {
+	assert := require.New(t)
+
+	for _, b := range backend.Implemented() {
+		var nbConstraints []int
+		for nbWraps := 1; nbWraps < 4; nbWraps++ {
+			ccs, err := frontend.Compile(ecc.BN254, b, &wrapCircuit{nbWraps: nbWraps})
+			assert.NoError(err)
+			nbConstraints = append(nbConstraints, ccs.GetNbConstraints())
+		}
+		if b == backend.GROTH16 {
+			// the decompositions of X and A, the boolean constraint of B
+			assert.Equal(2*(64+1)+1, nbConstraints[0])
+		}
+		for i := 1; i < len(nbConstraints); i++ {
+			assert.Equal(nbConstraints[0], nbConstraints[i], "%s: %d wraps", b, i+1)
+		}
+	}
+
+	// range checking on fewer bits reuses the decomposition
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &narrowCircuit{})
+	assert.NoError(err)
+	assert.Equal(64+1+(64-8), ccs.GetNbConstraints())
+}
+
+type narrowCircuit struct {
+	A Uint64
+}
+
+func (circuit *narrowCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	a := NewUint64(api, circuit.A)
+	NewUintN(api, a, 8)
+	NewUintN(api, a, 8)
+	NewUint64(api, a)
+	return nil
+}
@@ -0,0 +1,189 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// MaxNbBits is the maximum number of bits of a UintN, such that the products of two UintN fit in
+// the SNARK field of all the curves
+const MaxNbBits = 120
+
+// UintN is a variable constrained to be less than 2**n, n being its number of bits
+type UintN struct {
+	V frontend.Variable `gnark:",embed"`
+
+	bits []frontend.Variable // little endian, set by NewUintN
+}
+
+// NewUintN constrains v to be less than 2**nbBits, and returns it as a UintN.
+// v is a frontend.Variable, a UintN or Uint64 (an input of the circuit), or a constant.
+// nbBits must be in [1, MaxNbBits]
+func NewUintN(api frontend.API, v interface{}, nbBits int) UintN {
+	if nbBits < 1 || nbBits > MaxNbBits {
+		panic(fmt.Sprintf("NewUintN: nbBits must be in [1, %d]", MaxNbBits))
+	}
+	res := UintN{V: toVariable(api, v)}
+	res.bits = api.RangeCheck(res.V, nbBits)
+	return res
+}
+
+// Assign sets the value of u in a witness
+func (u *UintN) Assign(value uint64) {
+	u.V.Assign(value)
+}
+
+// Variable returns the variable of u
+func (u UintN) Variable() frontend.Variable {
+	return u.V
+}
+
+// NbBits returns the number of bits of u
+func (u UintN) NbBits() int {
+	u.mustBeChecked()
+	return len(u.bits)
+}
+
+// ToBinary returns the NbBits() bits of u, in little endian. It doesn't add any constraint
+func (u UintN) ToBinary() []Bool {
+	u.mustBeChecked()
+	res := make([]Bool, len(u.bits))
+	for i := 0; i < len(u.bits); i++ {
+		res[i] = Bool{V: u.bits[i]}
+	}
+	return res
+}
+
+// Add returns u + other mod 2**n, and the carry. u and other must have the same number of bits
+func (u UintN) Add(api frontend.API, other UintN) (UintN, Bool) {
+	n := u.mustMatch(other)
+	bits := api.ToBinary(api.Add(u.V, other.V), n+1)
+	return fromBits(api, bits[:n]), Bool{V: bits[n]}
+}
+
+// Sub returns u - other mod 2**n, and the borrow (1 if other > u). u and other must have the
+// same number of bits
+func (u UintN) Sub(api frontend.API, other UintN) (UintN, Bool) {
+	n := u.mustMatch(other)
+	var shift big.Int
+	shift.Lsh(big.NewInt(1), uint(n))
+	bits := api.ToBinary(api.Sub(api.Add(u.V, &shift), other.V), n+1)
+	return fromBits(api, bits[:n]), Bool{V: bits[n]}.Not(api)
+}
+
+// Mul returns the low and high n bits of u * other. u and other must have the same number of bits
+func (u UintN) Mul(api frontend.API, other UintN) (lo, hi UintN) {
+	n := u.mustMatch(other)
+	bits := api.ToBinary(api.Mul(u.V, other.V), 2*n)
+	return fromBits(api, bits[:n]), fromBits(api, bits[n:])
+}
+
+// AssertIsLessOrEqual fails if u > other. u and other must have the same number of bits
+func (u UintN) AssertIsLessOrEqual(api frontend.API, other UintN) {
+	n := u.mustMatch(other)
+
+	// other - u (mod q) is less than 2**n if and only if u <= other, as 2**n < q/2
+	api.RangeCheck(api.Sub(other.V, u.V), n)
+}
+
+// fromBits returns the UintN whose bits are bits; they must be constrained as booleans
+func fromBits(api frontend.API, bits []frontend.Variable) UintN {
+	return UintN{V: api.FromBinary(bits...), bits: bits}
+}
+
+func (u UintN) mustBeChecked() {
+	if u.bits == nil {
+		panic("UintN must be created with NewUintN")
+	}
+}
+
+// mustMatch returns the number of bits of u and other, which must be equal
+func (u UintN) mustMatch(other UintN) int {
+	u.mustBeChecked()
+	other.mustBeChecked()
+	if len(u.bits) != len(other.bits) {
+		panic(fmt.Sprintf("UintN: operands of %d and %d bits", len(u.bits), len(other.bits)))
+	}
+	return len(u.bits)
+}
+
+// Uint64 is a variable constrained to be less than 2**64
+type Uint64 struct {
+	V frontend.Variable `gnark:",embed"`
+
+	bits []frontend.Variable // little endian, set by NewUint64
+}
+
+// NewUint64 constrains v to be less than 2**64, and returns it as a Uint64.
+// v is a frontend.Variable, a Uint64 or UintN (an input of the circuit), or a constant
+func NewUint64(api frontend.API, v interface{}) Uint64 {
+	return uint64Of(NewUintN(api, v, 64))
+}
+
+// Assign sets the value of u in a witness
+func (u *Uint64) Assign(value uint64) {
+	u.V.Assign(value)
+}
+
+// Variable returns the variable of u
+func (u Uint64) Variable() frontend.Variable {
+	return u.V
+}
+
+// UintN returns u as a UintN of 64 bits
+func (u Uint64) UintN(api frontend.API) UintN {
+	if u.bits == nil {
+		return NewUintN(api, u.V, 64)
+	}
+	return UintN{V: u.V, bits: u.bits}
+}
+
+// ToBinary returns the 64 bits of u, in little endian
+func (u Uint64) ToBinary(api frontend.API) []Bool {
+	return u.UintN(api).ToBinary()
+}
+
+// Add returns u + other mod 2**64, and the carry
+func (u Uint64) Add(api frontend.API, other Uint64) (Uint64, Bool) {
+	res, carry := u.UintN(api).Add(api, other.UintN(api))
+	return uint64Of(res), carry
+}
+
+// Sub returns u - other mod 2**64, and the borrow (1 if other > u)
+func (u Uint64) Sub(api frontend.API, other Uint64) (Uint64, Bool) {
+	res, borrow := u.UintN(api).Sub(api, other.UintN(api))
+	return uint64Of(res), borrow
+}
+
+// Mul returns the low and high 64 bits of u * other
+func (u Uint64) Mul(api frontend.API, other Uint64) (lo, hi Uint64) {
+	l, h := u.UintN(api).Mul(api, other.UintN(api))
+	return uint64Of(l), uint64Of(h)
+}
+
+// AssertIsLessOrEqual fails if u > other
+func (u Uint64) AssertIsLessOrEqual(api frontend.API, other Uint64) {
+	u.UintN(api).AssertIsLessOrEqual(api, other.UintN(api))
+}
+
+func uint64Of(u UintN) Uint64 {
+	return Uint64{V: u.V, bits: u.bits}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package witnesscommit binds the secret witness of a circuit to a public commitment, for example to
+// a blob published off-chain: the commitment, the circuit's public input, is the hash of the secret
+// inputs, computed natively by backend/witness.Commit and checked in the circuit by Verify.
+//
+// Both hash the secret inputs in the order of the secret witness vector, given by the schema of the
+// circuit (see frontend.SecretInputs), one field element per input, without padding. A circuit
+// commits to its own secret inputs with:
+//
+//	func (circuit *Circuit) Define(curveID ecc.ID, api frontend.API) error {
+//		secret, err := frontend.SecretInputs(circuit)
+//		if err != nil {
+//			return err
+//		}
+//		h, err := mimc.NewMiMC(seed, curveID, api)
+//		if err != nil {
+//			return err
+//		}
+//		witnesscommit.Verify(api, &h, secret, circuit.Commitment)
+//		...
+//	}
+//
+// and the commitment of an assignment is witness.Commit(curveID, &assignment, hash.MIMC_BN254.New(seed))
+// (gnark-crypto's MiMC of the curve, with the same seed).
+package witnesscommit
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+)
+
+// Verify asserts that commitment is the hash by h of secretVars, in their order; secretVars are usually
+// the secret inputs of the circuit, see frontend.SecretInputs. h is reset first.
+func Verify(api frontend.API, h hash.Hash, secretVars []frontend.Variable, commitment frontend.Variable) {
+	h.Reset()
+	h.Write(secretVars...)
+	api.AssertIsEqual(h.Sum(), commitment)
+}
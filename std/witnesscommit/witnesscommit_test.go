@@ -0,0 +1,129 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package witnesscommit
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+const seed = "witnesscommit test"
+
+// blobCircuit proves knowledge of a blob, whose commitment is the only public input; the secret inputs
+// are interleaved with the public one and nested, to check the ordering of the commitments
+type blobCircuit struct {
+	Header     frontend.Variable `gnark:",secret"`
+	Commitment frontend.Variable `gnark:",public"`
+	Blob       []frontend.Variable
+	Trailer    struct {
+		Length frontend.Variable
+		Sum    frontend.Variable
+	} `gnark:",secret"`
+}
+
+func newBlobCircuit(n int) *blobCircuit {
+	return &blobCircuit{Blob: make([]frontend.Variable, n)}
+}
+
+func (circuit *blobCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	secret, err := frontend.SecretInputs(circuit)
+	if err != nil {
+		return err
+	}
+	h, err := mimc.NewMiMC(seed, curveID, api)
+	if err != nil {
+		return err
+	}
+	Verify(api, &h, secret, circuit.Commitment)
+
+	api.AssertIsEqual(circuit.Trailer.Length, len(circuit.Blob))
+	sum := circuit.Header
+	for _, b := range circuit.Blob {
+		sum = api.Add(sum, b)
+	}
+	api.AssertIsEqual(circuit.Trailer.Sum, sum)
+	return nil
+}
+
+// blobAssignment returns the assignment of the blob, committed to
+func blobAssignment(t *testing.T, blob []uint64) *blobCircuit {
+	w := newBlobCircuit(len(blob))
+	w.Header.Assign(42)
+	sum := uint64(42)
+	for i, b := range blob {
+		w.Blob[i].Assign(b)
+		sum += b
+	}
+	w.Trailer.Length.Assign(len(blob))
+	w.Trailer.Sum.Assign(sum)
+
+	commitment, err := witness.Commit(ecc.BN254, w, hash.MIMC_BN254.New(seed))
+	require.NoError(t, err)
+	w.Commitment.Assign(commitment)
+	return w
+}
+
+func TestVerify(t *testing.T) {
+	blob := []uint64{1, 2, 3, 4, 5}
+	valid := blobAssignment(t, blob)
+
+	assert := test.NewAssert(t)
+	assert.ProverSucceeded(newBlobCircuit(len(blob)), valid, test.WithCurves(ecc.BN254))
+
+	// the blob doesn't match the commitment
+	tampered := blobAssignment(t, blob)
+	tampered.Blob[2].WitnessValue = 7
+	tampered.Trailer.Sum.WitnessValue = 42 + 1 + 2 + 7 + 4 + 5
+	assert.ProverFailed(newBlobCircuit(len(blob)), tampered, test.WithCurves(ecc.BN254))
+
+	// the commitment depends on the order of the inputs
+	swapped := blobAssignment(t, []uint64{2, 1, 3, 4, 5})
+	require.NotEqual(t, valid.Commitment.WitnessValue, swapped.Commitment.WitnessValue)
+}
+
+func TestVerifyEndToEnd(t *testing.T) {
+	assert := require.New(t)
+	blob := []uint64{10, 20, 30}
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, newBlobCircuit(len(blob)))
+	assert.NoError(err)
+	_, nbSecret, nbPublic := ccs.GetNbVariables()
+	assert.Equal(2, nbPublic) // the constant wire and the commitment
+	assert.Equal(len(blob)+3, nbSecret)
+
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	w := blobAssignment(t, blob)
+	proof, err := groth16.Prove(ccs, pk, w)
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, w))
+
+	// a one element change of the blob changes the commitment, the proof doesn't verify against it
+	other := blobAssignment(t, []uint64{10, 21, 30})
+	assert.NotEqual(w.Commitment.WitnessValue, other.Commitment.WitnessValue)
+	assert.Error(groth16.Verify(proof, vk, other))
+}
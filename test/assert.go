@@ -17,19 +17,22 @@ limitations under the License.
 package test
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/simulated"
 	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/frontend"
+	gnarkcurves "github.com/consensys/gnark/internal/curves"
 	"github.com/consensys/gnark/internal/utils"
+	"github.com/consensys/gnark/logger"
 	"github.com/stretchr/testify/require"
 )
 
@@ -39,10 +42,15 @@ var (
 	ErrInvalidWitnessVerified      = errors.New("invalid witness resulted in a valid proof")
 )
 
+// curves tested by default
+var curves = gnarkcurves.Implemented()
+
 // Assert is a helper to test circuits
 type Assert struct {
+	t *testing.T
 	*require.Assertions
 	compiled map[string]frontend.CompiledConstraintSystem // cache compilation
+	skipped  map[string]struct{}                          // combinations of the matrix already reported as skipped
 }
 
 // NewAssert returns an Assert helper embedding a testify/require object for convenience
@@ -52,7 +60,7 @@ type Assert struct {
 // the first call to assert.ProverSucceeded/Failed will compile the circuit for n curves, m backends
 // and subsequent calls will re-use the result of the compilation, if available.
 func NewAssert(t *testing.T) *Assert {
-	return &Assert{require.New(t), make(map[string]frontend.CompiledConstraintSystem)}
+	return &Assert{t, require.New(t), make(map[string]frontend.CompiledConstraintSystem), make(map[string]struct{})}
 }
 
 // ProverSucceeded fails the test if any of the following step errored:
@@ -60,95 +68,22 @@ func NewAssert(t *testing.T) *Assert {
 // 1. compiles the circuit (or fetch it from the cache)
 // 2. using the test execution engine, executes the circuit with provided witness
 // 3. run Setup / Prove / Verify with the backend
-// 4. if set, (de)serializes the witness and call ReadAndProve and ReadAndVerify on the backend
+// 4. serializes the public witness, checks its number of elements against the verifying key, and
+// verifies the proof with the deserialized copy
+// 5. if set, (de)serializes the full witness and call ReadAndProve on the backend
+// 6. if set (see WithProofIndistinguishability), checks that the proofs are randomized, see ProofIndistinguishable
+//
+// Steps 2 to 5 are run for each curve, the engine first, then each backend; if the witness is rejected,
+// the test fails with a table of the outcomes of all the combinations, which shows if they disagree.
 //
 // By default, this tests on all curves and proving schemes supported by gnark. See available TestingOption.
 func (assert *Assert) ProverSucceeded(circuit frontend.Circuit, validWitness frontend.Circuit, opts ...func(opt *TestingOption) error) {
 	opt := assert.options(opts...)
 
-	var buf bytes.Buffer
-
-	// for each {curve, backend} tuple
-	for _, curve := range opt.curves {
-		for _, b := range opt.backends {
-
-			checkError := func(err error) { assert.checkError(err, b, curve, validWitness) }
-
-			// 1- compile the circuit
-			ccs, err := assert.compile(circuit, curve, b, opt.compileOpts)
-			checkError(err)
-
-			// must not error with big int test engine
-			err = IsSolved(circuit, validWitness, curve)
-			checkError(err)
-
-			switch b {
-			case backend.GROTH16:
-				pk, vk, err := groth16.Setup(ccs)
-				checkError(err)
-
-				// ensure prove / verify works well with valid witnesses
-				proof, err := groth16.Prove(ccs, pk, validWitness, opt.proverOpts...)
-				checkError(err)
-
-				err = groth16.Verify(proof, vk, validWitness)
-				checkError(err)
-
-				// same thing through serialized witnesses
-				if opt.witnessSerialization {
-					buf.Reset()
-
-					_, err = witness.WriteFullTo(&buf, curve, validWitness)
-					checkError(err)
-
-					correctProof, err := groth16.ReadAndProve(ccs, pk, &buf, opt.proverOpts...)
-					checkError(err)
-
-					buf.Reset()
-
-					_, err = witness.WritePublicTo(&buf, curve, validWitness)
-					checkError(err)
-
-					err = groth16.ReadAndVerify(correctProof, vk, &buf)
-					checkError(err)
-				}
-
-			case backend.PLONK:
-				srs, err := NewKZGSRS(ccs)
-				checkError(err)
-
-				pk, vk, err := plonk.Setup(ccs, srs)
-				checkError(err)
-
-				correctProof, err := plonk.Prove(ccs, pk, validWitness, opt.proverOpts...)
-				checkError(err)
-
-				err = plonk.Verify(correctProof, vk, validWitness)
-				checkError(err)
-
-				// witness serialization tests.
-				if opt.witnessSerialization {
-					buf.Reset()
-
-					_, err := witness.WriteFullTo(&buf, curve, validWitness)
-					checkError(err)
-
-					correctProof, err := plonk.ReadAndProve(ccs, pk, &buf, opt.proverOpts...)
-					checkError(err)
-
-					buf.Reset()
+	assert.checkOutcomes(assert.crossCheck(circuit, validWitness, opt, true), true, validWitness)
 
-					_, err = witness.WritePublicTo(&buf, curve, validWitness)
-					checkError(err)
-
-					err = plonk.ReadAndVerify(correctProof, vk, &buf)
-					checkError(err)
-				}
-
-			default:
-				panic("backend not implemented")
-			}
-		}
+	if opt.nbProofs != 0 {
+		assert.ProofIndistinguishable(circuit, validWitness, opt.nbProofs, opts...)
 	}
 
 	// TODO may not be the right place, but ensures all our tests call these minimal tests
@@ -156,62 +91,33 @@ func (assert *Assert) ProverSucceeded(circuit frontend.Circuit, validWitness fro
 	assert.Fuzz(circuit, 5, opts...)
 }
 
-// ProverSucceeded fails the test if any of the following step errored:
+// ProverFailed fails the test if any of the following step didn't error:
 //
 // 1. compiles the circuit (or fetch it from the cache)
 // 2. using the test execution engine, executes the circuit with provided witness (must fail)
 // 3. run Setup / Prove / Verify with the backend (must fail)
 //
+// As for ProverSucceeded, an accepted witness fails the test with a table of the outcomes of all the combinations.
+//
 // By default, this tests on all curves and proving schemes supported by gnark. See available TestingOption.
 func (assert *Assert) ProverFailed(circuit frontend.Circuit, invalidWitness frontend.Circuit, opts ...func(opt *TestingOption) error) {
 	opt := assert.options(opts...)
 
-	popts := append(opt.proverOpts, backend.IgnoreSolverError)
+	assert.checkOutcomes(assert.crossCheck(circuit, invalidWitness, opt, false), false, invalidWitness)
+}
+
+// CompilationFailed fails the test if, for any curve and backend, frontend.Compile doesn't return an error
+// containing expectedError. The test execution engine is not run, the circuit being invalid.
+//
+// By default, this tests on all curves and proving schemes supported by gnark. See available TestingOption.
+func (assert *Assert) CompilationFailed(circuit frontend.Circuit, expectedError string, opts ...func(opt *TestingOption) error) {
+	opt := assert.options(opts...)
 
 	for _, curve := range opt.curves {
 		for _, b := range opt.backends {
-
-			checkError := func(err error) { assert.checkError(err, b, curve, invalidWitness) }
-			mustError := func(err error) { assert.mustError(err, b, curve, invalidWitness) }
-
-			// 1- compile the circuit
-			ccs, err := assert.compile(circuit, curve, b, opt.compileOpts)
-			checkError(err)
-
-			// must error with big int test engine
-			err = IsSolved(circuit, invalidWitness, curve)
-			mustError(err)
-
-			switch b {
-			case backend.GROTH16:
-				pk, vk, err := groth16.Setup(ccs)
-				checkError(err)
-
-				err = groth16.IsSolved(ccs, invalidWitness)
-				mustError(err)
-
-				proof, _ := groth16.Prove(ccs, pk, invalidWitness, popts...)
-
-				err = groth16.Verify(proof, vk, invalidWitness)
-				mustError(err)
-
-			case backend.PLONK:
-				srs, err := NewKZGSRS(ccs)
-				checkError(err)
-
-				pk, vk, err := plonk.Setup(ccs, srs)
-				checkError(err)
-
-				err = plonk.IsSolved(ccs, invalidWitness)
-				mustError(err)
-
-				incorrectProof, _ := plonk.Prove(ccs, pk, invalidWitness, popts...)
-				err = plonk.Verify(incorrectProof, vk, invalidWitness)
-				mustError(err)
-
-			default:
-				panic("backend not implemented")
-			}
+			_, err := frontend.Compile(curve, b, circuit, opt.compileOpts...)
+			assert.Error(err, "%s(%s): compilation should have failed", b, curve)
+			assert.Contains(err.Error(), expectedError, "%s(%s): unexpected compilation error", b, curve)
 		}
 	}
 }
@@ -245,6 +151,9 @@ func (assert *Assert) solvingSucceeded(circuit frontend.Circuit, validWitness fr
 	case backend.PLONK:
 		err := plonk.IsSolved(ccs, validWitness, opt.proverOpts...)
 		checkError(err)
+	case backend.SIMULATED:
+		err := simulated.IsSolved(ccs, validWitness, opt.proverOpts...)
+		checkError(err)
 	default:
 		panic("not implemented")
 	}
@@ -268,7 +177,7 @@ func (assert *Assert) solvingFailed(circuit frontend.Circuit, invalidWitness fro
 	// 1- compile the circuit
 	ccs, err := assert.compile(circuit, curve, b, opt.compileOpts)
 	if err != nil {
-		fmt.Println(reflect.TypeOf(circuit).String())
+		logger.Warn("%s: compilation failed", reflect.TypeOf(circuit).String())
 	}
 	checkError(err)
 
@@ -283,6 +192,9 @@ func (assert *Assert) solvingFailed(circuit frontend.Circuit, invalidWitness fro
 	case backend.PLONK:
 		err := plonk.IsSolved(ccs, invalidWitness, opt.proverOpts...)
 		mustError(err)
+	case backend.SIMULATED:
+		err := simulated.IsSolved(ccs, invalidWitness, opt.proverOpts...)
+		mustError(err)
 	default:
 		panic("not implemented")
 	}
@@ -292,6 +204,8 @@ func (assert *Assert) solvingFailed(circuit frontend.Circuit, invalidWitness fro
 // Fuzz fuzzes the given circuit by instantiating "randomized" witnesses and cross checking
 // execution result between constraint system solver and big.Int test execution engine
 //
+// The inputs with a generator (see WithGenerators) are set by their generator.
+//
 // note: this is experimental and will be more tightly integrated with go1.18 built-in fuzzing
 func (assert *Assert) Fuzz(circuit frontend.Circuit, fuzzCount int, opts ...func(opt *TestingOption) error) {
 	opt := assert.options(opts...)
@@ -335,6 +249,7 @@ func (assert *Assert) Fuzz(circuit frontend.Circuit, fuzzCount int, opts ...func
 func (assert *Assert) fuzzer(fuzzer filler, circuit, w frontend.Circuit, b backend.ID, curve ecc.ID, opt *TestingOption) int {
 	// fuzz a witness
 	fuzzer(w, curve)
+	assert.NoError(generate(w, opt.generators, curve), "generating witness")
 
 	err := IsSolved(circuit, w, curve)
 
@@ -349,6 +264,57 @@ func (assert *Assert) fuzzer(fuzzer filler, circuit, w frontend.Circuit, b backe
 	return 0
 }
 
+// PropertyTest generates random assignments of the circuit (see WithGenerators and WithNbAssignments), and
+// checks that the circuit is satisfied by an assignment if and only if property holds for it.
+//
+// property is the Go-level specification of the circuit; it gets the generated assignment, which inputs
+// are set with frontend.Value. A mismatch fails the test and reports the offending assignment as witness JSON.
+func (assert *Assert) PropertyTest(circuit frontend.Circuit, property func(assignment frontend.Circuit) bool, opts ...func(opt *TestingOption) error) {
+	opt := assert.options(opts...)
+
+	w := utils.ShallowClone(circuit)
+
+	for _, curve := range opt.curves {
+		for _, b := range opt.backends {
+			ccs, err := assert.compile(circuit, curve, b, opt.compileOpts)
+			assert.NoError(err)
+
+			for i := 0; i < opt.nbAssignments; i++ {
+				randomFiller(w, curve)
+				assert.NoError(generate(w, opt.generators, curve), "generating witness")
+
+				expected := property(w)
+
+				switch b {
+				case backend.GROTH16:
+					err = groth16.IsSolved(ccs, w, opt.proverOpts...)
+				case backend.PLONK:
+					err = plonk.IsSolved(ccs, w, opt.proverOpts...)
+				case backend.SIMULATED:
+					err = simulated.IsSolved(ccs, w, opt.proverOpts...)
+				default:
+					panic("not implemented")
+				}
+
+				if expected != (err == nil) {
+					verdict := "satisfied"
+					if err != nil {
+						verdict = "not satisfied: " + err.Error()
+					}
+					json, jErr := witness.ToJSON(w, curve)
+					if jErr != nil {
+						json = jErr.Error()
+					}
+					assert.FailNow(fmt.Sprintf("%s(%s): property is %t but the circuit is %s\nwitness:%s", b.String(), curve.String(), expected, verdict, json))
+				}
+			}
+
+			utils.ResetWitness(w)
+			utils.ResetWitness(circuit)
+		}
+	}
+}
+
 // compile the given circuit for given curve and backend, if not already present in cache
 func (assert *Assert) compile(circuit frontend.Circuit, curveID ecc.ID, backendID backend.ID, compileOpts []func(opt *frontend.CompileOption) error) (frontend.CompiledConstraintSystem, error) {
 	key := curveID.String() + backendID.String() + reflect.TypeOf(circuit).String()
@@ -386,24 +352,110 @@ func (assert *Assert) options(opts ...func(*TestingOption) error) TestingOption
 	// apply options
 	opt := TestingOption{
 		witnessSerialization: true,
-		backends:             backend.Implemented(),
-		curves:               ecc.Implemented(),
+		nbAssignments:        100,
 	}
 	for _, option := range opts {
 		err := option(&opt)
 		assert.NoError(err, "parsing TestingOption")
 	}
 
+	curvesNarrowedBy, backendsNarrowedBy := assert.defaultMatrix(&opt)
+
 	if testing.Short() {
 		// if curves are all there, we just test with bn254
-		if reflect.DeepEqual(opt.curves, ecc.Implemented()) {
+		if !opt.fullMatrix && reflect.DeepEqual(opt.curves, curves) {
 			opt.curves = []ecc.ID{ecc.BN254}
+			curvesNarrowedBy = "-short"
 		}
 		opt.witnessSerialization = false
 	}
+
+	assert.logSkipped(opt, curvesNarrowedBy, backendsNarrowedBy)
 	return opt
 }
 
+// defaultMatrix sets the curves and backends which were not set by the options
+// and returns what narrowed them from the full matrix, if anything
+func (assert *Assert) defaultMatrix(opt *TestingOption) (curvesNarrowedBy, backendsNarrowedBy string) {
+	assert.NoError(envErr, "parsing environment")
+
+	if opt.curves == nil {
+		switch {
+		case envCurves != nil:
+			opt.curves, curvesNarrowedBy = envCurves, EnvCurves
+		case defaultCurves != nil:
+			opt.curves, curvesNarrowedBy = defaultCurves, "SetDefaultCurves"
+		default:
+			opt.curves = curves
+		}
+	}
+	if opt.backends == nil {
+		switch {
+		case envBackends != nil:
+			opt.backends, backendsNarrowedBy = envBackends, EnvBackends
+		case defaultBackends != nil:
+			opt.backends, backendsNarrowedBy = defaultBackends, "SetDefaultBackends"
+		default:
+			opt.backends = backend.Implemented()
+		}
+	}
+	return
+}
+
+// logSkipped reports (once per Assert) the combinations which won't be tested because the
+// default matrix was narrowed; combinations excluded by the options of the call are not reported
+func (assert *Assert) logSkipped(opt TestingOption, curvesNarrowedBy, backendsNarrowedBy string) {
+	if curvesNarrowedBy == "" && backendsNarrowedBy == "" {
+		return
+	}
+	allCurves, allBackends := opt.curves, opt.backends
+	if curvesNarrowedBy != "" {
+		allCurves = curves
+	}
+	if backendsNarrowedBy != "" {
+		allBackends = backend.Implemented()
+	}
+
+	for _, b := range allBackends {
+		for _, c := range allCurves {
+			var narrowedBy []string
+			if !containsCurve(opt.curves, c) {
+				narrowedBy = append(narrowedBy, curvesNarrowedBy)
+			}
+			if !containsBackend(opt.backends, b) {
+				narrowedBy = append(narrowedBy, backendsNarrowedBy)
+			}
+			if len(narrowedBy) == 0 {
+				continue
+			}
+			key := b.String() + "(" + c.String() + ")"
+			if _, ok := assert.skipped[key]; ok {
+				continue
+			}
+			assert.skipped[key] = struct{}{}
+			assert.t.Logf("skipping %s: test matrix narrowed by %s", key, strings.Join(narrowedBy, ", "))
+		}
+	}
+}
+
+func containsCurve(l []ecc.ID, c ecc.ID) bool {
+	for _, v := range l {
+		if v == c {
+			return true
+		}
+	}
+	return false
+}
+
+func containsBackend(l []backend.ID, b backend.ID) bool {
+	for _, v := range l {
+		if v == b {
+			return true
+		}
+	}
+	return false
+}
+
 // ensure the error is set, else fails the test
 func (assert *Assert) mustError(err error, backendID backend.ID, curve ecc.ID, w frontend.Circuit) {
 	if err != nil {
@@ -0,0 +1,94 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+)
+
+// oldDivCircuit is deliberately backend divergent: compiled, it divides as Div did before the divisor was
+// constrained to be non zero (0 / 0 is then solved), while the test execution engine rejects it
+type oldDivCircuit struct {
+	X, Y frontend.Variable `gnark:",secret"`
+	Z    frontend.Variable `gnark:",public"`
+}
+
+func (circuit *oldDivCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	if _, compiled := api.(interface{ NbConstraints() int }); compiled {
+		api.AssertIsEqual(api.DivUnchecked(circuit.X, circuit.Y), circuit.Z)
+	} else {
+		api.AssertIsEqual(api.Div(circuit.X, circuit.Y), circuit.Z)
+	}
+	return nil
+}
+
+func TestCrossCheck(t *testing.T) {
+	assert := NewAssert(t)
+	opt := assert.options(WithCurves(ecc.BN254))
+
+	// the engine and the backends agree on 6 / 3
+	v := assert.crossCheck(&oldDivCircuit{}, &oldDivCircuit{X: frontend.Value(6), Y: frontend.Value(3), Z: frontend.Value(2)}, opt, true)
+	assert.Len(v, 1+len(backend.Implemented()))
+	assert.False(v.disagree())
+	for _, r := range v {
+		assert.True(r.accepted, "%s(%s): %v", r.name, r.curve, r.err)
+	}
+
+	// but not on 0 / 0
+	w := &oldDivCircuit{X: frontend.Value(0), Y: frontend.Value(0), Z: frontend.Value(0)}
+	for _, valid := range []bool{true, false} {
+		v = assert.crossCheck(&oldDivCircuit{}, w, opt, valid)
+		assert.True(v.disagree())
+		assert.False(v[0].accepted, "engine")
+		for _, r := range v[1:] {
+			assert.True(r.accepted, "%s(%s): %v", r.name, r.curve, r.err)
+		}
+
+		table := strings.Split(strings.TrimSpace(v.String()), "\n")
+		assert.Len(table, 1+len(v))
+		assert.Regexp(`^curve\s+backend\s+verdict\s+error$`, table[0])
+		assert.Regexp(`^bn254\s+engine\s+rejected\s+\S`, table[1])
+		for i, b := range backend.Implemented() {
+			assert.Regexp(`^bn254\s+`+b.String()+`\s+accepted\s*$`, table[2+i])
+		}
+	}
+}
+
+func TestPublicWitnessSize(t *testing.T) {
+	assert := NewAssert(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &oldDivCircuit{})
+	assert.NoError(err)
+	_, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	assert.Equal(1, vk.NbPublicWitness())
+
+	var buf bytes.Buffer
+	w := &oldDivCircuit{Z: frontend.Value(2)}
+	assert.NoError(writePublicWitness(&buf, ecc.BN254, w, vk.NbPublicWitness()))
+	assert.Equal(4+32, buf.Len())
+
+	err = writePublicWitness(&buf, ecc.BN254, w, vk.NbPublicWitness()+1)
+	assert.EqualError(err, "serialized public witness has 1 elements, the verifying key expects 2")
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// ConstraintBudget fails the test if, for any curve and backend, the constraints of a tag (see frontend.API.Tag)
+// exceed its budget: the budget of a label covers the constraints of its scopes, including the ones of the
+// tags nested in them ("hash" covers "hash/round"). The empty label is the budget of the untagged
+// constraints. A label which the circuit doesn't tag fails the test too, to catch stale budgets. For example,
+// to catch the regressions of a gadget in CI:
+//
+//	assert.ConstraintBudget(&circuit, map[string]int{"sha256": 27000}, test.WithBackends(backend.GROTH16))
+//
+// By default, this tests on all curves and proving schemes supported by gnark. See available TestingOption.
+func (assert *Assert) ConstraintBudget(circuit frontend.Circuit, budgets map[string]int, opts ...func(opt *TestingOption) error) {
+	opt := assert.options(opts...)
+
+	for _, curve := range opt.curves {
+		for _, b := range opt.backends {
+			ccs, err := assert.compile(circuit, curve, b, opt.compileOpts)
+			assert.NoError(err, "%s(%s)", b, curve)
+			if overruns := budgetOverruns(ccs.ConstraintsPerTag(), budgets); len(overruns) != 0 {
+				assert.FailNow(fmt.Sprintf("%s(%s): constraint budget exceeded\n%s", b, curve, strings.Join(overruns, "\n")))
+			}
+		}
+	}
+}
+
+// budgetOverruns returns the labels of budgets (sorted) which the counts of constraints per tag exceed, or
+// which don't have any count, with their number of constraints
+func budgetOverruns(perTag, budgets map[string]int) []string {
+	labels := make([]string, 0, len(budgets))
+	for label := range budgets {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	var res []string
+	for _, label := range labels {
+		n, found := 0, false
+		for tag, c := range perTag {
+			if tag == label || (label != "" && strings.HasPrefix(tag, label+"/")) {
+				n += c
+				found = true
+			}
+		}
+		switch {
+		case !found:
+			res = append(res, fmt.Sprintf("%q: not a tag of the circuit", label))
+		case n > budgets[label]:
+			res = append(res, fmt.Sprintf("%q: %d constraints, budget %d", label, n, budgets[label]))
+		}
+	}
+	return res
+}
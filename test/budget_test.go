@@ -0,0 +1,55 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+)
+
+// budgetCircuit tags a decomposition in bits and the products of its bits
+type budgetCircuit struct {
+	X frontend.Variable `gnark:",secret"`
+}
+
+func (circuit *budgetCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	end := api.Tag("gadget")
+	bits := api.ToBinary(circuit.X, 8)
+	endMul := api.Tag("mul")
+	api.AssertIsEqual(api.Mul(bits[0], bits[1]), 0)
+	endMul()
+	end()
+	return nil
+}
+
+func TestConstraintBudget(t *testing.T) {
+	assert := NewAssert(t)
+
+	assert.ConstraintBudget(&budgetCircuit{}, map[string]int{"gadget": 12, "gadget/mul": 2}, WithCurves(ecc.BN254), WithBackends(backend.GROTH16))
+
+	perTag := map[string]int{"": 2, "gadget": 9, "gadget/mul": 1}
+	assert.Empty(budgetOverruns(perTag, map[string]int{"": 2, "gadget": 10}))
+	assert.Equal([]string{
+		`"": 2 constraints, budget 1`,
+		`"gadget": 10 constraints, budget 9`,
+		`"gadget/add": not a tag of the circuit`,
+		`"gadget/mu": not a tag of the circuit`,
+	}, budgetOverruns(perTag, map[string]int{"gadget": 9, "gadget/mu": 100, "gadget/add": 0, "": 1}))
+}
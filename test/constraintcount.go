@@ -0,0 +1,195 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+)
+
+// constraintCounts is the content of a golden file of RecordConstraintCounts: the number of constraints
+// per circuit name, backend and curve
+type constraintCounts map[string]map[string]map[string]int
+
+// AssertConstraintCount compiles the circuit and fails the test if its number of constraints (see
+// frontend.CompiledConstraintSystem.GetNbConstraints) differs from expected by more than tolerance * expected,
+// in either direction: a count which drops must be updated too, to keep catching the regressions. The failure
+// message shows the constraints per tag when the circuit has tags (see frontend.API.Tag). For example:
+//
+//	test.AssertConstraintCount(t, &circuit, backend.GROTH16, ecc.BN254, 27000, 0.05)
+func AssertConstraintCount(t testing.TB, circuit frontend.Circuit, backendID backend.ID, curve ecc.ID, expected int, tolerance float64) {
+	t.Helper()
+	ccs, err := frontend.Compile(curve, backendID, circuit)
+	if err != nil {
+		t.Fatalf("%s(%s): %v", backendID, curve, err)
+	}
+	if msg := checkConstraintCount(ccs, expected, tolerance); msg != "" {
+		t.Fatalf("%s(%s): %s", backendID, curve, msg)
+	}
+}
+
+// RecordConstraintCounts compiles the circuits and writes their number of constraints to w, as the JSON
+// golden file read by CheckConstraintCounts. The circuits are compiled on all the curves and backends,
+// unless narrowed by the WithCurves and WithBackends options (the environment and the defaults of the test
+// matrix don't apply, the golden file being shared); WithCompileOpts sets the compile options.
+func RecordConstraintCounts(circuits map[string]frontend.Circuit, w io.Writer, opts ...func(opt *TestingOption) error) error {
+	opt, err := constraintCountsOptions(opts)
+	if err != nil {
+		return err
+	}
+	if opt.curves == nil {
+		opt.curves = curves
+	}
+	if opt.backends == nil {
+		opt.backends = backend.Implemented()
+	}
+
+	counts := make(constraintCounts, len(circuits))
+	for name, circuit := range circuits {
+		counts[name] = make(map[string]map[string]int, len(opt.backends))
+		for _, b := range opt.backends {
+			counts[name][b.String()] = make(map[string]int, len(opt.curves))
+			for _, curve := range opt.curves {
+				ccs, err := frontend.Compile(curve, b, circuit, opt.compileOpts...)
+				if err != nil {
+					return fmt.Errorf("%s %s(%s): %w", name, b, curve, err)
+				}
+				counts[name][b.String()][curve.String()] = ccs.GetNbConstraints()
+			}
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(counts)
+}
+
+// CheckConstraintCounts fails the test if the number of constraints of the circuits differs from the golden
+// file r (written by RecordConstraintCounts) by more than tolerance * the recorded count, on any of the curves
+// and backends it records. A circuit missing from r, or recorded but missing from circuits, fails the test
+// too. The compile options are set by WithCompileOpts, the other options don't apply.
+func CheckConstraintCounts(t testing.TB, circuits map[string]frontend.Circuit, r io.Reader, tolerance float64, opts ...func(opt *TestingOption) error) {
+	t.Helper()
+	opt, err := constraintCountsOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var counts constraintCounts
+	if err := json.NewDecoder(r).Decode(&counts); err != nil {
+		t.Fatalf("reading the constraint counts: %v", err)
+	}
+
+	names := make([]string, 0, len(circuits)+len(counts))
+	for name := range circuits {
+		names = append(names, name)
+	}
+	for name := range counts {
+		if _, ok := circuits[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var mismatches []string
+	for _, name := range names {
+		circuit, ok := circuits[name]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, fmt.Sprintf("%s: recorded, but not a circuit of the test", name))
+			continue
+		case counts[name] == nil:
+			mismatches = append(mismatches, fmt.Sprintf("%s: not recorded", name))
+			continue
+		}
+		bNames := make([]string, 0, len(counts[name]))
+		for bName := range counts[name] {
+			bNames = append(bNames, bName)
+		}
+		sort.Strings(bNames)
+		for _, bName := range bNames {
+			b, ok := lookupBackend(bName)
+			if !ok {
+				mismatches = append(mismatches, fmt.Sprintf("%s: unknown backend %q", name, bName))
+				continue
+			}
+			cNames := make([]string, 0, len(counts[name][bName]))
+			for cName := range counts[name][bName] {
+				cNames = append(cNames, cName)
+			}
+			sort.Strings(cNames)
+			for _, cName := range cNames {
+				curve, ok := lookupCurve(cName)
+				if !ok {
+					mismatches = append(mismatches, fmt.Sprintf("%s: unknown curve %q", name, cName))
+					continue
+				}
+				ccs, err := frontend.Compile(curve, b, circuit, opt.compileOpts...)
+				if err != nil {
+					mismatches = append(mismatches, fmt.Sprintf("%s %s(%s): %v", name, b, curve, err))
+					continue
+				}
+				if msg := checkConstraintCount(ccs, counts[name][bName][cName], tolerance); msg != "" {
+					mismatches = append(mismatches, fmt.Sprintf("%s %s(%s): %s", name, b, curve, msg))
+				}
+			}
+		}
+	}
+	if len(mismatches) != 0 {
+		t.Fatalf("the constraint counts differ from the golden file, record it again if it is intended:\n%s", strings.Join(mismatches, "\n"))
+	}
+}
+
+// checkConstraintCount returns a message with the number of constraints of ccs, and of its tags, if it
+// differs from expected by more than tolerance * expected, or ""
+func checkConstraintCount(ccs frontend.CompiledConstraintSystem, expected int, tolerance float64) string {
+	actual := ccs.GetNbConstraints()
+	if math.Abs(float64(actual-expected)) <= tolerance*float64(expected) {
+		return ""
+	}
+	msg := fmt.Sprintf("%d constraints, expected %d (tolerance %g%%)", actual, expected, 100*tolerance)
+
+	perTag := ccs.ConstraintsPerTag()
+	labels := make([]string, 0, len(perTag))
+	for label := range perTag {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		msg += fmt.Sprintf("\n\t%q: %d", label, perTag[label])
+	}
+	return msg
+}
+
+// constraintCountsOptions applies the options of RecordConstraintCounts and CheckConstraintCounts
+func constraintCountsOptions(opts []func(opt *TestingOption) error) (TestingOption, error) {
+	var opt TestingOption
+	for _, option := range opts {
+		if err := option(&opt); err != nil {
+			return opt, err
+		}
+	}
+	return opt, nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+)
+
+// failureRecorder records the failure of a check instead of failing the test
+type failureRecorder struct {
+	testing.TB
+	failure string
+}
+
+func (r *failureRecorder) Fatalf(format string, args ...interface{}) {
+	r.failure = fmt.Sprintf(format, args...)
+}
+
+func TestConstraintCount(t *testing.T) {
+	assert := NewAssert(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &budgetCircuit{})
+	assert.NoError(err)
+	n := ccs.GetNbConstraints()
+	AssertConstraintCount(t, &budgetCircuit{}, backend.GROTH16, ecc.BN254, n, 0)
+	AssertConstraintCount(t, &budgetCircuit{}, backend.GROTH16, ecc.BN254, n+1, 0.1)
+
+	// the count is off in either direction, with the constraints per tag
+	assert.Empty(checkConstraintCount(ccs, n-1, 0.1))
+	assert.Equal(fmt.Sprintf("%d constraints, expected %d (tolerance 10%%)\n\t\"gadget\": 9\n\t\"gadget/mul\": 2", n, 2*n), checkConstraintCount(ccs, 2*n, 0.1))
+	assert.Contains(checkConstraintCount(ccs, n/2, 0.1), fmt.Sprintf("%d constraints, expected %d", n, n/2))
+
+	// the golden file round trips
+	circuits := map[string]frontend.Circuit{"budget": &budgetCircuit{}}
+	var golden bytes.Buffer
+	assert.NoError(RecordConstraintCounts(circuits, &golden, WithCurves(ecc.BN254, ecc.BLS12_377)))
+	var counts constraintCounts
+	assert.NoError(json.Unmarshal(golden.Bytes(), &counts))
+	assert.Equal(n, counts["budget"]["groth16"]["bn254"])
+	assert.Len(counts["budget"], len(backend.Implemented()))
+	assert.Len(counts["budget"]["plonk"], 2)
+	CheckConstraintCounts(t, circuits, bytes.NewReader(golden.Bytes()), 0)
+
+	// a regression, a stale and a missing circuit are reported
+	counts["budget"]["groth16"]["bn254"] = n / 2
+	counts["removed"] = counts["budget"]
+	data, err := json.Marshal(counts)
+	assert.NoError(err)
+	r := &failureRecorder{TB: t}
+	CheckConstraintCounts(r, map[string]frontend.Circuit{"budget": &budgetCircuit{}, "added": &budgetCircuit{}}, bytes.NewReader(data), 0.1)
+	assert.Contains(r.failure, "added: not recorded")
+	assert.Contains(r.failure, fmt.Sprintf("budget groth16(bn254): %d constraints, expected %d", n, n/2))
+	assert.Contains(r.failure, "removed: recorded, but not a circuit of the test")
+	assert.NotContains(r.failure, "budget plonk")
+}
@@ -0,0 +1,306 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/simulated"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+)
+
+// outcome is whether the test execution engine, or a backend, accepted a witness on a curve
+type outcome struct {
+	curve    ecc.ID
+	name     string // "engine", or the backend
+	accepted bool
+	err      error // why the witness was rejected, or ErrInvalidWitnessVerified
+}
+
+// outcomes of the test execution engine and of the backends, curve by curve
+type outcomes []outcome
+
+// disagree returns true if, on a curve, the engine and the backends don't all accept (or reject) the witness
+func (v outcomes) disagree() bool {
+	for i := 1; i < len(v); i++ {
+		if v[i].curve == v[i-1].curve && v[i].accepted != v[i-1].accepted {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns a table of the outcomes, one row per combination of the test matrix
+func (v outcomes) String() string {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "curve\tbackend\tverdict\terror")
+	for _, r := range v {
+		result := "rejected"
+		if r.accepted {
+			result = "accepted"
+		}
+		reason := ""
+		if r.err != nil {
+			// errors of unsatisfied constraints come with their stack trace
+			reason = strings.SplitN(r.err.Error(), "\n", 2)[0]
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.curve, r.name, result, reason)
+	}
+	tw.Flush()
+	return buf.String()
+}
+
+// crossCheck runs, for each curve, the test execution engine and then each backend on the witness, and
+// returns their outcomes. If valid, the backends prove and verify (see proveAndVerify), else they solve
+// the constraint system and verify a proof computed ignoring the solver errors.
+//
+// The compilation and the setup don't depend on the witness and fail the test if they error.
+func (assert *Assert) crossCheck(circuit, w frontend.Circuit, opt TestingOption, valid bool) outcomes {
+	var v outcomes
+	for _, curve := range opt.curves {
+		ccs := make([]frontend.CompiledConstraintSystem, len(opt.backends))
+		for i, b := range opt.backends {
+			var err error
+			ccs[i], err = assert.compile(circuit, curve, b, opt.compileOpts)
+			assert.checkError(err, b, curve, w)
+		}
+
+		err := IsSolved(circuit, w, curve)
+		v = append(v, outcome{curve: curve, name: "engine", accepted: err == nil, err: err})
+
+		for i, b := range opt.backends {
+			r := outcome{curve: curve, name: b.String()}
+			if valid {
+				r.err = assert.proveAndVerify(ccs[i], b, curve, w, opt)
+				r.accepted = r.err == nil
+			} else {
+				r.accepted, r.err = assert.solveAndVerify(ccs[i], b, curve, w, opt)
+			}
+			v = append(v, r)
+		}
+	}
+	return v
+}
+
+// checkOutcomes fails the test, with the table of the outcomes, if a witness isn't accepted (or rejected) as expected
+func (assert *Assert) checkOutcomes(v outcomes, expected bool, w frontend.Circuit) {
+	for _, r := range v {
+		if r.accepted == expected {
+			continue
+		}
+		msg := "valid witness rejected"
+		if !expected {
+			msg = "invalid witness accepted"
+		}
+		if v.disagree() {
+			msg = "the engine and the backends disagree: " + msg
+		}
+		json, err := witness.ToJSON(w, r.curve)
+		if err != nil {
+			json = err.Error()
+		}
+		assert.FailNow(fmt.Sprintf("%s\n%s\nwitness:%s", msg, v, json))
+	}
+}
+
+// proveAndVerify runs Setup, Prove and Verify of the backend with the witness, and returns the first error.
+// The public witness is also serialized: its number of elements must be the one expected by the verifying
+// key, and the proof is verified with the deserialized copy. If opt.witnessSerialization, the proof is
+// computed from the serialized full witness (see ReadAndProve).
+func (assert *Assert) proveAndVerify(ccs frontend.CompiledConstraintSystem, b backend.ID, curve ecc.ID, w frontend.Circuit, opt TestingOption) error {
+	var buf bytes.Buffer
+
+	switch b {
+	case backend.GROTH16:
+		pk, vk, err := groth16.Setup(ccs)
+		assert.checkError(err, b, curve, w)
+
+		proof, err := groth16.Prove(ccs, pk, w, opt.proverOpts...)
+		if err != nil {
+			return err
+		}
+		if err := groth16.Verify(proof, vk, w); err != nil {
+			return err
+		}
+
+		if opt.witnessSerialization {
+			if _, err := witness.WriteFullTo(&buf, curve, w); err != nil {
+				return err
+			}
+			if proof, err = groth16.ReadAndProve(ccs, pk, &buf, opt.proverOpts...); err != nil {
+				return fmt.Errorf("serialized witness: %w", err)
+			}
+		}
+
+		if err := writePublicWitness(&buf, curve, w, vk.NbPublicWitness()); err != nil {
+			return err
+		}
+		if err := groth16.ReadAndVerify(proof, vk, &buf); err != nil {
+			return fmt.Errorf("serialized public witness: %w", err)
+		}
+
+	case backend.PLONK:
+		srs, err := NewKZGSRS(ccs)
+		assert.checkError(err, b, curve, w)
+
+		pk, vk, err := plonk.Setup(ccs, srs)
+		assert.checkError(err, b, curve, w)
+
+		proof, err := plonk.Prove(ccs, pk, w, opt.proverOpts...)
+		if err != nil {
+			return err
+		}
+		if err := plonk.Verify(proof, vk, w); err != nil {
+			return err
+		}
+
+		if opt.witnessSerialization {
+			if _, err := witness.WriteFullTo(&buf, curve, w); err != nil {
+				return err
+			}
+			if proof, err = plonk.ReadAndProve(ccs, pk, &buf, opt.proverOpts...); err != nil {
+				return fmt.Errorf("serialized witness: %w", err)
+			}
+		}
+
+		if err := writePublicWitness(&buf, curve, w, vk.NbPublicWitness()); err != nil {
+			return err
+		}
+		if err := plonk.ReadAndVerify(proof, vk, &buf); err != nil {
+			return fmt.Errorf("serialized public witness: %w", err)
+		}
+
+	case backend.SIMULATED:
+		pk, vk, err := simulated.Setup(ccs)
+		assert.checkError(err, b, curve, w)
+
+		proof, err := simulated.Prove(ccs, pk, w, opt.proverOpts...)
+		if err != nil {
+			return err
+		}
+		if err := simulated.Verify(proof, vk, w); err != nil {
+			return err
+		}
+
+		if opt.witnessSerialization {
+			if _, err := witness.WriteFullTo(&buf, curve, w); err != nil {
+				return err
+			}
+			if proof, err = simulated.ReadAndProve(ccs, pk, &buf, opt.proverOpts...); err != nil {
+				return fmt.Errorf("serialized witness: %w", err)
+			}
+		}
+
+		if err := writePublicWitness(&buf, curve, w, vk.NbPublicWitness()); err != nil {
+			return err
+		}
+		if err := simulated.ReadAndVerify(proof, vk, &buf); err != nil {
+			return fmt.Errorf("serialized public witness: %w", err)
+		}
+
+	default:
+		panic("backend not implemented")
+	}
+	return nil
+}
+
+// solveAndVerify returns accepted == true if the constraint system of the backend is solved by the
+// witness, or if a proof computed ignoring the solver errors verifies (err is then ErrInvalidWitnessVerified)
+func (assert *Assert) solveAndVerify(ccs frontend.CompiledConstraintSystem, b backend.ID, curve ecc.ID, w frontend.Circuit, opt TestingOption) (accepted bool, err error) {
+	popts := append(opt.proverOpts, backend.IgnoreSolverError)
+
+	switch b {
+	case backend.GROTH16:
+		pk, vk, err := groth16.Setup(ccs)
+		assert.checkError(err, b, curve, w)
+
+		if err = groth16.IsSolved(ccs, w, opt.proverOpts...); err == nil {
+			return true, nil
+		}
+		proof, _ := groth16.Prove(ccs, pk, w, popts...)
+		if groth16.Verify(proof, vk, w) == nil {
+			return true, ErrInvalidWitnessVerified
+		}
+		return false, err
+
+	case backend.PLONK:
+		srs, err := NewKZGSRS(ccs)
+		assert.checkError(err, b, curve, w)
+
+		pk, vk, err := plonk.Setup(ccs, srs)
+		assert.checkError(err, b, curve, w)
+
+		if err = plonk.IsSolved(ccs, w, opt.proverOpts...); err == nil {
+			return true, nil
+		}
+		proof, _ := plonk.Prove(ccs, pk, w, popts...)
+		if plonk.Verify(proof, vk, w) == nil {
+			return true, ErrInvalidWitnessVerified
+		}
+		return false, err
+
+	case backend.SIMULATED:
+		pk, vk, err := simulated.Setup(ccs)
+		assert.checkError(err, b, curve, w)
+
+		if err = simulated.IsSolved(ccs, w, opt.proverOpts...); err == nil {
+			return true, nil
+		}
+		proof, _ := simulated.Prove(ccs, pk, w, popts...)
+		if proof != nil && simulated.Verify(proof, vk, w) == nil {
+			return true, ErrInvalidWitnessVerified
+		}
+		return false, err
+
+	default:
+		panic("backend not implemented")
+	}
+}
+
+// writePublicWitness resets buf and serializes the public witness in it, and returns an error if it
+// doesn't have nbPublic elements (the number expected by the verifying key)
+func writePublicWitness(buf *bytes.Buffer, curve ecc.ID, w frontend.Circuit, nbPublic int) error {
+	buf.Reset()
+	if _, err := witness.WritePublicTo(buf, curve, w); err != nil {
+		return err
+	}
+
+	// [uint32(nbElements) | publicVariables], see package witness
+	data := buf.Bytes()
+	if len(data) < 4 {
+		return fmt.Errorf("serialized public witness: truncated header")
+	}
+	nbElements := int(binary.BigEndian.Uint32(data[:4]))
+	frSize := (curve.Info().Fr.Modulus().BitLen() + 7) / 8
+	if len(data) != 4+nbElements*frSize {
+		return fmt.Errorf("serialized public witness: %d bytes for %d elements", len(data), nbElements)
+	}
+	if nbElements != nbPublic {
+		return fmt.Errorf("serialized public witness has %d elements, the verifying key expects %d", nbElements, nbPublic)
+	}
+	return nil
+}
@@ -1,9 +1,11 @@
 package test
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend"
 )
@@ -17,17 +19,21 @@ func (circuit *hintCircuit) Define(curveID ecc.ID, api frontend.API) error {
 	a25b := api.NewHint(hint.IthBit, circuit.A, 25)
 	aisZero := api.NewHint(hint.IsZero, circuit.A)
 	bisZero := api.NewHint(hint.IsZero, circuit.B)
+	aInv := api.NewHint(hint.InvMod, circuit.A)
+	bInv := api.NewHint(hint.InvMod, circuit.B)
 
 	api.AssertIsEqual(aisZero, 0)
 	api.AssertIsEqual(bisZero, 1)
 	api.AssertIsEqual(a3b, 1)
 	api.AssertIsEqual(a25b, 0)
+	api.AssertIsEqual(api.Mul(aInv, circuit.A), 1)
+	api.AssertIsEqual(bInv, 0)
 
 	return nil
 }
 
 func TestBuiltinHints(t *testing.T) {
-	for _, curve := range ecc.Implemented() {
+	for _, curve := range curves {
 		if err := IsSolved(&hintCircuit{}, &hintCircuit{
 			A: frontend.Value(0b1000),
 			B: frontend.Value(0),
@@ -44,3 +50,219 @@ func TestBuiltinHints(t *testing.T) {
 	}
 
 }
+
+type inverseCircuit struct {
+	X, Inv frontend.Variable
+}
+
+func (circuit *inverseCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Inverse(circuit.X), circuit.Inv)
+	return nil
+}
+
+type inverseOrZeroCircuit inverseCircuit
+
+func (circuit *inverseOrZeroCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.InverseOrZero(circuit.X), circuit.Inv)
+	return nil
+}
+
+func TestInverse(t *testing.T) {
+	assert := NewAssert(t)
+
+	for _, curve := range curves {
+		minusOne := new(big.Int).Sub(curve.Info().Fr.Modulus(), big.NewInt(1))
+		opt := WithCurves(curve)
+
+		for _, orZero := range []bool{false, true} {
+			circuit := frontend.Circuit(&inverseCircuit{})
+			witness := func(x, inv interface{}) frontend.Circuit {
+				return &inverseCircuit{X: frontend.Value(x), Inv: frontend.Value(inv)}
+			}
+			if orZero {
+				circuit = &inverseOrZeroCircuit{}
+				witness = func(x, inv interface{}) frontend.Circuit {
+					return &inverseOrZeroCircuit{X: frontend.Value(x), Inv: frontend.Value(inv)}
+				}
+			}
+
+			assert.ProverSucceeded(circuit, witness(1, 1), opt)
+			assert.ProverSucceeded(circuit, witness(minusOne, minusOne), opt)
+
+			// the prover can't claim a fake inverse
+			assert.ProverFailed(circuit, witness(2, 3), opt)
+			assert.ProverFailed(circuit, witness(minusOne, 1), opt)
+
+			if orZero {
+				assert.ProverSucceeded(circuit, witness(0, 0), opt)
+				assert.ProverFailed(circuit, witness(0, 1), opt)
+			} else {
+				// 0 has no inverse: the constraint system is unsatisfiable
+				assert.ProverFailed(circuit, witness(0, 0), opt)
+				assert.ProverFailed(circuit, witness(0, 1), opt)
+			}
+		}
+	}
+}
+
+type batchInvertCircuit struct {
+	X, Inv [4]frontend.Variable
+}
+
+func (circuit *batchInvertCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	// the constant is inverted at compile time
+	inv := api.BatchInvert(append(circuit.X[:], api.Constant(2)))
+	for i := 0; i < len(circuit.X); i++ {
+		api.AssertIsEqual(inv[i], circuit.Inv[i])
+	}
+	api.AssertIsEqual(api.Mul(inv[len(circuit.X)], 2), 1)
+	return nil
+}
+
+type batchInvertOrZeroCircuit batchInvertCircuit
+
+func (circuit *batchInvertOrZeroCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	inv := api.BatchInvertOrZero(append(circuit.X[:], api.Constant(0)))
+	for i := 0; i < len(circuit.X); i++ {
+		api.AssertIsEqual(inv[i], circuit.Inv[i])
+	}
+	api.AssertIsEqual(inv[len(circuit.X)], 0)
+	return nil
+}
+
+func TestBatchInvert(t *testing.T) {
+	assert := NewAssert(t)
+
+	for _, curve := range curves {
+		minusOne := new(big.Int).Sub(curve.Info().Fr.Modulus(), big.NewInt(1))
+		inverseOfTwo := new(big.Int).ModInverse(big.NewInt(2), curve.Info().Fr.Modulus())
+		opt := WithCurves(curve)
+
+		for _, orZero := range []bool{false, true} {
+			circuit := frontend.Circuit(&batchInvertCircuit{})
+			witness := func(x, inv [4]interface{}) frontend.Circuit {
+				var w batchInvertCircuit
+				for i := range x {
+					w.X[i].Assign(x[i])
+					w.Inv[i].Assign(inv[i])
+				}
+				return &w
+			}
+			if orZero {
+				circuit = &batchInvertOrZeroCircuit{}
+				witness = func(x, inv [4]interface{}) frontend.Circuit {
+					var w batchInvertOrZeroCircuit
+					for i := range x {
+						w.X[i].Assign(x[i])
+						w.Inv[i].Assign(inv[i])
+					}
+					return &w
+				}
+			}
+
+			assert.ProverSucceeded(circuit, witness(
+				[4]interface{}{1, minusOne, 2, 1},
+				[4]interface{}{1, minusOne, inverseOfTwo, 1}), opt)
+
+			// the prover can't claim a fake inverse
+			assert.ProverFailed(circuit, witness(
+				[4]interface{}{1, minusOne, 2, 1},
+				[4]interface{}{1, minusOne, 2, 1}), opt)
+
+			if orZero {
+				assert.ProverSucceeded(circuit, witness(
+					[4]interface{}{2, 0, minusOne, 0},
+					[4]interface{}{inverseOfTwo, 0, minusOne, 0}), opt)
+				assert.ProverFailed(circuit, witness(
+					[4]interface{}{2, 0, minusOne, 0},
+					[4]interface{}{inverseOfTwo, 1, minusOne, 0}), opt)
+			} else {
+				// 0 has no inverse: the constraint system is unsatisfiable
+				assert.ProverFailed(circuit, witness(
+					[4]interface{}{2, 0, minusOne, 1},
+					[4]interface{}{inverseOfTwo, 0, minusOne, 1}), opt)
+			}
+		}
+	}
+}
+
+type inverseManyCircuit struct {
+	X      [100]frontend.Variable
+	batch  bool
+	orZero bool
+}
+
+func (circuit *inverseManyCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	var inv []frontend.Variable
+	switch {
+	case circuit.batch && circuit.orZero:
+		inv = api.BatchInvertOrZero(circuit.X[:])
+	case circuit.batch:
+		inv = api.BatchInvert(circuit.X[:])
+	default:
+		for i := 0; i < len(circuit.X); i++ {
+			if circuit.orZero {
+				inv = append(inv, api.InverseOrZero(circuit.X[i]))
+			} else {
+				inv = append(inv, api.Inverse(circuit.X[i]))
+			}
+		}
+	}
+	api.AssertIsEqual(api.Sum(inv...), 1)
+	return nil
+}
+
+// TestBatchInvertConstraints checks the batch inversions cost no more than the element-wise
+// inversions: the gain is in the solver, which computes a single modular inversion
+func TestBatchInvertConstraints(t *testing.T) {
+	for _, b := range backend.Implemented() {
+		for _, orZero := range []bool{false, true} {
+			batch, err := frontend.Compile(ecc.BN254, b, &inverseManyCircuit{batch: true, orZero: orZero})
+			if err != nil {
+				t.Fatal(err)
+			}
+			single, err := frontend.Compile(ecc.BN254, b, &inverseManyCircuit{orZero: orZero})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if batch.GetNbConstraints() > single.GetNbConstraints() {
+				t.Fatalf("%s (orZero: %t): BatchInvert has %d constraints, Inverse %d", b, orZero, batch.GetNbConstraints(), single.GetNbConstraints())
+			}
+			t.Logf("%s (orZero: %t): %d constraints for 100 inversions", b, orZero, batch.GetNbConstraints())
+		}
+	}
+}
+
+type linearCombinationCircuit struct {
+	X [10]frontend.Variable
+	S frontend.Variable `gnark:",public"`
+}
+
+func (circuit *linearCombinationCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	// S = sum(i * X[i] + 1) - X[0]
+	lc := api.NewLinearCombination()
+	for i := 0; i < len(circuit.X); i++ {
+		lc.Add(api.Mul(circuit.X[i], i))
+		lc.Add(1)
+	}
+	lc.Add(api.Neg(circuit.X[0]))
+	api.AssertIsEqual(lc.Commit(), circuit.S)
+	return nil
+}
+
+func TestLinearCombination(t *testing.T) {
+	assert := NewAssert(t)
+
+	var witness, wrong linearCombinationCircuit
+	s := 10 - 2
+	for i := 0; i < len(witness.X); i++ {
+		witness.X[i].Assign(2)
+		wrong.X[i].Assign(2)
+		s += 2 * i
+	}
+	witness.S.Assign(s)
+	wrong.S.Assign(s + 1)
+
+	assert.ProverSucceeded(&linearCombinationCircuit{}, &witness)
+	assert.ProverFailed(&linearCombinationCircuit{}, &wrong)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	mrand "math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/simulated"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/consensys/gnark/internal/normalize"
+	"github.com/consensys/gnark/internal/parser"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// nbEquivalenceSamples is the number of random assignments used by CircuitsEquivalent
+// when the constraint systems are not structurally equal
+const nbEquivalenceSamples = 64
+
+// equivalenceSeed seeds the random assignments generator, such that a counterexample is reproducible
+const equivalenceSeed = 42
+
+// ErrCircuitsNotEquivalent is returned by CircuitsEquivalent when a counterexample is found
+var ErrCircuitsNotEquivalent = errors.New("circuits are not equivalent")
+
+// AssertCircuitsEquivalent fails the test if circuitA and circuitB are not equivalent,
+// for each curve and backend set in the options (see CircuitsEquivalent).
+func AssertCircuitsEquivalent(t *testing.T, circuitA, circuitB frontend.Circuit, opts ...func(opt *TestingOption) error) {
+	assert := NewAssert(t)
+	opt := assert.options(opts...)
+
+	for _, curve := range opt.curves {
+		for _, b := range opt.backends {
+			err := CircuitsEquivalent(circuitA, circuitB, curve, b, opts...)
+			assert.NoError(err, "%s(%s)", b.String(), curve.String())
+		}
+	}
+}
+
+// CircuitsEquivalent returns nil if circuitA and circuitB compile (for the given curve and backend) to
+// equivalent constraint systems.
+//
+// Both circuits must share the same input structure: the same number of public and secret
+// variables, in the same order.
+//
+// The constraint systems are first normalized (internal wires are renumbered in order of first
+// appearance, terms are sorted and coefficients are compared by value); if the normalized systems are
+// equal, the circuits are equivalent. Otherwise, random assignments are generated and both systems
+// are solved (hints included, see WithProverOpts); the circuits are equivalent if the satisfiability
+// verdicts always match. The first divergent assignment is reported in the returned error.
+//
+// Note that the randomized testing gives no formal guarantee.
+func CircuitsEquivalent(circuitA, circuitB frontend.Circuit, curveID ecc.ID, backendID backend.ID, opts ...func(opt *TestingOption) error) error {
+	opt := TestingOption{}
+	for _, option := range opts {
+		if err := option(&opt); err != nil {
+			return err
+		}
+	}
+
+	nbA, nbB := countInputs(circuitA), countInputs(circuitB)
+	if nbA != nbB {
+		return fmt.Errorf("circuits don't share the same input structure: %v != %v (public, secret)", nbA, nbB)
+	}
+
+	ccsA, err := frontend.Compile(curveID, backendID, circuitA, opt.compileOpts...)
+	if err != nil {
+		return fmt.Errorf("compiling circuitA: %w", err)
+	}
+	ccsB, err := frontend.Compile(curveID, backendID, circuitB, opt.compileOpts...)
+	if err != nil {
+		return fmt.Errorf("compiling circuitB: %w", err)
+	}
+
+	// structural equality
+	if reflect.DeepEqual(normalize.Constraints(ccsA), normalize.Constraints(ccsB)) {
+		return nil
+	}
+
+	// randomized testing
+	isSolved := func(ccs frontend.CompiledConstraintSystem, w frontend.Circuit) error {
+		switch backendID {
+		case backend.GROTH16:
+			return groth16.IsSolved(ccs, w, opt.proverOpts...)
+		case backend.PLONK:
+			return plonk.IsSolved(ccs, w, opt.proverOpts...)
+		case backend.SIMULATED:
+			return simulated.IsSolved(ccs, w, opt.proverOpts...)
+		default:
+			panic("backend not implemented")
+		}
+	}
+
+	wA := utils.ShallowClone(circuitA)
+	wB := utils.ShallowClone(circuitB)
+	defer utils.ResetWitness(wA)
+	defer utils.ResetWitness(wB)
+
+	rng := mrand.New(mrand.NewSource(equivalenceSeed))
+	modulus := curveID.Info().Fr.Modulus()
+	fillers := []func() interface{}{
+		func() interface{} { return 0 },
+		func() interface{} { return int(rng.Uint32() % 2) },
+		func() interface{} { return int(rng.Uint32() % 16) },
+		func() interface{} {
+			r := new(big.Int).Set(seedCorpus[rng.Intn(len(seedCorpus))])
+			return r.Mod(r, modulus)
+		},
+		func() interface{} { return new(big.Int).Rand(rng, modulus) },
+	}
+
+	for i := 0; i < nbEquivalenceSamples; i++ {
+		fill(wA, fillers[i%len(fillers)])
+		utils.CopyWitness(wB, wA)
+
+		errA := isSolved(ccsA, wA)
+		errB := isSolved(ccsB, wB)
+		if (errA == nil) == (errB == nil) {
+			continue
+		}
+
+		json, err := witness.ToJSON(wA, curveID)
+		if err != nil {
+			json = err.Error()
+		}
+		return fmt.Errorf("%w: assignment %s\ncircuitA: %v\ncircuitB: %v", ErrCircuitsNotEquivalent, json, verdict(errA), verdict(errB))
+	}
+
+	return nil
+}
+
+func verdict(err error) string {
+	if err == nil {
+		return "solved"
+	}
+	return "not solved (" + err.Error() + ")"
+}
+
+// countInputs returns the number of public and secret variables in the circuit
+func countInputs(circuit frontend.Circuit) [2]int {
+	var res [2]int
+	counter := func(visibility compiled.Visibility, name string, tValue reflect.Value) error {
+		if visibility == compiled.Public {
+			res[0]++
+		} else if visibility == compiled.Secret {
+			res[1]++
+		}
+		return nil
+	}
+	// ignoring error, counter() always return nil
+	_ = parser.Visit(circuit, "", compiled.Unset, counter, reflect.TypeOf(frontend.Variable{}))
+	return res
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/normalize"
+)
+
+type doubleMulCircuit struct {
+	X, Y frontend.Variable
+}
+
+func (circuit *doubleMulCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, 2), circuit.Y)
+	return nil
+}
+
+type doubleAddCircuit struct {
+	X, Y frontend.Variable
+}
+
+func (circuit *doubleAddCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Add(circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+type booleanCircuit struct {
+	X frontend.Variable
+}
+
+func (circuit *booleanCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsBoolean(circuit.X)
+	return nil
+}
+
+type squareCircuit struct {
+	X frontend.Variable
+}
+
+func (circuit *squareCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.X)
+	return nil
+}
+
+// brokenBooleanCircuit is a broken rewrite of booleanCircuit, which also accepts X == 2
+type brokenBooleanCircuit struct {
+	X frontend.Variable
+}
+
+func (circuit *brokenBooleanCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsLessOrEqual(circuit.X, 2)
+	return nil
+}
+
+func TestCircuitsEquivalent(t *testing.T) {
+	// structurally equal
+	for _, b := range backend.Implemented() {
+		ccsA, err := frontend.Compile(ecc.BN254, b, &doubleMulCircuit{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ccsB, err := frontend.Compile(ecc.BN254, b, &doubleAddCircuit{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(normalize.Constraints(ccsA), normalize.Constraints(ccsB)) {
+			t.Fatalf("%s: x*2 and x+x should normalize to the same constraint system", b.String())
+		}
+	}
+	AssertCircuitsEquivalent(t, &doubleMulCircuit{}, &doubleAddCircuit{})
+
+	// structurally different, but equivalent
+	AssertCircuitsEquivalent(t, &booleanCircuit{}, &squareCircuit{})
+}
+
+func TestCircuitsNotEquivalent(t *testing.T) {
+	for _, b := range backend.Implemented() {
+		err := CircuitsEquivalent(&booleanCircuit{}, &brokenBooleanCircuit{}, ecc.BN254, b)
+		if !errors.Is(err, ErrCircuitsNotEquivalent) {
+			t.Fatalf("%s: expected a counterexample, got %v", b.String(), err)
+		}
+		t.Log(err)
+
+		// input structure mismatch
+		if err := CircuitsEquivalent(&booleanCircuit{}, &doubleMulCircuit{}, ecc.BN254, b); err == nil {
+			t.Fatal("circuits with different inputs can't be equivalent")
+		}
+	}
+}
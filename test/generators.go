@@ -0,0 +1,140 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"fmt"
+	"math/big"
+	mrand "math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/frontend"
+)
+
+// Generator generates random values for inputs of a circuit, see WithGenerators
+type Generator interface {
+	// Generate returns one value per input the generator is registered for, in the order of
+	// registration. The values are given to frontend.Value.
+	Generate(r *mrand.Rand, curveID ecc.ID) []interface{}
+}
+
+// GeneratorFunc is an adapter to use a function as a Generator
+type GeneratorFunc func(r *mrand.Rand, curveID ecc.ID) []interface{}
+
+// Generate calls f(r, curveID)
+func (f GeneratorFunc) Generate(r *mrand.Rand, curveID ecc.ID) []interface{} {
+	return f(r, curveID)
+}
+
+// UintRange returns a Generator of one input, uniform in [min, max]
+func UintRange(min, max uint64) Generator {
+	if min > max {
+		panic("invalid range")
+	}
+	n := new(big.Int).SetUint64(max - min)
+	n.Add(n, big.NewInt(1))
+	return GeneratorFunc(func(r *mrand.Rand, curveID ecc.ID) []interface{} {
+		v := new(big.Int).Rand(r, n)
+		return []interface{}{v.Add(v, new(big.Int).SetUint64(min))}
+	})
+}
+
+// MiMCPreimage returns a Generator of two inputs, a uniform preimage and its MiMC hash with the given seed
+// (see std/hash/mimc), on the scalar field of the curve
+func MiMCPreimage(seed string) Generator {
+	return GeneratorFunc(func(r *mrand.Rand, curveID ecc.ID) []interface{} {
+		var h hash.Hash
+		switch curveID {
+		case ecc.BN254:
+			h = hash.MIMC_BN254
+		case ecc.BLS12_381:
+			h = hash.MIMC_BLS12_381
+		case ecc.BLS12_377:
+			h = hash.MIMC_BLS12_377
+		case ecc.BW6_761:
+			h = hash.MIMC_BW6_761
+		case ecc.BLS24_315:
+			h = hash.MIMC_BLS24_315
+		case ecc.BW6_633:
+			h = hash.MIMC_BW6_633
+		default:
+			panic("not implemented")
+		}
+
+		preImage := new(big.Int).Rand(r, curveID.Info().Fr.Modulus())
+		mimc := h.New(seed)
+		_, _ = mimc.Write(preImage.FillBytes(make([]byte, mimc.BlockSize())))
+		return []interface{}{preImage, new(big.Int).SetBytes(mimc.Sum(nil))}
+	})
+}
+
+// rnd is the source of the generators, generate is not safe for concurrent use
+var rnd = mrand.New(mrand.NewSource(time.Now().UnixNano())) //#nosec G404 weak rng is fine here
+
+// generate sets the inputs of w which have a generator to generated values
+func generate(w frontend.Circuit, generators map[string]Generator, curveID ecc.ID) error {
+	if len(generators) == 0 {
+		return nil
+	}
+
+	// the generators are called in a deterministic order
+	keys := make([]string, 0, len(generators))
+	for k := range generators {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values := make(map[string]interface{})
+	for _, k := range keys {
+		paths := strings.Split(k, ",")
+		v := generators[k].Generate(rnd, curveID)
+		if len(v) != len(paths) {
+			return fmt.Errorf("generator of %s returned %d values, expected %d", k, len(v), len(paths))
+		}
+		for i, path := range paths {
+			values[strings.TrimSpace(path)] = v[i]
+		}
+	}
+
+	schema, err := frontend.ParseSchema(w)
+	if err != nil {
+		return err
+	}
+	err = schema.Visit(w, func(f *frontend.Field, v *frontend.Variable) error {
+		if value, ok := values[f.Path]; ok {
+			*v = frontend.Value(value)
+			delete(values, f.Path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(values) != 0 {
+		unknown := make([]string, 0, len(values))
+		for path := range values {
+			unknown = append(unknown, path)
+		}
+		sort.Strings(unknown)
+		return fmt.Errorf("generators of unknown inputs: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"math/big"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/stretchr/testify/require"
+)
+
+type boundCircuit struct {
+	X frontend.Variable
+}
+
+func (circuit *boundCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	api.AssertIsLessOrEqual(circuit.X, 100)
+	return nil
+}
+
+type preImageCircuit struct {
+	PreImage frontend.Variable
+	Hash     frontend.Variable `gnark:",public"`
+}
+
+func (circuit *preImageCircuit) Define(curveID ecc.ID, api frontend.API) error {
+	h, err := mimc.NewMiMC("seed", curveID, api)
+	if err != nil {
+		return err
+	}
+	h.Write(circuit.PreImage)
+	api.AssertIsEqual(h.Sum(), circuit.Hash)
+	return nil
+}
+
+func TestPropertyTest(t *testing.T) {
+	assert := NewAssert(t)
+
+	assert.PropertyTest(&boundCircuit{}, func(assignment frontend.Circuit) bool {
+		x := assignment.(*boundCircuit).X.WitnessValue.(*big.Int)
+		return x.Cmp(big.NewInt(100)) <= 0
+	}, WithGenerators(map[string]Generator{"X": UintRange(50, 150)}), WithNbAssignments(20), WithCurves(ecc.BN254))
+
+	// a generator of correlated values
+	assert.PropertyTest(&preImageCircuit{}, func(assignment frontend.Circuit) bool {
+		return true
+	}, WithGenerators(map[string]Generator{"PreImage, Hash": MiMCPreimage("seed")}), WithNbAssignments(5))
+
+	// generators are applied by Fuzz too
+	assert.Fuzz(&preImageCircuit{}, 2, WithGenerators(map[string]Generator{"PreImage,Hash": MiMCPreimage("seed")}), WithBackends(backend.GROTH16))
+}
+
+func TestGenerate(t *testing.T) {
+	assert := require.New(t)
+
+	var w boundCircuit
+	r := mrand.New(mrand.NewSource(0)) //#nosec G404 weak rng is fine here
+	for i := 0; i < 100; i++ {
+		v := UintRange(3, 5).Generate(r, ecc.BN254)[0].(*big.Int)
+		assert.True(v.Cmp(big.NewInt(3)) >= 0 && v.Cmp(big.NewInt(5)) <= 0)
+	}
+
+	assert.NoError(generate(&w, map[string]Generator{"X": UintRange(7, 7)}, ecc.BN254))
+	assert.Equal(big.NewInt(7), w.X.WitnessValue)
+
+	assert.Error(generate(&w, map[string]Generator{"Y": UintRange(7, 7)}, ecc.BN254), "unknown input")
+	assert.Error(generate(&w, map[string]Generator{"X": MiMCPreimage("seed")}, ecc.BN254), "wrong number of values")
+}
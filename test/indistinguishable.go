@@ -0,0 +1,135 @@
+/*
+Copyright © 2021 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	mrand "math/rand"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+)
+
+// seed of the readers given to backend.WithRandomness by ProofIndistinguishable
+const randomnessSeed = 42
+
+// ProofIndistinguishable fails the test if, for any curve and backend, the n proofs (n >= 2) of the valid
+// witness computed with fresh randomness don't all verify or aren't pairwise distinct, byte-wise: the
+// randomness of the prover is what makes two proofs of the same statement unlinkable. It also fails if
+// the n proofs computed with backend.WithRandomness, of readers returning the same bytes, aren't identical.
+//
+// The PLONK prover runs with backend.WithBlindingCheck. The proofs of backend.SIMULATED have no randomness,
+// it is skipped.
+//
+// By default, this tests on all curves and proving schemes supported by gnark. See available TestingOption,
+// and WithProofIndistinguishability to run it from ProverSucceeded.
+func (assert *Assert) ProofIndistinguishable(circuit, validWitness frontend.Circuit, n int, opts ...func(opt *TestingOption) error) {
+	assert.GreaterOrEqual(n, 2, "ProofIndistinguishable needs at least 2 proofs")
+	opt := assert.options(opts...)
+
+	for _, curve := range opt.curves {
+		for _, b := range opt.backends {
+			if b == backend.SIMULATED {
+				continue
+			}
+			checkError := func(err error) { assert.checkError(err, b, curve, validWitness) }
+
+			ccs, err := assert.compile(circuit, curve, b, opt.compileOpts)
+			checkError(err)
+			prove := assert.newProver(ccs, b, curve, validWitness)
+			popts := append(opt.proverOpts, backend.WithBlindingCheck())
+
+			// fresh randomness
+			seen := make(map[string]int, n)
+			for i := 0; i < n; i++ {
+				proof, err := prove(popts...)
+				checkError(err)
+				if j, ok := seen[string(proof)]; ok {
+					assert.FailNow(fmt.Sprintf("%s(%s): proofs %d and %d are identical, the prover ignores its randomness", b, curve, j, i))
+				}
+				seen[string(proof)] = i
+			}
+
+			// fixed randomness
+			var first []byte
+			for i := 0; i < n; i++ {
+				rnd := mrand.New(mrand.NewSource(randomnessSeed)) //#nosec G404 reproducible proofs are the point
+				proof, err := prove(append(popts, backend.WithRandomness(rnd))...)
+				checkError(err)
+				if i == 0 {
+					first = proof
+					continue
+				}
+				if !bytes.Equal(first, proof) {
+					assert.FailNow(fmt.Sprintf("%s(%s): proofs 0 and %d differ with the same randomness, the prover has another source of randomness", b, curve, i))
+				}
+			}
+		}
+	}
+}
+
+// newProver runs the setup of the backend, and returns a function which proves the witness, checks that
+// the proof verifies, and returns its serialization
+func (assert *Assert) newProver(ccs frontend.CompiledConstraintSystem, b backend.ID, curve ecc.ID, w frontend.Circuit) func(opts ...func(opt *backend.ProverOption) error) ([]byte, error) {
+	var prove func(opts []func(opt *backend.ProverOption) error) (io.WriterTo, error)
+
+	switch b {
+	case backend.GROTH16:
+		pk, vk, err := groth16.Setup(ccs)
+		assert.checkError(err, b, curve, w)
+		prove = func(opts []func(opt *backend.ProverOption) error) (io.WriterTo, error) {
+			proof, err := groth16.Prove(ccs, pk, w, opts...)
+			if err != nil {
+				return nil, err
+			}
+			return proof, groth16.Verify(proof, vk, w)
+		}
+
+	case backend.PLONK:
+		srs, err := NewKZGSRS(ccs)
+		assert.checkError(err, b, curve, w)
+		pk, vk, err := plonk.Setup(ccs, srs)
+		assert.checkError(err, b, curve, w)
+		prove = func(opts []func(opt *backend.ProverOption) error) (io.WriterTo, error) {
+			proof, err := plonk.Prove(ccs, pk, w, opts...)
+			if err != nil {
+				return nil, err
+			}
+			return proof, plonk.Verify(proof, vk, w)
+		}
+
+	default:
+		panic("backend not implemented")
+	}
+
+	return func(opts ...func(opt *backend.ProverOption) error) ([]byte, error) {
+		proof, err := prove(opts)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if _, err := proof.WriteTo(&buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+}
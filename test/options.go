@@ -17,11 +17,38 @@ limitations under the License.
 package test
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/frontend"
 )
 
+// Environment variables narrowing the default test matrix, as comma separated lists
+// (for example GNARK_TEST_CURVES=bn254,bls12_377 GNARK_TEST_BACKENDS=groth16). "all" selects everything.
+// GNARK_TEST_BACKENDS=simulated runs the tests with the mock backend only, see package backend/simulated.
+const (
+	EnvCurves   = "GNARK_TEST_CURVES"
+	EnvBackends = "GNARK_TEST_BACKENDS"
+)
+
+// test matrix used when a call doesn't set its curves (resp. backends). By order of precedence:
+// the environment variables, then SetDefaultCurves (resp. SetDefaultBackends), then the full matrix
+var (
+	defaultCurves   []ecc.ID
+	defaultBackends []backend.ID
+
+	envCurves   []ecc.ID
+	envBackends []backend.ID
+	envErr      error
+)
+
+func init() {
+	envCurves, envBackends, envErr = parseEnv(os.Getenv(EnvCurves), os.Getenv(EnvBackends))
+}
+
 // TestingOption enables calls to assert.ProverSucceeded and assert.ProverFailed to run with various features
 //
 // In particular: chose the curve, chose the backend and execute serialization tests on the witness
@@ -33,11 +60,16 @@ type TestingOption struct {
 	witnessSerialization bool
 	proverOpts           []func(opt *backend.ProverOption) error
 	compileOpts          []func(opt *frontend.CompileOption) error
+	fullMatrix           bool
+	generators           map[string]Generator
+	nbAssignments        int
+	nbProofs             int // if not 0, ProverSucceeded runs ProofIndistinguishable with nbProofs proofs
 }
 
 // WithBackends enables calls to assert.ProverSucceeded and assert.ProverFailed to run on specific backends only
 //
-// (defaults to all gnark supported backends)
+// (defaults to all gnark supported backends). backend.SIMULATED runs everything but the cryptography,
+// see package backend/simulated
 func WithBackends(b backend.ID, backends ...backend.ID) func(opt *TestingOption) error {
 	return func(opt *TestingOption) error {
 		opt.backends = []backend.ID{b}
@@ -57,6 +89,40 @@ func WithCurves(c ecc.ID, curves ...ecc.ID) func(opt *TestingOption) error {
 	}
 }
 
+// WithFullMatrix enables calls to assert.ProverSucceeded and assert.ProverFailed to run on all curves
+// and all backends, ignoring the defaults set by SetDefaultCurves, SetDefaultBackends, the environment
+// variables and the -short flag
+func WithFullMatrix() func(opt *TestingOption) error {
+	return func(opt *TestingOption) error {
+		opt.curves = curves
+		opt.backends = backend.Implemented()
+		opt.fullMatrix = true
+		return nil
+	}
+}
+
+// SetDefaultCurves sets the curves used by calls which don't specify them (see WithCurves).
+// Calling it with no curves restores the default (all curves).
+//
+// EnvCurves takes precedence over this default.
+func SetDefaultCurves(c ...ecc.ID) {
+	defaultCurves = c
+	if len(c) == 0 {
+		defaultCurves = nil
+	}
+}
+
+// SetDefaultBackends sets the backends used by calls which don't specify them (see WithBackends).
+// Calling it with no backends restores the default (all backends).
+//
+// EnvBackends takes precedence over this default.
+func SetDefaultBackends(b ...backend.ID) {
+	defaultBackends = b
+	if len(b) == 0 {
+		defaultBackends = nil
+	}
+}
+
 // NoSerialization enables calls to assert.ProverSucceeded and assert.ProverFailed to skip witness serialization tests
 func NoSerialization() func(opt *TestingOption) error {
 	return func(opt *TestingOption) error {
@@ -82,3 +148,107 @@ func WithCompileOpts(compileOpts ...func(opt *frontend.CompileOption) error) fun
 		return nil
 	}
 }
+
+// WithGenerators enables calls to assert.Fuzz and assert.PropertyTest to generate the values of the given
+// inputs with the given generators, instead of uniform field elements.
+//
+// The generators are keyed by the path of the input in the circuit structure (see frontend.Field), for
+// example "A.B.0"; a generator of correlated values is keyed by comma separated paths, for example
+// "PreImage,Hash", and generates one value per path.
+func WithGenerators(generators map[string]Generator) func(opt *TestingOption) error {
+	return func(opt *TestingOption) error {
+		for key, g := range generators {
+			if g == nil {
+				return fmt.Errorf("nil generator for %s", key)
+			}
+		}
+		opt.generators = generators
+		return nil
+	}
+}
+
+// WithNbAssignments sets the number of assignments generated by assert.PropertyTest, for each curve and
+// backend (defaults to 100)
+func WithNbAssignments(n int) func(opt *TestingOption) error {
+	return func(opt *TestingOption) error {
+		if n <= 0 {
+			return fmt.Errorf("invalid number of assignments %d", n)
+		}
+		opt.nbAssignments = n
+		return nil
+	}
+}
+
+// WithProofIndistinguishability enables calls to assert.ProverSucceeded to also run assert.ProofIndistinguishable
+// with n proofs (n >= 2), for each curve and backend
+func WithProofIndistinguishability(n int) func(opt *TestingOption) error {
+	return func(opt *TestingOption) error {
+		if n < 2 {
+			return fmt.Errorf("invalid number of proofs %d, at least 2 are needed", n)
+		}
+		opt.nbProofs = n
+		return nil
+	}
+}
+
+// parseEnv parses the values of EnvCurves and EnvBackends; empty values leave the matrix untouched
+func parseEnv(envCurves, envBackends string) (c []ecc.ID, b []backend.ID, err error) {
+	names, all := splitEnv(envCurves)
+	if all {
+		c = curves
+	}
+	for _, name := range names {
+		id, ok := lookupCurve(name)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s: unknown curve %q", EnvCurves, name)
+		}
+		c = append(c, id)
+	}
+
+	names, all = splitEnv(envBackends)
+	if all {
+		b = backend.Implemented()
+	}
+	for _, name := range names {
+		id, ok := lookupBackend(name)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s: unknown backend %q", EnvBackends, name)
+		}
+		b = append(b, id)
+	}
+
+	return
+}
+
+// splitEnv returns the lower case, comma separated names of s, or all == true if s is "all"
+func splitEnv(s string) (names []string, all bool) {
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if name == "all" {
+			return nil, true
+		}
+		names = append(names, name)
+	}
+	return
+}
+
+func lookupCurve(name string) (ecc.ID, bool) {
+	for _, id := range curves {
+		if id.String() == name {
+			return id, true
+		}
+	}
+	return ecc.UNKNOWN, false
+}
+
+func lookupBackend(name string) (backend.ID, bool) {
+	for _, id := range append(backend.Implemented(), backend.SIMULATED) {
+		if id.String() == name {
+			return id, true
+		}
+	}
+	return backend.UNKNOWN, false
+}
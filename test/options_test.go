@@ -0,0 +1,94 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnv(t *testing.T) {
+	assert := require.New(t)
+
+	c, b, err := parseEnv("", "")
+	assert.NoError(err)
+	assert.Nil(c)
+	assert.Nil(b)
+
+	c, b, err = parseEnv(" BN254 ,bls12_377,", "groth16")
+	assert.NoError(err)
+	assert.Equal([]ecc.ID{ecc.BN254, ecc.BLS12_377}, c)
+	assert.Equal([]backend.ID{backend.GROTH16}, b)
+
+	c, b, err = parseEnv("all", "ALL")
+	assert.NoError(err)
+	assert.Equal(curves, c)
+	assert.Equal(backend.Implemented(), b)
+
+	_, _, err = parseEnv("bn256", "")
+	assert.Error(err)
+	_, _, err = parseEnv("", "groth17")
+	assert.Error(err)
+}
+
+func TestMatrixPrecedence(t *testing.T) {
+	defer func(c []ecc.ID, b []backend.ID, ec []ecc.ID, eb []backend.ID) {
+		defaultCurves, defaultBackends, envCurves, envBackends = c, b, ec, eb
+	}(defaultCurves, defaultBackends, envCurves, envBackends)
+
+	allCurves := curves
+	if testing.Short() {
+		allCurves = []ecc.ID{ecc.BN254}
+	}
+
+	// nothing set: the full matrix (bn254 only with -short)
+	SetDefaultCurves()
+	SetDefaultBackends()
+	envCurves, envBackends = nil, nil
+	opt := NewAssert(t).options()
+	require.Equal(t, allCurves, opt.curves)
+	require.Equal(t, backend.Implemented(), opt.backends)
+
+	// package level defaults
+	SetDefaultCurves(ecc.BLS12_377)
+	SetDefaultBackends(backend.PLONK)
+	opt = NewAssert(t).options()
+	require.Equal(t, []ecc.ID{ecc.BLS12_377}, opt.curves)
+	require.Equal(t, []backend.ID{backend.PLONK}, opt.backends)
+
+	// the environment takes precedence over the package level defaults
+	envCurves, envBackends = []ecc.ID{ecc.BLS12_381}, []backend.ID{backend.GROTH16}
+	assert := NewAssert(t)
+	opt = assert.options()
+	require.Equal(t, []ecc.ID{ecc.BLS12_381}, opt.curves)
+	require.Equal(t, []backend.ID{backend.GROTH16}, opt.backends)
+
+	// skipped combinations are reported
+	require.Contains(t, assert.skipped, "plonk(bls12_381)")
+	require.Contains(t, assert.skipped, "groth16(bn254)")
+	require.NotContains(t, assert.skipped, "groth16(bls12_381)")
+
+	// the call site options take precedence over everything
+	opt = NewAssert(t).options(WithCurves(ecc.BW6_761), WithBackends(backend.PLONK))
+	require.Equal(t, []ecc.ID{ecc.BW6_761}, opt.curves)
+	require.Equal(t, []backend.ID{backend.PLONK}, opt.backends)
+
+	assert = NewAssert(t)
+	opt = assert.options(WithCurves(ecc.BW6_761))
+	require.Equal(t, []ecc.ID{ecc.BW6_761}, opt.curves)
+	require.Equal(t, []backend.ID{backend.GROTH16}, opt.backends)
+	require.Equal(t, map[string]struct{}{"plonk(bw6_761)": {}}, assert.skipped)
+
+	// the full matrix ignores the defaults, the environment and -short
+	assert = NewAssert(t)
+	opt = assert.options(WithFullMatrix())
+	require.Equal(t, curves, opt.curves)
+	require.Equal(t, backend.Implemented(), opt.backends)
+	require.Empty(t, assert.skipped)
+
+	// options are applied in order
+	opt = NewAssert(t).options(WithFullMatrix(), WithCurves(ecc.BN254))
+	require.Equal(t, []ecc.ID{ecc.BN254}, opt.curves)
+	require.Equal(t, backend.Implemented(), opt.backends)
+}